@@ -0,0 +1,388 @@
+package acl
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	// PolicyLintWarning findings point at rules that are very likely to be
+	// mistakes or that grant far more access than intended.
+	PolicyLintWarning = "warning"
+
+	// PolicyLintInfo findings describe rule interactions that are legitimate
+	// but worth double checking, such as a narrower rule overriding a broader one.
+	PolicyLintInfo = "info"
+)
+
+const (
+	PolicyLintDuplicate   = "duplicate"
+	PolicyLintShadowed    = "shadowed"
+	PolicyLintOverrides   = "overrides"
+	PolicyLintUnreachable = "unreachable"
+	PolicyLintBroadWrite  = "broad-write"
+	PolicyLintDeprecated  = "deprecated"
+)
+
+// PolicyLintFinding describes a single problem found by LintPolicy.
+type PolicyLintFinding struct {
+	// Severity is one of PolicyLintWarning or PolicyLintInfo.
+	Severity string
+
+	// Kind identifies the check that produced the finding, e.g. "shadowed".
+	Kind string
+
+	// Rule is the rule the finding is about, formatted as it would appear in
+	// the policy source, e.g. `service_prefix "web"`.
+	Rule string
+
+	// Message is a human readable explanation of the finding.
+	Message string
+}
+
+// lintRule is a normalized representation of a single named rule.
+type lintRule struct {
+	kind    string
+	segment string
+	prefix  bool
+	policy  string
+
+	// intentions is the intentions policy a service rule grants, either set
+	// explicitly or derived from the service policy. It is empty for the
+	// other kinds of rules.
+	intentions string
+}
+
+func (r *lintRule) String() string {
+	return formatLintRule(r.kind, r.segment, r.prefix)
+}
+
+func formatLintRule(kind, segment string, prefix bool) string {
+	if prefix {
+		kind += "_prefix"
+	}
+	return fmt.Sprintf("%s %q", kind, segment)
+}
+
+// lintRuleSet holds all of the rules for a single kind of resource in the
+// order they were defined.
+type lintRuleSet struct {
+	kind  string
+	rules []*lintRule
+}
+
+func (s *lintRuleSet) add(segment, policy string, prefix bool) {
+	s.rules = append(s.rules, &lintRule{kind: s.kind, segment: segment, prefix: prefix, policy: policy})
+}
+
+func (s *lintRuleSet) addService(rule *ServiceRule, prefix bool) {
+	s.rules = append(s.rules, &lintRule{
+		kind:       s.kind,
+		segment:    rule.Name,
+		prefix:     prefix,
+		policy:     rule.Policy,
+		intentions: serviceIntentions(rule),
+	})
+}
+
+// serviceIntentions returns the intentions policy granted by a service rule,
+// derived from the service policy the same way the policy authorizer does
+// when it is not set explicitly.
+func serviceIntentions(rule *ServiceRule) string {
+	if rule.Intentions != "" {
+		return rule.Intentions
+	}
+	switch rule.Policy {
+	case PolicyRead, PolicyWrite:
+		return PolicyRead
+	default:
+		return PolicyDeny
+	}
+}
+
+// effective returns the exact and prefix rules that are actually enforced for
+// this set. When the same rule is defined multiple times the definitions are
+// merged the same way the policy authorizer merges them.
+func (s *lintRuleSet) effective() (exact, prefix map[string]*lintRule) {
+	exact = make(map[string]*lintRule)
+	prefix = make(map[string]*lintRule)
+	for _, rule := range s.rules {
+		m := exact
+		if rule.prefix {
+			m = prefix
+		}
+		if existing, ok := m[rule.segment]; !ok || takesPrecedenceOver(rule.policy, existing.policy) {
+			m[rule.segment] = rule
+		}
+	}
+	return exact, prefix
+}
+
+// longestPrefix returns the prefix rule with the longest segment that is a
+// prefix of the given segment. When strict is true a rule for the segment
+// itself is not considered.
+func longestPrefix(prefixes map[string]*lintRule, segment string, strict bool) *lintRule {
+	var match *lintRule
+	for p, rule := range prefixes {
+		if !strings.HasPrefix(segment, p) || (strict && p == segment) {
+			continue
+		}
+		if match == nil || len(p) > len(match.segment) {
+			match = rule
+		}
+	}
+	return match
+}
+
+func collectLintRuleSets(p *PolicyRules) []*lintRuleSet {
+	agents := &lintRuleSet{kind: string(ResourceAgent)}
+	for _, r := range p.Agents {
+		agents.add(r.Node, r.Policy, false)
+	}
+	for _, r := range p.AgentPrefixes {
+		agents.add(r.Node, r.Policy, true)
+	}
+
+	keys := &lintRuleSet{kind: string(ResourceKey)}
+	for _, r := range p.Keys {
+		keys.add(r.Prefix, r.Policy, false)
+	}
+	for _, r := range p.KeyPrefixes {
+		keys.add(r.Prefix, r.Policy, true)
+	}
+
+	nodes := &lintRuleSet{kind: string(ResourceNode)}
+	for _, r := range p.Nodes {
+		nodes.add(r.Name, r.Policy, false)
+	}
+	for _, r := range p.NodePrefixes {
+		nodes.add(r.Name, r.Policy, true)
+	}
+
+	services := &lintRuleSet{kind: string(ResourceService)}
+	for _, r := range p.Services {
+		services.addService(r, false)
+	}
+	for _, r := range p.ServicePrefixes {
+		services.addService(r, true)
+	}
+
+	sessions := &lintRuleSet{kind: string(ResourceSession)}
+	for _, r := range p.Sessions {
+		sessions.add(r.Node, r.Policy, false)
+	}
+	for _, r := range p.SessionPrefixes {
+		sessions.add(r.Node, r.Policy, true)
+	}
+
+	events := &lintRuleSet{kind: string(ResourceEvent)}
+	for _, r := range p.Events {
+		events.add(r.Event, r.Policy, false)
+	}
+	for _, r := range p.EventPrefixes {
+		events.add(r.Event, r.Policy, true)
+	}
+
+	queries := &lintRuleSet{kind: string(ResourceQuery)}
+	for _, r := range p.PreparedQueries {
+		queries.add(r.Prefix, r.Policy, false)
+	}
+	for _, r := range p.PreparedQueryPrefixes {
+		queries.add(r.Prefix, r.Policy, true)
+	}
+
+	return []*lintRuleSet{agents, keys, nodes, services, sessions, events, queries}
+}
+
+// LintPolicy inspects a parsed policy for rules that can never take effect,
+// rules that conflict with each other and rules that grant overly broad
+// access. The policy is expected to have already passed validation by
+// NewPolicyFromSource. Findings are returned in a stable order.
+func LintPolicy(policy *Policy) []PolicyLintFinding {
+	if policy == nil {
+		return nil
+	}
+
+	var findings []PolicyLintFinding
+	for _, set := range collectLintRuleSets(&policy.PolicyRules) {
+		findings = append(findings, lintRuleSetFindings(set)...)
+	}
+
+	// Intentions are derived from the service policy unless set explicitly so
+	// only the explicit values are worth checking for broad access.
+	for _, sp := range policy.ServicePrefixes {
+		if sp.Name == "" && sp.Intentions == PolicyWrite {
+			findings = append(findings, PolicyLintFinding{
+				Severity: PolicyLintWarning,
+				Kind:     PolicyLintBroadWrite,
+				Rule:     formatLintRule(string(ResourceService), sp.Name, true),
+				Message:  "grants intentions write for every service; consider scoping intentions to the services that need them",
+			})
+		}
+	}
+
+	return findings
+}
+
+func lintRuleSetFindings(set *lintRuleSet) []PolicyLintFinding {
+	var findings []PolicyLintFinding
+
+	exact, prefix := set.effective()
+
+	// Duplicate definitions of the same rule are merged by precedence so
+	// every definition other than the winning one is shadowed.
+	for _, rule := range set.rules {
+		m := exact
+		if rule.prefix {
+			m = prefix
+		}
+		if winner := m[rule.segment]; winner != rule {
+			findings = append(findings, PolicyLintFinding{
+				Severity: PolicyLintWarning,
+				Kind:     PolicyLintDuplicate,
+				Rule:     rule.String(),
+				Message: fmt.Sprintf("is defined more than once; the %q definition takes precedence so the %q definition has no effect",
+					winner.policy, rule.policy),
+			})
+		}
+	}
+
+	for _, segment := range sortedLintSegments(exact) {
+		rule := exact[segment]
+
+		if segment == "" {
+			findings = append(findings, PolicyLintFinding{
+				Severity: PolicyLintWarning,
+				Kind:     PolicyLintUnreachable,
+				Rule:     rule.String(),
+				Message:  fmt.Sprintf("can never match because %s names cannot be empty; use %s_prefix \"\" to match every %s", set.kind, set.kind, set.kind),
+			})
+			continue
+		}
+
+		if set.kind == string(ResourceKey) && strings.HasSuffix(segment, "/") {
+			findings = append(findings, PolicyLintFinding{
+				Severity: PolicyLintWarning,
+				Kind:     PolicyLintDeprecated,
+				Rule:     rule.String(),
+				Message:  "only matches this exact key; the legacy ACL syntax treated key rules as prefixes, use key_prefix to match everything below it",
+			})
+		}
+
+		findings = append(findings, lintAgainstBroaderRule(rule, longestPrefix(prefix, segment, false))...)
+	}
+
+	for _, segment := range sortedLintSegments(prefix) {
+		rule := prefix[segment]
+
+		if segment == "" && rule.policy == PolicyWrite {
+			findings = append(findings, PolicyLintFinding{
+				Severity: PolicyLintWarning,
+				Kind:     PolicyLintBroadWrite,
+				Rule:     rule.String(),
+				Message:  fmt.Sprintf("grants write access to every %s; consider scoping the rule to the names that need it", set.kind),
+			})
+		}
+
+		findings = append(findings, lintAgainstBroaderRule(rule, longestPrefix(prefix, segment, true))...)
+	}
+
+	return findings
+}
+
+// lintAgainstBroaderRule compares a rule with the broader prefix rule that
+// would apply to its resources if the rule did not exist.
+func lintAgainstBroaderRule(rule, broader *lintRule) []PolicyLintFinding {
+	if broader == nil {
+		return nil
+	}
+
+	// A service rule also grants intentions, so it only has no effect when
+	// both of its policies match the broader rule.
+	if broader.policy == rule.policy && broader.intentions == rule.intentions {
+		return []PolicyLintFinding{{
+			Severity: PolicyLintWarning,
+			Kind:     PolicyLintShadowed,
+			Rule:     rule.String(),
+			Message:  fmt.Sprintf("has no effect because %s already grants %q", broader, broader.policy),
+		}}
+	}
+
+	var message string
+	switch {
+	case broader.policy == rule.policy:
+		message = fmt.Sprintf("grants intentions %q which overrides intentions %q from %s", rule.intentions, broader.intentions, broader)
+	case broader.intentions == rule.intentions:
+		message = fmt.Sprintf("grants %q which overrides %q from %s", rule.policy, broader.policy, broader)
+	default:
+		message = fmt.Sprintf("grants %q and intentions %q which override %q and intentions %q from %s",
+			rule.policy, rule.intentions, broader.policy, broader.intentions, broader)
+	}
+
+	return []PolicyLintFinding{{
+		Severity: PolicyLintInfo,
+		Kind:     PolicyLintOverrides,
+		Rule:     rule.String(),
+		Message:  message,
+	}}
+}
+
+func sortedLintSegments(m map[string]*lintRule) []string {
+	segments := make([]string, 0, len(m))
+	for segment := range m {
+		segments = append(segments, segment)
+	}
+	sort.Strings(segments)
+	return segments
+}
+
+// GoverningRule returns the rule within the policy that decides the access to
+// the named resource along with the access level it grants. Exact rules take
+// precedence over prefix rules and longer prefixes take precedence over
+// shorter ones. The second return value is false when no rule in the policy
+// applies and the decision falls through to other policies or the default.
+func GoverningRule(policy *Policy, rsc Resource, segment string) (string, bool) {
+	if policy == nil {
+		return "", false
+	}
+
+	switch rsc {
+	case ResourceACL:
+		return formatTopLevelRule("acl", policy.ACL)
+	case ResourceKeyring:
+		return formatTopLevelRule("keyring", policy.Keyring)
+	case ResourceOperator:
+		return formatTopLevelRule("operator", policy.Operator)
+	case ResourceMesh:
+		return formatTopLevelRule("mesh", policy.Mesh)
+	case ResourceIntention:
+		// intentions are governed by the service rules
+		rsc = ResourceService
+	}
+
+	for _, set := range collectLintRuleSets(&policy.PolicyRules) {
+		if set.kind != string(rsc) {
+			continue
+		}
+
+		exact, prefix := set.effective()
+		rule, ok := exact[segment]
+		if !ok {
+			rule = longestPrefix(prefix, segment, false)
+		}
+		if rule == nil {
+			return "", false
+		}
+		return rule.String(), true
+	}
+
+	return "", false
+}
+
+func formatTopLevelRule(name, policy string) (string, bool) {
+	if policy == "" {
+		return "", false
+	}
+	return fmt.Sprintf("%s = %q", name, policy), true
+}
@@ -0,0 +1,243 @@
+package acl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLintPolicy(t *testing.T) {
+	type testCase struct {
+		rules    string
+		expected []PolicyLintFinding
+	}
+
+	cases := map[string]testCase{
+		"override": {
+			rules: `
+				service_prefix "" {
+					policy = "read"
+				}
+				service "web" {
+					policy = "write"
+				}
+				key_prefix "app/" {
+					policy = "write"
+				}
+			`,
+			expected: []PolicyLintFinding{
+				{
+					Severity: PolicyLintInfo,
+					Kind:     PolicyLintOverrides,
+					Rule:     `service "web"`,
+					Message:  `grants "write" which overrides "read" from service_prefix ""`,
+				},
+			},
+		},
+		"override intentions": {
+			rules: `
+				service_prefix "" {
+					policy = "read"
+				}
+				service "web" {
+					policy = "read"
+					intentions = "write"
+				}
+			`,
+			expected: []PolicyLintFinding{
+				{
+					Severity: PolicyLintInfo,
+					Kind:     PolicyLintOverrides,
+					Rule:     `service "web"`,
+					Message:  `grants intentions "write" which overrides intentions "read" from service_prefix ""`,
+				},
+			},
+		},
+		"shadowed intentions": {
+			rules: `
+				service_prefix "" {
+					policy = "read"
+				}
+				service "web" {
+					policy = "read"
+					intentions = "read"
+				}
+			`,
+			expected: []PolicyLintFinding{
+				{
+					Severity: PolicyLintWarning,
+					Kind:     PolicyLintShadowed,
+					Rule:     `service "web"`,
+					Message:  `has no effect because service_prefix "" already grants "read"`,
+				},
+			},
+		},
+		"duplicate": {
+			rules: `
+				node "foo" {
+					policy = "write"
+				}
+				node "foo" {
+					policy = "deny"
+				}
+			`,
+			expected: []PolicyLintFinding{
+				{
+					Severity: PolicyLintWarning,
+					Kind:     PolicyLintDuplicate,
+					Rule:     `node "foo"`,
+					Message:  `is defined more than once; the "deny" definition takes precedence so the "write" definition has no effect`,
+				},
+			},
+		},
+		"shadowed": {
+			rules: `
+				key_prefix "app/" {
+					policy = "read"
+				}
+				key_prefix "app/config/" {
+					policy = "read"
+				}
+				key "app/config/db" {
+					policy = "read"
+				}
+			`,
+			expected: []PolicyLintFinding{
+				{
+					Severity: PolicyLintWarning,
+					Kind:     PolicyLintShadowed,
+					Rule:     `key "app/config/db"`,
+					Message:  `has no effect because key_prefix "app/config/" already grants "read"`,
+				},
+				{
+					Severity: PolicyLintWarning,
+					Kind:     PolicyLintShadowed,
+					Rule:     `key_prefix "app/config/"`,
+					Message:  `has no effect because key_prefix "app/" already grants "read"`,
+				},
+			},
+		},
+		"exact shadowed by same prefix": {
+			rules: `
+				session_prefix "node1" {
+					policy = "write"
+				}
+				session "node1" {
+					policy = "write"
+				}
+			`,
+			expected: []PolicyLintFinding{
+				{
+					Severity: PolicyLintWarning,
+					Kind:     PolicyLintShadowed,
+					Rule:     `session "node1"`,
+					Message:  `has no effect because session_prefix "node1" already grants "write"`,
+				},
+			},
+		},
+		"unreachable empty exact": {
+			rules: `
+				agent "" {
+					policy = "read"
+				}
+			`,
+			expected: []PolicyLintFinding{
+				{
+					Severity: PolicyLintWarning,
+					Kind:     PolicyLintUnreachable,
+					Rule:     `agent ""`,
+					Message:  `can never match because agent names cannot be empty; use agent_prefix "" to match every agent`,
+				},
+			},
+		},
+		"legacy key syntax": {
+			rules: `
+				key "app/" {
+					policy = "read"
+				}
+			`,
+			expected: []PolicyLintFinding{
+				{
+					Severity: PolicyLintWarning,
+					Kind:     PolicyLintDeprecated,
+					Rule:     `key "app/"`,
+					Message:  "only matches this exact key; the legacy ACL syntax treated key rules as prefixes, use key_prefix to match everything below it",
+				},
+			},
+		},
+		"broad write": {
+			rules: `
+				event_prefix "" {
+					policy = "write"
+				}
+				service_prefix "" {
+					policy = "read"
+					intentions = "write"
+				}
+			`,
+			expected: []PolicyLintFinding{
+				{
+					Severity: PolicyLintWarning,
+					Kind:     PolicyLintBroadWrite,
+					Rule:     `event_prefix ""`,
+					Message:  "grants write access to every event; consider scoping the rule to the names that need it",
+				},
+				{
+					Severity: PolicyLintWarning,
+					Kind:     PolicyLintBroadWrite,
+					Rule:     `service_prefix ""`,
+					Message:  "grants intentions write for every service; consider scoping intentions to the services that need them",
+				},
+			},
+		},
+	}
+
+	for name, tcase := range cases {
+		t.Run(name, func(t *testing.T) {
+			policy, err := NewPolicyFromSource(tcase.rules, SyntaxCurrent, nil, nil)
+			require.NoError(t, err)
+			require.Equal(t, tcase.expected, LintPolicy(policy))
+		})
+	}
+}
+
+func TestGoverningRule(t *testing.T) {
+	policy, err := NewPolicyFromSource(`
+		acl = "read"
+		service_prefix "" {
+			policy = "read"
+		}
+		service_prefix "web" {
+			policy = "write"
+		}
+		service "web-admin" {
+			policy = "deny"
+		}
+	`, SyntaxCurrent, nil, nil)
+	require.NoError(t, err)
+
+	type testCase struct {
+		resource Resource
+		segment  string
+		rule     string
+		found    bool
+	}
+
+	cases := map[string]testCase{
+		"top level":      {resource: ResourceACL, rule: `acl = "read"`, found: true},
+		"unset":          {resource: ResourceOperator},
+		"exact":          {resource: ResourceService, segment: "web-admin", rule: `service "web-admin"`, found: true},
+		"longest prefix": {resource: ResourceService, segment: "web-ui", rule: `service_prefix "web"`, found: true},
+		"catch all":      {resource: ResourceService, segment: "db", rule: `service_prefix ""`, found: true},
+		"intention":      {resource: ResourceIntention, segment: "web", rule: `service_prefix "web"`, found: true},
+		"no rules":       {resource: ResourceNode, segment: "foo"},
+	}
+
+	for name, tcase := range cases {
+		t.Run(name, func(t *testing.T) {
+			rule, found := GoverningRule(policy, tcase.resource, tcase.segment)
+			require.Equal(t, tcase.found, found)
+			require.Equal(t, tcase.rule, rule)
+		})
+	}
+}
@@ -16,6 +16,12 @@ type aclBootstrapResponse struct {
 	structs.ACLToken
 }
 
+// aclPolicyLintResponse is the body returned by the policy lint endpoint
+type aclPolicyLintResponse struct {
+	Results []structs.ACLPolicyLintResult
+	Grants  []structs.ACLPolicyGrant
+}
+
 var aclDisabled = UnauthorizedError{Reason: "ACL support disabled"}
 
 // checkACLDisabled will return a standard response if ACLs are disabled. This
@@ -101,6 +107,61 @@ func (s *HTTPHandlers) ACLPolicyList(resp http.ResponseWriter, req *http.Request
 	return out.Policies, nil
 }
 
+// ACLPolicyLint lints the stored policies, or the rules given in the request
+// body when called with PUT. When a resource is given in the query parameters
+// the policies granting access to it are returned instead.
+func (s *HTTPHandlers) ACLPolicyLint(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
+	if s.checkACLDisabled() {
+		return nil, aclDisabled
+	}
+
+	var args structs.ACLPolicyLintRequest
+	if done := s.parse(resp, req, &args.Datacenter, &args.QueryOptions); done {
+		return nil, nil
+	}
+	if err := s.parseEntMeta(req, &args.EnterpriseMeta); err != nil {
+		return nil, err
+	}
+
+	if args.Datacenter == "" {
+		args.Datacenter = s.agent.config.Datacenter
+	}
+
+	if req.Method == "PUT" {
+		var body struct {
+			Rules string
+		}
+		if err := lib.DecodeJSON(req.Body, &body); err != nil {
+			return nil, BadRequestError{Reason: fmt.Sprintf("Policy lint decoding failed: %v", err)}
+		}
+		if body.Rules == "" {
+			return nil, BadRequestError{Reason: "Missing Rules to lint"}
+		}
+		args.Rules = body.Rules
+	}
+
+	query := req.URL.Query()
+	args.Resource = acl.Resource(query.Get("resource"))
+	args.Segment = query.Get("segment")
+	args.Access = query.Get("access")
+
+	var out structs.ACLPolicyLintResponse
+	defer setMeta(resp, &out.QueryMeta)
+	if err := s.agent.RPC("ACL.PolicyLint", &args, &out); err != nil {
+		return nil, err
+	}
+
+	// make sure we return arrays and not nil
+	if out.Results == nil {
+		out.Results = make([]structs.ACLPolicyLintResult, 0)
+	}
+	if out.Grants == nil {
+		out.Grants = make([]structs.ACLPolicyGrant, 0)
+	}
+
+	return &aclPolicyLintResponse{Results: out.Results, Grants: out.Grants}, nil
+}
+
 func (s *HTTPHandlers) ACLPolicyCRUD(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
 	if s.checkACLDisabled() {
 		return nil, aclDisabled
@@ -487,6 +548,29 @@ func (s *HTTPHandlers) ACLTokenClone(resp http.ResponseWriter, req *http.Request
 	return &out, nil
 }
 
+func (s *HTTPHandlers) ACLTokenDerive(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
+	if s.checkACLDisabled() {
+		return nil, aclDisabled
+	}
+
+	args := structs.ACLTokenDeriveRequest{
+		Datacenter: s.agent.config.Datacenter,
+	}
+	s.parseDC(req, &args.Datacenter)
+	s.parseToken(req, &args.Token)
+
+	if err := s.rewordUnknownEnterpriseFieldError(lib.DecodeJSON(req.Body, &args.ACLToken)); err != nil {
+		return nil, BadRequestError{Reason: fmt.Sprintf("Token decoding failed: %v", err)}
+	}
+
+	var out structs.ACLToken
+	if err := s.agent.RPC("ACL.TokenDerive", &args, &out); err != nil {
+		return nil, err
+	}
+
+	return &out, nil
+}
+
 func (s *HTTPHandlers) ACLRoleList(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
 	if s.checkACLDisabled() {
 		return nil, aclDisabled
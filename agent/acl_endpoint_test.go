@@ -47,6 +47,7 @@ func TestACL_Disabled_Response(t *testing.T) {
 		{"ACLPolicyList", a.srv.ACLPolicyList},
 		{"ACLPolicyCRUD", a.srv.ACLPolicyCRUD},
 		{"ACLPolicyCreate", a.srv.ACLPolicyCreate},
+		{"ACLPolicyLint", a.srv.ACLPolicyLint},
 		{"ACLTokenList", a.srv.ACLTokenList},
 		{"ACLTokenCreate", a.srv.ACLTokenCreate},
 		{"ACLTokenSelf", a.srv.ACLTokenSelf},
@@ -401,6 +402,45 @@ func TestACL_HTTP(t *testing.T) {
 			require.True(t, ok)
 			require.Equal(t, policyMap[idMap["policy-"+policyName]], policy)
 		})
+
+		t.Run("Lint", func(t *testing.T) {
+			body := map[string]string{"Rules": `node_prefix "" { policy = "write" }`}
+			req, _ := http.NewRequest("PUT", "/v1/acl/policy/lint?token=root", jsonBody(body))
+			resp := httptest.NewRecorder()
+			raw, err := a.srv.ACLPolicyLint(resp, req)
+			require.NoError(t, err)
+			out, ok := raw.(*aclPolicyLintResponse)
+			require.True(t, ok)
+			require.Len(t, out.Results, 1)
+			require.Len(t, out.Results[0].Findings, 1)
+			require.Equal(t, acl.PolicyLintBroadWrite, out.Results[0].Findings[0].Kind)
+			require.Empty(t, out.Grants)
+		})
+
+		t.Run("Lint Missing Rules", func(t *testing.T) {
+			req, _ := http.NewRequest("PUT", "/v1/acl/policy/lint?token=root", jsonBody(map[string]string{}))
+			resp := httptest.NewRecorder()
+			_, err := a.srv.ACLPolicyLint(resp, req)
+			require.Error(t, err)
+			_, ok := err.(BadRequestError)
+			require.True(t, ok)
+		})
+
+		t.Run("Lint Grants", func(t *testing.T) {
+			req, _ := http.NewRequest("GET", "/v1/acl/policy/lint?token=root&resource=node&segment=foo", nil)
+			resp := httptest.NewRecorder()
+			raw, err := a.srv.ACLPolicyLint(resp, req)
+			require.NoError(t, err)
+			out, ok := raw.(*aclPolicyLintResponse)
+			require.True(t, ok)
+			require.Empty(t, out.Results)
+
+			var names []string
+			for _, grant := range out.Grants {
+				names = append(names, grant.PolicyName)
+			}
+			require.ElementsMatch(t, []string{"global-management", "read-all-nodes"}, names)
+		})
 	})
 
 	t.Run("Role", func(t *testing.T) {
@@ -771,6 +811,34 @@ func TestACL_HTTP(t *testing.T) {
 			idMap["token-cloned"] = token.AccessorID
 			tokenMap[token.AccessorID] = token
 		})
+		t.Run("Derive", func(t *testing.T) {
+			baseToken := tokenMap[idMap["token-test"]]
+
+			tokenInput := &structs.ACLToken{
+				Description:   "derived token",
+				Policies:      baseToken.Policies,
+				ExpirationTTL: time.Minute,
+			}
+
+			req, _ := http.NewRequest("PUT", "/v1/acl/token/derive?token="+baseToken.SecretID, jsonBody(tokenInput))
+			resp := httptest.NewRecorder()
+			obj, err := a.srv.ACLTokenDerive(resp, req)
+			require.NoError(t, err)
+			token, ok := obj.(*structs.ACLToken)
+			require.True(t, ok)
+
+			require.NotEqual(t, baseToken.AccessorID, token.AccessorID)
+			require.Equal(t, baseToken.AccessorID, token.ParentAccessorID)
+			require.Equal(t, tokenInput.Description, token.Description)
+			require.Equal(t, baseToken.Policies, token.Policies)
+			require.NotNil(t, token.ExpirationTime)
+
+			// Clean up so that the listing below is unaffected.
+			req, _ = http.NewRequest("DELETE", "/v1/acl/token/"+token.AccessorID+"?token=root", nil)
+			resp = httptest.NewRecorder()
+			_, err = a.srv.ACLTokenCRUD(resp, req)
+			require.NoError(t, err)
+		})
 		t.Run("Update", func(t *testing.T) {
 			originalToken := tokenMap[idMap["token-cloned"]]
 
@@ -1621,6 +1621,64 @@ func (s *HTTPHandlers) AgentConnectCALeafCert(resp http.ResponseWriter, req *htt
 	return reply, nil
 }
 
+// AgentXDSStatus returns what the xDS server has sent to a proxy connected to
+// this agent, and how the proxy responded.
+//
+// GET /v1/agent/xds/:proxy_id
+func (s *HTTPHandlers) AgentXDSStatus(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
+	// Note that this is the ID of a proxy's service instance.
+	id, err := getPathSuffixUnescaped(req.URL.Path, "/v1/agent/xds/")
+	if err != nil {
+		return nil, err
+	}
+	if id == "" {
+		return nil, BadRequestError{Reason: "Missing proxy ID"}
+	}
+
+	var token string
+	s.parseToken(req, &token)
+
+	var entMeta acl.EnterpriseMeta
+	if err := s.parseEntMetaNoWildcard(req, &entMeta); err != nil {
+		return nil, err
+	}
+
+	authz, err := s.agent.delegate.ResolveTokenAndDefaultMeta(token, &entMeta, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if !s.validateRequestPartition(resp, &entMeta) {
+		return nil, nil
+	}
+
+	sid := structs.NewServiceID(id, &entMeta)
+	svc := s.agent.State.Service(sid)
+	if svc == nil {
+		return nil, NotFoundError{Reason: fmt.Sprintf("unknown service ID: %s", sid.String())}
+	}
+
+	var authzContext acl.AuthorizerContext
+	svc.FillAuthzContext(&authzContext)
+	if err := authz.ToAllowAuthorizer().ServiceReadAllowed(svc.Service, &authzContext); err != nil {
+		return nil, err
+	}
+
+	if svc.Kind != structs.ServiceKindConnectProxy && !svc.IsGateway() {
+		return nil, BadRequestError{Reason: fmt.Sprintf("service %s is not a proxy or gateway", sid.String())}
+	}
+
+	if s.agent.xdsServer == nil {
+		return nil, NotFoundError{Reason: "the xDS server is not running, the agent has no gRPC port"}
+	}
+
+	status, ok := s.agent.xdsServer.ProxyStatus(sid)
+	if !ok {
+		return nil, NotFoundError{Reason: fmt.Sprintf("proxy %s is not connected to this agent", sid.String())}
+	}
+	return status, nil
+}
+
 // AgentConnectAuthorize
 //
 // POST /v1/agent/connect/authorize
@@ -6550,6 +6550,58 @@ func requireLeafValidUnderCA(t *testing.T, issued *structs.IssuedCert, ca *struc
 	require.NoError(t, err)
 }
 
+func TestAgent_XDSStatus(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+	a := NewTestAgent(t, "")
+	defer a.Shutdown()
+	testrpc.WaitForTestAgent(t, a.RPC, "dc1")
+
+	require.NoError(t, a.State.AddService(&structs.NodeService{
+		ID:      "web",
+		Service: "web",
+		Port:    8080,
+	}, ""))
+	require.NoError(t, a.State.AddService(&structs.NodeService{
+		Kind:    structs.ServiceKindConnectProxy,
+		ID:      "web-sidecar-proxy",
+		Service: "web-sidecar-proxy",
+		Port:    21000,
+		Proxy: structs.ConnectProxyConfig{
+			DestinationServiceName: "web",
+			DestinationServiceID:   "web",
+		},
+	}, ""))
+
+	run := func(t *testing.T, id string) (int, string) {
+		req, _ := http.NewRequest("GET", "/v1/agent/xds/"+id, nil)
+		resp := httptest.NewRecorder()
+		a.srv.h.ServeHTTP(resp, req)
+		return resp.Code, resp.Body.String()
+	}
+
+	t.Run("unknown service", func(t *testing.T) {
+		code, body := run(t, "db-sidecar-proxy")
+		require.Equal(t, http.StatusNotFound, code)
+		require.Contains(t, body, "unknown service ID")
+	})
+
+	t.Run("not a proxy", func(t *testing.T) {
+		code, body := run(t, "web")
+		require.Equal(t, http.StatusBadRequest, code)
+		require.Contains(t, body, "is not a proxy or gateway")
+	})
+
+	t.Run("proxy not connected", func(t *testing.T) {
+		code, body := run(t, "web-sidecar-proxy")
+		require.Equal(t, http.StatusNotFound, code)
+		require.Contains(t, body, "is not connected to this agent")
+	})
+}
+
 func TestAgentConnectAuthorize_badBody(t *testing.T) {
 	if testing.Short() {
 		t.Skip("too slow for testing.Short")
@@ -6784,6 +6836,94 @@ func TestAgentConnectAuthorize_deny(t *testing.T) {
 	assert.Contains(t, obj.Reason, "Matched")
 }
 
+// Test the permissions of an L7 intention being evaluated against a request
+func TestAgentConnectAuthorize_L7(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+
+	a := NewTestAgent(t, "")
+	defer a.Shutdown()
+
+	testrpc.WaitForTestAgent(t, a.RPC, "dc1")
+	target := "db"
+
+	// L7 intentions require the destination to speak HTTP.
+	for _, entry := range []structs.ConfigEntry{
+		&structs.ServiceConfigEntry{
+			Kind:     structs.ServiceDefaults,
+			Name:     target,
+			Protocol: "http",
+		},
+		&structs.ServiceIntentionsConfigEntry{
+			Kind: structs.ServiceIntentions,
+			Name: target,
+			Sources: []*structs.SourceIntention{
+				{
+					Name: "web",
+					Permissions: []*structs.IntentionPermission{
+						{
+							Action: structs.IntentionActionAllow,
+							HTTP:   &structs.IntentionHTTPPermission{PathPrefix: "/public"},
+						},
+						{
+							Action: structs.IntentionActionDeny,
+							HTTP:   &structs.IntentionHTTPPermission{PathPrefix: "/"},
+						},
+					},
+				},
+			},
+		},
+	} {
+		var out bool
+		req := structs.ConfigEntryRequest{Datacenter: "dc1", Entry: entry}
+		require.NoError(t, a.RPC("ConfigEntry.Apply", &req, &out))
+	}
+
+	authorize := func(t *testing.T, args *structs.ConnectAuthorizeRequest) *connectAuthorizeResp {
+		t.Helper()
+		req, _ := http.NewRequest("POST", "/v1/agent/connect/authorize", jsonReader(args))
+		resp := httptest.NewRecorder()
+		a.srv.h.ServeHTTP(resp, req)
+		require.Equal(t, 200, resp.Code)
+
+		obj := &connectAuthorizeResp{}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(obj))
+		return obj
+	}
+
+	t.Run("no request is denied", func(t *testing.T) {
+		obj := authorize(t, &structs.ConnectAuthorizeRequest{
+			Target:        target,
+			ClientCertURI: connect.TestSpiffeIDService(t, "web").URI().String(),
+		})
+		require.False(t, obj.Authorized)
+		require.Contains(t, obj.Reason, "Matched L7 intention")
+	})
+
+	t.Run("first matching permission allows", func(t *testing.T) {
+		obj := authorize(t, &structs.ConnectAuthorizeRequest{
+			Target:        target,
+			ClientCertURI: connect.TestSpiffeIDService(t, "web").URI().String(),
+			HTTP:          &structs.ConnectAuthorizeHTTPRequest{Method: "GET", Path: "/public/index.html"},
+		})
+		require.True(t, obj.Authorized)
+		require.Contains(t, obj.Reason, "permission 0")
+	})
+
+	t.Run("first matching permission denies", func(t *testing.T) {
+		obj := authorize(t, &structs.ConnectAuthorizeRequest{
+			Target:        target,
+			ClientCertURI: connect.TestSpiffeIDService(t, "web").URI().String(),
+			HTTP:          &structs.ConnectAuthorizeHTTPRequest{Method: "GET", Path: "/admin"},
+		})
+		require.False(t, obj.Authorized)
+		require.Contains(t, obj.Reason, "permission 1")
+	})
+}
+
 // Test when there is an intention allowing service with a different trust
 // domain. We allow this because migration between trust domains shouldn't cause
 // an outage even if we have stale info about current trusted domains. It's safe
@@ -0,0 +1,70 @@
+package cachetype
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hashicorp/consul/agent/cache"
+	"github.com/hashicorp/consul/agent/structs"
+)
+
+func TestPeeringMeshInfo(t *testing.T) {
+	rpc := TestRPC(t)
+	typ := &PeeringMeshInfo{RPC: rpc}
+
+	// Expect the proper RPC call. This also sets the expected value
+	// since that is return-by-pointer in the arguments.
+	var resp *structs.IndexedPeeringMeshInfos
+	rpc.On("RPC", "Peering.MeshInfo", mock.Anything, mock.Anything).Return(nil).
+		Run(func(args mock.Arguments) {
+			req := args.Get(1).(*structs.PeeringMeshInfoRequest)
+			require.Equal(t, uint64(24), req.QueryOptions.MinQueryIndex)
+			require.Equal(t, 1*time.Second, req.QueryOptions.MaxQueryTime)
+			require.True(t, req.AllowStale)
+			require.Equal(t, "east", req.PeerName)
+
+			reply := args.Get(2).(*structs.IndexedPeeringMeshInfos)
+			reply.MeshInfos = map[string]*structs.PeeringMeshInfo{
+				"east": {
+					TrustDomain:      "11111111-2222-3333-4444-555555555555.consul",
+					RootPEMs:         []string{"root"},
+					Datacenter:       "dc2",
+					GatewayAddresses: []string{"198.18.0.1:443"},
+				},
+			}
+			reply.QueryMeta.Index = 48
+			resp = reply
+		})
+
+	// Fetch
+	resultA, err := typ.Fetch(cache.FetchOptions{
+		MinIndex: 24,
+		Timeout:  1 * time.Second,
+	}, &structs.PeeringMeshInfoRequest{
+		Datacenter:  "dc1",
+		PeerName:    "east",
+		ServiceName: "api",
+	})
+	require.NoError(t, err)
+	require.Equal(t, cache.FetchResult{
+		Value: resp,
+		Index: 48,
+	}, resultA)
+
+	rpc.AssertExpectations(t)
+}
+
+func TestPeeringMeshInfo_badReqType(t *testing.T) {
+	rpc := TestRPC(t)
+	typ := &PeeringMeshInfo{RPC: rpc}
+
+	// Fetch
+	_, err := typ.Fetch(cache.FetchOptions{}, cache.TestRequest(
+		t, cache.RequestInfo{Key: "foo", MinIndex: 64}))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "wrong type")
+	rpc.AssertExpectations(t)
+}
@@ -712,13 +712,20 @@ func (b *builder) build() (rt RuntimeConfig, err error) {
 			"existing_arn":   "ExistingARN",
 			"delete_on_exit": "DeleteOnExit",
 
+			// PKCS#11 CA config
+			"lib_path":    "LibPath",
+			"token_label": "TokenLabel",
+			"pin":         "PIN",
+			"key_label":   "KeyLabel",
+
 			// Common CA config
-			"leaf_cert_ttl":      "LeafCertTTL",
-			"csr_max_per_second": "CSRMaxPerSecond",
-			"csr_max_concurrent": "CSRMaxConcurrent",
-			"private_key_type":   "PrivateKeyType",
-			"private_key_bits":   "PrivateKeyBits",
-			"root_cert_ttl":      "RootCertTTL",
+			"leaf_cert_ttl":        "LeafCertTTL",
+			"csr_max_per_second":   "CSRMaxPerSecond",
+			"csr_max_concurrent":   "CSRMaxConcurrent",
+			"private_key_type":     "PrivateKeyType",
+			"private_key_bits":     "PrivateKeyBits",
+			"root_cert_ttl":        "RootCertTTL",
+			"root_rotation_period": "RootRotationPeriod",
 		})
 	}
 
@@ -1394,6 +1401,7 @@ func (b *builder) validate(rt RuntimeConfig) error {
 		structs.ConsulCAProvider: true,
 		structs.VaultCAProvider:  true,
 		structs.AWSCAProvider:    true,
+		structs.PKCS11CAProvider: true,
 	}
 	if _, ok := validCAProviders[rt.ConnectCAProvider]; !ok {
 		return fmt.Errorf("%s is not a valid CA provider", rt.ConnectCAProvider)
@@ -1411,6 +1419,10 @@ func (b *builder) validate(rt RuntimeConfig) error {
 			if _, err := ca.ParseAWSCAConfig(rt.ConnectCAConfig); err != nil {
 				return err
 			}
+		case structs.PKCS11CAProvider:
+			if _, err := ca.ParsePKCS11CAConfig(rt.ConnectCAConfig); err != nil {
+				return err
+			}
 		}
 	}
 
@@ -1698,6 +1710,7 @@ func (b *builder) upstreamsVal(v []Upstream) structs.Upstreams {
 			DestinationNamespace: stringVal(u.DestinationNamespace),
 			DestinationPartition: stringVal(u.DestinationPartition),
 			DestinationName:      stringVal(u.DestinationName),
+			DestinationPeer:      stringVal(u.DestinationPeer),
 			Datacenter:           stringVal(u.Datacenter),
 			LocalBindAddress:     stringVal(u.LocalBindAddress),
 			LocalBindPort:        intVal(u.LocalBindPort),
@@ -5878,6 +5878,7 @@ func TestLoad_FullConfig(t *testing.T) {
 			"IntermediateCertTTL": "8760h",
 			"LeafCertTTL":         "1h",
 			"RootCertTTL":         "96360h",
+			"RootRotationPeriod":  "43800h",
 			"CSRMaxPerSecond":     float64(100),
 			"CSRMaxConcurrent":    float64(2),
 		},
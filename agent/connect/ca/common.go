@@ -2,7 +2,10 @@ package ca
 
 import (
 	"bytes"
+	"crypto"
+	"crypto/rand"
 	"crypto/x509"
+	"encoding/pem"
 	"fmt"
 	"strings"
 
@@ -104,3 +107,20 @@ func EnsureTrailingNewline(cert string) string {
 	}
 	return fmt.Sprintf("%s\n", cert)
 }
+
+// createRevocationList signs the template with the given key on behalf of
+// issuer and returns the PEM-encoded CRL.
+func createRevocationList(template *x509.RevocationList, issuer *x509.Certificate, signer crypto.Signer) (string, error) {
+	template.SignatureAlgorithm = connect.SigAlgoForKey(signer)
+
+	bs, err := x509.CreateRevocationList(rand.Reader, template, issuer, signer)
+	if err != nil {
+		return "", fmt.Errorf("error generating revocation list: %s", err)
+	}
+
+	var buf bytes.Buffer
+	if err := pem.Encode(&buf, &pem.Block{Type: "X509 CRL", Bytes: bs}); err != nil {
+		return "", fmt.Errorf("error encoding revocation list: %s", err)
+	}
+	return buf.String(), nil
+}
@@ -209,3 +209,26 @@ type RootResult struct {
 type NeedsStop interface {
 	Stop()
 }
+
+// RootRotator is an optional interface that may be implemented by a primary
+// CA provider that manages its own root key material. It allows the leader to
+// rotate the root automatically once it reaches the configured
+// RootRotationPeriod, without the operator supplying new key material.
+type RootRotator interface {
+	// RootRotationState returns the provider state that a new instance of the
+	// provider should be configured with in order to generate a new private
+	// key and root certificate. The current provider is left untouched so that
+	// it can still cross-sign the new root.
+	RootRotationState() (map[string]string, error)
+}
+
+// RevocationListSigner is an optional interface that may be implemented by a
+// provider which holds the key used to sign leaf certificates. It allows the
+// leader to publish a certificate revocation list for the leaf certificates
+// signed in the local datacenter.
+type RevocationListSigner interface {
+	// SignRevocationList signs the template with the key of the active leaf
+	// signing certificate and returns the PEM-encoded CRL. The issuer and
+	// signature algorithm of the template are set by the provider.
+	SignRevocationList(template *x509.RevocationList) (string, error)
+}
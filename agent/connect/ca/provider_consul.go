@@ -11,6 +11,7 @@ import (
 	"fmt"
 	"math/big"
 	"net/url"
+	"strconv"
 	"sync"
 	"time"
 
@@ -28,6 +29,11 @@ var (
 	ErrNotInitialized = errors.New("provider not initialized")
 )
 
+// consulRootGenerationStateKey is the provider state key which records how
+// many times the root has been rotated automatically. It is part of the
+// provider ID so every generation gets its own private key and root cert.
+const consulRootGenerationStateKey = "root_generation"
+
 type ConsulProvider struct {
 	Delegate ConsulProviderStateDelegate
 
@@ -38,6 +44,10 @@ type ConsulProvider struct {
 	spiffeID  *connect.SpiffeIDSigning
 	logger    hclog.Logger
 
+	// rootGeneration is the automatic root rotation generation read from the
+	// provider state. It is empty until the root is first rotated automatically.
+	rootGeneration string
+
 	// testState is only used to test Consul leader's handling of providers that
 	// need to persist state. Consul provider actually manages it's state directly
 	// in the FSM since it is highly sensitive not (root private keys) not just
@@ -72,7 +82,14 @@ func (c *ConsulProvider) Configure(cfg ProviderConfig) error {
 		return err
 	}
 	c.config = config
-	c.id = hexStringHash(fmt.Sprintf("%s,%s,%s,%d,%v", config.PrivateKey, config.RootCert, config.PrivateKeyType, config.PrivateKeyBits, cfg.IsPrimary))
+	c.rootGeneration = cfg.State[consulRootGenerationStateKey]
+	id := fmt.Sprintf("%s,%s,%s,%d,%v", config.PrivateKey, config.RootCert, config.PrivateKeyType, config.PrivateKeyBits, cfg.IsPrimary)
+	if c.rootGeneration != "" {
+		// Only include the generation once set so that the ID of providers
+		// which were never rotated automatically doesn't change.
+		id += "," + c.rootGeneration
+	}
+	c.id = hexStringHash(id)
 	c.clusterID = cfg.ClusterID
 	c.isPrimary = cfg.IsPrimary
 	c.spiffeID = connect.SpiffeIDSigningForCluster(c.clusterID)
@@ -90,6 +107,12 @@ func (c *ConsulProvider) Configure(cfg ProviderConfig) error {
 		return nil
 	}
 
+	// Old ID schemes predate automatic root rotation, so an entry using one of
+	// them can never belong to a rotated root generation.
+	if c.rootGeneration != "" {
+		return c.createProviderState()
+	}
+
 	oldIDs := []string{
 		hexStringHash(fmt.Sprintf("%s,%s,%v", config.PrivateKey, config.RootCert, cfg.IsPrimary)),
 		fmt.Sprintf("%s,%s", config.PrivateKey, config.RootCert),
@@ -127,6 +150,12 @@ func (c *ConsulProvider) Configure(cfg ProviderConfig) error {
 		}
 	}
 
+	return c.createProviderState()
+}
+
+// createProviderState writes an empty provider state entry for this
+// provider's ID.
+func (c *ConsulProvider) createProviderState() error {
 	args := &structs.CARequest{
 		Op:            structs.CAOpSetProviderState,
 		ProviderState: &structs.CAConsulProviderState{ID: c.id},
@@ -146,7 +175,44 @@ func (c *ConsulProvider) Configure(cfg ProviderConfig) error {
 // state handling behavior without needing to plumb a full test mock provider
 // right through Consul server code.
 func (c *ConsulProvider) State() (map[string]string, error) {
-	return c.testState, nil
+	if c.rootGeneration == "" {
+		return c.testState, nil
+	}
+
+	state := make(map[string]string, len(c.testState)+1)
+	for k, v := range c.testState {
+		state[k] = v
+	}
+	state[consulRootGenerationStateKey] = c.rootGeneration
+	return state, nil
+}
+
+// RootRotationState implements RootRotator by bumping the root generation. As
+// the generation is part of the provider ID, a provider configured with the
+// returned state has no existing state and generates a new root.
+func (c *ConsulProvider) RootRotationState() (map[string]string, error) {
+	if !c.isPrimary {
+		return nil, fmt.Errorf("provider is not the root certificate authority")
+	}
+	if c.config.PrivateKey != "" || c.config.RootCert != "" {
+		return nil, fmt.Errorf("cannot automatically rotate a root CA that was configured with a PrivateKey or RootCert")
+	}
+
+	generation := 0
+	if c.rootGeneration != "" {
+		var err error
+		generation, err = strconv.Atoi(c.rootGeneration)
+		if err != nil {
+			return nil, fmt.Errorf("invalid root generation %q in provider state: %w", c.rootGeneration, err)
+		}
+	}
+
+	state := make(map[string]string, len(c.testState)+1)
+	for k, v := range c.testState {
+		state[k] = v
+	}
+	state[consulRootGenerationStateKey] = strconv.Itoa(generation + 1)
+	return state, nil
 }
 
 // GenerateRoot initializes a new root certificate and private key if needed.
@@ -413,6 +479,34 @@ func (c *ConsulProvider) Sign(csr *x509.CertificateRequest) (string, error) {
 	return buf.String(), nil
 }
 
+// SignRevocationList signs the template with the key of the active root, or
+// the intermediate in a secondary datacenter.
+func (c *ConsulProvider) SignRevocationList(template *x509.RevocationList) (string, error) {
+	providerState, err := c.getState()
+	if err != nil {
+		return "", err
+	}
+	if providerState.PrivateKey == "" {
+		return "", ErrNotInitialized
+	}
+
+	signer, err := connect.ParseSigner(providerState.PrivateKey)
+	if err != nil {
+		return "", err
+	}
+
+	certPEM, err := c.ActiveIntermediate()
+	if err != nil {
+		return "", err
+	}
+	caCert, err := connect.ParseCert(certPEM)
+	if err != nil {
+		return "", fmt.Errorf("error parsing CA cert: %s", err)
+	}
+
+	return createRevocationList(template, caCert, signer)
+}
+
 // SignIntermediate will validate the CSR to ensure the trust domain in the
 // URI SAN matches the local one and that basic constraints for a CA certificate
 // are met. It should return a signed CA certificate with a path length constraint
@@ -2,7 +2,10 @@ package ca
 
 import (
 	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
 	"fmt"
+	"math/big"
 	"testing"
 	"time"
 
@@ -510,3 +513,115 @@ func TestConsulCAProvider_MigrateOldID(t *testing.T) {
 		})
 	}
 }
+
+func TestConsulCAProvider_RootRotationState(t *testing.T) {
+	t.Parallel()
+
+	conf := testConsulCAConfig()
+	delegate := newMockDelegate(t, conf)
+
+	provider := TestConsulProvider(t, delegate)
+	require.NoError(t, provider.Configure(testProviderConfig(conf)))
+	oldRoot, err := provider.GenerateRoot()
+	require.NoError(t, err)
+
+	state, err := provider.State()
+	require.NoError(t, err)
+	require.Empty(t, state)
+
+	rotationState, err := provider.RootRotationState()
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"root_generation": "1"}, rotationState)
+
+	// A provider configured with the rotation state generates a new root.
+	newProvider := TestConsulProvider(t, delegate)
+	newCfg := testProviderConfig(conf)
+	newCfg.State = rotationState
+	require.NoError(t, newProvider.Configure(newCfg))
+	newRoot, err := newProvider.GenerateRoot()
+	require.NoError(t, err)
+	require.NotEqual(t, oldRoot.PEM, newRoot.PEM)
+
+	state, err = newProvider.State()
+	require.NoError(t, err)
+	require.Equal(t, rotationState, state)
+
+	// The old provider is untouched and can cross-sign the new root.
+	root, err := provider.GenerateRoot()
+	require.NoError(t, err)
+	require.Equal(t, oldRoot.PEM, root.PEM)
+
+	newRootCert, err := connect.ParseCert(newRoot.PEM)
+	require.NoError(t, err)
+	_, err = provider.CrossSignCA(newRootCert)
+	require.NoError(t, err)
+
+	// Reconfiguring with the same state finds the rotated root again.
+	reconfigured := TestConsulProvider(t, delegate)
+	require.NoError(t, reconfigured.Configure(newCfg))
+	root, err = reconfigured.GenerateRoot()
+	require.NoError(t, err)
+	require.Equal(t, newRoot.PEM, root.PEM)
+
+	rotationState, err = reconfigured.RootRotationState()
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"root_generation": "2"}, rotationState)
+
+	t.Run("user supplied root", func(t *testing.T) {
+		rootCA := connect.TestCAWithTTL(t, nil, 5*time.Hour)
+		conf := testConsulCAConfig()
+		conf.Config = map[string]interface{}{
+			"PrivateKey": rootCA.SigningKey,
+			"RootCert":   rootCA.RootCert,
+		}
+		delegate := newMockDelegate(t, conf)
+
+		provider := TestConsulProvider(t, delegate)
+		require.NoError(t, provider.Configure(testProviderConfig(conf)))
+
+		_, err := provider.RootRotationState()
+		require.Error(t, err)
+	})
+}
+
+func TestConsulCAProvider_SignRevocationList(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range KeyTestCases {
+		tc := tc
+		t.Run(tc.Desc, func(t *testing.T) {
+			conf := testConsulCAConfig()
+			conf.Config["PrivateKeyType"] = tc.KeyType
+			conf.Config["PrivateKeyBits"] = tc.KeyBits
+			delegate := newMockDelegate(t, conf)
+
+			provider := TestConsulProvider(t, delegate)
+			require.NoError(t, provider.Configure(testProviderConfig(conf)))
+			root, err := provider.GenerateRoot()
+			require.NoError(t, err)
+
+			now := time.Now()
+			crlPEM, err := provider.SignRevocationList(&x509.RevocationList{
+				Number:     big.NewInt(1),
+				ThisUpdate: now,
+				NextUpdate: now.Add(time.Hour),
+				RevokedCertificates: []pkix.RevokedCertificate{
+					{SerialNumber: big.NewInt(42), RevocationTime: now},
+				},
+			})
+			require.NoError(t, err)
+
+			block, _ := pem.Decode([]byte(crlPEM))
+			require.NotNil(t, block)
+			require.Equal(t, "X509 CRL", block.Type)
+			crl, err := x509.ParseRevocationList(block.Bytes)
+			require.NoError(t, err)
+
+			rootCert, err := connect.ParseCert(root.PEM)
+			require.NoError(t, err)
+			require.NoError(t, crl.CheckSignatureFrom(rootCert))
+			require.Len(t, crl.RevokedCertificates, 1)
+			require.Equal(t, int64(42), crl.RevokedCertificates[0].SerialNumber.Int64())
+		})
+	}
+}
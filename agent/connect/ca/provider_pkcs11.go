@@ -0,0 +1,947 @@
+//go:build cgo
+// +build cgo
+
+package ca
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/miekg/pkcs11"
+
+	"github.com/hashicorp/consul/agent/connect"
+	"github.com/hashicorp/consul/agent/structs"
+)
+
+const (
+	// PKCS11StateKeyIDKey is the key in the provider State we store the
+	// CKA_ID of the signing key under. Only the identifier of the key is ever
+	// persisted, the private key itself never leaves the token.
+	PKCS11StateKeyIDKey = "key_id"
+
+	// PKCS11StateKeyLabelKey is the key in the provider State we store the
+	// label of the signing key under.
+	PKCS11StateKeyLabelKey = "key_label"
+
+	// PKCS11StateTokenLabelKey is the key in the provider State we store the
+	// label of the token holding the signing key under.
+	PKCS11StateTokenLabelKey = "token_label"
+)
+
+var (
+	pkcs11ModulesLock sync.Mutex
+	// pkcs11Modules holds the PKCS#11 modules loaded so far, by path. A module
+	// can only be initialized once per process so it is shared by all the
+	// provider instances and never finalized.
+	pkcs11Modules = map[string]*pkcs11.Ctx{}
+)
+
+// PKCS11Provider implements Provider using a private key stored in a PKCS#11
+// token, typically an HSM. The signing key is generated on the token as a
+// sensitive, non-extractable object and every signature is made by the token.
+// The CA certificate of the key is stored on the token next to it.
+type PKCS11Provider struct {
+	config     *structs.PKCS11CAProviderConfig
+	clusterID  string
+	datacenter string
+	isPrimary  bool
+	spiffeID   *connect.SpiffeIDSigning
+	logger     hclog.Logger
+
+	keyLabel string
+	keyID    []byte
+
+	ctx     *pkcs11.Ctx
+	session pkcs11.SessionHandle
+	signer  *pkcs11Signer
+
+	// sessionLock serializes the use of the session since PKCS#11 sessions
+	// can't be used concurrently.
+	sessionLock sync.Mutex
+}
+
+// NewPKCS11Provider returns a new PKCS11Provider.
+func NewPKCS11Provider(logger hclog.Logger) (Provider, error) {
+	return &PKCS11Provider{logger: logger}, nil
+}
+
+// Configure implements Provider
+func (p *PKCS11Provider) Configure(cfg ProviderConfig) error {
+	config, err := ParsePKCS11CAConfig(cfg.RawConfig)
+	if err != nil {
+		return err
+	}
+
+	p.config = config
+	p.clusterID = cfg.ClusterID
+	p.datacenter = cfg.Datacenter
+	p.isPrimary = cfg.IsPrimary
+	p.spiffeID = connect.SpiffeIDSigningForCluster(p.clusterID)
+
+	p.keyLabel = config.KeyLabel
+	if p.keyLabel == "" {
+		p.keyLabel = "consul-connect-ca-" + p.datacenter
+	}
+
+	// Only reuse the key from the previous state if it was created on the same
+	// token with the same label, otherwise the configuration asks for a
+	// different key.
+	p.keyID = nil
+	if cfg.State[PKCS11StateKeyLabelKey] == p.keyLabel && cfg.State[PKCS11StateTokenLabelKey] == config.TokenLabel {
+		if id := cfg.State[PKCS11StateKeyIDKey]; id != "" {
+			p.keyID, err = hex.DecodeString(id)
+			if err != nil {
+				return fmt.Errorf("invalid PKCS#11 key ID in provider state: %v", err)
+			}
+		}
+	}
+
+	ctx, err := loadPKCS11Module(config.LibPath)
+	if err != nil {
+		return err
+	}
+	slot, err := findPKCS11Slot(ctx, config.TokenLabel)
+	if err != nil {
+		return err
+	}
+	session, err := ctx.OpenSession(slot, pkcs11.CKF_SERIAL_SESSION|pkcs11.CKF_RW_SESSION)
+	if err != nil {
+		return fmt.Errorf("error opening a session on PKCS#11 token %q: %v", config.TokenLabel, err)
+	}
+	// The login state is shared by all the sessions of the application so
+	// another provider instance may have logged in already.
+	if err := ctx.Login(session, pkcs11.CKU_USER, config.PIN); err != nil && !errors.Is(err, pkcs11.Error(pkcs11.CKR_USER_ALREADY_LOGGED_IN)) {
+		ctx.CloseSession(session)
+		return fmt.Errorf("error logging into PKCS#11 token %q: %v", config.TokenLabel, err)
+	}
+
+	p.closeSession()
+	p.ctx = ctx
+	p.session = session
+	p.signer = nil
+
+	// Load the signing key if it already exists, it is otherwise generated
+	// when the root or the intermediate CSR is first requested.
+	if err := p.loadKey(); err != nil && err != ErrNotInitialized {
+		return err
+	}
+
+	p.logger.Debug("PKCS#11 CA provider configured",
+		"token", config.TokenLabel,
+		"key_label", p.keyLabel,
+		"is_primary", p.isPrimary,
+	)
+	return nil
+}
+
+// State implements Provider. It returns the identifier of the signing key so
+// that it is used again after a restart or a leader election.
+func (p *PKCS11Provider) State() (map[string]string, error) {
+	if p.keyID == nil {
+		return nil, nil
+	}
+	return map[string]string{
+		PKCS11StateKeyIDKey:      hex.EncodeToString(p.keyID),
+		PKCS11StateKeyLabelKey:   p.keyLabel,
+		PKCS11StateTokenLabelKey: p.config.TokenLabel,
+	}, nil
+}
+
+// GenerateRoot implements PrimaryProvider
+func (p *PKCS11Provider) GenerateRoot() (RootResult, error) {
+	if !p.isPrimary {
+		return RootResult{}, fmt.Errorf("provider is not the root certificate authority")
+	}
+
+	p.sessionLock.Lock()
+	defer p.sessionLock.Unlock()
+
+	if err := p.ensureKey(); err != nil {
+		return RootResult{}, err
+	}
+
+	certPEM, err := p.readCert()
+	if err != nil {
+		return RootResult{}, err
+	}
+	if certPEM != "" {
+		return RootResult{PEM: certPEM}, nil
+	}
+
+	certPEM, err = p.generateCA()
+	if err != nil {
+		return RootResult{}, fmt.Errorf("error generating CA: %v", err)
+	}
+	if err := p.writeCert(certPEM); err != nil {
+		return RootResult{}, err
+	}
+
+	return RootResult{PEM: certPEM}, nil
+}
+
+// GenerateIntermediateCSR implements SecondaryProvider. The CSR is for the key
+// held by the token, which is generated if needed.
+func (p *PKCS11Provider) GenerateIntermediateCSR() (string, error) {
+	if p.isPrimary {
+		return "", fmt.Errorf("provider is the root certificate authority, " +
+			"cannot generate an intermediate CSR")
+	}
+
+	p.sessionLock.Lock()
+	defer p.sessionLock.Unlock()
+
+	if err := p.ensureKey(); err != nil {
+		return "", err
+	}
+	return connect.CreateCACSR(p.spiffeID, p.signer)
+}
+
+// SetIntermediate implements SecondaryProvider. It validates that the
+// intermediate was issued for the key held by the token and stores it on the
+// token.
+func (p *PKCS11Provider) SetIntermediate(intermediatePEM, rootPEM string) error {
+	if p.isPrimary {
+		return fmt.Errorf("cannot set an intermediate using another root in the primary datacenter")
+	}
+
+	if err := validateSetIntermediate(intermediatePEM, rootPEM, p.spiffeID); err != nil {
+		return err
+	}
+
+	p.sessionLock.Lock()
+	defer p.sessionLock.Unlock()
+
+	if p.signer == nil {
+		return ErrNotInitialized
+	}
+	intermediate, err := connect.ParseCert(intermediatePEM)
+	if err != nil {
+		return fmt.Errorf("error parsing intermediate PEM: %v", err)
+	}
+	if !publicKeysEqual(intermediate.PublicKey, p.signer.Public()) {
+		return fmt.Errorf("intermediate cert is for a different private key")
+	}
+
+	return p.writeCert(intermediatePEM)
+}
+
+// ActiveIntermediate implements Provider. The primary signs leaf certificates
+// with its root directly, like the built-in Consul provider.
+func (p *PKCS11Provider) ActiveIntermediate() (string, error) {
+	p.sessionLock.Lock()
+	defer p.sessionLock.Unlock()
+
+	return p.readCert()
+}
+
+// GenerateIntermediate implements Provider
+func (p *PKCS11Provider) GenerateIntermediate() (string, error) {
+	return p.ActiveIntermediate()
+}
+
+// Sign implements Provider
+func (p *PKCS11Provider) Sign(csr *x509.CertificateRequest) (string, error) {
+	connect.HackSANExtensionForCSR(csr)
+
+	p.sessionLock.Lock()
+	defer p.sessionLock.Unlock()
+
+	caCert, err := p.signingCert()
+	if err != nil {
+		return "", err
+	}
+	keyId, err := connect.KeyId(p.signer.Public())
+	if err != nil {
+		return "", err
+	}
+	subjectKeyID, err := connect.KeyId(csr.PublicKey)
+	if err != nil {
+		return "", err
+	}
+	sn, err := pkcs11SerialNumber()
+	if err != nil {
+		return "", err
+	}
+
+	// Sign the certificate valid from 1 minute in the past, this helps it be
+	// accepted right away even when nodes are not in close time sync across the
+	// cluster. A minute is more than enough for typical DC clock drift.
+	effectiveNow := time.Now().Add(-1 * CertificateTimeDriftBuffer)
+	template := x509.Certificate{
+		SerialNumber:          sn,
+		URIs:                  csr.URIs,
+		Signature:             csr.Signature,
+		SignatureAlgorithm:    connect.SigAlgoForKey(p.signer),
+		PublicKeyAlgorithm:    csr.PublicKeyAlgorithm,
+		PublicKey:             csr.PublicKey,
+		BasicConstraintsValid: true,
+		KeyUsage: x509.KeyUsageDataEncipherment |
+			x509.KeyUsageKeyAgreement |
+			x509.KeyUsageDigitalSignature |
+			x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage: []x509.ExtKeyUsage{
+			x509.ExtKeyUsageClientAuth,
+			x509.ExtKeyUsageServerAuth,
+		},
+		NotAfter:       effectiveNow.Add(p.config.LeafCertTTL),
+		NotBefore:      effectiveNow,
+		AuthorityKeyId: keyId,
+		SubjectKeyId:   subjectKeyID,
+		DNSNames:       csr.DNSNames,
+		IPAddresses:    csr.IPAddresses,
+	}
+
+	return p.createCert(&template, caCert, csr.PublicKey)
+}
+
+// SignRevocationList implements RevocationListSigner
+func (p *PKCS11Provider) SignRevocationList(template *x509.RevocationList) (string, error) {
+	p.sessionLock.Lock()
+	defer p.sessionLock.Unlock()
+
+	caCert, err := p.signingCert()
+	if err != nil {
+		return "", err
+	}
+	return createRevocationList(template, caCert, p.signer)
+}
+
+// SignIntermediate implements PrimaryProvider
+func (p *PKCS11Provider) SignIntermediate(csr *x509.CertificateRequest) (string, error) {
+	if err := validateSignIntermediate(csr, p.spiffeID); err != nil {
+		return "", err
+	}
+
+	p.sessionLock.Lock()
+	defer p.sessionLock.Unlock()
+
+	caCert, err := p.signingCert()
+	if err != nil {
+		return "", err
+	}
+	subjectKeyID, err := connect.KeyId(csr.PublicKey)
+	if err != nil {
+		return "", err
+	}
+	sn, err := pkcs11SerialNumber()
+	if err != nil {
+		return "", err
+	}
+
+	effectiveNow := time.Now().Add(-1 * CertificateTimeDriftBuffer)
+	template := x509.Certificate{
+		SerialNumber:          sn,
+		DNSNames:              csr.DNSNames,
+		EmailAddresses:        csr.EmailAddresses,
+		IPAddresses:           csr.IPAddresses,
+		URIs:                  csr.URIs,
+		ExtraExtensions:       csr.ExtraExtensions,
+		Subject:               csr.Subject,
+		Signature:             csr.Signature,
+		SignatureAlgorithm:    connect.SigAlgoForKey(p.signer),
+		PublicKeyAlgorithm:    csr.PublicKeyAlgorithm,
+		PublicKey:             csr.PublicKey,
+		BasicConstraintsValid: true,
+		KeyUsage: x509.KeyUsageCertSign |
+			x509.KeyUsageCRLSign |
+			x509.KeyUsageDigitalSignature,
+		IsCA:           true,
+		MaxPathLenZero: true,
+		NotAfter:       effectiveNow.Add(p.config.IntermediateCertTTL),
+		NotBefore:      effectiveNow,
+		SubjectKeyId:   subjectKeyID,
+	}
+
+	return p.createCert(&template, caCert, csr.PublicKey)
+}
+
+// CrossSignCA implements PrimaryProvider
+func (p *PKCS11Provider) CrossSignCA(cert *x509.Certificate) (string, error) {
+	p.sessionLock.Lock()
+	defer p.sessionLock.Unlock()
+
+	caCert, err := p.signingCert()
+	if err != nil {
+		return "", err
+	}
+	keyId, err := connect.KeyId(p.signer.Public())
+	if err != nil {
+		return "", err
+	}
+	sn, err := pkcs11SerialNumber()
+	if err != nil {
+		return "", err
+	}
+
+	// Create the cross-signing template from the existing root CA
+	template := *cert
+	template.SerialNumber = sn
+	template.SignatureAlgorithm = caCert.SignatureAlgorithm
+	template.AuthorityKeyId = keyId
+
+	// This cross-signed cert is only needed during rotation, and only while old
+	// leaf certs are still in use, see ConsulProvider.CrossSignCA.
+	effectiveNow := time.Now().Add(-1 * CertificateTimeDriftBuffer)
+	template.NotBefore = effectiveNow
+	template.NotAfter = effectiveNow.AddDate(0, 0, 7)
+
+	return p.createCert(&template, caCert, cert.PublicKey)
+}
+
+// SupportsCrossSigning implements Provider
+func (p *PKCS11Provider) SupportsCrossSigning() (bool, error) {
+	return true, nil
+}
+
+// Cleanup implements Provider. The key and certificate objects are left on the
+// token since they may be subject to the HSM's own retention rules, it is up
+// to the operator to destroy them once the root has been rotated out.
+func (p *PKCS11Provider) Cleanup(_ bool, _ map[string]interface{}) error {
+	p.Stop()
+	return nil
+}
+
+// Stop implements NeedsStop
+func (p *PKCS11Provider) Stop() {
+	p.sessionLock.Lock()
+	defer p.sessionLock.Unlock()
+
+	p.closeSession()
+}
+
+func (p *PKCS11Provider) closeSession() {
+	if p.ctx == nil {
+		return
+	}
+	if err := p.ctx.CloseSession(p.session); err != nil {
+		p.logger.Warn("failed to close PKCS#11 session", "error", err)
+	}
+	p.ctx = nil
+	p.signer = nil
+}
+
+// signingCert returns the parsed CA certificate of the signing key. It must be
+// called with the sessionLock held.
+func (p *PKCS11Provider) signingCert() (*x509.Certificate, error) {
+	if p.signer == nil {
+		return nil, ErrNotInitialized
+	}
+	certPEM, err := p.readCert()
+	if err != nil {
+		return nil, err
+	}
+	if certPEM == "" {
+		return nil, ErrNotInitialized
+	}
+	caCert, err := connect.ParseCert(certPEM)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing CA cert: %s", err)
+	}
+	return caCert, nil
+}
+
+// createCert signs the template with the key held by the token and returns the
+// PEM encoded certificate.
+func (p *PKCS11Provider) createCert(template, parent *x509.Certificate, pub crypto.PublicKey) (string, error) {
+	bs, err := x509.CreateCertificate(rand.Reader, template, parent, pub, p.signer)
+	if err != nil {
+		return "", fmt.Errorf("error generating certificate: %s", err)
+	}
+
+	var buf bytes.Buffer
+	if err := pem.Encode(&buf, &pem.Block{Type: "CERTIFICATE", Bytes: bs}); err != nil {
+		return "", fmt.Errorf("error encoding certificate: %s", err)
+	}
+	return buf.String(), nil
+}
+
+// generateCA makes a new self-signed root CA for the key held by the token.
+func (p *PKCS11Provider) generateCA() (string, error) {
+	keyId, err := connect.KeyId(p.signer.Public())
+	if err != nil {
+		return "", err
+	}
+	uid, err := connect.CompactUID()
+	if err != nil {
+		return "", err
+	}
+	sn, err := pkcs11SerialNumber()
+	if err != nil {
+		return "", err
+	}
+
+	template := x509.Certificate{
+		SerialNumber:          sn,
+		Subject:               pkix.Name{CommonName: connect.CACN("pkcs11", uid, p.clusterID, p.isPrimary)},
+		URIs:                  []*url.URL{p.spiffeID.URI()},
+		BasicConstraintsValid: true,
+		KeyUsage: x509.KeyUsageCertSign |
+			x509.KeyUsageCRLSign |
+			x509.KeyUsageDigitalSignature,
+		IsCA:           true,
+		NotAfter:       time.Now().Add(p.config.RootCertTTL),
+		NotBefore:      time.Now(),
+		AuthorityKeyId: keyId,
+		SubjectKeyId:   keyId,
+	}
+
+	return p.createCert(&template, &template, p.signer.Public())
+}
+
+// ensureKey loads the signing key from the token, generating it first if it
+// doesn't exist yet. It must be called with the sessionLock held.
+func (p *PKCS11Provider) ensureKey() error {
+	if p.ctx == nil {
+		return ErrNotInitialized
+	}
+	err := p.loadKey()
+	if err != ErrNotInitialized {
+		return err
+	}
+	return p.generateKey()
+}
+
+// loadKey looks up the signing key on the token, by ID if one was persisted in
+// the provider state and by label otherwise. It returns ErrNotInitialized when
+// no key exists yet.
+func (p *PKCS11Provider) loadKey() error {
+	if p.signer != nil {
+		return nil
+	}
+
+	template := []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_CLASS, pkcs11.CKO_PRIVATE_KEY),
+		pkcs11.NewAttribute(pkcs11.CKA_LABEL, p.keyLabel),
+	}
+	if p.keyID != nil {
+		template = append(template, pkcs11.NewAttribute(pkcs11.CKA_ID, p.keyID))
+	}
+	objects, err := p.findObjects(template)
+	if err != nil {
+		return err
+	}
+	switch len(objects) {
+	case 0:
+		if p.keyID != nil {
+			return fmt.Errorf("signing key %q with ID %x not found on PKCS#11 token %q", p.keyLabel, p.keyID, p.config.TokenLabel)
+		}
+		return ErrNotInitialized
+	case 1:
+	default:
+		return fmt.Errorf("found %d keys labeled %q on PKCS#11 token %q, use a unique KeyLabel", len(objects), p.keyLabel, p.config.TokenLabel)
+	}
+
+	attrs, err := p.ctx.GetAttributeValue(p.session, objects[0], []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_ID, nil),
+	})
+	if err != nil {
+		return fmt.Errorf("error reading PKCS#11 key ID: %v", err)
+	}
+	keyID := attrs[0].Value
+
+	pub, err := p.readPublicKey(keyID)
+	if err != nil {
+		return err
+	}
+
+	p.keyID = keyID
+	p.signer = &pkcs11Signer{provider: p, key: objects[0], pub: pub}
+	return nil
+}
+
+// generateKey generates the signing key pair on the token. The private key is
+// marked sensitive and non-extractable so it can never be read back.
+func (p *PKCS11Provider) generateKey() error {
+	keyID := make([]byte, 16)
+	if _, err := io.ReadFull(rand.Reader, keyID); err != nil {
+		return err
+	}
+
+	var mech *pkcs11.Mechanism
+	pubTemplate := []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_TOKEN, true),
+		pkcs11.NewAttribute(pkcs11.CKA_VERIFY, true),
+		pkcs11.NewAttribute(pkcs11.CKA_LABEL, p.keyLabel),
+		pkcs11.NewAttribute(pkcs11.CKA_ID, keyID),
+	}
+	switch p.config.PrivateKeyType {
+	case "ec":
+		params, err := ecParamsForBits(p.config.PrivateKeyBits)
+		if err != nil {
+			return err
+		}
+		mech = pkcs11.NewMechanism(pkcs11.CKM_EC_KEY_PAIR_GEN, nil)
+		pubTemplate = append(pubTemplate, pkcs11.NewAttribute(pkcs11.CKA_EC_PARAMS, params))
+	case "rsa":
+		mech = pkcs11.NewMechanism(pkcs11.CKM_RSA_PKCS_KEY_PAIR_GEN, nil)
+		pubTemplate = append(pubTemplate,
+			pkcs11.NewAttribute(pkcs11.CKA_MODULUS_BITS, p.config.PrivateKeyBits),
+			pkcs11.NewAttribute(pkcs11.CKA_PUBLIC_EXPONENT, []byte{1, 0, 1}),
+		)
+	default:
+		return fmt.Errorf("unsupported private key type %q", p.config.PrivateKeyType)
+	}
+	privTemplate := []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_TOKEN, true),
+		pkcs11.NewAttribute(pkcs11.CKA_PRIVATE, true),
+		pkcs11.NewAttribute(pkcs11.CKA_SIGN, true),
+		pkcs11.NewAttribute(pkcs11.CKA_SENSITIVE, true),
+		pkcs11.NewAttribute(pkcs11.CKA_EXTRACTABLE, false),
+		pkcs11.NewAttribute(pkcs11.CKA_LABEL, p.keyLabel),
+		pkcs11.NewAttribute(pkcs11.CKA_ID, keyID),
+	}
+
+	if _, _, err := p.ctx.GenerateKeyPair(p.session, []*pkcs11.Mechanism{mech}, pubTemplate, privTemplate); err != nil {
+		return fmt.Errorf("error generating key on PKCS#11 token %q: %v", p.config.TokenLabel, err)
+	}
+	p.logger.Info("generated CA signing key on PKCS#11 token",
+		"token", p.config.TokenLabel,
+		"key_label", p.keyLabel,
+		"key_id", hex.EncodeToString(keyID),
+	)
+
+	p.keyID = keyID
+	return p.loadKey()
+}
+
+// readPublicKey returns the public key matching the given key ID.
+func (p *PKCS11Provider) readPublicKey(keyID []byte) (crypto.PublicKey, error) {
+	objects, err := p.findObjects([]*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_CLASS, pkcs11.CKO_PUBLIC_KEY),
+		pkcs11.NewAttribute(pkcs11.CKA_ID, keyID),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(objects) != 1 {
+		return nil, fmt.Errorf("expected 1 public key with ID %x on PKCS#11 token %q, found %d", keyID, p.config.TokenLabel, len(objects))
+	}
+
+	attrs, err := p.ctx.GetAttributeValue(p.session, objects[0], []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_KEY_TYPE, nil),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error reading PKCS#11 public key: %v", err)
+	}
+
+	// CKA_KEY_TYPE is a CK_ULONG in native byte order, compare it to the
+	// encoding of the expected values rather than decoding it.
+	keyType := attrs[0].Value
+	switch {
+	case bytes.Equal(keyType, pkcs11.NewAttribute(pkcs11.CKA_KEY_TYPE, pkcs11.CKK_EC).Value):
+		attrs, err := p.ctx.GetAttributeValue(p.session, objects[0], []*pkcs11.Attribute{
+			pkcs11.NewAttribute(pkcs11.CKA_EC_PARAMS, nil),
+			pkcs11.NewAttribute(pkcs11.CKA_EC_POINT, nil),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error reading PKCS#11 public key: %v", err)
+		}
+		return parseECPublicKey(attrs[0].Value, attrs[1].Value)
+	case bytes.Equal(keyType, pkcs11.NewAttribute(pkcs11.CKA_KEY_TYPE, pkcs11.CKK_RSA).Value):
+		attrs, err := p.ctx.GetAttributeValue(p.session, objects[0], []*pkcs11.Attribute{
+			pkcs11.NewAttribute(pkcs11.CKA_MODULUS, nil),
+			pkcs11.NewAttribute(pkcs11.CKA_PUBLIC_EXPONENT, nil),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error reading PKCS#11 public key: %v", err)
+		}
+		return &rsa.PublicKey{
+			N: new(big.Int).SetBytes(attrs[0].Value),
+			E: int(new(big.Int).SetBytes(attrs[1].Value).Int64()),
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported PKCS#11 key type %x", keyType)
+	}
+}
+
+// certTemplate returns the attributes identifying the certificate object
+// stored for the signing key.
+func (p *PKCS11Provider) certTemplate() []*pkcs11.Attribute {
+	return []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_CLASS, pkcs11.CKO_CERTIFICATE),
+		pkcs11.NewAttribute(pkcs11.CKA_CERTIFICATE_TYPE, pkcs11.CKC_X_509),
+		pkcs11.NewAttribute(pkcs11.CKA_LABEL, p.keyLabel),
+		pkcs11.NewAttribute(pkcs11.CKA_ID, p.keyID),
+	}
+}
+
+// readCert returns the PEM encoded CA certificate stored for the signing key,
+// or an empty string if there is none.
+func (p *PKCS11Provider) readCert() (string, error) {
+	if p.ctx == nil {
+		return "", ErrNotInitialized
+	}
+	if p.keyID == nil {
+		return "", nil
+	}
+
+	objects, err := p.findObjects(p.certTemplate())
+	if err != nil {
+		return "", err
+	}
+	if len(objects) == 0 {
+		return "", nil
+	}
+
+	attrs, err := p.ctx.GetAttributeValue(p.session, objects[0], []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_VALUE, nil),
+	})
+	if err != nil {
+		return "", fmt.Errorf("error reading PKCS#11 certificate: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := pem.Encode(&buf, &pem.Block{Type: "CERTIFICATE", Bytes: attrs[0].Value}); err != nil {
+		return "", fmt.Errorf("error encoding certificate: %s", err)
+	}
+	return buf.String(), nil
+}
+
+// writeCert stores the given CA certificate for the signing key on the token,
+// replacing the previous one.
+func (p *PKCS11Provider) writeCert(certPEM string) error {
+	cert, err := connect.ParseCert(certPEM)
+	if err != nil {
+		return err
+	}
+
+	existing, err := p.findObjects(p.certTemplate())
+	if err != nil {
+		return err
+	}
+
+	template := append(p.certTemplate(),
+		pkcs11.NewAttribute(pkcs11.CKA_TOKEN, true),
+		pkcs11.NewAttribute(pkcs11.CKA_SUBJECT, cert.RawSubject),
+		pkcs11.NewAttribute(pkcs11.CKA_ISSUER, cert.RawIssuer),
+		pkcs11.NewAttribute(pkcs11.CKA_VALUE, cert.Raw),
+	)
+	if _, err := p.ctx.CreateObject(p.session, template); err != nil {
+		return fmt.Errorf("error storing certificate on PKCS#11 token %q: %v", p.config.TokenLabel, err)
+	}
+
+	for _, obj := range existing {
+		if err := p.ctx.DestroyObject(p.session, obj); err != nil {
+			return fmt.Errorf("error removing previous certificate from PKCS#11 token %q: %v", p.config.TokenLabel, err)
+		}
+	}
+	return nil
+}
+
+func (p *PKCS11Provider) findObjects(template []*pkcs11.Attribute) ([]pkcs11.ObjectHandle, error) {
+	if err := p.ctx.FindObjectsInit(p.session, template); err != nil {
+		return nil, fmt.Errorf("error searching PKCS#11 token %q: %v", p.config.TokenLabel, err)
+	}
+	defer p.ctx.FindObjectsFinal(p.session)
+
+	var result []pkcs11.ObjectHandle
+	for {
+		objects, _, err := p.ctx.FindObjects(p.session, 16)
+		if err != nil {
+			return nil, fmt.Errorf("error searching PKCS#11 token %q: %v", p.config.TokenLabel, err)
+		}
+		if len(objects) == 0 {
+			return result, nil
+		}
+		result = append(result, objects...)
+	}
+}
+
+// pkcs11Signer implements crypto.Signer with a private key held by a PKCS#11
+// token. It must only be used with the provider's sessionLock held.
+type pkcs11Signer struct {
+	provider *PKCS11Provider
+	key      pkcs11.ObjectHandle
+	pub      crypto.PublicKey
+}
+
+// Public implements crypto.Signer
+func (s *pkcs11Signer) Public() crypto.PublicKey {
+	return s.pub
+}
+
+// Sign implements crypto.Signer
+func (s *pkcs11Signer) Sign(_ io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	ctx, session := s.provider.ctx, s.provider.session
+	if ctx == nil {
+		return nil, ErrNotInitialized
+	}
+
+	switch s.pub.(type) {
+	case *ecdsa.PublicKey:
+		if err := ctx.SignInit(session, []*pkcs11.Mechanism{pkcs11.NewMechanism(pkcs11.CKM_ECDSA, nil)}, s.key); err != nil {
+			return nil, err
+		}
+		sig, err := ctx.Sign(session, digest)
+		if err != nil {
+			return nil, err
+		}
+		return encodeECDSASignature(sig)
+	case *rsa.PublicKey:
+		if _, ok := opts.(*rsa.PSSOptions); ok {
+			return nil, fmt.Errorf("RSA-PSS signatures are not supported")
+		}
+		prefix, ok := rsaDigestInfoPrefixes[opts.HashFunc()]
+		if !ok {
+			return nil, fmt.Errorf("unsupported hash function %v", opts.HashFunc())
+		}
+		if err := ctx.SignInit(session, []*pkcs11.Mechanism{pkcs11.NewMechanism(pkcs11.CKM_RSA_PKCS, nil)}, s.key); err != nil {
+			return nil, err
+		}
+		return ctx.Sign(session, append(append([]byte{}, prefix...), digest...))
+	default:
+		return nil, fmt.Errorf("unsupported key type %T", s.pub)
+	}
+}
+
+// rsaDigestInfoPrefixes are the DER encoded DigestInfo prefixes CKM_RSA_PKCS
+// expects in front of the digest, see RFC 8017 section 9.2.
+var rsaDigestInfoPrefixes = map[crypto.Hash][]byte{
+	crypto.SHA256: {0x30, 0x31, 0x30, 0x0d, 0x06, 0x09, 0x60, 0x86, 0x48, 0x01, 0x65, 0x03, 0x04, 0x02, 0x01, 0x05, 0x00, 0x04, 0x20},
+	crypto.SHA384: {0x30, 0x41, 0x30, 0x0d, 0x06, 0x09, 0x60, 0x86, 0x48, 0x01, 0x65, 0x03, 0x04, 0x02, 0x02, 0x05, 0x00, 0x04, 0x30},
+	crypto.SHA512: {0x30, 0x51, 0x30, 0x0d, 0x06, 0x09, 0x60, 0x86, 0x48, 0x01, 0x65, 0x03, 0x04, 0x02, 0x03, 0x05, 0x00, 0x04, 0x40},
+}
+
+var ecCurveOIDs = map[int]asn1.ObjectIdentifier{
+	256: {1, 2, 840, 10045, 3, 1, 7},
+	384: {1, 3, 132, 0, 34},
+	521: {1, 3, 132, 0, 35},
+}
+
+var ecCurves = map[int]elliptic.Curve{
+	256: elliptic.P256(),
+	384: elliptic.P384(),
+	521: elliptic.P521(),
+}
+
+// ecParamsForBits returns the DER encoded CKA_EC_PARAMS of the named curve
+// with the given size.
+func ecParamsForBits(bits int) ([]byte, error) {
+	oid, ok := ecCurveOIDs[bits]
+	if !ok {
+		return nil, fmt.Errorf("unsupported EC key length %d", bits)
+	}
+	return asn1.Marshal(oid)
+}
+
+// parseECPublicKey parses the CKA_EC_PARAMS and CKA_EC_POINT attributes of an
+// EC public key.
+func parseECPublicKey(params, point []byte) (*ecdsa.PublicKey, error) {
+	var oid asn1.ObjectIdentifier
+	if _, err := asn1.Unmarshal(params, &oid); err != nil {
+		return nil, fmt.Errorf("error parsing EC params: %v", err)
+	}
+	var curve elliptic.Curve
+	for bits, curveOID := range ecCurveOIDs {
+		if oid.Equal(curveOID) {
+			curve = ecCurves[bits]
+		}
+	}
+	if curve == nil {
+		return nil, fmt.Errorf("unsupported EC curve %s", oid)
+	}
+
+	// CKA_EC_POINT is a DER encoded OCTET STRING, though some modules return
+	// the raw point.
+	var raw []byte
+	if rest, err := asn1.Unmarshal(point, &raw); err != nil || len(rest) > 0 {
+		raw = point
+	}
+	x, y := elliptic.Unmarshal(curve, raw)
+	if x == nil {
+		return nil, fmt.Errorf("error parsing EC point")
+	}
+	return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+}
+
+// encodeECDSASignature converts the r || s signature returned by CKM_ECDSA to
+// the ASN.1 encoding used by x509.
+func encodeECDSASignature(sig []byte) ([]byte, error) {
+	if len(sig) == 0 || len(sig)%2 != 0 {
+		return nil, fmt.Errorf("invalid ECDSA signature length %d", len(sig))
+	}
+	half := len(sig) / 2
+	return asn1.Marshal(struct {
+		R, S *big.Int
+	}{
+		R: new(big.Int).SetBytes(sig[:half]),
+		S: new(big.Int).SetBytes(sig[half:]),
+	})
+}
+
+// publicKeysEqual returns whether both keys are the same.
+func publicKeysEqual(a, b crypto.PublicKey) bool {
+	aID, err := connect.KeyId(a)
+	if err != nil {
+		return false
+	}
+	bID, err := connect.KeyId(b)
+	if err != nil {
+		return false
+	}
+	return bytes.Equal(aID, bID)
+}
+
+// pkcs11SerialNumber returns a random 128 bit serial number. Unlike the
+// built-in provider there is no counter in the state store to draw from.
+func pkcs11SerialNumber() (*big.Int, error) {
+	return rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+}
+
+// loadPKCS11Module loads and initializes the PKCS#11 module at the given path,
+// reusing it if it was already loaded.
+func loadPKCS11Module(path string) (*pkcs11.Ctx, error) {
+	pkcs11ModulesLock.Lock()
+	defer pkcs11ModulesLock.Unlock()
+
+	if ctx, ok := pkcs11Modules[path]; ok {
+		return ctx, nil
+	}
+
+	ctx := pkcs11.New(path)
+	if ctx == nil {
+		return nil, fmt.Errorf("failed to load PKCS#11 module %q", path)
+	}
+	if err := ctx.Initialize(); err != nil && !errors.Is(err, pkcs11.Error(pkcs11.CKR_CRYPTOKI_ALREADY_INITIALIZED)) {
+		ctx.Destroy()
+		return nil, fmt.Errorf("failed to initialize PKCS#11 module %q: %v", path, err)
+	}
+	pkcs11Modules[path] = ctx
+	return ctx, nil
+}
+
+// findPKCS11Slot returns the slot holding the token with the given label.
+func findPKCS11Slot(ctx *pkcs11.Ctx, tokenLabel string) (uint, error) {
+	slots, err := ctx.GetSlotList(true)
+	if err != nil {
+		return 0, fmt.Errorf("error listing PKCS#11 slots: %v", err)
+	}
+	for _, slot := range slots {
+		info, err := ctx.GetTokenInfo(slot)
+		if err != nil {
+			return 0, fmt.Errorf("error reading PKCS#11 token info: %v", err)
+		}
+		if strings.TrimSpace(info.Label) == tokenLabel {
+			return slot, nil
+		}
+	}
+	return 0, fmt.Errorf("no PKCS#11 token labeled %q found", tokenLabel)
+}
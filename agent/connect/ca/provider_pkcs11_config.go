@@ -0,0 +1,53 @@
+package ca
+
+import (
+	"fmt"
+
+	"github.com/mitchellh/mapstructure"
+
+	"github.com/hashicorp/consul/agent/structs"
+)
+
+// ParsePKCS11CAConfig parses and validates PKCS#11 CA Provider configuration.
+func ParsePKCS11CAConfig(raw map[string]interface{}) (*structs.PKCS11CAProviderConfig, error) {
+	config := structs.PKCS11CAProviderConfig{
+		CommonCAProviderConfig: defaultCommonConfig(),
+	}
+
+	decodeConf := &mapstructure.DecoderConfig{
+		DecodeHook:       structs.ParseDurationFunc(),
+		Result:           &config,
+		WeaklyTypedInput: true,
+	}
+
+	decoder, err := mapstructure.NewDecoder(decodeConf)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := decoder.Decode(raw); err != nil {
+		return nil, fmt.Errorf("error decoding config: %s", err)
+	}
+
+	if config.LibPath == "" {
+		return nil, fmt.Errorf("must provide the path to a PKCS#11 module")
+	}
+	if config.TokenLabel == "" {
+		return nil, fmt.Errorf("must provide the label of the PKCS#11 token")
+	}
+	if config.PIN == "" {
+		return nil, fmt.Errorf("must provide the PIN of the PKCS#11 token")
+	}
+
+	if err := config.CommonCAProviderConfig.Validate(); err != nil {
+		return nil, err
+	}
+
+	// PKCS#11 modules commonly only support the named NIST curves from 256 bits
+	// upwards, so be stricter than the other providers here.
+	if config.PrivateKeyType == "ec" && config.PrivateKeyBits == 224 {
+		return nil, fmt.Errorf("EC key length must be one of (256, 384, 521) bits for the PKCS#11 provider")
+	}
+
+	return &config, nil
+}
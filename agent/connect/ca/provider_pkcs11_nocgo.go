@@ -0,0 +1,16 @@
+//go:build !cgo
+// +build !cgo
+
+package ca
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/go-hclog"
+)
+
+// NewPKCS11Provider returns an error since talking to a PKCS#11 module
+// requires loading it through cgo.
+func NewPKCS11Provider(_ hclog.Logger) (Provider, error) {
+	return nil, fmt.Errorf("the PKCS#11 CA provider requires a build of Consul with cgo enabled")
+}
@@ -0,0 +1,212 @@
+//go:build cgo
+// +build cgo
+
+package ca
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/asn1"
+	"fmt"
+	"io/ioutil"
+	"testing"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hashicorp/consul/agent/connect"
+)
+
+func TestPKCS11CAProvider_ParsePKCS11CAConfig(t *testing.T) {
+	base := func() map[string]interface{} {
+		return map[string]interface{}{
+			"LibPath":    "/usr/lib/softhsm/libsofthsm2.so",
+			"TokenLabel": "consul",
+			"PIN":        "1234",
+		}
+	}
+
+	cases := map[string]struct {
+		modify    func(map[string]interface{})
+		expectErr string
+	}{
+		"valid": {
+			modify: func(map[string]interface{}) {},
+		},
+		"no lib path": {
+			modify:    func(c map[string]interface{}) { delete(c, "LibPath") },
+			expectErr: "must provide the path to a PKCS#11 module",
+		},
+		"no token label": {
+			modify:    func(c map[string]interface{}) { delete(c, "TokenLabel") },
+			expectErr: "must provide the label of the PKCS#11 token",
+		},
+		"no pin": {
+			modify:    func(c map[string]interface{}) { delete(c, "PIN") },
+			expectErr: "must provide the PIN of the PKCS#11 token",
+		},
+		"unsupported curve": {
+			modify: func(c map[string]interface{}) {
+				c["PrivateKeyType"] = "ec"
+				c["PrivateKeyBits"] = 224
+			},
+			expectErr: "EC key length must be one of (256, 384, 521) bits for the PKCS#11 provider",
+		},
+	}
+
+	for name, tc := range cases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			raw := base()
+			tc.modify(raw)
+			config, err := ParsePKCS11CAConfig(raw)
+			if tc.expectErr != "" {
+				require.EqualError(t, err, tc.expectErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, "consul", config.TokenLabel)
+			require.Equal(t, connect.DefaultPrivateKeyType, config.PrivateKeyType)
+		})
+	}
+}
+
+func TestPKCS11CAProvider_ECDSAEncoding(t *testing.T) {
+	for bits, curve := range ecCurves {
+		curve := curve
+		t.Run(fmt.Sprintf("P-%d", bits), func(t *testing.T) {
+			key, err := ecdsa.GenerateKey(curve, rand.Reader)
+			require.NoError(t, err)
+
+			// The public key is read back from CKA_EC_PARAMS and CKA_EC_POINT.
+			params, err := ecParamsForBits(bits)
+			require.NoError(t, err)
+			point, err := asn1.Marshal(elliptic.Marshal(curve, key.X, key.Y))
+			require.NoError(t, err)
+			pub, err := parseECPublicKey(params, point)
+			require.NoError(t, err)
+			require.True(t, key.PublicKey.Equal(pub))
+
+			// Signatures are returned by CKM_ECDSA as r || s.
+			digest := sha256.Sum256([]byte("hello"))
+			r, s, err := ecdsa.Sign(rand.Reader, key, digest[:])
+			require.NoError(t, err)
+			size := (curve.Params().BitSize + 7) / 8
+			raw := make([]byte, 2*size)
+			r.FillBytes(raw[:size])
+			s.FillBytes(raw[size:])
+
+			sig, err := encodeECDSASignature(raw)
+			require.NoError(t, err)
+			require.True(t, ecdsa.VerifyASN1(&key.PublicKey, digest[:], sig))
+		})
+	}
+}
+
+func testPKCS11Config(t *testing.T, token, keyType string, keyBits int) map[string]interface{} {
+	raw := NewTestSoftHSMToken(t, token)
+	raw["PrivateKeyType"] = keyType
+	raw["PrivateKeyBits"] = keyBits
+	return raw
+}
+
+func testPKCS11Provider(t *testing.T, raw map[string]interface{}, isPrimary bool, state map[string]string) Provider {
+	logger := hclog.New(&hclog.LoggerOptions{Output: ioutil.Discard})
+	provider, err := NewPKCS11Provider(logger)
+	require.NoError(t, err)
+
+	cfg := ProviderConfig{
+		ClusterID:  connect.TestClusterID,
+		Datacenter: "dc1",
+		IsPrimary:  isPrimary,
+		RawConfig:  raw,
+		State:      state,
+	}
+	if !isPrimary {
+		cfg.Datacenter = "dc2"
+	}
+	require.NoError(t, provider.Configure(cfg))
+	t.Cleanup(provider.(NeedsStop).Stop)
+	return provider
+}
+
+func TestPKCS11CAProvider_SignLeaf(t *testing.T) {
+	for i, tc := range KeyTestCases {
+		tc := tc
+		token := fmt.Sprintf("sign-leaf-%d", i)
+		t.Run(tc.Desc, func(t *testing.T) {
+			conf := testPKCS11Config(t, token, tc.KeyType, tc.KeyBits)
+			provider := testPKCS11Provider(t, conf, true, nil)
+
+			root, err := provider.GenerateRoot()
+			require.NoError(t, err)
+
+			// Only the identifier of the key is persisted.
+			state, err := provider.State()
+			require.NoError(t, err)
+			require.Len(t, state, 3)
+			require.NotEmpty(t, state[PKCS11StateKeyIDKey])
+			require.Equal(t, "consul-connect-ca-dc1", state[PKCS11StateKeyLabelKey])
+			require.Equal(t, token, state[PKCS11StateTokenLabelKey])
+
+			spiffeService := &connect.SpiffeIDService{
+				Host:       connect.TestClusterID + ".consul",
+				Namespace:  "default",
+				Datacenter: "dc1",
+				Service:    "foo",
+			}
+			raw, _ := connect.TestCSR(t, spiffeService)
+			csr, err := connect.ParseCSR(raw)
+			require.NoError(t, err)
+
+			leafPEM, err := provider.Sign(csr)
+			require.NoError(t, err)
+			require.NoError(t, connect.ValidateLeaf(root.PEM, leafPEM, nil))
+
+			leaf, err := connect.ParseCert(leafPEM)
+			require.NoError(t, err)
+			require.Equal(t, spiffeService.URI(), leaf.URIs[0])
+
+			// A new provider instance configured with the persisted state
+			// uses the same key and root.
+			provider2 := testPKCS11Provider(t, conf, true, state)
+			root2, err := provider2.GenerateRoot()
+			require.NoError(t, err)
+			require.Equal(t, root.PEM, root2.PEM)
+		})
+	}
+}
+
+func TestPKCS11CAProvider_CrossSignCA(t *testing.T) {
+	for i, tc := range CASigningKeyTypeCases() {
+		tc := tc
+		token := fmt.Sprintf("cross-sign-%d", i)
+		t.Run(tc.Desc, func(t *testing.T) {
+			provider1 := testPKCS11Provider(t, testPKCS11Config(t, token, tc.SigningKeyType, tc.SigningKeyBits), true, nil)
+			_, err := provider1.GenerateRoot()
+			require.NoError(t, err)
+
+			provider2 := testPKCS11Provider(t, testPKCS11Config(t, token+"-new", tc.CSRKeyType, tc.CSRKeyBits), true, nil)
+
+			testCrossSignProviders(t, provider1, provider2)
+		})
+	}
+}
+
+func TestPKCS11CAProvider_SignIntermediate(t *testing.T) {
+	for i, tc := range CASigningKeyTypeCases() {
+		tc := tc
+		token := fmt.Sprintf("sign-intermediate-%d", i)
+		t.Run(tc.Desc, func(t *testing.T) {
+			provider1 := testPKCS11Provider(t, testPKCS11Config(t, token, tc.SigningKeyType, tc.SigningKeyBits), true, nil)
+			_, err := provider1.GenerateRoot()
+			require.NoError(t, err)
+
+			provider2 := testPKCS11Provider(t, testPKCS11Config(t, token+"-secondary", tc.CSRKeyType, tc.CSRKeyBits), false, nil)
+
+			testSignIntermediateCrossDC(t, provider1, provider2)
+		})
+	}
+}
@@ -105,6 +105,89 @@ func SkipIfVaultNotPresent(t testing.T) {
 	}
 }
 
+var (
+	softHSMOnce    sync.Once
+	softHSMLibPath string
+	softHSMErr     error
+)
+
+// softHSMLibPaths are the usual install locations of the SoftHSM v2 module.
+var softHSMLibPaths = []string{
+	"/usr/lib/softhsm/libsofthsm2.so",
+	"/usr/local/lib/softhsm/libsofthsm2.so",
+	"/usr/lib/x86_64-linux-gnu/softhsm/libsofthsm2.so",
+	"/usr/local/opt/softhsm/lib/softhsm/libsofthsm2.so",
+}
+
+// SkipIfSoftHSMNotPresent skips the test if softhsm2-util is not in PATH or
+// the SoftHSM module can't be found. The module path can be set with the
+// SOFTHSM2_LIB environment variable.
+func SkipIfSoftHSMNotPresent(t testing.T) {
+	if path, err := exec.LookPath("softhsm2-util"); err != nil || path == "" {
+		t.Skip("softhsm2-util not found on $PATH - download and install SoftHSM v2 to run this test")
+	}
+	if softHSMModulePath() == "" {
+		t.Skip("SoftHSM v2 module not found - set SOFTHSM2_LIB to run this test")
+	}
+}
+
+func softHSMModulePath() string {
+	if path := os.Getenv("SOFTHSM2_LIB"); path != "" {
+		return path
+	}
+	for _, path := range softHSMLibPaths {
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return ""
+}
+
+// NewTestSoftHSMToken initializes a new SoftHSM token with the given label
+// and returns the CA provider configuration needed to use it. The SoftHSM
+// configuration is shared by the whole test process since the module reads it
+// only once when it is loaded.
+func NewTestSoftHSMToken(t testing.T, label string) map[string]interface{} {
+	SkipIfSoftHSMNotPresent(t)
+
+	softHSMOnce.Do(func() {
+		dir, err := ioutil.TempDir("", "consul-softhsm")
+		if err != nil {
+			softHSMErr = err
+			return
+		}
+		tokenDir := dir + "/tokens"
+		if err := os.Mkdir(tokenDir, 0700); err != nil {
+			softHSMErr = err
+			return
+		}
+		conf := dir + "/softhsm2.conf"
+		content := fmt.Sprintf("directories.tokendir = %s\nobjectstore.backend = file\n", tokenDir)
+		if err := ioutil.WriteFile(conf, []byte(content), 0600); err != nil {
+			softHSMErr = err
+			return
+		}
+		softHSMErr = os.Setenv("SOFTHSM2_CONF", conf)
+		softHSMLibPath = softHSMModulePath()
+	})
+	if softHSMErr != nil {
+		t.Fatalf("err: %v", softHSMErr)
+	}
+
+	pin := "1234"
+	cmd := exec.Command("softhsm2-util", "--init-token", "--free",
+		"--label", label, "--pin", pin, "--so-pin", pin)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("failed to initialize SoftHSM token: %v: %s", err, out)
+	}
+
+	return map[string]interface{}{
+		"LibPath":    softHSMLibPath,
+		"TokenLabel": label,
+		"PIN":        pin,
+	}
+}
+
 func NewTestVaultServer(t testing.T) *TestVaultServer {
 	testVault, err := runTestVault(t)
 	if err != nil {
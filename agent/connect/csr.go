@@ -19,7 +19,9 @@ import (
 // ECDSAWithSHA256 on the basis that it will fail anyway and we've already type
 // checked keys by the time we call this in general.
 func SigAlgoForKey(key crypto.Signer) x509.SignatureAlgorithm {
-	if _, ok := key.(*rsa.PrivateKey); ok {
+	// Check the public key rather than the concrete signer type so that signers
+	// backed by an HSM are handled too.
+	if _, ok := key.Public().(*rsa.PublicKey); ok {
 		return x509.SHA256WithRSA
 	}
 	// We default to ECDSA but don't bother detecting invalid key types as we do
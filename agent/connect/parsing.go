@@ -223,3 +223,17 @@ func KeyInfoFromCert(cert *x509.Certificate) (keyType string, keyBits int, err e
 		return "", 0, fmt.Errorf("unsupported key type")
 	}
 }
+
+// ParseSerialNumber parses a serial number encoded as hex, optionally
+// separated by colons as produced by EncodeSerialNumber.
+func ParseSerialNumber(input string) (*big.Int, error) {
+	raw := strings.Replace(strings.TrimSpace(input), ":", "", -1)
+	if raw == "" {
+		return nil, fmt.Errorf("serial number is empty")
+	}
+	serial, ok := new(big.Int).SetString(raw, 16)
+	if !ok || serial.Sign() < 0 {
+		return nil, fmt.Errorf("invalid serial number %q", input)
+	}
+	return serial, nil
+}
@@ -63,6 +63,14 @@ func ServiceSNI(service string, subset string, namespace string, partition strin
 	}
 }
 
+// DestinationSNI returns the SNI used to reach the given address of a
+// service-defaults destination through a terminating gateway. Dots and colons
+// in the address are replaced so that it fits in a single SNI label.
+func DestinationSNI(address string, service string, namespace string, partition string, datacenter string, trustDomain string) string {
+	label := strings.NewReplacer(".", "-", ":", "-").Replace(address)
+	return dotJoin("destination", label, ServiceSNI(service, "", namespace, partition, datacenter, trustDomain))
+}
+
 func dotJoin(parts ...string) string {
 	return strings.Join(parts, ".")
 }
@@ -169,6 +169,16 @@ func TestQuerySNI(t *testing.T) {
 		QuerySNI("magicquery", "foo", testTrustDomain1))
 }
 
+func TestDestinationSNI(t *testing.T) {
+	// hostname address
+	require.Equal(t, "destination.api-example-com.api.default.foo."+testTrustDomainSuffix1,
+		DestinationSNI("api.example.com", "api", "", "", "foo", testTrustDomain1))
+
+	// ipv6 address, set partition
+	require.Equal(t, "destination.2001-db8--1.api.neighbor.part1.foo."+testTrustDomainSuffix2WithPart,
+		DestinationSNI("2001:db8::1", "api", "neighbor", "part1", "foo", testTrustDomain2))
+}
+
 func TestTargetSNI(t *testing.T) {
 	// empty namespace, empty subset
 	require.Equal(t, "api.default.foo."+testTrustDomainSuffix1,
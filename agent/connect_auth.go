@@ -18,7 +18,8 @@ import (
 // a separate agent method here because we need to re-use this both in our own
 // HTTP API authz endpoint and in the gRPX xDS/ext_authz API for envoy.
 //
-// NOTE: This treats any L7 intentions as DENY.
+// NOTE: This treats any L7 intentions as DENY unless the request includes the
+// HTTP attributes to evaluate their permissions against.
 //
 // The ACL token and the auth request are provided and the auth decision (true
 // means authorized) and reason string are returned.
@@ -129,9 +130,21 @@ func (a *Agent) ConnectAuthorize(token string,
 			return auth, reason, &meta, nil
 		}
 
-		// This is an L7 intention, so DENY.
-		reason = fmt.Sprintf("Matched L7 intention: %s", ixnMatch.String())
-		return false, reason, &meta, nil
+		if req.HTTP == nil {
+			// This is an L7 intention and there is no request to evaluate its
+			// permissions against, so DENY.
+			reason = fmt.Sprintf("Matched L7 intention: %s", ixnMatch.String())
+			return false, reason, &meta, nil
+		}
+
+		// The first permission matching the request decides. Requests no
+		// permission matches get the default behavior, like they do in Envoy.
+		for i, perm := range ixnMatch.Permissions {
+			if perm.MatchesHTTP(req.HTTP) {
+				reason = fmt.Sprintf("Matched L7 intention: %s, permission %d", ixnMatch.String(), i)
+				return perm.Action == structs.IntentionActionAllow, reason, &meta, nil
+			}
+		}
 	}
 
 	reason = "Default behavior configured by ACLs"
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/hashicorp/consul/agent/consul"
 	"github.com/hashicorp/consul/agent/structs"
@@ -105,3 +106,96 @@ func (s *HTTPHandlers) ConnectCAConfigurationSet(req *http.Request) (interface{}
 	}
 	return nil, err
 }
+
+// /v1/connect/ca/crl
+func (s *HTTPHandlers) ConnectCARevocations(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
+	switch req.Method {
+	case "GET":
+		return s.ConnectCARevocationList(resp, req)
+
+	case "PUT":
+		return s.ConnectCARevoke(req)
+
+	default:
+		return nil, MethodNotAllowedError{req.Method, []string{"GET", "PUT"}}
+	}
+}
+
+// GET /v1/connect/ca/crl
+func (s *HTTPHandlers) ConnectCARevocationList(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
+	// Method is tested in ConnectCARevocations
+	var args structs.DCSpecificRequest
+	if done := s.parse(resp, req, &args.Datacenter, &args.QueryOptions); done {
+		return nil, nil
+	}
+
+	pemResponse := false
+	if pemParam := req.URL.Query().Get("pem"); pemParam != "" {
+		val, err := strconv.ParseBool(pemParam)
+		if err != nil {
+			return nil, BadRequestError{Reason: "The 'pem' query parameter must be a boolean value"}
+		}
+		pemResponse = val
+	}
+
+	var reply structs.IndexedCARevocations
+	defer setMeta(resp, &reply.QueryMeta)
+	if err := s.agent.RPC("ConnectCA.RevocationList", &args, &reply); err != nil {
+		return nil, err
+	}
+
+	if !pemResponse {
+		return reply, nil
+	}
+
+	resp.Header().Set("Content-Type", "application/x-pem-file")
+	for _, list := range reply.RevocationLists {
+		if _, err := resp.Write([]byte(list.PEM)); err != nil {
+			return nil, err
+		}
+	}
+	return nil, nil
+}
+
+// PUT /v1/connect/ca/crl
+func (s *HTTPHandlers) ConnectCARevoke(req *http.Request) (interface{}, error) {
+	// Method is tested in ConnectCARevocations
+	var args structs.CARevocationRequest
+	s.parseDC(req, &args.Datacenter)
+	s.parseToken(req, &args.Token)
+	if err := decodeBody(req.Body, &args.Revocation); err != nil {
+		return nil, BadRequestError{
+			Reason: fmt.Sprintf("Request decode failed: %v", err),
+		}
+	}
+	if args.Revocation.SerialNumber != "" && args.Revocation.Datacenter == "" {
+		args.Revocation.Datacenter = args.Datacenter
+	}
+	if err := args.Revocation.Validate(); err != nil {
+		return nil, BadRequestError{Reason: err.Error()}
+	}
+
+	var id string
+	if err := s.agent.RPC("ConnectCA.Revoke", &args, &id); err != nil {
+		return nil, err
+	}
+	return struct{ ID string }{id}, nil
+}
+
+// DELETE /v1/connect/ca/crl/:id
+func (s *HTTPHandlers) ConnectCARevocationDelete(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
+	var args structs.CARevocationRequest
+	s.parseDC(req, &args.Datacenter)
+	s.parseToken(req, &args.Token)
+
+	args.Revocation.ID = strings.TrimPrefix(req.URL.Path, "/v1/connect/ca/crl/")
+	if args.Revocation.ID == "" {
+		return nil, BadRequestError{Reason: "Missing revocation ID"}
+	}
+
+	var reply interface{}
+	if err := s.agent.RPC("ConnectCA.RevocationDelete", &args, &reply); err != nil {
+		return nil, err
+	}
+	return true, nil
+}
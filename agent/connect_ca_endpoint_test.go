@@ -299,3 +299,50 @@ func TestConnectCARoots_PEMEncoding(t *testing.T) {
 	_, err = x509.ParseCertificate(block.Bytes)
 	require.NoError(t, err)
 }
+
+func TestConnectCARevocations(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+
+	a := NewTestAgent(t, "")
+	defer a.Shutdown()
+	testrpc.WaitForTestAgent(t, a.RPC, "dc1")
+
+	// A serial number without a datacenter is scoped to the agent's.
+	body := bytes.NewBufferString(`{"SerialNumber": "0a:1b", "Reason": "key compromise"}`)
+	req, _ := http.NewRequest("PUT", "/v1/connect/ca/crl", body)
+	resp := httptest.NewRecorder()
+	obj, err := a.srv.ConnectCARevocations(resp, req)
+	require.NoError(t, err)
+	id := obj.(struct{ ID string }).ID
+	require.NotEmpty(t, id)
+
+	// Both a serial number and a SPIFFE ID is rejected.
+	body = bytes.NewBufferString(`{"SerialNumber": "0a:1b", "SpiffeID": "spiffe://foo.consul/ns/default/dc/dc1/svc/web"}`)
+	req, _ = http.NewRequest("PUT", "/v1/connect/ca/crl", body)
+	_, err = a.srv.ConnectCARevocations(httptest.NewRecorder(), req)
+	require.Error(t, err)
+	require.IsType(t, BadRequestError{}, err)
+
+	req, _ = http.NewRequest("GET", "/v1/connect/ca/crl", nil)
+	obj, err = a.srv.ConnectCARevocations(httptest.NewRecorder(), req)
+	require.NoError(t, err)
+	value := obj.(structs.IndexedCARevocations)
+	require.Len(t, value.Revocations, 1)
+	require.Equal(t, id, value.Revocations[0].ID)
+	require.Equal(t, "0a:1b", value.Revocations[0].SerialNumber)
+	require.Equal(t, "dc1", value.Revocations[0].Datacenter)
+	require.Equal(t, "key compromise", value.Revocations[0].Reason)
+
+	req, _ = http.NewRequest("DELETE", "/v1/connect/ca/crl/"+id, nil)
+	_, err = a.srv.ConnectCARevocationDelete(httptest.NewRecorder(), req)
+	require.NoError(t, err)
+
+	req, _ = http.NewRequest("GET", "/v1/connect/ca/crl", nil)
+	obj, err = a.srv.ConnectCARevocations(httptest.NewRecorder(), req)
+	require.NoError(t, err)
+	require.Empty(t, obj.(structs.IndexedCARevocations).Revocations)
+}
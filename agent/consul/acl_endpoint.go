@@ -23,6 +23,7 @@ import (
 	"github.com/hashicorp/consul/agent/consul/state"
 	"github.com/hashicorp/consul/agent/structs"
 	"github.com/hashicorp/consul/lib"
+	"github.com/hashicorp/consul/lib/stringslice"
 	"github.com/hashicorp/consul/lib/template"
 )
 
@@ -37,6 +38,10 @@ var ACLEndpointSummaries = []prometheus.SummaryDefinition{
 		Name: []string{"acl", "token", "clone"},
 		Help: "",
 	},
+	{
+		Name: []string{"acl", "token", "derive"},
+		Help: "",
+	},
 	{
 		Name: []string{"acl", "token", "upsert"},
 		Help: "",
@@ -494,6 +499,218 @@ func (a *ACL) TokenClone(args *structs.ACLTokenSetRequest, reply *structs.ACLTok
 	return a.tokenSetInternal(&cloneReq, reply, false)
 }
 
+// TokenDerive creates a short-lived child token from the token used to
+// authorize the request. The child can only be linked to a subset of the
+// parent's policies and identities, so its privileges can never exceed those
+// of the parent.
+func (a *ACL) TokenDerive(args *structs.ACLTokenDeriveRequest, reply *structs.ACLToken) error {
+	if err := a.aclPreCheck(); err != nil {
+		return err
+	}
+
+	if err := a.srv.validateEnterpriseRequest(&args.ACLToken.EnterpriseMeta, true); err != nil {
+		return err
+	}
+
+	// clients will not know whether the server has local token store. In the case
+	// where it doesn't we will transparently forward requests.
+	if !a.srv.LocalTokensEnabled() {
+		args.Datacenter = a.srv.config.PrimaryDatacenter
+	}
+
+	if done, err := a.srv.ForwardRPC("ACL.TokenDerive", args, reply); done {
+		return err
+	}
+
+	defer metrics.MeasureSince([]string{"acl", "token", "derive"}, time.Now())
+
+	if args.Token == "" {
+		return acl.ErrPermissionDenied
+	}
+
+	state := a.srv.fsm.State()
+
+	_, parent, err := state.ACLTokenGetBySecret(nil, args.Token, nil)
+	if err != nil {
+		return err
+	} else if parent == nil || parent.IsExpired(time.Now()) {
+		return acl.ErrNotFound
+	} else if revoked, err := a.srv.isDerivedTokenRevoked(parent); err != nil {
+		return err
+	} else if revoked {
+		return acl.ErrNotFound
+	} else if !a.srv.InPrimaryDatacenter() && !parent.Local {
+		// global token writes must be forwarded to the primary DC
+		args.Datacenter = a.srv.config.PrimaryDatacenter
+		return a.srv.forwardDC("ACL.TokenDerive", a.srv.config.PrimaryDatacenter, args, reply)
+	}
+
+	if parent.AccessorID == structs.ACLTokenAnonymousID {
+		return fmt.Errorf("Cannot derive a token from the anonymous token")
+	}
+
+	if parent.Rules != "" {
+		return fmt.Errorf("Cannot derive a token from a legacy ACL")
+	}
+
+	child := args.ACLToken
+
+	if child.AccessorID != "" || child.SecretID != "" {
+		return fmt.Errorf("AccessorID and SecretID cannot be specified for a derived token")
+	}
+
+	if len(child.Roles) > 0 {
+		return fmt.Errorf("Roles cannot be linked to a derived token")
+	}
+
+	if child.HasExpirationTime() {
+		return fmt.Errorf("ExpirationTime cannot be specified for a derived token, use ExpirationTTL instead")
+	}
+
+	if child.ExpirationTTL <= 0 {
+		return fmt.Errorf("ExpirationTTL is required for a derived token")
+	}
+
+	if parent.HasExpirationTime() && time.Now().Add(child.ExpirationTTL).After(*parent.ExpirationTime) {
+		return fmt.Errorf("Derived token cannot outlive its parent token which expires at %s", parent.ExpirationTime)
+	}
+
+	if len(child.Policies) == 0 && len(child.ServiceIdentities) == 0 && len(child.NodeIdentities) == 0 {
+		return fmt.Errorf("Derived token must be linked to at least one policy, service identity or node identity")
+	}
+
+	grant, err := a.lookupDerivableGrant(state, parent)
+	if err != nil {
+		return err
+	}
+
+	for i, link := range child.Policies {
+		if link.ID == "" {
+			_, policy, err := state.ACLPolicyGetByName(nil, link.Name, &parent.EnterpriseMeta)
+			if err != nil {
+				return fmt.Errorf("Error looking up policy for name %q: %v", link.Name, err)
+			} else if policy == nil {
+				return fmt.Errorf("No such ACL policy with name %q", link.Name)
+			}
+			child.Policies[i].ID = policy.ID
+		}
+
+		if _, ok := grant.policyIDs[child.Policies[i].ID]; !ok {
+			return acl.PermissionDeniedError{Cause: fmt.Sprintf("Policy %q is not granted to the parent token", link.Name+link.ID)}
+		}
+	}
+
+	for _, svcid := range child.ServiceIdentities {
+		if !grant.coversServiceIdentity(svcid) {
+			return acl.PermissionDeniedError{Cause: fmt.Sprintf("Service identity %q is not granted to the parent token", svcid.ServiceName)}
+		}
+	}
+
+	for _, nodeid := range child.NodeIdentities {
+		if !grant.coversNodeIdentity(nodeid) {
+			return acl.PermissionDeniedError{Cause: fmt.Sprintf("Node identity %q is not granted to the parent token", nodeid.NodeName)}
+		}
+	}
+
+	if child.Description == "" {
+		child.Description = fmt.Sprintf("Derived from token %s", parent.AccessorID)
+	}
+
+	createReq := structs.ACLTokenSetRequest{
+		Datacenter: args.Datacenter,
+		Create:     true,
+		ACLToken: structs.ACLToken{
+			Description:       child.Description,
+			Policies:          child.Policies,
+			ServiceIdentities: child.ServiceIdentities,
+			NodeIdentities:    child.NodeIdentities,
+			Local:             parent.Local,
+			ParentAccessorID:  parent.AccessorID,
+			ExpirationTTL:     child.ExpirationTTL,
+			EnterpriseMeta:    parent.EnterpriseMeta,
+		},
+		WriteRequest: args.WriteRequest,
+	}
+
+	return a.tokenSetInternal(&createReq, reply, false)
+}
+
+// derivableGrant is the set of policies and identities that a token may pass
+// on to tokens derived from it. It includes everything linked directly to the
+// token as well as everything linked to the token's roles.
+type derivableGrant struct {
+	policyIDs         map[string]struct{}
+	serviceIdentities []*structs.ACLServiceIdentity
+	nodeIdentities    []*structs.ACLNodeIdentity
+}
+
+func (a *ACL) lookupDerivableGrant(state *state.Store, token *structs.ACLToken) (*derivableGrant, error) {
+	grant := &derivableGrant{
+		policyIDs:         make(map[string]struct{}),
+		serviceIdentities: token.ServiceIdentities,
+		nodeIdentities:    token.NodeIdentities,
+	}
+
+	for _, link := range token.Policies {
+		grant.policyIDs[link.ID] = struct{}{}
+	}
+
+	for _, link := range token.Roles {
+		_, role, err := state.ACLRoleGetByID(nil, link.ID, &token.EnterpriseMeta)
+		if err != nil {
+			return nil, fmt.Errorf("Error looking up role for id %q: %v", link.ID, err)
+		} else if role == nil {
+			continue
+		}
+
+		for _, policy := range role.Policies {
+			grant.policyIDs[policy.ID] = struct{}{}
+		}
+		grant.serviceIdentities = append(grant.serviceIdentities, role.ServiceIdentities...)
+		grant.nodeIdentities = append(grant.nodeIdentities, role.NodeIdentities...)
+	}
+
+	return grant, nil
+}
+
+// coversServiceIdentity returns true if the grant contains a service identity
+// for the same service which is valid in every datacenter requested by svcid.
+func (g *derivableGrant) coversServiceIdentity(svcid *structs.ACLServiceIdentity) bool {
+	for _, granted := range g.serviceIdentities {
+		if granted.ServiceName != svcid.ServiceName {
+			continue
+		}
+		if len(granted.Datacenters) == 0 {
+			return true
+		}
+		if len(svcid.Datacenters) == 0 {
+			continue
+		}
+
+		covered := true
+		for _, dc := range svcid.Datacenters {
+			if !stringslice.Contains(granted.Datacenters, dc) {
+				covered = false
+				break
+			}
+		}
+		if covered {
+			return true
+		}
+	}
+	return false
+}
+
+// coversNodeIdentity returns true if the grant contains the exact node identity.
+func (g *derivableGrant) coversNodeIdentity(nodeid *structs.ACLNodeIdentity) bool {
+	for _, granted := range g.nodeIdentities {
+		if granted.NodeName == nodeid.NodeName && granted.Datacenter == nodeid.Datacenter {
+			return true
+		}
+	}
+	return false
+}
+
 func (a *ACL) TokenSet(args *structs.ACLTokenSetRequest, reply *structs.ACLToken) error {
 	if err := a.aclPreCheck(); err != nil {
 		return err
@@ -524,6 +741,10 @@ func (a *ACL) TokenSet(args *structs.ACLTokenSetRequest, reply *structs.ACLToken
 		return err
 	}
 
+	if args.ACLToken.ParentAccessorID != "" && (args.Create || args.ACLToken.AccessorID == "") {
+		return fmt.Errorf("ParentAccessorID can only be set by deriving a token")
+	}
+
 	return a.tokenSetInternal(args, reply, false)
 }
 
@@ -675,6 +896,12 @@ func (a *ACL) tokenSetInternal(args *structs.ACLTokenSetRequest, reply *structs.
 			return fmt.Errorf("Cannot change AuthMethod of %s", token.AccessorID)
 		}
 
+		if token.ParentAccessorID == "" {
+			token.ParentAccessorID = accessorMatch.ParentAccessorID
+		} else if token.ParentAccessorID != accessorMatch.ParentAccessorID {
+			return fmt.Errorf("Cannot change ParentAccessorID of %s", token.AccessorID)
+		}
+
 		if token.ExpirationTTL != 0 {
 			return fmt.Errorf("Cannot change expiration time of %s", token.AccessorID)
 		}
@@ -1407,6 +1634,111 @@ func (a *ACL) PolicyList(args *structs.ACLPolicyListRequest, reply *structs.ACLP
 		})
 }
 
+// PolicyLint is used to check policy rules for mistakes such as shadowed or
+// unreachable rules, and to report which policies grant access to a resource.
+func (a *ACL) PolicyLint(args *structs.ACLPolicyLintRequest, reply *structs.ACLPolicyLintResponse) error {
+	if err := a.aclPreCheck(); err != nil {
+		return err
+	}
+
+	if err := a.srv.validateEnterpriseRequest(&args.EnterpriseMeta, false); err != nil {
+		return err
+	}
+
+	if done, err := a.srv.ForwardRPC("ACL.PolicyLint", args, reply); done {
+		return err
+	}
+
+	var authzContext acl.AuthorizerContext
+
+	authz, err := a.srv.ResolveTokenAndDefaultMeta(args.Token, &args.EnterpriseMeta, &authzContext)
+	if err != nil {
+		return err
+	} else if err := authz.ToAllowAuthorizer().ACLReadAllowed(&authzContext); err != nil {
+		return err
+	}
+
+	if args.Rules != "" {
+		policy, err := acl.NewPolicyFromSource(args.Rules, acl.SyntaxCurrent, a.srv.aclConfig, args.EnterpriseMeta.ToEnterprisePolicyMeta())
+		if err != nil {
+			return err
+		}
+
+		reply.Results = []structs.ACLPolicyLintResult{{Findings: acl.LintPolicy(policy)}}
+		return nil
+	}
+
+	if args.Access == "" {
+		args.Access = acl.AccessRead.String()
+	}
+
+	// validate the resource and access level up front rather than once per policy
+	if args.Resource != "" {
+		if _, err := acl.Enforce(acl.DenyAll(), args.Resource, args.Segment, args.Access, nil); err != nil {
+			return err
+		}
+	}
+
+	return a.srv.blockingQuery(&args.QueryOptions, &reply.QueryMeta,
+		func(ws memdb.WatchSet, state *state.Store) error {
+			index, policies, err := state.ACLPolicyList(ws, &args.EnterpriseMeta)
+			if err != nil {
+				return err
+			}
+
+			// filter down to just what the requester has permissions to see
+			a.srv.filterACLWithAuthorizer(authz, &policies)
+
+			var results []structs.ACLPolicyLintResult
+			var grants []structs.ACLPolicyGrant
+			for _, policy := range policies {
+				parsed, err := acl.NewPolicyFromSource(policy.Rules, policy.Syntax, a.srv.aclConfig, policy.EnterprisePolicyMeta())
+				if err != nil {
+					return fmt.Errorf("failed to parse policy %q: %v", policy.Name, err)
+				}
+
+				if args.Resource == "" {
+					if findings := acl.LintPolicy(parsed); len(findings) > 0 {
+						results = append(results, structs.ACLPolicyLintResult{
+							PolicyID:   policy.ID,
+							PolicyName: policy.Name,
+							Findings:   findings,
+						})
+					}
+					continue
+				}
+
+				// policies scoped to other datacenters are never used here
+				if len(policy.Datacenters) > 0 && !stringslice.Contains(policy.Datacenters, a.srv.config.Datacenter) {
+					continue
+				}
+
+				policyAuthz, err := acl.NewPolicyAuthorizerWithDefaults(acl.DenyAll(), []*acl.Policy{parsed}, a.srv.aclConfig)
+				if err != nil {
+					return err
+				}
+
+				decision, err := acl.Enforce(policyAuthz, args.Resource, args.Segment, args.Access, &authzContext)
+				if err != nil {
+					return err
+				}
+				if decision != acl.Allow {
+					continue
+				}
+
+				rule, _ := acl.GoverningRule(parsed, args.Resource, args.Segment)
+				grants = append(grants, structs.ACLPolicyGrant{
+					PolicyID:   policy.ID,
+					PolicyName: policy.Name,
+					Rule:       rule,
+				})
+			}
+
+			reply.Index, reply.Results, reply.Grants = index, results, grants
+			return nil
+		})
+}
+
 // PolicyResolve is used to retrieve a subset of the policies associated with a given token
 // The policy ids in the args simply act as a filter on the policy set assigned to the token
 func (a *ACL) PolicyResolve(args *structs.ACLPolicyBatchGetRequest, reply *structs.ACLPolicyBatchResponse) error {
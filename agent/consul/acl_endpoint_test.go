@@ -465,6 +465,204 @@ func TestACLEndpoint_TokenClone(t *testing.T) {
 	})
 }
 
+func TestACLEndpoint_TokenDerive(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+
+	_, srv, codec := testACLServerWithConfig(t, func(c *Config) {
+		c.ACLTokenMinExpirationTTL = 10 * time.Millisecond
+		c.ACLTokenMaxExpirationTTL = 5 * time.Second
+	}, false)
+	waitForLeaderEstablishment(t, srv)
+
+	p1, err := upsertTestPolicy(codec, TestDefaultInitialManagementToken, "dc1")
+	require.NoError(t, err)
+
+	p2, err := upsertTestPolicy(codec, TestDefaultInitialManagementToken, "dc1")
+	require.NoError(t, err)
+
+	p3, err := upsertTestPolicy(codec, TestDefaultInitialManagementToken, "dc1")
+	require.NoError(t, err)
+
+	r1, err := upsertTestCustomizedRole(codec, TestDefaultInitialManagementToken, "dc1", func(role *structs.ACLRole) {
+		role.Policies = []structs.ACLRolePolicyLink{{ID: p2.ID}}
+	})
+	require.NoError(t, err)
+
+	parent, err := upsertTestToken(codec, TestDefaultInitialManagementToken, "dc1", func(t *structs.ACLToken) {
+		t.Policies = []structs.ACLTokenPolicyLink{{ID: p1.ID}}
+		t.Roles = []structs.ACLTokenRoleLink{{ID: r1.ID}}
+		t.ServiceIdentities = []*structs.ACLServiceIdentity{
+			{ServiceName: "web", Datacenters: []string{"dc1", "dc2"}},
+		}
+		t.NodeIdentities = []*structs.ACLNodeIdentity{
+			{NodeName: "foo", Datacenter: "dc1"},
+		}
+	})
+	require.NoError(t, err)
+
+	endpoint := ACL{srv: srv}
+
+	derive := func(secretID string, child structs.ACLToken) (*structs.ACLToken, error) {
+		req := structs.ACLTokenDeriveRequest{
+			Datacenter:   "dc1",
+			ACLToken:     child,
+			WriteRequest: structs.WriteRequest{Token: secretID},
+		}
+
+		var out structs.ACLToken
+		if err := endpoint.TokenDerive(&req, &out); err != nil {
+			return nil, err
+		}
+		return &out, nil
+	}
+
+	t.Run("subset of direct and role policies", func(t *testing.T) {
+		child, err := derive(parent.SecretID, structs.ACLToken{
+			Policies:      []structs.ACLTokenPolicyLink{{ID: p1.ID}, {Name: p2.Name}},
+			ExpirationTTL: time.Second,
+		})
+		require.NoError(t, err)
+
+		require.Equal(t, parent.AccessorID, child.ParentAccessorID)
+		require.Equal(t, parent.Local, child.Local)
+		require.ElementsMatch(t, []string{p1.ID, p2.ID}, child.PolicyIDs())
+		require.Empty(t, child.Roles)
+		require.True(t, child.HasExpirationTime())
+		require.NotEqual(t, parent.AccessorID, child.AccessorID)
+		require.NotEqual(t, parent.SecretID, child.SecretID)
+	})
+
+	t.Run("identities", func(t *testing.T) {
+		child, err := derive(parent.SecretID, structs.ACLToken{
+			ServiceIdentities: []*structs.ACLServiceIdentity{
+				{ServiceName: "web", Datacenters: []string{"dc1"}},
+			},
+			NodeIdentities: []*structs.ACLNodeIdentity{
+				{NodeName: "foo", Datacenter: "dc1"},
+			},
+			ExpirationTTL: time.Second,
+		})
+		require.NoError(t, err)
+		require.Len(t, child.ServiceIdentities, 1)
+		require.Len(t, child.NodeIdentities, 1)
+	})
+
+	t.Run("policy not granted to parent", func(t *testing.T) {
+		_, err := derive(parent.SecretID, structs.ACLToken{
+			Policies:      []structs.ACLTokenPolicyLink{{ID: p3.ID}},
+			ExpirationTTL: time.Second,
+		})
+		require.True(t, acl.IsErrPermissionDenied(err), "unexpected error: %v", err)
+	})
+
+	t.Run("service identity widens datacenters", func(t *testing.T) {
+		_, err := derive(parent.SecretID, structs.ACLToken{
+			ServiceIdentities: []*structs.ACLServiceIdentity{
+				{ServiceName: "web"},
+			},
+			ExpirationTTL: time.Second,
+		})
+		require.True(t, acl.IsErrPermissionDenied(err), "unexpected error: %v", err)
+	})
+
+	t.Run("unknown node identity", func(t *testing.T) {
+		_, err := derive(parent.SecretID, structs.ACLToken{
+			NodeIdentities: []*structs.ACLNodeIdentity{
+				{NodeName: "foo", Datacenter: "dc2"},
+			},
+			ExpirationTTL: time.Second,
+		})
+		require.True(t, acl.IsErrPermissionDenied(err), "unexpected error: %v", err)
+	})
+
+	t.Run("roles are not allowed", func(t *testing.T) {
+		_, err := derive(parent.SecretID, structs.ACLToken{
+			Roles:         []structs.ACLTokenRoleLink{{ID: r1.ID}},
+			ExpirationTTL: time.Second,
+		})
+		testutil.RequireErrorContains(t, err, "Roles cannot be linked to a derived token")
+	})
+
+	t.Run("expiration ttl is required", func(t *testing.T) {
+		_, err := derive(parent.SecretID, structs.ACLToken{
+			Policies: []structs.ACLTokenPolicyLink{{ID: p1.ID}},
+		})
+		testutil.RequireErrorContains(t, err, "ExpirationTTL is required")
+	})
+
+	t.Run("cannot outlive parent", func(t *testing.T) {
+		expiring, err := upsertTestToken(codec, TestDefaultInitialManagementToken, "dc1", func(t *structs.ACLToken) {
+			t.Policies = []structs.ACLTokenPolicyLink{{ID: p1.ID}}
+			t.ExpirationTTL = time.Second
+		})
+		require.NoError(t, err)
+
+		_, err = derive(expiring.SecretID, structs.ACLToken{
+			Policies:      []structs.ACLTokenPolicyLink{{ID: p1.ID}},
+			ExpirationTTL: 2 * time.Second,
+		})
+		testutil.RequireErrorContains(t, err, "cannot outlive its parent")
+	})
+
+	t.Run("parent token must exist", func(t *testing.T) {
+		_, err := derive("e1bb17f7-4a3f-4cfc-9d70-9fc13d6c8d11", structs.ACLToken{
+			Policies:      []structs.ACLTokenPolicyLink{{ID: p1.ID}},
+			ExpirationTTL: time.Second,
+		})
+		require.Equal(t, acl.ErrNotFound, err)
+	})
+
+	t.Run("revoked with parent", func(t *testing.T) {
+		parent, err := upsertTestToken(codec, TestDefaultInitialManagementToken, "dc1", func(t *structs.ACLToken) {
+			t.Policies = []structs.ACLTokenPolicyLink{{ID: p1.ID}}
+		})
+		require.NoError(t, err)
+
+		child, err := derive(parent.SecretID, structs.ACLToken{
+			Policies:      []structs.ACLTokenPolicyLink{{ID: p1.ID}},
+			ExpirationTTL: 4 * time.Second,
+		})
+		require.NoError(t, err)
+
+		grandchild, err := derive(child.SecretID, structs.ACLToken{
+			Policies:      []structs.ACLTokenPolicyLink{{ID: p1.ID}},
+			ExpirationTTL: 2 * time.Second,
+		})
+		require.NoError(t, err)
+
+		_, ident, err := srv.ResolveIdentityFromToken(grandchild.SecretID)
+		require.NoError(t, err)
+		require.NotNil(t, ident)
+
+		err = deleteTestToken(codec, TestDefaultInitialManagementToken, "dc1", parent.AccessorID)
+		require.NoError(t, err)
+
+		_, _, err = srv.ResolveIdentityFromToken(child.SecretID)
+		require.Equal(t, acl.ErrNotFound, err)
+		_, _, err = srv.ResolveIdentityFromToken(grandchild.SecretID)
+		require.Equal(t, acl.ErrNotFound, err)
+	})
+
+	t.Run("parent accessor cannot be set directly", func(t *testing.T) {
+		req := structs.ACLTokenSetRequest{
+			Datacenter: "dc1",
+			ACLToken: structs.ACLToken{
+				Policies:         []structs.ACLTokenPolicyLink{{ID: p1.ID}},
+				ParentAccessorID: parent.AccessorID,
+			},
+			WriteRequest: structs.WriteRequest{Token: TestDefaultInitialManagementToken},
+		}
+
+		var out structs.ACLToken
+		err := endpoint.TokenSet(&req, &out)
+		testutil.RequireErrorContains(t, err, "ParentAccessorID can only be set by deriving a token")
+	})
+}
+
 func TestACLEndpoint_TokenSet(t *testing.T) {
 	if testing.Short() {
 		t.Skip("too slow for testing.Short")
@@ -2269,6 +2467,126 @@ func TestACLEndpoint_PolicyList(t *testing.T) {
 	require.ElementsMatch(t, gatherIDs(t, resp.Policies), policies)
 }
 
+func TestACLEndpoint_PolicyLint(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+
+	_, srv, codec := testACLServerWithConfig(t, nil, false)
+	waitForLeaderEstablishment(t, srv)
+
+	clean, err := upsertTestPolicyWithRules(codec, TestDefaultInitialManagementToken, "dc1", `service "web" { policy = "write" }`)
+	require.NoError(t, err)
+
+	shadowed, err := upsertTestPolicyWithRules(codec, TestDefaultInitialManagementToken, "dc1", `
+		service_prefix "web" { policy = "read" }
+		service "web-ui" { policy = "read" }
+	`)
+	require.NoError(t, err)
+
+	aclEp := ACL{srv: srv}
+
+	t.Run("rules", func(t *testing.T) {
+		req := structs.ACLPolicyLintRequest{
+			Datacenter:   "dc1",
+			Rules:        `key_prefix "" { policy = "write" }`,
+			QueryOptions: structs.QueryOptions{Token: TestDefaultInitialManagementToken},
+		}
+
+		var resp structs.ACLPolicyLintResponse
+		require.NoError(t, aclEp.PolicyLint(&req, &resp))
+		require.Len(t, resp.Results, 1)
+		require.Empty(t, resp.Results[0].PolicyID)
+		require.Len(t, resp.Results[0].Findings, 1)
+		require.Equal(t, `key_prefix ""`, resp.Results[0].Findings[0].Rule)
+		require.Equal(t, "broad-write", resp.Results[0].Findings[0].Kind)
+	})
+
+	t.Run("invalid rules", func(t *testing.T) {
+		req := structs.ACLPolicyLintRequest{
+			Datacenter:   "dc1",
+			Rules:        `service "web" { policy = "sudo" }`,
+			QueryOptions: structs.QueryOptions{Token: TestDefaultInitialManagementToken},
+		}
+
+		var resp structs.ACLPolicyLintResponse
+		require.Error(t, aclEp.PolicyLint(&req, &resp))
+	})
+
+	t.Run("stored policies", func(t *testing.T) {
+		req := structs.ACLPolicyLintRequest{
+			Datacenter:   "dc1",
+			QueryOptions: structs.QueryOptions{Token: TestDefaultInitialManagementToken},
+		}
+
+		var resp structs.ACLPolicyLintResponse
+		require.NoError(t, aclEp.PolicyLint(&req, &resp))
+
+		var ids []string
+		for _, result := range resp.Results {
+			ids = append(ids, result.PolicyID)
+			if result.PolicyID == shadowed.ID {
+				require.Equal(t, shadowed.Name, result.PolicyName)
+				require.Len(t, result.Findings, 1)
+				require.Equal(t, "shadowed", result.Findings[0].Kind)
+			}
+		}
+		// the builtin global-management policy grants write on every prefix
+		require.ElementsMatch(t, []string{structs.ACLPolicyGlobalManagementID, shadowed.ID}, ids)
+		require.NotContains(t, ids, clean.ID)
+	})
+
+	t.Run("grants", func(t *testing.T) {
+		req := structs.ACLPolicyLintRequest{
+			Datacenter:   "dc1",
+			Resource:     "service",
+			Segment:      "web",
+			Access:       "write",
+			QueryOptions: structs.QueryOptions{Token: TestDefaultInitialManagementToken},
+		}
+
+		var resp structs.ACLPolicyLintResponse
+		require.NoError(t, aclEp.PolicyLint(&req, &resp))
+		require.Empty(t, resp.Results)
+		require.ElementsMatch(t, []structs.ACLPolicyGrant{
+			{PolicyID: structs.ACLPolicyGlobalManagementID, PolicyName: "global-management", Rule: `service_prefix ""`},
+			{PolicyID: clean.ID, PolicyName: clean.Name, Rule: `service "web"`},
+		}, resp.Grants)
+
+		// read access is also granted by the policy with the prefix rule
+		req.Access = ""
+		resp = structs.ACLPolicyLintResponse{}
+		require.NoError(t, aclEp.PolicyLint(&req, &resp))
+		require.Len(t, resp.Grants, 3)
+	})
+
+	t.Run("invalid access", func(t *testing.T) {
+		req := structs.ACLPolicyLintRequest{
+			Datacenter:   "dc1",
+			Resource:     "service",
+			Segment:      "web",
+			Access:       "sudo",
+			QueryOptions: structs.QueryOptions{Token: TestDefaultInitialManagementToken},
+		}
+
+		var resp structs.ACLPolicyLintResponse
+		require.Error(t, aclEp.PolicyLint(&req, &resp))
+	})
+
+	t.Run("permission denied", func(t *testing.T) {
+		req := structs.ACLPolicyLintRequest{
+			Datacenter:   "dc1",
+			QueryOptions: structs.QueryOptions{Token: ""},
+		}
+
+		var resp structs.ACLPolicyLintResponse
+		err := aclEp.PolicyLint(&req, &resp)
+		require.True(t, acl.IsErrPermissionDenied(err), "expected permission denied, got %v", err)
+	})
+}
+
 func TestACLEndpoint_PolicyResolve(t *testing.T) {
 	if testing.Short() {
 		t.Skip("too slow for testing.Short")
@@ -131,12 +131,34 @@ func (s *Server) ResolveIdentityFromToken(token string) (bool, structs.ACLIdenti
 	if err != nil {
 		return true, nil, err
 	} else if aclToken != nil && !aclToken.IsExpired(time.Now()) {
-		return true, aclToken, nil
+		revoked, err := s.isDerivedTokenRevoked(aclToken)
+		if err != nil {
+			return true, nil, err
+		} else if !revoked {
+			return true, aclToken, nil
+		}
 	}
 
 	return s.InPrimaryDatacenter() || index > 0, nil, acl.ErrNotFound
 }
 
+// isDerivedTokenRevoked returns true if the token was derived from another
+// token and any token in its chain of parents no longer exists. Such tokens
+// are eventually deleted by the token reaper but must not be honored in the
+// meantime.
+func (s *Server) isDerivedTokenRevoked(token *structs.ACLToken) (bool, error) {
+	for token.ParentAccessorID != "" {
+		_, parent, err := s.fsm.State().ACLTokenGetByAccessor(nil, token.ParentAccessorID, nil)
+		if err != nil {
+			return false, err
+		} else if parent == nil || parent.IsExpired(time.Now()) {
+			return true, nil
+		}
+		token = parent
+	}
+	return false, nil
+}
+
 func (s *serverACLResolverBackend) ResolvePolicyFromID(policyID string) (bool, *structs.ACLPolicy, error) {
 	index, policy, err := s.fsm.State().ACLPolicyGetByID(nil, policyID, nil)
 	if err != nil {
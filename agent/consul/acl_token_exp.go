@@ -21,11 +21,17 @@ func (s *Server) reapExpiredTokens(ctx context.Context) error {
 			if _, err := s.reapExpiredLocalACLTokens(); err != nil {
 				s.logger.Error("error reaping expired local ACL tokens", "error", err)
 			}
+			if _, err := s.reapOrphanedLocalACLTokens(); err != nil {
+				s.logger.Error("error reaping orphaned local ACL tokens", "error", err)
+			}
 		}
 		if s.InPrimaryDatacenter() {
 			if _, err := s.reapExpiredGlobalACLTokens(); err != nil {
 				s.logger.Error("error reaping expired global ACL tokens", "error", err)
 			}
+			if _, err := s.reapOrphanedGlobalACLTokens(); err != nil {
+				s.logger.Error("error reaping orphaned global ACL tokens", "error", err)
+			}
 		}
 	}
 }
@@ -62,8 +68,6 @@ func (s *Server) reapExpiredACLTokens(local, global bool) (int, error) {
 		return 0, fmt.Errorf("cannot reap both local and global tokens in the same request")
 	}
 
-	locality := localityName(local)
-
 	minExpiredTime, err := s.fsm.State().ACLTokenMinExpirationTime(local)
 	if err != nil {
 		return 0, err
@@ -84,26 +88,62 @@ func (s *Server) reapExpiredACLTokens(local, global bool) (int, error) {
 		return 0, nil
 	}
 
+	return s.deleteReapedACLTokens(tokens, local, "expired")
+}
+
+func (s *Server) reapOrphanedGlobalACLTokens() (int, error) {
+	return s.reapOrphanedACLTokens(false, true)
+}
+func (s *Server) reapOrphanedLocalACLTokens() (int, error) {
+	return s.reapOrphanedACLTokens(true, false)
+}
+
+// reapOrphanedACLTokens deletes derived tokens whose parent token has been
+// deleted. Children of those tokens are in turn orphaned and picked up on a
+// later pass.
+func (s *Server) reapOrphanedACLTokens(local, global bool) (int, error) {
+	if !s.config.ACLsEnabled {
+		return 0, nil
+	}
+	if local == global {
+		return 0, fmt.Errorf("cannot reap both local and global tokens in the same request")
+	}
+
+	tokens, err := s.fsm.State().ACLTokenListOrphaned(local, aclBatchDeleteSize)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(tokens) == 0 {
+		return 0, nil
+	}
+
+	return s.deleteReapedACLTokens(tokens, local, "orphaned")
+}
+
+func (s *Server) deleteReapedACLTokens(tokens structs.ACLTokens, local bool, reason string) (int, error) {
+	locality := localityName(local)
+
 	var (
 		secretIDs []string
 		req       structs.ACLTokenBatchDeleteRequest
 	)
 	for _, token := range tokens {
 		if token.Local != local {
-			return 0, fmt.Errorf("expired index for local=%v returned a mismatched token with local=%v: %s", local, token.Local, token.AccessorID)
+			return 0, fmt.Errorf("%s index for local=%v returned a mismatched token with local=%v: %s", reason, local, token.Local, token.AccessorID)
 		}
 		req.TokenIDs = append(req.TokenIDs, token.AccessorID)
 		secretIDs = append(secretIDs, token.SecretID)
 	}
 
-	s.logger.Info("deleting expired ACL tokens",
+	s.logger.Info("deleting "+reason+" ACL tokens",
 		"amount", len(req.TokenIDs),
 		"locality", locality,
 	)
 
-	_, err = s.leaderRaftApply("ACL.TokenDelete", structs.ACLTokenDeleteRequestType, &req)
+	_, err := s.leaderRaftApply("ACL.TokenDelete", structs.ACLTokenDeleteRequestType, &req)
 	if err != nil {
-		return 0, fmt.Errorf("Failed to apply token expiration deletions: %v", err)
+		return 0, fmt.Errorf("Failed to apply %s token deletions: %v", reason, err)
 	}
 
 	// Purge the identities from the cache
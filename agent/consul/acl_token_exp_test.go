@@ -222,3 +222,77 @@ func testACLTokenReap_Primary(t *testing.T, local, global bool) {
 		})
 	})
 }
+
+func TestACLTokenReap_Orphaned(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+
+	for _, local := range []bool{true, false} {
+		local := local
+		t.Run(localityName(local), func(t *testing.T) {
+			t.Parallel()
+
+			_, s1, codec := testACLServerWithConfig(t, func(c *Config) {
+				c.ACLTokenMinExpirationTTL = 10 * time.Millisecond
+				c.ACLTokenMaxExpirationTTL = 8 * time.Second
+			}, false)
+			waitForLeaderEstablishment(t, s1)
+
+			acl := ACL{srv: s1}
+
+			policy, err := upsertTestPolicy(codec, TestDefaultInitialManagementToken, "dc1")
+			require.NoError(t, err)
+
+			parent, err := upsertTestToken(codec, TestDefaultInitialManagementToken, "dc1", func(token *structs.ACLToken) {
+				token.Policies = []structs.ACLTokenPolicyLink{{ID: policy.ID}}
+				token.Local = local
+			})
+			require.NoError(t, err)
+
+			derive := func(secretID string, ttl time.Duration) *structs.ACLToken {
+				req := structs.ACLTokenDeriveRequest{
+					Datacenter: "dc1",
+					ACLToken: structs.ACLToken{
+						Policies:      []structs.ACLTokenPolicyLink{{ID: policy.ID}},
+						ExpirationTTL: ttl,
+					},
+					WriteRequest: structs.WriteRequest{Token: secretID},
+				}
+
+				var out structs.ACLToken
+				require.NoError(t, acl.TokenDerive(&req, &out))
+				require.Equal(t, local, out.Local)
+				return &out
+			}
+
+			child := derive(parent.SecretID, 5*time.Second)
+			grandchild := derive(child.SecretID, 4*time.Second)
+
+			n, err := s1.reapOrphanedACLTokens(local, !local)
+			require.NoError(t, err)
+			require.Equal(t, 0, n)
+
+			require.NoError(t, deleteTestToken(codec, TestDefaultInitialManagementToken, "dc1", parent.AccessorID))
+
+			// The child is orphaned first, and the grandchild once the child
+			// has been reaped.
+			n, err = s1.reapOrphanedACLTokens(local, !local)
+			require.NoError(t, err)
+			require.Equal(t, 1, n)
+
+			n, err = s1.reapOrphanedACLTokens(local, !local)
+			require.NoError(t, err)
+			require.Equal(t, 1, n)
+
+			state := s1.fsm.State()
+			for _, token := range []*structs.ACLToken{child, grandchild} {
+				_, found, err := state.ACLTokenGetByAccessor(nil, token.AccessorID, nil)
+				require.NoError(t, err)
+				require.Nil(t, found)
+			}
+		})
+	}
+}
@@ -130,6 +130,13 @@ type Config struct {
 	// RPCSrcAddr is the source address for outgoing RPC connections.
 	RPCSrcAddr *net.TCPAddr
 
+	// GRPCPort is the port of the public gRPC server, which peers dial to
+	// replicate exported services. It is 0 when the gRPC server is disabled.
+	GRPCPort int
+
+	// GRPCTLSEnabled is whether the public gRPC server serves TLS.
+	GRPCTLSEnabled bool
+
 	// (Enterprise-only) The network segment this agent is part of.
 	Segment string
 
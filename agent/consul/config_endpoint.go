@@ -548,7 +548,12 @@ func (c *ConfigEntry) computeResolvedServiceConfig(
 	// Pass the WatchSet to both the service and proxy config lookups. If either is updated during the
 	// blocking query, this function will be rerun and these state store lookups will both be current.
 	// We use the default enterprise meta to look up the global proxy defaults because they are not namespaced.
-	var proxyConfGlobalProtocol string
+	var (
+		proxyConfGlobalProtocol string
+		envoyExtensions         structs.EnvoyExtensions
+		mutualTLSMode           structs.MutualTLSMode
+		permissiveSourceCIDRs   []string
+	)
 	proxyConf := entries.GetProxyDefaults(args.PartitionOrDefault())
 	if proxyConf != nil {
 		// Apply the proxy defaults to the sidecar's proxy config
@@ -562,6 +567,24 @@ func (c *ConfigEntry) computeResolvedServiceConfig(
 		thisReply.MeshGateway = proxyConf.MeshGateway
 		thisReply.Expose = proxyConf.Expose
 
+		// Access logs and tracing apply to every kind of proxy, which all read
+		// the opaque proxy config but not the typed fields of the response.
+		if proxyConf.AccessLogs.Enabled {
+			if thisReply.ProxyConfig == nil {
+				thisReply.ProxyConfig = make(map[string]interface{})
+			}
+			thisReply.ProxyConfig["access_logs"] = proxyConf.AccessLogs
+		}
+		if proxyConf.Tracing != nil {
+			if thisReply.ProxyConfig == nil {
+				thisReply.ProxyConfig = make(map[string]interface{})
+			}
+			thisReply.ProxyConfig["tracing"] = proxyConf.Tracing
+		}
+		envoyExtensions = append(envoyExtensions, proxyConf.EnvoyExtensions...)
+		mutualTLSMode = proxyConf.MutualTLSMode
+		permissiveSourceCIDRs = proxyConf.PermissiveSourceCIDRs
+
 		// Extract the global protocol from proxyConf for upstream configs.
 		rawProtocol := proxyConf.Config["protocol"]
 		if rawProtocol != nil {
@@ -592,6 +615,24 @@ func (c *ConfigEntry) computeResolvedServiceConfig(
 			}
 			thisReply.ProxyConfig["protocol"] = serviceConf.Protocol
 		}
+		if serviceConf.RateLimit != nil {
+			if thisReply.ProxyConfig == nil {
+				thisReply.ProxyConfig = make(map[string]interface{})
+			}
+			thisReply.ProxyConfig["rate_limit"] = serviceConf.RateLimit
+		}
+		if serviceConf.ExtAuthz != nil {
+			if thisReply.ProxyConfig == nil {
+				thisReply.ProxyConfig = make(map[string]interface{})
+			}
+			thisReply.ProxyConfig["ext_authz"] = serviceConf.ExtAuthz
+		}
+		if serviceConf.ConnectionPool != nil {
+			if thisReply.ProxyConfig == nil {
+				thisReply.ProxyConfig = make(map[string]interface{})
+			}
+			thisReply.ProxyConfig["connection_pool"] = serviceConf.ConnectionPool
+		}
 		if serviceConf.TransparentProxy.OutboundListenerPort != 0 {
 			thisReply.TransparentProxy.OutboundListenerPort = serviceConf.TransparentProxy.OutboundListenerPort
 		}
@@ -601,10 +642,41 @@ func (c *ConfigEntry) computeResolvedServiceConfig(
 		if serviceConf.Mode != structs.ProxyModeDefault {
 			thisReply.Mode = serviceConf.Mode
 		}
+		if serviceConf.Destination != nil {
+			thisReply.Destination = *serviceConf.Destination
+		}
+		envoyExtensions = append(envoyExtensions, serviceConf.EnvoyExtensions...)
+		if serviceConf.MutualTLSMode != structs.MutualTLSModeDefault {
+			mutualTLSMode = serviceConf.MutualTLSMode
+		}
+		if len(serviceConf.PermissiveSourceCIDRs) > 0 {
+			permissiveSourceCIDRs = serviceConf.PermissiveSourceCIDRs
+		}
 
 		thisReply.Meta = serviceConf.Meta
 	}
 
+	// The mutual TLS mode is read by the public listener from the opaque proxy
+	// config, like the other settings of the service-defaults it applies.
+	if mutualTLSMode != structs.MutualTLSModeDefault {
+		if thisReply.ProxyConfig == nil {
+			thisReply.ProxyConfig = make(map[string]interface{})
+		}
+		thisReply.ProxyConfig["mutual_tls_mode"] = mutualTLSMode
+		if len(permissiveSourceCIDRs) > 0 {
+			thisReply.ProxyConfig["permissive_source_cidrs"] = permissiveSourceCIDRs
+		}
+	}
+
+	// The extensions of the proxy-defaults run before those of the
+	// service-defaults, which may build on them.
+	if len(envoyExtensions) > 0 {
+		if thisReply.ProxyConfig == nil {
+			thisReply.ProxyConfig = make(map[string]interface{})
+		}
+		thisReply.ProxyConfig["envoy_extensions"] = envoyExtensions
+	}
+
 	// First collect all upstreams into a set of seen upstreams.
 	// Upstreams can come from:
 	// - Explicitly from proxy registrations, and therefore as an argument to this RPC endpoint
@@ -1074,6 +1074,338 @@ func TestConfigEntry_ResolveServiceConfig(t *testing.T) {
 	require.Equal(t, map[string]interface{}{"foo": 1}, proxyConf.Config)
 }
 
+func TestConfigEntry_ResolveServiceConfig_RateLimit(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+
+	_, s1 := testServer(t)
+	codec := rpcClient(t, s1)
+	defer codec.Close()
+
+	state := s1.fsm.State()
+	require.NoError(t, state.EnsureConfigEntry(1, &structs.ServiceConfigEntry{
+		Kind:     structs.ServiceDefaults,
+		Name:     "foo",
+		Protocol: "http",
+		RateLimit: &structs.RateLimitConfig{
+			RequestsPerSecond: 100,
+			Routes: []structs.RateLimitRoute{
+				{PathPrefix: "/admin", RequestsPerSecond: 1},
+			},
+		},
+	}))
+
+	args := structs.ServiceConfigRequest{
+		Name:       "foo",
+		Datacenter: s1.config.Datacenter,
+	}
+	var out structs.ServiceConfigResponse
+	require.NoError(t, msgpackrpc.CallWithCodec(codec, "ConfigEntry.ResolveServiceConfig", &args, &out))
+
+	require.Equal(t, "http", out.ProxyConfig["protocol"])
+	require.Equal(t, map[string]interface{}{
+		"RequestsPerSecond": int64(100),
+		"Burst":             int64(0),
+		"Routes": []interface{}{
+			map[string]interface{}{
+				"PathPrefix":        "/admin",
+				"RequestsPerSecond": int64(1),
+				"Burst":             int64(0),
+			},
+		},
+		"Sources": nil,
+	}, out.ProxyConfig["rate_limit"])
+}
+
+func TestConfigEntry_ResolveServiceConfig_MutualTLSMode(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+
+	_, s1 := testServer(t)
+	codec := rpcClient(t, s1)
+	defer codec.Close()
+
+	state := s1.fsm.State()
+	require.NoError(t, state.EnsureConfigEntry(1, &structs.ProxyConfigEntry{
+		Kind:                  structs.ProxyDefaults,
+		Name:                  structs.ProxyConfigGlobal,
+		MutualTLSMode:         structs.MutualTLSModePermissive,
+		PermissiveSourceCIDRs: []string{"10.0.0.0/16"},
+	}))
+	require.NoError(t, state.EnsureConfigEntry(2, &structs.ServiceConfigEntry{
+		Kind:          structs.ServiceDefaults,
+		Name:          "strict",
+		MutualTLSMode: structs.MutualTLSModeStrict,
+	}))
+
+	resolve := func(name string) structs.ServiceConfigResponse {
+		args := structs.ServiceConfigRequest{
+			Name:       name,
+			Datacenter: s1.config.Datacenter,
+		}
+		var out structs.ServiceConfigResponse
+		require.NoError(t, msgpackrpc.CallWithCodec(codec, "ConfigEntry.ResolveServiceConfig", &args, &out))
+		return out
+	}
+
+	// The mode of the proxy-defaults applies to services without their own.
+	out := resolve("foo")
+	require.Equal(t, "permissive", out.ProxyConfig["mutual_tls_mode"])
+	require.Equal(t, []interface{}{"10.0.0.0/16"}, out.ProxyConfig["permissive_source_cidrs"])
+
+	// The service-defaults override it.
+	out = resolve("strict")
+	require.Equal(t, "strict", out.ProxyConfig["mutual_tls_mode"])
+}
+
+func TestConfigEntry_ResolveServiceConfig_ConnectionPool(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+
+	_, s1 := testServer(t)
+	codec := rpcClient(t, s1)
+	defer codec.Close()
+
+	zero, idleTimeout := 0, 30000
+
+	state := s1.fsm.State()
+	require.NoError(t, state.EnsureConfigEntry(1, &structs.ServiceConfigEntry{
+		Kind: structs.ServiceDefaults,
+		Name: "foo",
+		ConnectionPool: &structs.ConnectionPoolConfig{
+			TCPIdleTimeoutMs: &zero,
+		},
+		UpstreamConfig: &structs.UpstreamConfiguration{
+			Defaults: &structs.UpstreamConfig{
+				ConnectionPool: &structs.ConnectionPoolConfig{
+					HTTPIdleTimeoutMs: &idleTimeout,
+					TCPKeepalive:      &structs.TCPKeepaliveConfig{Probes: 3},
+				},
+			},
+		},
+	}))
+
+	args := structs.ServiceConfigRequest{
+		Name:        "foo",
+		Datacenter:  s1.config.Datacenter,
+		UpstreamIDs: []structs.ServiceID{structs.NewServiceID("bar", nil)},
+	}
+	var out structs.ServiceConfigResponse
+	require.NoError(t, msgpackrpc.CallWithCodec(codec, "ConfigEntry.ResolveServiceConfig", &args, &out))
+
+	require.Contains(t, out.ProxyConfig, "connection_pool")
+
+	upstreamConfig, ok := out.UpstreamIDConfigs.GetUpstreamConfig(structs.NewServiceID("bar", nil))
+	require.True(t, ok)
+	cfg, err := structs.ParseUpstreamConfig(upstreamConfig)
+	require.NoError(t, err)
+	require.Equal(t, &structs.ConnectionPoolConfig{
+		HTTPIdleTimeoutMs: &idleTimeout,
+		TCPKeepalive:      &structs.TCPKeepaliveConfig{Probes: 3},
+	}, cfg.ConnectionPool)
+}
+
+func TestConfigEntry_ResolveServiceConfig_ExtAuthz(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+
+	_, s1 := testServer(t)
+	codec := rpcClient(t, s1)
+	defer codec.Close()
+
+	state := s1.fsm.State()
+	require.NoError(t, state.EnsureConfigEntry(1, &structs.ServiceConfigEntry{
+		Kind:     structs.ServiceDefaults,
+		Name:     "foo",
+		Protocol: "http",
+		ExtAuthz: &structs.ExtAuthzConfig{
+			Service:          "authz",
+			Protocol:         "http",
+			TimeoutMs:        500,
+			FailureModeAllow: true,
+		},
+	}))
+
+	args := structs.ServiceConfigRequest{
+		Name:       "foo",
+		Datacenter: s1.config.Datacenter,
+	}
+	var out structs.ServiceConfigResponse
+	require.NoError(t, msgpackrpc.CallWithCodec(codec, "ConfigEntry.ResolveServiceConfig", &args, &out))
+
+	require.Equal(t, map[string]interface{}{
+		"Service":          "authz",
+		"Protocol":         "http",
+		"PathPrefix":       "",
+		"TimeoutMs":        uint64(500),
+		"FailureModeAllow": true,
+	}, out.ProxyConfig["ext_authz"])
+}
+
+func TestConfigEntry_ResolveServiceConfig_AccessLogs(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+
+	_, s1 := testServer(t)
+	codec := rpcClient(t, s1)
+	defer codec.Close()
+
+	state := s1.fsm.State()
+	require.NoError(t, state.EnsureConfigEntry(1, &structs.ProxyConfigEntry{
+		Kind: structs.ProxyDefaults,
+		Name: structs.ProxyConfigGlobal,
+		AccessLogs: structs.AccessLogsConfig{
+			Enabled:    true,
+			Type:       structs.FileLogSinkType,
+			Path:       "/var/log/envoy/access.log",
+			TextFormat: "%RESPONSE_CODE%",
+		},
+	}))
+
+	args := structs.ServiceConfigRequest{
+		Name:       "foo",
+		Datacenter: s1.config.Datacenter,
+	}
+	var out structs.ServiceConfigResponse
+	require.NoError(t, msgpackrpc.CallWithCodec(codec, "ConfigEntry.ResolveServiceConfig", &args, &out))
+
+	require.Equal(t, map[string]interface{}{
+		"Enabled":             true,
+		"DisableListenerLogs": false,
+		"Type":                "file",
+		"Path":                "/var/log/envoy/access.log",
+		"JSONFormat":          "",
+		"TextFormat":          "%RESPONSE_CODE%",
+	}, out.ProxyConfig["access_logs"])
+}
+
+func TestConfigEntry_ResolveServiceConfig_Tracing(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+
+	_, s1 := testServer(t)
+	codec := rpcClient(t, s1)
+	defer codec.Close()
+
+	state := s1.fsm.State()
+	require.NoError(t, state.EnsureConfigEntry(1, &structs.ProxyConfigEntry{
+		Kind: structs.ProxyDefaults,
+		Name: structs.ProxyConfigGlobal,
+		Tracing: &structs.TracingConfig{
+			Provider: structs.ZipkinTracingProvider,
+			Collector: structs.TracingCollector{
+				Address: "zipkin.service.consul",
+				Port:    9411,
+			},
+			SamplingPercentage: 2.5,
+			CustomTags:         map[string]string{"env": "prod"},
+		},
+	}))
+
+	args := structs.ServiceConfigRequest{
+		Name:       "foo",
+		Datacenter: s1.config.Datacenter,
+	}
+	var out structs.ServiceConfigResponse
+	require.NoError(t, msgpackrpc.CallWithCodec(codec, "ConfigEntry.ResolveServiceConfig", &args, &out))
+
+	require.Equal(t, map[string]interface{}{
+		"Provider": "zipkin",
+		"Collector": map[string]interface{}{
+			"Service": "",
+			"Address": "zipkin.service.consul",
+			"Port":    uint64(9411),
+		},
+		"CollectorEndpoint":  "",
+		"SamplingPercentage": 2.5,
+		"CustomTags":         map[string]interface{}{"env": "prod"},
+	}, out.ProxyConfig["tracing"])
+}
+
+func TestConfigEntry_ResolveServiceConfig_EnvoyExtensions(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+
+	_, s1 := testServer(t)
+	codec := rpcClient(t, s1)
+	defer codec.Close()
+
+	state := s1.fsm.State()
+	require.NoError(t, state.EnsureConfigEntry(1, &structs.ProxyConfigEntry{
+		Kind: structs.ProxyDefaults,
+		Name: structs.ProxyConfigGlobal,
+		EnvoyExtensions: structs.EnvoyExtensions{
+			{
+				Name: "builtin/lua",
+				Arguments: map[string]interface{}{
+					"Listener": "outbound",
+					"Script":   "function envoy_on_request(h) end",
+				},
+			},
+		},
+	}))
+	require.NoError(t, state.EnsureConfigEntry(2, &structs.ServiceConfigEntry{
+		Kind:     structs.ServiceDefaults,
+		Name:     "foo",
+		Protocol: "http",
+		EnvoyExtensions: structs.EnvoyExtensions{
+			{
+				Name: "builtin/wasm",
+				Arguments: map[string]interface{}{
+					"Listener": "inbound",
+					"Filename": "/etc/envoy/filter.wasm",
+				},
+			},
+		},
+	}))
+
+	args := structs.ServiceConfigRequest{
+		Name:       "foo",
+		Datacenter: s1.config.Datacenter,
+	}
+	var out structs.ServiceConfigResponse
+	require.NoError(t, msgpackrpc.CallWithCodec(codec, "ConfigEntry.ResolveServiceConfig", &args, &out))
+
+	// The extensions of the proxy-defaults come first.
+	require.Equal(t, []interface{}{
+		map[string]interface{}{
+			"Name": "builtin/lua",
+			"Arguments": map[string]interface{}{
+				"Listener": "outbound",
+				"Script":   "function envoy_on_request(h) end",
+			},
+		},
+		map[string]interface{}{
+			"Name": "builtin/wasm",
+			"Arguments": map[string]interface{}{
+				"Listener": "inbound",
+				"Filename": "/etc/envoy/filter.wasm",
+			},
+		},
+	}, out.ProxyConfig["envoy_extensions"])
+}
+
 func TestConfigEntry_ResolveServiceConfig_TransparentProxy(t *testing.T) {
 	if testing.Short() {
 		t.Skip("too slow for testing.Short")
@@ -7,8 +7,10 @@ import (
 
 	"github.com/hashicorp/go-hclog"
 	"github.com/hashicorp/go-memdb"
+	"github.com/hashicorp/go-uuid"
 
 	"github.com/hashicorp/consul/agent/connect"
+	"github.com/hashicorp/consul/agent/connect/ca"
 	"github.com/hashicorp/consul/agent/consul/state"
 	"github.com/hashicorp/consul/agent/structs"
 )
@@ -208,3 +210,222 @@ func (s *ConnectCA) SignIntermediate(
 
 	return nil
 }
+
+// Revoke revokes a leaf certificate by serial number, or every certificate
+// issued for a SPIFFE ID. Revocations are stored in the primary datacenter
+// and replicated to the others.
+func (s *ConnectCA) Revoke(
+	args *structs.CARevocationRequest,
+	reply *string) error {
+	// Exit early if Connect hasn't been enabled.
+	if !s.srv.config.ConnectEnabled {
+		return ErrConnectNotEnabled
+	}
+
+	// Serial numbers are scoped to the CA of the datacenter the request was
+	// made in unless another one was given.
+	if args.Revocation.SerialNumber != "" && args.Revocation.Datacenter == "" {
+		args.Revocation.Datacenter = args.Datacenter
+	}
+	args.Datacenter = s.srv.config.PrimaryDatacenter
+
+	if done, err := s.srv.ForwardRPC("ConnectCA.Revoke", args, reply); done {
+		return err
+	}
+
+	// This action requires operator write access.
+	authz, err := s.srv.ResolveToken(args.Token)
+	if err != nil {
+		return err
+	}
+	if err := authz.ToAllowAuthorizer().OperatorWriteAllowed(nil); err != nil {
+		return err
+	}
+
+	revocation := args.Revocation
+	if err := revocation.Validate(); err != nil {
+		return err
+	}
+	if revocation.SerialNumber != "" {
+		serial, err := connect.ParseSerialNumber(revocation.SerialNumber)
+		if err != nil {
+			return err
+		}
+		revocation.SerialNumber = connect.EncodeSerialNumber(serial)
+	} else {
+		id, err := connect.ParseCertURIFromString(revocation.SpiffeID)
+		if err != nil {
+			return fmt.Errorf("invalid SpiffeID: %v", err)
+		}
+		svc, ok := id.(*connect.SpiffeIDService)
+		if !ok {
+			return fmt.Errorf("SpiffeID must identify a service")
+		}
+		revocation.SpiffeID = svc.URI().String()
+	}
+
+	state := s.srv.fsm.State()
+	_, existing, _, err := state.CARevocations(nil)
+	if err != nil {
+		return err
+	}
+	for _, r := range existing {
+		if r.SerialNumber == revocation.SerialNumber &&
+			r.Datacenter == revocation.Datacenter &&
+			r.SpiffeID == revocation.SpiffeID {
+			// Already revoked.
+			*reply = r.ID
+			return nil
+		}
+	}
+
+	if revocation.SerialNumber != "" {
+		if err := s.srv.caManager.checkRevocationListSigners(s.srv.router.GetDatacenters()); err != nil {
+			return err
+		}
+	}
+
+	_, config, err := state.CAConfig(nil)
+	if err != nil {
+		return err
+	}
+	if config == nil {
+		return fmt.Errorf("CA has not finished initializing")
+	}
+	common, err := config.GetCommonConfig()
+	if err != nil {
+		return err
+	}
+
+	revocation.ID, err = uuid.GenerateUUID()
+	if err != nil {
+		return err
+	}
+	revocation.RevokedAt = time.Now().UTC()
+	// Leaf certificates are backdated by the drift buffer, so nothing signed
+	// before the revocation can still be valid after this.
+	revocation.ExpiresAt = revocation.RevokedAt.Add(common.LeafCertTTL + ca.CertificateTimeDriftBuffer)
+
+	req := &structs.CARequest{
+		Op:         structs.CAOpRevoke,
+		Revocation: &revocation,
+	}
+	if _, err := s.srv.raftApply(structs.ConnectCARequestType, req); err != nil {
+		return err
+	}
+
+	*reply = revocation.ID
+	return nil
+}
+
+// RevocationDelete removes a revocation.
+func (s *ConnectCA) RevocationDelete(
+	args *structs.CARevocationRequest,
+	reply *interface{}) error {
+	// Exit early if Connect hasn't been enabled.
+	if !s.srv.config.ConnectEnabled {
+		return ErrConnectNotEnabled
+	}
+
+	args.Datacenter = s.srv.config.PrimaryDatacenter
+	if done, err := s.srv.ForwardRPC("ConnectCA.RevocationDelete", args, reply); done {
+		return err
+	}
+
+	// This action requires operator write access.
+	authz, err := s.srv.ResolveToken(args.Token)
+	if err != nil {
+		return err
+	}
+	if err := authz.ToAllowAuthorizer().OperatorWriteAllowed(nil); err != nil {
+		return err
+	}
+
+	if args.Revocation.ID == "" {
+		return fmt.Errorf("must provide the ID of the revocation")
+	}
+
+	req := &structs.CARequest{
+		Op:         structs.CAOpDeleteRevocation,
+		Revocation: &structs.CARevocation{ID: args.Revocation.ID},
+	}
+	_, err = s.srv.raftApply(structs.ConnectCARequestType, req)
+	return err
+}
+
+// RevocationList returns the revoked certificates and the revocation lists
+// that enforce them.
+func (s *ConnectCA) RevocationList(
+	args *structs.DCSpecificRequest,
+	reply *structs.IndexedCARevocations) error {
+	// Forward if necessary
+	if done, err := s.srv.ForwardRPC("ConnectCA.RevocationList", args, reply); done {
+		return err
+	}
+
+	// Exit early if Connect hasn't been enabled.
+	if !s.srv.config.ConnectEnabled {
+		return ErrConnectNotEnabled
+	}
+
+	return s.srv.blockingQuery(
+		&args.QueryOptions, &reply.QueryMeta,
+		func(ws memdb.WatchSet, state *state.Store) error {
+			index, revocations, lists, err := state.CARevocations(ws)
+			if err != nil {
+				return err
+			}
+
+			reply.Index = index
+			reply.Revocations = revocations
+			reply.RevocationLists = lists
+			if reply.Revocations == nil {
+				reply.Revocations = make([]*structs.CARevocation, 0)
+			}
+			if reply.RevocationLists == nil {
+				reply.RevocationLists = make([]*structs.CARevocationList, 0)
+			}
+			return nil
+		},
+	)
+}
+
+// PublishRevocationList stores the revocation list signed by the CA of a
+// secondary datacenter in the primary datacenter.
+func (s *ConnectCA) PublishRevocationList(
+	args *structs.CARevocationListRequest,
+	reply *interface{}) error {
+	// Exit early if Connect hasn't been enabled.
+	if !s.srv.config.ConnectEnabled {
+		return ErrConnectNotEnabled
+	}
+
+	if done, err := s.srv.ForwardRPC("ConnectCA.PublishRevocationList", args, reply); done {
+		return err
+	}
+
+	// Verify we are allowed to serve this request
+	if s.srv.config.PrimaryDatacenter != s.srv.config.Datacenter {
+		return ErrNotPrimaryDatacenter
+	}
+
+	// This action requires operator write access.
+	authz, err := s.srv.ResolveToken(args.Token)
+	if err != nil {
+		return err
+	}
+	if err := authz.ToAllowAuthorizer().OperatorWriteAllowed(nil); err != nil {
+		return err
+	}
+
+	if args.RevocationList == nil || args.RevocationList.SigningKeyID == "" {
+		return fmt.Errorf("must provide a revocation list with a SigningKeyID")
+	}
+
+	req := &structs.CARequest{
+		Op:             structs.CAOpSetRevocationList,
+		RevocationList: args.RevocationList,
+	}
+	_, err = s.srv.raftApply(structs.ConnectCARequestType, req)
+	return err
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/pem"
 	"fmt"
 	"os"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -78,6 +79,112 @@ func TestConnectCARoots(t *testing.T) {
 	assert.Equal(t, fmt.Sprintf("%s.consul", caCfg.ClusterID), reply.TrustDomain)
 }
 
+func TestConnectCARevoke(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+
+	dir1, s1 := testServer(t)
+	defer os.RemoveAll(dir1)
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+	defer codec.Close()
+
+	testrpc.WaitForLeader(t, s1.RPC, "dc1")
+
+	// The leader keeps a revocation list for its signing key even before
+	// anything is revoked.
+	retry.Run(t, func(r *retry.R) {
+		_, _, lists, err := s1.fsm.State().CARevocations(nil)
+		require.NoError(r, err)
+		require.Len(r, lists, 1)
+		require.Equal(r, "dc1", lists[0].Datacenter)
+		require.Empty(r, lists[0].SerialNumbers)
+	})
+
+	// Sign a leaf so there is a serial number to revoke.
+	csr, _ := connect.TestCSR(t, connect.TestSpiffeIDService(t, "web"))
+	signArgs := &structs.CASignRequest{Datacenter: "dc1", CSR: csr}
+	var leaf structs.IssuedCert
+	require.NoError(t, msgpackrpc.CallWithCodec(codec, "ConnectCA.Sign", signArgs, &leaf))
+
+	// Revoking requires exactly one of a serial number or a SPIFFE ID.
+	args := &structs.CARevocationRequest{Datacenter: "dc1"}
+	var id string
+	err := msgpackrpc.CallWithCodec(codec, "ConnectCA.Revoke", args, &id)
+	testutil.RequireErrorContains(t, err, "must provide either SerialNumber or SpiffeID")
+
+	// The serial number defaults to the CA of the request datacenter and is
+	// normalized.
+	args.Revocation = structs.CARevocation{
+		SerialNumber: strings.Replace(leaf.SerialNumber, ":", "", -1),
+		Reason:       "key compromise",
+	}
+	require.NoError(t, msgpackrpc.CallWithCodec(codec, "ConnectCA.Revoke", args, &id))
+	require.NotEmpty(t, id)
+
+	// Revoking the same certificate again is a no-op.
+	var id2 string
+	require.NoError(t, msgpackrpc.CallWithCodec(codec, "ConnectCA.Revoke", args, &id2))
+	require.Equal(t, id, id2)
+
+	args.Revocation = structs.CARevocation{
+		SpiffeID: connect.TestSpiffeIDService(t, "db").URI().String(),
+	}
+	var spiffeRevocationID string
+	require.NoError(t, msgpackrpc.CallWithCodec(codec, "ConnectCA.Revoke", args, &spiffeRevocationID))
+
+	listArgs := &structs.DCSpecificRequest{Datacenter: "dc1"}
+	var revocations structs.IndexedCARevocations
+	require.NoError(t, msgpackrpc.CallWithCodec(codec, "ConnectCA.RevocationList", listArgs, &revocations))
+	require.Len(t, revocations.Revocations, 2)
+	for _, r := range revocations.Revocations {
+		if r.ID != id {
+			continue
+		}
+		require.Equal(t, leaf.SerialNumber, r.SerialNumber)
+		require.Equal(t, "dc1", r.Datacenter)
+		require.True(t, r.ExpiresAt.After(leaf.ValidBefore))
+	}
+
+	// The leader signs a revocation list for the serial number and the roots
+	// include it along with the revoked SPIFFE ID.
+	var roots structs.IndexedCARoots
+	retry.Run(t, func(r *retry.R) {
+		_, _, lists, err := s1.fsm.State().CARevocations(nil)
+		require.NoError(r, err)
+		require.Len(r, lists, 1)
+		require.Equal(r, []string{leaf.SerialNumber}, lists[0].SerialNumbers)
+	})
+	require.NoError(t, msgpackrpc.CallWithCodec(codec, "ConnectCA.Roots", listArgs, &roots))
+	require.Len(t, roots.RevocationLists, 1)
+	require.Equal(t, []string{args.Revocation.SpiffeID}, roots.RevokedSpiffeIDs)
+
+	block, _ := pem.Decode([]byte(roots.RevocationLists[0]))
+	require.NotNil(t, block)
+	crl, err := x509.ParseRevocationList(block.Bytes)
+	require.NoError(t, err)
+	require.NoError(t, crl.CheckSignatureFrom(testParseCert(t, roots.Active().RootCert)))
+	require.Len(t, crl.RevokedCertificates, 1)
+	require.Equal(t, leaf.SerialNumber, connect.EncodeSerialNumber(crl.RevokedCertificates[0].SerialNumber))
+
+	// Once the serial number revocation is removed the lists are no longer
+	// needed by proxies.
+	deleteArgs := &structs.CARevocationRequest{
+		Datacenter: "dc1",
+		Revocation: structs.CARevocation{ID: id},
+	}
+	var out interface{}
+	require.NoError(t, msgpackrpc.CallWithCodec(codec, "ConnectCA.RevocationDelete", deleteArgs, &out))
+
+	roots = structs.IndexedCARoots{}
+	require.NoError(t, msgpackrpc.CallWithCodec(codec, "ConnectCA.Roots", listArgs, &roots))
+	require.Empty(t, roots.RevocationLists)
+	require.Equal(t, []string{args.Revocation.SpiffeID}, roots.RevokedSpiffeIDs)
+}
+
 func TestConnectCAConfig_GetSet(t *testing.T) {
 	if testing.Short() {
 		t.Skip("too slow for testing.Short")
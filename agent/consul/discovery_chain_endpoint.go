@@ -9,6 +9,7 @@ import (
 	hashstructure_v2 "github.com/mitchellh/hashstructure/v2"
 
 	"github.com/hashicorp/consul/acl"
+	"github.com/hashicorp/consul/agent/configentry"
 	"github.com/hashicorp/consul/agent/consul/discoverychain"
 	"github.com/hashicorp/consul/agent/consul/state"
 	"github.com/hashicorp/consul/agent/structs"
@@ -100,3 +101,92 @@ func (c *DiscoveryChain) Get(args *structs.DiscoveryChainRequest, reply *structs
 			return nil
 		})
 }
+
+// Simulate compiles the discovery chain for a service as if the config entries
+// in the request had been written, and returns it alongside the chain compiled
+// from the config entries currently stored so the two can be compared.
+func (c *DiscoveryChain) Simulate(args *structs.DiscoveryChainSimulateRequest, reply *structs.DiscoveryChainSimulateResponse) error {
+	// Exit early if Connect hasn't been enabled.
+	if !c.srv.config.ConnectEnabled {
+		return ErrConnectNotEnabled
+	}
+
+	if done, err := c.srv.ForwardRPC("DiscoveryChain.Simulate", args, reply); done {
+		return err
+	}
+	defer metrics.MeasureSince([]string{"discovery_chain", "simulate"}, time.Now())
+
+	// Fetch the ACL token, if any.
+	entMeta := args.GetEnterpriseMeta()
+	var authzContext acl.AuthorizerContext
+	authz, err := c.srv.ResolveTokenAndDefaultMeta(args.Token, entMeta, &authzContext)
+	if err != nil {
+		return err
+	}
+	if err := authz.ToAllowAuthorizer().ServiceReadAllowed(args.Name, &authzContext); err != nil {
+		return err
+	}
+
+	if args.Name == "" {
+		return fmt.Errorf("Must provide service name")
+	}
+
+	overrides := make(map[configentry.KindName]structs.ConfigEntry, len(args.Entries))
+	for _, entry := range args.Entries {
+		switch entry.GetKind() {
+		case structs.ProxyDefaults, structs.ServiceDefaults,
+			structs.ServiceRouter, structs.ServiceSplitter, structs.ServiceResolver:
+		default:
+			return fmt.Errorf("config entry kind %q does not affect discovery chains", entry.GetKind())
+		}
+
+		if err := entry.Normalize(); err != nil {
+			return err
+		}
+		if err := entry.Validate(); err != nil {
+			return fmt.Errorf("invalid config entry %s/%s: %w", entry.GetKind(), entry.GetName(), err)
+		}
+
+		kn := configentry.NewKindName(entry.GetKind(), entry.GetName(), entry.GetEnterpriseMeta())
+		kn.Normalize()
+		if _, ok := overrides[kn]; ok {
+			return fmt.Errorf("config entry %s/%s was given more than once", entry.GetKind(), entry.GetName())
+		}
+		overrides[kn] = entry
+	}
+
+	evalDC := args.EvaluateInDatacenter
+	if evalDC == "" {
+		evalDC = c.srv.config.Datacenter
+	}
+
+	return c.srv.blockingQuery(
+		&args.QueryOptions,
+		&reply.QueryMeta,
+		func(ws memdb.WatchSet, state *state.Store) error {
+			req := discoverychain.CompileRequest{
+				ServiceName:            args.Name,
+				EvaluateInNamespace:    entMeta.NamespaceOrDefault(),
+				EvaluateInPartition:    entMeta.PartitionOrDefault(),
+				EvaluateInDatacenter:   evalDC,
+				OverrideMeshGateway:    args.OverrideMeshGateway,
+				OverrideProtocol:       args.OverrideProtocol,
+				OverrideConnectTimeout: args.OverrideConnectTimeout,
+			}
+			index, current, _, err := state.ServiceDiscoveryChain(ws, args.Name, entMeta, req)
+			if err != nil {
+				return err
+			}
+			_, chain, _, err := state.ServiceDiscoveryChainWithOverrides(ws, args.Name, entMeta, req, overrides)
+			if err != nil {
+				return err
+			}
+
+			reply.Index = index
+			reply.CurrentChain = current
+			reply.Chain = chain
+			reply.Explain = discoverychain.Explain(chain)
+			reply.Diff = discoverychain.Diff(discoverychain.Explain(current), reply.Explain)
+			return nil
+		})
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/hashicorp/consul/acl"
 	"github.com/hashicorp/consul/agent/connect"
 	"github.com/hashicorp/consul/agent/structs"
+	"github.com/hashicorp/consul/sdk/testutil"
 	"github.com/hashicorp/consul/testrpc"
 )
 
@@ -333,3 +334,148 @@ func TestDiscoveryChainEndpoint_Get_BlockOnNoChange(t *testing.T) {
 		run(t, "completely-different-other")
 	})
 }
+
+func TestDiscoveryChainEndpoint_Simulate(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+
+	_, s1 := testServerWithConfig(t, func(c *Config) {
+		c.DevMode = true // keep it in ram to make it 10x faster on macos
+		c.PrimaryDatacenter = "dc1"
+	})
+
+	codec := rpcClient(t, s1)
+
+	waitForLeaderEstablishment(t, s1)
+	testrpc.WaitForTestAgent(t, s1.RPC, "dc1")
+
+	var out bool
+	require.NoError(t, msgpackrpc.CallWithCodec(codec, "ConfigEntry.Apply", &structs.ConfigEntryRequest{
+		Datacenter: "dc1",
+		Entry: &structs.ServiceResolverConfigEntry{
+			Kind:           structs.ServiceResolver,
+			Name:           "web",
+			ConnectTimeout: 33 * time.Second,
+		},
+	}, &out))
+	require.True(t, out)
+
+	simulate := func(entries ...structs.ConfigEntry) (*structs.DiscoveryChainSimulateResponse, error) {
+		args := structs.DiscoveryChainSimulateRequest{
+			DiscoveryChainRequest: structs.DiscoveryChainRequest{
+				Name:                 "web",
+				EvaluateInDatacenter: "dc1",
+				EvaluateInNamespace:  "default",
+				EvaluateInPartition:  "default",
+				Datacenter:           "dc1",
+			},
+			Entries: entries,
+		}
+		var resp structs.DiscoveryChainSimulateResponse
+		if err := msgpackrpc.CallWithCodec(codec, "DiscoveryChain.Simulate", &args, &resp); err != nil {
+			return nil, err
+		}
+		return &resp, nil
+	}
+
+	t.Run("failover", func(t *testing.T) {
+		resp, err := simulate(&structs.ServiceResolverConfigEntry{
+			Kind:           structs.ServiceResolver,
+			Name:           "web",
+			ConnectTimeout: 10 * time.Second,
+			Failover: map[string]structs.ServiceResolverFailover{
+				"*": {Datacenters: []string{"dc2"}},
+			},
+		})
+		require.NoError(t, err)
+
+		current := resp.CurrentChain.Nodes["resolver:web.default.default.dc1"].Resolver
+		require.Equal(t, 33*time.Second, current.ConnectTimeout)
+		require.Nil(t, current.Failover)
+
+		simulated := resp.Chain.Nodes["resolver:web.default.default.dc1"].Resolver
+		require.Equal(t, 10*time.Second, simulated.ConnectTimeout)
+		require.Equal(t, []string{"web.default.default.dc2"}, simulated.Failover.Targets)
+
+		require.Contains(t, resp.Explain, "failover 1: web.default.default.dc2")
+		require.Equal(t, `  web (protocol: tcp, datacenter: dc1)
+  resolver: web.default.default.dc1
+    target: web.default.default.dc1
+-   connect timeout: 33s
++   connect timeout: 10s
++   failover 1: web.default.default.dc2
+`, resp.Diff)
+
+		// The simulated entry must not have been written.
+		state := s1.fsm.State()
+		_, entry, err := state.ConfigEntry(nil, structs.ServiceResolver, "web", nil)
+		require.NoError(t, err)
+		require.Equal(t, 33*time.Second, entry.(*structs.ServiceResolverConfigEntry).ConnectTimeout)
+	})
+
+	t.Run("no changes", func(t *testing.T) {
+		resp, err := simulate(&structs.ServiceResolverConfigEntry{
+			Kind:           structs.ServiceResolver,
+			Name:           "web",
+			ConnectTimeout: 33 * time.Second,
+		})
+		require.NoError(t, err)
+		require.Empty(t, resp.Diff)
+	})
+
+	t.Run("router requires an http protocol", func(t *testing.T) {
+		_, err := simulate(&structs.ServiceRouterConfigEntry{
+			Kind: structs.ServiceRouter,
+			Name: "web",
+		})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "does not permit advanced routing or splitting behavior")
+
+		resp, err := simulate(
+			&structs.ProxyConfigEntry{
+				Kind: structs.ProxyDefaults,
+				Name: structs.ProxyConfigGlobal,
+				Config: map[string]interface{}{
+					"protocol": "http",
+				},
+			},
+			&structs.ServiceRouterConfigEntry{
+				Kind: structs.ServiceRouter,
+				Name: "web",
+				Routes: []structs.ServiceRoute{{
+					Match: &structs.ServiceRouteMatch{
+						HTTP: &structs.ServiceRouteHTTPMatch{PathPrefix: "/admin"},
+					},
+					Destination: &structs.ServiceRouteDestination{Service: "admin"},
+				}},
+			},
+		)
+		require.NoError(t, err)
+		require.Equal(t, "http", resp.Chain.Protocol)
+		require.Contains(t, resp.Diff, `+   route 1: path prefix "/admin"`)
+	})
+
+	t.Run("invalid entries", func(t *testing.T) {
+		_, err := simulate(&structs.TerminatingGatewayConfigEntry{
+			Kind: structs.TerminatingGateway,
+			Name: "gateway",
+		})
+		testutil.RequireErrorContains(t, err, `config entry kind "terminating-gateway" does not affect discovery chains`)
+
+		_, err = simulate(&structs.ServiceResolverConfigEntry{
+			Kind:           structs.ServiceResolver,
+			Name:           "web",
+			ConnectTimeout: -1 * time.Second,
+		})
+		testutil.RequireErrorContains(t, err, "invalid config entry service-resolver/web")
+
+		_, err = simulate(
+			&structs.ServiceResolverConfigEntry{Kind: structs.ServiceResolver, Name: "web"},
+			&structs.ServiceResolverConfigEntry{Kind: structs.ServiceResolver, Name: "web"},
+		)
+		testutil.RequireErrorContains(t, err, "config entry service-resolver/web was given more than once")
+	})
+}
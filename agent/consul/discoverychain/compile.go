@@ -520,6 +520,9 @@ func (c *compiler) removeUnusedNodes() error {
 		case structs.DiscoveryGraphNodeTypeRouter:
 			for _, route := range node.Routes {
 				todo[route.NextNode] = struct{}{}
+				if route.MirrorNode != "" {
+					todo[route.MirrorNode] = struct{}{}
+				}
 			}
 		case structs.DiscoveryGraphNodeTypeSplitter:
 			for _, split := range node.Splits {
@@ -597,6 +600,16 @@ func (c *compiler) assembleChain() error {
 		return err
 	}
 
+	// Routers on non-HTTP services are rejected as a whole once the chain is
+	// compiled, so only HTTP services need their routes checked here.
+	if enableAdvancedRoutingForProtocol(c.protocol) {
+		if err := router.ValidateForProtocol(c.protocol); err != nil {
+			return &structs.ConfigEntryGraphError{
+				Message: fmt.Sprintf("service-router %q: %v", routerID.String(), err),
+			}
+		}
+	}
+
 	for i := range router.Routes {
 		// We don't use range variables here because we'll take the address of
 		// this route and store that in a DiscoveryGraphNode and the range
@@ -638,6 +651,25 @@ func (c *compiler) assembleChain() error {
 			return err
 		}
 		compiledRoute.NextNode = node.MapKey()
+
+		if mirror := dest.Mirror; mirror != nil {
+			// Mirrored requests are sent to a single cluster, so they are
+			// never split.
+			mirrorNode, err := c.getResolverNode(
+				c.newTarget(
+					defaultIfEmpty(mirror.Service, c.serviceName),
+					mirror.ServiceSubset,
+					defaultIfEmpty(mirror.Namespace, router.NamespaceOrDefault()),
+					defaultIfEmpty(mirror.Partition, router.PartitionOrDefault()),
+					"",
+				),
+				false,
+			)
+			if err != nil {
+				return err
+			}
+			compiledRoute.MirrorNode = mirrorNode.MapKey()
+		}
 	}
 
 	// If we have a router, we'll add a catch-all route at the end to send
@@ -936,9 +968,11 @@ RESOLVE_AGAIN:
 		Type: structs.DiscoveryGraphNodeTypeResolver,
 		Name: target.ID,
 		Resolver: &structs.DiscoveryResolver{
-			Default:        resolver.IsDefault(),
-			Target:         target.ID,
-			ConnectTimeout: connectTimeout,
+			Default:              resolver.IsDefault(),
+			Target:               target.ID,
+			ConnectTimeout:       connectTimeout,
+			OutlierDetection:     resolver.OutlierDetection,
+			PrioritizeByLocality: resolver.PrioritizeByLocality,
 		},
 		LoadBalancer: resolver.LoadBalancer,
 	}
@@ -30,6 +30,7 @@ func TestCompile(t *testing.T) {
 		"router with defaults and noop split and resolver": testcase_RouterWithDefaults_WithNoopSplit_WithResolver(),
 		"router with no destination":                       testcase_JustRouterWithNoDestination(),
 		"route bypasses splitter":                          testcase_RouteBypassesSplit(),
+		"route with fault injection and mirror":            testcase_RouteWithFaultInjectionAndMirror(),
 		"noop split":                                       testcase_NoopSplit_DefaultResolver(),
 		"noop split with protocol from proxy defaults":     testcase_NoopSplit_DefaultResolver_ProtocolFromProxyDefaults(),
 		"noop split with resolver":                         testcase_NoopSplit_WithResolver(),
@@ -53,6 +54,7 @@ func TestCompile(t *testing.T) {
 		"default resolver with proxy defaults":             testcase_DefaultResolver_WithProxyDefaults(),
 		"loadbalancer splitter and resolver":               testcase_LBSplitterAndResolver(),
 		"loadbalancer resolver":                            testcase_LBResolver(),
+		"outlier detection resolver":                       testcase_OutlierDetectionResolver(),
 		"service redirect to service with default resolver is not a default chain": testcase_RedirectToDefaultResolverIsNotDefaultChain(),
 		"service meta projection":               testcase_ServiceMetaProjection(),
 		"service meta projection with redirect": testcase_ServiceMetaProjectionWithRedirect(),
@@ -63,6 +65,7 @@ func TestCompile(t *testing.T) {
 		// various errors
 		"splitter requires valid protocol":        testcase_SplitterRequiresValidProtocol(),
 		"router requires valid protocol":          testcase_RouterRequiresValidProtocol(),
+		"grpc route requires grpc protocol":       testcase_GRPCRouteRequiresGRPCProtocol(),
 		"split to unsplittable protocol":          testcase_SplitToUnsplittableProtocol(),
 		"route to unroutable protocol":            testcase_RouteToUnroutableProtocol(),
 		"failover crosses protocols":              testcase_FailoverCrossesProtocols(),
@@ -596,6 +599,105 @@ func testcase_RouteBypassesSplit() compileTestCase {
 	return compileTestCase{entries: entries, expect: expect}
 }
 
+func testcase_RouteWithFaultInjectionAndMirror() compileTestCase {
+	entries := newEntries()
+	setServiceProtocol(entries, "main", "http")
+	setServiceProtocol(entries, "shadow", "http")
+
+	entries.AddRouters(
+		&structs.ServiceRouterConfigEntry{
+			Kind: "service-router",
+			Name: "main",
+			Routes: []structs.ServiceRoute{
+				newSimpleRoute("main", func(r *structs.ServiceRoute) {
+					r.Destination.FaultInjection = &structs.ServiceRouteFaultInjection{
+						Abort: &structs.ServiceRouteFaultAbort{
+							HTTPStatus: 503,
+							Percentage: 5,
+						},
+					}
+					r.Destination.Mirror = &structs.ServiceRouteMirror{
+						Service:       "shadow",
+						ServiceSubset: "v2",
+						Percentage:    10,
+					}
+				}),
+			},
+		},
+	)
+	entries.AddSplitters(
+		&structs.ServiceSplitterConfigEntry{
+			Kind: "service-splitter",
+			Name: "shadow",
+			Splits: []structs.ServiceSplit{
+				{Weight: 100, Service: "ignored"},
+			},
+		},
+	)
+	entries.AddResolvers(
+		&structs.ServiceResolverConfigEntry{
+			Kind: "service-resolver",
+			Name: "shadow",
+			Subsets: map[string]structs.ServiceResolverSubset{
+				"v2": {
+					Filter: "Service.Meta.version == v2",
+				},
+			},
+		},
+	)
+
+	router := entries.GetRouter(structs.NewServiceID("main", nil))
+
+	expect := &structs.CompiledDiscoveryChain{
+		Protocol:  "http",
+		StartNode: "router:main.default.default",
+		Nodes: map[string]*structs.DiscoveryGraphNode{
+			"router:main.default.default": {
+				Type: structs.DiscoveryGraphNodeTypeRouter,
+				Name: "main.default.default",
+				Routes: []*structs.DiscoveryRoute{
+					{
+						Definition: &router.Routes[0],
+						NextNode:   "resolver:main.default.default.dc1",
+						MirrorNode: "resolver:v2.shadow.default.default.dc1",
+					},
+					{
+						Definition: newDefaultServiceRoute("main", "default", "default"),
+						NextNode:   "resolver:main.default.default.dc1",
+					},
+				},
+			},
+			"resolver:main.default.default.dc1": {
+				Type: structs.DiscoveryGraphNodeTypeResolver,
+				Name: "main.default.default.dc1",
+				Resolver: &structs.DiscoveryResolver{
+					Default:        true,
+					ConnectTimeout: 5 * time.Second,
+					Target:         "main.default.default.dc1",
+				},
+			},
+			"resolver:v2.shadow.default.default.dc1": {
+				Type: structs.DiscoveryGraphNodeTypeResolver,
+				Name: "v2.shadow.default.default.dc1",
+				Resolver: &structs.DiscoveryResolver{
+					ConnectTimeout: 5 * time.Second,
+					Target:         "v2.shadow.default.default.dc1",
+				},
+			},
+		},
+		Targets: map[string]*structs.DiscoveryTarget{
+			"main.default.default.dc1": newTarget("main", "", "default", "default", "dc1", nil),
+			"v2.shadow.default.default.dc1": newTarget("shadow", "v2", "default", "default", "dc1", func(t *structs.DiscoveryTarget) {
+				t.Subset = structs.ServiceResolverSubset{
+					Filter: "Service.Meta.version == v2",
+				}
+			}),
+		},
+	}
+
+	return compileTestCase{entries: entries, expect: expect}
+}
+
 func testcase_NoopSplit_DefaultResolver() compileTestCase {
 	entries := newEntries()
 	setServiceProtocol(entries, "main", "http")
@@ -2234,6 +2336,32 @@ func testcase_RouterRequiresValidProtocol() compileTestCase {
 	}
 }
 
+func testcase_GRPCRouteRequiresGRPCProtocol() compileTestCase {
+	entries := newEntries()
+	setServiceProtocol(entries, "main", "http")
+
+	entries.AddRouters(
+		&structs.ServiceRouterConfigEntry{
+			Kind: structs.ServiceRouter,
+			Name: "main",
+			Routes: []structs.ServiceRoute{
+				{
+					Match: &structs.ServiceRouteMatch{
+						GRPC: &structs.ServiceRouteGRPCMatch{
+							Service: "helloworld.Greeter",
+						},
+					},
+				},
+			},
+		},
+	)
+	return compileTestCase{
+		entries:        entries,
+		expectErr:      "GRPC match criteria are only valid for the grpc protocol",
+		expectGraphErr: true,
+	}
+}
+
 func testcase_SplitToUnsplittableProtocol() compileTestCase {
 	entries := newEntries()
 	setServiceProtocol(entries, "main", "tcp")
@@ -2750,6 +2878,48 @@ func testcase_LBResolver() compileTestCase {
 	return compileTestCase{entries: entries, expect: expect}
 }
 
+func testcase_OutlierDetectionResolver() compileTestCase {
+	entries := newEntries()
+
+	entries.AddResolvers(
+		&structs.ServiceResolverConfigEntry{
+			Kind: "service-resolver",
+			Name: "main",
+			OutlierDetection: &structs.OutlierDetection{
+				Consecutive5xx:     3,
+				BaseEjectionTime:   time.Minute,
+				MaxEjectionPercent: 50,
+			},
+		},
+	)
+
+	expect := &structs.CompiledDiscoveryChain{
+		Protocol:  "tcp",
+		StartNode: "resolver:main.default.default.dc1",
+		Nodes: map[string]*structs.DiscoveryGraphNode{
+			"resolver:main.default.default.dc1": {
+				Type: structs.DiscoveryGraphNodeTypeResolver,
+				Name: "main.default.default.dc1",
+				Resolver: &structs.DiscoveryResolver{
+					Default:        false,
+					ConnectTimeout: 5 * time.Second,
+					Target:         "main.default.default.dc1",
+					OutlierDetection: &structs.OutlierDetection{
+						Consecutive5xx:     3,
+						BaseEjectionTime:   time.Minute,
+						MaxEjectionPercent: 50,
+					},
+				},
+			},
+		},
+		Targets: map[string]*structs.DiscoveryTarget{
+			"main.default.default.dc1": newTarget("main", "", "default", "default", "dc1", nil),
+		},
+	}
+
+	return compileTestCase{entries: entries, expect: expect}
+}
+
 func newSimpleRoute(name string, muts ...func(*structs.ServiceRoute)) structs.ServiceRoute {
 	r := structs.ServiceRoute{
 		Match: &structs.ServiceRouteMatch{
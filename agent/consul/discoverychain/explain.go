@@ -0,0 +1,288 @@
+package discoverychain
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/consul/agent/structs"
+)
+
+// Explain renders a compiled discovery chain as a human readable tree of the
+// routes, splits, resolvers and failover targets that a request follows,
+// starting at the start node of the chain.
+func Explain(chain *structs.CompiledDiscoveryChain) string {
+	if chain == nil {
+		return ""
+	}
+
+	e := &explainer{chain: chain}
+	e.line(0, "%s (protocol: %s, datacenter: %s)",
+		chain.ServiceName, defaultIfEmpty(chain.Protocol, structs.DefaultServiceProtocol), chain.Datacenter)
+	e.node(0, chain.StartNode)
+	return e.String()
+}
+
+type explainer struct {
+	chain *structs.CompiledDiscoveryChain
+	lines []string
+}
+
+func (e *explainer) String() string {
+	return strings.Join(e.lines, "\n") + "\n"
+}
+
+func (e *explainer) line(depth int, format string, args ...interface{}) {
+	e.lines = append(e.lines, strings.Repeat("  ", depth)+fmt.Sprintf(format, args...))
+}
+
+func (e *explainer) node(depth int, name string) {
+	node, ok := e.chain.Nodes[name]
+	if !ok {
+		e.line(depth, "missing node %q", name)
+		return
+	}
+
+	switch node.Type {
+	case structs.DiscoveryGraphNodeTypeRouter:
+		e.line(depth, "router: %s", node.Name)
+		for i, route := range node.Routes {
+			e.line(depth+1, "route %d: %s", i+1, explainRouteMatch(route.Definition))
+			if dest := explainRouteDestination(route.Definition); dest != "" {
+				e.line(depth+2, "%s", dest)
+			}
+			if route.MirrorNode != "" {
+				e.line(depth+2, "mirror %s%% to:", formatPercent(route.Definition.Destination.Mirror.Percentage))
+				e.node(depth+3, route.MirrorNode)
+			}
+			e.node(depth+2, route.NextNode)
+		}
+
+	case structs.DiscoveryGraphNodeTypeSplitter:
+		e.line(depth, "splitter: %s", node.Name)
+		for _, split := range node.Splits {
+			e.line(depth+1, "split %s%%:", formatPercent(split.Weight))
+			e.node(depth+2, split.NextNode)
+		}
+
+	case structs.DiscoveryGraphNodeTypeResolver:
+		e.resolver(depth, node)
+
+	default:
+		e.line(depth, "unknown node type %q: %s", node.Type, node.Name)
+	}
+}
+
+func (e *explainer) resolver(depth int, node *structs.DiscoveryGraphNode) {
+	resolver := node.Resolver
+	if resolver.Default {
+		e.line(depth, "resolver: %s (default)", node.Name)
+	} else {
+		e.line(depth, "resolver: %s", node.Name)
+	}
+
+	e.target(depth+1, "target", resolver.Target)
+	if resolver.ConnectTimeout > 0 {
+		e.line(depth+1, "connect timeout: %s", resolver.ConnectTimeout)
+	}
+	if node.LoadBalancer != nil && node.LoadBalancer.Policy != "" {
+		e.line(depth+1, "load balancer: %s", node.LoadBalancer.Policy)
+	}
+	if resolver.Failover != nil {
+		for i, targetID := range resolver.Failover.Targets {
+			e.target(depth+1, "failover "+strconv.Itoa(i+1), targetID)
+		}
+	}
+}
+
+func (e *explainer) target(depth int, label, id string) {
+	e.line(depth, "%s: %s", label, id)
+
+	target, ok := e.chain.Targets[id]
+	if !ok {
+		return
+	}
+	if target.Subset.Filter != "" {
+		e.line(depth+1, "subset filter: %s", target.Subset.Filter)
+	}
+	if target.Subset.OnlyPassing {
+		e.line(depth+1, "only passing instances")
+	}
+	if target.MeshGateway.Mode != structs.MeshGatewayModeDefault {
+		e.line(depth+1, "mesh gateway: %s", target.MeshGateway.Mode)
+	}
+	if target.External {
+		e.line(depth+1, "external service via terminating gateway")
+	}
+}
+
+func explainRouteMatch(route *structs.ServiceRoute) string {
+	if route == nil || route.Match == nil || route.Match.IsEmpty() {
+		return "default"
+	}
+
+	var parts []string
+	if m := route.Match.HTTP; m != nil {
+		switch {
+		case m.PathExact != "":
+			parts = append(parts, fmt.Sprintf("path exact %q", m.PathExact))
+		case m.PathPrefix != "":
+			parts = append(parts, fmt.Sprintf("path prefix %q", m.PathPrefix))
+		case m.PathRegex != "":
+			parts = append(parts, fmt.Sprintf("path regex %q", m.PathRegex))
+		}
+		parts = append(parts, explainHeaderMatches("header", m.Header)...)
+		for _, q := range m.QueryParam {
+			switch {
+			case q.Present:
+				parts = append(parts, fmt.Sprintf("query %q present", q.Name))
+			case q.Regex != "":
+				parts = append(parts, fmt.Sprintf("query %q regex %q", q.Name, q.Regex))
+			default:
+				parts = append(parts, fmt.Sprintf("query %q exact %q", q.Name, q.Exact))
+			}
+		}
+		if len(m.Methods) > 0 {
+			parts = append(parts, "methods "+strings.Join(m.Methods, ","))
+		}
+	}
+	if m := route.Match.GRPC; m != nil {
+		if m.Service != "" {
+			parts = append(parts, fmt.Sprintf("grpc service %q", m.Service))
+		}
+		if m.Method != "" {
+			parts = append(parts, fmt.Sprintf("grpc method %q", m.Method))
+		}
+		parts = append(parts, explainHeaderMatches("metadata", m.Header)...)
+	}
+	return strings.Join(parts, ", ")
+}
+
+func explainHeaderMatches(label string, headers []structs.ServiceRouteHTTPMatchHeader) []string {
+	var parts []string
+	for _, h := range headers {
+		var match string
+		switch {
+		case h.Present:
+			match = "present"
+		case h.Prefix != "":
+			match = fmt.Sprintf("prefix %q", h.Prefix)
+		case h.Suffix != "":
+			match = fmt.Sprintf("suffix %q", h.Suffix)
+		case h.Regex != "":
+			match = fmt.Sprintf("regex %q", h.Regex)
+		default:
+			match = fmt.Sprintf("exact %q", h.Exact)
+		}
+		if h.Invert {
+			match = "not " + match
+		}
+		parts = append(parts, fmt.Sprintf("%s %q %s", label, h.Name, match))
+	}
+	return parts
+}
+
+// explainRouteDestination summarizes the settings of a route destination that
+// change how a request is handled, other than where it is sent.
+func explainRouteDestination(route *structs.ServiceRoute) string {
+	if route == nil || route.Destination == nil {
+		return ""
+	}
+	dest := route.Destination
+
+	var parts []string
+	if dest.PrefixRewrite != "" {
+		parts = append(parts, fmt.Sprintf("prefix rewrite %q", dest.PrefixRewrite))
+	}
+	if dest.RequestTimeout > 0 {
+		parts = append(parts, "request timeout "+dest.RequestTimeout.String())
+	}
+	if dest.PerTryTimeout > 0 {
+		parts = append(parts, "per try timeout "+dest.PerTryTimeout.String())
+	}
+	if dest.HasRetryFeatures() {
+		var retryOn []string
+		if dest.RetryOnConnectFailure {
+			retryOn = append(retryOn, "connect-failure")
+		}
+		for _, code := range dest.RetryOnStatusCodes {
+			retryOn = append(retryOn, strconv.FormatUint(uint64(code), 10))
+		}
+		retryOn = append(retryOn, dest.RetryOnGRPCStatusCodes...)
+
+		retries := fmt.Sprintf("%d retries", dest.NumRetries)
+		if len(retryOn) > 0 {
+			retries += " on " + strings.Join(retryOn, ",")
+		}
+		parts = append(parts, retries)
+	}
+	if f := dest.FaultInjection; f != nil {
+		if f.Delay != nil {
+			parts = append(parts, fmt.Sprintf("delay %s for %s%%", f.Delay.Duration, formatPercent(f.Delay.Percentage)))
+		}
+		if f.Abort != nil {
+			parts = append(parts, fmt.Sprintf("abort with %d for %s%%", f.Abort.HTTPStatus, formatPercent(f.Abort.Percentage)))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+func formatPercent(p float32) string {
+	return strconv.FormatFloat(float64(p), 'f', -1, 32)
+}
+
+// Diff returns a line based diff between two explanations produced by
+// Explain. Lines only in from are prefixed with "- ", lines only in to with
+// "+ " and lines in both with two spaces. An empty string is returned when
+// the explanations are identical.
+func Diff(from, to string) string {
+	if from == to {
+		return ""
+	}
+	a := splitLines(from)
+	b := splitLines(to)
+
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and
+	// b[j:]. Explanations are small so the quadratic table is fine.
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var out strings.Builder
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			out.WriteString("  " + a[i] + "\n")
+			i++
+			j++
+		case j < len(b) && (i == len(a) || lcs[i][j+1] > lcs[i+1][j]):
+			out.WriteString("+ " + b[j] + "\n")
+			j++
+		default:
+			out.WriteString("- " + a[i] + "\n")
+			i++
+		}
+	}
+	return out.String()
+}
+
+func splitLines(s string) []string {
+	s = strings.TrimSuffix(s, "\n")
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, "\n")
+}
@@ -0,0 +1,136 @@
+package discoverychain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hashicorp/consul/agent/structs"
+)
+
+func TestExplain(t *testing.T) {
+	entries := []structs.ConfigEntry{
+		&structs.ProxyConfigEntry{
+			Kind: structs.ProxyDefaults,
+			Name: structs.ProxyConfigGlobal,
+			Config: map[string]interface{}{
+				"protocol": "http",
+			},
+		},
+		&structs.ServiceRouterConfigEntry{
+			Kind: structs.ServiceRouter,
+			Name: "web",
+			Routes: []structs.ServiceRoute{
+				{
+					Match: &structs.ServiceRouteMatch{
+						HTTP: &structs.ServiceRouteHTTPMatch{
+							PathPrefix: "/admin",
+							Header: []structs.ServiceRouteHTTPMatchHeader{
+								{Name: "x-debug", Present: true, Invert: true},
+							},
+							Methods: []string{"GET", "PUT"},
+						},
+					},
+					Destination: &structs.ServiceRouteDestination{
+						Service:               "admin",
+						PrefixRewrite:         "/",
+						RequestTimeout:        10 * time.Second,
+						NumRetries:            3,
+						RetryOnConnectFailure: true,
+						RetryOnStatusCodes:    []uint32{503},
+					},
+				},
+			},
+		},
+		&structs.ServiceSplitterConfigEntry{
+			Kind: structs.ServiceSplitter,
+			Name: "web",
+			Splits: []structs.ServiceSplit{
+				{Weight: 90, ServiceSubset: "v1"},
+				{Weight: 10, ServiceSubset: "v2"},
+			},
+		},
+		&structs.ServiceResolverConfigEntry{
+			Kind: structs.ServiceResolver,
+			Name: "web",
+			Subsets: map[string]structs.ServiceResolverSubset{
+				"v1": {Filter: "Service.Meta.version == v1"},
+				"v2": {Filter: "Service.Meta.version == v2", OnlyPassing: true},
+			},
+			Failover: map[string]structs.ServiceResolverFailover{
+				"*": {Datacenters: []string{"dc2"}},
+			},
+		},
+	}
+
+	chain := TestCompileConfigEntries(t, "web", "default", "default", "dc1", "trustdomain.consul", nil, entries...)
+
+	expect := `web (protocol: http, datacenter: dc1)
+router: web.default.default
+  route 1: path prefix "/admin", header "x-debug" not present, methods GET,PUT
+    prefix rewrite "/", request timeout 10s, 3 retries on connect-failure,503
+    resolver: admin.default.default.dc1 (default)
+      target: admin.default.default.dc1
+      connect timeout: 5s
+  route 2: path prefix "/"
+    splitter: web.default.default
+      split 90%:
+        resolver: v1.web.default.default.dc1
+          target: v1.web.default.default.dc1
+            subset filter: Service.Meta.version == v1
+          connect timeout: 5s
+          failover 1: v1.web.default.default.dc2
+            subset filter: Service.Meta.version == v1
+      split 10%:
+        resolver: v2.web.default.default.dc1
+          target: v2.web.default.default.dc1
+            subset filter: Service.Meta.version == v2
+            only passing instances
+          connect timeout: 5s
+          failover 1: v2.web.default.default.dc2
+            subset filter: Service.Meta.version == v2
+            only passing instances
+`
+	require.Equal(t, expect, Explain(chain))
+
+	t.Run("default chain", func(t *testing.T) {
+		chain := TestCompileConfigEntries(t, "api", "default", "default", "dc1", "trustdomain.consul", nil)
+
+		expect := `api (protocol: tcp, datacenter: dc1)
+resolver: api.default.default.dc1 (default)
+  target: api.default.default.dc1
+  connect timeout: 5s
+`
+		require.Equal(t, expect, Explain(chain))
+	})
+
+	t.Run("nil chain", func(t *testing.T) {
+		require.Empty(t, Explain(nil))
+	})
+}
+
+func TestDiff(t *testing.T) {
+	from := `web (protocol: tcp, datacenter: dc1)
+resolver: web.default.default.dc1 (default)
+  target: web.default.default.dc1
+  connect timeout: 5s
+`
+	to := `web (protocol: tcp, datacenter: dc1)
+resolver: web.default.default.dc1
+  target: web.default.default.dc1
+  connect timeout: 5s
+  failover 1: web.default.default.dc2
+`
+	expect := `  web (protocol: tcp, datacenter: dc1)
+- resolver: web.default.default.dc1 (default)
++ resolver: web.default.default.dc1
+    target: web.default.default.dc1
+    connect timeout: 5s
++   failover 1: web.default.default.dc2
+`
+	require.Equal(t, expect, Diff(from, to))
+	require.Empty(t, Diff(from, from))
+	require.Equal(t, "+ a\n+ b\n", Diff("", "a\nb\n"))
+	require.Equal(t, "- a\n- b\n", Diff("a\nb\n", ""))
+}
@@ -131,6 +131,8 @@ func init() {
 	registerCommand(structs.ACLAuthMethodDeleteRequestType, (*FSM).applyACLAuthMethodDeleteOperation)
 	registerCommand(structs.FederationStateRequestType, (*FSM).applyFederationStateOperation)
 	registerCommand(structs.SystemMetadataRequestType, (*FSM).applySystemMetadataOperation)
+	registerCommand(structs.PeeringRequestType, (*FSM).applyPeeringOperation)
+	registerCommand(structs.PeeringImportRequestType, (*FSM).applyPeeringImportOperation)
 }
 
 func (c *FSM) applyRegister(buf []byte, index uint64) interface{} {
@@ -440,6 +442,31 @@ func ApplyConnectCAOperationFromRequest(state *state.Store, req *structs.CAReque
 		}
 
 		return sn
+	case structs.CAOpRevoke:
+		if err := state.CARevoke(index, req.Revocation); err != nil {
+			return err
+		}
+
+		return true
+	case structs.CAOpDeleteRevocation:
+		if err := state.CADeleteRevocation(index, req.Revocation.ID); err != nil {
+			return err
+		}
+
+		return true
+	case structs.CAOpSetRevocations:
+		act, err := state.CASetRevocations(index, req.Index, req.Revocations, req.RevocationLists)
+		if err != nil {
+			return err
+		}
+
+		return act
+	case structs.CAOpSetRevocationList:
+		if err := state.CASetRevocationList(index, req.RevocationList); err != nil {
+			return err
+		}
+
+		return true
 	default:
 		return fmt.Errorf("Invalid CA operation '%s'", req.Op)
 	}
@@ -679,3 +706,40 @@ func (c *FSM) applySystemMetadataOperation(buf []byte, index uint64) interface{}
 		return fmt.Errorf("invalid system metadata operation type: %v", req.Op)
 	}
 }
+
+func (c *FSM) applyPeeringOperation(buf []byte, index uint64) interface{} {
+	var req structs.PeeringRequest
+	if err := structs.Decode(buf, &req); err != nil {
+		panic(fmt.Errorf("failed to decode request: %v", err))
+	}
+
+	switch req.Op {
+	case structs.PeeringUpsert:
+		defer metrics.MeasureSinceWithLabels([]string{"fsm", "peering"}, time.Now(),
+			[]metrics.Label{{Name: "op", Value: "upsert"}})
+		if err := c.state.PeeringWrite(index, req.Peering); err != nil {
+			return err
+		}
+		return true
+	case structs.PeeringDelete:
+		defer metrics.MeasureSinceWithLabels([]string{"fsm", "peering"}, time.Now(),
+			[]metrics.Label{{Name: "op", Value: "delete"}})
+		return c.state.PeeringDelete(index, req.Peering.Name)
+	default:
+		return fmt.Errorf("invalid peering operation type: %v", req.Op)
+	}
+}
+
+func (c *FSM) applyPeeringImportOperation(buf []byte, index uint64) interface{} {
+	var req structs.PeeringImportRequest
+	if err := structs.Decode(buf, &req); err != nil {
+		panic(fmt.Errorf("failed to decode request: %v", err))
+	}
+
+	defer metrics.MeasureSinceWithLabels([]string{"fsm", "peering", "import"}, time.Now(),
+		[]metrics.Label{{Name: "op", Value: string(req.Op)}})
+	if err := c.state.PeeringImport(index, &req); err != nil {
+		return err
+	}
+	return true
+}
@@ -24,6 +24,8 @@ func init() {
 	registerRestorer(structs.ConnectCARequestType, restoreConnectCA)
 	registerRestorer(structs.ConnectCAProviderStateType, restoreConnectCAProviderState)
 	registerRestorer(structs.ConnectCAConfigType, restoreConnectCAConfig)
+	registerRestorer(structs.ConnectCARevocationType, restoreConnectCARevocation)
+	registerRestorer(structs.ConnectCARevocationListType, restoreConnectCARevocationList)
 	registerRestorer(structs.IndexRequestType, restoreIndex)
 	registerRestorer(structs.ACLTokenSetRequestType, restoreToken)
 	registerRestorer(structs.ACLPolicySetRequestType, restorePolicy)
@@ -35,6 +37,8 @@ func init() {
 	registerRestorer(structs.SystemMetadataRequestType, restoreSystemMetadata)
 	registerRestorer(structs.ServiceVirtualIPRequestType, restoreServiceVirtualIP)
 	registerRestorer(structs.FreeVirtualIPRequestType, restoreFreeVirtualIP)
+	registerRestorer(structs.PeeringRequestType, restorePeering)
+	registerRestorer(structs.PeeringImportedServiceType, restorePeeringImportedService)
 }
 
 func persistOSS(s *snapshot, sink raft.SnapshotSink, encoder *codec.Encoder) error {
@@ -74,6 +78,9 @@ func persistOSS(s *snapshot, sink raft.SnapshotSink, encoder *codec.Encoder) err
 	if err := s.persistConnectCAConfig(sink, encoder); err != nil {
 		return err
 	}
+	if err := s.persistConnectCARevocations(sink, encoder); err != nil {
+		return err
+	}
 	if err := s.persistConfigEntries(sink, encoder); err != nil {
 		return err
 	}
@@ -83,6 +90,9 @@ func persistOSS(s *snapshot, sink raft.SnapshotSink, encoder *codec.Encoder) err
 	if err := s.persistSystemMetadata(sink, encoder); err != nil {
 		return err
 	}
+	if err := s.persistPeerings(sink, encoder); err != nil {
+		return err
+	}
 	if err := s.persistIndex(sink, encoder); err != nil {
 		return err
 	}
@@ -411,6 +421,70 @@ func (s *snapshot) persistConnectCAProviderState(sink raft.SnapshotSink,
 	return nil
 }
 
+func (s *snapshot) persistPeerings(sink raft.SnapshotSink,
+	encoder *codec.Encoder) error {
+	peerings, err := s.state.Peerings()
+	if err != nil {
+		return err
+	}
+
+	for _, p := range peerings {
+		if _, err := sink.Write([]byte{byte(structs.PeeringRequestType)}); err != nil {
+			return err
+		}
+		if err := encoder.Encode(p); err != nil {
+			return err
+		}
+	}
+
+	imported, err := s.state.PeeringImportedServices()
+	if err != nil {
+		return err
+	}
+
+	for _, svc := range imported {
+		if _, err := sink.Write([]byte{byte(structs.PeeringImportedServiceType)}); err != nil {
+			return err
+		}
+		if err := encoder.Encode(svc); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *snapshot) persistConnectCARevocations(sink raft.SnapshotSink,
+	encoder *codec.Encoder) error {
+	revocations, err := s.state.CARevocations()
+	if err != nil {
+		return err
+	}
+
+	for _, r := range revocations {
+		if _, err := sink.Write([]byte{byte(structs.ConnectCARevocationType)}); err != nil {
+			return err
+		}
+		if err := encoder.Encode(r); err != nil {
+			return err
+		}
+	}
+
+	lists, err := s.state.CARevocationLists()
+	if err != nil {
+		return err
+	}
+
+	for _, l := range lists {
+		if _, err := sink.Write([]byte{byte(structs.ConnectCARevocationListType)}); err != nil {
+			return err
+		}
+		if err := encoder.Encode(l); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (s *snapshot) persistLegacyIntentions(sink raft.SnapshotSink,
 	encoder *codec.Encoder) error {
 	//nolint:staticcheck
@@ -733,6 +807,28 @@ func restoreConnectCAProviderState(header *SnapshotHeader, restore *state.Restor
 	return nil
 }
 
+func restoreConnectCARevocation(header *SnapshotHeader, restore *state.Restore, decoder *codec.Decoder) error {
+	var req structs.CARevocation
+	if err := decoder.Decode(&req); err != nil {
+		return err
+	}
+	if err := restore.CARevocation(&req); err != nil {
+		return err
+	}
+	return nil
+}
+
+func restoreConnectCARevocationList(header *SnapshotHeader, restore *state.Restore, decoder *codec.Decoder) error {
+	var req structs.CARevocationList
+	if err := decoder.Decode(&req); err != nil {
+		return err
+	}
+	if err := restore.CARevocationList(&req); err != nil {
+		return err
+	}
+	return nil
+}
+
 func restoreConnectCAConfig(header *SnapshotHeader, restore *state.Restore, decoder *codec.Decoder) error {
 	var req structs.CAConfiguration
 	if err := decoder.Decode(&req); err != nil {
@@ -849,3 +945,25 @@ func restoreFreeVirtualIP(header *SnapshotHeader, restore *state.Restore, decode
 	}
 	return nil
 }
+
+func restorePeering(header *SnapshotHeader, restore *state.Restore, decoder *codec.Decoder) error {
+	var req structs.Peering
+	if err := decoder.Decode(&req); err != nil {
+		return err
+	}
+	if err := restore.Peering(&req); err != nil {
+		return err
+	}
+	return nil
+}
+
+func restorePeeringImportedService(header *SnapshotHeader, restore *state.Restore, decoder *codec.Decoder) error {
+	var req structs.PeeringImportedService
+	if err := decoder.Decode(&req); err != nil {
+		return err
+	}
+	if err := restore.PeeringImportedService(&req); err != nil {
+		return err
+	}
+	return nil
+}
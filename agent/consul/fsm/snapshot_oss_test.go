@@ -226,6 +226,21 @@ func TestFSM_SnapshotRestore_OSS(t *testing.T) {
 	err = fsm.state.CASetConfig(17, caConfig)
 	require.NoError(t, err)
 
+	// CA revocations
+	revocation := &structs.CARevocation{
+		ID:           "c31d9f8a-6a5b-4b0e-9c3c-1f0b9c8d2a11",
+		SerialNumber: "0a:1b",
+		Datacenter:   "dc1",
+		Reason:       "key compromise",
+	}
+	require.NoError(t, fsm.state.CARevoke(17, revocation))
+	revocationList := &structs.CARevocationList{
+		SigningKeyID: roots[0].SigningKeyID,
+		Datacenter:   "dc1",
+		PEM:          "crl",
+	}
+	require.NoError(t, fsm.state.CASetRevocationList(17, revocationList))
+
 	// Config entries
 	serviceConfig := &structs.ServiceConfigEntry{
 		Kind:     structs.ServiceDefaults,
@@ -465,6 +480,34 @@ func TestFSM_SnapshotRestore_OSS(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, vip, "240.0.0.2")
 
+	peering := &structs.Peering{
+		ID:                  "9e650110-ac74-4c5a-a6a8-9348b2bed4e9",
+		Name:                "east",
+		State:               structs.PeeringStateActive,
+		PeerID:              "b0a8d1a8-8f3f-4a5c-9b3e-1c4a0b1f6b0e",
+		PeerServerAddresses: []string{"10.0.0.1:8502"},
+		PeerMesh: &structs.PeeringMeshInfo{
+			TrustDomain:      "fe3a1ec4-2f4e-4c4a-9c43-0f3b0c8a5b2e.consul",
+			RootPEMs:         []string{"root-pem"},
+			Datacenter:       "dc2",
+			GatewayAddresses: []string{"10.0.0.2:443"},
+		},
+	}
+	require.NoError(t, fsm.state.PeeringWrite(31, peering))
+	require.NoError(t, fsm.state.PeeringImport(32, &structs.PeeringImportRequest{
+		Op:          structs.PeeringImportUpsertService,
+		PeerName:    "east",
+		ServiceName: "api",
+		Nodes: structs.CheckServiceNodes{
+			{
+				Node:    &structs.Node{Node: "east-node", Address: "10.0.0.3"},
+				Service: &structs.NodeService{ID: "api", Service: "api", Port: 8080},
+			},
+		},
+	}))
+	_, importedNodes, err := fsm.state.PeeringImportedServiceNodes(nil, "east", "api")
+	require.NoError(t, err)
+
 	_, serviceNames, err := fsm.state.ServiceNamesOfKind(nil, structs.ServiceKindTypical)
 	require.NoError(t, err)
 
@@ -709,6 +752,12 @@ func TestFSM_SnapshotRestore_OSS(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, caConfig, caConf)
 
+	// Verify CA revocations are restored.
+	_, revocations, revocationLists, err := fsm2.state.CARevocations(nil)
+	require.NoError(t, err)
+	require.Equal(t, []*structs.CARevocation{revocation}, revocations)
+	require.Equal(t, []*structs.CARevocationList{revocationList}, revocationLists)
+
 	// Verify config entries are restored
 	_, serviceConfEntry, err := fsm2.state.ConfigEntry(nil, structs.ServiceDefaults, "foo", structs.DefaultEnterpriseMetaInDefaultPartition())
 	require.NoError(t, err)
@@ -760,6 +809,14 @@ func TestFSM_SnapshotRestore_OSS(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, meshConfig, meshConfigEntry)
 
+	// Verify peerings and imported services are restored
+	_, peeringLoaded, err := fsm2.state.PeeringRead(nil, "east")
+	require.NoError(t, err)
+	require.Equal(t, peering, peeringLoaded)
+	_, importedNodesLoaded, err := fsm2.state.PeeringImportedServiceNodes(nil, "east", "api")
+	require.NoError(t, err)
+	require.Equal(t, importedNodes, importedNodesLoaded)
+
 	_, restoredServiceNames, err := fsm2.state.ServiceNamesOfKind(nil, structs.ServiceKindTypical)
 	require.NoError(t, err)
 
@@ -197,6 +197,8 @@ func (h *Health) ServiceNodes(args *structs.ServiceSpecificRequest, reply *struc
 	// Determine the function we'll call
 	var f func(memdb.WatchSet, *state.Store, *structs.ServiceSpecificRequest) (uint64, structs.CheckServiceNodes, error)
 	switch {
+	case args.PeerName != "":
+		f = h.serviceNodesPeered
 	case args.Connect:
 		f = h.serviceNodesConnect
 	case args.TagFilter:
@@ -322,6 +324,22 @@ func (h *Health) serviceNodesTagFilter(ws memdb.WatchSet, s *state.Store, args *
 	return s.CheckServiceTagNodes(ws, args.ServiceName, args.ServiceTags, &args.EnterpriseMeta)
 }
 
+// serviceNodesPeered returns the instances of a service imported from a peer.
+// They are reached through the mesh gateways of the peer, so Connect queries
+// return them as well.
+func (h *Health) serviceNodesPeered(ws memdb.WatchSet, s *state.Store, args *structs.ServiceSpecificRequest) (uint64, structs.CheckServiceNodes, error) {
+	var tags []string
+	if args.TagFilter {
+		tags = args.ServiceTags
+		// DEPRECATED (singular-service-tag) - remove this when backwards RPC compat
+		// with 1.2.x is not required.
+		if args.ServiceTag != "" {
+			tags = []string{args.ServiceTag}
+		}
+	}
+	return s.PeeringImportedServiceTagNodes(ws, args.PeerName, args.ServiceName, tags)
+}
+
 func (h *Health) serviceNodesDefault(ws memdb.WatchSet, s *state.Store, args *structs.ServiceSpecificRequest) (uint64, structs.CheckServiceNodes, error) {
 	return s.CheckServiceNodes(ws, args.ServiceName, &args.EnterpriseMeta)
 }
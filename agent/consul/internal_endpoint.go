@@ -224,7 +224,35 @@ func (m *Internal) IntentionUpstreams(args *structs.ServiceSpecificRequest, repl
 	if done, err := m.srv.ForwardRPC("Internal.IntentionUpstreams", args, reply); done {
 		return err
 	}
+	return m.internalUpstreams(args, reply, func(ws memdb.WatchSet, state *state.Store, sn structs.ServiceName, defaultDecision acl.EnforcementDecision) (uint64, structs.ServiceList, error) {
+		return state.IntentionTopology(ws, sn, false, defaultDecision)
+	})
+}
+
+// IntentionUpstreamsDestination returns the destinations a service is allowed
+// to reach. Destinations are declared in service-defaults config entries
+// rather than registered in the catalog.
+func (m *Internal) IntentionUpstreamsDestination(args *structs.ServiceSpecificRequest, reply *structs.IndexedServiceList) error {
+	// Exit early if Connect hasn't been enabled.
+	if !m.srv.config.ConnectEnabled {
+		return ErrConnectNotEnabled
+	}
+	if args.ServiceName == "" {
+		return fmt.Errorf("Must provide a service name")
+	}
+	if done, err := m.srv.ForwardRPC("Internal.IntentionUpstreamsDestination", args, reply); done {
+		return err
+	}
+	return m.internalUpstreams(args, reply, func(ws memdb.WatchSet, state *state.Store, sn structs.ServiceName, defaultDecision acl.EnforcementDecision) (uint64, structs.ServiceList, error) {
+		return state.IntentionTopologyDestinations(ws, sn, defaultDecision)
+	})
+}
 
+func (m *Internal) internalUpstreams(
+	args *structs.ServiceSpecificRequest,
+	reply *structs.IndexedServiceList,
+	fetch func(memdb.WatchSet, *state.Store, structs.ServiceName, acl.EnforcementDecision) (uint64, structs.ServiceList, error),
+) error {
 	authz, err := m.srv.ResolveTokenAndDefaultMeta(args.Token, &args.EnterpriseMeta, nil)
 	if err != nil {
 		return err
@@ -244,7 +272,7 @@ func (m *Internal) IntentionUpstreams(args *structs.ServiceSpecificRequest, repl
 			defaultDecision := authz.IntentionDefaultAllow(nil)
 
 			sn := structs.NewServiceName(args.ServiceName, &args.EnterpriseMeta)
-			index, services, err := state.IntentionTopology(ws, sn, false, defaultDecision)
+			index, services, err := fetch(ws, state, sn, defaultDecision)
 			if err != nil {
 				return err
 			}
@@ -2320,6 +2320,76 @@ func TestInternal_IntentionUpstreams(t *testing.T) {
 	})
 }
 
+func TestInternal_IntentionUpstreamsDestination(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+	dir1, s1 := testServer(t)
+	defer os.RemoveAll(dir1)
+	defer s1.Shutdown()
+
+	testrpc.WaitForLeader(t, s1.RPC, "dc1")
+
+	codec := rpcClient(t, s1)
+	defer codec.Close()
+
+	// Intentions
+	// * -> * (deny) intention
+	// web -> api (allow)
+	registerIntentionUpstreamEntries(t, codec, "")
+
+	// Destinations
+	// api is not a destination
+	// external is a destination, and web is allowed to reach it
+	// blocked is a destination, but web is denied by default
+	for _, name := range []string{"external", "blocked"} {
+		var out bool
+		require.NoError(t, msgpackrpc.CallWithCodec(codec, "ConfigEntry.Apply", &structs.ConfigEntryRequest{
+			Datacenter: "dc1",
+			Entry: &structs.ServiceConfigEntry{
+				Kind: structs.ServiceDefaults,
+				Name: name,
+				Destination: &structs.DestinationConfig{
+					Addresses: []string{name + ".example.com"},
+					Port:      443,
+				},
+			},
+		}, &out))
+		require.True(t, out)
+	}
+	var out bool
+	require.NoError(t, msgpackrpc.CallWithCodec(codec, "ConfigEntry.Apply", &structs.ConfigEntryRequest{
+		Datacenter: "dc1",
+		Entry: &structs.ServiceIntentionsConfigEntry{
+			Kind: structs.ServiceIntentions,
+			Name: "external",
+			Sources: []*structs.SourceIntention{
+				{
+					Name:   "web",
+					Action: structs.IntentionActionAllow,
+				},
+			},
+		},
+	}, &out))
+	require.True(t, out)
+
+	retry.Run(t, func(r *retry.R) {
+		args := structs.ServiceSpecificRequest{
+			Datacenter:  "dc1",
+			ServiceName: "web",
+		}
+		var out structs.IndexedServiceList
+		require.NoError(r, msgpackrpc.CallWithCodec(codec, "Internal.IntentionUpstreamsDestination", &args, &out))
+
+		expectUp := structs.ServiceList{
+			structs.NewServiceName("external", structs.DefaultEnterpriseMetaInDefaultPartition()),
+		}
+		require.Equal(r, expectUp, out.Services)
+	})
+}
+
 func TestInternal_IntentionUpstreams_BlockOnNoChange(t *testing.T) {
 	if testing.Short() {
 		t.Skip("too slow for testing.Short")
@@ -305,6 +305,8 @@ func (s *Server) establishLeadership(ctx context.Context) error {
 
 	s.startFederationStateAntiEntropy(ctx)
 
+	s.startPeeringStreams(ctx)
+
 	if err := s.startConnectLeader(ctx); err != nil {
 		return err
 	}
@@ -336,6 +338,8 @@ func (s *Server) revokeLeadership() {
 
 	s.stopFederationStateAntiEntropy()
 
+	s.stopPeeringStreams()
+
 	s.stopFederationStateReplication()
 
 	s.stopConfigReplication()
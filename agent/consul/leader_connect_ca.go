@@ -11,6 +11,8 @@ import (
 	"sync"
 	"time"
 
+	"github.com/armon/go-metrics"
+	"github.com/armon/go-metrics/prometheus"
 	"github.com/hashicorp/go-hclog"
 	uuid "github.com/hashicorp/go-uuid"
 	"golang.org/x/time/rate"
@@ -35,6 +37,40 @@ const (
 	caStateReconfig          caState = "RECONFIGURING"
 )
 
+var (
+	metricsKeyCARootRotation            = []string{"leader", "ca", "root_rotation"}
+	metricsKeyCARootRotationStarted     = []string{"leader", "ca", "root_rotation", "started"}
+	metricsKeyCARootRotationCrossSigned = []string{"leader", "ca", "root_rotation", "cross_signed"}
+	metricsKeyCARootRotationCompleted   = []string{"leader", "ca", "root_rotation", "completed"}
+	metricsKeyCARootRotationFailed      = []string{"leader", "ca", "root_rotation", "failed"}
+)
+
+var CARootRotationCounters = []prometheus.CounterDefinition{
+	{
+		Name: metricsKeyCARootRotationStarted,
+		Help: "Increments when the leader starts an automatic rotation of the Connect CA root.",
+	},
+	{
+		Name: metricsKeyCARootRotationCrossSigned,
+		Help: "Increments when a new Connect CA root is cross-signed by the previous root during a rotation.",
+	},
+	{
+		Name: metricsKeyCARootRotationCompleted,
+		Help: "Increments when an automatic rotation of the Connect CA root completes.",
+	},
+	{
+		Name: metricsKeyCARootRotationFailed,
+		Help: "Increments when an automatic rotation of the Connect CA root fails.",
+	},
+}
+
+var CARootRotationSummaries = []prometheus.SummaryDefinition{
+	{
+		Name: metricsKeyCARootRotation,
+		Help: "Measures the time taken by an automatic rotation of the Connect CA root.",
+	},
+}
+
 // caServerDelegate is an interface for server operations for facilitating
 // easier testing.
 type caServerDelegate interface {
@@ -46,6 +82,7 @@ type caServerDelegate interface {
 
 	forwardDC(method, dc string, args interface{}, reply interface{}) error
 	generateCASignRequest(csr string) *structs.CASignRequest
+	generateCARevocationListRequest(list *structs.CARevocationList) *structs.CARevocationListRequest
 
 	ServersSupportMultiDCConnectCA() error
 }
@@ -129,6 +166,14 @@ func (c *caDelegateWithState) generateCASignRequest(csr string) *structs.CASignR
 	}
 }
 
+func (c *caDelegateWithState) generateCARevocationListRequest(list *structs.CARevocationList) *structs.CARevocationListRequest {
+	return &structs.CARevocationListRequest{
+		Datacenter:     c.Server.config.PrimaryDatacenter,
+		RevocationList: list,
+		WriteRequest:   structs.WriteRequest{Token: c.Server.tokens.ReplicationToken()},
+	}
+}
+
 func (c *caDelegateWithState) ServersSupportMultiDCConnectCA() error {
 	versionOk, primaryFound := ServersInDCMeetMinimumVersion(c.Server, c.Server.config.PrimaryDatacenter, minMultiDCConnectVersion)
 	if !primaryFound {
@@ -332,6 +377,9 @@ func (c *CAManager) Start(ctx context.Context) {
 func (c *CAManager) Stop() {
 	c.leaderRoutineManager.Stop(secondaryCARootWatchRoutineName)
 	c.leaderRoutineManager.Stop(intermediateCertRenewWatchRoutineName)
+	c.leaderRoutineManager.Stop(caRootRotationRoutineName)
+	c.leaderRoutineManager.Stop(caRevocationListRoutineName)
+	c.leaderRoutineManager.Stop(secondaryCARevocationWatchRoutineName)
 	c.leaderRoutineManager.Stop(backgroundCAInitializationRoutineName)
 
 	if provider, _ := c.getCAProvider(); provider != nil {
@@ -349,9 +397,15 @@ func (c *CAManager) startPostInitializeRoutines(ctx context.Context) {
 	// Start the Connect secondary DC actions if enabled.
 	if c.serverConf.Datacenter != c.serverConf.PrimaryDatacenter {
 		c.leaderRoutineManager.Start(ctx, secondaryCARootWatchRoutineName, c.secondaryCARootWatch)
+		c.leaderRoutineManager.Start(ctx, secondaryCARevocationWatchRoutineName, c.secondaryCARevocationWatch)
+	} else {
+		// Secondaries pick up a rotated root through the roots watch so only
+		// the primary rotates it.
+		c.leaderRoutineManager.Start(ctx, caRootRotationRoutineName, c.runRotateRoot)
 	}
 
 	c.leaderRoutineManager.Start(ctx, intermediateCertRenewWatchRoutineName, c.runRenewIntermediate)
+	c.leaderRoutineManager.Start(ctx, caRevocationListRoutineName, c.runRevocationList)
 }
 
 func (c *CAManager) backgroundCAInitialization(ctx context.Context) error {
@@ -458,6 +512,8 @@ func (c *CAManager) newProvider(conf *structs.CAConfiguration) (ca.Provider, err
 		return ca.NewVaultProvider(logger), nil
 	case structs.AWSCAProvider:
 		return ca.NewAWSProvider(logger), nil
+	case structs.PKCS11CAProvider:
+		return ca.NewPKCS11Provider(logger)
 	default:
 		if c.providerShim != nil {
 			return c.providerShim, nil
@@ -985,6 +1041,10 @@ func (c *CAManager) primaryUpdateRootCA(newProvider ca.Provider, args *structs.C
 			// Add the cross signed cert to the new CA's intermediates (to be attached
 			// to leaf certs).
 			newActiveRoot.IntermediateCerts = []string{xcCert}
+
+			metrics.IncrCounter(metricsKeyCARootRotationCrossSigned, 1)
+			c.logger.Info("CA root rotation: new root cross-signed by the previous root",
+				"phase", "cross-signed", "root", newActiveRoot.ID)
 		}
 	}
 
@@ -1203,6 +1263,153 @@ func (c *CAManager) RenewIntermediate(ctx context.Context, isPrimary bool) error
 	return nil
 }
 
+// runRotateRoot periodically checks whether the active root has been in use
+// for longer than the configured RootRotationPeriod and rotates it if so.
+func (c *CAManager) runRotateRoot(ctx context.Context) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(structs.RootRotationCheckInterval):
+			if err := c.RotateRootIfNeeded(); err != nil {
+				c.logger.Error("error rotating CA root",
+					"routine", caRootRotationRoutineName,
+					"error", err,
+				)
+			}
+		}
+	}
+}
+
+// RotateRootIfNeeded rotates the active root in the primary datacenter once it
+// has been in use for longer than the configured RootRotationPeriod. The new
+// root is cross-signed by the old one, following the same process as a
+// configuration change that results in a new root.
+func (c *CAManager) RotateRootIfNeeded() error {
+	if c.serverConf.Datacenter != c.serverConf.PrimaryDatacenter {
+		return nil
+	}
+
+	state := c.delegate.State()
+	_, config, err := state.CAConfig(nil)
+	if err != nil {
+		return err
+	}
+	if config == nil {
+		return nil
+	}
+
+	common, err := config.GetCommonConfig()
+	if err != nil {
+		return err
+	}
+	if common.RootRotationPeriod <= 0 {
+		return nil
+	}
+
+	_, root, err := state.CARootActive(nil)
+	if err != nil {
+		return err
+	}
+	if root == nil {
+		return nil
+	}
+	if c.timeNow().Before(root.NotBefore.Add(common.RootRotationPeriod)) {
+		return nil
+	}
+
+	return c.rotateRoot(root)
+}
+
+// rotateRoot generates a new root with the current provider's key material
+// rotated and makes it the active root.
+func (c *CAManager) rotateRoot(oldRoot *structs.CARoot) (reterr error) {
+	// Take the 'lock' so the provider/config can't be changed out while the
+	// root is rotated.
+	oldState, err := c.setState(caStateReconfig, true)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if reterr == nil {
+			c.setState(caStateInitialized, false)
+		} else {
+			c.setState(oldState, false)
+		}
+	}()
+
+	start := time.Now()
+	metrics.IncrCounter(metricsKeyCARootRotationStarted, 1)
+	c.logger.Info("CA root rotation: started",
+		"phase", "started", "root", oldRoot.ID, "not_before", oldRoot.NotBefore)
+
+	if err := c.primaryRotateRoot(); err != nil {
+		metrics.IncrCounter(metricsKeyCARootRotationFailed, 1)
+		c.logger.Error("CA root rotation: failed", "phase", "failed", "root", oldRoot.ID, "error", err)
+		return err
+	}
+
+	metrics.MeasureSince(metricsKeyCARootRotation, start)
+	metrics.IncrCounter(metricsKeyCARootRotationCompleted, 1)
+	c.logger.Info("CA root rotation: completed", "phase", "completed", "previous_root", oldRoot.ID)
+	return nil
+}
+
+// primaryRotateRoot configures a new instance of the current provider with
+// the state returned by ca.RootRotator and rotates to its root.
+// It should only be called while the state lock is held by setting the state to non-ready.
+func (c *CAManager) primaryRotateRoot() error {
+	provider, _ := c.getCAProvider()
+	if provider == nil {
+		return fmt.Errorf("internal error: CA provider is nil")
+	}
+
+	state := c.delegate.State()
+	_, config, err := state.CAConfig(nil)
+	if err != nil {
+		return err
+	}
+	if config == nil {
+		return fmt.Errorf("CA configuration is not initialized")
+	}
+
+	rotator, ok := provider.(ca.RootRotator)
+	if !ok {
+		return fmt.Errorf("the %s CA provider does not support automatic root rotation", providerPrettyName(config.Provider))
+	}
+	newState, err := rotator.RootRotationState()
+	if err != nil {
+		return fmt.Errorf("error getting root rotation state: %w", err)
+	}
+
+	newConfig := *config
+	newConfig.State = newState
+
+	newProvider, err := c.newProvider(&newConfig)
+	if err != nil {
+		return fmt.Errorf("could not initialize provider: %w", err)
+	}
+	pCfg := ca.ProviderConfig{
+		ClusterID:  newConfig.ClusterID,
+		Datacenter: c.serverConf.Datacenter,
+		IsPrimary:  true,
+		RawConfig:  newConfig.Config,
+		State:      newConfig.State,
+	}
+	if err := newProvider.Configure(pCfg); err != nil {
+		return fmt.Errorf("error configuring provider: %w", err)
+	}
+
+	args := &structs.CARequest{Config: &newConfig}
+	if err := c.primaryUpdateRootCA(newProvider, args, config); err != nil {
+		if err := newProvider.Cleanup(false, newConfig.Config); err != nil {
+			c.logger.Warn("failed to clean up CA provider after failed root rotation", "provider", newProvider, "error", err)
+		}
+		return err
+	}
+	return nil
+}
+
 // secondaryCARootWatch maintains a blocking query to the primary datacenter's
 // ConnectCA.Roots endpoint to monitor when it needs to request a new signed
 // intermediate certificate.
@@ -1590,6 +1797,8 @@ func providerPrettyName(provider string) string {
 		return "Vault"
 	case "aws-pca":
 		return "Aws-Pca"
+	case "pkcs11":
+		return "PKCS11"
 	case "provider-name":
 		return "Provider-Name"
 	default:
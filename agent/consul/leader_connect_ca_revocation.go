@@ -0,0 +1,302 @@
+package consul
+
+import (
+	"context"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/hashicorp/go-memdb"
+
+	"github.com/hashicorp/consul/agent/connect"
+	"github.com/hashicorp/consul/agent/connect/ca"
+	"github.com/hashicorp/consul/agent/structs"
+	"github.com/hashicorp/consul/lib/stringslice"
+)
+
+const (
+	// caRevocationListValidity is how long a revocation list signed by the
+	// leader is valid for. The list is signed again once half of this has
+	// elapsed so that proxies never see an expired list.
+	caRevocationListValidity = 24 * time.Hour
+
+	// caRevocationListRetryInterval is how long to wait before trying to sign
+	// or publish the revocation list again after a failure.
+	caRevocationListRetryInterval = 30 * time.Second
+)
+
+// runRevocationList keeps the revocation list signed by the local CA up to
+// date with the revoked serial numbers. In the primary datacenter it also
+// prunes revocations and lists that have expired.
+func (c *CAManager) runRevocationList(ctx context.Context) error {
+	for {
+		ws := memdb.NewWatchSet()
+		state := c.delegate.State()
+
+		// Watch the roots too so a new signing key gets a list right away.
+		_, _, err := state.CARoots(ws)
+		wait := caRevocationListValidity / 2
+		if err == nil {
+			wait, err = c.UpdateRevocationList(ws)
+		}
+		if err != nil {
+			c.logger.Error("error updating CA revocation list",
+				"routine", caRevocationListRoutineName,
+				"error", err,
+			)
+			wait = caRevocationListRetryInterval
+		}
+
+		waitCtx, cancel := context.WithTimeout(ctx, wait)
+		ws.WatchCtx(waitCtx)
+		cancel()
+
+		select {
+		case <-ctx.Done():
+			return nil
+		default:
+		}
+	}
+}
+
+// UpdateRevocationList prunes expired revocations in the primary datacenter
+// and signs a new revocation list with the local CA if the revoked serial
+// numbers changed or the current list is due to be refreshed. It returns how
+// long to wait before the list needs to be checked again, unless ws fires.
+func (c *CAManager) UpdateRevocationList(ws memdb.WatchSet) (time.Duration, error) {
+	wait := caRevocationListValidity / 2
+	now := c.timeNow()
+	isPrimary := c.serverConf.Datacenter == c.serverConf.PrimaryDatacenter
+
+	index, revocations, lists, err := c.delegate.State().CARevocations(ws)
+	if err != nil {
+		return 0, err
+	}
+
+	if isPrimary {
+		pruned, err := c.pruneRevocations(index, revocations, lists, now)
+		if err != nil || pruned {
+			// The watch fires for the pruning so the list is signed with the
+			// remaining revocations on the next pass.
+			return wait, err
+		}
+	}
+
+	// Serial numbers are only checked by proxies once a list is configured, at
+	// which point every CA in the chain must have published one. A list is
+	// kept for the active signing key even when nothing is revoked so that one
+	// exists for every CA, including a previous root during a rotation, by the
+	// time a certificate is revoked.
+	var revoked []*structs.CARevocation
+	for _, r := range revocations {
+		if r.SerialNumber == "" || !now.Before(r.ExpiresAt) {
+			continue
+		}
+		if r.Datacenter == c.serverConf.Datacenter {
+			revoked = append(revoked, r)
+		}
+	}
+	sort.Slice(revoked, func(i, j int) bool {
+		return revoked[i].SerialNumber < revoked[j].SerialNumber
+	})
+	serials := make([]string, 0, len(revoked))
+	for _, r := range revoked {
+		serials = append(serials, r.SerialNumber)
+	}
+
+	provider, root := c.getCAProvider()
+	if provider == nil || root == nil {
+		return wait, nil
+	}
+	signer, ok := provider.(ca.RevocationListSigner)
+	if !ok {
+		// Revoking by serial number is refused while this is the case, see
+		// checkRevocationListSigners.
+		return wait, nil
+	}
+
+	for _, l := range lists {
+		if l.SigningKeyID != root.SigningKeyID {
+			continue
+		}
+		refreshAt := l.NextUpdate.Add(-caRevocationListValidity / 2)
+		if stringslice.Equal(l.SerialNumbers, serials) && now.Before(refreshAt) {
+			return refreshAt.Sub(now), nil
+		}
+	}
+
+	template := &x509.RevocationList{
+		Number:     big.NewInt(now.UnixNano()),
+		ThisUpdate: now.Add(-ca.CertificateTimeDriftBuffer),
+		NextUpdate: now.Add(caRevocationListValidity),
+	}
+	for _, r := range revoked {
+		serial, err := connect.ParseSerialNumber(r.SerialNumber)
+		if err != nil {
+			return 0, err
+		}
+		template.RevokedCertificates = append(template.RevokedCertificates, pkix.RevokedCertificate{
+			SerialNumber:   serial,
+			RevocationTime: r.RevokedAt,
+		})
+	}
+
+	pem, err := signer.SignRevocationList(template)
+	if err != nil {
+		return 0, fmt.Errorf("error signing revocation list: %w", err)
+	}
+
+	list := &structs.CARevocationList{
+		SigningKeyID:  root.SigningKeyID,
+		Datacenter:    c.serverConf.Datacenter,
+		PEM:           pem,
+		SerialNumbers: serials,
+		NextUpdate:    template.NextUpdate,
+	}
+
+	if isPrimary {
+		args := &structs.CARequest{
+			Op:             structs.CAOpSetRevocationList,
+			RevocationList: list,
+		}
+		if _, err := c.delegate.ApplyCARequest(args); err != nil {
+			return 0, fmt.Errorf("error storing revocation list: %w", err)
+		}
+	} else {
+		var reply interface{}
+		args := c.delegate.generateCARevocationListRequest(list)
+		if err := c.delegate.forwardDC("ConnectCA.PublishRevocationList", c.serverConf.PrimaryDatacenter, args, &reply); err != nil {
+			return 0, fmt.Errorf("error publishing revocation list to the primary datacenter: %w", err)
+		}
+	}
+
+	c.logger.Info("signed CA revocation list",
+		"signing_key_id", root.SigningKeyID,
+		"revoked", len(serials),
+		"next_update", list.NextUpdate,
+	)
+	return wait, nil
+}
+
+// checkRevocationListSigners returns an error unless the CA of every one of
+// the given datacenters can sign a revocation list. Once a certificate is
+// revoked by serial number proxies reject every certificate whose CA has not
+// published a list, so revoking one is refused until all of them can.
+func (c *CAManager) checkRevocationListSigners(datacenters []string) error {
+	provider, _ := c.getCAProvider()
+	if provider == nil {
+		return fmt.Errorf("CA has not finished initializing")
+	}
+	if _, ok := provider.(ca.RevocationListSigner); !ok {
+		return fmt.Errorf("the CA provider of datacenter %q cannot sign certificate revocation lists; "+
+			"revoke the certificate by SpiffeID instead", c.serverConf.Datacenter)
+	}
+
+	_, _, lists, err := c.delegate.State().CARevocations(nil)
+	if err != nil {
+		return err
+	}
+	now := c.timeNow()
+	published := make(map[string]bool)
+	for _, l := range lists {
+		if now.Before(l.NextUpdate) {
+			published[l.Datacenter] = true
+		}
+	}
+	for _, dc := range datacenters {
+		if dc == c.serverConf.Datacenter || published[dc] {
+			continue
+		}
+		return fmt.Errorf("datacenter %q has not published a certificate revocation list, its CA provider "+
+			"may not be able to sign one; revoke the certificate by SpiffeID instead", dc)
+	}
+	return nil
+}
+
+// pruneRevocations removes the revocations that no longer affect any valid
+// certificate and the revocation lists that have expired. It returns true if
+// anything was removed.
+func (c *CAManager) pruneRevocations(index uint64, revocations []*structs.CARevocation, lists []*structs.CARevocationList, now time.Time) (bool, error) {
+	keepRevocations := make([]*structs.CARevocation, 0, len(revocations))
+	for _, r := range revocations {
+		if now.Before(r.ExpiresAt) {
+			keepRevocations = append(keepRevocations, r)
+		}
+	}
+	keepLists := make([]*structs.CARevocationList, 0, len(lists))
+	for _, l := range lists {
+		if now.Before(l.NextUpdate) {
+			keepLists = append(keepLists, l)
+		}
+	}
+	if len(keepRevocations) == len(revocations) && len(keepLists) == len(lists) {
+		return false, nil
+	}
+
+	args := &structs.CARequest{
+		Op:              structs.CAOpSetRevocations,
+		Index:           index,
+		Revocations:     keepRevocations,
+		RevocationLists: keepLists,
+	}
+	if _, err := c.delegate.ApplyCARequest(args); err != nil {
+		return false, fmt.Errorf("error pruning revocations: %w", err)
+	}
+
+	c.logger.Info("pruned expired CA revocations",
+		"revocations", len(revocations)-len(keepRevocations),
+		"revocation_lists", len(lists)-len(keepLists),
+	)
+	return true, nil
+}
+
+// secondaryCARevocationWatch maintains a blocking query to the primary
+// datacenter's ConnectCA.RevocationList endpoint and replicates the
+// revocations and revocation lists into the local state.
+func (c *CAManager) secondaryCARevocationWatch(ctx context.Context) error {
+	args := structs.DCSpecificRequest{
+		Datacenter: c.serverConf.PrimaryDatacenter,
+		QueryOptions: structs.QueryOptions{
+			MaxQueryTime: c.serverConf.MaxQueryTime,
+		},
+	}
+
+	c.logger.Debug("starting Connect CA revocation replication from primary datacenter", "primary", c.serverConf.PrimaryDatacenter)
+
+	retryLoopBackoff(ctx, func() error {
+		var reply structs.IndexedCARevocations
+		if err := c.delegate.forwardDC("ConnectCA.RevocationList", c.serverConf.PrimaryDatacenter, &args, &reply); err != nil {
+			return fmt.Errorf("Error retrieving the primary datacenter's revocations: %v", err)
+		}
+
+		// Return if the context has been canceled while waiting on the RPC.
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+
+		if reply.QueryMeta.Index != args.QueryOptions.MinQueryIndex {
+			req := &structs.CARequest{
+				Op:              structs.CAOpSetRevocations,
+				Revocations:     reply.Revocations,
+				RevocationLists: reply.RevocationLists,
+			}
+			if _, err := c.delegate.ApplyCARequest(req); err != nil {
+				return fmt.Errorf("Error storing the primary datacenter's revocations: %v", err)
+			}
+		}
+		args.QueryOptions.MinQueryIndex = nextIndexVal(args.QueryOptions.MinQueryIndex, reply.QueryMeta.Index)
+		return nil
+	}, func(err error) {
+		c.logger.Error("CA revocation replication failed, will retry",
+			"routine", secondaryCARevocationWatchRoutineName,
+			"error", err,
+		)
+	})
+
+	return nil
+}
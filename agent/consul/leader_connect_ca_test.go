@@ -233,6 +233,13 @@ func (m *mockCAServerDelegate) generateCASignRequest(csr string) *structs.CASign
 	}
 }
 
+func (m *mockCAServerDelegate) generateCARevocationListRequest(list *structs.CARevocationList) *structs.CARevocationListRequest {
+	return &structs.CARevocationListRequest{
+		Datacenter:     m.config.PrimaryDatacenter,
+		RevocationList: list,
+	}
+}
+
 // mockCAProvider mocks an empty provider implementation with a channel in order to coordinate
 // waiting for certain methods to be called.
 type mockCAProvider struct {
@@ -1042,3 +1049,112 @@ func setupPrimaryCA(t *testing.T, client *vaultapi.Client, path string, rootPEM
 	require.NoError(t, err, "failed to set signed intermediate")
 	return ca.EnsureTrailingNewline(buf.String())
 }
+
+func TestCAManager_RotateRootIfNeeded(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+	_, s1 := testServerWithConfig(t, func(c *Config) {
+		c.CAConfig = &structs.CAConfiguration{
+			Provider: "consul",
+			Config: map[string]interface{}{
+				"LeafCertTTL":         "72h",
+				"IntermediateCertTTL": "8760h",
+				"RootCertTTL":         "87600h",
+				"RootRotationPeriod":  "168h",
+			},
+		}
+	})
+	defer s1.Shutdown()
+	testrpc.WaitForLeader(t, s1.RPC, "dc1")
+
+	state := s1.fsm.State()
+	var oldRoot *structs.CARoot
+	retry.Run(t, func(r *retry.R) {
+		_, root, err := state.CARootActive(nil)
+		require.NoError(r, err)
+		require.NotNil(r, root)
+		oldRoot = root
+	})
+
+	// Nothing happens before the rotation period has elapsed.
+	require.NoError(t, s1.caManager.RotateRootIfNeeded())
+	_, root, err := state.CARootActive(nil)
+	require.NoError(t, err)
+	require.Equal(t, oldRoot.ID, root.ID)
+
+	s1.caManager.timeNow = func() time.Time {
+		return time.Now().Add(200 * time.Hour)
+	}
+	require.NoError(t, s1.caManager.RotateRootIfNeeded())
+
+	_, roots, err := state.CARoots(nil)
+	require.NoError(t, err)
+	require.Len(t, roots, 2)
+
+	var newRoot *structs.CARoot
+	for _, r := range roots {
+		if r.ID == oldRoot.ID {
+			require.False(t, r.Active)
+			require.False(t, r.RotatedOutAt.IsZero())
+		} else {
+			newRoot = r
+		}
+	}
+	require.NotNil(t, newRoot)
+	require.True(t, newRoot.Active)
+	require.NotEqual(t, oldRoot.SigningKeyID, newRoot.SigningKeyID)
+	require.Len(t, newRoot.IntermediateCerts, 1, "new root should be cross-signed by the old one")
+
+	_, config, err := state.CAConfig(nil)
+	require.NoError(t, err)
+	require.Equal(t, "1", config.State["root_generation"])
+}
+
+// mockCARevocationListSigner is a mockCAProvider that can sign revocation
+// lists.
+type mockCARevocationListSigner struct {
+	*mockCAProvider
+}
+
+func (m *mockCARevocationListSigner) SignRevocationList(*x509.RevocationList) (string, error) {
+	return "", nil
+}
+
+func TestCAManager_CheckRevocationListSigners(t *testing.T) {
+	conf := DefaultConfig()
+	conf.ConnectEnabled = true
+	conf.PrimaryDatacenter = "dc1"
+	conf.Datacenter = "dc1"
+	delegate := NewMockCAServerDelegate(t, conf)
+	manager := NewCAManager(delegate, nil, testutil.Logger(t), conf)
+
+	// The local provider must be able to sign a list.
+	manager.setCAProvider(&mockCAProvider{}, delegate.primaryRoot)
+	err := manager.checkRevocationListSigners([]string{"dc1"})
+	testutil.RequireErrorContains(t, err, `the CA provider of datacenter "dc1" cannot sign certificate revocation lists`)
+
+	manager.setCAProvider(&mockCARevocationListSigner{&mockCAProvider{}}, delegate.primaryRoot)
+	require.NoError(t, manager.checkRevocationListSigners([]string{"dc1"}))
+
+	// Other datacenters must have published a list that is still current.
+	err = manager.checkRevocationListSigners([]string{"dc1", "dc2"})
+	testutil.RequireErrorContains(t, err, `datacenter "dc2" has not published a certificate revocation list`)
+
+	require.NoError(t, delegate.store.CASetRevocationList(10, &structs.CARevocationList{
+		SigningKeyID: "expired",
+		Datacenter:   "dc2",
+		NextUpdate:   time.Now().Add(-time.Minute),
+	}))
+	err = manager.checkRevocationListSigners([]string{"dc1", "dc2"})
+	testutil.RequireErrorContains(t, err, `datacenter "dc2" has not published a certificate revocation list`)
+
+	require.NoError(t, delegate.store.CASetRevocationList(11, &structs.CARevocationList{
+		SigningKeyID: "current",
+		Datacenter:   "dc2",
+		NextUpdate:   time.Now().Add(time.Hour),
+	}))
+	require.NoError(t, manager.checkRevocationListSigners([]string{"dc1", "dc2"}))
+}
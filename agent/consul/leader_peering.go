@@ -0,0 +1,192 @@
+package consul
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/go-memdb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
+
+	"github.com/hashicorp/consul/agent/grpc/public/services/peering"
+	"github.com/hashicorp/consul/agent/structs"
+	"github.com/hashicorp/consul/logging"
+	"github.com/hashicorp/consul/proto/pbpeering"
+)
+
+func (s *Server) startPeeringStreams(ctx context.Context) {
+	s.leaderRoutineManager.Start(ctx, peeringStreamsRoutineName, s.runPeeringStreams)
+}
+
+func (s *Server) stopPeeringStreams() {
+	s.leaderRoutineManager.Stop(peeringStreamsRoutineName)
+}
+
+// peeringDialTarget is what a replication stream to a peer is opened with.
+// The stream is reopened when it changes, which happens when a peering is
+// established again with a new token.
+type peeringDialTarget struct {
+	PeerID              string
+	StreamSecret        string
+	PeerCAPems          []string
+	PeerServerName      string
+	PeerServerAddresses []string
+}
+
+type peeringStream struct {
+	target peeringDialTarget
+	cancel context.CancelFunc
+}
+
+// runPeeringStreams keeps a replication stream open to the peers of the
+// peerings established from this cluster. The other side of the peerings
+// opens the streams itself.
+func (s *Server) runPeeringStreams(ctx context.Context) error {
+	logger := s.loggers.Named(logging.Peering)
+
+	streams := make(map[string]*peeringStream)
+	defer func() {
+		for _, stream := range streams {
+			stream.cancel()
+		}
+	}()
+
+	for {
+		state := s.fsm.State()
+		ws := memdb.NewWatchSet()
+		ws.Add(state.AbandonCh())
+
+		_, peerings, err := state.PeeringList(ws)
+		if err != nil {
+			logger.Error("failed to list peerings", "error", err)
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-time.After(time.Second):
+				continue
+			}
+		}
+
+		dialed := make(map[string]struct{})
+		for _, p := range peerings {
+			if !p.ShouldDial() || p.IsTerminated() {
+				continue
+			}
+			dialed[p.Name] = struct{}{}
+
+			target := peeringDialTarget{
+				PeerID:              p.PeerID,
+				StreamSecret:        p.StreamSecret,
+				PeerCAPems:          p.PeerCAPems,
+				PeerServerName:      p.PeerServerName,
+				PeerServerAddresses: p.PeerServerAddresses,
+			}
+			if existing, ok := streams[p.Name]; ok {
+				if reflect.DeepEqual(existing.target, target) {
+					continue
+				}
+				existing.cancel()
+			}
+
+			streamCtx, cancel := context.WithCancel(ctx)
+			streams[p.Name] = &peeringStream{target: target, cancel: cancel}
+			go s.dialPeer(streamCtx, cancel, p.Name, logger.With("peer_name", p.Name))
+		}
+
+		// Streams of deleted peerings are not canceled, so that they get to tell
+		// the peer about it. They stop by themselves afterwards.
+		for name := range streams {
+			if _, ok := dialed[name]; !ok {
+				delete(streams, name)
+			}
+		}
+
+		if err := ws.WatchCtx(ctx); err != nil {
+			// The context is done.
+			return nil
+		}
+	}
+}
+
+// dialPeer opens a replication stream to the servers of a peer, and opens it
+// again whenever it closes until the peering is deleted or terminated.
+func (s *Server) dialPeer(ctx context.Context, cancel context.CancelFunc, peerName string, logger hclog.Logger) {
+	defer cancel()
+
+	var attempt int
+	retryLoopBackoff(ctx, func() error {
+		_, p, err := s.fsm.State().PeeringRead(nil, peerName)
+		if err != nil {
+			return fmt.Errorf("failed to look up peering: %w", err)
+		}
+		if p == nil || !p.ShouldDial() || p.IsTerminated() {
+			cancel()
+			return nil
+		}
+
+		// Rotate through the servers of the peer, only its leader accepts
+		// streams.
+		addr := p.PeerServerAddresses[attempt%len(p.PeerServerAddresses)]
+		attempt++
+
+		err = s.streamToPeer(ctx, p, addr)
+		if status.Code(err) == codes.NotFound {
+			// The peer does not know about the peering anymore.
+			logger.Info("peering was deleted by the peer")
+			return peeringBackend{s}.PeeringImport(&structs.PeeringImportRequest{
+				Op:       structs.PeeringImportTerminate,
+				PeerName: peerName,
+			})
+		}
+		return err
+	}, func(err error) {
+		s.peeringServer.Tracker.Disconnected(peerName, err)
+		logger.Warn("replication stream with peer failed", "error", err)
+	})
+}
+
+// streamToPeer opens a replication stream to a server of a peer. The stream
+// secret is sent along, so the servers of the peer are always verified with
+// TLS, against the system roots if the token had no CA certificates.
+func (s *Server) streamToPeer(ctx context.Context, p *structs.Peering, addr string) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	tlsConfig := &tls.Config{
+		ServerName: p.PeerServerName,
+	}
+	if len(p.PeerCAPems) > 0 {
+		tlsConfig.RootCAs = x509.NewCertPool()
+		for _, pem := range p.PeerCAPems {
+			if !tlsConfig.RootCAs.AppendCertsFromPEM([]byte(pem)) {
+				return fmt.Errorf("failed to parse the CA certificates of the peer")
+			}
+		}
+	}
+
+	conn, err := grpc.DialContext(ctx, addr, grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)))
+	if err != nil {
+		return fmt.Errorf("failed to dial %s: %w", addr, err)
+	}
+	defer conn.Close()
+
+	streamCtx := peering.ContextWithStreamSecret(ctx, p.StreamSecret)
+	stream, err := pbpeering.NewPeeringServiceClient(conn).StreamResources(streamCtx)
+	if err != nil {
+		return fmt.Errorf("failed to open stream to %s: %w", addr, err)
+	}
+
+	return s.peeringServer.HandleStream(peering.HandleStreamRequest{
+		PeerName:     p.Name,
+		PeerID:       p.PeerID,
+		StreamSecret: p.StreamSecret,
+		Stream:       stream,
+	})
+}
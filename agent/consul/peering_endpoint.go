@@ -0,0 +1,420 @@
+package consul
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/armon/go-metrics"
+	"github.com/armon/go-metrics/prometheus"
+	"github.com/hashicorp/go-memdb"
+	"github.com/hashicorp/go-uuid"
+
+	"github.com/hashicorp/consul/acl"
+	"github.com/hashicorp/consul/agent/consul/state"
+	"github.com/hashicorp/consul/agent/structs"
+)
+
+var PeeringSummaries = []prometheus.SummaryDefinition{
+	{
+		Name: []string{"peering", "generate_token"},
+		Help: "Measures the time spent generating a peering token.",
+	},
+	{
+		Name: []string{"peering", "establish"},
+		Help: "Measures the time spent establishing a peering from a token.",
+	},
+	{
+		Name: []string{"peering", "read"},
+		Help: "Measures the time spent reading a peering.",
+	},
+	{
+		Name: []string{"peering", "list"},
+		Help: "Measures the time spent listing peerings.",
+	},
+	{
+		Name: []string{"peering", "delete"},
+		Help: "Measures the time spent deleting a peering.",
+	},
+	{
+		Name: []string{"peering", "mesh_info"},
+		Help: "Measures the time spent reading the mesh information of peers.",
+	},
+}
+
+var errPeeringNameRequired = errors.New("Must provide a peer name")
+
+var errPeeringTLSRequired = errors.New("gRPC TLS must be enabled on servers to generate peering tokens")
+
+// Peering endpoint is used to manage the peerings of this cluster with other
+// clusters.
+type Peering struct {
+	srv *Server
+}
+
+// GenerateToken creates a peering on the accepting side, if it does not exist
+// yet, and returns the token the peer establishes it with. Each token carries
+// a new stream secret, so generating one for an existing peering revokes the
+// previous tokens and closes the stream opened with them.
+func (p *Peering) GenerateToken(args *structs.PeeringGenerateTokenRequest, reply *structs.PeeringGenerateTokenResponse) error {
+	if done, err := p.srv.ForwardRPC("Peering.GenerateToken", args, reply); done {
+		return err
+	}
+
+	defer metrics.MeasureSince([]string{"peering", "generate_token"}, time.Now())
+
+	authz, err := p.srv.ResolveToken(args.Token)
+	if err != nil {
+		return err
+	}
+	if err := authz.ToAllowAuthorizer().OperatorWriteAllowed(nil); err != nil {
+		return err
+	}
+
+	if args.PeerName == "" {
+		return errPeeringNameRequired
+	}
+
+	serverAddrs := args.ServerExternalAddresses
+	if len(serverAddrs) == 0 {
+		serverAddrs, err = p.serverGRPCAddresses()
+		if err != nil {
+			return err
+		}
+	}
+
+	// Peers are only dialed over TLS, so a token for servers serving plaintext
+	// gRPC could never establish the peering.
+	tlsConfigurator := p.srv.tlsConfigurator
+	if !p.srv.config.GRPCTLSEnabled || !tlsConfigurator.GRPCTLSConfigured() {
+		return errPeeringTLSRequired
+	}
+
+	_, existing, err := p.srv.fsm.State().PeeringRead(nil, args.PeerName)
+	if err != nil {
+		return err
+	}
+
+	secret, err := uuid.GenerateUUID()
+	if err != nil {
+		return err
+	}
+
+	var peering *structs.Peering
+	switch {
+	case existing == nil:
+		id, err := uuid.GenerateUUID()
+		if err != nil {
+			return err
+		}
+		peering = &structs.Peering{
+			ID:    id,
+			Name:  args.PeerName,
+			State: structs.PeeringStatePending,
+			Meta:  args.Meta,
+		}
+	case existing.ShouldDial():
+		return fmt.Errorf("peering %q was established from a token generated by the peer", args.PeerName)
+	case existing.IsTerminated():
+		return fmt.Errorf("peering %q was terminated by the peer and must be deleted first", args.PeerName)
+	default:
+		cp := *existing
+		peering = &cp
+	}
+	peering.StreamSecret = secret
+
+	_, err = p.srv.raftApply(structs.PeeringRequestType, &structs.PeeringRequest{
+		Op:      structs.PeeringUpsert,
+		Peering: peering,
+	})
+	if err != nil {
+		return err
+	}
+
+	token := structs.PeeringToken{
+		ServerAddresses: serverAddrs,
+		PeerID:          peering.ID,
+		StreamSecret:    secret,
+		CA:              tlsConfigurator.GRPCManualCAPems(),
+		ServerName:      tlsConfigurator.ServerSNI(p.srv.config.Datacenter, ""),
+	}
+
+	reply.PeeringToken, err = token.Encode()
+	return err
+}
+
+// serverGRPCAddresses returns the addresses of the gRPC servers of the local
+// datacenter. All servers are expected to use the same gRPC port.
+func (p *Peering) serverGRPCAddresses() ([]string, error) {
+	port := p.srv.config.GRPCPort
+	if port <= 0 {
+		return nil, fmt.Errorf("the gRPC port must be enabled on servers to generate peering tokens")
+	}
+
+	var addrs []string
+	for _, server := range p.srv.serverLookup.Servers() {
+		tcpAddr, ok := server.Addr.(*net.TCPAddr)
+		if !ok {
+			continue
+		}
+		addrs = append(addrs, net.JoinHostPort(tcpAddr.IP.String(), strconv.Itoa(port)))
+	}
+	if len(addrs) == 0 {
+		return nil, fmt.Errorf("no server address found for the peering token")
+	}
+	sort.Strings(addrs)
+	return addrs, nil
+}
+
+// Establish creates a peering on the dialing side from a token generated by
+// the peer. The leader then opens the replication stream to the peer.
+func (p *Peering) Establish(args *structs.PeeringEstablishRequest, reply *struct{}) error {
+	if done, err := p.srv.ForwardRPC("Peering.Establish", args, reply); done {
+		return err
+	}
+
+	defer metrics.MeasureSince([]string{"peering", "establish"}, time.Now())
+
+	authz, err := p.srv.ResolveToken(args.Token)
+	if err != nil {
+		return err
+	}
+	if err := authz.ToAllowAuthorizer().OperatorWriteAllowed(nil); err != nil {
+		return err
+	}
+
+	if args.PeerName == "" {
+		return errPeeringNameRequired
+	}
+	token, err := structs.DecodePeeringToken(args.PeeringToken)
+	if err != nil {
+		return err
+	}
+
+	_, existing, err := p.srv.fsm.State().PeeringRead(nil, args.PeerName)
+	if err != nil {
+		return err
+	}
+
+	var id string
+	switch {
+	case existing == nil:
+		id, err = uuid.GenerateUUID()
+		if err != nil {
+			return err
+		}
+	case !existing.ShouldDial():
+		return fmt.Errorf("a peering named %q was already created to generate a token", args.PeerName)
+	default:
+		// Establishing again with a new token updates the peering in place.
+		id = existing.ID
+	}
+
+	_, err = p.srv.raftApply(structs.PeeringRequestType, &structs.PeeringRequest{
+		Op: structs.PeeringUpsert,
+		Peering: &structs.Peering{
+			ID:                  id,
+			Name:                args.PeerName,
+			State:               structs.PeeringStateEstablishing,
+			PeerID:              token.PeerID,
+			StreamSecret:        token.StreamSecret,
+			PeerCAPems:          token.CA,
+			PeerServerName:      token.ServerName,
+			PeerServerAddresses: token.ServerAddresses,
+			Meta:                args.Meta,
+		},
+	})
+	return err
+}
+
+// Read returns a single peering by name, along with the status of its
+// replication stream.
+func (p *Peering) Read(args *structs.PeeringReadRequest, reply *structs.PeeringResponse) error {
+	if done, err := p.srv.ForwardRPC("Peering.Read", args, reply); done {
+		return err
+	}
+
+	defer metrics.MeasureSince([]string{"peering", "read"}, time.Now())
+
+	authz, err := p.srv.ResolveToken(args.Token)
+	if err != nil {
+		return err
+	}
+	if err := authz.ToAllowAuthorizer().OperatorReadAllowed(nil); err != nil {
+		return err
+	}
+
+	if args.PeerName == "" {
+		return errPeeringNameRequired
+	}
+
+	return p.srv.blockingQuery(
+		&args.QueryOptions,
+		&reply.QueryMeta,
+		func(ws memdb.WatchSet, state *state.Store) error {
+			index, peering, err := state.PeeringRead(ws, args.PeerName)
+			if err != nil {
+				return err
+			}
+
+			reply.Index, reply.Peering = index, nil
+			if peering == nil {
+				return errNotFound
+			}
+			reply.Peering = p.withStreamStatus(peering)
+			return nil
+		})
+}
+
+// List returns all the peerings, along with the status of their replication
+// streams.
+func (p *Peering) List(args *structs.DCSpecificRequest, reply *structs.IndexedPeerings) error {
+	if done, err := p.srv.ForwardRPC("Peering.List", args, reply); done {
+		return err
+	}
+
+	defer metrics.MeasureSince([]string{"peering", "list"}, time.Now())
+
+	authz, err := p.srv.ResolveToken(args.Token)
+	if err != nil {
+		return err
+	}
+	if err := authz.ToAllowAuthorizer().OperatorReadAllowed(nil); err != nil {
+		return err
+	}
+
+	return p.srv.blockingQuery(
+		&args.QueryOptions,
+		&reply.QueryMeta,
+		func(ws memdb.WatchSet, state *state.Store) error {
+			index, peerings, err := state.PeeringList(ws)
+			if err != nil {
+				return err
+			}
+
+			reply.Index = index
+			reply.Peerings = make(structs.Peerings, 0, len(peerings))
+			for _, peering := range peerings {
+				reply.Peerings = append(reply.Peerings, p.withStreamStatus(peering))
+			}
+			return nil
+		})
+}
+
+// withStreamStatus returns a redacted copy of a peering with the status of its
+// replication stream. The status is only known to the leader, so followers
+// return the persisted state alone.
+func (p *Peering) withStreamStatus(peering *structs.Peering) *structs.Peering {
+	cp := peering.Redacted()
+	cp.StreamStatus = p.srv.peeringServer.Tracker.StreamStatus(peering.Name)
+	if cp.State == structs.PeeringStateActive && cp.StreamStatus != nil &&
+		!cp.StreamStatus.Connected && cp.StreamStatus.LastError != "" {
+		cp.State = structs.PeeringStateFailing
+	}
+	return cp
+}
+
+// Delete deletes a peering along with the services imported from the peer.
+// The peer is told about it if the replication stream is open.
+func (p *Peering) Delete(args *structs.PeeringDeleteRequest, reply *struct{}) error {
+	if done, err := p.srv.ForwardRPC("Peering.Delete", args, reply); done {
+		return err
+	}
+
+	defer metrics.MeasureSince([]string{"peering", "delete"}, time.Now())
+
+	authz, err := p.srv.ResolveToken(args.Token)
+	if err != nil {
+		return err
+	}
+	if err := authz.ToAllowAuthorizer().OperatorWriteAllowed(nil); err != nil {
+		return err
+	}
+
+	if args.PeerName == "" {
+		return errPeeringNameRequired
+	}
+
+	_, err = p.srv.raftApply(structs.PeeringRequestType, &structs.PeeringRequest{
+		Op:      structs.PeeringDelete,
+		Peering: &structs.Peering{Name: args.PeerName},
+	})
+	return err
+}
+
+// MeshInfo returns the mesh information of peers, which proxies use to reach
+// the services imported from a peer and to verify the peers a service is
+// exported to.
+func (p *Peering) MeshInfo(args *structs.PeeringMeshInfoRequest, reply *structs.IndexedPeeringMeshInfos) error {
+	if done, err := p.srv.ForwardRPC("Peering.MeshInfo", args, reply); done {
+		return err
+	}
+
+	defer metrics.MeasureSince([]string{"peering", "mesh_info"}, time.Now())
+
+	if args.ServiceName == "" {
+		return fmt.Errorf("Must provide service name")
+	}
+
+	var authzContext acl.AuthorizerContext
+	authz, err := p.srv.ResolveTokenAndDefaultMeta(args.Token, &args.EnterpriseMeta, &authzContext)
+	if err != nil {
+		return err
+	}
+	if err := p.srv.validateEnterpriseRequest(&args.EnterpriseMeta, false); err != nil {
+		return err
+	}
+	if err := authz.ToAllowAuthorizer().ServiceReadAllowed(args.ServiceName, &authzContext); err != nil {
+		return err
+	}
+
+	return p.srv.blockingQuery(
+		&args.QueryOptions,
+		&reply.QueryMeta,
+		func(ws memdb.WatchSet, state *state.Store) error {
+			peerNames := []string{args.PeerName}
+			var index uint64
+			if args.PeerName == "" {
+				var err error
+				sn := structs.NewServiceName(args.ServiceName, &args.EnterpriseMeta)
+				index, peerNames, err = state.PeersForExportedService(ws, sn)
+				if err != nil {
+					return err
+				}
+			}
+
+			infos := make(map[string]*structs.PeeringMeshInfo, len(peerNames))
+			for _, name := range peerNames {
+				idx, peering, err := state.PeeringRead(ws, name)
+				if err != nil {
+					return err
+				}
+				if idx > index {
+					index = idx
+				}
+				if peering != nil && peering.PeerMesh != nil {
+					infos[name] = peering.PeerMesh
+				}
+			}
+
+			reply.Index, reply.MeshInfos = index, infos
+			return nil
+		})
+}
+
+// peeringBackend applies what peers send through raft.
+type peeringBackend struct {
+	srv *Server
+}
+
+func (b peeringBackend) IsLeader() bool {
+	return b.srv.IsLeader()
+}
+
+func (b peeringBackend) PeeringImport(req *structs.PeeringImportRequest) error {
+	_, err := b.srv.raftApply(structs.PeeringImportRequestType, req)
+	return err
+}
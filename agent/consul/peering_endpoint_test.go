@@ -0,0 +1,255 @@
+package consul
+
+import (
+	"net"
+	"os"
+	"testing"
+
+	msgpackrpc "github.com/hashicorp/consul-net-rpc/net-rpc-msgpackrpc"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+
+	"github.com/hashicorp/consul/agent/structs"
+	"github.com/hashicorp/consul/sdk/testutil/retry"
+	"github.com/hashicorp/consul/testrpc"
+)
+
+func TestPeering_GenerateToken_NoGRPCPort(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+
+	dir1, s1 := testServer(t)
+	defer os.RemoveAll(dir1)
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+	defer codec.Close()
+
+	testrpc.WaitForLeader(t, s1.RPC, "dc1")
+
+	req := structs.PeeringGenerateTokenRequest{Datacenter: "dc1", PeerName: "east"}
+	var resp structs.PeeringGenerateTokenResponse
+	err := msgpackrpc.CallWithCodec(codec, "Peering.GenerateToken", &req, &resp)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "gRPC port must be enabled")
+
+	req.PeerName = ""
+	req.ServerExternalAddresses = []string{"198.18.0.1:8502"}
+	err = msgpackrpc.CallWithCodec(codec, "Peering.GenerateToken", &req, &resp)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "Must provide a peer name")
+}
+
+func TestPeering_GenerateToken_NoGRPCTLS(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+
+	run := func(t *testing.T, cb func(c *Config)) {
+		dir1, s1 := testServerWithConfig(t, cb)
+		defer os.RemoveAll(dir1)
+		defer s1.Shutdown()
+		codec := rpcClient(t, s1)
+		defer codec.Close()
+
+		testrpc.WaitForLeader(t, s1.RPC, "dc1")
+
+		req := structs.PeeringGenerateTokenRequest{
+			Datacenter:              "dc1",
+			PeerName:                "east",
+			ServerExternalAddresses: []string{"198.18.0.1:8502"},
+		}
+		var resp structs.PeeringGenerateTokenResponse
+		err := msgpackrpc.CallWithCodec(codec, "Peering.GenerateToken", &req, &resp)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "gRPC TLS must be enabled")
+
+		// No peering is created for the failed token.
+		readReq := structs.PeeringReadRequest{Datacenter: "dc1", PeerName: "east"}
+		var readResp structs.PeeringResponse
+		require.NoError(t, msgpackrpc.CallWithCodec(codec, "Peering.Read", &readReq, &readResp))
+		require.Nil(t, readResp.Peering)
+	}
+
+	t.Run("plaintext gRPC", func(t *testing.T) {
+		run(t, func(c *Config) {
+			c.TLSConfig.Domain = "consul"
+			c.TLSConfig.GRPC.CAFile = "../../test/hostname/CertAuth.crt"
+			c.TLSConfig.GRPC.CertFile = "../../test/hostname/Alice.crt"
+			c.TLSConfig.GRPC.KeyFile = "../../test/hostname/Alice.key"
+		})
+	})
+
+	t.Run("no gRPC certificate", func(t *testing.T) {
+		run(t, func(c *Config) {
+			c.GRPCTLSEnabled = true
+		})
+	})
+}
+
+func TestPeering_Replication(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+
+	dir1, acceptor := testServerWithConfig(t, func(c *Config) {
+		c.Datacenter = "dc1"
+		c.PrimaryDatacenter = "dc1"
+
+		// Peers are only dialed over TLS.
+		c.GRPCTLSEnabled = true
+		c.TLSConfig.Domain = "consul"
+		c.TLSConfig.GRPC.CAFile = "../../test/hostname/CertAuth.crt"
+		c.TLSConfig.GRPC.CertFile = "../../test/hostname/Alice.crt"
+		c.TLSConfig.GRPC.KeyFile = "../../test/hostname/Alice.key"
+	})
+	defer os.RemoveAll(dir1)
+	defer acceptor.Shutdown()
+	acceptorCodec := rpcClient(t, acceptor)
+	defer acceptorCodec.Close()
+
+	dir2, dialer := testServerWithConfig(t, func(c *Config) {
+		c.Datacenter = "dc2"
+		c.PrimaryDatacenter = "dc2"
+	})
+	defer os.RemoveAll(dir2)
+	defer dialer.Shutdown()
+	dialerCodec := rpcClient(t, dialer)
+	defer dialerCodec.Close()
+
+	testrpc.WaitForLeader(t, acceptor.RPC, "dc1")
+	testrpc.WaitForLeader(t, dialer.RPC, "dc2")
+
+	// publicGRPCServer is created and bound to a listener by the wrapping
+	// agent code, so we need to serve the peering service over TLS ourselves
+	// here.
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	grpcServer := grpc.NewServer(grpc.Creds(credentials.NewTLS(acceptor.tlsConfigurator.IncomingGRPCConfig())))
+	acceptor.peeringServer.Register(grpcServer)
+	go grpcServer.Serve(lis)
+	t.Cleanup(grpcServer.Stop)
+
+	// Export a service to the peer before the peering exists.
+	registerTestCatalogEntries(t, acceptorCodec)
+	exports := structs.ConfigEntryRequest{
+		Datacenter: "dc1",
+		Entry: &structs.ExportedServicesConfigEntry{
+			Name: "default",
+			Services: []structs.ExportedService{
+				{Name: "redis", Consumers: []structs.ServiceConsumer{{PeerName: "dc2-peer"}}},
+			},
+		},
+	}
+	var ok bool
+	require.NoError(t, msgpackrpc.CallWithCodec(acceptorCodec, "ConfigEntry.Apply", &exports, &ok))
+
+	tokenReq := structs.PeeringGenerateTokenRequest{
+		Datacenter:              "dc1",
+		PeerName:                "dc2-peer",
+		ServerExternalAddresses: []string{lis.Addr().String()},
+	}
+	var tokenResp structs.PeeringGenerateTokenResponse
+	require.NoError(t, msgpackrpc.CallWithCodec(acceptorCodec, "Peering.GenerateToken", &tokenReq, &tokenResp))
+
+	token, err := structs.DecodePeeringToken(tokenResp.PeeringToken)
+	require.NoError(t, err)
+	require.Equal(t, []string{lis.Addr().String()}, token.ServerAddresses)
+	require.NotEmpty(t, token.CA)
+	require.Equal(t, "server.dc1.consul", token.ServerName)
+
+	// The stream secret is only handed out in tokens.
+	readReq := structs.PeeringReadRequest{Datacenter: "dc1", PeerName: "dc2-peer"}
+	var readResp structs.PeeringResponse
+	require.NoError(t, msgpackrpc.CallWithCodec(acceptorCodec, "Peering.Read", &readReq, &readResp))
+	require.Equal(t, token.PeerID, readResp.Peering.ID)
+	require.Empty(t, readResp.Peering.StreamSecret)
+
+	// Generating a token again rotates the secret, the previous token is not
+	// accepted anymore.
+	require.NoError(t, msgpackrpc.CallWithCodec(acceptorCodec, "Peering.GenerateToken", &tokenReq, &tokenResp))
+	rotated, err := structs.DecodePeeringToken(tokenResp.PeeringToken)
+	require.NoError(t, err)
+	require.Equal(t, token.PeerID, rotated.PeerID)
+	require.NotEqual(t, token.StreamSecret, rotated.StreamSecret)
+	_, peering, err := acceptor.fsm.State().PeeringRead(nil, "dc2-peer")
+	require.NoError(t, err)
+	require.Equal(t, rotated.StreamSecret, peering.StreamSecret)
+
+	// A token cannot be used to establish a peering on the generating side.
+	establishReq := structs.PeeringEstablishRequest{
+		Datacenter:   "dc1",
+		PeerName:     "dc2-peer",
+		PeeringToken: tokenResp.PeeringToken,
+	}
+	err = msgpackrpc.CallWithCodec(acceptorCodec, "Peering.Establish", &establishReq, &struct{}{})
+	require.Error(t, err)
+
+	establishReq.Datacenter = "dc2"
+	establishReq.PeerName = "dc1-peer"
+	require.NoError(t, msgpackrpc.CallWithCodec(dialerCodec, "Peering.Establish", &establishReq, &struct{}{}))
+
+	retry.Run(t, func(r *retry.R) {
+		req := structs.ServiceSpecificRequest{
+			Datacenter:  "dc2",
+			ServiceName: "redis",
+			PeerName:    "dc1-peer",
+		}
+		var resp structs.IndexedCheckServiceNodes
+		require.NoError(r, msgpackrpc.CallWithCodec(dialerCodec, "Health.ServiceNodes", &req, &resp))
+		require.NotEmpty(r, resp.Nodes)
+		require.Equal(r, "redis", resp.Nodes[0].Service.Service)
+
+		// The service is not registered in the local catalog.
+		req.PeerName = ""
+		require.NoError(r, msgpackrpc.CallWithCodec(dialerCodec, "Health.ServiceNodes", &req, &resp))
+		require.Empty(r, resp.Nodes)
+	})
+
+	retry.Run(t, func(r *retry.R) {
+		req := structs.DCSpecificRequest{Datacenter: "dc1"}
+		var resp structs.IndexedPeerings
+		require.NoError(r, msgpackrpc.CallWithCodec(acceptorCodec, "Peering.List", &req, &resp))
+		require.Len(r, resp.Peerings, 1)
+		require.Equal(r, structs.PeeringStateActive, resp.Peerings[0].State)
+		require.NotNil(r, resp.Peerings[0].StreamStatus)
+		require.True(r, resp.Peerings[0].StreamStatus.Connected)
+		require.NotNil(r, resp.Peerings[0].PeerMesh)
+		require.Equal(r, "dc2", resp.Peerings[0].PeerMesh.Datacenter)
+	})
+
+	// Proxies of the exported service learn about the roots of the peer.
+	meshReq := structs.PeeringMeshInfoRequest{Datacenter: "dc1", ServiceName: "redis"}
+	var meshResp structs.IndexedPeeringMeshInfos
+	require.NoError(t, msgpackrpc.CallWithCodec(acceptorCodec, "Peering.MeshInfo", &meshReq, &meshResp))
+	require.Contains(t, meshResp.MeshInfos, "dc2-peer")
+	require.NotEmpty(t, meshResp.MeshInfos["dc2-peer"].RootPEMs)
+
+	// Deleting the peering on one side terminates it on the other.
+	deleteReq := structs.PeeringDeleteRequest{Datacenter: "dc1", PeerName: "dc2-peer"}
+	require.NoError(t, msgpackrpc.CallWithCodec(acceptorCodec, "Peering.Delete", &deleteReq, &struct{}{}))
+
+	retry.Run(t, func(r *retry.R) {
+		req := structs.PeeringReadRequest{Datacenter: "dc2", PeerName: "dc1-peer"}
+		var resp structs.PeeringResponse
+		require.NoError(r, msgpackrpc.CallWithCodec(dialerCodec, "Peering.Read", &req, &resp))
+		require.NotNil(r, resp.Peering)
+		require.Equal(r, structs.PeeringStateTerminated, resp.Peering.State)
+	})
+
+	req := structs.ServiceSpecificRequest{
+		Datacenter:  "dc2",
+		ServiceName: "redis",
+		PeerName:    "dc1-peer",
+	}
+	var resp structs.IndexedCheckServiceNodes
+	require.NoError(t, msgpackrpc.CallWithCodec(dialerCodec, "Health.ServiceNodes", &req, &resp))
+	require.Empty(t, resp.Nodes)
+}
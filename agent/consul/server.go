@@ -46,6 +46,7 @@ import (
 	"github.com/hashicorp/consul/agent/grpc/private/services/subscribe"
 	"github.com/hashicorp/consul/agent/grpc/public/services/connectca"
 	"github.com/hashicorp/consul/agent/grpc/public/services/dataplane"
+	"github.com/hashicorp/consul/agent/grpc/public/services/peering"
 	"github.com/hashicorp/consul/agent/metadata"
 	"github.com/hashicorp/consul/agent/pool"
 	"github.com/hashicorp/consul/agent/router"
@@ -112,6 +113,8 @@ const (
 	aclTokenReapingRoutineName            = "acl token reaping"
 	aclUpgradeRoutineName                 = "legacy ACL token upgrade"
 	caRootPruningRoutineName              = "CA root pruning"
+	caRootRotationRoutineName             = "CA root rotation"
+	caRevocationListRoutineName           = "CA revocation list"
 	caRootMetricRoutineName               = "CA root expiration metric"
 	caSigningMetricRoutineName            = "CA signing expiration metric"
 	configReplicationRoutineName          = "config entry replication"
@@ -120,9 +123,11 @@ const (
 	federationStatePruningRoutineName     = "federation state pruning"
 	intentionMigrationRoutineName         = "intention config entry migration"
 	secondaryCARootWatchRoutineName       = "secondary CA roots watch"
+	secondaryCARevocationWatchRoutineName = "secondary CA revocations watch"
 	intermediateCertRenewWatchRoutineName = "intermediate cert renew watch"
 	backgroundCAInitializationRoutineName = "CA initialization"
 	virtualIPCheckRoutineName             = "virtual IP version check"
+	peeringStreamsRoutineName             = "peering streams"
 )
 
 var (
@@ -243,6 +248,10 @@ type Server struct {
 	// enable RPC forwarding.
 	publicConnectCAServer *connectca.Server
 
+	// peeringServer serves the replication streams opened by the peers of this
+	// cluster on the public gRPC port, and handles the ones the leader opens.
+	peeringServer *peering.Server
+
 	// publicGRPCServer is the gRPC server exposed on the dedicated gRPC port, as
 	// opposed to the multiplexed "server" port which is served by grpcHandler.
 	publicGRPCServer *grpc.Server
@@ -681,6 +690,15 @@ func NewServer(config *Config, flat Deps, publicGRPCServer *grpc.Server) (*Serve
 		ACLResolver: plainACLResolver{s.ACLResolver},
 	}).Register(s.publicGRPCServer)
 
+	s.peeringServer = peering.NewServer(peering.Config{
+		Backend:    peeringBackend{s},
+		GetStore:   func() peering.StateStore { return s.FSM().State() },
+		Tracker:    peering.NewTracker(),
+		Logger:     logger.Named("grpc-api.peering"),
+		Datacenter: s.config.Datacenter,
+	})
+	s.peeringServer.Register(s.publicGRPCServer)
+
 	// Initialize private gRPC server.
 	//
 	// Note: some "public" gRPC services are also exposed on the private gRPC server
@@ -2,6 +2,7 @@ package consul
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/hashicorp/go-memdb"
 
@@ -72,5 +73,35 @@ func (s *Server) getCARoots(ws memdb.WatchSet, state *state.Store) (*structs.Ind
 		}
 	}
 
+	revocationsIndex, revocations, lists, err := state.CARevocations(ws)
+	if err != nil {
+		return nil, err
+	}
+	if revocationsIndex > indexedRoots.Index {
+		indexedRoots.Index = revocationsIndex
+	}
+
+	// Revocations are pruned by the leader once they expire, so the list here
+	// only needs to be filtered for the time in between.
+	now := time.Now()
+	var anySerials bool
+	for _, r := range revocations {
+		if !now.Before(r.ExpiresAt) {
+			continue
+		}
+		if r.SpiffeID != "" {
+			indexedRoots.RevokedSpiffeIDs = append(indexedRoots.RevokedSpiffeIDs, r.SpiffeID)
+		} else {
+			anySerials = true
+		}
+	}
+	if anySerials {
+		for _, l := range lists {
+			if now.Before(l.NextUpdate) {
+				indexedRoots.RevocationLists = append(indexedRoots.RevocationLists, l.PEM)
+			}
+		}
+	}
+
 	return indexedRoots, nil
 }
@@ -527,6 +527,15 @@ func aclTokenSetTxn(tx WriteTxn, idx uint64, token *structs.ACLToken, opts ACLTo
 		}
 	}
 
+	if token.ParentAccessorID != "" && original == nil && !opts.FromReplication {
+		_, parent, err := aclTokenGetFromIndex(tx, token.ParentAccessorID, indexAccessor, nil)
+		if err != nil {
+			return fmt.Errorf("failed parent token lookup: %v", err)
+		} else if parent == nil {
+			return fmt.Errorf("No such parent token with AccessorID: %s", token.ParentAccessorID)
+		}
+	}
+
 	for _, svcid := range token.ServiceIdentities {
 		if svcid.ServiceName == "" {
 			return fmt.Errorf("Encountered a Token with an empty service identity name in the state store")
@@ -558,6 +567,10 @@ func aclTokenSetTxn(tx WriteTxn, idx uint64, token *structs.ACLToken, opts ACLTo
 			return fmt.Errorf("The ACL Token SecretID field is immutable")
 		}
 
+		if token.ParentAccessorID != original.ParentAccessorID {
+			return fmt.Errorf("The ACL Token ParentAccessorID field is immutable")
+		}
+
 		token.CreateIndex = original.CreateIndex
 		token.ModifyIndex = idx
 	} else {
@@ -791,6 +804,40 @@ func (s *Store) ACLTokenListExpired(local bool, asOf time.Time, max int) (struct
 	return tokens, iter.WatchCh(), nil
 }
 
+// ACLTokenListOrphaned lists derived tokens whose parent token no longer
+// exists. The returned set will be no larger than the max value provided.
+func (s *Store) ACLTokenListOrphaned(local bool, max int) (structs.ACLTokens, error) {
+	tx := s.db.Txn(false)
+	defer tx.Abort()
+
+	iter, err := tx.Get(tableACLTokens, indexParent)
+	if err != nil {
+		return nil, fmt.Errorf("failed acl token listing: %v", err)
+	}
+
+	var tokens structs.ACLTokens
+	for raw := iter.Next(); raw != nil; raw = iter.Next() {
+		token := raw.(*structs.ACLToken)
+		if token.Local != local {
+			continue
+		}
+
+		_, parent, err := aclTokenGetFromIndex(tx, token.ParentAccessorID, indexAccessor, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed parent token lookup: %v", err)
+		} else if parent != nil {
+			continue
+		}
+
+		tokens = append(tokens, token)
+		if len(tokens) >= max {
+			break
+		}
+	}
+
+	return tokens, nil
+}
+
 func (s *Store) expiresIndexName(local bool) string {
 	if local {
 		return indexExpiresLocal
@@ -43,9 +43,11 @@ func testIndexerTableACLTokens() map[string]indexerTestCase {
 	policyID2 := "123e4567-e89a-12d7-a456-426614174002"
 	roleID1 := "123e4567-e89a-12d7-a457-426614174001"
 	roleID2 := "123e4567-e89a-12d7-a457-426614174002"
+	parentID := "123e4567-e89a-12d7-a458-426614174001"
 	obj := &structs.ACLToken{
-		AccessorID: "123e4567-e89a-12d7-a456-426614174abc",
-		SecretID:   "123e4567-e89a-12d7-a456-426614174abd",
+		AccessorID:       "123e4567-e89a-12d7-a456-426614174abc",
+		SecretID:         "123e4567-e89a-12d7-a456-426614174abd",
+		ParentAccessorID: parentID,
 
 		Policies: []structs.ACLTokenPolicyLink{
 			{ID: policyID1}, {ID: policyID2},
@@ -59,6 +61,7 @@ func testIndexerTableACLTokens() map[string]indexerTestCase {
 	encodedPID2 := []byte{0x12, 0x3e, 0x45, 0x67, 0xe8, 0x9a, 0x12, 0xd7, 0xa4, 0x56, 0x42, 0x66, 0x14, 0x17, 0x40, 0x02}
 	encodedRID1 := []byte{0x12, 0x3e, 0x45, 0x67, 0xe8, 0x9a, 0x12, 0xd7, 0xa4, 0x57, 0x42, 0x66, 0x14, 0x17, 0x40, 0x1}
 	encodedRID2 := []byte{0x12, 0x3e, 0x45, 0x67, 0xe8, 0x9a, 0x12, 0xd7, 0xa4, 0x57, 0x42, 0x66, 0x14, 0x17, 0x40, 0x2}
+	encodedParentID := []byte{0x12, 0x3e, 0x45, 0x67, 0xe8, 0x9a, 0x12, 0xd7, 0xa4, 0x58, 0x42, 0x66, 0x14, 0x17, 0x40, 0x1}
 	return map[string]indexerTestCase{
 		indexPolicies: {
 			read: indexValue{
@@ -96,6 +99,18 @@ func testIndexerTableACLTokens() map[string]indexerTestCase {
 				expected: []byte("test-auth-method\x00"),
 			},
 		},
+		indexParent: {
+			read: indexValue{
+				source: Query{
+					Value: parentID,
+				},
+				expected: encodedParentID,
+			},
+			write: indexValue{
+				source:   obj,
+				expected: encodedParentID,
+			},
+		},
 	}
 }
 
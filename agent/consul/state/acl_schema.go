@@ -21,6 +21,7 @@ const (
 	indexPolicies      = "policies"
 	indexRoles         = "roles"
 	indexAuthMethod    = "authmethod"
+	indexParent        = "parent"
 	indexLocality      = "locality"
 	indexName          = "name"
 	indexExpiresGlobal = "expires-global"
@@ -78,6 +79,15 @@ func tokensTableSchema() *memdb.TableSchema {
 					writeIndex: writeIndex(indexAuthMethodFromACLToken),
 				},
 			},
+			indexParent: {
+				Name:         indexParent,
+				AllowMissing: true,
+				Unique:       false,
+				Indexer: indexerSingle{
+					readIndex:  readIndex(indexFromUUIDQuery),
+					writeIndex: writeIndex(indexParentFromACLToken),
+				},
+			},
 			indexLocality: {
 				Name:         indexLocality,
 				AllowMissing: false,
@@ -364,6 +374,19 @@ func indexSecretIDFromACLToken(raw interface{}) ([]byte, error) {
 	return b.Bytes(), nil
 }
 
+func indexParentFromACLToken(raw interface{}) ([]byte, error) {
+	p, ok := raw.(*structs.ACLToken)
+	if !ok {
+		return nil, fmt.Errorf("unexpected type %T for structs.ACLToken index", raw)
+	}
+
+	if p.ParentAccessorID == "" {
+		return nil, errMissingValueForIndex
+	}
+
+	return uuidStringToBytes(p.ParentAccessorID)
+}
+
 func indexFromStringCaseSensitive(raw interface{}) ([]byte, error) {
 	q, ok := raw.(string)
 	if !ok {
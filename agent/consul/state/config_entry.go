@@ -398,6 +398,7 @@ func validateProposedConfigEntryInGraph(
 	case structs.ServiceIntentions:
 	case structs.MeshConfig:
 	case structs.ExportedServices:
+	case structs.JWTProvider:
 	default:
 		return fmt.Errorf("unhandled kind %q during validation of %q", kindName.Kind, kindName.Name)
 	}
@@ -432,7 +433,7 @@ func (s *Store) discoveryChainTargetsTxn(tx ReadTxn, ws memdb.WatchSet, dc, serv
 		EvaluateInPartition:  source.PartitionOrDefault(),
 		EvaluateInDatacenter: dc,
 	}
-	idx, chain, _, err := s.serviceDiscoveryChainTxn(tx, ws, source.Name, entMeta, req)
+	idx, chain, _, err := s.serviceDiscoveryChainTxn(tx, ws, source.Name, entMeta, req, nil)
 	if err != nil {
 		return 0, nil, fmt.Errorf("failed to fetch discovery chain for %q: %v", source.String(), err)
 	}
@@ -489,7 +490,7 @@ func (s *Store) discoveryChainSourcesTxn(tx ReadTxn, ws memdb.WatchSet, dc strin
 			EvaluateInPartition:  sn.PartitionOrDefault(),
 			EvaluateInDatacenter: dc,
 		}
-		idx, chain, _, err := s.serviceDiscoveryChainTxn(tx, ws, sn.Name, &sn.EnterpriseMeta, req)
+		idx, chain, _, err := s.serviceDiscoveryChainTxn(tx, ws, sn.Name, &sn.EnterpriseMeta, req, nil)
 		if err != nil {
 			return 0, nil, fmt.Errorf("failed to fetch discovery chain for %q: %v", sn.String(), err)
 		}
@@ -777,7 +778,24 @@ func (s *Store) ServiceDiscoveryChain(
 	tx := s.db.ReadTxn()
 	defer tx.Abort()
 
-	return s.serviceDiscoveryChainTxn(tx, ws, serviceName, entMeta, req)
+	return s.serviceDiscoveryChainTxn(tx, ws, serviceName, entMeta, req, nil)
+}
+
+// ServiceDiscoveryChainWithOverrides compiles the discovery chain for the
+// service as if the entries in overrides had been written. Nil values are
+// tombstones to simulate deleting an entry. See readDiscoveryChainConfigEntries
+// for how the overrides are applied.
+func (s *Store) ServiceDiscoveryChainWithOverrides(
+	ws memdb.WatchSet,
+	serviceName string,
+	entMeta *acl.EnterpriseMeta,
+	req discoverychain.CompileRequest,
+	overrides map[configentry.KindName]structs.ConfigEntry,
+) (uint64, *structs.CompiledDiscoveryChain, *configentry.DiscoveryChainSet, error) {
+	tx := s.db.ReadTxn()
+	defer tx.Abort()
+
+	return s.serviceDiscoveryChainTxn(tx, ws, serviceName, entMeta, req, overrides)
 }
 
 func (s *Store) serviceDiscoveryChainTxn(
@@ -786,9 +804,10 @@ func (s *Store) serviceDiscoveryChainTxn(
 	serviceName string,
 	entMeta *acl.EnterpriseMeta,
 	req discoverychain.CompileRequest,
+	overrides map[configentry.KindName]structs.ConfigEntry,
 ) (uint64, *structs.CompiledDiscoveryChain, *configentry.DiscoveryChainSet, error) {
 
-	index, entries, err := readDiscoveryChainConfigEntriesTxn(tx, ws, serviceName, nil, entMeta)
+	index, entries, err := readDiscoveryChainConfigEntriesTxn(tx, ws, serviceName, overrides, entMeta)
 	if err != nil {
 		return 0, nil, nil, err
 	}
@@ -0,0 +1,296 @@
+package state
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/go-memdb"
+
+	"github.com/hashicorp/consul/agent/structs"
+)
+
+const (
+	tableConnectCARevocations     = "connect-ca-revocations"
+	tableConnectCARevocationLists = "connect-ca-revocation-lists"
+)
+
+// caRevocationsTableSchema returns a new table schema used for storing
+// revoked Connect leaf certificates and identities.
+func caRevocationsTableSchema() *memdb.TableSchema {
+	return &memdb.TableSchema{
+		Name: tableConnectCARevocations,
+		Indexes: map[string]*memdb.IndexSchema{
+			"id": {
+				Name:         "id",
+				AllowMissing: false,
+				Unique:       true,
+				Indexer: &memdb.StringFieldIndex{
+					Field: "ID",
+				},
+			},
+		},
+	}
+}
+
+// caRevocationListsTableSchema returns a new table schema used for storing
+// the certificate revocation lists signed by the CA of each datacenter.
+func caRevocationListsTableSchema() *memdb.TableSchema {
+	return &memdb.TableSchema{
+		Name: tableConnectCARevocationLists,
+		Indexes: map[string]*memdb.IndexSchema{
+			"id": {
+				Name:         "id",
+				AllowMissing: false,
+				Unique:       true,
+				Indexer: &memdb.StringFieldIndex{
+					Field: "SigningKeyID",
+				},
+			},
+		},
+	}
+}
+
+// CARevocations is used to pull all the CA revocations for the snapshot.
+func (s *Snapshot) CARevocations() ([]*structs.CARevocation, error) {
+	iter, err := s.tx.Get(tableConnectCARevocations, "id")
+	if err != nil {
+		return nil, err
+	}
+
+	var ret []*structs.CARevocation
+	for wrapped := iter.Next(); wrapped != nil; wrapped = iter.Next() {
+		ret = append(ret, wrapped.(*structs.CARevocation))
+	}
+	return ret, nil
+}
+
+// CARevocation is used when restoring from a snapshot.
+func (s *Restore) CARevocation(r *structs.CARevocation) error {
+	if err := s.tx.Insert(tableConnectCARevocations, r); err != nil {
+		return fmt.Errorf("failed restoring CA revocation: %s", err)
+	}
+	if err := indexUpdateMaxTxn(s.tx, r.ModifyIndex, tableConnectCARevocations); err != nil {
+		return fmt.Errorf("failed updating index: %s", err)
+	}
+	return nil
+}
+
+// CARevocationLists is used to pull all the CA revocation lists for the
+// snapshot.
+func (s *Snapshot) CARevocationLists() ([]*structs.CARevocationList, error) {
+	iter, err := s.tx.Get(tableConnectCARevocationLists, "id")
+	if err != nil {
+		return nil, err
+	}
+
+	var ret []*structs.CARevocationList
+	for wrapped := iter.Next(); wrapped != nil; wrapped = iter.Next() {
+		ret = append(ret, wrapped.(*structs.CARevocationList))
+	}
+	return ret, nil
+}
+
+// CARevocationList is used when restoring from a snapshot.
+func (s *Restore) CARevocationList(l *structs.CARevocationList) error {
+	if err := s.tx.Insert(tableConnectCARevocationLists, l); err != nil {
+		return fmt.Errorf("failed restoring CA revocation list: %s", err)
+	}
+	if err := indexUpdateMaxTxn(s.tx, l.ModifyIndex, tableConnectCARevocationLists); err != nil {
+		return fmt.Errorf("failed updating index: %s", err)
+	}
+	return nil
+}
+
+// CARevocations returns all the revocations and revocation lists. The index
+// returned is the max index of both tables.
+func (s *Store) CARevocations(ws memdb.WatchSet) (uint64, []*structs.CARevocation, []*structs.CARevocationList, error) {
+	tx := s.db.Txn(false)
+	defer tx.Abort()
+
+	return caRevocationsTxn(tx, ws)
+}
+
+func caRevocationsTxn(tx ReadTxn, ws memdb.WatchSet) (uint64, []*structs.CARevocation, []*structs.CARevocationList, error) {
+	idx := maxIndexTxn(tx, tableConnectCARevocations, tableConnectCARevocationLists)
+
+	iter, err := tx.Get(tableConnectCARevocations, "id")
+	if err != nil {
+		return 0, nil, nil, fmt.Errorf("failed CA revocation lookup: %s", err)
+	}
+	ws.Add(iter.WatchCh())
+
+	var revocations []*structs.CARevocation
+	for v := iter.Next(); v != nil; v = iter.Next() {
+		revocations = append(revocations, v.(*structs.CARevocation))
+	}
+
+	iter, err = tx.Get(tableConnectCARevocationLists, "id")
+	if err != nil {
+		return 0, nil, nil, fmt.Errorf("failed CA revocation list lookup: %s", err)
+	}
+	ws.Add(iter.WatchCh())
+
+	var lists []*structs.CARevocationList
+	for v := iter.Next(); v != nil; v = iter.Next() {
+		lists = append(lists, v.(*structs.CARevocationList))
+	}
+
+	return idx, revocations, lists, nil
+}
+
+// CARevoke adds a revocation.
+func (s *Store) CARevoke(idx uint64, r *structs.CARevocation) error {
+	tx := s.db.WriteTxn(idx)
+	defer tx.Abort()
+
+	if r.ID == "" {
+		return fmt.Errorf("missing revocation ID")
+	}
+
+	existing, err := tx.First(tableConnectCARevocations, "id", r.ID)
+	if err != nil {
+		return fmt.Errorf("failed CA revocation lookup: %s", err)
+	}
+	if existing != nil {
+		return fmt.Errorf("revocation %q already exists", r.ID)
+	}
+
+	r.CreateIndex = idx
+	r.ModifyIndex = idx
+	if err := tx.Insert(tableConnectCARevocations, r); err != nil {
+		return fmt.Errorf("failed inserting CA revocation: %s", err)
+	}
+	if err := tx.Insert(tableIndex, &IndexEntry{tableConnectCARevocations, idx}); err != nil {
+		return fmt.Errorf("failed updating index: %s", err)
+	}
+
+	return tx.Commit()
+}
+
+// CADeleteRevocation removes the revocation with the given ID.
+func (s *Store) CADeleteRevocation(idx uint64, id string) error {
+	tx := s.db.WriteTxn(idx)
+	defer tx.Abort()
+
+	existing, err := tx.First(tableConnectCARevocations, "id", id)
+	if err != nil {
+		return fmt.Errorf("failed CA revocation lookup: %s", err)
+	}
+	if existing == nil {
+		return nil
+	}
+
+	if err := tx.Delete(tableConnectCARevocations, existing); err != nil {
+		return fmt.Errorf("failed deleting CA revocation: %s", err)
+	}
+	if err := tx.Insert(tableIndex, &IndexEntry{tableConnectCARevocations, idx}); err != nil {
+		return fmt.Errorf("failed updating index: %s", err)
+	}
+
+	return tx.Commit()
+}
+
+// CASetRevocations replaces all revocations and revocation lists. If cidx is
+// non-zero the operation is a check-and-set against the index returned by
+// CARevocations.
+//
+// The boolean result returns whether the transaction succeeded or not.
+func (s *Store) CASetRevocations(idx, cidx uint64, rs []*structs.CARevocation, ls []*structs.CARevocationList) (bool, error) {
+	tx := s.db.WriteTxn(idx)
+	defer tx.Abort()
+
+	if cidx != 0 {
+		if midx := maxIndexTxn(tx, tableConnectCARevocations, tableConnectCARevocationLists); midx != cidx {
+			return false, nil
+		}
+	}
+
+	for _, r := range rs {
+		if r.ID == "" {
+			return false, fmt.Errorf("missing revocation ID")
+		}
+		existing, err := tx.First(tableConnectCARevocations, "id", r.ID)
+		if err != nil {
+			return false, fmt.Errorf("failed CA revocation lookup: %s", err)
+		}
+		if existing != nil {
+			r.CreateIndex = existing.(*structs.CARevocation).CreateIndex
+			r.ModifyIndex = existing.(*structs.CARevocation).ModifyIndex
+		} else {
+			r.CreateIndex = idx
+			r.ModifyIndex = idx
+		}
+	}
+	for _, l := range ls {
+		if l.SigningKeyID == "" {
+			return false, fmt.Errorf("missing revocation list signing key ID")
+		}
+		existing, err := tx.First(tableConnectCARevocationLists, "id", l.SigningKeyID)
+		if err != nil {
+			return false, fmt.Errorf("failed CA revocation list lookup: %s", err)
+		}
+		if existing != nil {
+			l.CreateIndex = existing.(*structs.CARevocationList).CreateIndex
+		} else {
+			l.CreateIndex = idx
+		}
+		l.ModifyIndex = idx
+	}
+
+	if _, err := tx.DeleteAll(tableConnectCARevocations, "id"); err != nil {
+		return false, err
+	}
+	if _, err := tx.DeleteAll(tableConnectCARevocationLists, "id"); err != nil {
+		return false, err
+	}
+	for _, r := range rs {
+		if err := tx.Insert(tableConnectCARevocations, r); err != nil {
+			return false, err
+		}
+	}
+	for _, l := range ls {
+		if err := tx.Insert(tableConnectCARevocationLists, l); err != nil {
+			return false, err
+		}
+	}
+
+	if err := tx.Insert(tableIndex, &IndexEntry{tableConnectCARevocations, idx}); err != nil {
+		return false, fmt.Errorf("failed updating index: %s", err)
+	}
+	if err := tx.Insert(tableIndex, &IndexEntry{tableConnectCARevocationLists, idx}); err != nil {
+		return false, fmt.Errorf("failed updating index: %s", err)
+	}
+
+	err := tx.Commit()
+	return err == nil, err
+}
+
+// CASetRevocationList stores the revocation list signed by the key with the
+// list's SigningKeyID, replacing any previous list signed by the same key.
+func (s *Store) CASetRevocationList(idx uint64, l *structs.CARevocationList) error {
+	tx := s.db.WriteTxn(idx)
+	defer tx.Abort()
+
+	if l.SigningKeyID == "" {
+		return fmt.Errorf("missing revocation list signing key ID")
+	}
+
+	existing, err := tx.First(tableConnectCARevocationLists, "id", l.SigningKeyID)
+	if err != nil {
+		return fmt.Errorf("failed CA revocation list lookup: %s", err)
+	}
+	if existing != nil {
+		l.CreateIndex = existing.(*structs.CARevocationList).CreateIndex
+	} else {
+		l.CreateIndex = idx
+	}
+	l.ModifyIndex = idx
+
+	if err := tx.Insert(tableConnectCARevocationLists, l); err != nil {
+		return fmt.Errorf("failed inserting CA revocation list: %s", err)
+	}
+	if err := tx.Insert(tableIndex, &IndexEntry{tableConnectCARevocationLists, idx}); err != nil {
+		return fmt.Errorf("failed updating index: %s", err)
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,114 @@
+package state
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-memdb"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hashicorp/consul/agent/structs"
+)
+
+func TestStore_CARevocations(t *testing.T) {
+	s := testStateStore(t)
+
+	// Empty state.
+	ws := memdb.NewWatchSet()
+	idx, revocations, lists, err := s.CARevocations(ws)
+	require.NoError(t, err)
+	require.Equal(t, uint64(0), idx)
+	require.Empty(t, revocations)
+	require.Empty(t, lists)
+
+	revocation := &structs.CARevocation{
+		ID:           "a6b4c0c4-9b4c-4c1d-8e8b-0c3c5e5b3f01",
+		SerialNumber: "0a:1b",
+		Datacenter:   "dc1",
+		ExpiresAt:    time.Now().Add(time.Hour),
+	}
+	require.NoError(t, s.CARevoke(1, revocation))
+	require.True(t, watchFired(ws))
+
+	// Revocation IDs must be unique.
+	require.Error(t, s.CARevoke(2, &structs.CARevocation{ID: revocation.ID}))
+
+	ws = memdb.NewWatchSet()
+	list := &structs.CARevocationList{
+		SigningKeyID:  "aa:bb",
+		Datacenter:    "dc1",
+		PEM:           "crl",
+		SerialNumbers: []string{"0a:1b"},
+	}
+	require.NoError(t, s.CASetRevocationList(2, list))
+
+	idx, revocations, lists, err = s.CARevocations(ws)
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), idx)
+	require.Equal(t, []*structs.CARevocation{revocation}, revocations)
+	require.Equal(t, []*structs.CARevocationList{list}, lists)
+	require.Equal(t, uint64(1), revocations[0].CreateIndex)
+
+	// A new list from the same key replaces the old one.
+	updated := &structs.CARevocationList{
+		SigningKeyID: "aa:bb",
+		Datacenter:   "dc1",
+		PEM:          "crl2",
+	}
+	require.NoError(t, s.CASetRevocationList(3, updated))
+	require.True(t, watchFired(ws))
+
+	_, _, lists, err = s.CARevocations(nil)
+	require.NoError(t, err)
+	require.Len(t, lists, 1)
+	require.Equal(t, "crl2", lists[0].PEM)
+	require.Equal(t, uint64(2), lists[0].CreateIndex)
+	require.Equal(t, uint64(3), lists[0].ModifyIndex)
+
+	// Deleting bumps the index so blocking queries wake up.
+	require.NoError(t, s.CADeleteRevocation(4, revocation.ID))
+	idx, revocations, _, err = s.CARevocations(nil)
+	require.NoError(t, err)
+	require.Equal(t, uint64(4), idx)
+	require.Empty(t, revocations)
+}
+
+func TestStore_CASetRevocations(t *testing.T) {
+	s := testStateStore(t)
+
+	require.NoError(t, s.CARevoke(1, &structs.CARevocation{ID: "one", SpiffeID: "spiffe://foo"}))
+	require.NoError(t, s.CASetRevocationList(2, &structs.CARevocationList{SigningKeyID: "aa:bb"}))
+
+	replacement := []*structs.CARevocation{
+		{ID: "one", SpiffeID: "spiffe://foo"},
+		{ID: "two", SerialNumber: "01", Datacenter: "dc2"},
+	}
+
+	// A stale check-and-set index is a no-op.
+	ok, err := s.CASetRevocations(3, 1, replacement, nil)
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	ok, err = s.CASetRevocations(3, 2, replacement, nil)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	idx, revocations, lists, err := s.CARevocations(nil)
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), idx)
+	require.Len(t, revocations, 2)
+	require.Empty(t, lists)
+
+	// Existing revocations keep their indexes.
+	require.Equal(t, uint64(1), revocations[0].CreateIndex)
+	require.Equal(t, uint64(3), revocations[1].CreateIndex)
+
+	// An index of 0 replaces unconditionally.
+	ok, err = s.CASetRevocations(4, 0, nil, nil)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	_, revocations, _, err = s.CARevocations(nil)
+	require.NoError(t, err)
+	require.Empty(t, revocations)
+}
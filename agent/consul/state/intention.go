@@ -1018,6 +1018,78 @@ func (s *Store) intentionTopologyTxn(tx ReadTxn, ws memdb.WatchSet,
 		services = append(services, ingress...)
 	}
 
+	candidates := make([]structs.ServiceName, 0, len(services))
+	for _, svc := range services {
+		candidates = append(candidates, svc.Service)
+	}
+	result, err := s.intentionTopologyDecisions(target, candidates, intentions, downstreams, defaultDecision)
+	return maxIdx, result, err
+}
+
+// IntentionTopologyDestinations returns the destinations a service is allowed to reach. Destinations are services
+// declared by a service-defaults config entry rather than registered in the catalog, so they are never returned by
+// IntentionTopology.
+func (s *Store) IntentionTopologyDestinations(ws memdb.WatchSet,
+	target structs.ServiceName, defaultDecision acl.EnforcementDecision) (uint64, structs.ServiceList, error) {
+	tx := s.db.ReadTxn()
+	defer tx.Abort()
+
+	idx, services, err := s.intentionTopologyDestinationsTxn(tx, ws, target, defaultDecision)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to fetch destinations for %s: %v", target.String(), err)
+	}
+
+	resp := make(structs.ServiceList, 0)
+	for _, svc := range services {
+		resp = append(resp, svc.Name)
+	}
+	return idx, resp, nil
+}
+
+func (s *Store) intentionTopologyDestinationsTxn(tx ReadTxn, ws memdb.WatchSet,
+	target structs.ServiceName, defaultDecision acl.EnforcementDecision) (uint64, []ServiceWithDecision, error) {
+
+	var maxIdx uint64
+
+	entry := structs.IntentionMatchEntry{
+		Namespace: target.NamespaceOrDefault(),
+		Partition: target.PartitionOrDefault(),
+		Name:      target.Name,
+	}
+	index, intentions, err := compatIntentionMatchOneTxn(tx, ws, entry, structs.IntentionMatchSource)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to query intentions for %s", target.String())
+	}
+	if index > maxIdx {
+		maxIdx = index
+	}
+
+	index, entries, err := configEntriesByKindTxn(tx, ws, structs.ServiceDefaults, structs.WildcardEnterpriseMetaInPartition(target.PartitionOrDefault()))
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to list service-defaults config entries: %v", err)
+	}
+	if index > maxIdx {
+		maxIdx = index
+	}
+
+	var candidates []structs.ServiceName
+	for _, entry := range entries {
+		conf, ok := entry.(*structs.ServiceConfigEntry)
+		if !ok || conf.Destination == nil {
+			continue
+		}
+		candidates = append(candidates, structs.NewServiceName(conf.Name, &conf.EnterpriseMeta))
+	}
+
+	result, err := s.intentionTopologyDecisions(target, candidates, intentions, false, defaultDecision)
+	return maxIdx, result, err
+}
+
+// intentionTopologyDecisions returns the candidates allowed by intentions to be upstreams, or downstreams, of the
+// target service.
+func (s *Store) intentionTopologyDecisions(target structs.ServiceName, candidates []structs.ServiceName,
+	intentions structs.Intentions, downstreams bool, defaultDecision acl.EnforcementDecision) ([]ServiceWithDecision, error) {
+
 	// When checking authorization to upstreams, the match type for the decision is `destination` because we are deciding
 	// if upstream candidates are covered by intentions that have the target service as a source.
 	// The reverse is true for downstreams.
@@ -1025,9 +1097,8 @@ func (s *Store) intentionTopologyTxn(tx ReadTxn, ws memdb.WatchSet,
 	if downstreams {
 		decisionMatchType = structs.IntentionMatchSource
 	}
-	result := make([]ServiceWithDecision, 0, len(services))
-	for _, svc := range services {
-		candidate := svc.Service
+	result := make([]ServiceWithDecision, 0, len(candidates))
+	for _, candidate := range candidates {
 		if candidate.Name == structs.ConsulServiceName {
 			continue
 		}
@@ -1047,7 +1118,7 @@ func (s *Store) intentionTopologyTxn(tx ReadTxn, ws memdb.WatchSet,
 			if downstreams {
 				src, dst = candidate, target
 			}
-			return 0, nil, fmt.Errorf("failed to get intention decision from (%s) to (%s): %v",
+			return nil, fmt.Errorf("failed to get intention decision from (%s) to (%s): %v",
 				src.String(), dst.String(), err)
 		}
 		if !decision.Allowed || target.Matches(candidate) {
@@ -1059,5 +1130,5 @@ func (s *Store) intentionTopologyTxn(tx ReadTxn, ws memdb.WatchSet,
 			Decision: decision,
 		})
 	}
-	return maxIdx, result, err
+	return result, nil
 }
@@ -2282,3 +2282,57 @@ func TestStore_IntentionTopology_Watches(t *testing.T) {
 	}
 	require.Equal(t, expect, got)
 }
+
+func TestStore_IntentionTopologyDestinations(t *testing.T) {
+	s := testConfigStateStore(t)
+
+	target := structs.NewServiceName("web", structs.DefaultEnterpriseMetaInDefaultPartition())
+
+	ws := memdb.NewWatchSet()
+	_, got, err := s.IntentionTopologyDestinations(ws, target, acl.Deny)
+	require.NoError(t, err)
+	require.Empty(t, got)
+
+	// A service-defaults entry without a destination is not a candidate.
+	require.NoError(t, s.EnsureConfigEntry(1, &structs.ServiceConfigEntry{
+		Kind:     structs.ServiceDefaults,
+		Name:     "api",
+		Protocol: "http",
+	}))
+	require.NoError(t, s.EnsureConfigEntry(2, &structs.ServiceConfigEntry{
+		Kind: structs.ServiceDefaults,
+		Name: "external",
+		Destination: &structs.DestinationConfig{
+			Addresses: []string{"api.example.com"},
+			Port:      443,
+		},
+	}))
+	require.True(t, watchFired(ws))
+
+	// The destination is not allowed by default.
+	ws = memdb.NewWatchSet()
+	_, got, err = s.IntentionTopologyDestinations(ws, target, acl.Deny)
+	require.NoError(t, err)
+	require.Empty(t, got)
+
+	require.NoError(t, s.EnsureConfigEntry(3, &structs.ServiceIntentionsConfigEntry{
+		Kind: structs.ServiceIntentions,
+		Name: "*",
+		Sources: []*structs.SourceIntention{
+			{
+				Name:   "web",
+				Action: structs.IntentionActionAllow,
+			},
+		},
+	}))
+	require.True(t, watchFired(ws))
+
+	index, got, err := s.IntentionTopologyDestinations(nil, target, acl.Deny)
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), index)
+
+	expect := structs.ServiceList{
+		structs.NewServiceName("external", structs.DefaultEnterpriseMetaInDefaultPartition()),
+	}
+	require.Equal(t, expect, got)
+}
@@ -0,0 +1,546 @@
+package state
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/go-memdb"
+
+	"github.com/hashicorp/consul/acl"
+	"github.com/hashicorp/consul/agent/structs"
+)
+
+const (
+	tablePeering                 = "peering"
+	tablePeeringImportedServices = "peering-imported-services"
+
+	indexPeer = "peer"
+)
+
+// peeringTableSchema returns a new table schema used for storing the
+// peerings with other clusters.
+func peeringTableSchema() *memdb.TableSchema {
+	return &memdb.TableSchema{
+		Name: tablePeering,
+		Indexes: map[string]*memdb.IndexSchema{
+			indexID: {
+				Name:         indexID,
+				AllowMissing: false,
+				Unique:       true,
+				Indexer: &memdb.StringFieldIndex{
+					Field: "ID",
+				},
+			},
+			indexName: {
+				Name:         indexName,
+				AllowMissing: false,
+				Unique:       true,
+				Indexer: &memdb.StringFieldIndex{
+					Field:     "Name",
+					Lowercase: true,
+				},
+			},
+		},
+	}
+}
+
+// peeringImportedServicesTableSchema returns a new table schema used for
+// storing the services imported from peers.
+func peeringImportedServicesTableSchema() *memdb.TableSchema {
+	return &memdb.TableSchema{
+		Name: tablePeeringImportedServices,
+		Indexes: map[string]*memdb.IndexSchema{
+			indexID: {
+				Name:         indexID,
+				AllowMissing: false,
+				Unique:       true,
+				Indexer: &memdb.CompoundIndex{
+					Indexes: []memdb.Indexer{
+						&memdb.StringFieldIndex{
+							Field:     "PeerName",
+							Lowercase: true,
+						},
+						&memdb.StringFieldIndex{
+							Field:     "ServiceName",
+							Lowercase: true,
+						},
+					},
+				},
+			},
+			indexPeer: {
+				Name:         indexPeer,
+				AllowMissing: false,
+				Unique:       false,
+				Indexer: &memdb.StringFieldIndex{
+					Field:     "PeerName",
+					Lowercase: true,
+				},
+			},
+		},
+	}
+}
+
+// Peerings is used to pull all the peerings for the snapshot.
+func (s *Snapshot) Peerings() ([]*structs.Peering, error) {
+	iter, err := s.tx.Get(tablePeering, indexID)
+	if err != nil {
+		return nil, err
+	}
+
+	var ret []*structs.Peering
+	for wrapped := iter.Next(); wrapped != nil; wrapped = iter.Next() {
+		ret = append(ret, wrapped.(*structs.Peering))
+	}
+	return ret, nil
+}
+
+// Peering is used when restoring from a snapshot.
+func (s *Restore) Peering(p *structs.Peering) error {
+	if err := s.tx.Insert(tablePeering, p); err != nil {
+		return fmt.Errorf("failed restoring peering: %s", err)
+	}
+	if err := indexUpdateMaxTxn(s.tx, p.ModifyIndex, tablePeering); err != nil {
+		return fmt.Errorf("failed updating index: %s", err)
+	}
+	return nil
+}
+
+// PeeringImportedServices is used to pull all the imported services for the
+// snapshot.
+func (s *Snapshot) PeeringImportedServices() ([]*structs.PeeringImportedService, error) {
+	iter, err := s.tx.Get(tablePeeringImportedServices, indexID)
+	if err != nil {
+		return nil, err
+	}
+
+	var ret []*structs.PeeringImportedService
+	for wrapped := iter.Next(); wrapped != nil; wrapped = iter.Next() {
+		ret = append(ret, wrapped.(*structs.PeeringImportedService))
+	}
+	return ret, nil
+}
+
+// PeeringImportedService is used when restoring from a snapshot.
+func (s *Restore) PeeringImportedService(svc *structs.PeeringImportedService) error {
+	if err := s.tx.Insert(tablePeeringImportedServices, svc); err != nil {
+		return fmt.Errorf("failed restoring imported service: %s", err)
+	}
+	if err := indexUpdateMaxTxn(s.tx, svc.ModifyIndex, tablePeeringImportedServices); err != nil {
+		return fmt.Errorf("failed updating index: %s", err)
+	}
+	return nil
+}
+
+// PeeringRead returns the peering with the given name.
+func (s *Store) PeeringRead(ws memdb.WatchSet, name string) (uint64, *structs.Peering, error) {
+	tx := s.db.Txn(false)
+	defer tx.Abort()
+
+	return peeringReadTxn(tx, ws, indexName, name)
+}
+
+// PeeringReadByID returns the peering with the given ID.
+func (s *Store) PeeringReadByID(ws memdb.WatchSet, id string) (uint64, *structs.Peering, error) {
+	tx := s.db.Txn(false)
+	defer tx.Abort()
+
+	return peeringReadTxn(tx, ws, indexID, id)
+}
+
+func peeringReadTxn(tx ReadTxn, ws memdb.WatchSet, index, value string) (uint64, *structs.Peering, error) {
+	idx := maxIndexTxn(tx, tablePeering)
+
+	watchCh, existing, err := tx.FirstWatch(tablePeering, index, value)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed peering lookup: %s", err)
+	}
+	ws.Add(watchCh)
+
+	if existing == nil {
+		return idx, nil, nil
+	}
+	return idx, existing.(*structs.Peering), nil
+}
+
+// PeeringList returns all the peerings.
+func (s *Store) PeeringList(ws memdb.WatchSet) (uint64, structs.Peerings, error) {
+	tx := s.db.Txn(false)
+	defer tx.Abort()
+
+	idx := maxIndexTxn(tx, tablePeering)
+
+	iter, err := tx.Get(tablePeering, indexID)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed peering lookup: %s", err)
+	}
+	ws.Add(iter.WatchCh())
+
+	var result structs.Peerings
+	for v := iter.Next(); v != nil; v = iter.Next() {
+		result = append(result, v.(*structs.Peering))
+	}
+	result.Sort()
+	return idx, result, nil
+}
+
+// PeeringWrite creates or updates a peering. The name of an existing peering
+// cannot be changed.
+func (s *Store) PeeringWrite(idx uint64, p *structs.Peering) error {
+	tx := s.db.WriteTxn(idx)
+	defer tx.Abort()
+
+	if err := peeringWriteTxn(tx, idx, p); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func peeringWriteTxn(tx WriteTxn, idx uint64, p *structs.Peering) error {
+	if p.ID == "" {
+		return fmt.Errorf("missing peering ID")
+	}
+	if p.Name == "" {
+		return fmt.Errorf("missing peering name")
+	}
+
+	existing, err := tx.First(tablePeering, indexID, p.ID)
+	if err != nil {
+		return fmt.Errorf("failed peering lookup: %s", err)
+	}
+	if existing != nil {
+		prev := existing.(*structs.Peering)
+		if !strings.EqualFold(prev.Name, p.Name) {
+			return fmt.Errorf("cannot rename peering %q", prev.Name)
+		}
+		p.CreateIndex = prev.CreateIndex
+	} else {
+		byName, err := tx.First(tablePeering, indexName, p.Name)
+		if err != nil {
+			return fmt.Errorf("failed peering lookup: %s", err)
+		}
+		if byName != nil {
+			return fmt.Errorf("a peering named %q already exists", p.Name)
+		}
+		p.CreateIndex = idx
+	}
+	p.ModifyIndex = idx
+	// The stream status is only ever computed on reads.
+	p.StreamStatus = nil
+
+	if err := tx.Insert(tablePeering, p); err != nil {
+		return fmt.Errorf("failed inserting peering: %s", err)
+	}
+	if err := tx.Insert(tableIndex, &IndexEntry{tablePeering, idx}); err != nil {
+		return fmt.Errorf("failed updating index: %s", err)
+	}
+	return nil
+}
+
+// PeeringDelete deletes a peering along with all the services imported from
+// the peer.
+func (s *Store) PeeringDelete(idx uint64, name string) error {
+	tx := s.db.WriteTxn(idx)
+	defer tx.Abort()
+
+	existing, err := tx.First(tablePeering, indexName, name)
+	if err != nil {
+		return fmt.Errorf("failed peering lookup: %s", err)
+	}
+	if existing == nil {
+		return nil
+	}
+
+	if err := tx.Delete(tablePeering, existing); err != nil {
+		return fmt.Errorf("failed removing peering: %s", err)
+	}
+	if err := tx.Insert(tableIndex, &IndexEntry{tablePeering, idx}); err != nil {
+		return fmt.Errorf("failed updating index: %s", err)
+	}
+	if err := peeringDeleteImportedServicesTxn(tx, idx, name); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func peeringDeleteImportedServicesTxn(tx WriteTxn, idx uint64, peerName string) error {
+	n, err := tx.DeleteAll(tablePeeringImportedServices, indexPeer, peerName)
+	if err != nil {
+		return fmt.Errorf("failed removing imported services: %s", err)
+	}
+	if n == 0 {
+		return nil
+	}
+	if err := tx.Insert(tableIndex, &IndexEntry{tablePeeringImportedServices, idx}); err != nil {
+		return fmt.Errorf("failed updating index: %s", err)
+	}
+	return nil
+}
+
+// PeeringImport applies what was received on the replication stream of a
+// peering.
+func (s *Store) PeeringImport(idx uint64, req *structs.PeeringImportRequest) error {
+	tx := s.db.WriteTxn(idx)
+	defer tx.Abort()
+
+	existing, err := tx.First(tablePeering, indexName, req.PeerName)
+	if err != nil {
+		return fmt.Errorf("failed peering lookup: %s", err)
+	}
+	if existing == nil {
+		// The peering was deleted while the import was in flight.
+		return nil
+	}
+	peering := *existing.(*structs.Peering)
+
+	switch req.Op {
+	case structs.PeeringImportUpsertService:
+		if peering.IsTerminated() {
+			return nil
+		}
+		err = peeringImportServiceTxn(tx, idx, peering.Name, req.ServiceName, req.Nodes)
+
+	case structs.PeeringImportDeleteService:
+		err = peeringImportDeleteServiceTxn(tx, idx, peering.Name, req.ServiceName)
+
+	case structs.PeeringImportSetMeshInfo:
+		if peering.IsTerminated() {
+			return nil
+		}
+		peering.PeerMesh = req.MeshInfo
+		err = peeringWriteTxn(tx, idx, &peering)
+
+	case structs.PeeringImportActivate:
+		if peering.State == structs.PeeringStateActive || peering.IsTerminated() {
+			return nil
+		}
+		peering.State = structs.PeeringStateActive
+		err = peeringWriteTxn(tx, idx, &peering)
+
+	case structs.PeeringImportTerminate:
+		if peering.IsTerminated() {
+			return nil
+		}
+		peering.State = structs.PeeringStateTerminated
+		peering.PeerMesh = nil
+		if err = peeringWriteTxn(tx, idx, &peering); err == nil {
+			err = peeringDeleteImportedServicesTxn(tx, idx, peering.Name)
+		}
+
+	default:
+		return fmt.Errorf("invalid peering import operation: %q", req.Op)
+	}
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func peeringImportServiceTxn(tx WriteTxn, idx uint64, peerName, serviceName string, nodes structs.CheckServiceNodes) error {
+	if serviceName == "" {
+		return fmt.Errorf("missing imported service name")
+	}
+
+	svc := &structs.PeeringImportedService{
+		PeerName:    peerName,
+		ServiceName: serviceName,
+		Nodes:       nodes,
+	}
+	existing, err := tx.First(tablePeeringImportedServices, indexID, peerName, serviceName)
+	if err != nil {
+		return fmt.Errorf("failed imported service lookup: %s", err)
+	}
+	if existing != nil {
+		svc.CreateIndex = existing.(*structs.PeeringImportedService).CreateIndex
+	} else {
+		svc.CreateIndex = idx
+	}
+	svc.ModifyIndex = idx
+
+	if err := tx.Insert(tablePeeringImportedServices, svc); err != nil {
+		return fmt.Errorf("failed inserting imported service: %s", err)
+	}
+	if err := tx.Insert(tableIndex, &IndexEntry{tablePeeringImportedServices, idx}); err != nil {
+		return fmt.Errorf("failed updating index: %s", err)
+	}
+	return nil
+}
+
+func peeringImportDeleteServiceTxn(tx WriteTxn, idx uint64, peerName, serviceName string) error {
+	existing, err := tx.First(tablePeeringImportedServices, indexID, peerName, serviceName)
+	if err != nil {
+		return fmt.Errorf("failed imported service lookup: %s", err)
+	}
+	if existing == nil {
+		return nil
+	}
+
+	if err := tx.Delete(tablePeeringImportedServices, existing); err != nil {
+		return fmt.Errorf("failed removing imported service: %s", err)
+	}
+	if err := tx.Insert(tableIndex, &IndexEntry{tablePeeringImportedServices, idx}); err != nil {
+		return fmt.Errorf("failed updating index: %s", err)
+	}
+	return nil
+}
+
+// PeeringImportedServiceNodes returns the instances of a service imported from
+// a peer. The returned nodes must not be modified.
+func (s *Store) PeeringImportedServiceNodes(ws memdb.WatchSet, peerName, serviceName string) (uint64, structs.CheckServiceNodes, error) {
+	tx := s.db.Txn(false)
+	defer tx.Abort()
+
+	idx := maxIndexTxn(tx, tablePeeringImportedServices)
+
+	watchCh, existing, err := tx.FirstWatch(tablePeeringImportedServices, indexID, peerName, serviceName)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed imported service lookup: %s", err)
+	}
+	ws.Add(watchCh)
+
+	if existing == nil {
+		return idx, nil, nil
+	}
+	return idx, existing.(*structs.PeeringImportedService).Nodes, nil
+}
+
+// PeeringImportedServiceTagNodes returns a copy of the instances of a service
+// imported from a peer which have all the given tags.
+func (s *Store) PeeringImportedServiceTagNodes(ws memdb.WatchSet, peerName, serviceName string, tags []string) (uint64, structs.CheckServiceNodes, error) {
+	idx, nodes, err := s.PeeringImportedServiceNodes(ws, peerName, serviceName)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	results := make(structs.CheckServiceNodes, 0, len(nodes))
+	for _, n := range nodes {
+		if n.Service != nil && serviceTagsFilter(n.Service.ToServiceNode(n.Node.Node), tags) {
+			continue
+		}
+		results = append(results, n)
+	}
+	return idx, results, nil
+}
+
+// PeeringImportedServiceNames returns the names of the services imported from
+// a peer.
+func (s *Store) PeeringImportedServiceNames(ws memdb.WatchSet, peerName string) (uint64, []string, error) {
+	tx := s.db.Txn(false)
+	defer tx.Abort()
+
+	idx := maxIndexTxn(tx, tablePeeringImportedServices)
+
+	iter, err := tx.Get(tablePeeringImportedServices, indexPeer, peerName)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed imported service lookup: %s", err)
+	}
+	ws.Add(iter.WatchCh())
+
+	var names []string
+	for v := iter.Next(); v != nil; v = iter.Next() {
+		names = append(names, v.(*structs.PeeringImportedService).ServiceName)
+	}
+	return idx, names, nil
+}
+
+// ExportedServicesForPeer returns the services the exported-services config
+// entry of the default partition exports to the given peer. A service named
+// with the wildcard exports all the services of its namespace.
+func (s *Store) ExportedServicesForPeer(ws memdb.WatchSet, peerName string) (uint64, []structs.ServiceName, error) {
+	tx := s.db.Txn(false)
+	defer tx.Abort()
+
+	entMeta := structs.DefaultEnterpriseMetaInDefaultPartition()
+	idx, raw, err := configEntryTxn(tx, ws, structs.ExportedServices, entMeta.PartitionOrDefault(), entMeta)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to fetch exported-services config entry: %w", err)
+	}
+	entry, ok := raw.(*structs.ExportedServicesConfigEntry)
+	if !ok {
+		return idx, nil, nil
+	}
+
+	seen := make(map[structs.ServiceName]struct{})
+	var result []structs.ServiceName
+	add := func(sn structs.ServiceName) {
+		if _, ok := seen[sn]; ok {
+			return
+		}
+		seen[sn] = struct{}{}
+		result = append(result, sn)
+	}
+
+	for _, svc := range entry.Services {
+		if !exportedToPeer(svc, peerName) {
+			continue
+		}
+		svcMeta := acl.NewEnterpriseMetaWithPartition(entMeta.PartitionOrDefault(), svc.Namespace)
+
+		if svc.Name != structs.WildcardSpecifier {
+			add(structs.NewServiceName(svc.Name, &svcMeta))
+			continue
+		}
+
+		kindIdx, names, err := serviceNamesOfKindTxn(tx, ws, structs.ServiceKindTypical)
+		if err != nil {
+			return 0, nil, fmt.Errorf("failed to list services: %w", err)
+		}
+		if kindIdx > idx {
+			idx = kindIdx
+		}
+		for _, n := range names {
+			if n.Service.EnterpriseMeta.NamespaceOrDefault() != svcMeta.NamespaceOrDefault() {
+				continue
+			}
+			add(n.Service)
+		}
+	}
+	return idx, result, nil
+}
+
+// PeersForExportedService returns the names of the peers the given service is
+// exported to.
+func (s *Store) PeersForExportedService(ws memdb.WatchSet, service structs.ServiceName) (uint64, []string, error) {
+	tx := s.db.Txn(false)
+	defer tx.Abort()
+
+	entMeta := structs.DefaultEnterpriseMetaInDefaultPartition()
+	idx, raw, err := configEntryTxn(tx, ws, structs.ExportedServices, entMeta.PartitionOrDefault(), entMeta)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to fetch exported-services config entry: %w", err)
+	}
+	entry, ok := raw.(*structs.ExportedServicesConfigEntry)
+	if !ok {
+		return idx, nil, nil
+	}
+
+	seen := make(map[string]struct{})
+	var peers []string
+	for _, svc := range entry.Services {
+		if svc.Name != structs.WildcardSpecifier && svc.Name != service.Name {
+			continue
+		}
+		if acl.NamespaceOrDefault(svc.Namespace) != service.EnterpriseMeta.NamespaceOrDefault() {
+			continue
+		}
+		for _, c := range svc.Consumers {
+			if c.PeerName == "" {
+				continue
+			}
+			if _, ok := seen[c.PeerName]; ok {
+				continue
+			}
+			seen[c.PeerName] = struct{}{}
+			peers = append(peers, c.PeerName)
+		}
+	}
+	return idx, peers, nil
+}
+
+func exportedToPeer(svc structs.ExportedService, peerName string) bool {
+	for _, c := range svc.Consumers {
+		if strings.EqualFold(c.PeerName, peerName) {
+			return true
+		}
+	}
+	return false
+}
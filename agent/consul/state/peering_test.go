@@ -0,0 +1,198 @@
+package state
+
+import (
+	"testing"
+
+	"github.com/hashicorp/go-memdb"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hashicorp/consul/agent/structs"
+)
+
+func TestStore_Peering(t *testing.T) {
+	s := testStateStore(t)
+
+	ws := memdb.NewWatchSet()
+	idx, peerings, err := s.PeeringList(ws)
+	require.NoError(t, err)
+	require.Equal(t, uint64(0), idx)
+	require.Empty(t, peerings)
+
+	peering := &structs.Peering{
+		ID:    "5a2d2a1f-2ac4-4b4e-b8b5-6b5b3c0c7d01",
+		Name:  "east",
+		State: structs.PeeringStatePending,
+	}
+	require.NoError(t, s.PeeringWrite(1, peering))
+	require.True(t, watchFired(ws))
+
+	// Names are unique, and cannot change.
+	require.Error(t, s.PeeringWrite(2, &structs.Peering{ID: "other", Name: "EAST"}))
+	require.Error(t, s.PeeringWrite(2, &structs.Peering{ID: peering.ID, Name: "west"}))
+
+	ws = memdb.NewWatchSet()
+	idx, got, err := s.PeeringRead(ws, "east")
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), idx)
+	require.Equal(t, peering, got)
+
+	_, got, err = s.PeeringReadByID(nil, peering.ID)
+	require.NoError(t, err)
+	require.Equal(t, peering, got)
+
+	// Activation and mesh info only update the peering.
+	require.NoError(t, s.PeeringImport(2, &structs.PeeringImportRequest{
+		Op:       structs.PeeringImportActivate,
+		PeerName: "east",
+	}))
+	require.True(t, watchFired(ws))
+	meshInfo := &structs.PeeringMeshInfo{
+		TrustDomain:      "d1b5a4e0.consul",
+		RootPEMs:         []string{"root"},
+		Datacenter:       "dc2",
+		GatewayAddresses: []string{"10.0.0.1:443"},
+	}
+	require.NoError(t, s.PeeringImport(3, &structs.PeeringImportRequest{
+		Op:       structs.PeeringImportSetMeshInfo,
+		PeerName: "east",
+		MeshInfo: meshInfo,
+	}))
+	_, got, err = s.PeeringRead(nil, "east")
+	require.NoError(t, err)
+	require.Equal(t, structs.PeeringStateActive, got.State)
+	require.Equal(t, meshInfo, got.PeerMesh)
+	require.Equal(t, uint64(1), got.CreateIndex)
+	require.Equal(t, uint64(3), got.ModifyIndex)
+
+	nodes := structs.CheckServiceNodes{
+		{
+			Node:    &structs.Node{Node: "n1", Address: "10.0.0.2"},
+			Service: &structs.NodeService{ID: "api", Service: "api", Port: 8080},
+		},
+	}
+	ws = memdb.NewWatchSet()
+	_, imported, err := s.PeeringImportedServiceNodes(ws, "east", "api")
+	require.NoError(t, err)
+	require.Empty(t, imported)
+	require.NoError(t, s.PeeringImport(4, &structs.PeeringImportRequest{
+		Op:          structs.PeeringImportUpsertService,
+		PeerName:    "east",
+		ServiceName: "api",
+		Nodes:       nodes,
+	}))
+	require.True(t, watchFired(ws))
+
+	idx, imported, err = s.PeeringImportedServiceNodes(nil, "east", "api")
+	require.NoError(t, err)
+	require.Equal(t, uint64(4), idx)
+	require.Equal(t, nodes, imported)
+
+	_, names, err := s.PeeringImportedServiceNames(nil, "east")
+	require.NoError(t, err)
+	require.Equal(t, []string{"api"}, names)
+
+	// Terminating the peering drops its imports and mesh info, and nothing
+	// can be imported anymore.
+	require.NoError(t, s.PeeringImport(5, &structs.PeeringImportRequest{
+		Op:       structs.PeeringImportTerminate,
+		PeerName: "east",
+	}))
+	require.NoError(t, s.PeeringImport(6, &structs.PeeringImportRequest{
+		Op:          structs.PeeringImportUpsertService,
+		PeerName:    "east",
+		ServiceName: "web",
+		Nodes:       nodes,
+	}))
+	_, got, err = s.PeeringRead(nil, "east")
+	require.NoError(t, err)
+	require.True(t, got.IsTerminated())
+	require.Nil(t, got.PeerMesh)
+	_, names, err = s.PeeringImportedServiceNames(nil, "east")
+	require.NoError(t, err)
+	require.Empty(t, names)
+
+	// Deleting the peering deletes its imports.
+	require.NoError(t, s.PeeringWrite(7, &structs.Peering{ID: "b1", Name: "west"}))
+	require.NoError(t, s.PeeringImport(8, &structs.PeeringImportRequest{
+		Op:          structs.PeeringImportUpsertService,
+		PeerName:    "west",
+		ServiceName: "api",
+		Nodes:       nodes,
+	}))
+	require.NoError(t, s.PeeringDelete(9, "west"))
+	_, got, err = s.PeeringRead(nil, "west")
+	require.NoError(t, err)
+	require.Nil(t, got)
+	_, imported, err = s.PeeringImportedServiceNodes(nil, "west", "api")
+	require.NoError(t, err)
+	require.Empty(t, imported)
+
+	// Imports for unknown peerings are ignored.
+	require.NoError(t, s.PeeringImport(10, &structs.PeeringImportRequest{
+		Op:          structs.PeeringImportUpsertService,
+		PeerName:    "west",
+		ServiceName: "api",
+		Nodes:       nodes,
+	}))
+	_, imported, err = s.PeeringImportedServiceNodes(nil, "west", "api")
+	require.NoError(t, err)
+	require.Empty(t, imported)
+}
+
+func TestStore_ExportedServicesForPeer(t *testing.T) {
+	s := testStateStore(t)
+
+	ws := memdb.NewWatchSet()
+	_, services, err := s.ExportedServicesForPeer(ws, "east")
+	require.NoError(t, err)
+	require.Empty(t, services)
+
+	testRegisterNode(t, s, 1, "node1")
+	testRegisterService(t, s, 2, "node1", "api")
+	testRegisterService(t, s, 3, "node1", "db")
+
+	require.NoError(t, s.EnsureConfigEntry(4, &structs.ExportedServicesConfigEntry{
+		Name: "default",
+		Services: []structs.ExportedService{
+			{
+				Name:      "api",
+				Consumers: []structs.ServiceConsumer{{PeerName: "east"}, {PeerName: "west"}},
+			},
+			{
+				Name:      "web",
+				Consumers: []structs.ServiceConsumer{{PeerName: "west"}},
+			},
+		},
+	}))
+	require.True(t, watchFired(ws))
+
+	_, services, err = s.ExportedServicesForPeer(nil, "east")
+	require.NoError(t, err)
+	require.Equal(t, []structs.ServiceName{structs.NewServiceName("api", nil)}, services)
+
+	_, peers, err := s.PeersForExportedService(nil, structs.NewServiceName("api", nil))
+	require.NoError(t, err)
+	require.Equal(t, []string{"east", "west"}, peers)
+
+	// The wildcard exports all the services of the namespace.
+	require.NoError(t, s.EnsureConfigEntry(5, &structs.ExportedServicesConfigEntry{
+		Name: "default",
+		Services: []structs.ExportedService{
+			{
+				Name:      structs.WildcardSpecifier,
+				Consumers: []structs.ServiceConsumer{{PeerName: "east"}},
+			},
+		},
+	}))
+
+	_, services, err = s.ExportedServicesForPeer(nil, "east")
+	require.NoError(t, err)
+	require.ElementsMatch(t, []structs.ServiceName{
+		structs.NewServiceName("api", nil),
+		structs.NewServiceName("db", nil),
+	}, services)
+
+	_, peers, err = s.PeersForExportedService(nil, structs.NewServiceName("db", nil))
+	require.NoError(t, err)
+	require.Equal(t, []string{"east"}, peers)
+}
@@ -17,6 +17,8 @@ func newDBSchema() *memdb.DBSchema {
 		bindingRulesTableSchema,
 		caBuiltinProviderTableSchema,
 		caConfigTableSchema,
+		caRevocationListsTableSchema,
+		caRevocationsTableSchema,
 		caRootTableSchema,
 		checksTableSchema,
 		configTableSchema,
@@ -28,6 +30,8 @@ func newDBSchema() *memdb.DBSchema {
 		kvsTableSchema,
 		meshTopologyTableSchema,
 		nodesTableSchema,
+		peeringTableSchema,
+		peeringImportedServicesTableSchema,
 		policiesTableSchema,
 		preparedQueriesTableSchema,
 		rolesTableSchema,
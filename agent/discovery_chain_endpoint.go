@@ -35,12 +35,32 @@ func (s *HTTPHandlers) DiscoveryChainRead(resp http.ResponseWriter, req *http.Re
 	}
 	args.WithEnterpriseMeta(&entMeta)
 
+	_, simulate := req.URL.Query()["simulate"]
+	if simulate && req.Method != "POST" {
+		return nil, BadRequestError{Reason: "Simulating a discovery chain requires a POST request with the config entries to simulate"}
+	}
+
+	var entries []structs.ConfigEntry
 	if req.Method == "POST" {
 		var raw map[string]interface{}
 		if err := decodeBody(req.Body, &raw); err != nil {
 			return nil, BadRequestError{Reason: fmt.Sprintf("Request decoding failed: %v", err)}
 		}
 
+		if simulate {
+			entries, err = decodeDiscoveryChainSimulateEntries(raw)
+			if err != nil {
+				return nil, BadRequestError{Reason: fmt.Sprintf("Request decoding failed: %v", err)}
+			}
+			for _, entry := range entries {
+				var meta acl.EnterpriseMeta
+				if err := s.parseEntMetaForConfigEntryKind(entry.GetKind(), req, &meta); err != nil {
+					return nil, err
+				}
+				entry.GetEnterpriseMeta().Merge(&meta)
+			}
+		}
+
 		apiReq, err := decodeDiscoveryChainReadRequest(raw)
 		if err != nil {
 			return nil, BadRequestError{Reason: fmt.Sprintf("Request decoding failed: %v", err)}
@@ -58,6 +78,10 @@ func (s *HTTPHandlers) DiscoveryChainRead(resp http.ResponseWriter, req *http.Re
 		}
 	}
 
+	if simulate {
+		return s.discoveryChainSimulate(resp, &args, entries)
+	}
+
 	// Make the RPC request
 	var out structs.DiscoveryChainResponse
 	defer setMeta(resp, &out.QueryMeta)
@@ -91,6 +115,30 @@ func (s *HTTPHandlers) DiscoveryChainRead(resp http.ResponseWriter, req *http.Re
 	return discoveryChainReadResponse{Chain: out.Chain}, nil
 }
 
+// discoveryChainSimulate compiles the discovery chain requested by args as if
+// the given config entries had been written.
+func (s *HTTPHandlers) discoveryChainSimulate(resp http.ResponseWriter, args *structs.DiscoveryChainRequest, entries []structs.ConfigEntry) (interface{}, error) {
+	simArgs := structs.DiscoveryChainSimulateRequest{
+		DiscoveryChainRequest: *args,
+		Entries:               entries,
+	}
+
+	var out structs.DiscoveryChainSimulateResponse
+	defer setMeta(resp, &out.QueryMeta)
+
+	if err := s.agent.RPC("DiscoveryChain.Simulate", &simArgs, &out); err != nil {
+		return nil, err
+	}
+	out.ConsistencyLevel = args.QueryOptions.ConsistencyLevel()
+
+	return discoveryChainSimulateResponse{
+		Chain:        out.Chain,
+		CurrentChain: out.CurrentChain,
+		Explain:      out.Explain,
+		Diff:         out.Diff,
+	}, nil
+}
+
 // discoveryChainReadRequest is the API variation of structs.DiscoveryChainRequest
 type discoveryChainReadRequest struct {
 	OverrideMeshGateway    structs.MeshGatewayConfig `alias:"override_mesh_gateway"`
@@ -103,6 +151,46 @@ type discoveryChainReadResponse struct {
 	Chain *structs.CompiledDiscoveryChain
 }
 
+// discoveryChainSimulateResponse is the API variation of
+// structs.DiscoveryChainSimulateResponse
+type discoveryChainSimulateResponse struct {
+	Chain        *structs.CompiledDiscoveryChain
+	CurrentChain *structs.CompiledDiscoveryChain
+	Explain      string
+	Diff         string
+}
+
+// decodeDiscoveryChainSimulateEntries decodes the config entries to simulate
+// from the Entries field of the request body.
+func decodeDiscoveryChainSimulateEntries(raw map[string]interface{}) ([]structs.ConfigEntry, error) {
+	rawEntries, ok := raw["Entries"]
+	if !ok {
+		rawEntries, ok = raw["entries"]
+	}
+	if !ok {
+		return nil, fmt.Errorf("Payload does not contain an Entries key at the top level")
+	}
+
+	list, ok := rawEntries.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("Entries must be a list of config entries")
+	}
+
+	entries := make([]structs.ConfigEntry, 0, len(list))
+	for i, rawEntry := range list {
+		m, ok := rawEntry.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("Entries[%d] is not a config entry", i)
+		}
+		entry, err := structs.DecodeConfigEntry(m)
+		if err != nil {
+			return nil, fmt.Errorf("Entries[%d]: %v", i, err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
 func decodeDiscoveryChainReadRequest(raw map[string]interface{}) (*discoveryChainReadRequest, error) {
 	var apiReq discoveryChainReadRequest
 	// TODO(dnephin): at this time only JSON payloads are read, so it is unlikely
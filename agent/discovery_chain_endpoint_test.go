@@ -363,3 +363,92 @@ func TestDiscoveryChainRead(t *testing.T) {
 		require.Equal(t, expectModifiedWithOverrides, value.Chain)
 	}))
 }
+
+func TestDiscoveryChainRead_Simulate(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+
+	a := NewTestAgent(t, "")
+	defer a.Shutdown()
+	testrpc.WaitForTestAgent(t, a.RPC, "dc1")
+
+	{ // Register a resolver that the simulated one replaces.
+		args := &structs.ConfigEntryRequest{
+			Datacenter: "dc1",
+			Entry: &structs.ServiceResolverConfigEntry{
+				Kind:           structs.ServiceResolver,
+				Name:           "web",
+				ConnectTimeout: 33 * time.Second,
+			},
+		}
+		var out bool
+		require.NoError(t, a.RPC("ConfigEntry.Apply", args, &out))
+		require.True(t, out)
+	}
+
+	t.Run("GET is not allowed", func(t *testing.T) {
+		req, err := http.NewRequest("GET", "/v1/discovery-chain/web?simulate", nil)
+		require.NoError(t, err)
+
+		resp := httptest.NewRecorder()
+		_, err = a.srv.DiscoveryChainRead(resp, req)
+		require.Error(t, err)
+		_, ok := err.(BadRequestError)
+		require.True(t, ok)
+	})
+
+	t.Run("missing entries", func(t *testing.T) {
+		body := `{ "OverrideProtocol": "http" }`
+		req, err := http.NewRequest("POST", "/v1/discovery-chain/web?simulate", strings.NewReader(body))
+		require.NoError(t, err)
+
+		resp := httptest.NewRecorder()
+		_, err = a.srv.DiscoveryChainRead(resp, req)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "Payload does not contain an Entries key")
+	})
+
+	t.Run("invalid entry", func(t *testing.T) {
+		body := `{ "Entries": [ { "Kind": "service-resolver", "Name": "web", "Bogus": true } ] }`
+		req, err := http.NewRequest("POST", "/v1/discovery-chain/web?simulate", strings.NewReader(body))
+		require.NoError(t, err)
+
+		resp := httptest.NewRecorder()
+		_, err = a.srv.DiscoveryChainRead(resp, req)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "Entries[0]")
+	})
+
+	t.Run("simulate resolver", func(t *testing.T) {
+		body := ` {
+			"entries": [
+				{
+					"kind": "service-resolver",
+					"name": "web",
+					"connect_timeout": "10s",
+					"failover": {
+						"*": { "datacenters": ["dc2"] }
+					}
+				}
+			]
+		} `
+		req, err := http.NewRequest("POST", "/v1/discovery-chain/web?simulate", strings.NewReader(body))
+		require.NoError(t, err)
+
+		resp := httptest.NewRecorder()
+		obj, err := a.srv.DiscoveryChainRead(resp, req)
+		require.NoError(t, err)
+
+		value := obj.(discoveryChainSimulateResponse)
+
+		require.Equal(t, 33*time.Second, value.CurrentChain.Nodes["resolver:web.default.default.dc1"].Resolver.ConnectTimeout)
+		require.Equal(t, 10*time.Second, value.Chain.Nodes["resolver:web.default.default.dc1"].Resolver.ConnectTimeout)
+		require.Contains(t, value.Explain, "failover 1: web.default.default.dc2")
+		require.Contains(t, value.Diff, "-   connect timeout: 33s\n")
+		require.Contains(t, value.Diff, "+   connect timeout: 10s\n")
+		require.Contains(t, value.Diff, "+   failover 1: web.default.default.dc2\n")
+	})
+}
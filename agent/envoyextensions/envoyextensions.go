@@ -0,0 +1,129 @@
+// Package envoyextensions implements the built-in Envoy extensions that can be
+// configured on proxies through the EnvoyExtensions field of the
+// service-defaults and proxy-defaults config entries.
+//
+// An extension patches the xDS resources Consul generates for a proxy. This
+// package only depends on the API client and the Envoy protos so that the
+// arguments of an extension can be validated when its config entry is written.
+package envoyextensions
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	envoy_listener_v3 "github.com/envoyproxy/go-control-plane/envoy/config/listener/v3"
+	"github.com/mitchellh/mapstructure"
+
+	"github.com/hashicorp/consul/api"
+)
+
+const (
+	// LuaExtensionName is the name of the extension adding a Lua HTTP filter.
+	LuaExtensionName = "builtin/lua"
+
+	// WASMExtensionName is the name of the extension adding a WebAssembly HTTP
+	// filter loaded from a file local to the proxy.
+	WASMExtensionName = "builtin/wasm"
+)
+
+const (
+	// ListenerInbound selects the public listener of a proxy.
+	ListenerInbound = "inbound"
+
+	// ListenerOutbound selects the listeners of the upstreams of a proxy.
+	ListenerOutbound = "outbound"
+)
+
+// Extension patches the xDS resources generated for a proxy.
+type Extension interface {
+	// CanApply returns true if the extension applies to proxies of the given
+	// kind.
+	CanApply(kind api.ServiceKind) bool
+
+	// PatchFilter patches a network filter of one of the proxy's listeners.
+	// Inbound is true for the filters of the public listener and false for
+	// those of the upstream listeners. It returns false if the filter was left
+	// unchanged.
+	PatchFilter(filter *envoy_listener_v3.Filter, inbound bool) (*envoy_listener_v3.Filter, bool, error)
+}
+
+// constructor builds an extension from its arguments, validating them.
+type constructor func(args map[string]interface{}) (Extension, error)
+
+// constructors indexes the built-in extensions by name.
+var constructors = map[string]constructor{
+	LuaExtensionName:  newLuaExtension,
+	WASMExtensionName: newWASMExtension,
+}
+
+// Construct returns the extension with the given name configured with the
+// given arguments.
+func Construct(name string, args map[string]interface{}) (Extension, error) {
+	fn, ok := constructors[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown extension %q, must be one of: %s", name, strings.Join(Names(), ", "))
+	}
+	return fn(args)
+}
+
+// Validate returns an error if the extension with the given name doesn't
+// exist or if its arguments are invalid.
+func Validate(name string, args map[string]interface{}) error {
+	_, err := Construct(name, args)
+	return err
+}
+
+// Names returns the sorted names of the built-in extensions.
+func Names() []string {
+	names := make([]string, 0, len(constructors))
+	for name := range constructors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// decodeArguments decodes the arguments of an extension into out, rejecting
+// any argument out has no field for.
+func decodeArguments(args map[string]interface{}, out interface{}) error {
+	d, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		ErrorUnused:      true,
+		WeaklyTypedInput: true,
+		Result:           out,
+	})
+	if err != nil {
+		return err
+	}
+	return d.Decode(args)
+}
+
+// listenerFilter holds the arguments shared by the extensions adding an HTTP
+// filter to the listeners of a proxy.
+type listenerFilter struct {
+	ProxyType api.ServiceKind
+	Listener  string
+}
+
+func (f *listenerFilter) normalizeAndValidate() error {
+	if f.ProxyType == "" {
+		f.ProxyType = api.ServiceKindConnectProxy
+	}
+	if f.ProxyType != api.ServiceKindConnectProxy {
+		return fmt.Errorf("unsupported ProxyType %q, only %q is supported", f.ProxyType, api.ServiceKindConnectProxy)
+	}
+	if f.Listener != ListenerInbound && f.Listener != ListenerOutbound {
+		return fmt.Errorf("Listener must be %q or %q", ListenerInbound, ListenerOutbound)
+	}
+	return nil
+}
+
+func (f *listenerFilter) CanApply(kind api.ServiceKind) bool {
+	return kind == f.ProxyType
+}
+
+// matches returns true if the filter applies to an inbound or outbound
+// listener.
+func (f *listenerFilter) matches(inbound bool) bool {
+	return (f.Listener == ListenerInbound) == inbound
+}
@@ -0,0 +1,182 @@
+package envoyextensions
+
+import (
+	"testing"
+
+	envoy_listener_v3 "github.com/envoyproxy/go-control-plane/envoy/config/listener/v3"
+	envoy_lua_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/lua/v3"
+	envoy_http_wasm_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/wasm/v3"
+	envoy_http_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/http_connection_manager/v3"
+	envoy_tcp_proxy_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/tcp_proxy/v3"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/golang/protobuf/ptypes/wrappers"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hashicorp/consul/api"
+)
+
+func TestConstruct_Validation(t *testing.T) {
+	cases := map[string]struct {
+		name   string
+		args   map[string]interface{}
+		expErr string
+	}{
+		"unknown extension": {
+			name:   "builtin/nope",
+			expErr: `unknown extension "builtin/nope", must be one of: builtin/lua, builtin/wasm`,
+		},
+		"lua valid": {
+			name: LuaExtensionName,
+			args: map[string]interface{}{
+				"Listener": "inbound",
+				"Script":   "function envoy_on_request(h) end",
+			},
+		},
+		"lua missing script": {
+			name:   LuaExtensionName,
+			args:   map[string]interface{}{"Listener": "inbound"},
+			expErr: "Script is required",
+		},
+		"lua bad listener": {
+			name: LuaExtensionName,
+			args: map[string]interface{}{
+				"Listener": "sideways",
+				"Script":   "function envoy_on_request(h) end",
+			},
+			expErr: `Listener must be "inbound" or "outbound"`,
+		},
+		"lua unsupported proxy type": {
+			name: LuaExtensionName,
+			args: map[string]interface{}{
+				"ProxyType": "terminating-gateway",
+				"Listener":  "inbound",
+				"Script":    "function envoy_on_request(h) end",
+			},
+			expErr: `unsupported ProxyType "terminating-gateway"`,
+		},
+		"lua unknown argument": {
+			name: LuaExtensionName,
+			args: map[string]interface{}{
+				"Listener": "inbound",
+				"Script":   "function envoy_on_request(h) end",
+				"Scripts":  "typo",
+			},
+			expErr: "invalid keys: Scripts",
+		},
+		"wasm valid": {
+			name: WASMExtensionName,
+			args: map[string]interface{}{
+				"Listener":      "outbound",
+				"Filename":      "/etc/envoy/filter.wasm",
+				"Configuration": `{"header": "x-filtered"}`,
+				"FailOpen":      true,
+			},
+		},
+		"wasm missing filename": {
+			name:   WASMExtensionName,
+			args:   map[string]interface{}{"Listener": "outbound"},
+			expErr: "Filename is required",
+		},
+	}
+
+	for name, tc := range cases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			err := Validate(tc.name, tc.args)
+			if tc.expErr == "" {
+				require.NoError(t, err)
+			} else {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tc.expErr)
+			}
+		})
+	}
+}
+
+func TestLuaExtension_PatchFilter(t *testing.T) {
+	ext, err := Construct(LuaExtensionName, map[string]interface{}{
+		"Listener": "inbound",
+		"Script":   "function envoy_on_request(h) end",
+	})
+	require.NoError(t, err)
+	require.True(t, ext.CanApply(api.ServiceKindConnectProxy))
+	require.False(t, ext.CanApply(api.ServiceKindIngressGateway))
+
+	// Outbound listeners are left alone.
+	filter := testHTTPConnectionManagerFilter(t)
+	_, patched, err := ext.PatchFilter(filter, false)
+	require.NoError(t, err)
+	require.False(t, patched)
+
+	// So are network filters other than the HTTP connection manager.
+	tcpFilter, err := makeFilter("envoy.filters.network.tcp_proxy", &envoy_tcp_proxy_v3.TcpProxy{StatPrefix: "db"})
+	require.NoError(t, err)
+	_, patched, err = ext.PatchFilter(tcpFilter, true)
+	require.NoError(t, err)
+	require.False(t, patched)
+
+	newFilter, patched, err := ext.PatchFilter(filter, true)
+	require.NoError(t, err)
+	require.True(t, patched)
+
+	httpFilters := testHTTPFilters(t, newFilter)
+	require.Equal(t, []string{"envoy.filters.http.rbac", "envoy.filters.http.lua", "envoy.filters.http.router"}, httpFilterNames(httpFilters))
+
+	var lua envoy_lua_v3.Lua
+	require.NoError(t, ptypes.UnmarshalAny(httpFilters[1].GetTypedConfig(), &lua))
+	require.Equal(t, "function envoy_on_request(h) end", lua.InlineCode)
+}
+
+func TestWASMExtension_PatchFilter(t *testing.T) {
+	ext, err := Construct(WASMExtensionName, map[string]interface{}{
+		"Listener":      "outbound",
+		"Filename":      "/etc/envoy/filter.wasm",
+		"RootID":        "filter",
+		"Configuration": `{"header": "x-filtered"}`,
+	})
+	require.NoError(t, err)
+
+	newFilter, patched, err := ext.PatchFilter(testHTTPConnectionManagerFilter(t), false)
+	require.NoError(t, err)
+	require.True(t, patched)
+
+	httpFilters := testHTTPFilters(t, newFilter)
+	require.Equal(t, []string{"envoy.filters.http.rbac", "envoy.filters.http.wasm", "envoy.filters.http.router"}, httpFilterNames(httpFilters))
+
+	var wasm envoy_http_wasm_v3.Wasm
+	require.NoError(t, ptypes.UnmarshalAny(httpFilters[1].GetTypedConfig(), &wasm))
+	require.Equal(t, "/etc/envoy/filter.wasm", wasm.Config.Name)
+	require.Equal(t, "filter", wasm.Config.RootId)
+	require.Equal(t, wasmRuntimeV8, wasm.Config.GetVmConfig().Runtime)
+	require.Equal(t, "/etc/envoy/filter.wasm", wasm.Config.GetVmConfig().Code.GetLocal().GetFilename())
+
+	var cfg wrappers.StringValue
+	require.NoError(t, ptypes.UnmarshalAny(wasm.Config.Configuration, &cfg))
+	require.Equal(t, `{"header": "x-filtered"}`, cfg.Value)
+}
+
+func testHTTPConnectionManagerFilter(t *testing.T) *envoy_listener_v3.Filter {
+	t.Helper()
+	hcm := &envoy_http_v3.HttpConnectionManager{StatPrefix: "public_listener"}
+	for _, name := range []string{"envoy.filters.http.rbac", routerHTTPFilterName} {
+		hcm.HttpFilters = append(hcm.HttpFilters, &envoy_http_v3.HttpFilter{Name: name})
+	}
+	filter, err := makeFilter(httpConnectionManagerFilterName, hcm)
+	require.NoError(t, err)
+	return filter
+}
+
+func testHTTPFilters(t *testing.T, filter *envoy_listener_v3.Filter) []*envoy_http_v3.HttpFilter {
+	t.Helper()
+	var hcm envoy_http_v3.HttpConnectionManager
+	require.NoError(t, ptypes.UnmarshalAny(filter.GetTypedConfig(), &hcm))
+	return hcm.HttpFilters
+}
+
+func httpFilterNames(filters []*envoy_http_v3.HttpFilter) []string {
+	var names []string
+	for _, f := range filters {
+		names = append(names, f.Name)
+	}
+	return names
+}
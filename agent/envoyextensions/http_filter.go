@@ -0,0 +1,79 @@
+package envoyextensions
+
+import (
+	"fmt"
+
+	envoy_listener_v3 "github.com/envoyproxy/go-control-plane/envoy/config/listener/v3"
+	envoy_http_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/http_connection_manager/v3"
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+)
+
+const (
+	httpConnectionManagerFilterName = "envoy.filters.network.http_connection_manager"
+	routerHTTPFilterName            = "envoy.filters.http.router"
+)
+
+// insertHTTPFilter adds an HTTP filter to an HTTP connection manager right
+// before its router, so it runs after the filters Consul relies on such as
+// RBAC. Other network filters are returned unchanged.
+func insertHTTPFilter(filter *envoy_listener_v3.Filter, httpFilter *envoy_http_v3.HttpFilter) (*envoy_listener_v3.Filter, bool, error) {
+	if filter.Name != httpConnectionManagerFilterName {
+		return filter, false, nil
+	}
+	typedConfig := filter.GetTypedConfig()
+	if typedConfig == nil {
+		return filter, false, fmt.Errorf("http connection manager filter has no typed config")
+	}
+
+	var hcm envoy_http_v3.HttpConnectionManager
+	if err := ptypes.UnmarshalAny(typedConfig, &hcm); err != nil {
+		return filter, false, fmt.Errorf("failed to unmarshal http connection manager: %w", err)
+	}
+
+	var (
+		httpFilters = make([]*envoy_http_v3.HttpFilter, 0, len(hcm.HttpFilters)+1)
+		inserted    bool
+	)
+	for _, f := range hcm.HttpFilters {
+		if f.Name == routerHTTPFilterName && !inserted {
+			httpFilters = append(httpFilters, httpFilter)
+			inserted = true
+		}
+		httpFilters = append(httpFilters, f)
+	}
+	if !inserted {
+		return filter, false, fmt.Errorf("http connection manager has no %s filter", routerHTTPFilterName)
+	}
+	hcm.HttpFilters = httpFilters
+
+	newFilter, err := makeFilter(httpConnectionManagerFilterName, &hcm)
+	if err != nil {
+		return filter, false, err
+	}
+	return newFilter, true, nil
+}
+
+func makeEnvoyHTTPFilter(name string, cfg proto.Message) (*envoy_http_v3.HttpFilter, error) {
+	typed, err := ptypes.MarshalAny(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &envoy_http_v3.HttpFilter{
+		Name:       name,
+		ConfigType: &envoy_http_v3.HttpFilter_TypedConfig{TypedConfig: typed},
+	}, nil
+}
+
+func makeFilter(name string, cfg proto.Message) (*envoy_listener_v3.Filter, error) {
+	typed, err := ptypes.MarshalAny(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &envoy_listener_v3.Filter{
+		Name:       name,
+		ConfigType: &envoy_listener_v3.Filter_TypedConfig{TypedConfig: typed},
+	}, nil
+}
@@ -0,0 +1,48 @@
+package envoyextensions
+
+import (
+	"errors"
+
+	envoy_listener_v3 "github.com/envoyproxy/go-control-plane/envoy/config/listener/v3"
+	envoy_lua_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/lua/v3"
+)
+
+// luaExtension adds a Lua HTTP filter running an inline script to the inbound
+// or outbound listeners of a proxy.
+type luaExtension struct {
+	listenerFilter `mapstructure:",squash"`
+
+	// Script is the Lua source code, which must define envoy_on_request or
+	// envoy_on_response, or both.
+	Script string
+}
+
+var _ Extension = (*luaExtension)(nil)
+
+func newLuaExtension(args map[string]interface{}) (Extension, error) {
+	var ext luaExtension
+	if err := decodeArguments(args, &ext); err != nil {
+		return nil, err
+	}
+	if err := ext.normalizeAndValidate(); err != nil {
+		return nil, err
+	}
+	if ext.Script == "" {
+		return nil, errors.New("Script is required")
+	}
+	return &ext, nil
+}
+
+func (e *luaExtension) PatchFilter(filter *envoy_listener_v3.Filter, inbound bool) (*envoy_listener_v3.Filter, bool, error) {
+	if !e.matches(inbound) {
+		return filter, false, nil
+	}
+
+	luaFilter, err := makeEnvoyHTTPFilter("envoy.filters.http.lua", &envoy_lua_v3.Lua{
+		InlineCode: e.Script,
+	})
+	if err != nil {
+		return filter, false, err
+	}
+	return insertHTTPFilter(filter, luaFilter)
+}
@@ -0,0 +1,100 @@
+package envoyextensions
+
+import (
+	"errors"
+
+	envoy_core_v3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	envoy_listener_v3 "github.com/envoyproxy/go-control-plane/envoy/config/listener/v3"
+	envoy_http_wasm_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/wasm/v3"
+	envoy_wasm_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/wasm/v3"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/golang/protobuf/ptypes/wrappers"
+)
+
+const wasmRuntimeV8 = "envoy.wasm.runtime.v8"
+
+// wasmExtension adds a WebAssembly HTTP filter to the inbound or outbound
+// listeners of a proxy. The module is read by Envoy from a file on the host
+// of the proxy.
+type wasmExtension struct {
+	listenerFilter `mapstructure:",squash"`
+
+	// Filename is the path of the WebAssembly module on the proxy's host.
+	Filename string
+
+	// Name identifies the plugin within its VM. Defaults to the file name.
+	Name string
+
+	// RootID selects the root context of the module, for modules
+	// implementing several plugins.
+	RootID string
+
+	// VMID lets filters loading the same module share a VM.
+	VMID string
+
+	// Configuration is passed to the plugin when it starts.
+	Configuration string
+
+	// FailOpen lets requests through when the plugin fails, instead of
+	// rejecting them.
+	FailOpen bool
+}
+
+var _ Extension = (*wasmExtension)(nil)
+
+func newWASMExtension(args map[string]interface{}) (Extension, error) {
+	var ext wasmExtension
+	if err := decodeArguments(args, &ext); err != nil {
+		return nil, err
+	}
+	if err := ext.normalizeAndValidate(); err != nil {
+		return nil, err
+	}
+	if ext.Filename == "" {
+		return nil, errors.New("Filename is required")
+	}
+	if ext.Name == "" {
+		ext.Name = ext.Filename
+	}
+	return &ext, nil
+}
+
+func (e *wasmExtension) PatchFilter(filter *envoy_listener_v3.Filter, inbound bool) (*envoy_listener_v3.Filter, bool, error) {
+	if !e.matches(inbound) {
+		return filter, false, nil
+	}
+
+	pluginConfig := &envoy_wasm_v3.PluginConfig{
+		Name:     e.Name,
+		RootId:   e.RootID,
+		FailOpen: e.FailOpen,
+		Vm: &envoy_wasm_v3.PluginConfig_VmConfig{
+			VmConfig: &envoy_wasm_v3.VmConfig{
+				VmId:    e.VMID,
+				Runtime: wasmRuntimeV8,
+				Code: &envoy_core_v3.AsyncDataSource{
+					Specifier: &envoy_core_v3.AsyncDataSource_Local{
+						Local: &envoy_core_v3.DataSource{
+							Specifier: &envoy_core_v3.DataSource_Filename{Filename: e.Filename},
+						},
+					},
+				},
+			},
+		},
+	}
+	if e.Configuration != "" {
+		cfg, err := ptypes.MarshalAny(&wrappers.StringValue{Value: e.Configuration})
+		if err != nil {
+			return filter, false, err
+		}
+		pluginConfig.Configuration = cfg
+	}
+
+	wasmFilter, err := makeEnvoyHTTPFilter("envoy.filters.http.wasm", &envoy_http_wasm_v3.Wasm{
+		Config: pluginConfig,
+	})
+	if err != nil {
+		return filter, false, err
+	}
+	return insertHTTPFilter(filter, wasmFilter)
+}
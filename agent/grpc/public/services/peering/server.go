@@ -0,0 +1,142 @@
+package peering
+
+import (
+	"context"
+	"crypto/subtle"
+	"io"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/go-memdb"
+
+	"github.com/hashicorp/consul/acl"
+	"github.com/hashicorp/consul/agent/structs"
+	"github.com/hashicorp/consul/proto/pbpeering"
+)
+
+const (
+	// TypeURLService is the type URL of the services exported to a peer.
+	TypeURLService = "type.googleapis.com/peering.ExportedService"
+
+	// TypeURLMeshInfo is the type URL of the mesh information of a peer.
+	TypeURLMeshInfo = "type.googleapis.com/peering.MeshInfo"
+)
+
+// metadataKeyStreamSecret is the gRPC metadata the dialing side of a peering
+// presents its stream secret in.
+const metadataKeyStreamSecret = "x-consul-peering-stream-secret"
+
+// ContextWithStreamSecret returns a context with the stream secret of a
+// peering attached, to open its replication stream with.
+func ContextWithStreamSecret(ctx context.Context, secret string) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, metadataKeyStreamSecret, secret)
+}
+
+// streamSecretFromContext returns the stream secret in the gRPC metadata
+// attached to the given context.
+func streamSecretFromContext(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	secrets, ok := md[metadataKeyStreamSecret]
+	if ok && len(secrets) > 0 {
+		return secrets[0]
+	}
+	return ""
+}
+
+// Server replicates exported resources with the peers of this cluster. It
+// serves the streams opened by the dialing side of peerings, and handles the
+// streams the leader opens to its own peers.
+type Server struct {
+	Config
+}
+
+type Config struct {
+	Backend  Backend
+	GetStore func() StateStore
+	Tracker  *Tracker
+	Logger   hclog.Logger
+
+	// Datacenter is the name of the local datacenter, sent to peers along
+	// with the mesh information.
+	Datacenter string
+}
+
+// Backend applies what peers send through raft.
+type Backend interface {
+	IsLeader() bool
+	PeeringImport(req *structs.PeeringImportRequest) error
+}
+
+type StateStore interface {
+	PeeringRead(ws memdb.WatchSet, name string) (uint64, *structs.Peering, error)
+	PeeringReadByID(ws memdb.WatchSet, id string) (uint64, *structs.Peering, error)
+	ExportedServicesForPeer(ws memdb.WatchSet, peerName string) (uint64, []structs.ServiceName, error)
+	CheckServiceNodes(ws memdb.WatchSet, serviceName string, entMeta *acl.EnterpriseMeta) (uint64, structs.CheckServiceNodes, error)
+	CARootsAndConfig(ws memdb.WatchSet) (uint64, structs.CARoots, *structs.CAConfiguration, error)
+	ServiceDump(ws memdb.WatchSet, kind structs.ServiceKind, useKind bool, entMeta *acl.EnterpriseMeta) (uint64, structs.CheckServiceNodes, error)
+	AbandonCh() <-chan struct{}
+}
+
+func NewServer(cfg Config) *Server {
+	return &Server{cfg}
+}
+
+func (s *Server) Register(grpcServer *grpc.Server) {
+	pbpeering.RegisterPeeringServiceServer(grpcServer, s)
+}
+
+// StreamResources is opened by the dialing side of a peering. Its first
+// message must be a subscription carrying the ID of the peering on this side,
+// and the stream must present the stream secret of the peering, which
+// authenticates the peer. The ID alone is not a secret, reads return it.
+func (s *Server) StreamResources(stream pbpeering.PeeringService_StreamResourcesServer) error {
+	// Only the leader can apply what the peer sends, the peer retries the
+	// other servers until it finds it.
+	if !s.Backend.IsLeader() {
+		return status.Error(codes.FailedPrecondition, "cannot establish a peering stream on a follower node")
+	}
+
+	first, err := stream.Recv()
+	if err == io.EOF {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	req := first.GetRequest()
+	if req == nil || req.PeerID == "" {
+		return status.Error(codes.InvalidArgument, "first message must be a subscription carrying the peer ID")
+	}
+
+	_, peering, err := s.GetStore().PeeringReadByID(nil, req.PeerID)
+	switch {
+	case err != nil:
+		return status.Errorf(codes.Internal, "failed to look up peering: %v", err)
+	case peering == nil:
+		return status.Error(codes.NotFound, "peering not found")
+	case peering.ShouldDial():
+		return status.Error(codes.FailedPrecondition, "peering must be dialed from this side")
+	case peering.IsTerminated():
+		return status.Error(codes.FailedPrecondition, "peering was terminated")
+	}
+
+	secret := streamSecretFromContext(stream.Context())
+	if peering.StreamSecret == "" || subtle.ConstantTimeCompare([]byte(secret), []byte(peering.StreamSecret)) != 1 {
+		return status.Error(codes.PermissionDenied, "invalid peering stream secret, a new peering token may have been generated")
+	}
+
+	return s.HandleStream(HandleStreamRequest{
+		PeerName:     peering.Name,
+		StreamSecret: peering.StreamSecret,
+		Stream:       stream,
+		Initial:      first,
+	})
+}
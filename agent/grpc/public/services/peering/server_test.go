@@ -0,0 +1,313 @@
+package peering
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/hashicorp/consul/agent/consul/state"
+	"github.com/hashicorp/consul/agent/structs"
+	"github.com/hashicorp/consul/proto/pbpeering"
+	"github.com/hashicorp/consul/sdk/testutil/retry"
+)
+
+// testBackend applies imports directly to a state store.
+type testBackend struct {
+	lock   sync.Mutex
+	store  *state.Store
+	index  uint64
+	leader bool
+}
+
+func (b *testBackend) IsLeader() bool {
+	return b.leader
+}
+
+func (b *testBackend) PeeringImport(req *structs.PeeringImportRequest) error {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.index++
+	return b.store.PeeringImport(b.index, req)
+}
+
+func (b *testBackend) write(t *testing.T, fn func(idx uint64, store *state.Store) error) {
+	t.Helper()
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.index++
+	require.NoError(t, fn(b.index, b.store))
+}
+
+func newTestServer(t *testing.T, dc string) (*Server, *testBackend) {
+	t.Helper()
+
+	backend := &testBackend{store: state.NewStateStore(nil), leader: true}
+	srv := NewServer(Config{
+		Backend:    backend,
+		GetStore:   func() StateStore { return backend.store },
+		Tracker:    NewTracker(),
+		Logger:     hclog.NewNullLogger(),
+		Datacenter: dc,
+	})
+	return srv, backend
+}
+
+func testClient(t *testing.T, server *Server) pbpeering.PeeringServiceClient {
+	t.Helper()
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	grpcServer := grpc.NewServer()
+	server.Register(grpcServer)
+
+	go grpcServer.Serve(lis)
+	t.Cleanup(grpcServer.Stop)
+
+	conn, err := grpc.DialContext(context.Background(), lis.Addr().String(), grpc.WithInsecure())
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, conn.Close())
+	})
+
+	return pbpeering.NewPeeringServiceClient(conn)
+}
+
+func TestServer_StreamResources_Rejections(t *testing.T) {
+	acceptor, backend := newTestServer(t, "dc1")
+	client := testClient(t, acceptor)
+
+	backend.write(t, func(idx uint64, store *state.Store) error {
+		return store.PeeringWrite(idx, &structs.Peering{
+			ID:                  "dialer",
+			Name:                "dialer",
+			PeerServerAddresses: []string{"10.0.0.1:8502"},
+		})
+	})
+	backend.write(t, func(idx uint64, store *state.Store) error {
+		return store.PeeringWrite(idx, &structs.Peering{
+			ID:           "acceptor",
+			Name:         "acceptor",
+			State:        structs.PeeringStatePending,
+			StreamSecret: "secret",
+		})
+	})
+
+	openWithSecret := func(peerID, secret string) error {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		stream, err := client.StreamResources(ContextWithStreamSecret(ctx, secret))
+		require.NoError(t, err)
+		require.NoError(t, stream.Send(&pbpeering.ReplicationMessage{
+			Payload: &pbpeering.ReplicationMessage_Request_{
+				Request: &pbpeering.ReplicationMessage_Request{PeerID: peerID, ResourceURL: TypeURLService},
+			},
+		}))
+		_, err = stream.Recv()
+		return err
+	}
+	open := func(peerID string) error {
+		return openWithSecret(peerID, "secret")
+	}
+
+	require.Equal(t, codes.InvalidArgument, status.Code(open("")))
+	require.Equal(t, codes.NotFound, status.Code(open("unknown")))
+	require.Equal(t, codes.FailedPrecondition, status.Code(open("dialer")))
+
+	// The peering ID alone does not authenticate the peer.
+	require.Equal(t, codes.PermissionDenied, status.Code(openWithSecret("acceptor", "")))
+	require.Equal(t, codes.PermissionDenied, status.Code(openWithSecret("acceptor", "wrong")))
+	require.NoError(t, open("acceptor"))
+
+	backend.leader = false
+	require.Equal(t, codes.FailedPrecondition, status.Code(open("dialer")))
+}
+
+func TestServer_HandleStream(t *testing.T) {
+	acceptor, acceptorBackend := newTestServer(t, "dc1")
+	dialer, dialerBackend := newTestServer(t, "dc2")
+	client := testClient(t, acceptor)
+
+	acceptorBackend.write(t, func(idx uint64, store *state.Store) error {
+		return store.PeeringWrite(idx, &structs.Peering{
+			ID:           "acceptor-id",
+			Name:         "east",
+			State:        structs.PeeringStatePending,
+			StreamSecret: "secret",
+		})
+	})
+	acceptorBackend.write(t, func(idx uint64, store *state.Store) error {
+		return store.CASetConfig(idx, &structs.CAConfiguration{ClusterID: "11111111-2222-3333-4444-555555555555"})
+	})
+	acceptorBackend.write(t, func(idx uint64, store *state.Store) error {
+		_, err := store.CARootSetCAS(idx, 0, []*structs.CARoot{{ID: "root", RootCert: "root-pem", Active: true}})
+		return err
+	})
+	acceptorBackend.write(t, func(idx uint64, store *state.Store) error {
+		return store.EnsureRegistration(idx, &structs.RegisterRequest{
+			Node:    "node1",
+			Address: "10.0.0.1",
+			Service: &structs.NodeService{ID: "api", Service: "api", Port: 8080},
+		})
+	})
+	acceptorBackend.write(t, func(idx uint64, store *state.Store) error {
+		return store.EnsureRegistration(idx, &structs.RegisterRequest{
+			Node:    "node1",
+			Address: "10.0.0.1",
+			Service: &structs.NodeService{
+				Kind:    structs.ServiceKindMeshGateway,
+				ID:      "mesh-gateway",
+				Service: "mesh-gateway",
+				Port:    8443,
+				TaggedAddresses: map[string]structs.ServiceAddress{
+					structs.TaggedAddressWAN: {Address: "198.18.0.1", Port: 443},
+				},
+			},
+		})
+	})
+	acceptorBackend.write(t, func(idx uint64, store *state.Store) error {
+		return store.EnsureConfigEntry(idx, &structs.ExportedServicesConfigEntry{
+			Name: "default",
+			Services: []structs.ExportedService{
+				{Name: "api", Consumers: []structs.ServiceConsumer{{PeerName: "east"}}},
+			},
+		})
+	})
+
+	dialerBackend.write(t, func(idx uint64, store *state.Store) error {
+		return store.CASetConfig(idx, &structs.CAConfiguration{ClusterID: "66666666-7777-8888-9999-000000000000"})
+	})
+	dialerBackend.write(t, func(idx uint64, store *state.Store) error {
+		return store.PeeringWrite(idx, &structs.Peering{
+			ID:                  "dialer-id",
+			Name:                "west",
+			State:               structs.PeeringStateEstablishing,
+			PeerID:              "acceptor-id",
+			StreamSecret:        "secret",
+			PeerServerAddresses: []string{"127.0.0.1:8502"},
+		})
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stream, err := client.StreamResources(ContextWithStreamSecret(ctx, "secret"))
+	require.NoError(t, err)
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- dialer.HandleStream(HandleStreamRequest{
+			PeerName:     "west",
+			PeerID:       "acceptor-id",
+			StreamSecret: "secret",
+			Stream:       stream,
+		})
+	}()
+
+	retry.Run(t, func(r *retry.R) {
+		_, nodes, err := dialerBackend.store.PeeringImportedServiceNodes(nil, "west", "api")
+		require.NoError(r, err)
+		require.Len(r, nodes, 1)
+		require.Equal(r, "api", nodes[0].Service.Service)
+		require.Equal(r, "10.0.0.1", nodes[0].Node.Address)
+
+		_, peering, err := dialerBackend.store.PeeringRead(nil, "west")
+		require.NoError(r, err)
+		require.Equal(r, structs.PeeringStateActive, peering.State)
+		require.Equal(r, &structs.PeeringMeshInfo{
+			TrustDomain:      "11111111-2222-3333-4444-555555555555.consul",
+			RootPEMs:         []string{"root-pem"},
+			Datacenter:       "dc1",
+			GatewayAddresses: []string{"198.18.0.1:443"},
+		}, peering.PeerMesh)
+
+		_, peering, err = acceptorBackend.store.PeeringRead(nil, "east")
+		require.NoError(r, err)
+		require.Equal(r, structs.PeeringStateActive, peering.State)
+		require.NotNil(r, peering.PeerMesh)
+		require.Equal(r, "dc2", peering.PeerMesh.Datacenter)
+	})
+
+	status := acceptor.Tracker.StreamStatus("east")
+	require.NotNil(t, status)
+	require.True(t, status.Connected)
+
+	// Un-exporting the service deletes it on the peer.
+	acceptorBackend.write(t, func(idx uint64, store *state.Store) error {
+		return store.DeleteConfigEntry(idx, structs.ExportedServices, "default", nil)
+	})
+	retry.Run(t, func(r *retry.R) {
+		_, names, err := dialerBackend.store.PeeringImportedServiceNames(nil, "west")
+		require.NoError(r, err)
+		require.Empty(r, names)
+	})
+
+	// Deleting the peering terminates it on the peer.
+	acceptorBackend.write(t, func(idx uint64, store *state.Store) error {
+		return store.PeeringDelete(idx, "east")
+	})
+	select {
+	case err := <-errCh:
+		require.NoError(t, err)
+	case <-time.After(10 * time.Second):
+		t.Fatal("stream did not close")
+	}
+
+	_, peering, err := dialerBackend.store.PeeringRead(nil, "west")
+	require.NoError(t, err)
+	require.True(t, peering.IsTerminated())
+	require.Nil(t, peering.PeerMesh)
+
+	status = dialer.Tracker.StreamStatus("west")
+	require.NotNil(t, status)
+	require.False(t, status.Connected)
+}
+
+func TestServer_StreamResources_StreamSecretRotation(t *testing.T) {
+	acceptor, backend := newTestServer(t, "dc1")
+	client := testClient(t, acceptor)
+
+	peering := &structs.Peering{
+		ID:           "acceptor-id",
+		Name:         "east",
+		State:        structs.PeeringStatePending,
+		StreamSecret: "secret",
+	}
+	backend.write(t, func(idx uint64, store *state.Store) error {
+		return store.PeeringWrite(idx, peering)
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	stream, err := client.StreamResources(ContextWithStreamSecret(ctx, "secret"))
+	require.NoError(t, err)
+	require.NoError(t, stream.Send(&pbpeering.ReplicationMessage{
+		Payload: &pbpeering.ReplicationMessage_Request_{
+			Request: &pbpeering.ReplicationMessage_Request{PeerID: "acceptor-id", ResourceURL: TypeURLService},
+		},
+	}))
+	_, err = stream.Recv()
+	require.NoError(t, err)
+
+	// Generating a new token closes the stream opened with the previous one.
+	backend.write(t, func(idx uint64, store *state.Store) error {
+		rotated := *peering
+		rotated.StreamSecret = "rotated"
+		return store.PeeringWrite(idx, &rotated)
+	})
+	for {
+		_, err := stream.Recv()
+		if err != nil {
+			require.Contains(t, err.Error(), errStreamSecretRotated.Error())
+			break
+		}
+	}
+}
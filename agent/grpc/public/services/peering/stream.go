@@ -0,0 +1,464 @@
+package peering
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"reflect"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/golang/protobuf/ptypes"
+	"github.com/golang/protobuf/ptypes/any"
+	"github.com/hashicorp/go-memdb"
+
+	"github.com/hashicorp/consul/agent/connect"
+	"github.com/hashicorp/consul/agent/structs"
+	"github.com/hashicorp/consul/proto/pbpeering"
+	"github.com/hashicorp/consul/proto/pbservice"
+)
+
+// errPeeringDeleted ends a stream once the peering was deleted locally and
+// the peer was told about it.
+var errPeeringDeleted = errors.New("peering was deleted")
+
+// errPeeringTerminated ends a stream once the peer deleted the peering on its
+// side.
+var errPeeringTerminated = errors.New("peering was terminated by the peer")
+
+// errStreamSecretRotated ends a stream once the stream secret it was opened
+// with is replaced, so that generating a new token cuts off the previous ones.
+var errStreamSecretRotated = errors.New("peering stream secret was rotated")
+
+// BidirectionalStream is implemented by both ends of a replication stream.
+type BidirectionalStream interface {
+	Send(*pbpeering.ReplicationMessage) error
+	Recv() (*pbpeering.ReplicationMessage, error)
+	Context() context.Context
+}
+
+// HandleStreamRequest describes a replication stream with a peer.
+type HandleStreamRequest struct {
+	// PeerName is the local name of the peer.
+	PeerName string
+
+	// PeerID is the ID of the peering on the accepting side. It is only set
+	// when dialing the peer, and sent along with the subscriptions.
+	PeerID string
+
+	// StreamSecret is the stream secret of the peering the stream was opened
+	// with. The stream is closed when the peering has a different one.
+	StreamSecret string
+
+	// Stream is the established stream.
+	Stream BidirectionalStream
+
+	// Initial is a message already received on the stream, if any.
+	Initial *pbpeering.ReplicationMessage
+}
+
+// HandleStream replicates resources with a peer until the stream is closed,
+// or the peering is deleted on either side. Both sides subscribe to what the
+// other exports to them, so the stream is handled the same way whichever side
+// opened it.
+func (s *Server) HandleStream(req HandleStreamRequest) (err error) {
+	logger := s.Logger.With("peer_name", req.PeerName)
+
+	ctx, cancel := context.WithCancel(req.Stream.Context())
+	defer cancel()
+
+	s.Tracker.connected(req.PeerName)
+	defer func() {
+		s.Tracker.Disconnected(req.PeerName, err)
+	}()
+
+	var sendLock sync.Mutex
+	send := func(msg *pbpeering.ReplicationMessage) error {
+		sendLock.Lock()
+		defer sendLock.Unlock()
+		return req.Stream.Send(msg)
+	}
+
+	for _, url := range []string{TypeURLService, TypeURLMeshInfo} {
+		sub := &pbpeering.ReplicationMessage{
+			Payload: &pbpeering.ReplicationMessage_Request_{
+				Request: &pbpeering.ReplicationMessage_Request{
+					PeerID:      req.PeerID,
+					ResourceURL: url,
+				},
+			},
+		}
+		if err := send(sub); err != nil {
+			return fmt.Errorf("failed to subscribe to %s: %w", url, err)
+		}
+	}
+
+	// The receive loop, the exporters and the peering watch all report here.
+	errCh := make(chan error, 4)
+
+	go func() {
+		errCh <- s.watchPeering(ctx, req.PeerName, req.StreamSecret, send)
+	}()
+
+	recvCh := make(chan *pbpeering.ReplicationMessage)
+	go func() {
+		for {
+			msg, err := req.Stream.Recv()
+			if err != nil {
+				errCh <- err
+				return
+			}
+			select {
+			case recvCh <- msg:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	activated := false
+	subscribed := make(map[string]struct{})
+	handle := func(msg *pbpeering.ReplicationMessage) error {
+		s.Tracker.received(req.PeerName)
+
+		if !activated {
+			activated = true
+			if err := s.activate(req.PeerName); err != nil {
+				return err
+			}
+		}
+
+		switch payload := msg.Payload.(type) {
+		case *pbpeering.ReplicationMessage_Request_:
+			url := payload.Request.ResourceURL
+			if _, ok := subscribed[url]; ok {
+				return nil
+			}
+
+			switch url {
+			case TypeURLService:
+				go func() {
+					errCh <- s.exportServices(ctx, req.PeerName, send)
+				}()
+			case TypeURLMeshInfo:
+				go func() {
+					errCh <- s.exportMeshInfo(ctx, send)
+				}()
+			default:
+				logger.Warn("ignoring subscription to an unknown resource type", "resource_url", url)
+				return nil
+			}
+			subscribed[url] = struct{}{}
+
+		case *pbpeering.ReplicationMessage_Response_:
+			return s.importResource(req.PeerName, payload.Response)
+
+		case *pbpeering.ReplicationMessage_Terminated_:
+			logger.Info("peering was deleted by the peer")
+			err := s.Backend.PeeringImport(&structs.PeeringImportRequest{
+				Op:       structs.PeeringImportTerminate,
+				PeerName: req.PeerName,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to terminate peering: %w", err)
+			}
+			return errPeeringTerminated
+		}
+		return nil
+	}
+
+	if req.Initial != nil {
+		if err := handle(req.Initial); err != nil {
+			return streamResult(ctx, err)
+		}
+	}
+
+	for {
+		select {
+		case msg := <-recvCh:
+			if err := handle(msg); err != nil {
+				return streamResult(ctx, err)
+			}
+		case err := <-errCh:
+			return streamResult(ctx, err)
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// streamResult turns the errors that end a stream normally into nil.
+func streamResult(ctx context.Context, err error) error {
+	switch {
+	case err == io.EOF,
+		errors.Is(err, errPeeringDeleted),
+		errors.Is(err, errPeeringTerminated),
+		ctx.Err() != nil:
+		return nil
+	}
+	return err
+}
+
+// activate records that the peer connected at least once.
+func (s *Server) activate(peerName string) error {
+	_, peering, err := s.GetStore().PeeringRead(nil, peerName)
+	if err != nil {
+		return fmt.Errorf("failed to look up peering: %w", err)
+	}
+	if peering == nil || peering.State == structs.PeeringStateActive {
+		return nil
+	}
+	err = s.Backend.PeeringImport(&structs.PeeringImportRequest{
+		Op:       structs.PeeringImportActivate,
+		PeerName: peerName,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to activate peering: %w", err)
+	}
+	return nil
+}
+
+// watchPeering tells the peer once the peering is deleted locally, and ends
+// the stream once the stream secret of the peering is rotated.
+func (s *Server) watchPeering(ctx context.Context, peerName, streamSecret string, send func(*pbpeering.ReplicationMessage) error) error {
+	for {
+		store := s.GetStore()
+		ws := memdb.NewWatchSet()
+		ws.Add(store.AbandonCh())
+
+		_, peering, err := store.PeeringRead(ws, peerName)
+		if err != nil {
+			return fmt.Errorf("failed to look up peering: %w", err)
+		}
+		if peering == nil {
+			err := send(&pbpeering.ReplicationMessage{
+				Payload: &pbpeering.ReplicationMessage_Terminated_{
+					Terminated: &pbpeering.ReplicationMessage_Terminated{},
+				},
+			})
+			if err != nil {
+				return fmt.Errorf("failed to notify peer of the peering deletion: %w", err)
+			}
+			return errPeeringDeleted
+		}
+		if peering.IsTerminated() {
+			return errPeeringTerminated
+		}
+		if peering.StreamSecret != streamSecret {
+			return errStreamSecretRotated
+		}
+
+		if err := ws.WatchCtx(ctx); err != nil {
+			return err
+		}
+	}
+}
+
+// exportServices sends the instances of the services exported to the peer
+// whenever they change.
+func (s *Server) exportServices(ctx context.Context, peerName string, send func(*pbpeering.ReplicationMessage) error) error {
+	sent := make(map[string]structs.CheckServiceNodes)
+	for {
+		store := s.GetStore()
+		ws := memdb.NewWatchSet()
+		ws.Add(store.AbandonCh())
+
+		_, names, err := store.ExportedServicesForPeer(ws, peerName)
+		if err != nil {
+			return fmt.Errorf("failed to list exported services: %w", err)
+		}
+
+		current := make(map[string]structs.CheckServiceNodes, len(names))
+		for _, sn := range names {
+			_, nodes, err := store.CheckServiceNodes(ws, sn.Name, &sn.EnterpriseMeta)
+			if err != nil {
+				return fmt.Errorf("failed to read instances of exported service %q: %w", sn.Name, err)
+			}
+			current[sn.Name] = nodes
+		}
+
+		for name, nodes := range current {
+			if prev, ok := sent[name]; ok && reflect.DeepEqual(prev, nodes) {
+				continue
+			}
+			exported := &pbpeering.ExportedService{
+				Nodes: make([]*pbservice.CheckServiceNode, 0, len(nodes)),
+			}
+			for i := range nodes {
+				exported.Nodes = append(exported.Nodes, pbservice.NewCheckServiceNodeFromStructs(&nodes[i]))
+			}
+			resource, err := ptypes.MarshalAny(exported)
+			if err != nil {
+				return fmt.Errorf("failed to marshal exported service %q: %w", name, err)
+			}
+			err = send(responseMessage(TypeURLService, name, pbpeering.ReplicationMessage_Response_UPSERT, resource))
+			if err != nil {
+				return fmt.Errorf("failed to send exported service %q: %w", name, err)
+			}
+		}
+		for name := range sent {
+			if _, ok := current[name]; ok {
+				continue
+			}
+			err := send(responseMessage(TypeURLService, name, pbpeering.ReplicationMessage_Response_DELETE, nil))
+			if err != nil {
+				return fmt.Errorf("failed to send deletion of exported service %q: %w", name, err)
+			}
+		}
+		sent = current
+
+		if err := ws.WatchCtx(ctx); err != nil {
+			return err
+		}
+	}
+}
+
+// exportMeshInfo sends the mesh information of this cluster whenever it
+// changes.
+func (s *Server) exportMeshInfo(ctx context.Context, send func(*pbpeering.ReplicationMessage) error) error {
+	var sent *structs.PeeringMeshInfo
+	for {
+		store := s.GetStore()
+		ws := memdb.NewWatchSet()
+		ws.Add(store.AbandonCh())
+
+		info, err := s.meshInfo(ws, store)
+		if err != nil {
+			return err
+		}
+
+		if info != nil && !reflect.DeepEqual(info, sent) {
+			resource, err := ptypes.MarshalAny(&pbpeering.MeshInfo{
+				TrustDomain:      info.TrustDomain,
+				RootPEMs:         info.RootPEMs,
+				Datacenter:       info.Datacenter,
+				GatewayAddresses: info.GatewayAddresses,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to marshal mesh info: %w", err)
+			}
+			err = send(responseMessage(TypeURLMeshInfo, info.Datacenter, pbpeering.ReplicationMessage_Response_UPSERT, resource))
+			if err != nil {
+				return fmt.Errorf("failed to send mesh info: %w", err)
+			}
+			sent = info
+		}
+
+		if err := ws.WatchCtx(ctx); err != nil {
+			return err
+		}
+	}
+}
+
+// meshInfo returns what peers need to reach the services exported to them,
+// or nil while the CA is not initialized.
+func (s *Server) meshInfo(ws memdb.WatchSet, store StateStore) (*structs.PeeringMeshInfo, error) {
+	_, roots, config, err := store.CARootsAndConfig(ws)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA roots: %w", err)
+	}
+	if config == nil || config.ClusterID == "" {
+		return nil, nil
+	}
+
+	info := &structs.PeeringMeshInfo{
+		TrustDomain: connect.SpiffeIDSigningForCluster(config.ClusterID).Host(),
+		Datacenter:  s.Datacenter,
+	}
+	for _, root := range roots {
+		info.RootPEMs = append(info.RootPEMs, root.RootCert)
+	}
+
+	_, gateways, err := store.ServiceDump(ws, structs.ServiceKindMeshGateway, true, structs.WildcardEnterpriseMetaInDefaultPartition())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list mesh gateways: %w", err)
+	}
+	for _, gw := range gateways.Filter(false) {
+		// Peers reach the gateways the same way other datacenters do.
+		_, addr, port := gw.BestAddress(true)
+		if addr == "" || port == 0 {
+			continue
+		}
+		info.GatewayAddresses = append(info.GatewayAddresses, net.JoinHostPort(addr, strconv.Itoa(port)))
+	}
+	sort.Strings(info.GatewayAddresses)
+
+	return info, nil
+}
+
+// importResource stores a resource received from the peer.
+func (s *Server) importResource(peerName string, resp *pbpeering.ReplicationMessage_Response) error {
+	req := &structs.PeeringImportRequest{PeerName: peerName}
+
+	switch resp.ResourceURL {
+	case TypeURLService:
+		if resp.ResourceID == "" {
+			return fmt.Errorf("received an exported service without a name")
+		}
+		req.ServiceName = resp.ResourceID
+
+		switch resp.Operation {
+		case pbpeering.ReplicationMessage_Response_DELETE:
+			req.Op = structs.PeeringImportDeleteService
+
+		case pbpeering.ReplicationMessage_Response_UPSERT:
+			var exported pbpeering.ExportedService
+			if err := ptypes.UnmarshalAny(resp.Resource, &exported); err != nil {
+				return fmt.Errorf("failed to unmarshal exported service %q: %w", resp.ResourceID, err)
+			}
+			req.Op = structs.PeeringImportUpsertService
+			req.Nodes = make(structs.CheckServiceNodes, 0, len(exported.Nodes))
+			for _, n := range exported.Nodes {
+				csn, err := pbservice.CheckServiceNodeToStructs(n)
+				if err != nil {
+					return fmt.Errorf("failed to convert instance of exported service %q: %w", resp.ResourceID, err)
+				}
+				req.Nodes = append(req.Nodes, *csn)
+			}
+
+		default:
+			return fmt.Errorf("unsupported operation %s for exported services", resp.Operation)
+		}
+
+	case TypeURLMeshInfo:
+		if resp.Operation != pbpeering.ReplicationMessage_Response_UPSERT {
+			return fmt.Errorf("unsupported operation %s for mesh info", resp.Operation)
+		}
+		var info pbpeering.MeshInfo
+		if err := ptypes.UnmarshalAny(resp.Resource, &info); err != nil {
+			return fmt.Errorf("failed to unmarshal mesh info: %w", err)
+		}
+		req.Op = structs.PeeringImportSetMeshInfo
+		req.MeshInfo = &structs.PeeringMeshInfo{
+			TrustDomain:      info.TrustDomain,
+			RootPEMs:         info.RootPEMs,
+			Datacenter:       info.Datacenter,
+			GatewayAddresses: info.GatewayAddresses,
+		}
+
+	default:
+		s.Logger.Warn("ignoring resource of an unknown type", "peer_name", peerName, "resource_url", resp.ResourceURL)
+		return nil
+	}
+
+	if err := s.Backend.PeeringImport(req); err != nil {
+		return fmt.Errorf("failed to import %s %q: %w", resp.ResourceURL, resp.ResourceID, err)
+	}
+	return nil
+}
+
+func responseMessage(url, id string, op pbpeering.ReplicationMessage_Response_Operation, resource *any.Any) *pbpeering.ReplicationMessage {
+	return &pbpeering.ReplicationMessage{
+		Payload: &pbpeering.ReplicationMessage_Response_{
+			Response: &pbpeering.ReplicationMessage_Response{
+				ResourceURL: url,
+				ResourceID:  id,
+				Resource:    resource,
+				Operation:   op,
+			},
+		},
+	}
+}
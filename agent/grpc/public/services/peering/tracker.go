@@ -0,0 +1,77 @@
+package peering
+
+import (
+	"sync"
+	"time"
+
+	"github.com/hashicorp/consul/agent/structs"
+)
+
+// Tracker keeps the status of the replication streams of the peerings handled
+// by this server, by peer name.
+type Tracker struct {
+	mu      sync.RWMutex
+	streams map[string]*structs.PeeringStreamStatus
+
+	// timeNow is a shim for testing.
+	timeNow func() time.Time
+}
+
+func NewTracker() *Tracker {
+	return &Tracker{
+		streams: make(map[string]*structs.PeeringStreamStatus),
+		timeNow: time.Now,
+	}
+}
+
+// StreamStatus returns a copy of the status of the stream with a peer, or nil
+// if no stream was ever opened with it.
+func (t *Tracker) StreamStatus(peerName string) *structs.PeeringStreamStatus {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	status, ok := t.streams[peerName]
+	if !ok {
+		return nil
+	}
+	cp := *status
+	return &cp
+}
+
+// Disconnected records that the stream with a peer closed, or could not be
+// opened, with the given error.
+func (t *Tracker) Disconnected(peerName string, err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	status := t.statusLocked(peerName)
+	status.Connected = false
+	if err != nil {
+		status.LastError = err.Error()
+	}
+}
+
+func (t *Tracker) connected(peerName string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	status := t.statusLocked(peerName)
+	status.Connected = true
+	status.LastError = ""
+}
+
+func (t *Tracker) received(peerName string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.statusLocked(peerName).LastReceive = t.timeNow().UTC()
+}
+
+func (t *Tracker) statusLocked(peerName string) *structs.PeeringStreamStatus {
+	status, ok := t.streams[peerName]
+	if !ok {
+		status = &structs.PeeringStreamStatus{}
+		t.streams[peerName] = status
+	}
+	return status
+}
@@ -201,6 +201,9 @@ func (s *HTTPHandlers) healthServiceNodes(resp http.ResponseWriter, req *http.Re
 		args.TagFilter = true
 	}
 
+	// Services imported from a peer are queried by peer name.
+	args.PeerName = params.Get("peer")
+
 	// Determine the prefix
 	var prefix string
 	switch healthType {
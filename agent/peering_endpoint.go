@@ -0,0 +1,122 @@
+package agent
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/hashicorp/consul/agent/structs"
+)
+
+// POST /v1/peering/token
+func (s *HTTPHandlers) PeeringGenerateToken(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
+	var args structs.PeeringGenerateTokenRequest
+	if err := decodeBody(req.Body, &args); err != nil {
+		return nil, BadRequestError{Reason: fmt.Sprintf("Request decode failed: %v", err)}
+	}
+	s.parseDC(req, &args.Datacenter)
+	s.parseToken(req, &args.Token)
+
+	if args.PeerName == "" {
+		return nil, BadRequestError{Reason: "PeerName is required in the payload when generating a peering token."}
+	}
+
+	var out structs.PeeringGenerateTokenResponse
+	if err := s.agent.RPC("Peering.GenerateToken", &args, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// POST /v1/peering/establish
+func (s *HTTPHandlers) PeeringEstablish(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
+	var args structs.PeeringEstablishRequest
+	if err := decodeBody(req.Body, &args); err != nil {
+		return nil, BadRequestError{Reason: fmt.Sprintf("Request decode failed: %v", err)}
+	}
+	s.parseDC(req, &args.Datacenter)
+	s.parseToken(req, &args.Token)
+
+	if args.PeerName == "" {
+		return nil, BadRequestError{Reason: "PeerName is required in the payload when establishing a peering."}
+	}
+	if args.PeeringToken == "" {
+		return nil, BadRequestError{Reason: "PeeringToken is required in the payload when establishing a peering."}
+	}
+
+	var out struct{}
+	if err := s.agent.RPC("Peering.Establish", &args, &out); err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+
+// GET /v1/peerings
+func (s *HTTPHandlers) PeeringList(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
+	var args structs.DCSpecificRequest
+	if done := s.parse(resp, req, &args.Datacenter, &args.QueryOptions); done {
+		return nil, nil
+	}
+
+	var out structs.IndexedPeerings
+	defer setMeta(resp, &out.QueryMeta)
+	if err := s.agent.RPC("Peering.List", &args, &out); err != nil {
+		return nil, err
+	}
+
+	// make sure we return an array and not nil
+	if out.Peerings == nil {
+		out.Peerings = make(structs.Peerings, 0)
+	}
+	return out.Peerings, nil
+}
+
+// GET, DELETE /v1/peering/<name>
+func (s *HTTPHandlers) PeeringEndpoint(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
+	name, err := getPathSuffixUnescaped(req.URL.Path, "/v1/peering/")
+	if err != nil {
+		return nil, err
+	}
+	if name == "" {
+		return nil, BadRequestError{Reason: "Must specify a peer name"}
+	}
+
+	switch req.Method {
+	case "GET":
+		return s.peeringRead(resp, req, name)
+	case "DELETE":
+		return s.peeringDelete(resp, req, name)
+	default:
+		return nil, MethodNotAllowedError{req.Method, []string{"GET", "DELETE"}}
+	}
+}
+
+func (s *HTTPHandlers) peeringRead(resp http.ResponseWriter, req *http.Request, name string) (interface{}, error) {
+	args := structs.PeeringReadRequest{PeerName: name}
+	if done := s.parse(resp, req, &args.Datacenter, &args.QueryOptions); done {
+		return nil, nil
+	}
+
+	var out structs.PeeringResponse
+	defer setMeta(resp, &out.QueryMeta)
+	if err := s.agent.RPC("Peering.Read", &args, &out); err != nil {
+		return nil, err
+	}
+
+	if out.Peering == nil {
+		resp.WriteHeader(http.StatusNotFound)
+		return nil, nil
+	}
+	return out.Peering, nil
+}
+
+func (s *HTTPHandlers) peeringDelete(resp http.ResponseWriter, req *http.Request, name string) (interface{}, error) {
+	args := structs.PeeringDeleteRequest{PeerName: name}
+	s.parseDC(req, &args.Datacenter)
+	s.parseToken(req, &args.Token)
+
+	var out struct{}
+	if err := s.agent.RPC("Peering.Delete", &args, &out); err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
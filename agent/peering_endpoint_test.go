@@ -0,0 +1,99 @@
+package agent
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hashicorp/consul/agent/structs"
+	"github.com/hashicorp/consul/testrpc"
+)
+
+func TestPeeringEndpoints(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+	// Peering tokens are only generated by servers serving gRPC over TLS.
+	a := StartTestAgent(t, TestAgent{
+		UseTLS: true,
+		HCL: `
+			ca_file = "../test/hostname/CertAuth.crt"
+			cert_file = "../test/hostname/Alice.crt"
+			key_file = "../test/hostname/Alice.key"
+		`,
+	})
+	defer a.Shutdown()
+	testrpc.WaitForTestAgent(t, a.RPC, "dc1")
+
+	do := func(method, url string, body interface{}) *httptest.ResponseRecorder {
+		t.Helper()
+		var buf bytes.Buffer
+		if body != nil {
+			require.NoError(t, json.NewEncoder(&buf).Encode(body))
+		}
+		req, err := http.NewRequest(method, url, &buf)
+		require.NoError(t, err)
+		resp := httptest.NewRecorder()
+		a.srv.h.ServeHTTP(resp, req)
+		return resp
+	}
+
+	t.Run("missing peer name", func(t *testing.T) {
+		resp := do("POST", "/v1/peering/token", map[string]interface{}{})
+		require.Equal(t, http.StatusBadRequest, resp.Code)
+
+		resp = do("POST", "/v1/peering/establish", map[string]interface{}{"PeeringToken": "abc"})
+		require.Equal(t, http.StatusBadRequest, resp.Code)
+	})
+
+	t.Run("invalid token", func(t *testing.T) {
+		resp := do("POST", "/v1/peering/establish", map[string]interface{}{
+			"PeerName":     "west",
+			"PeeringToken": "not-a-token",
+		})
+		require.Equal(t, http.StatusInternalServerError, resp.Code)
+		require.Contains(t, resp.Body.String(), "peering token is not valid base64")
+	})
+
+	resp := do("POST", "/v1/peering/token", map[string]interface{}{
+		"PeerName":                "east",
+		"Meta":                    map[string]string{"env": "production"},
+		"ServerExternalAddresses": []string{"198.18.0.1:8502"},
+	})
+	require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+
+	var tokenResp structs.PeeringGenerateTokenResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&tokenResp))
+	token, err := structs.DecodePeeringToken(tokenResp.PeeringToken)
+	require.NoError(t, err)
+	require.Equal(t, []string{"198.18.0.1:8502"}, token.ServerAddresses)
+
+	resp = do("GET", "/v1/peering/east", nil)
+	require.Equal(t, http.StatusOK, resp.Code)
+	var peering structs.Peering
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&peering))
+	require.Equal(t, token.PeerID, peering.ID)
+	require.NotEmpty(t, token.StreamSecret)
+	require.Empty(t, peering.StreamSecret)
+	require.Equal(t, structs.PeeringStatePending, peering.State)
+	require.Equal(t, map[string]string{"env": "production"}, peering.Meta)
+
+	resp = do("GET", "/v1/peerings", nil)
+	require.Equal(t, http.StatusOK, resp.Code)
+	var peerings []structs.Peering
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&peerings))
+	require.Len(t, peerings, 1)
+	require.Equal(t, "east", peerings[0].Name)
+
+	resp = do("DELETE", "/v1/peering/east", nil)
+	require.Equal(t, http.StatusOK, resp.Code)
+
+	resp = do("GET", "/v1/peering/east", nil)
+	require.Equal(t, http.StatusNotFound, resp.Code)
+}
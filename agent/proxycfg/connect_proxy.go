@@ -29,6 +29,11 @@ func (s *handlerConnectProxy) initialize(ctx context.Context) (ConfigSnapshot, e
 	snap.ConnectProxy.UpstreamConfig = make(map[UpstreamID]*structs.Upstream)
 	snap.ConnectProxy.PassthroughUpstreams = make(map[UpstreamID]map[string]map[string]struct{})
 	snap.ConnectProxy.PassthroughIndices = make(map[string]indexedTarget)
+	snap.ConnectProxy.PeerUpstreamEndpoints = make(map[UpstreamID]structs.CheckServiceNodes)
+	snap.ConnectProxy.UpstreamPeerMeshInfos = make(map[UpstreamID]*structs.PeeringMeshInfo)
+	snap.ConnectProxy.WatchedDestinations = make(map[UpstreamID]context.CancelFunc)
+	snap.ConnectProxy.DestinationsUpstream = make(map[UpstreamID]*structs.ServiceConfigEntry)
+	snap.ConnectProxy.DestinationGateways = make(map[UpstreamID]structs.CheckServiceNodes)
 
 	// Watch for root changes
 	err := s.cache.Notify(ctx, cachetype.ConnectCARootName, &structs.DCSpecificRequest{
@@ -82,6 +87,29 @@ func (s *handlerConnectProxy) initialize(ctx context.Context) (ConfigSnapshot, e
 		return snap, err
 	}
 
+	// Watch the JWT providers that intentions may refer to.
+	err = s.cache.Notify(ctx, cachetype.ConfigEntriesName, &structs.ConfigEntryQuery{
+		Kind:           structs.JWTProvider,
+		Datacenter:     s.source.Datacenter,
+		QueryOptions:   structs.QueryOptions{Token: s.token},
+		EnterpriseMeta: *structs.DefaultEnterpriseMetaInPartition(s.proxyID.PartitionOrDefault()),
+	}, jwtProvidersWatchID, s.ch)
+	if err != nil {
+		return snap, err
+	}
+
+	// Watch the cluster peers the service is exported to, their proxies
+	// present certificates signed by their own roots.
+	err = s.cache.Notify(ctx, cachetype.PeeringMeshInfoName, &structs.PeeringMeshInfoRequest{
+		Datacenter:     s.source.Datacenter,
+		QueryOptions:   structs.QueryOptions{Token: s.token},
+		ServiceName:    s.proxyCfg.DestinationServiceName,
+		EnterpriseMeta: s.proxyID.EnterpriseMeta,
+	}, exportedPeerMeshInfoWatchID, s.ch)
+	if err != nil {
+		return snap, err
+	}
+
 	// Watch for service check updates
 	err = s.cache.Notify(ctx, cachetype.ServiceHTTPChecksName, &cachetype.ServiceHTTPChecksRequest{
 		ServiceID:      s.proxyCfg.DestinationServiceID,
@@ -102,6 +130,18 @@ func (s *handlerConnectProxy) initialize(ctx context.Context) (ConfigSnapshot, e
 		if err != nil {
 			return snap, err
 		}
+
+		// External destinations this service is allowed to reach are routed
+		// through the terminating gateways linked to them.
+		err = s.cache.Notify(ctx, cachetype.IntentionUpstreamsDestinationName, &structs.ServiceSpecificRequest{
+			Datacenter:     s.source.Datacenter,
+			QueryOptions:   structs.QueryOptions{Token: s.token},
+			ServiceName:    s.proxyCfg.DestinationServiceName,
+			EnterpriseMeta: s.proxyID.EnterpriseMeta,
+		}, intentionUpstreamsDestinationID, s.ch)
+		if err != nil {
+			return snap, err
+		}
 	}
 
 	// Watch for updates to service endpoints for all upstreams
@@ -123,6 +163,16 @@ func (s *handlerConnectProxy) initialize(ctx context.Context) (ConfigSnapshot, e
 			continue
 		}
 
+		// Upstreams imported from a cluster peer are not resolved through a
+		// discovery chain, they are reached through the mesh gateways of the
+		// peer.
+		if u.DestinationPeer != "" {
+			if err := s.watchPeerUpstream(ctx, uid, &u); err != nil {
+				return snap, fmt.Errorf("failed to watch peered upstream %s: %v", uid.String(), err)
+			}
+			continue
+		}
+
 		dc := s.source.Datacenter
 		if u.Datacenter != "" {
 			dc = u.Datacenter
@@ -193,6 +243,65 @@ func (s *handlerConnectProxy) initialize(ctx context.Context) (ConfigSnapshot, e
 	return snap, nil
 }
 
+// watchPeerUpstream watches the instances of an upstream imported from a
+// cluster peer, and the mesh information of the peer.
+func (s *handlerConnectProxy) watchPeerUpstream(ctx context.Context, uid UpstreamID, u *structs.Upstream) error {
+	err := s.health.Notify(ctx, structs.ServiceSpecificRequest{
+		Datacenter:     s.source.Datacenter,
+		QueryOptions:   structs.QueryOptions{Token: s.token},
+		ServiceName:    u.DestinationName,
+		PeerName:       u.DestinationPeer,
+		Source:         *s.source,
+		EnterpriseMeta: uid.EnterpriseMeta,
+	}, peerUpstreamIDPrefix+uid.String(), s.ch)
+	if err != nil {
+		return err
+	}
+
+	return s.cache.Notify(ctx, cachetype.PeeringMeshInfoName, &structs.PeeringMeshInfoRequest{
+		Datacenter:     s.source.Datacenter,
+		QueryOptions:   structs.QueryOptions{Token: s.token},
+		PeerName:       u.DestinationPeer,
+		ServiceName:    u.DestinationName,
+		EnterpriseMeta: uid.EnterpriseMeta,
+	}, peerMeshInfoIDPrefix+uid.String(), s.ch)
+}
+
+// watchDestination watches the service-defaults entry declaring an external
+// destination, and the terminating gateways the destination is linked to.
+func (s *handlerConnectProxy) watchDestination(ctx context.Context, snap *ConfigSnapshot, uid UpstreamID, svc structs.ServiceName) error {
+	ctx, cancel := context.WithCancel(ctx)
+
+	err := s.cache.Notify(ctx, cachetype.ConfigEntryName, &structs.ConfigEntryQuery{
+		Kind:           structs.ServiceDefaults,
+		Name:           svc.Name,
+		Datacenter:     s.source.Datacenter,
+		QueryOptions:   structs.QueryOptions{Token: s.token},
+		EnterpriseMeta: svc.EnterpriseMeta,
+	}, destinationConfigEntryIDPrefix+uid.String(), s.ch)
+	if err != nil {
+		cancel()
+		return err
+	}
+
+	// A Connect query for the destination returns the terminating gateways
+	// linked to it, since the destination has no instances of its own.
+	err = s.health.Notify(ctx, structs.ServiceSpecificRequest{
+		Datacenter:     s.source.Datacenter,
+		QueryOptions:   structs.QueryOptions{Token: s.token},
+		ServiceName:    svc.Name,
+		Connect:        true,
+		EnterpriseMeta: svc.EnterpriseMeta,
+	}, destinationGatewayIDPrefix+uid.String(), s.ch)
+	if err != nil {
+		cancel()
+		return err
+	}
+
+	snap.ConnectProxy.WatchedDestinations[uid] = cancel
+	return nil
+}
+
 func (s *handlerConnectProxy) handleUpdate(ctx context.Context, u cache.UpdateEvent, snap *ConfigSnapshot) error {
 	if u.Err != nil {
 		return fmt.Errorf("error filling agent cache: %v", u.Err)
@@ -218,6 +327,13 @@ func (s *handlerConnectProxy) handleUpdate(ctx context.Context, u cache.UpdateEv
 		}
 		snap.ConnectProxy.IntentionsSet = true
 
+	case u.CorrelationID == jwtProvidersWatchID:
+		providers, err := jwtProvidersFromResult(u.Result)
+		if err != nil {
+			return err
+		}
+		snap.ConnectProxy.JWTProviders = providers
+
 	case u.CorrelationID == intentionUpstreamsID:
 		resp, ok := u.Result.(*structs.IndexedServiceList)
 		if !ok {
@@ -361,6 +477,99 @@ func (s *handlerConnectProxy) handleUpdate(ctx context.Context, u cache.UpdateEv
 		uid := UpstreamIDFromString(pq)
 		snap.ConnectProxy.PreparedQueryEndpoints[uid] = resp.Nodes
 
+	case u.CorrelationID == intentionUpstreamsDestinationID:
+		resp, ok := u.Result.(*structs.IndexedServiceList)
+		if !ok {
+			return fmt.Errorf("invalid type for response %T", u.Result)
+		}
+
+		seenDestinations := make(map[UpstreamID]struct{})
+		for _, svc := range resp.Services {
+			uid := NewUpstreamIDFromServiceName(svc)
+			seenDestinations[uid] = struct{}{}
+
+			if _, ok := snap.ConnectProxy.WatchedDestinations[uid]; ok {
+				continue
+			}
+			if err := s.watchDestination(ctx, snap, uid, svc); err != nil {
+				return fmt.Errorf("failed to watch destination %s: %v", uid, err)
+			}
+		}
+
+		// Clean up data from destinations that were not in the update
+		for uid, cancelFn := range snap.ConnectProxy.WatchedDestinations {
+			if _, ok := seenDestinations[uid]; !ok {
+				cancelFn()
+				delete(snap.ConnectProxy.WatchedDestinations, uid)
+			}
+		}
+		for uid := range snap.ConnectProxy.DestinationsUpstream {
+			if _, ok := seenDestinations[uid]; !ok {
+				delete(snap.ConnectProxy.DestinationsUpstream, uid)
+			}
+		}
+		for uid := range snap.ConnectProxy.DestinationGateways {
+			if _, ok := seenDestinations[uid]; !ok {
+				delete(snap.ConnectProxy.DestinationGateways, uid)
+			}
+		}
+
+	case strings.HasPrefix(u.CorrelationID, destinationConfigEntryIDPrefix):
+		resp, ok := u.Result.(*structs.ConfigEntryResponse)
+		if !ok {
+			return fmt.Errorf("invalid type for response: %T", u.Result)
+		}
+		uid := UpstreamIDFromString(strings.TrimPrefix(u.CorrelationID, destinationConfigEntryIDPrefix))
+		if _, ok := snap.ConnectProxy.WatchedDestinations[uid]; !ok {
+			// The destination is no longer allowed, ignore late updates.
+			return nil
+		}
+
+		svcConf, ok := resp.Entry.(*structs.ServiceConfigEntry)
+		if !ok || svcConf.Destination == nil {
+			delete(snap.ConnectProxy.DestinationsUpstream, uid)
+			return nil
+		}
+		snap.ConnectProxy.DestinationsUpstream[uid] = svcConf
+
+	case strings.HasPrefix(u.CorrelationID, destinationGatewayIDPrefix):
+		resp, ok := u.Result.(*structs.IndexedCheckServiceNodes)
+		if !ok {
+			return fmt.Errorf("invalid type for response: %T", u.Result)
+		}
+		uid := UpstreamIDFromString(strings.TrimPrefix(u.CorrelationID, destinationGatewayIDPrefix))
+		if _, ok := snap.ConnectProxy.WatchedDestinations[uid]; !ok {
+			return nil
+		}
+		snap.ConnectProxy.DestinationGateways[uid] = resp.Nodes
+
+	case u.CorrelationID == exportedPeerMeshInfoWatchID:
+		resp, ok := u.Result.(*structs.IndexedPeeringMeshInfos)
+		if !ok {
+			return fmt.Errorf("invalid type for response: %T", u.Result)
+		}
+		snap.ConnectProxy.ExportedPeerMeshInfos = resp.MeshInfos
+
+	case strings.HasPrefix(u.CorrelationID, peerUpstreamIDPrefix):
+		resp, ok := u.Result.(*structs.IndexedCheckServiceNodes)
+		if !ok {
+			return fmt.Errorf("invalid type for response: %T", u.Result)
+		}
+		uid := UpstreamIDFromString(strings.TrimPrefix(u.CorrelationID, peerUpstreamIDPrefix))
+		snap.ConnectProxy.PeerUpstreamEndpoints[uid] = resp.Nodes
+
+	case strings.HasPrefix(u.CorrelationID, peerMeshInfoIDPrefix):
+		resp, ok := u.Result.(*structs.IndexedPeeringMeshInfos)
+		if !ok {
+			return fmt.Errorf("invalid type for response: %T", u.Result)
+		}
+		uid := UpstreamIDFromString(strings.TrimPrefix(u.CorrelationID, peerMeshInfoIDPrefix))
+		if info, ok := resp.MeshInfos[uid.Peer]; ok {
+			snap.ConnectProxy.UpstreamPeerMeshInfos[uid] = info
+		} else {
+			delete(snap.ConnectProxy.UpstreamPeerMeshInfos, uid)
+		}
+
 	case strings.HasPrefix(u.CorrelationID, svcChecksWatchIDPrefix):
 		resp, ok := u.Result.([]structs.CheckType)
 		if !ok {
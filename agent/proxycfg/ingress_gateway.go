@@ -49,6 +49,17 @@ func (s *handlerIngressGateway) initialize(ctx context.Context) (ConfigSnapshot,
 		return snap, err
 	}
 
+	// Watch the JWT providers that ingress services may require tokens from.
+	err = s.cache.Notify(ctx, cachetype.ConfigEntriesName, &structs.ConfigEntryQuery{
+		Kind:           structs.JWTProvider,
+		Datacenter:     s.source.Datacenter,
+		QueryOptions:   structs.QueryOptions{Token: s.token},
+		EnterpriseMeta: *structs.DefaultEnterpriseMetaInPartition(s.proxyID.PartitionOrDefault()),
+	}, jwtProvidersWatchID, s.ch)
+	if err != nil {
+		return snap, err
+	}
+
 	// Watch the ingress-gateway's list of upstreams
 	err = s.cache.Notify(ctx, cachetype.GatewayServicesName, &structs.ServiceSpecificRequest{
 		Datacenter:     s.source.Datacenter,
@@ -106,6 +117,13 @@ func (s *handlerIngressGateway) handleUpdate(ctx context.Context, u cache.Update
 			return err
 		}
 
+	case u.CorrelationID == jwtProvidersWatchID:
+		providers, err := jwtProvidersFromResult(u.Result)
+		if err != nil {
+			return err
+		}
+		snap.IngressGateway.JWTProviders = providers
+
 	case u.CorrelationID == gatewayServicesWatchID:
 		services, ok := u.Result.(*structs.IndexedGatewayServices)
 		if !ok {
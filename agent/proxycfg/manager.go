@@ -82,6 +82,10 @@ type ManagerConfig struct {
 	// information to proxies that need to make intention decisions on their
 	// own.
 	IntentionDefaultAllow bool
+
+	// NodeMeta is the agent's node meta. It provides the locality of proxies
+	// whose service meta doesn't set one.
+	NodeMeta map[string]string
 }
 
 // NewManager constructs a manager from the provided agent cache.
@@ -209,6 +213,7 @@ func (m *Manager) ensureProxyServiceLocked(ns *structs.NodeService, notifyBroadc
 		source:                m.Source,
 		dnsConfig:             m.DNSConfig,
 		intentionDefaultAllow: m.IntentionDefaultAllow,
+		nodeMeta:              m.NodeMeta,
 	}
 	if m.TLSConfigurator != nil {
 		stateConfig.serverSNIFn = m.TLSConfigurator.ServerSNI
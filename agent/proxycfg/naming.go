@@ -11,6 +11,9 @@ type UpstreamID struct {
 	Type       string
 	Name       string
 	Datacenter string
+
+	// Peer is the name of the cluster peer the upstream is imported from.
+	Peer string
 	acl.EnterpriseMeta
 }
 
@@ -19,6 +22,7 @@ func NewUpstreamID(u *structs.Upstream) UpstreamID {
 		Type:       u.DestinationType,
 		Name:       u.DestinationName,
 		Datacenter: u.Datacenter,
+		Peer:       u.DestinationPeer,
 		EnterpriseMeta: acl.NewEnterpriseMetaWithPartition(
 			u.DestinationPartition,
 			u.DestinationNamespace,
@@ -76,7 +80,11 @@ func (u *UpstreamID) normalize() {
 // String encodes the UpstreamID into a string for use in agent cache keys.
 // You can decode it back again using UpstreamIDFromString.
 func (u UpstreamID) String() string {
-	return UpstreamIDString(u.Type, u.Datacenter, u.Name, &u.EnterpriseMeta)
+	ret := UpstreamIDString(u.Type, u.Datacenter, u.Name, &u.EnterpriseMeta)
+	if u.Peer != "" {
+		ret += upstreamPeerSeparator + u.Peer
+	}
+	return ret
 }
 
 func (u UpstreamID) GoString() string {
@@ -84,10 +92,17 @@ func (u UpstreamID) GoString() string {
 }
 
 func UpstreamIDFromString(input string) UpstreamID {
+	var peer string
+	if idx := strings.LastIndex(input, upstreamPeerSeparator); idx != -1 {
+		peer = input[idx+len(upstreamPeerSeparator):]
+		input = input[:idx]
+	}
+
 	typ, dc, name, entMeta := ParseUpstreamIDString(input)
 	id := UpstreamID{
 		Type:           typ,
 		Datacenter:     dc,
+		Peer:           peer,
 		Name:           name,
 		EnterpriseMeta: *entMeta,
 	}
@@ -97,6 +112,10 @@ func UpstreamIDFromString(input string) UpstreamID {
 
 const upstreamTypePreparedQueryPrefix = structs.UpstreamDestTypePreparedQuery + ":"
 
+// upstreamPeerSeparator is appended to the string form of upstreams imported
+// from a cluster peer, followed by the peer name.
+const upstreamPeerSeparator = "?peer="
+
 func ParseUpstreamIDString(input string) (typ, dc, name string, meta *acl.EnterpriseMeta) {
 	if strings.HasPrefix(input, upstreamTypePreparedQueryPrefix) {
 		typ = structs.UpstreamDestTypePreparedQuery
@@ -129,6 +148,9 @@ func (u UpstreamID) EnvoyID() string {
 	if u.Datacenter != "" {
 		name += "?dc=" + u.Datacenter
 	}
+	if u.Peer != "" {
+		name += upstreamPeerSeparator + u.Peer
+	}
 
 	// Service is default type so never prefix it. This is more readable and long
 	// term it is the only type that matters so we can drop the prefix and have
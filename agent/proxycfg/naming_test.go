@@ -91,6 +91,13 @@ func TestUpstreamIDFromString(t *testing.T) {
 				Datacenter: "dc2",
 			},
 		},
+		"normal peer": {
+			prefix + "foo?peer=east",
+			UpstreamID{
+				Name: "foo",
+				Peer: "east",
+			},
+		},
 	}
 
 	for name, tc := range cases {
@@ -144,6 +151,13 @@ func TestUpstreamID_String(t *testing.T) {
 			},
 			prefix + "foo?dc=dc2",
 		},
+		"normal implicit peer": {
+			UpstreamID{
+				Name: "foo",
+				Peer: "east",
+			},
+			prefix + "foo?peer=east",
+		},
 		"normal explicit": {
 			UpstreamID{
 				Type: structs.UpstreamDestTypeService,
@@ -207,6 +221,13 @@ func TestUpstreamID_EnvoyID(t *testing.T) {
 			},
 			"foo?dc=dc2",
 		},
+		"normal implicit peer": {
+			UpstreamID{
+				Name: "foo",
+				Peer: "east",
+			},
+			"foo?peer=east",
+		},
 		"normal explicit": {
 			UpstreamID{
 				Type: structs.UpstreamDestTypeService,
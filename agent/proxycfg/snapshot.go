@@ -120,6 +120,40 @@ type configSnapshotConnectProxy struct {
 	// intentions.
 	Intentions    structs.Intentions
 	IntentionsSet bool
+
+	// JWTProviders are the jwt-provider config entries of the proxy's
+	// partition, keyed by name. Intentions refer to them to require end-user
+	// tokens.
+	JWTProviders map[string]*structs.JWTProviderConfigEntry
+
+	// PeerUpstreamEndpoints is a map of UpstreamID -> CheckServiceNodes for
+	// the upstreams imported from a cluster peer. The instances are not
+	// dialed directly, they only tell whether the upstream is healthy.
+	PeerUpstreamEndpoints map[UpstreamID]structs.CheckServiceNodes
+
+	// UpstreamPeerMeshInfos is a map of UpstreamID -> mesh information of the
+	// cluster peer the upstream is imported from. It has the mesh gateways
+	// the upstream is reached through, and the roots it is verified with.
+	UpstreamPeerMeshInfos map[UpstreamID]*structs.PeeringMeshInfo
+
+	// ExportedPeerMeshInfos is a map of peer name -> mesh information of the
+	// cluster peers the service is exported to. Their roots are trusted by
+	// the public listener.
+	ExportedPeerMeshInfos map[string]*structs.PeeringMeshInfo
+
+	// WatchedDestinations is a map of UpstreamID -> cancel function for the
+	// watches of the external destinations the proxy is allowed to reach by
+	// intentions. Only populated in transparent proxy mode.
+	WatchedDestinations map[UpstreamID]context.CancelFunc
+
+	// DestinationsUpstream is a map of UpstreamID -> service-defaults config
+	// entry declaring the external destination.
+	DestinationsUpstream map[UpstreamID]*structs.ServiceConfigEntry
+
+	// DestinationGateways is a map of UpstreamID -> terminating gateway
+	// instances linked to the destination. Traffic to the destination is
+	// sent through them.
+	DestinationGateways map[UpstreamID]structs.CheckServiceNodes
 }
 
 // isEmpty is a test helper
@@ -140,6 +174,13 @@ func (c *configSnapshotConnectProxy) isEmpty() bool {
 		len(c.UpstreamConfig) == 0 &&
 		len(c.PassthroughUpstreams) == 0 &&
 		len(c.IntentionUpstreams) == 0 &&
+		len(c.JWTProviders) == 0 &&
+		len(c.PeerUpstreamEndpoints) == 0 &&
+		len(c.UpstreamPeerMeshInfos) == 0 &&
+		len(c.ExportedPeerMeshInfos) == 0 &&
+		len(c.WatchedDestinations) == 0 &&
+		len(c.DestinationsUpstream) == 0 &&
+		len(c.DestinationGateways) == 0 &&
 		!c.MeshConfigSet
 }
 
@@ -236,7 +277,14 @@ func (c *configSnapshotTerminatingGateway) ValidServices() []structs.ServiceName
 
 		// Skip the service if we haven't gotten our service config yet to know
 		// the protocol.
-		if _, ok := c.ServiceConfigs[svc]; !ok {
+		svcConfig, ok := c.ServiceConfigs[svc]
+		if !ok {
+			continue
+		}
+
+		// Services declaring an external destination are handled by
+		// ValidDestinations, even if they have instances in the catalog.
+		if len(svcConfig.Destination.Addresses) > 0 {
 			continue
 		}
 
@@ -245,6 +293,34 @@ func (c *configSnapshotTerminatingGateway) ValidServices() []structs.ServiceName
 	return out
 }
 
+// ValidDestinations returns the list of linked services declaring an external
+// destination that have enough data to be emitted, sorted by name.
+func (c *configSnapshotTerminatingGateway) ValidDestinations() []structs.ServiceName {
+	out := make([]structs.ServiceName, 0)
+	for svc := range c.GatewayServices {
+		svcConfig, ok := c.ServiceConfigs[svc]
+		if !ok || len(svcConfig.Destination.Addresses) == 0 {
+			continue
+		}
+
+		// Skip the destination if we don't have a cert to present for mTLS.
+		if cert, ok := c.ServiceLeaves[svc]; !ok || cert == nil {
+			continue
+		}
+
+		// Skip the destination if we haven't gotten our intentions yet.
+		if _, intentionsSet := c.Intentions[svc]; !intentionsSet {
+			continue
+		}
+
+		out = append(out, svc)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].String() < out[j].String()
+	})
+	return out
+}
+
 // isEmpty is a test helper
 func (c *configSnapshotTerminatingGateway) isEmpty() bool {
 	if c == nil {
@@ -387,6 +463,11 @@ type configSnapshotIngressGateway struct {
 	// Listeners is the original listener config from the ingress-gateway config
 	// entry to save us trying to pass fields through Upstreams
 	Listeners map[IngressListenerKey]structs.IngressListener
+
+	// JWTProviders are the jwt-provider config entries of the gateway's
+	// partition, keyed by name. Ingress services requiring a JWT refer to
+	// them.
+	JWTProviders map[string]*structs.JWTProviderConfigEntry
 }
 
 // isEmpty is a test helper
@@ -435,6 +516,10 @@ type ConfigSnapshot struct {
 	IntentionDefaultAllow bool
 	Locality              GatewayKey
 
+	// ServiceLocality is the region and zone of the proxy within its
+	// datacenter, if any.
+	ServiceLocality *structs.Locality
+
 	ServerSNIFn ServerSNIFunc
 	Roots       *structs.IndexedCARoots
 
@@ -502,6 +587,7 @@ func (s *ConfigSnapshot) Clone() (*ConfigSnapshot, error) {
 	case structs.ServiceKindConnectProxy:
 		snap.ConnectProxy.WatchedUpstreams = nil
 		snap.ConnectProxy.WatchedGateways = nil
+		snap.ConnectProxy.WatchedDestinations = nil
 	case structs.ServiceKindTerminatingGateway:
 		snap.TerminatingGateway.WatchedServices = nil
 		snap.TerminatingGateway.WatchedIntentions = nil
@@ -44,7 +44,14 @@ const (
 	serviceResolverIDPrefix            = "service-resolver:"
 	serviceIntentionsIDPrefix          = "service-intentions:"
 	intentionUpstreamsID               = "intention-upstreams"
+	intentionUpstreamsDestinationID    = "intention-upstreams-destination"
+	destinationConfigEntryIDPrefix     = "destination:"
+	destinationGatewayIDPrefix         = "destination-gateway:"
 	meshConfigEntryID                  = "mesh"
+	jwtProvidersWatchID                = "jwt-providers"
+	exportedPeerMeshInfoWatchID        = "exported-peer-mesh-info"
+	peerUpstreamIDPrefix               = "peer-upstream:"
+	peerMeshInfoIDPrefix               = "peer-mesh-info:"
 	svcChecksWatchIDPrefix             = cachetype.ServiceHTTPChecksName + ":"
 	preparedQueryIDPrefix              = string(structs.UpstreamDestTypePreparedQuery) + ":"
 	defaultPreparedQueryPollInterval   = 30 * time.Second
@@ -58,6 +65,7 @@ type stateConfig struct {
 	dnsConfig             DNSConfig
 	serverSNIFn           ServerSNIFunc
 	intentionDefaultAllow bool
+	nodeMeta              map[string]string
 }
 
 // state holds all the state needed to maintain the config for a registered
@@ -269,6 +277,7 @@ func newConfigSnapshotFromServiceInstance(s serviceInstance, config stateConfig)
 		Proxy:                 s.proxyCfg,
 		Datacenter:            config.source.Datacenter,
 		Locality:              GatewayKey{Datacenter: config.source.Datacenter, Partition: s.proxyID.PartitionOrDefault()},
+		ServiceLocality:       structs.LocalityFromMeta(s.meta, config.nodeMeta),
 		ServerSNIFn:           config.serverSNIFn,
 		IntentionDefaultAllow: config.intentionDefaultAllow,
 	}
@@ -459,3 +468,22 @@ func watchMeshGateway(ctx context.Context, opts gatewayWatchOpts) error {
 		EnterpriseMeta: *structs.DefaultEnterpriseMetaInPartition(opts.key.Partition),
 	}, fmt.Sprintf("mesh-gateway:%s:%s", opts.key.String(), opts.upstreamID.String()), opts.notifyCh)
 }
+
+// jwtProvidersFromResult indexes the jwt-provider config entries returned by a
+// jwtProvidersWatchID watch by name.
+func jwtProvidersFromResult(result interface{}) (map[string]*structs.JWTProviderConfigEntry, error) {
+	resp, ok := result.(*structs.IndexedConfigEntries)
+	if !ok {
+		return nil, fmt.Errorf("invalid type for response: %T", result)
+	}
+
+	providers := make(map[string]*structs.JWTProviderConfigEntry, len(resp.Entries))
+	for _, entry := range resp.Entries {
+		p, ok := entry.(*structs.JWTProviderConfigEntry)
+		if !ok {
+			return nil, fmt.Errorf("invalid type for config entry: %T", entry)
+		}
+		providers[p.Name] = p
+	}
+	return providers, nil
+}
@@ -336,6 +336,31 @@ func genVerifyGatewayServiceWatch(expectedService, expectedDatacenter string) ve
 	return genVerifyServiceSpecificRequest(cachetype.GatewayServicesName, expectedService, "", expectedDatacenter, false)
 }
 
+func genVerifyPeerServiceWatch(expectedService, expectedPeer, expectedDatacenter string) verifyWatchRequest {
+	return func(t testing.TB, cacheType string, request cache.Request) {
+		require.Equal(t, cachetype.HealthServicesName, cacheType)
+
+		reqReal, ok := request.(*structs.ServiceSpecificRequest)
+		require.True(t, ok)
+		require.Equal(t, expectedDatacenter, reqReal.Datacenter)
+		require.Equal(t, expectedService, reqReal.ServiceName)
+		require.Equal(t, expectedPeer, reqReal.PeerName)
+		require.False(t, reqReal.Connect)
+	}
+}
+
+func genVerifyPeeringMeshInfoWatch(expectedService, expectedPeer, expectedDatacenter string) verifyWatchRequest {
+	return func(t testing.TB, cacheType string, request cache.Request) {
+		require.Equal(t, cachetype.PeeringMeshInfoName, cacheType)
+
+		reqReal, ok := request.(*structs.PeeringMeshInfoRequest)
+		require.True(t, ok)
+		require.Equal(t, expectedDatacenter, reqReal.Datacenter)
+		require.Equal(t, expectedService, reqReal.ServiceName)
+		require.Equal(t, expectedPeer, reqReal.PeerName)
+	}
+}
+
 func genVerifyConfigEntryWatch(expectedKind, expectedName, expectedDatacenter string) verifyWatchRequest {
 	return func(t testing.TB, cacheType string, request cache.Request) {
 		require.Equal(t, cachetype.ConfigEntryName, cacheType)
@@ -2493,6 +2518,207 @@ func TestState_WatchesAndUpdates(t *testing.T) {
 				},
 			},
 		},
+		"connect-proxy-peered-upstream": {
+			ns: structs.NodeService{
+				Kind:    structs.ServiceKindConnectProxy,
+				ID:      "web-sidecar-proxy",
+				Service: "web-sidecar-proxy",
+				Address: "10.0.1.1",
+				Port:    443,
+				Proxy: structs.ConnectProxyConfig{
+					DestinationServiceName: "web",
+					Upstreams: structs.Upstreams{
+						{
+							DestinationName: "api",
+							DestinationPeer: "cluster-02",
+							LocalBindPort:   10001,
+						},
+					},
+				},
+			},
+			sourceDC: "dc1",
+			stages: []verificationStage{
+				{
+					requiredWatches: map[string]verifyWatchRequest{
+						rootsWatchID:                         genVerifyRootsWatch("dc1"),
+						leafWatchID:                          genVerifyLeafWatch("web", "dc1"),
+						intentionsWatchID:                    genVerifyIntentionWatch("web", "dc1"),
+						meshConfigEntryID:                    genVerifyMeshConfigWatch("dc1"),
+						exportedPeerMeshInfoWatchID:          genVerifyPeeringMeshInfoWatch("web", "", "dc1"),
+						"peer-upstream:api?peer=cluster-02":  genVerifyPeerServiceWatch("api", "cluster-02", "dc1"),
+						"peer-mesh-info:api?peer=cluster-02": genVerifyPeeringMeshInfoWatch("api", "cluster-02", "dc1"),
+					},
+					events: []cache.UpdateEvent{
+						rootWatchEvent(),
+						{
+							CorrelationID: leafWatchID,
+							Result:        issuedCert,
+						},
+						{
+							CorrelationID: intentionsWatchID,
+							Result:        TestIntentions(),
+						},
+						{
+							CorrelationID: meshConfigEntryID,
+							Result:        &structs.ConfigEntryResponse{},
+						},
+						{
+							CorrelationID: exportedPeerMeshInfoWatchID,
+							Result: &structs.IndexedPeeringMeshInfos{
+								MeshInfos: map[string]*structs.PeeringMeshInfo{
+									"cluster-03": {TrustDomain: "33333333.consul", Datacenter: "dc3", RootPEMs: []string{"ROOT3"}},
+								},
+							},
+						},
+						{
+							CorrelationID: "peer-upstream:api?peer=cluster-02",
+							Result: &structs.IndexedCheckServiceNodes{
+								Nodes: structs.CheckServiceNodes{
+									{
+										Node:    &structs.Node{Node: "node1", Address: "10.10.1.1"},
+										Service: &structs.NodeService{Service: "api", Port: 8080},
+									},
+								},
+							},
+						},
+						{
+							CorrelationID: "peer-mesh-info:api?peer=cluster-02",
+							Result: &structs.IndexedPeeringMeshInfos{
+								MeshInfos: map[string]*structs.PeeringMeshInfo{
+									"cluster-02": {
+										TrustDomain:      "22222222.consul",
+										Datacenter:       "dc2",
+										RootPEMs:         []string{"ROOT2"},
+										GatewayAddresses: []string{"198.18.0.2:443"},
+									},
+								},
+							},
+						},
+					},
+					verifySnapshot: func(t testing.TB, snap *ConfigSnapshot) {
+						require.True(t, snap.Valid())
+						require.False(t, snap.ConnectProxy.isEmpty())
+
+						uid := UpstreamIDFromString("api?peer=cluster-02")
+						require.Equal(t, "cluster-02", uid.Peer)
+						require.Len(t, snap.ConnectProxy.PeerUpstreamEndpoints[uid], 1)
+						require.Equal(t, "22222222.consul", snap.ConnectProxy.UpstreamPeerMeshInfos[uid].TrustDomain)
+						require.Contains(t, snap.ConnectProxy.ExportedPeerMeshInfos, "cluster-03")
+
+						// peered upstreams are not resolved through a discovery chain
+						require.Empty(t, snap.ConnectProxy.DiscoveryChain)
+						require.Empty(t, snap.ConnectProxy.WatchedUpstreams)
+					},
+				},
+				{
+					events: []cache.UpdateEvent{
+						{
+							CorrelationID: "peer-mesh-info:api?peer=cluster-02",
+							Result:        &structs.IndexedPeeringMeshInfos{},
+						},
+					},
+					verifySnapshot: func(t testing.TB, snap *ConfigSnapshot) {
+						require.Empty(t, snap.ConnectProxy.UpstreamPeerMeshInfos)
+					},
+				},
+			},
+		},
+		"transparent-proxy-destination": {
+			ns: structs.NodeService{
+				Kind:    structs.ServiceKindConnectProxy,
+				ID:      "api-proxy",
+				Service: "api-proxy",
+				Address: "10.0.1.1",
+				Proxy: structs.ConnectProxyConfig{
+					DestinationServiceName: "api",
+					Mode:                   structs.ProxyModeTransparent,
+				},
+			},
+			sourceDC: "dc1",
+			stages: []verificationStage{
+				{
+					requiredWatches: map[string]verifyWatchRequest{
+						intentionUpstreamsDestinationID: genVerifyServiceSpecificRequest(cachetype.IntentionUpstreamsDestinationName,
+							"api", "", "dc1", false),
+					},
+					events: []cache.UpdateEvent{
+						{
+							CorrelationID: intentionUpstreamsDestinationID,
+							Result: &structs.IndexedServiceList{
+								Services: structs.ServiceList{db},
+							},
+							Err: nil,
+						},
+					},
+					verifySnapshot: func(t testing.TB, snap *ConfigSnapshot) {
+						require.Contains(t, snap.ConnectProxy.WatchedDestinations, dbUID)
+						require.Empty(t, snap.ConnectProxy.DestinationsUpstream)
+						require.Empty(t, snap.ConnectProxy.DestinationGateways)
+					},
+				},
+				{
+					requiredWatches: map[string]verifyWatchRequest{
+						destinationConfigEntryIDPrefix + dbUID.String(): genVerifyConfigEntryWatch(structs.ServiceDefaults, "db", "dc1"),
+						destinationGatewayIDPrefix + dbUID.String():     genVerifyServiceWatch("db", "", "dc1", true),
+					},
+					events: []cache.UpdateEvent{
+						{
+							CorrelationID: destinationConfigEntryIDPrefix + dbUID.String(),
+							Result: &structs.ConfigEntryResponse{
+								Entry: &structs.ServiceConfigEntry{
+									Kind: structs.ServiceDefaults,
+									Name: "db",
+									Destination: &structs.DestinationConfig{
+										Addresses: []string{"db.example.com"},
+										Port:      5432,
+									},
+								},
+							},
+							Err: nil,
+						},
+						{
+							CorrelationID: destinationGatewayIDPrefix + dbUID.String(),
+							Result: &structs.IndexedCheckServiceNodes{
+								Nodes: structs.CheckServiceNodes{
+									{
+										Node: &structs.Node{Node: "gw-node", Address: "10.0.2.2"},
+										Service: &structs.NodeService{
+											Kind:    structs.ServiceKindTerminatingGateway,
+											ID:      "terminating-gateway",
+											Service: "terminating-gateway",
+											Port:    8443,
+										},
+									},
+								},
+							},
+							Err: nil,
+						},
+					},
+					verifySnapshot: func(t testing.TB, snap *ConfigSnapshot) {
+						require.Contains(t, snap.ConnectProxy.DestinationsUpstream, dbUID)
+						require.Equal(t, 5432, snap.ConnectProxy.DestinationsUpstream[dbUID].Destination.Port)
+						require.Len(t, snap.ConnectProxy.DestinationGateways[dbUID], 1)
+					},
+				},
+				{
+					// Losing the intention to the destination cleans up its watches
+					events: []cache.UpdateEvent{
+						{
+							CorrelationID: intentionUpstreamsDestinationID,
+							Result: &structs.IndexedServiceList{
+								Services: structs.ServiceList{},
+							},
+							Err: nil,
+						},
+					},
+					verifySnapshot: func(t testing.TB, snap *ConfigSnapshot) {
+						require.Empty(t, snap.ConnectProxy.WatchedDestinations)
+						require.Empty(t, snap.ConnectProxy.DestinationsUpstream)
+						require.Empty(t, snap.ConnectProxy.DestinationGateways)
+					},
+				},
+			},
+		},
 		"connect-proxy":                    newConnectProxyCase(structs.MeshGatewayModeDefault),
 		"connect-proxy-mesh-gateway-local": newConnectProxyCase(structs.MeshGatewayModeLocal),
 	}
@@ -33,6 +33,8 @@ type TestCacheTypes struct {
 	compiledChain     *ControllableCacheType
 	serviceHTTPChecks *ControllableCacheType
 	configEntry       *ControllableCacheType
+	configEntries     *ControllableCacheType
+	peeringMeshInfo   *ControllableCacheType
 }
 
 // NewTestCacheTypes creates a set of ControllableCacheTypes for all types that
@@ -48,6 +50,8 @@ func NewTestCacheTypes(t testing.T) *TestCacheTypes {
 		compiledChain:     NewControllableCacheType(t),
 		serviceHTTPChecks: NewControllableCacheType(t),
 		configEntry:       NewControllableCacheType(t),
+		configEntries:     NewControllableCacheType(t),
+		peeringMeshInfo:   NewControllableCacheType(t),
 	}
 	ct.query.blocking = false
 	return ct
@@ -65,6 +69,8 @@ func TestCacheWithTypes(t testing.T, types *TestCacheTypes) *cache.Cache {
 	c.RegisterType(cachetype.CompiledDiscoveryChainName, types.compiledChain)
 	c.RegisterType(cachetype.ServiceHTTPChecksName, types.serviceHTTPChecks)
 	c.RegisterType(cachetype.ConfigEntryName, types.configEntry)
+	c.RegisterType(cachetype.ConfigEntriesName, types.configEntries)
+	c.RegisterType(cachetype.PeeringMeshInfoName, types.peeringMeshInfo)
 
 	return c
 }
@@ -148,6 +154,37 @@ func TestUpstreamNodes(t testing.T, service string) structs.CheckServiceNodes {
 	}
 }
 
+// TestUpstreamNodesWithLocality returns instances of a service spread across
+// two zones of one region and a zone of another region.
+func TestUpstreamNodesWithLocality(t testing.T, service string) structs.CheckServiceNodes {
+	nodes := TestUpstreamNodes(t, service)
+	nodes[0].Node.Meta = map[string]string{
+		structs.MetaKeyLocalityRegion: "us-east-1",
+		structs.MetaKeyLocalityZone:   "us-east-1a",
+	}
+	nodes[1].Node.Meta = map[string]string{
+		structs.MetaKeyLocalityRegion: "us-east-1",
+		structs.MetaKeyLocalityZone:   "us-east-1b",
+	}
+
+	// The locality of this one comes from its service meta.
+	svc := structs.TestNodeServiceWithName(t, service)
+	svc.Meta = map[string]string{
+		structs.MetaKeyLocalityRegion: "us-west-2",
+		structs.MetaKeyLocalityZone:   "us-west-2a",
+	}
+	return append(nodes, structs.CheckServiceNode{
+		Node: &structs.Node{
+			ID:         "test3",
+			Node:       "test3",
+			Address:    "10.10.1.3",
+			Datacenter: "dc1",
+			Partition:  structs.NodeEnterpriseMetaInDefaultPartition().PartitionOrEmpty(),
+		},
+		Service: svc,
+	})
+}
+
 // TestPreparedQueryNodes returns instances of a service spread across two datacenters.
 // The service instance names use a "-target" suffix to ensure we don't use the
 // prepared query's name for SAN validation.
@@ -275,3 +275,50 @@ func TestConfigSnapshotGRPCExposeHTTP1(t testing.T) *ConfigSnapshot {
 		},
 	})
 }
+
+// TestConfigSnapshotPeering returns a snapshot of a proxy with an upstream
+// imported from the cluster peer "cluster-02", and a service exported to the
+// cluster peer "cluster-03".
+func TestConfigSnapshotPeering(t testing.T) *ConfigSnapshot {
+	paymentsUID := UpstreamIDFromString("payments?peer=cluster-02")
+
+	return TestConfigSnapshot(t, func(ns *structs.NodeService) {
+		ns.Proxy.Upstreams = append(ns.Proxy.Upstreams, structs.Upstream{
+			DestinationName: "payments",
+			DestinationPeer: "cluster-02",
+			LocalBindPort:   9090,
+		})
+	}, []cache.UpdateEvent{
+		{
+			CorrelationID: exportedPeerMeshInfoWatchID,
+			Result: &structs.IndexedPeeringMeshInfos{
+				MeshInfos: map[string]*structs.PeeringMeshInfo{
+					"cluster-03": {
+						TrustDomain: "33333333-2222-1111-0000-333333333333.consul",
+						Datacenter:  "dc3",
+						RootPEMs:    []string{"-----BEGIN CERTIFICATE-----\nPEER-03-ROOT\n-----END CERTIFICATE-----"},
+					},
+				},
+			},
+		},
+		{
+			CorrelationID: peerUpstreamIDPrefix + paymentsUID.String(),
+			Result: &structs.IndexedCheckServiceNodes{
+				Nodes: TestUpstreamNodes(t, "payments"),
+			},
+		},
+		{
+			CorrelationID: peerMeshInfoIDPrefix + paymentsUID.String(),
+			Result: &structs.IndexedPeeringMeshInfos{
+				MeshInfos: map[string]*structs.PeeringMeshInfo{
+					"cluster-02": {
+						TrustDomain:      "22222222-2222-1111-0000-222222222222.consul",
+						Datacenter:       "dc2",
+						RootPEMs:         []string{"-----BEGIN CERTIFICATE-----\nPEER-02-ROOT\n-----END CERTIFICATE-----"},
+						GatewayAddresses: []string{"198.18.2.1:8443", "198.18.2.2:8443"},
+					},
+				},
+			},
+		},
+	})
+}
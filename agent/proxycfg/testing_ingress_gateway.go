@@ -1499,3 +1499,232 @@ func TestConfigSnapshotIngressGateway_TLSMixedMinVersionListeners(t testing.T) *
 			},
 		})
 }
+
+// TestConfigSnapshotIngressGateway_TCPSNI returns a snapshot with two tcp
+// services sharing a TLS listener and routed by SNI, one of them with its own
+// SDS certificate.
+func TestConfigSnapshotIngressGateway_TCPSNI(t testing.T) *ConfigSnapshot {
+	return testConfigSnapshotIngressGatewayTCPSNI(t, func(entry *structs.IngressGatewayConfigEntry) {
+		entry.TLS.Enabled = true
+		entry.Listeners[0].Services[1].TLS = &structs.GatewayServiceTLSConfig{
+			SDS: &structs.GatewayTLSSDSConfig{
+				ClusterName:  "sds-cluster",
+				CertResource: "s2.example.com-cert",
+			},
+		}
+	})
+}
+
+// TestConfigSnapshotIngressGateway_TCPSNIPassthrough returns a snapshot with
+// two tcp services sharing a listener that passes TLS through to them.
+func TestConfigSnapshotIngressGateway_TCPSNIPassthrough(t testing.T) *ConfigSnapshot {
+	return testConfigSnapshotIngressGatewayTCPSNI(t, func(entry *structs.IngressGatewayConfigEntry) {
+		entry.Listeners[0].TLSPassthrough = true
+	})
+}
+
+func testConfigSnapshotIngressGatewayTCPSNI(t testing.T, configFn func(entry *structs.IngressGatewayConfigEntry)) *ConfigSnapshot {
+	var (
+		s1      = structs.NewServiceName("s1", nil)
+		s1UID   = NewUpstreamIDFromServiceName(s1)
+		s1Chain = discoverychain.TestCompileConfigEntries(t, "s1", "default", "default", "dc1", connect.TestClusterID+".consul", nil)
+
+		s2      = structs.NewServiceName("s2", nil)
+		s2UID   = NewUpstreamIDFromServiceName(s2)
+		s2Chain = discoverychain.TestCompileConfigEntries(t, "s2", "default", "default", "dc1", connect.TestClusterID+".consul", nil)
+	)
+
+	return TestConfigSnapshotIngressGateway(t, true, "tcp", "simple", nil,
+		func(entry *structs.IngressGatewayConfigEntry) {
+			entry.Listeners = []structs.IngressListener{
+				{
+					Port:     8443,
+					Protocol: "tcp",
+					Services: []structs.IngressService{
+						{Name: "s1", Hosts: []string{"s1.example.com"}},
+						{Name: "s2", Hosts: []string{"s2.example.com", "*.s2.example.com"}},
+					},
+				},
+			}
+			configFn(entry)
+		}, []cache.UpdateEvent{
+			{
+				CorrelationID: gatewayServicesWatchID,
+				Result: &structs.IndexedGatewayServices{
+					Services: []*structs.GatewayService{
+						{
+							Service:  s1,
+							Port:     8443,
+							Protocol: "tcp",
+							Hosts:    []string{"s1.example.com"},
+						},
+						{
+							Service:  s2,
+							Port:     8443,
+							Protocol: "tcp",
+							Hosts:    []string{"s2.example.com", "*.s2.example.com"},
+						},
+					},
+				},
+			},
+			{
+				CorrelationID: "discovery-chain:" + s1UID.String(),
+				Result: &structs.DiscoveryChainResponse{
+					Chain: s1Chain,
+				},
+			},
+			{
+				CorrelationID: "discovery-chain:" + s2UID.String(),
+				Result: &structs.DiscoveryChainResponse{
+					Chain: s2Chain,
+				},
+			},
+			{
+				CorrelationID: "upstream-target:" + s1Chain.ID() + ":" + s1UID.String(),
+				Result: &structs.IndexedCheckServiceNodes{
+					Nodes: TestUpstreamNodes(t, "s1"),
+				},
+			},
+			{
+				CorrelationID: "upstream-target:" + s2Chain.ID() + ":" + s2UID.String(),
+				Result: &structs.IndexedCheckServiceNodes{
+					Nodes: TestUpstreamNodes(t, "s2"),
+				},
+			},
+		})
+}
+
+// TestConfigSnapshotIngressGateway_HTTPPolicies returns a snapshot with an http
+// listener whose services set CORS policies, request size limits and JWT
+// requirements. s3 only trusts a provider that doesn't exist.
+func TestConfigSnapshotIngressGateway_HTTPPolicies(t testing.T) *ConfigSnapshot {
+	entries := []structs.ConfigEntry{
+		&structs.ProxyConfigEntry{
+			Kind: structs.ProxyDefaults,
+			Name: structs.ProxyConfigGlobal,
+			Config: map[string]interface{}{
+				"protocol": "http",
+			},
+		},
+	}
+
+	var (
+		s1      = structs.NewServiceName("s1", nil)
+		s1UID   = NewUpstreamIDFromServiceName(s1)
+		s1Chain = discoverychain.TestCompileConfigEntries(t, "s1", "default", "default", "dc1", connect.TestClusterID+".consul", nil, entries...)
+
+		s2      = structs.NewServiceName("s2", nil)
+		s2UID   = NewUpstreamIDFromServiceName(s2)
+		s2Chain = discoverychain.TestCompileConfigEntries(t, "s2", "default", "default", "dc1", connect.TestClusterID+".consul", nil, entries...)
+
+		s3      = structs.NewServiceName("s3", nil)
+		s3UID   = NewUpstreamIDFromServiceName(s3)
+		s3Chain = discoverychain.TestCompileConfigEntries(t, "s3", "default", "default", "dc1", connect.TestClusterID+".consul", nil, entries...)
+	)
+
+	return TestConfigSnapshotIngressGateway(t, false, "http", "default", nil, func(entry *structs.IngressGatewayConfigEntry) {
+		entry.Listeners = []structs.IngressListener{
+			{
+				Port:     8080,
+				Protocol: "http",
+				Services: []structs.IngressService{
+					{
+						Name: "s1",
+						CORS: &structs.IngressCORSConfig{
+							AllowOrigins: []string{"*"},
+							AllowMethods: []string{"GET", "POST"},
+							MaxAge:       10 * time.Minute,
+						},
+						MaxRequestBytes: 1024,
+					},
+					{
+						Name: "s2",
+						CORS: &structs.IngressCORSConfig{
+							AllowOrigins:     []string{"https://example.com"},
+							AllowHeaders:     []string{"authorization", "content-type"},
+							ExposeHeaders:    []string{"x-request-id"},
+							AllowCredentials: true,
+						},
+						JWT: &structs.IngressJWTRequirement{
+							Providers: []*structs.IngressJWTProvider{
+								{Name: "okta"},
+								// Not defined, so left out of the requirement.
+								{Name: "missing"},
+							},
+						},
+					},
+					{
+						Name: "s3",
+						JWT: &structs.IngressJWTRequirement{
+							Providers: []*structs.IngressJWTProvider{{Name: "missing"}},
+						},
+					},
+				},
+			},
+		}
+	}, []cache.UpdateEvent{
+		{
+			CorrelationID: jwtProvidersWatchID,
+			Result: &structs.IndexedConfigEntries{
+				Kind: structs.JWTProvider,
+				Entries: []structs.ConfigEntry{
+					&structs.JWTProviderConfigEntry{
+						Name:   "okta",
+						Issuer: "https://example.okta.com",
+						JSONWebKeySet: &structs.JSONWebKeySet{
+							Local: &structs.LocalJWKS{
+								Filename: "/etc/consul/okta-jwks.json",
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			CorrelationID: gatewayServicesWatchID,
+			Result: &structs.IndexedGatewayServices{
+				Services: []*structs.GatewayService{
+					{Service: s1, Port: 8080, Protocol: "http"},
+					{Service: s2, Port: 8080, Protocol: "http"},
+					{Service: s3, Port: 8080, Protocol: "http"},
+				},
+			},
+		},
+		{
+			CorrelationID: "discovery-chain:" + s1UID.String(),
+			Result: &structs.DiscoveryChainResponse{
+				Chain: s1Chain,
+			},
+		},
+		{
+			CorrelationID: "discovery-chain:" + s2UID.String(),
+			Result: &structs.DiscoveryChainResponse{
+				Chain: s2Chain,
+			},
+		},
+		{
+			CorrelationID: "discovery-chain:" + s3UID.String(),
+			Result: &structs.DiscoveryChainResponse{
+				Chain: s3Chain,
+			},
+		},
+		{
+			CorrelationID: "upstream-target:" + s1Chain.ID() + ":" + s1UID.String(),
+			Result: &structs.IndexedCheckServiceNodes{
+				Nodes: TestUpstreamNodes(t, "s1"),
+			},
+		},
+		{
+			CorrelationID: "upstream-target:" + s2Chain.ID() + ":" + s2UID.String(),
+			Result: &structs.IndexedCheckServiceNodes{
+				Nodes: TestUpstreamNodes(t, "s2"),
+			},
+		},
+		{
+			CorrelationID: "upstream-target:" + s3Chain.ID() + ":" + s3UID.String(),
+			Result: &structs.IndexedCheckServiceNodes{
+				Nodes: TestUpstreamNodes(t, "s3"),
+			},
+		},
+	})
+}
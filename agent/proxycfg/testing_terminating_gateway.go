@@ -704,3 +704,82 @@ func TestConfigSnapshotTerminatingGatewayWithLambdaServiceAndServiceResolvers(t
 			},
 		})
 }
+
+func TestConfigSnapshotTerminatingGatewayDestinations(t testing.T) *ConfigSnapshot {
+	var (
+		externalIPTCP       = structs.NewServiceName("external-IP-TCP", nil)
+		externalHostnameTCP = structs.NewServiceName("external-hostname-TCP", nil)
+	)
+
+	return TestConfigSnapshotTerminatingGateway(t, false, nil, []agentcache.UpdateEvent{
+		{
+			CorrelationID: gatewayServicesWatchID,
+			Result: &structs.IndexedGatewayServices{
+				Services: []*structs.GatewayService{
+					{
+						Service: externalIPTCP,
+					},
+					{
+						Service: externalHostnameTCP,
+						CAFile:  "ca.cert.pem",
+						SNI:     "api.hashicorp.com",
+					},
+				},
+			},
+		},
+		// ========
+		// no intentions defined for these services
+		{
+			CorrelationID: serviceIntentionsIDPrefix + externalIPTCP.String(),
+			Result: &structs.IndexedIntentionMatches{
+				Matches: []structs.Intentions{
+					nil,
+				},
+			},
+		},
+		{
+			CorrelationID: serviceIntentionsIDPrefix + externalHostnameTCP.String(),
+			Result: &structs.IndexedIntentionMatches{
+				Matches: []structs.Intentions{
+					nil,
+				},
+			},
+		},
+		// ========
+		{
+			CorrelationID: serviceLeafIDPrefix + externalIPTCP.String(),
+			Result: &structs.IssuedCert{
+				CertPEM:       golden(t, "test-leaf-cert"),
+				PrivateKeyPEM: golden(t, "test-leaf-key"),
+			},
+		},
+		{
+			CorrelationID: serviceLeafIDPrefix + externalHostnameTCP.String(),
+			Result: &structs.IssuedCert{
+				CertPEM:       golden(t, "alt-test-leaf-cert"),
+				PrivateKeyPEM: golden(t, "alt-test-leaf-key"),
+			},
+		},
+		// ========
+		{
+			CorrelationID: serviceConfigIDPrefix + externalIPTCP.String(),
+			Result: &structs.ServiceConfigResponse{
+				ProxyConfig: map[string]interface{}{"protocol": "tcp"},
+				Destination: structs.DestinationConfig{
+					Addresses: []string{"192.168.0.1"},
+					Port:      80,
+				},
+			},
+		},
+		{
+			CorrelationID: serviceConfigIDPrefix + externalHostnameTCP.String(),
+			Result: &structs.ServiceConfigResponse{
+				ProxyConfig: map[string]interface{}{"protocol": "tcp"},
+				Destination: structs.DestinationConfig{
+					Addresses: []string{"api.hashicorp.com"},
+					Port:      8089,
+				},
+			},
+		},
+	})
+}
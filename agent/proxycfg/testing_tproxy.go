@@ -523,3 +523,131 @@ func TestConfigSnapshotTransparentProxyTerminatingGatewayCatalogDestinationsOnly
 		},
 	})
 }
+
+func TestConfigSnapshotTransparentProxyDestination(t testing.T) *ConfigSnapshot {
+	return testConfigSnapshotTransparentProxyDestination(t)
+}
+
+// TestConfigSnapshotTransparentProxyDestinationSharedAddress adds a
+// destination listening on another port of an address of the kafka
+// destination.
+func TestConfigSnapshotTransparentProxyDestinationSharedAddress(t testing.T) *ConfigSnapshot {
+	return testConfigSnapshotTransparentProxyDestination(t, &structs.ServiceConfigEntry{
+		Kind: structs.ServiceDefaults,
+		Name: "kafka-admin",
+		Destination: &structs.DestinationConfig{
+			Addresses: []string{"192.168.2.1"},
+			Port:      9094,
+		},
+	})
+}
+
+func testConfigSnapshotTransparentProxyDestination(t testing.T, extraDestinations ...*structs.ServiceConfigEntry) *ConfigSnapshot {
+	// Destinations declared in service-defaults are routed to through the
+	// terminating gateways linked to them.
+	var (
+		google    = structs.NewServiceName("google", nil)
+		googleUID = NewUpstreamIDFromServiceName(google)
+
+		kafka    = structs.NewServiceName("kafka", nil)
+		kafkaUID = NewUpstreamIDFromServiceName(kafka)
+	)
+
+	tgate := structs.CheckServiceNode{
+		Node: &structs.Node{
+			Address:    "8.8.8.8",
+			Datacenter: "dc1",
+		},
+		Service: &structs.NodeService{
+			Service: "tgate1",
+			Kind:    structs.ServiceKind(structs.TerminatingGateway),
+			Address: "9.9.9.9",
+			Port:    9090,
+		},
+	}
+
+	destinations := structs.ServiceList{google, kafka}
+	var extraUpdates []cache.UpdateEvent
+	for _, entry := range extraDestinations {
+		name := structs.NewServiceName(entry.Name, nil)
+		uid := NewUpstreamIDFromServiceName(name)
+		destinations = append(destinations, name)
+		extraUpdates = append(extraUpdates,
+			cache.UpdateEvent{
+				CorrelationID: destinationConfigEntryIDPrefix + uid.String(),
+				Result:        &structs.ConfigEntryResponse{Entry: entry},
+			},
+			cache.UpdateEvent{
+				CorrelationID: destinationGatewayIDPrefix + uid.String(),
+				Result: &structs.IndexedCheckServiceNodes{
+					Nodes: []structs.CheckServiceNode{tgate},
+				},
+			},
+		)
+	}
+
+	return TestConfigSnapshot(t, func(ns *structs.NodeService) {
+		ns.Proxy.Mode = structs.ProxyModeTransparent
+	}, append([]cache.UpdateEvent{
+		{
+			CorrelationID: meshConfigEntryID,
+			Result: &structs.ConfigEntryResponse{
+				Entry: &structs.MeshConfigEntry{
+					TransparentProxy: structs.TransparentProxyMeshConfig{
+						MeshDestinationsOnly: true,
+					},
+				},
+			},
+		},
+		{
+			CorrelationID: intentionUpstreamsID,
+			Result: &structs.IndexedServiceList{
+				Services: structs.ServiceList{},
+			},
+		},
+		{
+			CorrelationID: intentionUpstreamsDestinationID,
+			Result: &structs.IndexedServiceList{
+				Services: destinations,
+			},
+		},
+		{
+			CorrelationID: destinationConfigEntryIDPrefix + googleUID.String(),
+			Result: &structs.ConfigEntryResponse{
+				Entry: &structs.ServiceConfigEntry{
+					Kind: structs.ServiceDefaults,
+					Name: "google",
+					Destination: &structs.DestinationConfig{
+						Addresses: []string{"www.google.com", "api.google.com"},
+						Port:      443,
+					},
+				},
+			},
+		},
+		{
+			CorrelationID: destinationConfigEntryIDPrefix + kafkaUID.String(),
+			Result: &structs.ConfigEntryResponse{
+				Entry: &structs.ServiceConfigEntry{
+					Kind: structs.ServiceDefaults,
+					Name: "kafka",
+					Destination: &structs.DestinationConfig{
+						Addresses: []string{"192.168.2.1", "192.168.2.2"},
+						Port:      9093,
+					},
+				},
+			},
+		},
+		{
+			CorrelationID: destinationGatewayIDPrefix + googleUID.String(),
+			Result: &structs.IndexedCheckServiceNodes{
+				Nodes: []structs.CheckServiceNode{tgate},
+			},
+		},
+		{
+			CorrelationID: destinationGatewayIDPrefix + kafkaUID.String(),
+			Result: &structs.IndexedCheckServiceNodes{
+				Nodes: []structs.CheckServiceNode{tgate},
+			},
+		},
+	}, extraUpdates...))
+}
@@ -169,8 +169,31 @@ func setupTestVariationConfigEntriesAndSnapshot(
 		})
 	case "chain-and-splitter":
 	case "grpc-router":
+	case "grpc-router-with-grpc-match":
 	case "chain-and-router":
 	case "lb-resolver":
+	case "outlier-detection-resolver":
+	case "locality-failover":
+		events = append(events, cache.UpdateEvent{
+			CorrelationID: "upstream-target:" + dbChain.ID() + ":" + dbUID.String(),
+			Result: &structs.IndexedCheckServiceNodes{
+				Nodes: TestUpstreamNodesWithLocality(t, "db"),
+			},
+		})
+	case "locality-failover-with-failover":
+		events = append(events, cache.UpdateEvent{
+			CorrelationID: "upstream-target:" + dbChain.ID() + ":" + dbUID.String(),
+			Result: &structs.IndexedCheckServiceNodes{
+				Nodes: TestUpstreamNodesWithLocality(t, "db"),
+			},
+		})
+		events = append(events, cache.UpdateEvent{
+			CorrelationID: "upstream-target:fail.default.default.dc1:" + dbUID.String(),
+			Result: &structs.IndexedCheckServiceNodes{
+				Nodes: TestUpstreamNodesAlternate(t),
+			},
+		})
+	case "router-with-fault-injection-and-mirror":
 	default:
 		t.Fatalf("unexpected variation: %q", variation)
 		return nil
@@ -452,6 +475,54 @@ func setupTestVariationDiscoveryChain(
 				},
 			},
 		)
+	case "grpc-router-with-grpc-match":
+		entries = append(entries,
+			&structs.ServiceResolverConfigEntry{
+				Kind:           structs.ServiceResolver,
+				Name:           "db",
+				ConnectTimeout: 33 * time.Second,
+			},
+			&structs.ProxyConfigEntry{
+				Kind: structs.ProxyDefaults,
+				Name: structs.ProxyConfigGlobal,
+				Config: map[string]interface{}{
+					"protocol": "grpc",
+				},
+			},
+			&structs.ServiceRouterConfigEntry{
+				Kind: structs.ServiceRouter,
+				Name: "db",
+				Routes: []structs.ServiceRoute{
+					{
+						Match: &structs.ServiceRouteMatch{
+							GRPC: &structs.ServiceRouteGRPCMatch{
+								Service: "fgrpc.PingServer",
+								Method:  "Ping",
+								Header: []structs.ServiceRouteHTTPMatchHeader{
+									{Name: "x-debug", Present: true},
+								},
+							},
+						},
+						Destination: &structs.ServiceRouteDestination{
+							Service:                "prefix",
+							NumRetries:             3,
+							RetryOnGRPCStatusCodes: []string{"UNAVAILABLE", "RESOURCE_EXHAUSTED"},
+							PerTryTimeout:          2 * time.Second,
+						},
+					},
+					{
+						Match: &structs.ServiceRouteMatch{
+							GRPC: &structs.ServiceRouteGRPCMatch{
+								Service: "fgrpc.PingServer",
+							},
+						},
+						Destination: &structs.ServiceRouteDestination{
+							Service: "prefix",
+						},
+					},
+				},
+			},
+		)
 	case "chain-and-router":
 		entries = append(entries,
 			&structs.ServiceResolverConfigEntry{
@@ -734,6 +805,107 @@ func setupTestVariationDiscoveryChain(
 				},
 			},
 		)
+	case "outlier-detection-resolver":
+		entries = append(entries,
+			&structs.ServiceResolverConfigEntry{
+				Kind: structs.ServiceResolver,
+				Name: "db",
+				OutlierDetection: &structs.OutlierDetection{
+					Interval:                  5 * time.Second,
+					Consecutive5xx:            3,
+					ConsecutiveGatewayFailure: 2,
+					SuccessRate: &structs.OutlierDetectionSuccessRate{
+						MinimumHosts:  4,
+						RequestVolume: 50,
+						StdevFactor:   1.5,
+					},
+					BaseEjectionTime:   45 * time.Second,
+					MaxEjectionPercent: 50,
+				},
+			},
+		)
+	case "locality-failover":
+		entries = append(entries,
+			&structs.ServiceResolverConfigEntry{
+				Kind: structs.ServiceResolver,
+				Name: "db",
+				PrioritizeByLocality: &structs.ServiceResolverPrioritizeByLocality{
+					Mode: structs.LocalityPriorityModeFailover,
+				},
+			},
+		)
+	case "locality-failover-with-failover":
+		entries = append(entries,
+			&structs.ServiceResolverConfigEntry{
+				Kind: structs.ServiceResolver,
+				Name: "db",
+				Failover: map[string]structs.ServiceResolverFailover{
+					"*": {
+						Service: "fail",
+					},
+				},
+				PrioritizeByLocality: &structs.ServiceResolverPrioritizeByLocality{
+					Mode: structs.LocalityPriorityModeFailover,
+				},
+			},
+		)
+	case "router-with-fault-injection-and-mirror":
+		entries = append(entries,
+			&structs.ProxyConfigEntry{
+				Kind: structs.ProxyDefaults,
+				Name: structs.ProxyConfigGlobal,
+				Config: map[string]interface{}{
+					"protocol": "http",
+				},
+			},
+			&structs.ServiceRouterConfigEntry{
+				Kind: structs.ServiceRouter,
+				Name: "db",
+				Routes: []structs.ServiceRoute{
+					{
+						Match: httpMatch(&structs.ServiceRouteHTTPMatch{
+							PathPrefix: "/delay",
+						}),
+						Destination: &structs.ServiceRouteDestination{
+							FaultInjection: &structs.ServiceRouteFaultInjection{
+								Delay: &structs.ServiceRouteFaultDelay{
+									Duration:   500 * time.Millisecond,
+									Percentage: 12.5,
+								},
+							},
+						},
+					},
+					{
+						Match: httpMatch(&structs.ServiceRouteHTTPMatch{
+							PathPrefix: "/abort",
+						}),
+						Destination: &structs.ServiceRouteDestination{
+							FaultInjection: &structs.ServiceRouteFaultInjection{
+								Delay: &structs.ServiceRouteFaultDelay{
+									Duration:   time.Second,
+									Percentage: 100,
+								},
+								Abort: &structs.ServiceRouteFaultAbort{
+									HTTPStatus: 503,
+									Percentage: 0.01,
+								},
+							},
+						},
+					},
+					{
+						Match: httpMatch(&structs.ServiceRouteHTTPMatch{
+							PathPrefix: "/mirror",
+						}),
+						Destination: &structs.ServiceRouteDestination{
+							Mirror: &structs.ServiceRouteMirror{
+								Service:    "shadow",
+								Percentage: 25,
+							},
+						},
+					},
+				},
+			},
+		)
 	default:
 		t.Fatalf("unexpected variation: %q", variation)
 		return nil
@@ -102,7 +102,8 @@ func (c *Client) Notify(
 }
 
 func (c *Client) useStreaming(req structs.ServiceSpecificRequest) bool {
-	return c.UseStreamingBackend && !req.Ingress && req.Source.Node == ""
+	// Services imported from peers are not published on the event stream.
+	return c.UseStreamingBackend && !req.Ingress && req.Source.Node == "" && req.PeerName == ""
 }
 
 func (c *Client) newServiceRequest(req structs.ServiceSpecificRequest) serviceRequest {
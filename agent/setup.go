@@ -263,6 +263,7 @@ func getPrometheusDefs(cfg lib.TelemetryConfig, isServer bool) ([]prometheus.Gau
 		CatalogCounters,
 		cache.Counters,
 		consul.ACLCounters,
+		consul.CARootRotationCounters,
 		consul.CatalogCounters,
 		consul.ClientCounters,
 		consul.RPCCounters,
@@ -308,11 +309,13 @@ func getPrometheusDefs(cfg lib.TelemetryConfig, isServer bool) ([]prometheus.Gau
 		HTTPSummaries,
 		consul.ACLSummaries,
 		consul.ACLEndpointSummaries,
+		consul.CARootRotationSummaries,
 		consul.CatalogSummaries,
 		consul.FederationStateSummaries,
 		consul.IntentionSummaries,
 		consul.KVSummaries,
 		consul.LeaderSummaries,
+		consul.PeeringSummaries,
 		consul.PreparedQuerySummaries,
 		consul.RPCSummaries,
 		consul.SegmentOSSSummaries,
@@ -260,6 +260,11 @@ type ACLToken struct {
 	// ACLAuthMethodEnterpriseMeta is the EnterpriseMeta for the AuthMethod that this token was created from
 	ACLAuthMethodEnterpriseMeta
 
+	// ParentAccessorID is the AccessorID of the token this token was derived
+	// from using the ACL.TokenDerive endpoint. Derived tokens are revoked
+	// when their parent token is deleted.
+	ParentAccessorID string `json:",omitempty"`
+
 	// ExpirationTime represents the point after which a token should be
 	// considered revoked and is eligible for destruction. The zero value
 	// represents NO expiration.
@@ -474,7 +479,7 @@ func (t *ACLToken) SetHash(force bool) []byte {
 
 func (t *ACLToken) EstimateSize() int {
 	// 41 = 16 (RaftIndex) + 8 (Hash) + 8 (ExpirationTime) + 8 (CreateTime) + 1 (Local)
-	size := 41 + len(t.AccessorID) + len(t.SecretID) + len(t.Description) + len(t.Type) + len(t.Rules) + len(t.AuthMethod)
+	size := 41 + len(t.AccessorID) + len(t.SecretID) + len(t.Description) + len(t.Type) + len(t.Rules) + len(t.AuthMethod) + len(t.ParentAccessorID)
 	for _, link := range t.Policies {
 		size += len(link.ID) + len(link.Name)
 	}
@@ -503,6 +508,7 @@ type ACLTokenListStub struct {
 	NodeIdentities    []*ACLNodeIdentity    `json:",omitempty"`
 	Local             bool
 	AuthMethod        string     `json:",omitempty"`
+	ParentAccessorID  string     `json:",omitempty"`
 	ExpirationTime    *time.Time `json:",omitempty"`
 	CreateTime        time.Time  `json:",omitempty"`
 	Hash              []byte
@@ -526,6 +532,7 @@ func (token *ACLToken) Stub() *ACLTokenListStub {
 		NodeIdentities:              token.NodeIdentities,
 		Local:                       token.Local,
 		AuthMethod:                  token.AuthMethod,
+		ParentAccessorID:            token.ParentAccessorID,
 		ExpirationTime:              token.ExpirationTime,
 		CreateTime:                  token.CreateTime,
 		Hash:                        token.Hash,
@@ -1215,6 +1222,19 @@ func (r *ACLTokenSetRequest) RequestDatacenter() string {
 	return r.Datacenter
 }
 
+// ACLTokenDeriveRequest is used to create a child token from the token that
+// authorizes the request. The child may only be granted a subset of the
+// parent's privileges and must set an ExpirationTTL.
+type ACLTokenDeriveRequest struct {
+	ACLToken   ACLToken // The policies, identities and TTL requested for the child token
+	Datacenter string   // The datacenter to perform the request within
+	WriteRequest
+}
+
+func (r *ACLTokenDeriveRequest) RequestDatacenter() string {
+	return r.Datacenter
+}
+
 // ACLTokenGetRequest is used for token read operations at the RPC layer
 type ACLTokenGetRequest struct {
 	TokenID     string         // id used for the token lookup
@@ -1421,6 +1441,48 @@ type ACLPolicyBatchResponse struct {
 	QueryMeta
 }
 
+// ACLPolicyLintRequest is used at the RPC layer to lint policy rules and to
+// report which policies grant access to a resource.
+//
+// When Rules is set only those rules are linted. When Resource is set the
+// stored policies granting Access to the resource are reported. Otherwise all
+// stored policies are linted.
+type ACLPolicyLintRequest struct {
+	Rules      string       // Policy rules to lint instead of the stored policies
+	Resource   acl.Resource // Resource to report grants for, e.g. "service"
+	Segment    string       // Name of the resource to report grants for
+	Access     string       // Access level to report grants for, defaults to "read"
+	Datacenter string       // The datacenter to perform the request within
+	acl.EnterpriseMeta
+	QueryOptions
+}
+
+func (r *ACLPolicyLintRequest) RequestDatacenter() string {
+	return r.Datacenter
+}
+
+// ACLPolicyLintResult holds the lint findings for a single policy. PolicyID
+// and PolicyName are empty when linting rules that have not been stored.
+type ACLPolicyLintResult struct {
+	PolicyID   string
+	PolicyName string
+	Findings   []acl.PolicyLintFinding
+}
+
+// ACLPolicyGrant identifies a policy that grants access to a resource and
+// the rule within that policy which grants it.
+type ACLPolicyGrant struct {
+	PolicyID   string
+	PolicyName string
+	Rule       string
+}
+
+type ACLPolicyLintResponse struct {
+	Results []ACLPolicyLintResult `json:",omitempty"`
+	Grants  []ACLPolicyGrant      `json:",omitempty"`
+	QueryMeta
+}
+
 // ACLPolicyBatchSetRequest is used at the Raft layer for batching
 // multiple policy creations and updates
 //
@@ -1,7 +1,10 @@
 package structs
 
 import (
+	"encoding/json"
 	"fmt"
+	"net"
+	"regexp"
 	"strconv"
 	"strings"
 	"time"
@@ -29,6 +32,7 @@ const (
 	ServiceIntentions  string = "service-intentions"
 	MeshConfig         string = "mesh"
 	ExportedServices   string = "exported-services"
+	JWTProvider        string = "jwt-provider"
 
 	ProxyConfigGlobal string = "global"
 	MeshConfigMesh    string = "mesh"
@@ -47,6 +51,7 @@ var AllConfigEntryKinds = []string{
 	ServiceIntentions,
 	MeshConfig,
 	ExportedServices,
+	JWTProvider,
 }
 
 // ConfigEntry is the interface for centralized configuration stored in Raft.
@@ -103,6 +108,17 @@ type ServiceConfigEntry struct {
 	Expose           ExposeConfig           `json:",omitempty"`
 	ExternalSNI      string                 `json:",omitempty" alias:"external_sni"`
 	UpstreamConfig   *UpstreamConfiguration `json:",omitempty" alias:"upstream_config"`
+	RateLimit        *RateLimitConfig       `json:",omitempty" alias:"rate_limit"`
+	ExtAuthz         *ExtAuthzConfig        `json:",omitempty" alias:"ext_authz"`
+	EnvoyExtensions  EnvoyExtensions        `json:",omitempty" alias:"envoy_extensions"`
+	Destination      *DestinationConfig     `json:",omitempty"`
+	ConnectionPool   *ConnectionPoolConfig  `json:",omitempty" alias:"connection_pool"`
+
+	// MutualTLSMode controls whether the public listener of the service's
+	// sidecar proxies also accepts plaintext connections, from the
+	// PermissiveSourceCIDRs, while the service's callers join the mesh.
+	MutualTLSMode         MutualTLSMode `json:",omitempty" alias:"mutual_tls_mode"`
+	PermissiveSourceCIDRs []string      `json:",omitempty" alias:"permissive_source_cidrs"`
 
 	Meta               map[string]string `json:",omitempty"`
 	acl.EnterpriseMeta `hcl:",squash" mapstructure:",squash"`
@@ -113,6 +129,12 @@ func (e *ServiceConfigEntry) Clone() *ServiceConfigEntry {
 	e2 := *e
 	e2.Expose = e.Expose.Clone()
 	e2.UpstreamConfig = e.UpstreamConfig.Clone()
+	e2.RateLimit = e.RateLimit.Clone()
+	e2.ExtAuthz = e.ExtAuthz.Clone()
+	e2.EnvoyExtensions = e.EnvoyExtensions.Clone()
+	e2.Destination = e.Destination.Clone()
+	e2.ConnectionPool = e.ConnectionPool.Clone()
+	e2.PermissiveSourceCIDRs = append([]string(nil), e.PermissiveSourceCIDRs...)
 	return &e2
 }
 
@@ -162,6 +184,14 @@ func (e *ServiceConfigEntry) Normalize() error {
 		}
 	}
 
+	if e.RateLimit != nil {
+		e.RateLimit.normalize(&e.EnterpriseMeta)
+	}
+
+	if e.ExtAuthz != nil {
+		e.ExtAuthz.normalize(&e.EnterpriseMeta)
+	}
+
 	return validationErr
 }
 
@@ -190,6 +220,38 @@ func (e *ServiceConfigEntry) Validate() error {
 		}
 	}
 
+	if e.RateLimit != nil {
+		if err := e.RateLimit.validate(e.Protocol); err != nil {
+			validationErr = multierror.Append(validationErr, fmt.Errorf("error in rate limit: %v", err))
+		}
+	}
+
+	if e.ExtAuthz != nil {
+		if err := e.ExtAuthz.validate(e.Protocol); err != nil {
+			validationErr = multierror.Append(validationErr, fmt.Errorf("error in ext_authz: %v", err))
+		}
+	}
+
+	if err := e.EnvoyExtensions.Validate(); err != nil {
+		validationErr = multierror.Append(validationErr, err)
+	}
+
+	if e.Destination != nil {
+		if err := e.Destination.validate(e.Protocol); err != nil {
+			validationErr = multierror.Append(validationErr, fmt.Errorf("error in destination: %v", err))
+		}
+	}
+
+	if e.ConnectionPool != nil {
+		if err := e.ConnectionPool.Validate(); err != nil {
+			validationErr = multierror.Append(validationErr, fmt.Errorf("error in connection pool: %v", err))
+		}
+	}
+
+	if err := validateMutualTLS(e.MutualTLSMode, e.PermissiveSourceCIDRs); err != nil {
+		validationErr = multierror.Append(validationErr, err)
+	}
+
 	return validationErr
 }
 
@@ -221,6 +283,320 @@ func (e *ServiceConfigEntry) GetEnterpriseMeta() *acl.EnterpriseMeta {
 	return &e.EnterpriseMeta
 }
 
+// DestinationConfig declares a service as an external destination, reached
+// by transparent proxies through the terminating gateways the service is
+// linked to, instead of through registered instances.
+type DestinationConfig struct {
+	// Addresses are the IP addresses or hostnames of the destination.
+	// Connections to an IP address are matched by their original destination,
+	// and connections to a hostname by the SNI of the TLS handshake, so
+	// clients must use TLS to reach a hostname.
+	Addresses []string `json:",omitempty"`
+
+	// Port is the port of the destination.
+	Port int `json:",omitempty"`
+}
+
+// validDestinationHostname matches DNS names made of one or more labels.
+var validDestinationHostname = regexp.MustCompile(`^([a-zA-Z0-9]([a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?\.)*[a-zA-Z0-9]([a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?$`)
+
+func (d *DestinationConfig) Clone() *DestinationConfig {
+	if d == nil {
+		return nil
+	}
+	d2 := *d
+	d2.Addresses = append([]string(nil), d.Addresses...)
+	return &d2
+}
+
+// HasHostname returns true if any of the addresses of the destination is a
+// hostname.
+func (d *DestinationConfig) HasHostname() bool {
+	if d == nil {
+		return false
+	}
+	for _, addr := range d.Addresses {
+		if IsDestinationHostname(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsDestinationHostname returns true if the address of a destination is a
+// hostname rather than an IP address.
+func IsDestinationHostname(addr string) bool {
+	return net.ParseIP(addr) == nil
+}
+
+func (d *DestinationConfig) validate(protocol string) error {
+	if protocol != "" && protocol != "tcp" {
+		return fmt.Errorf("destinations only support the \"tcp\" protocol, got %q", protocol)
+	}
+	if len(d.Addresses) == 0 {
+		return fmt.Errorf("at least one address is required")
+	}
+	if d.Port < 1 || d.Port > 65535 {
+		return fmt.Errorf("port must be between 1 and 65535, got %d", d.Port)
+	}
+
+	seen := make(map[string]bool, len(d.Addresses))
+	for _, addr := range d.Addresses {
+		if seen[addr] {
+			return fmt.Errorf("address %q is listed more than once", addr)
+		}
+		seen[addr] = true
+
+		if !IsDestinationHostname(addr) {
+			continue
+		}
+		if len(addr) > 253 || !validDestinationHostname.MatchString(addr) {
+			return fmt.Errorf("address %q is neither an IP address nor a valid hostname", addr)
+		}
+	}
+	return nil
+}
+
+// RateLimitConfig configures the rate at which the sidecar proxies of a
+// service accept inbound traffic. Limits are enforced by each proxy instance
+// independently, so the effective limit for a service grows with the number
+// of instances.
+type RateLimitConfig struct {
+	// RequestsPerSecond is the default number of requests per second each
+	// proxy instance accepts. For services using the "tcp" protocol it limits
+	// new connections per second instead. Zero means no default limit.
+	RequestsPerSecond int `json:",omitempty" alias:"requests_per_second"`
+
+	// Burst is the number of requests that can be accepted at once before the
+	// limit applies. Defaults to RequestsPerSecond.
+	Burst int `json:",omitempty"`
+
+	// Routes overrides the default limit for requests matching a path prefix.
+	// The longest matching prefix applies. Only supported for HTTP-like
+	// protocols.
+	Routes []RateLimitRoute `json:",omitempty"`
+
+	// Sources overrides the limit for requests from specific source services.
+	// Requests from a listed source are counted against that source's own
+	// limit instead of the default or route limit. Only supported for
+	// HTTP-like protocols and requires RequestsPerSecond to be set.
+	Sources []RateLimitSource `json:",omitempty"`
+}
+
+// RateLimitRoute is the rate limit applied to requests matching a path
+// prefix.
+type RateLimitRoute struct {
+	// PathPrefix is the request path prefix the limit applies to. It must
+	// begin with a "/".
+	PathPrefix string `json:",omitempty" alias:"path_prefix"`
+
+	// RequestsPerSecond is the number of matching requests per second each
+	// proxy instance accepts.
+	RequestsPerSecond int `json:",omitempty" alias:"requests_per_second"`
+
+	// Burst defaults to RequestsPerSecond.
+	Burst int `json:",omitempty"`
+}
+
+// RateLimitSource is the rate limit applied to requests from one source
+// service.
+type RateLimitSource struct {
+	// Name is the name of the source service.
+	Name string `json:",omitempty"`
+	// EnterpriseMeta defaults to that of the enclosing config entry.
+	acl.EnterpriseMeta `hcl:",squash" mapstructure:",squash"`
+
+	// RequestsPerSecond is the number of requests per second each proxy
+	// instance accepts from the source.
+	RequestsPerSecond int `json:",omitempty" alias:"requests_per_second"`
+
+	// Burst defaults to RequestsPerSecond.
+	Burst int `json:",omitempty"`
+}
+
+func (c *RateLimitConfig) Clone() *RateLimitConfig {
+	if c == nil {
+		return nil
+	}
+
+	c2 := *c
+	if len(c.Routes) > 0 {
+		c2.Routes = make([]RateLimitRoute, len(c.Routes))
+		copy(c2.Routes, c.Routes)
+	}
+	if len(c.Sources) > 0 {
+		c2.Sources = make([]RateLimitSource, len(c.Sources))
+		copy(c2.Sources, c.Sources)
+	}
+	return &c2
+}
+
+func (c *RateLimitConfig) normalize(entMeta *acl.EnterpriseMeta) {
+	for i := range c.Sources {
+		// If the source namespace is omitted it inherits that of the enclosing
+		// config entry.
+		c.Sources[i].EnterpriseMeta.MergeNoWildcard(entMeta)
+		c.Sources[i].EnterpriseMeta.Normalize()
+	}
+}
+
+func (c *RateLimitConfig) validate(protocol string) error {
+	if err := validateRateLimit(c.RequestsPerSecond, c.Burst); err != nil {
+		return err
+	}
+
+	if (len(c.Routes) > 0 || len(c.Sources) > 0) && protocol != "" && !IsProtocolHTTPLike(protocol) {
+		return fmt.Errorf("Routes and Sources are only supported for HTTP-like protocols, not %q", protocol)
+	}
+
+	var validationErr error
+
+	seenPrefixes := make(map[string]struct{})
+	for i, r := range c.Routes {
+		if !strings.HasPrefix(r.PathPrefix, "/") {
+			validationErr = multierror.Append(validationErr, fmt.Errorf("Routes[%d]: PathPrefix must begin with a '/'", i))
+			continue
+		}
+		if _, ok := seenPrefixes[r.PathPrefix]; ok {
+			validationErr = multierror.Append(validationErr, fmt.Errorf("Routes[%d]: duplicate PathPrefix %q", i, r.PathPrefix))
+			continue
+		}
+		seenPrefixes[r.PathPrefix] = struct{}{}
+
+		if r.RequestsPerSecond == 0 {
+			validationErr = multierror.Append(validationErr, fmt.Errorf("Routes[%d]: RequestsPerSecond is required", i))
+		} else if err := validateRateLimit(r.RequestsPerSecond, r.Burst); err != nil {
+			validationErr = multierror.Append(validationErr, fmt.Errorf("Routes[%d]: %v", i, err))
+		}
+	}
+
+	if len(c.Sources) > 0 && c.RequestsPerSecond == 0 {
+		validationErr = multierror.Append(validationErr, fmt.Errorf("RequestsPerSecond is required when Sources are set"))
+	}
+
+	seenSources := make(map[ServiceName]struct{})
+	for i, src := range c.Sources {
+		if src.Name == "" {
+			validationErr = multierror.Append(validationErr, fmt.Errorf("Sources[%d]: Name is required", i))
+			continue
+		}
+		if src.Name == WildcardSpecifier || src.NamespaceOrDefault() == WildcardSpecifier {
+			validationErr = multierror.Append(validationErr, fmt.Errorf("Sources[%d]: wildcards are not supported", i))
+			continue
+		}
+		sn := NewServiceName(src.Name, &src.EnterpriseMeta)
+		if _, ok := seenSources[sn]; ok {
+			validationErr = multierror.Append(validationErr, fmt.Errorf("Sources[%d]: duplicate source %q", i, sn.String()))
+			continue
+		}
+		seenSources[sn] = struct{}{}
+
+		if src.RequestsPerSecond == 0 {
+			validationErr = multierror.Append(validationErr, fmt.Errorf("Sources[%d]: RequestsPerSecond is required", i))
+		} else if err := validateRateLimit(src.RequestsPerSecond, src.Burst); err != nil {
+			validationErr = multierror.Append(validationErr, fmt.Errorf("Sources[%d]: %v", i, err))
+		}
+	}
+
+	return validationErr
+}
+
+func validateRateLimit(rps, burst int) error {
+	if rps < 0 {
+		return fmt.Errorf("RequestsPerSecond cannot be negative")
+	}
+	if burst < 0 {
+		return fmt.Errorf("Burst cannot be negative")
+	}
+	if burst != 0 && burst < rps {
+		return fmt.Errorf("Burst cannot be less than RequestsPerSecond")
+	}
+	if burst != 0 && rps == 0 {
+		return fmt.Errorf("Burst requires RequestsPerSecond to be set")
+	}
+	return nil
+}
+
+const (
+	ExtAuthzProtocolGRPC = "grpc"
+	ExtAuthzProtocolHTTP = "http"
+)
+
+// ExtAuthzConfig configures an external authorization service that the
+// sidecar proxies of a service consult for every inbound request, or every new
+// connection for services using the "tcp" protocol. The authorization service
+// must implement Envoy's external authorization API and be an upstream of the
+// proxies, so that it is reached over mTLS like any other mesh service.
+type ExtAuthzConfig struct {
+	// Service is the name of the authorization service.
+	Service string `json:",omitempty"`
+	// EnterpriseMeta defaults to that of the enclosing config entry.
+	acl.EnterpriseMeta `hcl:",squash" mapstructure:",squash"`
+
+	// Protocol is the API used to call the authorization service, either
+	// "grpc" or "http". Defaults to "grpc". Services using the "tcp" protocol
+	// only support "grpc".
+	Protocol string `json:",omitempty"`
+
+	// PathPrefix is prepended to the path of the request sent to an "http"
+	// authorization service.
+	PathPrefix string `json:",omitempty" alias:"path_prefix"`
+
+	// TimeoutMs is how long to wait for the authorization service to answer.
+	// Defaults to 200ms.
+	TimeoutMs int `json:",omitempty" alias:"timeout_ms"`
+
+	// FailureModeAllow lets requests through when the authorization service
+	// cannot be reached or fails to answer in time. By default they are
+	// rejected.
+	FailureModeAllow bool `json:",omitempty" alias:"failure_mode_allow"`
+}
+
+func (c *ExtAuthzConfig) Clone() *ExtAuthzConfig {
+	if c == nil {
+		return nil
+	}
+
+	c2 := *c
+	return &c2
+}
+
+func (c *ExtAuthzConfig) normalize(entMeta *acl.EnterpriseMeta) {
+	c.Protocol = strings.ToLower(c.Protocol)
+	c.EnterpriseMeta.MergeNoWildcard(entMeta)
+	c.EnterpriseMeta.Normalize()
+}
+
+func (c *ExtAuthzConfig) validate(protocol string) error {
+	if c.Service == "" {
+		return fmt.Errorf("Service is required")
+	}
+	if c.Service == WildcardSpecifier || c.NamespaceOrDefault() == WildcardSpecifier {
+		return fmt.Errorf("Service cannot be a wildcard")
+	}
+
+	switch c.Protocol {
+	case "", ExtAuthzProtocolGRPC:
+		if c.PathPrefix != "" {
+			return fmt.Errorf("PathPrefix is only supported with the %q protocol", ExtAuthzProtocolHTTP)
+		}
+	case ExtAuthzProtocolHTTP:
+		if protocol != "" && !IsProtocolHTTPLike(protocol) {
+			return fmt.Errorf("Protocol %q is only supported for HTTP-like services, not %q", ExtAuthzProtocolHTTP, protocol)
+		}
+		if c.PathPrefix != "" && !strings.HasPrefix(c.PathPrefix, "/") {
+			return fmt.Errorf("PathPrefix must begin with a '/'")
+		}
+	default:
+		return fmt.Errorf("Protocol must be %q or %q, not %q", ExtAuthzProtocolGRPC, ExtAuthzProtocolHTTP, c.Protocol)
+	}
+
+	if c.TimeoutMs < 0 {
+		return fmt.Errorf("TimeoutMs cannot be negative")
+	}
+	return nil
+}
+
 type UpstreamConfiguration struct {
 	// Overrides is a slice of per-service configuration. The name field is
 	// required.
@@ -262,6 +638,15 @@ type ProxyConfigEntry struct {
 	TransparentProxy TransparentProxyConfig `json:",omitempty" alias:"transparent_proxy"`
 	MeshGateway      MeshGatewayConfig      `json:",omitempty" alias:"mesh_gateway"`
 	Expose           ExposeConfig           `json:",omitempty"`
+	AccessLogs       AccessLogsConfig       `json:",omitempty" alias:"access_logs"`
+	Tracing          *TracingConfig         `json:",omitempty"`
+	EnvoyExtensions  EnvoyExtensions        `json:",omitempty" alias:"envoy_extensions"`
+
+	// MutualTLSMode controls whether the public listener of sidecar proxies
+	// also accepts plaintext connections, from the PermissiveSourceCIDRs.
+	// It can be overridden per service by the service-defaults config entry.
+	MutualTLSMode         MutualTLSMode `json:",omitempty" alias:"mutual_tls_mode"`
+	PermissiveSourceCIDRs []string      `json:",omitempty" alias:"permissive_source_cidrs"`
 
 	Meta               map[string]string `json:",omitempty"`
 	acl.EnterpriseMeta `hcl:",squash" mapstructure:",squash"`
@@ -297,6 +682,10 @@ func (e *ProxyConfigEntry) Normalize() error {
 
 	e.EnterpriseMeta.Normalize()
 
+	if e.Tracing != nil {
+		e.Tracing.normalize(&e.EnterpriseMeta)
+	}
+
 	return nil
 }
 
@@ -313,6 +702,24 @@ func (e *ProxyConfigEntry) Validate() error {
 		return err
 	}
 
+	if err := e.AccessLogs.Validate(); err != nil {
+		return fmt.Errorf("error in AccessLogs: %v", err)
+	}
+
+	if e.Tracing != nil {
+		if err := e.Tracing.Validate(); err != nil {
+			return fmt.Errorf("error in Tracing: %v", err)
+		}
+	}
+
+	if err := e.EnvoyExtensions.Validate(); err != nil {
+		return err
+	}
+
+	if err := validateMutualTLS(e.MutualTLSMode, e.PermissiveSourceCIDRs); err != nil {
+		return err
+	}
+
 	return e.validateEnterpriseMeta()
 }
 
@@ -386,8 +793,169 @@ func (e *ProxyConfigEntry) UnmarshalBinary(data []byte) error {
 	if err != nil {
 		return err
 	}
-
 	e.Config = config
+
+	for i, ext := range e.EnvoyExtensions {
+		args, err := lib.MapWalk(ext.Arguments)
+		if err != nil {
+			return err
+		}
+		e.EnvoyExtensions[i].Arguments = args
+	}
+	return nil
+}
+
+type LogSinkType string
+
+const (
+	DefaultLogSinkType LogSinkType = ""
+	FileLogSinkType    LogSinkType = "file"
+	StdErrLogSinkType  LogSinkType = "stderr"
+	StdOutLogSinkType  LogSinkType = "stdout"
+)
+
+// AccessLogsConfig configures the access logs written by every Envoy proxy
+// of the mesh, for all the listeners Consul generates.
+type AccessLogsConfig struct {
+	// Enabled turns access logging on.
+	Enabled bool `json:",omitempty"`
+
+	// DisableListenerLogs turns off the logging of connections rejected by a
+	// listener before reaching any filter chain, which are otherwise logged
+	// along with the requests and connections handled by the filter chains.
+	DisableListenerLogs bool `json:",omitempty" alias:"disable_listener_logs"`
+
+	// Type is where the logs are written. Defaults to "stdout".
+	Type LogSinkType `json:",omitempty"`
+
+	// Path is the file the logs are written to when Type is "file".
+	Path string `json:",omitempty"`
+
+	// JSONFormat is a JSON object whose values are Envoy format strings, used
+	// to write each entry as a JSON document.
+	JSONFormat string `json:",omitempty" alias:"json_format"`
+
+	// TextFormat is an Envoy format string used to write each entry as a
+	// line of text.
+	TextFormat string `json:",omitempty" alias:"text_format"`
+}
+
+func (c *AccessLogsConfig) Validate() error {
+	switch c.Type {
+	case DefaultLogSinkType, StdErrLogSinkType, StdOutLogSinkType:
+		if c.Path != "" {
+			return fmt.Errorf("Path is only supported with the %q type", FileLogSinkType)
+		}
+	case FileLogSinkType:
+		if c.Path == "" {
+			return fmt.Errorf("Path is required with the %q type", FileLogSinkType)
+		}
+	default:
+		return fmt.Errorf("invalid Type %q, must be one of %q, %q or %q", c.Type, StdOutLogSinkType, StdErrLogSinkType, FileLogSinkType)
+	}
+
+	if c.JSONFormat != "" && c.TextFormat != "" {
+		return fmt.Errorf("only one of JSONFormat or TextFormat can be set")
+	}
+	if c.JSONFormat != "" {
+		var format map[string]interface{}
+		if err := json.Unmarshal([]byte(c.JSONFormat), &format); err != nil {
+			return fmt.Errorf("JSONFormat must be a JSON object: %v", err)
+		}
+	}
+	return nil
+}
+
+type TracingProvider string
+
+const (
+	ZipkinTracingProvider        TracingProvider = "zipkin"
+	OpenTelemetryTracingProvider TracingProvider = "opentelemetry"
+)
+
+// TracingConfig configures the distributed tracing of the HTTP requests
+// handled by every Envoy proxy of the mesh.
+type TracingConfig struct {
+	// Provider is the tracer the spans are reported with.
+	Provider TracingProvider `json:",omitempty"`
+
+	// Collector is where the spans are reported.
+	Collector TracingCollector `json:",omitempty"`
+
+	// CollectorEndpoint is the HTTP path the spans are posted to with the
+	// "zipkin" provider. Defaults to "/api/v2/spans".
+	CollectorEndpoint string `json:",omitempty" alias:"collector_endpoint"`
+
+	// SamplingPercentage is the percentage of the requests not already
+	// sampled by the client that are traced. Defaults to 0, only tracing the
+	// requests that carry trace headers asking for it.
+	SamplingPercentage float64 `json:",omitempty" alias:"sampling_percentage"`
+
+	// CustomTags are added to every span.
+	CustomTags map[string]string `json:",omitempty" alias:"custom_tags"`
+}
+
+// TracingCollector is either a service of the mesh, which must be an upstream
+// of the proxies, or a static address reachable by the proxies.
+type TracingCollector struct {
+	Service            string `json:",omitempty"`
+	acl.EnterpriseMeta `hcl:",squash" mapstructure:",squash"`
+
+	Address string `json:",omitempty"`
+	Port    int    `json:",omitempty"`
+}
+
+func (c *TracingConfig) normalize(entMeta *acl.EnterpriseMeta) {
+	c.Provider = TracingProvider(strings.ToLower(string(c.Provider)))
+	if c.Collector.Service != "" {
+		c.Collector.EnterpriseMeta.MergeNoWildcard(entMeta)
+		c.Collector.EnterpriseMeta.Normalize()
+	}
+}
+
+func (c *TracingConfig) Validate() error {
+	switch c.Provider {
+	case ZipkinTracingProvider:
+	case OpenTelemetryTracingProvider:
+		if c.CollectorEndpoint != "" {
+			return fmt.Errorf("CollectorEndpoint is only supported with the %q provider", ZipkinTracingProvider)
+		}
+	case "":
+		return fmt.Errorf("Provider is required")
+	default:
+		return fmt.Errorf("invalid Provider %q, must be %q or %q", c.Provider, ZipkinTracingProvider, OpenTelemetryTracingProvider)
+	}
+
+	if c.CollectorEndpoint != "" && !strings.HasPrefix(c.CollectorEndpoint, "/") {
+		return fmt.Errorf("CollectorEndpoint must begin with a '/'")
+	}
+
+	switch {
+	case c.Collector.Service != "" && c.Collector.Address != "":
+		return fmt.Errorf("only one of Collector.Service or Collector.Address can be set")
+	case c.Collector.Service != "":
+		if c.Collector.Service == WildcardSpecifier || c.Collector.NamespaceOrDefault() == WildcardSpecifier {
+			return fmt.Errorf("Collector.Service cannot be a wildcard")
+		}
+		if c.Collector.Port != 0 {
+			return fmt.Errorf("Collector.Port is only supported with Collector.Address")
+		}
+	case c.Collector.Address != "":
+		if c.Collector.Port <= 0 || c.Collector.Port > 65535 {
+			return fmt.Errorf("Collector.Port must be between 1 and 65535")
+		}
+	default:
+		return fmt.Errorf("one of Collector.Service or Collector.Address is required")
+	}
+
+	if c.SamplingPercentage < 0 || c.SamplingPercentage > 100 {
+		return fmt.Errorf("SamplingPercentage must be between 0 and 100")
+	}
+	for tag := range c.CustomTags {
+		if tag == "" {
+			return fmt.Errorf("CustomTags cannot have an empty name")
+		}
+	}
 	return nil
 }
 
@@ -545,6 +1113,8 @@ func MakeConfigEntry(kind, name string) (ConfigEntry, error) {
 		return &MeshConfigEntry{}, nil
 	case ExportedServices:
 		return &ExportedServicesConfigEntry{Name: name}, nil
+	case JWTProvider:
+		return &JWTProviderConfigEntry{Name: name}, nil
 	default:
 		return nil, fmt.Errorf("invalid config entry kind: %s", kind)
 	}
@@ -719,6 +1289,10 @@ type UpstreamConfig struct {
 	// be monitored for removal from the load balancing pool.
 	PassiveHealthCheck *PassiveHealthCheck `json:",omitempty" alias:"passive_health_check"`
 
+	// ConnectionPool configures the idle timeouts, keepalives and request
+	// limits of the connections the local proxy makes to the upstream.
+	ConnectionPool *ConnectionPoolConfig `json:",omitempty" alias:"connection_pool"`
+
 	// MeshGatewayConfig controls how Mesh Gateways are configured and used
 	MeshGateway MeshGatewayConfig `json:",omitempty" alias:"mesh_gateway" `
 }
@@ -728,6 +1302,7 @@ func (cfg UpstreamConfig) Clone() UpstreamConfig {
 
 	cfg2.Limits = cfg.Limits.Clone()
 	cfg2.PassiveHealthCheck = cfg.PassiveHealthCheck.Clone()
+	cfg2.ConnectionPool = cfg.ConnectionPool.Clone()
 
 	return cfg2
 }
@@ -769,6 +1344,9 @@ func (cfg UpstreamConfig) MergeInto(dst map[string]interface{}) {
 	if cfg.PassiveHealthCheck != nil {
 		dst["passive_health_check"] = cfg.PassiveHealthCheck
 	}
+	if cfg.ConnectionPool != nil {
+		dst["connection_pool"] = cfg.ConnectionPool
+	}
 }
 
 func (cfg *UpstreamConfig) NormalizeWithoutName() error {
@@ -838,6 +1416,13 @@ func (cfg UpstreamConfig) validate(named bool) error {
 		}
 	}
 
+	if cfg.ConnectionPool != nil {
+		err := cfg.ConnectionPool.Validate()
+		if err != nil {
+			validationErr = multierror.Append(validationErr, err)
+		}
+	}
+
 	return validationErr
 }
 
@@ -970,6 +1555,80 @@ func (ul UpstreamLimits) Validate() error {
 	return nil
 }
 
+// ConnectionPoolConfig describes how the local proxy manages the connections
+// to an upstream, or to the local service instance for the public listener.
+// Unset values keep the Envoy defaults.
+type ConnectionPoolConfig struct {
+	// TCPIdleTimeoutMs is the number of milliseconds after which a TCP
+	// connection without any traffic is closed. Envoy defaults to 1 hour, and 0
+	// disables the timeout. It only applies to the tcp protocol.
+	TCPIdleTimeoutMs *int `json:",omitempty" alias:"tcp_idle_timeout_ms"`
+
+	// HTTPIdleTimeoutMs is the number of milliseconds after which an HTTP
+	// connection without active requests is closed. Envoy defaults to 1 hour,
+	// and 0 disables the timeout.
+	HTTPIdleTimeoutMs *int `json:",omitempty" alias:"http_idle_timeout_ms"`
+
+	// MaxRequestsPerConnection is the maximum number of HTTP requests sent over
+	// a connection before it is closed. Unlimited if not set.
+	MaxRequestsPerConnection *int `json:",omitempty" alias:"max_requests_per_connection"`
+
+	// MaxConcurrentStreams is the maximum number of concurrent streams of an
+	// HTTP/2 connection. It only applies to the http2 and grpc protocols.
+	MaxConcurrentStreams *int `json:",omitempty" alias:"max_concurrent_streams"`
+
+	// TCPKeepalive enables TCP keepalives on the connections when set.
+	TCPKeepalive *TCPKeepaliveConfig `json:",omitempty" alias:"tcp_keepalive"`
+}
+
+// TCPKeepaliveConfig configures the TCP keepalives of a connection. Zero
+// values keep the defaults of the operating system.
+type TCPKeepaliveConfig struct {
+	// Probes is the number of unanswered probes after which the connection is
+	// considered dead.
+	Probes uint32 `json:",omitempty"`
+
+	// TimeSeconds is the number of seconds a connection needs to be idle before
+	// probes are sent.
+	TimeSeconds uint32 `json:",omitempty" alias:"time_seconds"`
+
+	// IntervalSeconds is the number of seconds between probes.
+	IntervalSeconds uint32 `json:",omitempty" alias:"interval_seconds"`
+}
+
+func (c *ConnectionPoolConfig) Clone() *ConnectionPoolConfig {
+	if c == nil {
+		return nil
+	}
+	c2 := &ConnectionPoolConfig{
+		TCPIdleTimeoutMs:         intPointerCopy(c.TCPIdleTimeoutMs),
+		HTTPIdleTimeoutMs:        intPointerCopy(c.HTTPIdleTimeoutMs),
+		MaxRequestsPerConnection: intPointerCopy(c.MaxRequestsPerConnection),
+		MaxConcurrentStreams:     intPointerCopy(c.MaxConcurrentStreams),
+	}
+	if c.TCPKeepalive != nil {
+		keepalive := *c.TCPKeepalive
+		c2.TCPKeepalive = &keepalive
+	}
+	return c2
+}
+
+func (c ConnectionPoolConfig) Validate() error {
+	if c.TCPIdleTimeoutMs != nil && *c.TCPIdleTimeoutMs < 0 {
+		return fmt.Errorf("tcp idle timeout cannot be negative")
+	}
+	if c.HTTPIdleTimeoutMs != nil && *c.HTTPIdleTimeoutMs < 0 {
+		return fmt.Errorf("http idle timeout cannot be negative")
+	}
+	if c.MaxRequestsPerConnection != nil && *c.MaxRequestsPerConnection < 0 {
+		return fmt.Errorf("max requests per connection cannot be negative")
+	}
+	if c.MaxConcurrentStreams != nil && *c.MaxConcurrentStreams < 1 {
+		return fmt.Errorf("max concurrent streams must be at least 1")
+	}
+	return nil
+}
+
 type OpaqueUpstreamConfig struct {
 	Upstream ServiceID
 	Config   map[string]interface{}
@@ -995,6 +1654,7 @@ type ServiceConfigResponse struct {
 	Expose            ExposeConfig           `json:",omitempty"`
 	TransparentProxy  TransparentProxyConfig `json:",omitempty"`
 	Mode              ProxyMode              `json:",omitempty"`
+	Destination       DestinationConfig      `json:",omitempty"`
 	Meta              map[string]string      `json:",omitempty"`
 	QueryMeta
 }
@@ -11,6 +11,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/hashicorp/consul-net-rpc/go-msgpack/codec"
 	"github.com/hashicorp/go-bexpr"
 	"github.com/mitchellh/copystructure"
 	"github.com/mitchellh/hashstructure"
@@ -122,6 +123,16 @@ func (e *ServiceRouterConfigEntry) Normalize() error {
 		if route.Destination != nil && route.Destination.Partition == "" {
 			route.Destination.Partition = e.EnterpriseMeta.PartitionOrEmpty()
 		}
+
+		if route.Destination != nil && route.Destination.Mirror != nil {
+			mirror := route.Destination.Mirror
+			if mirror.Namespace == "" {
+				mirror.Namespace = e.EnterpriseMeta.NamespaceOrEmpty()
+			}
+			if mirror.Partition == "" {
+				mirror.Partition = e.EnterpriseMeta.PartitionOrEmpty()
+			}
+		}
 	}
 
 	return nil
@@ -142,6 +153,21 @@ func (e *ServiceRouterConfigEntry) Validate() error {
 
 	for i, route := range e.Routes {
 		eligibleForPrefixRewrite := false
+		if route.Match != nil && route.Match.HTTP != nil && route.Match.GRPC != nil {
+			return fmt.Errorf("Route[%d] should only contain one of HTTP or GRPC match criteria", i)
+		}
+		if route.Match != nil && route.Match.GRPC != nil {
+			grpcMatch := route.Match.GRPC
+			if grpcMatch.Method != "" && grpcMatch.Service == "" {
+				return fmt.Errorf("Route[%d] GRPC Method requires a Service", i)
+			}
+			if strings.Contains(grpcMatch.Service, "/") || strings.Contains(grpcMatch.Method, "/") {
+				return fmt.Errorf("Route[%d] GRPC Service and Method cannot contain '/'", i)
+			}
+			if err := validateRouteMatchHeaders(i, grpcMatch.Header); err != nil {
+				return err
+			}
+		}
 		if route.Match != nil && route.Match.HTTP != nil {
 			pathParts := 0
 			if route.Match.HTTP.PathExact != "" {
@@ -165,29 +191,8 @@ func (e *ServiceRouterConfigEntry) Validate() error {
 				return fmt.Errorf("Route[%d] should only contain at most one of PathExact, PathPrefix, or PathRegex", i)
 			}
 
-			for j, hdr := range route.Match.HTTP.Header {
-				if hdr.Name == "" {
-					return fmt.Errorf("Route[%d] Header[%d] missing required Name field", i, j)
-				}
-				hdrParts := 0
-				if hdr.Present {
-					hdrParts++
-				}
-				if hdr.Exact != "" {
-					hdrParts++
-				}
-				if hdr.Regex != "" {
-					hdrParts++
-				}
-				if hdr.Prefix != "" {
-					hdrParts++
-				}
-				if hdr.Suffix != "" {
-					hdrParts++
-				}
-				if hdrParts != 1 {
-					return fmt.Errorf("Route[%d] Header[%d] should only contain one of Present, Exact, Prefix, Suffix, or Regex", i, j)
-				}
+			if err := validateRouteMatchHeaders(i, route.Match.HTTP.Header); err != nil {
+				return err
 			}
 
 			for j, qm := range route.Match.HTTP.QueryParam {
@@ -228,12 +233,95 @@ func (e *ServiceRouterConfigEntry) Validate() error {
 			if route.Destination.PrefixRewrite != "" && !eligibleForPrefixRewrite {
 				return fmt.Errorf("Route[%d] cannot make use of PrefixRewrite without configuring either PathExact or PathPrefix", i)
 			}
+
+			if route.Destination.FaultInjection != nil {
+				if err := route.Destination.FaultInjection.validate(); err != nil {
+					return fmt.Errorf("Route[%d] FaultInjection: %v", i, err)
+				}
+			}
+
+			if route.Destination.Mirror != nil {
+				if err := validateRoutePercentage(route.Destination.Mirror.Percentage); err != nil {
+					return fmt.Errorf("Route[%d] Mirror %v", i, err)
+				}
+			}
+
+			if route.Destination.PerTryTimeout < 0 {
+				return fmt.Errorf("Route[%d] PerTryTimeout cannot be negative", i)
+			}
+
+			for _, code := range route.Destination.RetryOnGRPCStatusCodes {
+				if _, ok := grpcRetryStatusCodes[code]; !ok {
+					return fmt.Errorf("Route[%d] RetryOnGRPCStatusCodes contains an unsupported status code %q", i, code)
+				}
+			}
 		}
 	}
 
 	return nil
 }
 
+// ValidateForProtocol checks the routes of the router against the protocol
+// of its service, once it is known. gRPC specific match criteria and retries
+// are only valid for the grpc protocol.
+func (e *ServiceRouterConfigEntry) ValidateForProtocol(protocol string) error {
+	if protocol == "grpc" {
+		return nil
+	}
+	for i, route := range e.Routes {
+		if route.Match != nil && route.Match.GRPC != nil {
+			return fmt.Errorf("Route[%d] GRPC match criteria are only valid for the grpc protocol, not %q", i, protocol)
+		}
+		if route.Destination != nil && len(route.Destination.RetryOnGRPCStatusCodes) > 0 {
+			return fmt.Errorf("Route[%d] RetryOnGRPCStatusCodes is only valid for the grpc protocol, not %q", i, protocol)
+		}
+	}
+	return nil
+}
+
+func validateRouteMatchHeaders(route int, headers []ServiceRouteHTTPMatchHeader) error {
+	for j, hdr := range headers {
+		if hdr.Name == "" {
+			return fmt.Errorf("Route[%d] Header[%d] missing required Name field", route, j)
+		}
+		hdrParts := 0
+		if hdr.Present {
+			hdrParts++
+		}
+		if hdr.Exact != "" {
+			hdrParts++
+		}
+		if hdr.Regex != "" {
+			hdrParts++
+		}
+		if hdr.Prefix != "" {
+			hdrParts++
+		}
+		if hdr.Suffix != "" {
+			hdrParts++
+		}
+		if hdrParts != 1 {
+			return fmt.Errorf("Route[%d] Header[%d] should only contain one of Present, Exact, Prefix, Suffix, or Regex", route, j)
+		}
+	}
+	return nil
+}
+
+// grpcRetryStatusCodes are the gRPC status codes requests can be retried on,
+// mapped to the matching Envoy retry condition.
+var grpcRetryStatusCodes = map[string]string{
+	"CANCELLED":          "cancelled",
+	"DEADLINE_EXCEEDED":  "deadline-exceeded",
+	"INTERNAL":           "internal",
+	"RESOURCE_EXHAUSTED": "resource-exhausted",
+	"UNAVAILABLE":        "unavailable",
+}
+
+// GRPCRetryOn returns the Envoy retry condition of a gRPC status code.
+func GRPCRetryOn(code string) string {
+	return grpcRetryStatusCodes[code]
+}
+
 func isValidHTTPMethod(method string) bool {
 	switch method {
 	case http.MethodGet,
@@ -280,6 +368,13 @@ func (e *ServiceRouterConfigEntry) ListRelatedServices() []ServiceID {
 			if destID != svcID {
 				found[destID] = struct{}{}
 			}
+
+			if mirror := route.Destination.Mirror; mirror != nil {
+				mirrorID := NewServiceID(defaultIfEmpty(mirror.Service, e.Name), mirror.GetEnterpriseMeta(&e.EnterpriseMeta))
+				if mirrorID != svcID {
+					found[mirrorID] = struct{}{}
+				}
+			}
 		}
 	}
 
@@ -317,12 +412,17 @@ type ServiceRoute struct {
 type ServiceRouteMatch struct {
 	HTTP *ServiceRouteHTTPMatch `json:",omitempty"`
 
+	// GRPC matches gRPC requests by service and method. Only one of HTTP or
+	// GRPC may be set.
+	GRPC *ServiceRouteGRPCMatch `json:",omitempty"`
+
 	// If we have non-http match criteria for other protocols in the future
-	// (gRPC, redis, etc) they can go here.
+	// (redis, etc) they can go here.
 }
 
 func (m *ServiceRouteMatch) IsEmpty() bool {
-	return m.HTTP == nil || m.HTTP.IsEmpty()
+	return (m.HTTP == nil || m.HTTP.IsEmpty()) &&
+		(m.GRPC == nil || m.GRPC.IsEmpty())
 }
 
 // ServiceRouteHTTPMatch is a set of http-specific match criteria.
@@ -345,6 +445,26 @@ func (m *ServiceRouteHTTPMatch) IsEmpty() bool {
 		len(m.Methods) == 0
 }
 
+// ServiceRouteGRPCMatch is a set of gRPC-specific match criteria.
+type ServiceRouteGRPCMatch struct {
+	// Service is the fully qualified name of the gRPC service, for example
+	// "helloworld.Greeter". All the methods of the service match if Method is
+	// empty.
+	Service string `json:",omitempty"`
+
+	// Method is the name of the method of the Service.
+	Method string `json:",omitempty"`
+
+	// Header matches the request metadata.
+	Header []ServiceRouteHTTPMatchHeader `json:",omitempty"`
+}
+
+func (m *ServiceRouteGRPCMatch) IsEmpty() bool {
+	return m.Service == "" &&
+		m.Method == "" &&
+		len(m.Header) == 0
+}
+
 type ServiceRouteHTTPMatchHeader struct {
 	Name    string
 	Present bool   `json:",omitempty"`
@@ -413,23 +533,44 @@ type ServiceRouteDestination struct {
 	// eligible for retry. This again should be feasible in any reasonable proxy.
 	RetryOnStatusCodes []uint32 `json:",omitempty" alias:"retry_on_status_codes"`
 
+	// RetryOnGRPCStatusCodes is a list of gRPC status codes that are eligible
+	// for retry, for example "UNAVAILABLE". Only valid for the grpc protocol.
+	RetryOnGRPCStatusCodes []string `json:",omitempty" alias:"retry_on_grpc_status_codes"`
+
+	// PerTryTimeout is the amount of time permitted for each attempt of the
+	// request, including the first one.
+	PerTryTimeout time.Duration `json:",omitempty" alias:"per_try_timeout"`
+
 	// Allow HTTP header manipulation to be configured.
 	RequestHeaders  *HTTPHeaderModifiers `json:",omitempty" alias:"request_headers"`
 	ResponseHeaders *HTTPHeaderModifiers `json:",omitempty" alias:"response_headers"`
+
+	// FaultInjection delays or aborts a percentage of the requests matching
+	// this route, for testing how downstream services handle failures.
+	FaultInjection *ServiceRouteFaultInjection `json:",omitempty" alias:"fault_injection"`
+
+	// Mirror sends a copy of a percentage of the requests matching this route
+	// to another service. Responses from the mirror are discarded.
+	Mirror *ServiceRouteMirror `json:",omitempty"`
 }
 
 func (e *ServiceRouteDestination) MarshalJSON() ([]byte, error) {
 	type Alias ServiceRouteDestination
 	exported := &struct {
 		RequestTimeout string `json:",omitempty"`
+		PerTryTimeout  string `json:",omitempty"`
 		*Alias
 	}{
 		RequestTimeout: e.RequestTimeout.String(),
+		PerTryTimeout:  e.PerTryTimeout.String(),
 		Alias:          (*Alias)(e),
 	}
 	if e.RequestTimeout == 0 {
 		exported.RequestTimeout = ""
 	}
+	if e.PerTryTimeout == 0 {
+		exported.PerTryTimeout = ""
+	}
 
 	return json.Marshal(exported)
 }
@@ -438,6 +579,7 @@ func (e *ServiceRouteDestination) UnmarshalJSON(data []byte) error {
 	type Alias ServiceRouteDestination
 	aux := &struct {
 		RequestTimeout string
+		PerTryTimeout  string
 		*Alias
 	}{
 		Alias: (*Alias)(e),
@@ -451,11 +593,135 @@ func (e *ServiceRouteDestination) UnmarshalJSON(data []byte) error {
 			return err
 		}
 	}
+	if aux.PerTryTimeout != "" {
+		if e.PerTryTimeout, err = time.ParseDuration(aux.PerTryTimeout); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
 func (d *ServiceRouteDestination) HasRetryFeatures() bool {
-	return d.NumRetries > 0 || d.RetryOnConnectFailure || len(d.RetryOnStatusCodes) > 0
+	return d.NumRetries > 0 || d.RetryOnConnectFailure || len(d.RetryOnStatusCodes) > 0 ||
+		len(d.RetryOnGRPCStatusCodes) > 0 || d.PerTryTimeout > 0
+}
+
+// ServiceRouteFaultInjection describes the faults injected into requests
+// matching a route. At least one of Delay or Abort must be set.
+type ServiceRouteFaultInjection struct {
+	// Delay holds requests for a fixed duration before forwarding them.
+	Delay *ServiceRouteFaultDelay `json:",omitempty"`
+
+	// Abort responds to requests with an error status instead of forwarding
+	// them.
+	Abort *ServiceRouteFaultAbort `json:",omitempty"`
+}
+
+// ServiceRouteFaultDelay delays a percentage of requests.
+type ServiceRouteFaultDelay struct {
+	// Duration is how long each affected request is delayed.
+	Duration time.Duration `json:",omitempty"`
+
+	// Percentage of requests to delay, from 0.01 to 100.
+	Percentage float32 `json:",omitempty"`
+}
+
+func (d *ServiceRouteFaultDelay) MarshalJSON() ([]byte, error) {
+	type Alias ServiceRouteFaultDelay
+	exported := &struct {
+		Duration string `json:",omitempty"`
+		*Alias
+	}{
+		Duration: d.Duration.String(),
+		Alias:    (*Alias)(d),
+	}
+	if d.Duration == 0 {
+		exported.Duration = ""
+	}
+
+	return json.Marshal(exported)
+}
+
+func (d *ServiceRouteFaultDelay) UnmarshalJSON(data []byte) error {
+	type Alias ServiceRouteFaultDelay
+	aux := &struct {
+		Duration string
+		*Alias
+	}{
+		Alias: (*Alias)(d),
+	}
+	if err := lib.UnmarshalJSON(data, &aux); err != nil {
+		return err
+	}
+	var err error
+	if aux.Duration != "" {
+		if d.Duration, err = time.ParseDuration(aux.Duration); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ServiceRouteFaultAbort aborts a percentage of requests.
+type ServiceRouteFaultAbort struct {
+	// HTTPStatus is the status code returned for each affected request.
+	HTTPStatus uint32 `json:",omitempty" alias:"http_status"`
+
+	// Percentage of requests to abort, from 0.01 to 100.
+	Percentage float32 `json:",omitempty"`
+}
+
+// ServiceRouteMirror describes where a copy of the requests matching a route
+// is sent.
+type ServiceRouteMirror struct {
+	// Service is the service to mirror requests to. If empty the service of
+	// the router is used.
+	Service string `json:",omitempty"`
+
+	// ServiceSubset is a named subset of the given service to mirror requests
+	// to. If empty the default subset is used.
+	ServiceSubset string `json:",omitempty" alias:"service_subset"`
+
+	// Namespace is the namespace to resolve the service from instead of the
+	// current namespace. If empty the current namespace is assumed.
+	Namespace string `json:",omitempty"`
+
+	// Partition is the partition to resolve the service from instead of the
+	// current partition. If empty the current partition is assumed.
+	Partition string `json:",omitempty"`
+
+	// Percentage of requests to mirror, from 0.01 to 100.
+	Percentage float32 `json:",omitempty"`
+}
+
+func validateRoutePercentage(p float32) error {
+	if p < 0.01 || p > 100 {
+		return fmt.Errorf("Percentage must be between 0.01 and 100, got %v", p)
+	}
+	return nil
+}
+
+func (f *ServiceRouteFaultInjection) validate() error {
+	if f.Delay == nil && f.Abort == nil {
+		return fmt.Errorf("at least one of Delay or Abort is required")
+	}
+	if f.Delay != nil {
+		if f.Delay.Duration <= 0 {
+			return fmt.Errorf("Delay Duration must be greater than zero")
+		}
+		if err := validateRoutePercentage(f.Delay.Percentage); err != nil {
+			return fmt.Errorf("Delay %v", err)
+		}
+	}
+	if f.Abort != nil {
+		if f.Abort.HTTPStatus < 200 || f.Abort.HTTPStatus > 599 {
+			return fmt.Errorf("Abort HTTPStatus must be between 200 and 599, got %d", f.Abort.HTTPStatus)
+		}
+		if err := validateRoutePercentage(f.Abort.Percentage); err != nil {
+			return fmt.Errorf("Abort %v", err)
+		}
+	}
+	return nil
 }
 
 // ServiceSplitterConfigEntry defines how incoming requests are split across
@@ -815,6 +1081,16 @@ type ServiceResolverConfigEntry struct {
 	// issuing requests to this upstream service.
 	LoadBalancer *LoadBalancer `json:",omitempty" alias:"load_balancer"`
 
+	// OutlierDetection determines when the proxies of downstream services
+	// eject unhealthy instances of this service from their load balancing
+	// pool.
+	OutlierDetection *OutlierDetection `json:",omitempty" alias:"outlier_detection"`
+
+	// PrioritizeByLocality determines whether the proxies of downstream
+	// services prefer the instances of this service running in their own
+	// zone and region.
+	PrioritizeByLocality *ServiceResolverPrioritizeByLocality `json:",omitempty" alias:"prioritize_by_locality"`
+
 	Meta               map[string]string `json:",omitempty"`
 	acl.EnterpriseMeta `hcl:",squash" mapstructure:",squash"`
 	RaftIndex
@@ -873,7 +1149,9 @@ func (e *ServiceResolverConfigEntry) IsDefault() bool {
 		e.Redirect == nil &&
 		len(e.Failover) == 0 &&
 		e.ConnectTimeout == 0 &&
-		e.LoadBalancer == nil
+		e.LoadBalancer == nil &&
+		e.OutlierDetection == nil &&
+		e.PrioritizeByLocality == nil
 }
 
 func (e *ServiceResolverConfigEntry) GetKind() string {
@@ -1066,6 +1344,20 @@ func (e *ServiceResolverConfigEntry) Validate() error {
 		}
 	}
 
+	if e.OutlierDetection != nil {
+		if err := e.OutlierDetection.Validate(); err != nil {
+			return fmt.Errorf("Bad OutlierDetection: %v", err)
+		}
+	}
+
+	if e.PrioritizeByLocality != nil {
+		switch e.PrioritizeByLocality.Mode {
+		case "", LocalityPriorityModeNone, LocalityPriorityModeFailover:
+		default:
+			return fmt.Errorf("Bad PrioritizeByLocality: unknown mode %q", e.PrioritizeByLocality.Mode)
+		}
+	}
+
 	return nil
 }
 
@@ -1207,6 +1499,30 @@ type ServiceResolverFailover struct {
 	Datacenters []string `json:",omitempty"`
 }
 
+const (
+	// LocalityPriorityModeNone load balances across all the instances of a
+	// service regardless of their locality.
+	LocalityPriorityModeNone = "none"
+
+	// LocalityPriorityModeFailover sends requests to the instances in the
+	// zone of the downstream first, then to the rest of its region, then to
+	// every other instance.
+	LocalityPriorityModeFailover = "failover"
+)
+
+// ServiceResolverPrioritizeByLocality configures locality aware load balancing
+// using the locality-region and locality-zone service or node meta of the
+// instances and of the downstream proxy.
+type ServiceResolverPrioritizeByLocality struct {
+	// Mode is either "none", the default, or "failover".
+	Mode string `json:",omitempty"`
+}
+
+// IsEnabled returns true if instances should be prioritized by locality.
+func (p *ServiceResolverPrioritizeByLocality) IsEnabled() bool {
+	return p != nil && p.Mode == LocalityPriorityModeFailover
+}
+
 // LoadBalancer determines the load balancing policy and configuration for services
 // issuing requests to this upstream service.
 type LoadBalancer struct {
@@ -1227,6 +1543,68 @@ type LoadBalancer struct {
 	HashPolicies []HashPolicy `json:",omitempty" alias:"hash_policies"`
 }
 
+// OutlierDetection determines when the proxies of downstream services eject
+// unhealthy instances of a service from their load balancing pool. Zero values
+// use the defaults of Envoy.
+type OutlierDetection struct {
+	// Interval between ejection analysis sweeps. Defaults to 10s.
+	Interval time.Duration `json:",omitempty"`
+
+	// Consecutive5xx is the number of consecutive 5xx responses, or connection
+	// failures for TCP services, after which an instance is ejected. Defaults
+	// to 5.
+	Consecutive5xx uint32 `json:",omitempty" alias:"consecutive_5xx"`
+
+	// ConsecutiveGatewayFailure is the number of consecutive 502, 503 or 504
+	// responses, or connection failures, after which an instance is ejected.
+	// Disabled if zero.
+	ConsecutiveGatewayFailure uint32 `json:",omitempty" alias:"consecutive_gateway_failure"`
+
+	// SuccessRate ejects the instances whose success rate is too low compared
+	// to the other instances. Disabled if nil.
+	SuccessRate *OutlierDetectionSuccessRate `json:",omitempty" alias:"success_rate"`
+
+	// BaseEjectionTime is how long an instance is ejected for, multiplied by
+	// the number of times it was ejected. Defaults to 30s.
+	BaseEjectionTime time.Duration `json:",omitempty" alias:"base_ejection_time"`
+
+	// MaxEjectionPercent is the maximum percentage of the instances that can
+	// be ejected at once. Defaults to 10.
+	MaxEjectionPercent uint32 `json:",omitempty" alias:"max_ejection_percent"`
+}
+
+// OutlierDetectionSuccessRate contains configuration for the success rate
+// based ejection of instances.
+type OutlierDetectionSuccessRate struct {
+	// MinimumHosts is the number of instances that must have enough requests
+	// for success rates to be computed. Defaults to 5.
+	MinimumHosts uint32 `json:",omitempty" alias:"minimum_hosts"`
+
+	// RequestVolume is the number of requests an instance must receive during
+	// an interval to be included. Defaults to 100.
+	RequestVolume uint32 `json:",omitempty" alias:"request_volume"`
+
+	// StdevFactor ejects the instances whose success rate is below the mean
+	// minus StdevFactor times the standard deviation. Defaults to 1.9.
+	StdevFactor float64 `json:",omitempty" alias:"stdev_factor"`
+}
+
+func (od *OutlierDetection) Validate() error {
+	if od.Interval < 0 {
+		return fmt.Errorf("Interval cannot be negative")
+	}
+	if od.BaseEjectionTime < 0 {
+		return fmt.Errorf("BaseEjectionTime cannot be negative")
+	}
+	if od.MaxEjectionPercent > 100 {
+		return fmt.Errorf("MaxEjectionPercent must be between 0 and 100")
+	}
+	if od.SuccessRate != nil && od.SuccessRate.StdevFactor < 0 {
+		return fmt.Errorf("SuccessRate.StdevFactor cannot be negative")
+	}
+	return nil
+}
+
 // RingHashConfig contains configuration for the "ring_hash" policy type
 type RingHashConfig struct {
 	// MinimumRingSize determines the minimum number of entries in the hash ring
@@ -1411,6 +1789,102 @@ type DiscoveryChainResponse struct {
 	QueryMeta
 }
 
+// DiscoveryChainSimulateRequest is used to compile the discovery chain for a
+// service as if a set of config entries had been written, without writing
+// them.
+type DiscoveryChainSimulateRequest struct {
+	DiscoveryChainRequest
+
+	// Entries are overlaid on top of the config entries currently stored
+	// before the chain is compiled. Only the kinds that make up a discovery
+	// chain are accepted: proxy-defaults, service-defaults, service-router,
+	// service-splitter and service-resolver.
+	Entries []ConfigEntry
+}
+
+func (r *DiscoveryChainSimulateRequest) MarshalBinary() (data []byte, err error) {
+	// bs will grow if needed but allocate enough to avoid reallocation in common
+	// case.
+	bs := make([]byte, 128)
+	enc := codec.NewEncoderBytes(&bs, MsgpackHandle)
+
+	if err := enc.Encode(r.DiscoveryChainRequest); err != nil {
+		return nil, err
+	}
+
+	if err := enc.Encode(len(r.Entries)); err != nil {
+		return nil, err
+	}
+
+	for _, entry := range r.Entries {
+		if err := enc.Encode(entry.GetKind()); err != nil {
+			return nil, err
+		}
+		if err := enc.Encode(entry); err != nil {
+			return nil, err
+		}
+	}
+
+	return bs, nil
+}
+
+func (r *DiscoveryChainSimulateRequest) UnmarshalBinary(data []byte) error {
+	dec := codec.NewDecoderBytes(data, MsgpackHandle)
+	if err := dec.Decode(&r.DiscoveryChainRequest); err != nil {
+		return err
+	}
+
+	// Then decode the slice of ConfigEntries
+	var numEntries int
+	if err := dec.Decode(&numEntries); err != nil {
+		return err
+	}
+
+	r.Entries = make([]ConfigEntry, numEntries)
+	for i := 0; i < numEntries; i++ {
+		var kind string
+		if err := dec.Decode(&kind); err != nil {
+			return err
+		}
+
+		entry, err := MakeConfigEntry(kind, "")
+		if err != nil {
+			return err
+		}
+
+		if err := dec.Decode(entry); err != nil {
+			return err
+		}
+
+		r.Entries[i] = entry
+	}
+
+	return nil
+}
+
+// DiscoveryChainSimulateResponse is the result of compiling a discovery chain
+// against a set of config entries that have not been written.
+type DiscoveryChainSimulateResponse struct {
+	// Chain is the discovery chain compiled with the simulated entries.
+	Chain *CompiledDiscoveryChain
+
+	// CurrentChain is the discovery chain compiled with only the config
+	// entries currently stored.
+	CurrentChain *CompiledDiscoveryChain
+
+	// Explain is a human readable tree of the routes, splits, resolvers and
+	// failover targets of Chain.
+	Explain string
+
+	// Diff is a line based diff from the explanation of CurrentChain to
+	// Explain. Added lines are prefixed with "+ ", removed lines with "- " and
+	// unchanged lines with two spaces. It is empty if the chains are
+	// equivalent.
+	Diff string
+
+	QueryMeta
+}
+
 type ConfigEntryGraphError struct {
 	// one of Message or Err should be set
 	Message string
@@ -1152,6 +1152,75 @@ func TestServiceResolverConfigEntry_LoadBalancer(t *testing.T) {
 	}
 }
 
+func TestServiceResolverConfigEntry_OutlierDetection(t *testing.T) {
+	cases := []struct {
+		name        string
+		od          *OutlierDetection
+		validateErr string
+	}{
+		{
+			name: "empty",
+			od:   &OutlierDetection{},
+		},
+		{
+			name: "kitchen sink",
+			od: &OutlierDetection{
+				Interval:                  5 * time.Second,
+				Consecutive5xx:            3,
+				ConsecutiveGatewayFailure: 2,
+				SuccessRate: &OutlierDetectionSuccessRate{
+					MinimumHosts:  3,
+					RequestVolume: 50,
+					StdevFactor:   1.5,
+				},
+				BaseEjectionTime:   time.Minute,
+				MaxEjectionPercent: 50,
+			},
+		},
+		{
+			name:        "negative interval",
+			od:          &OutlierDetection{Interval: -1 * time.Second},
+			validateErr: "Bad OutlierDetection: Interval cannot be negative",
+		},
+		{
+			name:        "negative base ejection time",
+			od:          &OutlierDetection{BaseEjectionTime: -1 * time.Second},
+			validateErr: "Bad OutlierDetection: BaseEjectionTime cannot be negative",
+		},
+		{
+			name:        "max ejection percent too high",
+			od:          &OutlierDetection{MaxEjectionPercent: 101},
+			validateErr: "Bad OutlierDetection: MaxEjectionPercent must be between 0 and 100",
+		},
+		{
+			name:        "negative stdev factor",
+			od:          &OutlierDetection{SuccessRate: &OutlierDetectionSuccessRate{StdevFactor: -1}},
+			validateErr: "Bad OutlierDetection: SuccessRate.StdevFactor cannot be negative",
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			entry := &ServiceResolverConfigEntry{
+				Kind:             ServiceResolver,
+				Name:             "test",
+				OutlierDetection: tc.od,
+			}
+			require.NoError(t, entry.Normalize())
+			require.False(t, entry.IsDefault())
+
+			err := entry.Validate()
+			if tc.validateErr != "" {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tc.validateErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
 func TestServiceSplitterConfigEntry(t *testing.T) {
 
 	makesplitter := func(splits ...ServiceSplit) *ServiceSplitterConfigEntry {
@@ -1879,6 +1948,158 @@ func TestServiceRouterConfigEntry(t *testing.T) {
 			}))),
 			validateErr: "Methods contains \"GET\" more than once",
 		},
+		////////////////
+		{
+			name: "route with fault injection",
+			entry: makerouter(ServiceRoute{
+				Destination: &ServiceRouteDestination{
+					Service: "other",
+					FaultInjection: &ServiceRouteFaultInjection{
+						Delay: &ServiceRouteFaultDelay{Duration: time.Second, Percentage: 50},
+						Abort: &ServiceRouteFaultAbort{HTTPStatus: 503, Percentage: 0.01},
+					},
+				},
+			}),
+		},
+		{
+			name: "route with empty fault injection",
+			entry: makerouter(ServiceRoute{
+				Destination: &ServiceRouteDestination{
+					Service:        "other",
+					FaultInjection: &ServiceRouteFaultInjection{},
+				},
+			}),
+			validateErr: "Route[0] FaultInjection: at least one of Delay or Abort is required",
+		},
+		{
+			name: "route with fault injection delay without duration",
+			entry: makerouter(ServiceRoute{
+				Destination: &ServiceRouteDestination{
+					Service: "other",
+					FaultInjection: &ServiceRouteFaultInjection{
+						Delay: &ServiceRouteFaultDelay{Percentage: 50},
+					},
+				},
+			}),
+			validateErr: "Delay Duration must be greater than zero",
+		},
+		{
+			name: "route with fault injection abort with invalid status",
+			entry: makerouter(ServiceRoute{
+				Destination: &ServiceRouteDestination{
+					Service: "other",
+					FaultInjection: &ServiceRouteFaultInjection{
+						Abort: &ServiceRouteFaultAbort{HTTPStatus: 99, Percentage: 50},
+					},
+				},
+			}),
+			validateErr: "Abort HTTPStatus must be between 200 and 599, got 99",
+		},
+		{
+			name: "route with fault injection abort without percentage",
+			entry: makerouter(ServiceRoute{
+				Destination: &ServiceRouteDestination{
+					Service: "other",
+					FaultInjection: &ServiceRouteFaultInjection{
+						Abort: &ServiceRouteFaultAbort{HTTPStatus: 503},
+					},
+				},
+			}),
+			validateErr: "Abort Percentage must be between 0.01 and 100, got 0",
+		},
+		{
+			name: "route with mirror",
+			entry: makerouter(ServiceRoute{
+				Destination: &ServiceRouteDestination{
+					Service: "other",
+					Mirror:  &ServiceRouteMirror{Service: "shadow", Percentage: 10},
+				},
+			}),
+			check: func(t *testing.T, entry *ServiceRouterConfigEntry) {
+				mirror := entry.Routes[0].Destination.Mirror
+				require.Equal(t, entry.NamespaceOrEmpty(), mirror.Namespace)
+				require.Equal(t, entry.PartitionOrEmpty(), mirror.Partition)
+				require.Contains(t, entry.ListRelatedServices(), NewServiceID("shadow", &entry.EnterpriseMeta))
+			},
+		},
+		{
+			name: "route with mirror percentage too high",
+			entry: makerouter(ServiceRoute{
+				Destination: &ServiceRouteDestination{
+					Service: "other",
+					Mirror:  &ServiceRouteMirror{Service: "shadow", Percentage: 101},
+				},
+			}),
+			validateErr: "Route[0] Mirror Percentage must be between 0.01 and 100, got 101",
+		},
+		////////////////
+		{
+			name: "route with grpc match and retries",
+			entry: makerouter(ServiceRoute{
+				Match: &ServiceRouteMatch{GRPC: &ServiceRouteGRPCMatch{
+					Service: "helloworld.Greeter",
+					Method:  "SayHello",
+					Header:  []ServiceRouteHTTPMatchHeader{{Name: "x-debug", Exact: "1"}},
+				}},
+				Destination: &ServiceRouteDestination{
+					Service:                "other",
+					RetryOnGRPCStatusCodes: []string{"UNAVAILABLE", "RESOURCE_EXHAUSTED"},
+					PerTryTimeout:          time.Second,
+				},
+			}),
+		},
+		{
+			name: "route with both http and grpc match",
+			entry: makerouter(routeMatch(&ServiceRouteMatch{
+				HTTP: &ServiceRouteHTTPMatch{PathPrefix: "/"},
+				GRPC: &ServiceRouteGRPCMatch{Service: "helloworld.Greeter"},
+			})),
+			validateErr: "Route[0] should only contain one of HTTP or GRPC match criteria",
+		},
+		{
+			name: "route with grpc method without service",
+			entry: makerouter(routeMatch(&ServiceRouteMatch{
+				GRPC: &ServiceRouteGRPCMatch{Method: "SayHello"},
+			})),
+			validateErr: "Route[0] GRPC Method requires a Service",
+		},
+		{
+			name: "route with grpc service path",
+			entry: makerouter(routeMatch(&ServiceRouteMatch{
+				GRPC: &ServiceRouteGRPCMatch{Service: "/helloworld.Greeter"},
+			})),
+			validateErr: "Route[0] GRPC Service and Method cannot contain '/'",
+		},
+		{
+			name: "route with grpc header missing name",
+			entry: makerouter(routeMatch(&ServiceRouteMatch{
+				GRPC: &ServiceRouteGRPCMatch{
+					Service: "helloworld.Greeter",
+					Header:  []ServiceRouteHTTPMatchHeader{{Exact: "1"}},
+				},
+			})),
+			validateErr: "Route[0] Header[0] missing required Name field",
+		},
+		{
+			name: "route with unsupported grpc retry status code",
+			entry: makerouter(ServiceRoute{
+				Destination: &ServiceRouteDestination{
+					Service:                "other",
+					RetryOnGRPCStatusCodes: []string{"NOT_FOUND"},
+				},
+			}),
+			validateErr: `Route[0] RetryOnGRPCStatusCodes contains an unsupported status code "NOT_FOUND"`,
+		},
+		{
+			name: "route with negative per try timeout",
+			entry: makerouter(ServiceRoute{
+				Destination: &ServiceRouteDestination{
+					Service:       "other",
+					PerTryTimeout: -time.Second,
+				},
+			}),
+			validateErr: "Route[0] PerTryTimeout cannot be negative",
+		},
 	}
 
 	for _, tc := range cases {
@@ -1907,6 +2128,28 @@ func TestServiceRouterConfigEntry(t *testing.T) {
 	}
 }
 
+func TestServiceRouterConfigEntry_ValidateForProtocol(t *testing.T) {
+	router := &ServiceRouterConfigEntry{
+		Kind: ServiceRouter,
+		Name: "test",
+		Routes: []ServiceRoute{
+			{
+				Match: &ServiceRouteMatch{GRPC: &ServiceRouteGRPCMatch{Service: "helloworld.Greeter"}},
+			},
+		},
+	}
+	require.NoError(t, router.ValidateForProtocol("grpc"))
+	require.EqualError(t, router.ValidateForProtocol("http2"),
+		`Route[0] GRPC match criteria are only valid for the grpc protocol, not "http2"`)
+
+	router.Routes[0] = ServiceRoute{
+		Destination: &ServiceRouteDestination{RetryOnGRPCStatusCodes: []string{"UNAVAILABLE"}},
+	}
+	require.NoError(t, router.ValidateForProtocol("grpc"))
+	require.EqualError(t, router.ValidateForProtocol("http"),
+		`Route[0] RetryOnGRPCStatusCodes is only valid for the grpc protocol, not "http"`)
+}
+
 var validSubsetNames = []string{
 	"a", "aa", "2a", "a2", "a2a", "a22a",
 	"1", "11", "10", "01",
@@ -1947,3 +2190,70 @@ func TestIsProtocolHTTPLike(t *testing.T) {
 	assert.True(t, IsProtocolHTTPLike("http2"))
 	assert.True(t, IsProtocolHTTPLike("grpc"))
 }
+
+func TestServiceResolverConfigEntry_PrioritizeByLocality(t *testing.T) {
+	cases := []struct {
+		name        string
+		mode        string
+		enabled     bool
+		validateErr string
+	}{
+		{name: "empty"},
+		{name: "none", mode: LocalityPriorityModeNone},
+		{name: "failover", mode: LocalityPriorityModeFailover, enabled: true},
+		{name: "unknown", mode: "nearest", validateErr: `Bad PrioritizeByLocality: unknown mode "nearest"`},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			entry := &ServiceResolverConfigEntry{
+				Kind:                 ServiceResolver,
+				Name:                 "test",
+				PrioritizeByLocality: &ServiceResolverPrioritizeByLocality{Mode: tc.mode},
+			}
+			require.NoError(t, entry.Normalize())
+			require.False(t, entry.IsDefault())
+			require.Equal(t, tc.enabled, entry.PrioritizeByLocality.IsEnabled())
+
+			err := entry.Validate()
+			if tc.validateErr != "" {
+				require.EqualError(t, err, tc.validateErr)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestDiscoveryChainSimulateRequestMarshalling(t *testing.T) {
+	req := DiscoveryChainSimulateRequest{
+		DiscoveryChainRequest: DiscoveryChainRequest{
+			Name:                   "web",
+			EvaluateInDatacenter:   "dc2",
+			OverrideConnectTimeout: 22 * time.Second,
+			Datacenter:             "dc1",
+			QueryOptions:           QueryOptions{Token: "token"},
+		},
+		Entries: []ConfigEntry{
+			&ServiceResolverConfigEntry{
+				Kind:           ServiceResolver,
+				Name:           "web",
+				ConnectTimeout: 10 * time.Second,
+			},
+			&ServiceConfigEntry{
+				Kind:     ServiceDefaults,
+				Name:     "web",
+				Protocol: "http",
+			},
+		},
+	}
+
+	data, err := req.MarshalBinary()
+	require.NoError(t, err)
+	require.NotEmpty(t, data)
+
+	var out DiscoveryChainSimulateRequest
+	require.NoError(t, out.UnmarshalBinary(data))
+	require.Equal(t, req, out)
+}
@@ -35,9 +35,13 @@ type ExportedService struct {
 }
 
 // ServiceConsumer represents a downstream consumer of the service to be exported.
+// Exactly one of Partition or PeerName must be set.
 type ServiceConsumer struct {
 	// Partition is the admin partition to export the service to.
 	Partition string
+
+	// PeerName is the name of the cluster peer to export the service to.
+	PeerName string `json:",omitempty" alias:"peer_name"`
 }
 
 func (e *ExportedServicesConfigEntry) ToMap() map[string]map[string][]string {
@@ -49,6 +53,9 @@ func (e *ExportedServicesConfigEntry) ToMap() map[string]map[string][]string {
 		if _, ok := resp[svc.Namespace][svc.Name]; !ok {
 			consumers := make([]string, 0, len(svc.Consumers))
 			for _, c := range svc.Consumers {
+				if c.Partition == "" {
+					continue
+				}
 				consumers = append(consumers, c.Partition)
 			}
 			resp[svc.Namespace][svc.Name] = consumers
@@ -113,8 +120,12 @@ func (e *ExportedServicesConfigEntry) Validate() error {
 		return fmt.Errorf("exported-services Name must be the name of a partition, and not a wildcard")
 	}
 
-	if err := requireEnterprise(e.GetKind()); err != nil {
-		return err
+	// Exporting services to cluster peers from the default partition is
+	// supported everywhere, anything else requires admin partitions.
+	if e.Name != acl.PartitionOrDefault(e.Name) {
+		if err := requireEnterprise(e.GetKind()); err != nil {
+			return err
+		}
 	}
 	if err := validateConfigEntryMeta(e.Meta); err != nil {
 		return err
@@ -128,9 +139,23 @@ func (e *ExportedServicesConfigEntry) Validate() error {
 			return fmt.Errorf("service %q must have at least one consumer", svc.Name)
 		}
 		for _, consumer := range svc.Consumers {
+			switch {
+			case consumer.Partition != "" && consumer.PeerName != "":
+				return fmt.Errorf("service %q consumers must set either a partition or a peer name, not both", svc.Name)
+			case consumer.Partition == "" && consumer.PeerName == "":
+				return fmt.Errorf("service %q consumers must set a partition or a peer name", svc.Name)
+			}
+			if consumer.Partition != "" {
+				if err := requireEnterprise(e.GetKind()); err != nil {
+					return err
+				}
+			}
 			if consumer.Partition == WildcardSpecifier {
 				return fmt.Errorf("exporting to all partitions (wildcard) is not yet supported")
 			}
+			if consumer.PeerName == WildcardSpecifier {
+				return fmt.Errorf("exporting to all peers (wildcard) is not supported")
+			}
 		}
 	}
 	return nil
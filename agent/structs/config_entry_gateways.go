@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/miekg/dns"
 
@@ -49,10 +50,17 @@ type IngressListener struct {
 	// TLS config for this listener.
 	TLS *GatewayTLSConfig `json:",omitempty"`
 
+	// TLSPassthrough makes a "tcp" listener forward TLS connections to its
+	// services without terminating them. Connections are routed to a service
+	// when their SNI matches one of its Hosts.
+	TLSPassthrough bool `json:",omitempty" alias:"tls_passthrough"`
+
 	// Services declares the set of services to which the listener forwards
 	// traffic.
 	//
-	// For "tcp" protocol listeners, only a single service is allowed.
+	// For "tcp" protocol listeners, only a single service is allowed unless
+	// the listener terminates TLS or uses TLSPassthrough, in which case
+	// connections are routed to a service by SNI.
 	// For "http" listeners, multiple services can be declared.
 	Services []IngressService
 }
@@ -69,16 +77,17 @@ type IngressService struct {
 	Name string
 
 	// Hosts is a list of hostnames which should be associated to this service on
-	// the defined listener. Only allowed on layer 7 protocols, this will be used
-	// to route traffic to the service by matching the Host header of the HTTP
-	// request.
+	// the defined listener. On layer 7 protocols, this will be used to route
+	// traffic to the service by matching the Host header of the HTTP request.
+	// On "tcp" listeners that terminate TLS or use TLSPassthrough, it is
+	// matched against the SNI of the connection instead.
 	//
 	// If a host is provided for a service that also has a wildcard specifier
 	// defined, the host will override the wildcard-specifier-provided
 	// "<service-name>.*" domain for that listener.
 	//
 	// This cannot be specified when using the wildcard specifier, "*", or when
-	// using a "tcp" listener.
+	// using a "tcp" listener that doesn't see the SNI of connections.
 	Hosts []string
 
 	// TLS configuration overrides for this service. At least one entry must exist
@@ -90,10 +99,98 @@ type IngressService struct {
 	RequestHeaders  *HTTPHeaderModifiers `json:",omitempty" alias:"request_headers"`
 	ResponseHeaders *HTTPHeaderModifiers `json:",omitempty" alias:"response_headers"`
 
+	// CORS is the cross-origin resource sharing policy applied to requests
+	// for this service. Only supported on layer 7 listeners.
+	CORS *IngressCORSConfig `json:",omitempty"`
+
+	// MaxRequestBytes limits the size of request bodies sent to this service.
+	// Larger requests are rejected with a 413 status. Zero means no limit. Only
+	// supported on layer 7 listeners.
+	MaxRequestBytes uint32 `json:",omitempty" alias:"max_request_bytes"`
+
+	// JWT requires requests for this service to carry a valid JSON Web Token
+	// issued by one of the given providers. Only supported on layer 7
+	// listeners.
+	JWT *IngressJWTRequirement `json:",omitempty"`
+
 	Meta               map[string]string `json:",omitempty"`
 	acl.EnterpriseMeta `hcl:",squash" mapstructure:",squash"`
 }
 
+// IngressCORSConfig is the cross-origin resource sharing policy of an ingress
+// service.
+type IngressCORSConfig struct {
+	// AllowOrigins are the origins allowed to make cross-origin requests, such
+	// as "https://example.com". The special value "*" allows any origin.
+	AllowOrigins []string `json:",omitempty" alias:"allow_origins"`
+
+	// AllowMethods, AllowHeaders and ExposeHeaders are returned in the
+	// matching Access-Control-* response headers.
+	AllowMethods  []string `json:",omitempty" alias:"allow_methods"`
+	AllowHeaders  []string `json:",omitempty" alias:"allow_headers"`
+	ExposeHeaders []string `json:",omitempty" alias:"expose_headers"`
+
+	// MaxAge is how long browsers may cache the result of a preflight
+	// request.
+	MaxAge time.Duration `json:",omitempty" alias:"max_age"`
+
+	// AllowCredentials allows cross-origin requests to include credentials
+	// such as cookies.
+	AllowCredentials bool `json:",omitempty" alias:"allow_credentials"`
+}
+
+func (c *IngressCORSConfig) Validate() error {
+	if c == nil {
+		return nil
+	}
+	if len(c.AllowOrigins) == 0 {
+		return fmt.Errorf("AllowOrigins must have at least one origin")
+	}
+	for _, origin := range c.AllowOrigins {
+		if origin == WildcardSpecifier {
+			if c.AllowCredentials {
+				return fmt.Errorf("AllowOrigins cannot contain '*' when AllowCredentials is set")
+			}
+			continue
+		}
+		if !strings.Contains(origin, "://") {
+			return fmt.Errorf("origin %q must include a scheme, such as \"https://%s\"", origin, origin)
+		}
+	}
+	if c.MaxAge < 0 {
+		return fmt.Errorf("MaxAge cannot be negative")
+	}
+	return nil
+}
+
+// IngressJWTRequirement lists the JWT providers trusted to issue tokens for an
+// ingress service. A request is accepted if its token is valid for any of
+// them.
+type IngressJWTRequirement struct {
+	Providers []*IngressJWTProvider `json:",omitempty"`
+}
+
+// IngressJWTProvider references a jwt-provider config entry.
+type IngressJWTProvider struct {
+	// Name of the jwt-provider config entry.
+	Name string
+}
+
+func (r *IngressJWTRequirement) Validate() error {
+	if r == nil {
+		return nil
+	}
+	if len(r.Providers) == 0 {
+		return fmt.Errorf("Providers must have at least one provider")
+	}
+	for _, p := range r.Providers {
+		if p == nil || p.Name == "" {
+			return fmt.Errorf("provider name cannot be blank")
+		}
+	}
+	return nil
+}
+
 type GatewayTLSConfig struct {
 	// Indicates that TLS should be enabled for this gateway or listener
 	Enabled bool
@@ -274,12 +371,29 @@ func (e *IngressGatewayConfigEntry) Validate() error {
 			return fmt.Errorf("No service declared for listener with port %d", listener.Port)
 		}
 
+		// A tcp listener can only tell its services apart by the SNI of the
+		// connections, which requires TLS.
+		sniRouted := listener.Protocol == "tcp" && (listener.TLSPassthrough || e.listenerTLSEnabled(listener))
+
 		// Validate that http features aren't being used with tcp or another non-supported protocol.
-		if listener.Protocol != "http" && len(listener.Services) > 1 {
+		if listener.Protocol != "http" && len(listener.Services) > 1 && !sniRouted {
+			if listener.Protocol == "tcp" {
+				return fmt.Errorf("Multiple services per listener are only supported for protocol = 'tcp' if the listener terminates TLS or uses TLSPassthrough (listener on port %d)",
+					listener.Port)
+			}
 			return fmt.Errorf("Multiple services per listener are only supported for protocol = 'http' (listener on port %d)",
 				listener.Port)
 		}
 
+		if listener.TLSPassthrough {
+			if listener.Protocol != "tcp" {
+				return fmt.Errorf("TLSPassthrough is only supported for protocol = 'tcp' (listener on port %d)", listener.Port)
+			}
+			if listener.TLS != nil && (listener.TLS.Enabled || listener.TLS.SDS != nil) {
+				return fmt.Errorf("TLSPassthrough cannot be used with TLS enabled on the listener (listener on port %d)", listener.Port)
+			}
+		}
+
 		if listener.TLS != nil {
 			if err := validateGatewayTLSConfig(*listener.TLS); err != nil {
 				return err
@@ -296,13 +410,33 @@ func (e *IngressGatewayConfigEntry) Validate() error {
 			if err := s.ResponseHeaders.Validate(listener.Protocol); err != nil {
 				return fmt.Errorf("response headers %s (service %q on listener on port %d)", err, sn.String(), listener.Port)
 			}
+			if listener.Protocol == "tcp" && (s.CORS != nil || s.MaxRequestBytes != 0 || s.JWT != nil) {
+				return fmt.Errorf("CORS, MaxRequestBytes and JWT are only supported for HTTP based protocols (service %q on listener on port %d)", sn.String(), listener.Port)
+			}
+			if err := s.CORS.Validate(); err != nil {
+				return fmt.Errorf("Bad CORS: %s (service %q on listener on port %d)", err, sn.String(), listener.Port)
+			}
+			if err := s.JWT.Validate(); err != nil {
+				return fmt.Errorf("Bad JWT: %s (service %q on listener on port %d)", err, sn.String(), listener.Port)
+			}
 
 			if listener.Protocol == "tcp" {
 				if s.Name == WildcardSpecifier {
 					return fmt.Errorf("Wildcard service name is only valid for protocol = 'http' (listener on port %d)", listener.Port)
 				}
-				if len(s.Hosts) != 0 {
-					return fmt.Errorf("Associating hosts to a service is not supported for the %s protocol (listener on port %d)", listener.Protocol, listener.Port)
+				if len(s.Hosts) != 0 && !sniRouted {
+					return fmt.Errorf("Associating hosts to a service is not supported for the %s protocol unless the listener terminates TLS or uses TLSPassthrough (listener on port %d)", listener.Protocol, listener.Port)
+				}
+				if len(s.Hosts) == 0 && len(listener.Services) > 1 {
+					return fmt.Errorf("Services sharing a listener with protocol = 'tcp' must have at least one host to match the SNI against (service %q on listener on port %d)", sn.String(), listener.Port)
+				}
+				for _, h := range s.Hosts {
+					if h == "*" {
+						return fmt.Errorf("Host '*' is not allowed for protocol = 'tcp', hosts are matched against the SNI of connections (listener on port %d)", listener.Port)
+					}
+				}
+				if listener.TLSPassthrough && ingressServiceHasSDSOverrides(s) {
+					return fmt.Errorf("A service cannot specify TLS.SDS.CertResource on a listener using TLSPassthrough (service %q on listener on port %d)", sn.String(), listener.Port)
 				}
 			}
 			if s.Name == "" {
@@ -340,6 +474,20 @@ func (e *IngressGatewayConfigEntry) Validate() error {
 	return nil
 }
 
+// listenerTLSEnabled returns true if the listener terminates TLS, using either
+// the Connect leaf certificate or a certificate from SDS.
+func (e *IngressGatewayConfigEntry) listenerTLSEnabled(listener IngressListener) bool {
+	if e.TLS.Enabled || (e.TLS.SDS != nil && e.TLS.SDS.CertResource != "") {
+		return true
+	}
+	return listener.TLS != nil &&
+		(listener.TLS.Enabled || (listener.TLS.SDS != nil && listener.TLS.SDS.CertResource != ""))
+}
+
+func ingressServiceHasSDSOverrides(s IngressService) bool {
+	return s.TLS != nil && s.TLS.SDS != nil && s.TLS.SDS.CertResource != ""
+}
+
 func validateHost(tlsEnabled bool, host string) error {
 	// Special case '*' so that non-TLS ingress gateways can use it. This allows
 	// an easy demo/testing experience.
@@ -2,6 +2,7 @@ package structs
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -237,6 +238,139 @@ func TestIngressGatewayConfigEntry(t *testing.T) {
 			},
 			validateErr: "Associating hosts to a service is not supported for the tcp protocol",
 		},
+		"tcp listener with TLS routes multiple services by SNI": {
+			entry: &IngressGatewayConfigEntry{
+				Kind: "ingress-gateway",
+				Name: "ingress-web",
+				TLS: GatewayTLSConfig{
+					Enabled: true,
+				},
+				Listeners: []IngressListener{
+					{
+						Port:     1111,
+						Protocol: "tcp",
+						Services: []IngressService{
+							{
+								Name:  "db1",
+								Hosts: []string{"db1.example.com"},
+							},
+							{
+								Name:  "db2",
+								Hosts: []string{"*.db2.example.com"},
+							},
+						},
+					},
+				},
+			},
+		},
+		"tcp listener with TLS passthrough routes multiple services by SNI": {
+			entry: &IngressGatewayConfigEntry{
+				Kind: "ingress-gateway",
+				Name: "ingress-web",
+				Listeners: []IngressListener{
+					{
+						Port:           1111,
+						Protocol:       "tcp",
+						TLSPassthrough: true,
+						Services: []IngressService{
+							{
+								Name:  "db1",
+								Hosts: []string{"db1.example.com"},
+							},
+							{
+								Name:  "db2",
+								Hosts: []string{"db2.example.com"},
+							},
+						},
+					},
+				},
+			},
+		},
+		"tcp listener routed by SNI requires hosts": {
+			entry: &IngressGatewayConfigEntry{
+				Kind: "ingress-gateway",
+				Name: "ingress-web",
+				Listeners: []IngressListener{
+					{
+						Port:           1111,
+						Protocol:       "tcp",
+						TLSPassthrough: true,
+						Services: []IngressService{
+							{
+								Name:  "db1",
+								Hosts: []string{"db1.example.com"},
+							},
+							{
+								Name: "db2",
+							},
+						},
+					},
+				},
+			},
+			validateErr: "must have at least one host to match the SNI against",
+		},
+		"tcp listener routed by SNI does not allow the '*' host": {
+			entry: &IngressGatewayConfigEntry{
+				Kind: "ingress-gateway",
+				Name: "ingress-web",
+				Listeners: []IngressListener{
+					{
+						Port:           1111,
+						Protocol:       "tcp",
+						TLSPassthrough: true,
+						Services: []IngressService{
+							{
+								Name:  "db",
+								Hosts: []string{"*"},
+							},
+						},
+					},
+				},
+			},
+			validateErr: "Host '*' is not allowed for protocol = 'tcp'",
+		},
+		"tls passthrough requires a tcp listener": {
+			entry: &IngressGatewayConfigEntry{
+				Kind: "ingress-gateway",
+				Name: "ingress-web",
+				Listeners: []IngressListener{
+					{
+						Port:           1111,
+						Protocol:       "http",
+						TLSPassthrough: true,
+						Services: []IngressService{
+							{
+								Name: "web",
+							},
+						},
+					},
+				},
+			},
+			validateErr: "TLSPassthrough is only supported for protocol = 'tcp'",
+		},
+		"tls passthrough cannot be used with listener TLS": {
+			entry: &IngressGatewayConfigEntry{
+				Kind: "ingress-gateway",
+				Name: "ingress-web",
+				Listeners: []IngressListener{
+					{
+						Port:           1111,
+						Protocol:       "tcp",
+						TLSPassthrough: true,
+						TLS: &GatewayTLSConfig{
+							Enabled: true,
+						},
+						Services: []IngressService{
+							{
+								Name:  "db",
+								Hosts: []string{"db.example.com"},
+							},
+						},
+					},
+				},
+			},
+			validateErr: "TLSPassthrough cannot be used with TLS enabled on the listener",
+		},
 		"hosts cannot be set on a wildcard specifier": {
 			entry: &IngressGatewayConfigEntry{
 				Kind: "ingress-gateway",
@@ -481,6 +615,158 @@ func TestIngressGatewayConfigEntry(t *testing.T) {
 			},
 			validateErr: "response headers only valid for http",
 		},
+		"cors, request size limit and jwt on http listener": {
+			entry: &IngressGatewayConfigEntry{
+				Kind: "ingress-gateway",
+				Name: "ingress-web",
+				Listeners: []IngressListener{
+					{
+						Port:     1111,
+						Protocol: "http",
+						Services: []IngressService{
+							{
+								Name: "web",
+								CORS: &IngressCORSConfig{
+									AllowOrigins:     []string{"https://example.com"},
+									AllowMethods:     []string{"GET", "POST"},
+									MaxAge:           time.Hour,
+									AllowCredentials: true,
+								},
+								MaxRequestBytes: 1024,
+								JWT: &IngressJWTRequirement{
+									Providers: []*IngressJWTProvider{{Name: "okta"}},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		"cors not allowed for non-http protocol": {
+			entry: &IngressGatewayConfigEntry{
+				Kind: "ingress-gateway",
+				Name: "ingress-web",
+				Listeners: []IngressListener{
+					{
+						Port:     1111,
+						Protocol: "tcp",
+						Services: []IngressService{
+							{
+								Name: "db",
+								CORS: &IngressCORSConfig{
+									AllowOrigins: []string{"*"},
+								},
+							},
+						},
+					},
+				},
+			},
+			validateErr: "CORS, MaxRequestBytes and JWT are only supported for HTTP based protocols",
+		},
+		"request size limit not allowed for non-http protocol": {
+			entry: &IngressGatewayConfigEntry{
+				Kind: "ingress-gateway",
+				Name: "ingress-web",
+				Listeners: []IngressListener{
+					{
+						Port:     1111,
+						Protocol: "tcp",
+						Services: []IngressService{
+							{
+								Name:            "db",
+								MaxRequestBytes: 1024,
+							},
+						},
+					},
+				},
+			},
+			validateErr: "CORS, MaxRequestBytes and JWT are only supported for HTTP based protocols",
+		},
+		"cors requires an origin": {
+			entry: &IngressGatewayConfigEntry{
+				Kind: "ingress-gateway",
+				Name: "ingress-web",
+				Listeners: []IngressListener{
+					{
+						Port:     1111,
+						Protocol: "http",
+						Services: []IngressService{
+							{
+								Name: "web",
+								CORS: &IngressCORSConfig{
+									AllowMethods: []string{"GET"},
+								},
+							},
+						},
+					},
+				},
+			},
+			validateErr: "Bad CORS: AllowOrigins must have at least one origin",
+		},
+		"cors wildcard origin cannot allow credentials": {
+			entry: &IngressGatewayConfigEntry{
+				Kind: "ingress-gateway",
+				Name: "ingress-web",
+				Listeners: []IngressListener{
+					{
+						Port:     1111,
+						Protocol: "http",
+						Services: []IngressService{
+							{
+								Name: "web",
+								CORS: &IngressCORSConfig{
+									AllowOrigins:     []string{"*"},
+									AllowCredentials: true,
+								},
+							},
+						},
+					},
+				},
+			},
+			validateErr: "AllowOrigins cannot contain '*' when AllowCredentials is set",
+		},
+		"cors origin requires a scheme": {
+			entry: &IngressGatewayConfigEntry{
+				Kind: "ingress-gateway",
+				Name: "ingress-web",
+				Listeners: []IngressListener{
+					{
+						Port:     1111,
+						Protocol: "http",
+						Services: []IngressService{
+							{
+								Name: "web",
+								CORS: &IngressCORSConfig{
+									AllowOrigins: []string{"example.com"},
+								},
+							},
+						},
+					},
+				},
+			},
+			validateErr: `origin "example.com" must include a scheme`,
+		},
+		"jwt provider name cannot be blank": {
+			entry: &IngressGatewayConfigEntry{
+				Kind: "ingress-gateway",
+				Name: "ingress-web",
+				Listeners: []IngressListener{
+					{
+						Port:     1111,
+						Protocol: "http",
+						Services: []IngressService{
+							{
+								Name: "web",
+								JWT: &IngressJWTRequirement{
+									Providers: []*IngressJWTProvider{{Name: ""}},
+								},
+							},
+						},
+					},
+				},
+			},
+			validateErr: "Bad JWT: provider name cannot be blank",
+		},
 		"duplicate services not allowed": {
 			entry: &IngressGatewayConfigEntry{
 				Kind: "ingress-gateway",
@@ -2,6 +2,7 @@ package structs
 
 import (
 	"fmt"
+	"regexp"
 	"sort"
 	"strings"
 	"time"
@@ -266,12 +267,13 @@ type IntentionPermission struct {
 
 	HTTP *IntentionHTTPPermission `json:",omitempty"`
 
+	// JWT requires requests to carry a valid end-user token for the
+	// permission to match. It is checked by the destination's sidecar proxy.
+	JWT *IntentionJWTRequirement `json:",omitempty"`
+
 	// If we have non-http match criteria for other protocols
 	// in the future (gRPC, redis, etc) they can go here.
 
-	// Support for edge-decoded JWTs would likely be configured
-	// in a new top level section here.
-
 	// If we ever add Sentinel support, this is one place we may
 	// wish to add it.
 }
@@ -281,9 +283,62 @@ func (p *IntentionPermission) Clone() *IntentionPermission {
 	if p.HTTP != nil {
 		p2.HTTP = p.HTTP.Clone()
 	}
+	if p.JWT != nil {
+		p2.JWT = p.JWT.Clone()
+	}
+	return &p2
+}
+
+// IntentionJWTRequirement is satisfied by a request carrying a valid token
+// from any one of the providers, whose claims match all of that provider's
+// claim verifications.
+type IntentionJWTRequirement struct {
+	Providers []*IntentionJWTProvider `json:",omitempty"`
+}
+
+func (r *IntentionJWTRequirement) Clone() *IntentionJWTRequirement {
+	r2 := *r
+	if len(r.Providers) > 0 {
+		r2.Providers = make([]*IntentionJWTProvider, 0, len(r.Providers))
+		for _, p := range r.Providers {
+			r2.Providers = append(r2.Providers, p.Clone())
+		}
+	}
+	return &r2
+}
+
+type IntentionJWTProvider struct {
+	// Name of the jwt-provider config entry that issued the token.
+	Name string
+
+	// VerifyClaims are additional claims the token must contain.
+	VerifyClaims []*IntentionJWTClaimVerification `json:",omitempty" alias:"verify_claims"`
+}
+
+func (p *IntentionJWTProvider) Clone() *IntentionJWTProvider {
+	p2 := *p
+	if len(p.VerifyClaims) > 0 {
+		p2.VerifyClaims = make([]*IntentionJWTClaimVerification, 0, len(p.VerifyClaims))
+		for _, c := range p.VerifyClaims {
+			c2 := *c
+			c2.Path = append([]string(nil), c.Path...)
+			p2.VerifyClaims = append(p2.VerifyClaims, &c2)
+		}
+	}
 	return &p2
 }
 
+// IntentionJWTClaimVerification matches a claim of the token against an exact
+// string value.
+type IntentionJWTClaimVerification struct {
+	// Path is the path to the claim in the token payload. Nested claims are
+	// addressed with one element per level, for example ["perms", "role"].
+	Path []string
+
+	// Value is the value the claim must be equal to.
+	Value string
+}
+
 type IntentionHTTPPermission struct {
 	// PathExact, PathPrefix, and PathRegex are mutually exclusive.
 	PathExact  string `json:",omitempty" alias:"path_exact"`
@@ -320,6 +375,103 @@ type IntentionHTTPHeaderPermission struct {
 	Invert  bool   `json:",omitempty"`
 }
 
+// MatchesHTTP returns whether the permission applies to the given request. A
+// JWT requirement can only be checked by a proxy validating the token, so
+// here a deny permission requiring one still matches on its HTTP attributes
+// while an allow permission requiring one never matches.
+func (p *IntentionPermission) MatchesHTTP(req *ConnectAuthorizeHTTPRequest) bool {
+	if p.JWT != nil && p.Action != IntentionActionDeny {
+		return false
+	}
+	return p.HTTP == nil || p.HTTP.Matches(req)
+}
+
+// Matches returns whether the request matches all of the criteria of the
+// permission, with the same semantics as the RBAC rules generated for Envoy.
+func (p *IntentionHTTPPermission) Matches(req *ConnectAuthorizeHTTPRequest) bool {
+	switch {
+	case p.PathExact != "":
+		if req.Path != p.PathExact {
+			return false
+		}
+	case p.PathPrefix != "":
+		if !strings.HasPrefix(req.Path, p.PathPrefix) {
+			return false
+		}
+	case p.PathRegex != "":
+		if !matchesFullRegex(p.PathRegex, req.Path) {
+			return false
+		}
+	}
+
+	for _, hdr := range p.Header {
+		if !hdr.Matches(req.Header) {
+			return false
+		}
+	}
+
+	if len(p.Methods) > 0 {
+		for _, m := range p.Methods {
+			if strings.EqualFold(m, req.Method) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// Matches returns whether the headers satisfy the permission. Header names
+// are case insensitive and multiple values of a header are matched as a
+// single comma separated value.
+func (p IntentionHTTPHeaderPermission) Matches(header map[string][]string) bool {
+	var (
+		values  []string
+		present bool
+	)
+	for name, v := range header {
+		if strings.EqualFold(name, p.Name) {
+			values = append(values, v...)
+			present = true
+		}
+	}
+
+	var matched bool
+	switch {
+	case p.Present:
+		matched = present
+	case !present:
+		matched = false
+	default:
+		value := strings.Join(values, ",")
+		switch {
+		case p.Exact != "":
+			matched = value == p.Exact
+		case p.Prefix != "":
+			matched = strings.HasPrefix(value, p.Prefix)
+		case p.Suffix != "":
+			matched = strings.HasSuffix(value, p.Suffix)
+		case p.Regex != "":
+			matched = matchesFullRegex(p.Regex, value)
+		}
+	}
+
+	if p.Invert {
+		return !matched
+	}
+	return matched
+}
+
+// matchesFullRegex reports whether the whole of s matches the pattern, as the
+// RE2 matchers in Envoy do.
+func matchesFullRegex(pattern, s string) bool {
+	re, err := regexp.Compile("^(?:" + pattern + ")$")
+	if err != nil {
+		return false
+	}
+	return re.MatchString(s)
+}
+
 func cloneStringStringMap(m map[string]string) map[string]string {
 	if m == nil {
 		return nil
@@ -661,6 +813,16 @@ func (e *ServiceIntentionsConfigEntry) validate(legacyWrite bool) error {
 				return fmt.Errorf("Sources[%d].Permissions[%d].Action must be set to 'allow' or 'deny'", i, j)
 			}
 
+			if perm.JWT != nil {
+				if err := perm.JWT.validate(); err != nil {
+					return fmt.Errorf("Sources[%d].Permissions[%d].JWT%v", i, j, err)
+				}
+				if perm.HTTP == nil {
+					// The token requirement alone is enough to match on.
+					continue
+				}
+			}
+
 			errorPrefix := "Sources[%d].Permissions[%d].HTTP"
 			if perm.HTTP == nil {
 				return fmt.Errorf(errorPrefix+" is required", i, j)
@@ -752,6 +914,35 @@ func (e *ServiceIntentionsConfigEntry) validate(legacyWrite bool) error {
 	return nil
 }
 
+func (r *IntentionJWTRequirement) validate() error {
+	if len(r.Providers) == 0 {
+		return fmt.Errorf(".Providers is required")
+	}
+
+	seen := make(map[string]struct{})
+	for i, p := range r.Providers {
+		if p == nil || p.Name == "" {
+			return fmt.Errorf(".Providers[%d] missing required Name field", i)
+		}
+		if _, ok := seen[p.Name]; ok {
+			return fmt.Errorf(".Providers contains %q more than once", p.Name)
+		}
+		seen[p.Name] = struct{}{}
+
+		for k, c := range p.VerifyClaims {
+			if c == nil || len(c.Path) == 0 {
+				return fmt.Errorf(".Providers[%d].VerifyClaims[%d] missing required Path field", i, k)
+			}
+			for _, segment := range c.Path {
+				if segment == "" {
+					return fmt.Errorf(".Providers[%d].VerifyClaims[%d].Path cannot contain empty segments", i, k)
+				}
+			}
+		}
+	}
+	return nil
+}
+
 // Wildcard usage verification
 func validateIntentionWildcards(name string, entMeta *acl.EnterpriseMeta) error {
 	ns := entMeta.NamespaceOrDefault()
@@ -505,6 +505,124 @@ func TestServiceIntentionsConfigEntry(t *testing.T) {
 			},
 			validateErr: `Sources[0].Permissions[0].HTTP should only contain at most one of PathExact, PathPrefix, or PathRegex`,
 		},
+		"permission jwt has no providers": {
+			entry: &ServiceIntentionsConfigEntry{
+				Kind: ServiceIntentions,
+				Name: "test",
+				Sources: []*SourceIntention{
+					{
+						Name: "foo",
+						Permissions: []*IntentionPermission{
+							{
+								Action: IntentionActionAllow,
+								JWT:    &IntentionJWTRequirement{},
+							},
+						},
+					},
+				},
+			},
+			validateErr: `Sources[0].Permissions[0].JWT.Providers is required`,
+		},
+		"permission jwt provider missing name": {
+			entry: &ServiceIntentionsConfigEntry{
+				Kind: ServiceIntentions,
+				Name: "test",
+				Sources: []*SourceIntention{
+					{
+						Name: "foo",
+						Permissions: []*IntentionPermission{
+							{
+								Action: IntentionActionAllow,
+								JWT: &IntentionJWTRequirement{
+									Providers: []*IntentionJWTProvider{
+										{Name: "okta"},
+										{},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			validateErr: `Sources[0].Permissions[0].JWT.Providers[1] missing required Name field`,
+		},
+		"permission jwt provider listed twice": {
+			entry: &ServiceIntentionsConfigEntry{
+				Kind: ServiceIntentions,
+				Name: "test",
+				Sources: []*SourceIntention{
+					{
+						Name: "foo",
+						Permissions: []*IntentionPermission{
+							{
+								Action: IntentionActionAllow,
+								JWT: &IntentionJWTRequirement{
+									Providers: []*IntentionJWTProvider{
+										{Name: "okta"},
+										{Name: "okta"},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			validateErr: `Sources[0].Permissions[0].JWT.Providers contains "okta" more than once`,
+		},
+		"permission jwt claim missing path": {
+			entry: &ServiceIntentionsConfigEntry{
+				Kind: ServiceIntentions,
+				Name: "test",
+				Sources: []*SourceIntention{
+					{
+						Name: "foo",
+						Permissions: []*IntentionPermission{
+							{
+								Action: IntentionActionAllow,
+								JWT: &IntentionJWTRequirement{
+									Providers: []*IntentionJWTProvider{
+										{
+											Name: "okta",
+											VerifyClaims: []*IntentionJWTClaimVerification{
+												{Value: "admin"},
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			validateErr: `Sources[0].Permissions[0].JWT.Providers[0].VerifyClaims[0] missing required Path field`,
+		},
+		"permission jwt claim path has empty segment": {
+			entry: &ServiceIntentionsConfigEntry{
+				Kind: ServiceIntentions,
+				Name: "test",
+				Sources: []*SourceIntention{
+					{
+						Name: "foo",
+						Permissions: []*IntentionPermission{
+							{
+								Action: IntentionActionAllow,
+								JWT: &IntentionJWTRequirement{
+									Providers: []*IntentionJWTProvider{
+										{
+											Name: "okta",
+											VerifyClaims: []*IntentionJWTClaimVerification{
+												{Path: []string{"perms", ""}, Value: "admin"},
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			validateErr: `Sources[0].Permissions[0].JWT.Providers[0].VerifyClaims[0].Path cannot contain empty segments`,
+		},
 		"permission has invalid path exact": {
 			entry: &ServiceIntentionsConfigEntry{
 				Kind: ServiceIntentions,
@@ -1283,6 +1401,105 @@ func makeStringMap(keys, keySize, valSize int) map[string]string {
 	return m
 }
 
+func TestIntentionPermission_MatchesHTTP(t *testing.T) {
+	req := &ConnectAuthorizeHTTPRequest{
+		Method: "POST",
+		Path:   "/api/v1/users",
+		Header: map[string][]string{
+			"X-Env":      {"prod"},
+			"X-Multiple": {"a", "b"},
+		},
+	}
+
+	cases := map[string]struct {
+		perm *IntentionPermission
+		want bool
+	}{
+		"path exact": {
+			perm: &IntentionPermission{HTTP: &IntentionHTTPPermission{PathExact: "/api/v1/users"}},
+			want: true,
+		},
+		"path exact mismatch": {
+			perm: &IntentionPermission{HTTP: &IntentionHTTPPermission{PathExact: "/api/v1"}},
+			want: false,
+		},
+		"path prefix": {
+			perm: &IntentionPermission{HTTP: &IntentionHTTPPermission{PathPrefix: "/api/"}},
+			want: true,
+		},
+		"path regex must match the whole path": {
+			perm: &IntentionPermission{HTTP: &IntentionHTTPPermission{PathRegex: "/api/v[0-9]"}},
+			want: false,
+		},
+		"path regex": {
+			perm: &IntentionPermission{HTTP: &IntentionHTTPPermission{PathRegex: "/api/v[0-9]/.*"}},
+			want: true,
+		},
+		"methods": {
+			perm: &IntentionPermission{HTTP: &IntentionHTTPPermission{Methods: []string{"GET", "POST"}}},
+			want: true,
+		},
+		"methods mismatch": {
+			perm: &IntentionPermission{HTTP: &IntentionHTTPPermission{Methods: []string{"GET"}}},
+			want: false,
+		},
+		"header exact with any case name": {
+			perm: &IntentionPermission{HTTP: &IntentionHTTPPermission{
+				Header: []IntentionHTTPHeaderPermission{{Name: "x-env", Exact: "prod"}},
+			}},
+			want: true,
+		},
+		"header values are joined": {
+			perm: &IntentionPermission{HTTP: &IntentionHTTPPermission{
+				Header: []IntentionHTTPHeaderPermission{{Name: "X-Multiple", Exact: "a,b"}},
+			}},
+			want: true,
+		},
+		"header present": {
+			perm: &IntentionPermission{HTTP: &IntentionHTTPPermission{
+				Header: []IntentionHTTPHeaderPermission{{Name: "X-Missing", Present: true}},
+			}},
+			want: false,
+		},
+		"header inverted": {
+			perm: &IntentionPermission{HTTP: &IntentionHTTPPermission{
+				Header: []IntentionHTTPHeaderPermission{{Name: "X-Env", Prefix: "dev", Invert: true}},
+			}},
+			want: true,
+		},
+		"all criteria must match": {
+			perm: &IntentionPermission{HTTP: &IntentionHTTPPermission{
+				PathPrefix: "/api/",
+				Methods:    []string{"GET"},
+			}},
+			want: false,
+		},
+		"allow with jwt never matches": {
+			perm: &IntentionPermission{
+				Action: IntentionActionAllow,
+				HTTP:   &IntentionHTTPPermission{PathPrefix: "/api/"},
+				JWT:    &IntentionJWTRequirement{},
+			},
+			want: false,
+		},
+		"deny with jwt matches on http": {
+			perm: &IntentionPermission{
+				Action: IntentionActionDeny,
+				HTTP:   &IntentionHTTPPermission{PathPrefix: "/api/"},
+				JWT:    &IntentionJWTRequirement{},
+			},
+			want: true,
+		},
+	}
+
+	for name, tc := range cases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			require.Equal(t, tc.want, tc.perm.MatchesHTTP(req))
+		})
+	}
+}
+
 func TestMigrateIntentions(t *testing.T) {
 	type testcase struct {
 		in     Intentions
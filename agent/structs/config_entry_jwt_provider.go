@@ -0,0 +1,180 @@
+package structs
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hashicorp/consul/acl"
+)
+
+// JWTProviderConfigEntry describes an issuer of end-user JSON Web Tokens that
+// sidecar proxies can validate. Intentions refer to providers by name to
+// require a valid token on the requests they allow or deny.
+type JWTProviderConfigEntry struct {
+	Name string
+
+	// Issuer is the value the "iss" claim of a token must match.
+	Issuer string
+
+	// Audiences restricts the tokens accepted to those whose "aud" claim
+	// contains at least one of these values. If empty the audience is not
+	// checked.
+	Audiences []string `json:",omitempty"`
+
+	// JSONWebKeySet holds the public keys used to verify the signature of a
+	// token.
+	JSONWebKeySet *JSONWebKeySet `json:",omitempty" alias:"json_web_key_set"`
+
+	// ClockSkewSeconds is the amount of clock skew tolerated when checking the
+	// "exp" and "nbf" claims. If zero Envoy's default of 60 seconds is used.
+	ClockSkewSeconds int `json:",omitempty" alias:"clock_skew_seconds"`
+
+	Meta               map[string]string `json:",omitempty"`
+	acl.EnterpriseMeta `hcl:",squash" mapstructure:",squash"`
+	RaftIndex
+}
+
+// JSONWebKeySet describes where the key set of a JWT provider comes from.
+type JSONWebKeySet struct {
+	Local *LocalJWKS `json:",omitempty"`
+}
+
+// LocalJWKS is a key set made available to the proxy without fetching it over
+// the network. Exactly one of JWKS or Filename must be set.
+type LocalJWKS struct {
+	// JWKS is the key set inline, as a JSON document.
+	JWKS string `json:",omitempty"`
+
+	// Filename is the path to a file containing the key set on the host of
+	// each proxy.
+	Filename string `json:",omitempty"`
+}
+
+func (e *JWTProviderConfigEntry) GetKind() string {
+	return JWTProvider
+}
+
+func (e *JWTProviderConfigEntry) GetName() string {
+	if e == nil {
+		return ""
+	}
+
+	return e.Name
+}
+
+func (e *JWTProviderConfigEntry) GetMeta() map[string]string {
+	if e == nil {
+		return nil
+	}
+	return e.Meta
+}
+
+func (e *JWTProviderConfigEntry) Normalize() error {
+	if e == nil {
+		return fmt.Errorf("config entry is nil")
+	}
+
+	// Providers are shared by every namespace of a partition.
+	e.EnterpriseMeta = *DefaultEnterpriseMetaInPartition(e.PartitionOrEmpty())
+	e.EnterpriseMeta.Normalize()
+	return nil
+}
+
+func (e *JWTProviderConfigEntry) Validate() error {
+	if e == nil {
+		return fmt.Errorf("config entry is nil")
+	}
+	if e.Name == "" {
+		return fmt.Errorf("Name is required")
+	}
+	if e.Name == WildcardSpecifier {
+		return fmt.Errorf("Name cannot be a wildcard")
+	}
+	if err := validateConfigEntryMeta(e.Meta); err != nil {
+		return err
+	}
+
+	if e.Issuer == "" {
+		return fmt.Errorf("Issuer is required")
+	}
+	for i, aud := range e.Audiences {
+		if aud == "" {
+			return fmt.Errorf("Audiences[%d] cannot be empty", i)
+		}
+	}
+	if e.ClockSkewSeconds < 0 {
+		return fmt.Errorf("ClockSkewSeconds cannot be negative")
+	}
+
+	if e.JSONWebKeySet == nil || e.JSONWebKeySet.Local == nil {
+		return fmt.Errorf("JSONWebKeySet.Local is required")
+	}
+	if err := e.JSONWebKeySet.Local.validate(); err != nil {
+		return fmt.Errorf("JSONWebKeySet.Local %v", err)
+	}
+	return nil
+}
+
+func (l *LocalJWKS) validate() error {
+	switch {
+	case l.JWKS != "" && l.Filename != "":
+		return fmt.Errorf("must set only one of JWKS or Filename")
+	case l.Filename != "":
+		return nil
+	case l.JWKS == "":
+		return fmt.Errorf("must set one of JWKS or Filename")
+	}
+
+	var jwks struct {
+		Keys []json.RawMessage `json:"keys"`
+	}
+	if err := json.Unmarshal([]byte(l.JWKS), &jwks); err != nil {
+		return fmt.Errorf("JWKS is not a valid JSON Web Key Set: %v", err)
+	}
+	if len(jwks.Keys) == 0 {
+		return fmt.Errorf("JWKS must contain at least one key")
+	}
+	return nil
+}
+
+// CanRead always succeeds: a provider only holds public keys, and every proxy
+// enforcing intentions that refer to it needs to read it.
+func (e *JWTProviderConfigEntry) CanRead(authz acl.Authorizer) error {
+	return nil
+}
+
+func (e *JWTProviderConfigEntry) CanWrite(authz acl.Authorizer) error {
+	var authzContext acl.AuthorizerContext
+	e.FillAuthzContext(&authzContext)
+	return authz.ToAllowAuthorizer().MeshWriteAllowed(&authzContext)
+}
+
+func (e *JWTProviderConfigEntry) GetRaftIndex() *RaftIndex {
+	if e == nil {
+		return &RaftIndex{}
+	}
+
+	return &e.RaftIndex
+}
+
+func (e *JWTProviderConfigEntry) GetEnterpriseMeta() *acl.EnterpriseMeta {
+	if e == nil {
+		return nil
+	}
+
+	return &e.EnterpriseMeta
+}
+
+// MarshalJSON adds the Kind field so that the JSON can be decoded back into the
+// correct type.
+func (e *JWTProviderConfigEntry) MarshalJSON() ([]byte, error) {
+	type Alias JWTProviderConfigEntry
+	source := &struct {
+		Kind string
+		*Alias
+	}{
+		Kind:  JWTProvider,
+		Alias: (*Alias)(e),
+	}
+	return json.Marshal(source)
+}
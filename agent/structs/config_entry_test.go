@@ -303,6 +303,93 @@ func TestDecodeConfigEntry(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "proxy-defaults with access logs",
+			snake: `
+				kind = "proxy-defaults"
+				name = "main"
+				access_logs {
+					enabled = true
+					disable_listener_logs = true
+					type = "file"
+					path = "/var/log/envoy/access.log"
+					text_format = "%RESPONSE_CODE%"
+				}
+			`,
+			camel: `
+				Kind = "proxy-defaults"
+				Name = "main"
+				AccessLogs {
+					Enabled = true
+					DisableListenerLogs = true
+					Type = "file"
+					Path = "/var/log/envoy/access.log"
+					TextFormat = "%RESPONSE_CODE%"
+				}
+			`,
+			expect: &ProxyConfigEntry{
+				Kind: "proxy-defaults",
+				Name: "main",
+				AccessLogs: AccessLogsConfig{
+					Enabled:             true,
+					DisableListenerLogs: true,
+					Type:                FileLogSinkType,
+					Path:                "/var/log/envoy/access.log",
+					TextFormat:          "%RESPONSE_CODE%",
+				},
+			},
+		},
+		{
+			name: "proxy-defaults with tracing",
+			snake: `
+				kind = "proxy-defaults"
+				name = "main"
+				tracing {
+					provider = "zipkin"
+					collector {
+						address = "zipkin.service.consul"
+						port = 9411
+					}
+					collector_endpoint = "/api/v1/spans"
+					sampling_percentage = 2.5
+					custom_tags {
+						env = "prod"
+					}
+				}
+			`,
+			camel: `
+				Kind = "proxy-defaults"
+				Name = "main"
+				Tracing {
+					Provider = "zipkin"
+					Collector {
+						Address = "zipkin.service.consul"
+						Port = 9411
+					}
+					CollectorEndpoint = "/api/v1/spans"
+					SamplingPercentage = 2.5
+					CustomTags {
+						env = "prod"
+					}
+				}
+			`,
+			expect: &ProxyConfigEntry{
+				Kind: "proxy-defaults",
+				Name: "main",
+				Tracing: &TracingConfig{
+					Provider: ZipkinTracingProvider,
+					Collector: TracingCollector{
+						Address: "zipkin.service.consul",
+						Port:    9411,
+					},
+					CollectorEndpoint:  "/api/v1/spans",
+					SamplingPercentage: 2.5,
+					CustomTags: map[string]string{
+						"env": "prod",
+					},
+				},
+			},
+		},
 		{
 			name: "service-defaults",
 			snake: `
@@ -427,6 +514,284 @@ func TestDecodeConfigEntry(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "service-defaults: rate limit",
+			snake: `
+				kind = "service-defaults"
+				name = "main"
+				protocol = "http"
+				rate_limit {
+					requests_per_second = 500
+					burst = 1000
+					routes = [
+						{
+							path_prefix = "/admin"
+							requests_per_second = 10
+						},
+					]
+					sources = [
+						{
+							name = "web"
+							requests_per_second = 50
+							burst = 60
+						},
+					]
+				}
+			`,
+			camel: `
+				Kind = "service-defaults"
+				Name = "main"
+				Protocol = "http"
+				RateLimit {
+					RequestsPerSecond = 500
+					Burst = 1000
+					Routes = [
+						{
+							PathPrefix = "/admin"
+							RequestsPerSecond = 10
+						},
+					]
+					Sources = [
+						{
+							Name = "web"
+							RequestsPerSecond = 50
+							Burst = 60
+						},
+					]
+				}
+			`,
+			expect: &ServiceConfigEntry{
+				Kind:     "service-defaults",
+				Name:     "main",
+				Protocol: "http",
+				RateLimit: &RateLimitConfig{
+					RequestsPerSecond: 500,
+					Burst:             1000,
+					Routes: []RateLimitRoute{
+						{PathPrefix: "/admin", RequestsPerSecond: 10},
+					},
+					Sources: []RateLimitSource{
+						{Name: "web", RequestsPerSecond: 50, Burst: 60},
+					},
+				},
+			},
+		},
+		{
+			name: "service-defaults: envoy extensions",
+			snake: `
+				kind = "service-defaults"
+				name = "main"
+				protocol = "http"
+				envoy_extensions = [
+					{
+						name = "builtin/lua"
+						arguments = {
+							Listener = "inbound"
+							Script = "function envoy_on_request(h) end"
+						}
+					},
+				]
+			`,
+			camel: `
+				Kind = "service-defaults"
+				Name = "main"
+				Protocol = "http"
+				EnvoyExtensions = [
+					{
+						Name = "builtin/lua"
+						Arguments = {
+							Listener = "inbound"
+							Script = "function envoy_on_request(h) end"
+						}
+					},
+				]
+			`,
+			expect: &ServiceConfigEntry{
+				Kind:     "service-defaults",
+				Name:     "main",
+				Protocol: "http",
+				EnvoyExtensions: EnvoyExtensions{
+					{
+						Name: "builtin/lua",
+						Arguments: map[string]interface{}{
+							"Listener": "inbound",
+							"Script":   "function envoy_on_request(h) end",
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "service-defaults: ext authz",
+			snake: `
+				kind = "service-defaults"
+				name = "main"
+				protocol = "http"
+				ext_authz {
+					service = "authz"
+					protocol = "http"
+					path_prefix = "/check"
+					timeout_ms = 500
+					failure_mode_allow = true
+				}
+			`,
+			camel: `
+				Kind = "service-defaults"
+				Name = "main"
+				Protocol = "http"
+				ExtAuthz {
+					Service = "authz"
+					Protocol = "http"
+					PathPrefix = "/check"
+					TimeoutMs = 500
+					FailureModeAllow = true
+				}
+			`,
+			expect: &ServiceConfigEntry{
+				Kind:     "service-defaults",
+				Name:     "main",
+				Protocol: "http",
+				ExtAuthz: &ExtAuthzConfig{
+					Service:          "authz",
+					Protocol:         "http",
+					PathPrefix:       "/check",
+					TimeoutMs:        500,
+					FailureModeAllow: true,
+				},
+			},
+		},
+		{
+			name: "proxy-defaults: permissive mutual tls",
+			snake: `
+				kind = "proxy-defaults"
+				name = "main"
+				mutual_tls_mode = "permissive"
+				permissive_source_cidrs = ["10.0.0.0/16"]
+			`,
+			camel: `
+				Kind = "proxy-defaults"
+				Name = "main"
+				MutualTLSMode = "permissive"
+				PermissiveSourceCIDRs = ["10.0.0.0/16"]
+			`,
+			expect: &ProxyConfigEntry{
+				Kind:                  "proxy-defaults",
+				Name:                  "main",
+				MutualTLSMode:         MutualTLSModePermissive,
+				PermissiveSourceCIDRs: []string{"10.0.0.0/16"},
+			},
+		},
+		{
+			name: "service-defaults: strict mutual tls",
+			snake: `
+				kind = "service-defaults"
+				name = "main"
+				mutual_tls_mode = "strict"
+			`,
+			camel: `
+				Kind = "service-defaults"
+				Name = "main"
+				MutualTLSMode = "strict"
+			`,
+			expect: &ServiceConfigEntry{
+				Kind:          "service-defaults",
+				Name:          "main",
+				MutualTLSMode: MutualTLSModeStrict,
+			},
+		},
+		{
+			name: "service-defaults: connection pool",
+			snake: `
+				kind = "service-defaults"
+				name = "main"
+				connection_pool {
+					tcp_idle_timeout_ms = 0
+				}
+				upstream_config {
+					defaults {
+						connection_pool {
+							http_idle_timeout_ms = 30000
+							max_requests_per_connection = 100
+							tcp_keepalive {
+								probes = 3
+								time_seconds = 60
+								interval_seconds = 10
+							}
+						}
+					}
+				}
+			`,
+			camel: `
+				Kind = "service-defaults"
+				Name = "main"
+				ConnectionPool {
+					TCPIdleTimeoutMs = 0
+				}
+				UpstreamConfig {
+					Defaults {
+						ConnectionPool {
+							HTTPIdleTimeoutMs = 30000
+							MaxRequestsPerConnection = 100
+							TCPKeepalive {
+								Probes = 3
+								TimeSeconds = 60
+								IntervalSeconds = 10
+							}
+						}
+					}
+				}
+			`,
+			expect: &ServiceConfigEntry{
+				Kind: "service-defaults",
+				Name: "main",
+				ConnectionPool: &ConnectionPoolConfig{
+					TCPIdleTimeoutMs: intPointer(0),
+				},
+				UpstreamConfig: &UpstreamConfiguration{
+					Defaults: &UpstreamConfig{
+						ConnectionPool: &ConnectionPoolConfig{
+							HTTPIdleTimeoutMs:        intPointer(30000),
+							MaxRequestsPerConnection: intPointer(100),
+							TCPKeepalive: &TCPKeepaliveConfig{
+								Probes:          3,
+								TimeSeconds:     60,
+								IntervalSeconds: 10,
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "service-defaults: destination",
+			snake: `
+				kind = "service-defaults"
+				name = "external"
+				protocol = "tcp"
+				destination {
+					addresses = ["api.example.com", "192.0.2.10"]
+					port = 443
+				}
+			`,
+			camel: `
+				Kind = "service-defaults"
+				Name = "external"
+				Protocol = "tcp"
+				Destination {
+					Addresses = ["api.example.com", "192.0.2.10"]
+					Port = 443
+				}
+			`,
+			expect: &ServiceConfigEntry{
+				Kind:     "service-defaults",
+				Name:     "external",
+				Protocol: "tcp",
+				Destination: &DestinationConfig{
+					Addresses: []string{"api.example.com", "192.0.2.10"},
+					Port:      443,
+				},
+			},
+		},
 		{
 			name: "service-router: kitchen sink",
 			snake: `
@@ -717,10 +1082,148 @@ func TestDecodeConfigEntry(t *testing.T) {
 					},
 					{
 						Match: &ServiceRouteMatch{
-							HTTP: &ServiceRouteHTTPMatch{
-								PathRegex: "/foo",
+							HTTP: &ServiceRouteHTTPMatch{
+								PathRegex: "/foo",
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "service-router: fault injection and mirror",
+			snake: `
+				kind = "service-router"
+				name = "main"
+				routes = [
+					{
+						destination {
+							fault_injection {
+								delay {
+									duration   = "500ms"
+									percentage = 10
+								}
+								abort {
+									http_status = 503
+									percentage  = 0.5
+								}
+							}
+							mirror {
+								service        = "shadow"
+								service_subset = "v2"
+								percentage     = 25
+							}
+						}
+					},
+				]
+			`,
+			camel: `
+				Kind = "service-router"
+				Name = "main"
+				Routes = [
+					{
+						Destination {
+							FaultInjection {
+								Delay {
+									Duration   = "500ms"
+									Percentage = 10
+								}
+								Abort {
+									HTTPStatus = 503
+									Percentage = 0.5
+								}
+							}
+							Mirror {
+								Service       = "shadow"
+								ServiceSubset = "v2"
+								Percentage    = 25
+							}
+						}
+					},
+				]
+			`,
+			expect: &ServiceRouterConfigEntry{
+				Kind: "service-router",
+				Name: "main",
+				Routes: []ServiceRoute{
+					{
+						Destination: &ServiceRouteDestination{
+							FaultInjection: &ServiceRouteFaultInjection{
+								Delay: &ServiceRouteFaultDelay{
+									Duration:   500 * time.Millisecond,
+									Percentage: 10,
+								},
+								Abort: &ServiceRouteFaultAbort{
+									HTTPStatus: 503,
+									Percentage: 0.5,
+								},
+							},
+							Mirror: &ServiceRouteMirror{
+								Service:       "shadow",
+								ServiceSubset: "v2",
+								Percentage:    25,
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "service-router: grpc match and retries",
+			snake: `
+				kind = "service-router"
+				name = "main"
+				routes = [
+					{
+						match {
+							grpc {
+								service = "helloworld.Greeter"
+								method  = "SayHello"
+							}
+						}
+						destination {
+							num_retries                = 3
+							retry_on_grpc_status_codes = ["UNAVAILABLE", "RESOURCE_EXHAUSTED"]
+							per_try_timeout            = "2s"
+						}
+					},
+				]
+			`,
+			camel: `
+				Kind = "service-router"
+				Name = "main"
+				Routes = [
+					{
+						Match {
+							GRPC {
+								Service = "helloworld.Greeter"
+								Method  = "SayHello"
+							}
+						}
+						Destination {
+							NumRetries             = 3
+							RetryOnGRPCStatusCodes = ["UNAVAILABLE", "RESOURCE_EXHAUSTED"]
+							PerTryTimeout          = "2s"
+						}
+					},
+				]
+			`,
+			expect: &ServiceRouterConfigEntry{
+				Kind: "service-router",
+				Name: "main",
+				Routes: []ServiceRoute{
+					{
+						Match: &ServiceRouteMatch{
+							GRPC: &ServiceRouteGRPCMatch{
+								Service: "helloworld.Greeter",
+								Method:  "SayHello",
 							},
 						},
+						Destination: &ServiceRouteDestination{
+							NumRetries:             3,
+							RetryOnGRPCStatusCodes: []string{"UNAVAILABLE", "RESOURCE_EXHAUSTED"},
+							PerTryTimeout:          2 * time.Second,
+						},
 					},
 				},
 			},
@@ -1120,6 +1623,81 @@ func TestDecodeConfigEntry(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "service-resolver: outlier detection",
+			snake: `
+				kind = "service-resolver"
+				name = "main"
+				outlier_detection = {
+					interval = "5s"
+					consecutive_5xx = 3
+					consecutive_gateway_failure = 2
+					success_rate = {
+						minimum_hosts = 3
+						request_volume = 50
+						stdev_factor = 1.5
+					}
+					base_ejection_time = "1m"
+					max_ejection_percent = 50
+				}
+			`,
+			camel: `
+				Kind = "service-resolver"
+				Name = "main"
+				OutlierDetection = {
+					Interval = "5s"
+					Consecutive5xx = 3
+					ConsecutiveGatewayFailure = 2
+					SuccessRate = {
+						MinimumHosts = 3
+						RequestVolume = 50
+						StdevFactor = 1.5
+					}
+					BaseEjectionTime = "1m"
+					MaxEjectionPercent = 50
+				}
+			`,
+			expect: &ServiceResolverConfigEntry{
+				Kind: "service-resolver",
+				Name: "main",
+				OutlierDetection: &OutlierDetection{
+					Interval:                  5 * time.Second,
+					Consecutive5xx:            3,
+					ConsecutiveGatewayFailure: 2,
+					SuccessRate: &OutlierDetectionSuccessRate{
+						MinimumHosts:  3,
+						RequestVolume: 50,
+						StdevFactor:   1.5,
+					},
+					BaseEjectionTime:   time.Minute,
+					MaxEjectionPercent: 50,
+				},
+			},
+		},
+		{
+			name: "service-resolver: prioritize by locality",
+			snake: `
+				kind = "service-resolver"
+				name = "main"
+				prioritize_by_locality = {
+					mode = "failover"
+				}
+			`,
+			camel: `
+				Kind = "service-resolver"
+				Name = "main"
+				PrioritizeByLocality = {
+					Mode = "failover"
+				}
+			`,
+			expect: &ServiceResolverConfigEntry{
+				Kind: "service-resolver",
+				Name: "main",
+				PrioritizeByLocality: &ServiceResolverPrioritizeByLocality{
+					Mode: LocalityPriorityModeFailover,
+				},
+			},
+		},
 		{
 			// TODO(rb): test SDS stuff here in both places (global/service)
 			name: "ingress-gateway: kitchen sink",
@@ -1322,6 +1900,98 @@ func TestDecodeConfigEntry(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "ingress-gateway: cors, request size limit and jwt",
+			snake: `
+				kind = "ingress-gateway"
+				name = "ingress-web"
+				listeners = [
+					{
+						port = 8080
+						protocol = "http"
+						services = [
+							{
+								name = "web"
+								cors {
+									allow_origins = ["https://example.com"]
+									allow_methods = ["GET", "POST"]
+									allow_headers = ["content-type"]
+									expose_headers = ["x-request-id"]
+									max_age = "1h"
+									allow_credentials = true
+								}
+								max_request_bytes = 1048576
+								jwt {
+									providers = [
+										{
+											name = "okta"
+										}
+									]
+								}
+							}
+						]
+					}
+				]
+			`,
+			camel: `
+				Kind = "ingress-gateway"
+				Name = "ingress-web"
+				Listeners = [
+					{
+						Port = 8080
+						Protocol = "http"
+						Services = [
+							{
+								Name = "web"
+								CORS {
+									AllowOrigins = ["https://example.com"]
+									AllowMethods = ["GET", "POST"]
+									AllowHeaders = ["content-type"]
+									ExposeHeaders = ["x-request-id"]
+									MaxAge = "1h"
+									AllowCredentials = true
+								}
+								MaxRequestBytes = 1048576
+								JWT {
+									Providers = [
+										{
+											Name = "okta"
+										}
+									]
+								}
+							}
+						]
+					}
+				]
+			`,
+			expect: &IngressGatewayConfigEntry{
+				Kind: "ingress-gateway",
+				Name: "ingress-web",
+				Listeners: []IngressListener{
+					{
+						Port:     8080,
+						Protocol: "http",
+						Services: []IngressService{
+							{
+								Name: "web",
+								CORS: &IngressCORSConfig{
+									AllowOrigins:     []string{"https://example.com"},
+									AllowMethods:     []string{"GET", "POST"},
+									AllowHeaders:     []string{"content-type"},
+									ExposeHeaders:    []string{"x-request-id"},
+									MaxAge:           time.Hour,
+									AllowCredentials: true,
+								},
+								MaxRequestBytes: 1048576,
+								JWT: &IngressJWTRequirement{
+									Providers: []*IngressJWTProvider{{Name: "okta"}},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
 		{
 			name: "terminating-gateway: kitchen sink",
 			snake: `
@@ -1779,6 +2449,9 @@ func TestDecodeConfigEntry(t *testing.T) {
 						consumers = [
 							{
 								partition = "zoo"
+							},
+							{
+								peer_name = "east"
 							}
 						]
 					}
@@ -1810,6 +2483,9 @@ func TestDecodeConfigEntry(t *testing.T) {
 						Consumers = [
 							{
 								Partition = "zoo"
+							},
+							{
+								PeerName = "east"
 							}
 						]
 					}
@@ -1841,11 +2517,61 @@ func TestDecodeConfigEntry(t *testing.T) {
 							{
 								Partition: "zoo",
 							},
+							{
+								PeerName: "east",
+							},
 						},
 					},
 				},
 			},
 		},
+		{
+			name: "jwt-provider",
+			snake: `
+				kind = "jwt-provider"
+				name = "okta"
+				meta {
+					"foo" = "bar"
+				}
+				issuer = "https://example.okta.com"
+				audiences = ["api", "web"]
+				json_web_key_set {
+					local {
+						filename = "/etc/consul/okta-jwks.json"
+					}
+				}
+				clock_skew_seconds = 30
+			`,
+			camel: `
+				Kind = "jwt-provider"
+				Name = "okta"
+				Meta {
+					"foo" = "bar"
+				}
+				Issuer = "https://example.okta.com"
+				Audiences = ["api", "web"]
+				JSONWebKeySet {
+					Local {
+						Filename = "/etc/consul/okta-jwks.json"
+					}
+				}
+				ClockSkewSeconds = 30
+			`,
+			expect: &JWTProviderConfigEntry{
+				Name: "okta",
+				Meta: map[string]string{
+					"foo": "bar",
+				},
+				Issuer:    "https://example.okta.com",
+				Audiences: []string{"api", "web"},
+				JSONWebKeySet: &JSONWebKeySet{
+					Local: &LocalJWKS{
+						Filename: "/etc/consul/okta-jwks.json",
+					},
+				},
+				ClockSkewSeconds: 30,
+			},
+		},
 	} {
 		tc := tc
 
@@ -2099,6 +2825,162 @@ func TestPassiveHealthCheck_Validate(t *testing.T) {
 	}
 }
 
+func TestAccessLogsConfig_Validate(t *testing.T) {
+	tt := []struct {
+		name    string
+		input   AccessLogsConfig
+		wantErr string
+	}{
+		{
+			name:  "defaults",
+			input: AccessLogsConfig{Enabled: true},
+		},
+		{
+			name: "file with json format",
+			input: AccessLogsConfig{
+				Enabled:    true,
+				Type:       FileLogSinkType,
+				Path:       "/var/log/envoy/access.log",
+				JSONFormat: `{"status": "%RESPONSE_CODE%"}`,
+			},
+		},
+		{
+			name:    "file without path",
+			input:   AccessLogsConfig{Type: FileLogSinkType},
+			wantErr: `Path is required with the "file" type`,
+		},
+		{
+			name:    "path with stdout",
+			input:   AccessLogsConfig{Type: StdOutLogSinkType, Path: "/tmp/log"},
+			wantErr: `Path is only supported with the "file" type`,
+		},
+		{
+			name:    "invalid type",
+			input:   AccessLogsConfig{Type: "syslog"},
+			wantErr: `invalid Type "syslog"`,
+		},
+		{
+			name: "both formats",
+			input: AccessLogsConfig{
+				JSONFormat: `{"status": "%RESPONSE_CODE%"}`,
+				TextFormat: "%RESPONSE_CODE%",
+			},
+			wantErr: "only one of JSONFormat or TextFormat can be set",
+		},
+		{
+			name:    "invalid json format",
+			input:   AccessLogsConfig{JSONFormat: `["%RESPONSE_CODE%"]`},
+			wantErr: "JSONFormat must be a JSON object",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.input.Validate()
+			if tc.wantErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.wantErr)
+		})
+	}
+}
+
+func TestTracingConfig_Validate(t *testing.T) {
+	static := TracingCollector{Address: "zipkin.service.consul", Port: 9411}
+
+	tt := []struct {
+		name    string
+		input   TracingConfig
+		wantErr string
+	}{
+		{
+			name:  "zipkin with static collector",
+			input: TracingConfig{Provider: ZipkinTracingProvider, Collector: static, CollectorEndpoint: "/api/v1/spans"},
+		},
+		{
+			name: "opentelemetry with service collector",
+			input: TracingConfig{
+				Provider:           OpenTelemetryTracingProvider,
+				Collector:          TracingCollector{Service: "otel-collector"},
+				SamplingPercentage: 100,
+				CustomTags:         map[string]string{"env": "prod"},
+			},
+		},
+		{
+			name:    "missing provider",
+			input:   TracingConfig{Collector: static},
+			wantErr: "Provider is required",
+		},
+		{
+			name:    "invalid provider",
+			input:   TracingConfig{Provider: "jaeger", Collector: static},
+			wantErr: `invalid Provider "jaeger"`,
+		},
+		{
+			name:    "collector endpoint with opentelemetry",
+			input:   TracingConfig{Provider: OpenTelemetryTracingProvider, Collector: static, CollectorEndpoint: "/spans"},
+			wantErr: `CollectorEndpoint is only supported with the "zipkin" provider`,
+		},
+		{
+			name:    "relative collector endpoint",
+			input:   TracingConfig{Provider: ZipkinTracingProvider, Collector: static, CollectorEndpoint: "spans"},
+			wantErr: "CollectorEndpoint must begin with a '/'",
+		},
+		{
+			name:    "missing collector",
+			input:   TracingConfig{Provider: ZipkinTracingProvider},
+			wantErr: "one of Collector.Service or Collector.Address is required",
+		},
+		{
+			name: "service and address",
+			input: TracingConfig{
+				Provider:  ZipkinTracingProvider,
+				Collector: TracingCollector{Service: "zipkin", Address: "zipkin.service.consul", Port: 9411},
+			},
+			wantErr: "only one of Collector.Service or Collector.Address can be set",
+		},
+		{
+			name:    "service with port",
+			input:   TracingConfig{Provider: ZipkinTracingProvider, Collector: TracingCollector{Service: "zipkin", Port: 9411}},
+			wantErr: "Collector.Port is only supported with Collector.Address",
+		},
+		{
+			name:    "wildcard service",
+			input:   TracingConfig{Provider: ZipkinTracingProvider, Collector: TracingCollector{Service: WildcardSpecifier}},
+			wantErr: "Collector.Service cannot be a wildcard",
+		},
+		{
+			name:    "address without port",
+			input:   TracingConfig{Provider: ZipkinTracingProvider, Collector: TracingCollector{Address: "zipkin.service.consul"}},
+			wantErr: "Collector.Port must be between 1 and 65535",
+		},
+		{
+			name:    "sampling percentage too high",
+			input:   TracingConfig{Provider: ZipkinTracingProvider, Collector: static, SamplingPercentage: 100.5},
+			wantErr: "SamplingPercentage must be between 0 and 100",
+		},
+		{
+			name:    "empty custom tag",
+			input:   TracingConfig{Provider: ZipkinTracingProvider, Collector: static, CustomTags: map[string]string{"": "prod"}},
+			wantErr: "CustomTags cannot have an empty name",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.input.Validate()
+			if tc.wantErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.wantErr)
+		})
+	}
+}
+
 func TestUpstreamLimits_Validate(t *testing.T) {
 	tt := []struct {
 		name    string
@@ -2107,37 +2989,93 @@ func TestUpstreamLimits_Validate(t *testing.T) {
 		wantMsg string
 	}{
 		{
-			name:    "valid-max-conns",
-			input:   UpstreamLimits{MaxConnections: intPointer(0)},
+			name:    "valid-max-conns",
+			input:   UpstreamLimits{MaxConnections: intPointer(0)},
+			wantErr: false,
+		},
+		{
+			name:    "negative-max-conns",
+			input:   UpstreamLimits{MaxConnections: intPointer(-1)},
+			wantErr: true,
+			wantMsg: "cannot be negative",
+		},
+		{
+			name:    "valid-max-concurrent",
+			input:   UpstreamLimits{MaxConcurrentRequests: intPointer(0)},
+			wantErr: false,
+		},
+		{
+			name:    "negative-max-concurrent",
+			input:   UpstreamLimits{MaxConcurrentRequests: intPointer(-1)},
+			wantErr: true,
+			wantMsg: "cannot be negative",
+		},
+		{
+			name:    "valid-max-pending",
+			input:   UpstreamLimits{MaxPendingRequests: intPointer(0)},
+			wantErr: false,
+		},
+		{
+			name:    "negative-max-pending",
+			input:   UpstreamLimits{MaxPendingRequests: intPointer(-1)},
+			wantErr: true,
+			wantMsg: "cannot be negative",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.input.Validate()
+			if err == nil {
+				require.False(t, tc.wantErr)
+				return
+			}
+			require.Contains(t, err.Error(), tc.wantMsg)
+		})
+	}
+}
+
+func TestConnectionPoolConfig_Validate(t *testing.T) {
+	tt := []struct {
+		name    string
+		input   ConnectionPoolConfig
+		wantErr bool
+		wantMsg string
+	}{
+		{
+			name: "valid",
+			input: ConnectionPoolConfig{
+				TCPIdleTimeoutMs:         intPointer(0),
+				HTTPIdleTimeoutMs:        intPointer(60000),
+				MaxRequestsPerConnection: intPointer(100),
+				MaxConcurrentStreams:     intPointer(50),
+				TCPKeepalive:             &TCPKeepaliveConfig{Probes: 3},
+			},
 			wantErr: false,
 		},
 		{
-			name:    "negative-max-conns",
-			input:   UpstreamLimits{MaxConnections: intPointer(-1)},
+			name:    "negative-tcp-idle-timeout",
+			input:   ConnectionPoolConfig{TCPIdleTimeoutMs: intPointer(-1)},
 			wantErr: true,
-			wantMsg: "cannot be negative",
+			wantMsg: "tcp idle timeout cannot be negative",
 		},
 		{
-			name:    "valid-max-concurrent",
-			input:   UpstreamLimits{MaxConcurrentRequests: intPointer(0)},
-			wantErr: false,
+			name:    "negative-http-idle-timeout",
+			input:   ConnectionPoolConfig{HTTPIdleTimeoutMs: intPointer(-1)},
+			wantErr: true,
+			wantMsg: "http idle timeout cannot be negative",
 		},
 		{
-			name:    "negative-max-concurrent",
-			input:   UpstreamLimits{MaxConcurrentRequests: intPointer(-1)},
+			name:    "negative-max-requests-per-connection",
+			input:   ConnectionPoolConfig{MaxRequestsPerConnection: intPointer(-1)},
 			wantErr: true,
 			wantMsg: "cannot be negative",
 		},
 		{
-			name:    "valid-max-pending",
-			input:   UpstreamLimits{MaxPendingRequests: intPointer(0)},
-			wantErr: false,
-		},
-		{
-			name:    "negative-max-pending",
-			input:   UpstreamLimits{MaxPendingRequests: intPointer(-1)},
+			name:    "zero-max-concurrent-streams",
+			input:   ConnectionPoolConfig{MaxConcurrentStreams: intPointer(0)},
 			wantErr: true,
-			wantMsg: "cannot be negative",
+			wantMsg: "must be at least 1",
 		},
 	}
 
@@ -2148,6 +3086,7 @@ func TestUpstreamLimits_Validate(t *testing.T) {
 				require.False(t, tc.wantErr)
 				return
 			}
+			require.True(t, tc.wantErr)
 			require.Contains(t, err.Error(), tc.wantMsg)
 		})
 	}
@@ -2320,6 +3259,150 @@ func TestServiceConfigEntry(t *testing.T) {
 			},
 			validateErr: `Name is required`,
 		},
+		"normalize: rate limit source inherits enterprise meta": {
+			entry: &ServiceConfigEntry{
+				Name: "web",
+				RateLimit: &RateLimitConfig{
+					RequestsPerSecond: 10,
+					Sources: []RateLimitSource{
+						{Name: "api", RequestsPerSecond: 5},
+					},
+				},
+			},
+			expected: &ServiceConfigEntry{
+				Kind:           ServiceDefaults,
+				Name:           "web",
+				EnterpriseMeta: *DefaultEnterpriseMetaInDefaultPartition(),
+				RateLimit: &RateLimitConfig{
+					RequestsPerSecond: 10,
+					Sources: []RateLimitSource{
+						{
+							Name:              "api",
+							EnterpriseMeta:    *DefaultEnterpriseMetaInDefaultPartition(),
+							RequestsPerSecond: 5,
+						},
+					},
+				},
+			},
+			normalizeOnly: true,
+		},
+		"rate limit: valid": {
+			entry: &ServiceConfigEntry{
+				Name:     "web",
+				Protocol: "http",
+				RateLimit: &RateLimitConfig{
+					RequestsPerSecond: 100,
+					Burst:             200,
+					Routes: []RateLimitRoute{
+						{PathPrefix: "/admin", RequestsPerSecond: 1},
+					},
+					Sources: []RateLimitSource{
+						{Name: "api", RequestsPerSecond: 5},
+					},
+				},
+			},
+		},
+		"rate limit: negative requests per second": {
+			entry: &ServiceConfigEntry{
+				Name: "web",
+				RateLimit: &RateLimitConfig{
+					RequestsPerSecond: -1,
+				},
+			},
+			validateErr: `error in rate limit: RequestsPerSecond cannot be negative`,
+		},
+		"rate limit: burst less than requests per second": {
+			entry: &ServiceConfigEntry{
+				Name: "web",
+				RateLimit: &RateLimitConfig{
+					RequestsPerSecond: 10,
+					Burst:             5,
+				},
+			},
+			validateErr: `Burst cannot be less than RequestsPerSecond`,
+		},
+		"rate limit: routes with tcp protocol": {
+			entry: &ServiceConfigEntry{
+				Name:     "web",
+				Protocol: "tcp",
+				RateLimit: &RateLimitConfig{
+					Routes: []RateLimitRoute{
+						{PathPrefix: "/admin", RequestsPerSecond: 1},
+					},
+				},
+			},
+			validateErr: `Routes and Sources are only supported for HTTP-like protocols`,
+		},
+		"rate limit: route path prefix": {
+			entry: &ServiceConfigEntry{
+				Name: "web",
+				RateLimit: &RateLimitConfig{
+					Routes: []RateLimitRoute{
+						{PathPrefix: "admin", RequestsPerSecond: 1},
+					},
+				},
+			},
+			validateErr: `Routes[0]: PathPrefix must begin with a '/'`,
+		},
+		"rate limit: duplicate route": {
+			entry: &ServiceConfigEntry{
+				Name: "web",
+				RateLimit: &RateLimitConfig{
+					Routes: []RateLimitRoute{
+						{PathPrefix: "/admin", RequestsPerSecond: 1},
+						{PathPrefix: "/admin", RequestsPerSecond: 2},
+					},
+				},
+			},
+			validateErr: `Routes[1]: duplicate PathPrefix "/admin"`,
+		},
+		"rate limit: route without limit": {
+			entry: &ServiceConfigEntry{
+				Name: "web",
+				RateLimit: &RateLimitConfig{
+					Routes: []RateLimitRoute{
+						{PathPrefix: "/admin"},
+					},
+				},
+			},
+			validateErr: `Routes[0]: RequestsPerSecond is required`,
+		},
+		"rate limit: sources without default limit": {
+			entry: &ServiceConfigEntry{
+				Name: "web",
+				RateLimit: &RateLimitConfig{
+					Sources: []RateLimitSource{
+						{Name: "api", RequestsPerSecond: 5},
+					},
+				},
+			},
+			validateErr: `RequestsPerSecond is required when Sources are set`,
+		},
+		"rate limit: wildcard source": {
+			entry: &ServiceConfigEntry{
+				Name: "web",
+				RateLimit: &RateLimitConfig{
+					RequestsPerSecond: 10,
+					Sources: []RateLimitSource{
+						{Name: WildcardSpecifier, RequestsPerSecond: 5},
+					},
+				},
+			},
+			validateErr: `Sources[0]: wildcards are not supported`,
+		},
+		"rate limit: duplicate source": {
+			entry: &ServiceConfigEntry{
+				Name: "web",
+				RateLimit: &RateLimitConfig{
+					RequestsPerSecond: 10,
+					Sources: []RateLimitSource{
+						{Name: "api", RequestsPerSecond: 5},
+						{Name: "api", RequestsPerSecond: 6},
+					},
+				},
+			},
+			validateErr: `Sources[1]: duplicate source "api"`,
+		},
 		"upstream config defaults with name": {
 			entry: &ServiceConfigEntry{
 				Name: "web",
@@ -2373,6 +3456,371 @@ func TestServiceConfigEntry(t *testing.T) {
 				EnterpriseMeta: *DefaultEnterpriseMetaInDefaultPartition(),
 			},
 		},
+		"normalize: ext authz": {
+			entry: &ServiceConfigEntry{
+				Name: "web",
+				ExtAuthz: &ExtAuthzConfig{
+					Service:  "authz",
+					Protocol: "GRPC",
+				},
+			},
+			expected: &ServiceConfigEntry{
+				Kind:           ServiceDefaults,
+				Name:           "web",
+				EnterpriseMeta: *DefaultEnterpriseMetaInDefaultPartition(),
+				ExtAuthz: &ExtAuthzConfig{
+					Service:        "authz",
+					EnterpriseMeta: *DefaultEnterpriseMetaInDefaultPartition(),
+					Protocol:       "grpc",
+				},
+			},
+		},
+		"destination: valid": {
+			entry: &ServiceConfigEntry{
+				Name: "external",
+				Destination: &DestinationConfig{
+					Addresses: []string{"api.example.com", "192.0.2.10", "2001:db8::1"},
+					Port:      443,
+				},
+			},
+		},
+		"destination: http protocol": {
+			entry: &ServiceConfigEntry{
+				Name:     "external",
+				Protocol: "http",
+				Destination: &DestinationConfig{
+					Addresses: []string{"api.example.com"},
+					Port:      443,
+				},
+			},
+			validateErr: `error in destination: destinations only support the "tcp" protocol, got "http"`,
+		},
+		"destination: missing addresses": {
+			entry: &ServiceConfigEntry{
+				Name:        "external",
+				Destination: &DestinationConfig{Port: 443},
+			},
+			validateErr: `error in destination: at least one address is required`,
+		},
+		"destination: invalid port": {
+			entry: &ServiceConfigEntry{
+				Name: "external",
+				Destination: &DestinationConfig{
+					Addresses: []string{"api.example.com"},
+				},
+			},
+			validateErr: `error in destination: port must be between 1 and 65535, got 0`,
+		},
+		"destination: invalid hostname": {
+			entry: &ServiceConfigEntry{
+				Name: "external",
+				Destination: &DestinationConfig{
+					Addresses: []string{"*.example.com"},
+					Port:      443,
+				},
+			},
+			validateErr: `error in destination: address "*.example.com" is neither an IP address nor a valid hostname`,
+		},
+		"destination: duplicate address": {
+			entry: &ServiceConfigEntry{
+				Name: "external",
+				Destination: &DestinationConfig{
+					Addresses: []string{"192.0.2.10", "192.0.2.10"},
+					Port:      443,
+				},
+			},
+			validateErr: `error in destination: address "192.0.2.10" is listed more than once`,
+		},
+		"mutual tls: permissive": {
+			entry: &ServiceConfigEntry{
+				Name:                  "web",
+				MutualTLSMode:         MutualTLSModePermissive,
+				PermissiveSourceCIDRs: []string{"10.0.0.0/16", "2001:db8::/32"},
+			},
+		},
+		"mutual tls: invalid mode": {
+			entry: &ServiceConfigEntry{
+				Name:          "web",
+				MutualTLSMode: "lenient",
+			},
+			validateErr: `Invalid Mutual TLS Mode: "lenient"`,
+		},
+		"mutual tls: invalid source CIDR": {
+			entry: &ServiceConfigEntry{
+				Name:                  "web",
+				MutualTLSMode:         MutualTLSModePermissive,
+				PermissiveSourceCIDRs: []string{"10.0.0.1"},
+			},
+			validateErr: `invalid permissive source CIDR "10.0.0.1"`,
+		},
+		"mutual tls: permissive without source CIDRs": {
+			entry: &ServiceConfigEntry{
+				Name:          "web",
+				MutualTLSMode: MutualTLSModePermissive,
+			},
+			validateErr: "PermissiveSourceCIDRs must be set in permissive mutual TLS mode",
+		},
+		"ext authz: missing service": {
+			entry: &ServiceConfigEntry{
+				Name:     "web",
+				ExtAuthz: &ExtAuthzConfig{},
+			},
+			validateErr: `error in ext_authz: Service is required`,
+		},
+		"ext authz: wildcard service": {
+			entry: &ServiceConfigEntry{
+				Name: "web",
+				ExtAuthz: &ExtAuthzConfig{
+					Service: "*",
+				},
+			},
+			validateErr: `error in ext_authz: Service cannot be a wildcard`,
+		},
+		"ext authz: invalid protocol": {
+			entry: &ServiceConfigEntry{
+				Name: "web",
+				ExtAuthz: &ExtAuthzConfig{
+					Service:  "authz",
+					Protocol: "tcp",
+				},
+			},
+			validateErr: `error in ext_authz: Protocol must be "grpc" or "http", not "tcp"`,
+		},
+		"ext authz: http with tcp service": {
+			entry: &ServiceConfigEntry{
+				Name:     "web",
+				Protocol: "tcp",
+				ExtAuthz: &ExtAuthzConfig{
+					Service:  "authz",
+					Protocol: "http",
+				},
+			},
+			validateErr: `error in ext_authz: Protocol "http" is only supported for HTTP-like services, not "tcp"`,
+		},
+		"ext authz: path prefix with grpc": {
+			entry: &ServiceConfigEntry{
+				Name: "web",
+				ExtAuthz: &ExtAuthzConfig{
+					Service:    "authz",
+					PathPrefix: "/check",
+				},
+			},
+			validateErr: `error in ext_authz: PathPrefix is only supported with the "http" protocol`,
+		},
+		"ext authz: invalid path prefix": {
+			entry: &ServiceConfigEntry{
+				Name:     "web",
+				Protocol: "http",
+				ExtAuthz: &ExtAuthzConfig{
+					Service:    "authz",
+					Protocol:   "http",
+					PathPrefix: "check",
+				},
+			},
+			validateErr: `error in ext_authz: PathPrefix must begin with a '/'`,
+		},
+		"ext authz: negative timeout": {
+			entry: &ServiceConfigEntry{
+				Name: "web",
+				ExtAuthz: &ExtAuthzConfig{
+					Service:   "authz",
+					TimeoutMs: -1,
+				},
+			},
+			validateErr: `error in ext_authz: TimeoutMs cannot be negative`,
+		},
+		"envoy extensions: valid": {
+			entry: &ServiceConfigEntry{
+				Name: "web",
+				EnvoyExtensions: EnvoyExtensions{
+					{
+						Name: "builtin/wasm",
+						Arguments: map[string]interface{}{
+							"Listener": "inbound",
+							"Filename": "/etc/envoy/filter.wasm",
+						},
+					},
+				},
+			},
+		},
+		"envoy extensions: missing name": {
+			entry: &ServiceConfigEntry{
+				Name:            "web",
+				EnvoyExtensions: EnvoyExtensions{{}},
+			},
+			validateErr: `EnvoyExtensions[0]: Name is required`,
+		},
+		"envoy extensions: unknown extension": {
+			entry: &ServiceConfigEntry{
+				Name:            "web",
+				EnvoyExtensions: EnvoyExtensions{{Name: "builtin/nope"}},
+			},
+			validateErr: `EnvoyExtensions[0] (builtin/nope): unknown extension "builtin/nope"`,
+		},
+		"envoy extensions: invalid arguments": {
+			entry: &ServiceConfigEntry{
+				Name: "web",
+				EnvoyExtensions: EnvoyExtensions{
+					{
+						Name: "builtin/lua",
+						Arguments: map[string]interface{}{
+							"Listener": "inbound",
+							"Script":   "function envoy_on_request(h) end",
+						},
+					},
+					{
+						Name: "builtin/lua",
+						Arguments: map[string]interface{}{
+							"Listener": "outbound",
+						},
+					},
+				},
+			},
+			validateErr: `EnvoyExtensions[1] (builtin/lua): Script is required`,
+		},
+	}
+	testConfigEntryNormalizeAndValidate(t, cases)
+}
+
+func TestServiceConfigEntry_Clone(t *testing.T) {
+	orig := &ServiceConfigEntry{
+		Kind: ServiceDefaults,
+		Name: "web",
+		EnvoyExtensions: EnvoyExtensions{
+			{
+				Name: "builtin/lua",
+				Arguments: map[string]interface{}{
+					"ProxyType": "connect-proxy",
+					"Listener":  "inbound",
+					"Nested":    map[string]interface{}{"Key": "value"},
+					"List":      []interface{}{"a"},
+				},
+			},
+		},
+	}
+
+	clone := orig.Clone()
+	require.Equal(t, orig, clone)
+
+	clone.EnvoyExtensions[0].Arguments["Listener"] = "outbound"
+	clone.EnvoyExtensions[0].Arguments["Nested"].(map[string]interface{})["Key"] = "changed"
+	clone.EnvoyExtensions[0].Arguments["List"].([]interface{})[0] = "b"
+
+	require.Equal(t, "inbound", orig.EnvoyExtensions[0].Arguments["Listener"])
+	require.Equal(t, map[string]interface{}{"Key": "value"}, orig.EnvoyExtensions[0].Arguments["Nested"])
+	require.Equal(t, []interface{}{"a"}, orig.EnvoyExtensions[0].Arguments["List"])
+}
+
+func TestJWTProviderConfigEntry(t *testing.T) {
+	const jwks = `{"keys": [{"kty": "EC", "crv": "P-256", "x": "x", "y": "y"}]}`
+
+	localJWKS := func(l *LocalJWKS) *JSONWebKeySet {
+		return &JSONWebKeySet{Local: l}
+	}
+
+	cases := map[string]configEntryTestcase{
+		"valid inline": {
+			entry: &JWTProviderConfigEntry{
+				Name:          "okta",
+				Issuer:        "https://example.okta.com",
+				JSONWebKeySet: localJWKS(&LocalJWKS{JWKS: jwks}),
+			},
+			expected: &JWTProviderConfigEntry{
+				Name:           "okta",
+				Issuer:         "https://example.okta.com",
+				JSONWebKeySet:  localJWKS(&LocalJWKS{JWKS: jwks}),
+				EnterpriseMeta: *DefaultEnterpriseMetaInDefaultPartition(),
+			},
+		},
+		"valid filename": {
+			entry: &JWTProviderConfigEntry{
+				Name:             "okta",
+				Issuer:           "https://example.okta.com",
+				Audiences:        []string{"api"},
+				JSONWebKeySet:    localJWKS(&LocalJWKS{Filename: "/etc/consul/okta-jwks.json"}),
+				ClockSkewSeconds: 30,
+			},
+			expected: &JWTProviderConfigEntry{
+				Name:             "okta",
+				Issuer:           "https://example.okta.com",
+				Audiences:        []string{"api"},
+				JSONWebKeySet:    localJWKS(&LocalJWKS{Filename: "/etc/consul/okta-jwks.json"}),
+				ClockSkewSeconds: 30,
+				EnterpriseMeta:   *DefaultEnterpriseMetaInDefaultPartition(),
+			},
+		},
+		"wildcard name": {
+			entry: &JWTProviderConfigEntry{
+				Name:          "*",
+				Issuer:        "https://example.okta.com",
+				JSONWebKeySet: localJWKS(&LocalJWKS{JWKS: jwks}),
+			},
+			validateErr: "Name cannot be a wildcard",
+		},
+		"missing issuer": {
+			entry: &JWTProviderConfigEntry{
+				Name:          "okta",
+				JSONWebKeySet: localJWKS(&LocalJWKS{JWKS: jwks}),
+			},
+			validateErr: "Issuer is required",
+		},
+		"empty audience": {
+			entry: &JWTProviderConfigEntry{
+				Name:          "okta",
+				Issuer:        "https://example.okta.com",
+				Audiences:     []string{"api", ""},
+				JSONWebKeySet: localJWKS(&LocalJWKS{JWKS: jwks}),
+			},
+			validateErr: "Audiences[1] cannot be empty",
+		},
+		"negative clock skew": {
+			entry: &JWTProviderConfigEntry{
+				Name:             "okta",
+				Issuer:           "https://example.okta.com",
+				JSONWebKeySet:    localJWKS(&LocalJWKS{JWKS: jwks}),
+				ClockSkewSeconds: -1,
+			},
+			validateErr: "ClockSkewSeconds cannot be negative",
+		},
+		"missing key set": {
+			entry: &JWTProviderConfigEntry{
+				Name:   "okta",
+				Issuer: "https://example.okta.com",
+			},
+			validateErr: "JSONWebKeySet.Local is required",
+		},
+		"both inline and filename": {
+			entry: &JWTProviderConfigEntry{
+				Name:          "okta",
+				Issuer:        "https://example.okta.com",
+				JSONWebKeySet: localJWKS(&LocalJWKS{JWKS: jwks, Filename: "/etc/consul/okta-jwks.json"}),
+			},
+			validateErr: "must set only one of JWKS or Filename",
+		},
+		"neither inline nor filename": {
+			entry: &JWTProviderConfigEntry{
+				Name:          "okta",
+				Issuer:        "https://example.okta.com",
+				JSONWebKeySet: localJWKS(&LocalJWKS{}),
+			},
+			validateErr: "must set one of JWKS or Filename",
+		},
+		"invalid inline": {
+			entry: &JWTProviderConfigEntry{
+				Name:          "okta",
+				Issuer:        "https://example.okta.com",
+				JSONWebKeySet: localJWKS(&LocalJWKS{JWKS: "not json"}),
+			},
+			validateErr: "JWKS is not a valid JSON Web Key Set",
+		},
+		"inline without keys": {
+			entry: &JWTProviderConfigEntry{
+				Name:          "okta",
+				Issuer:        "https://example.okta.com",
+				JSONWebKeySet: localJWKS(&LocalJWKS{JWKS: `{"keys": []}`}),
+			},
+			validateErr: "JWKS must contain at least one key",
+		},
 	}
 	testConfigEntryNormalizeAndValidate(t, cases)
 }
@@ -2650,6 +4098,37 @@ func TestParseUpstreamConfig(t *testing.T) {
 				Protocol: "tcp",
 			},
 		},
+		{
+			name: "connection pool map",
+			input: map[string]interface{}{
+				"connection_pool": map[string]interface{}{
+					"tcp_idle_timeout_ms":         0,
+					"http_idle_timeout_ms":        "30000",
+					"max_requests_per_connection": 100,
+					"max_concurrent_streams":      50,
+					"tcp_keepalive": map[string]interface{}{
+						"probes":           3,
+						"time_seconds":     60,
+						"interval_seconds": 10,
+					},
+				},
+			},
+			want: UpstreamConfig{
+				ConnectTimeoutMs: 5000,
+				ConnectionPool: &ConnectionPoolConfig{
+					TCPIdleTimeoutMs:         intPointer(0),
+					HTTPIdleTimeoutMs:        intPointer(30000),
+					MaxRequestsPerConnection: intPointer(100),
+					MaxConcurrentStreams:     intPointer(50),
+					TCPKeepalive: &TCPKeepaliveConfig{
+						Probes:          3,
+						TimeSeconds:     60,
+						IntervalSeconds: 10,
+					},
+				},
+				Protocol: "tcp",
+			},
+		},
 		{
 			name: "mesh gateway map",
 			input: map[string]interface{}{
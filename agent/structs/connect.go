@@ -19,6 +19,20 @@ type ConnectAuthorizeRequest struct {
 	// lists.
 	ClientCertURI    string
 	ClientCertSerial string
+
+	// HTTP has the attributes of the request being authorized, for callers
+	// authorizing each HTTP request rather than whole connections. When it is
+	// set the permissions of L7 intentions are evaluated against the request,
+	// otherwise any L7 intention denies it.
+	HTTP *ConnectAuthorizeHTTPRequest `json:",omitempty"`
+}
+
+// ConnectAuthorizeHTTPRequest has the attributes of an HTTP request that the
+// permissions of L7 intentions match against.
+type ConnectAuthorizeHTTPRequest struct {
+	Method string
+	Path   string
+	Header map[string][]string `json:",omitempty"`
 }
 
 func (req *ConnectAuthorizeRequest) TargetPartition() string {
@@ -53,6 +53,16 @@ type IndexedCARoots struct {
 	// Roots is a list of root CA certs to trust.
 	Roots []*CARoot
 
+	// RevocationLists is a list of PEM-encoded certificate revocation lists
+	// published by the CAs in the federation. It is only populated while at
+	// least one certificate is revoked by serial number, since proxies reject
+	// any chain which has a CA without a matching list once one is configured.
+	RevocationLists []string `json:",omitempty"`
+
+	// RevokedSpiffeIDs is a list of SPIFFE IDs whose certificates are no
+	// longer trusted regardless of the certificate serial number.
+	RevokedSpiffeIDs []string `json:",omitempty"`
+
 	// QueryMeta contains the meta sent via a header. We ignore for JSON
 	// so this whole structure can be returned.
 	QueryMeta `json:"-"`
@@ -235,6 +245,125 @@ type IssuedCert struct {
 	RaftIndex
 }
 
+// CARevocation revokes either a single leaf certificate identified by its
+// serial number, or every certificate issued for a SPIFFE ID.
+type CARevocation struct {
+	// ID is a unique identifier for the revocation.
+	ID string
+
+	// SerialNumber is the serial number of the revoked certificate, encoded
+	// in standard hex separated by :. Serial numbers are only unique per CA,
+	// so Datacenter must name the datacenter whose CA issued the certificate.
+	SerialNumber string `json:",omitempty"`
+
+	// Datacenter is the datacenter whose CA issued the certificate revoked by
+	// SerialNumber.
+	Datacenter string `json:",omitempty"`
+
+	// SpiffeID is the SPIFFE ID of the revoked identity, for example
+	// spiffe://<trust-domain>/ns/default/dc/dc1/svc/web.
+	SpiffeID string `json:",omitempty"`
+
+	// Reason is a human-friendly explanation of the revocation. It is opaque
+	// to Consul.
+	Reason string `json:",omitempty"`
+
+	// RevokedAt is the time at which the revocation was made.
+	RevokedAt time.Time
+
+	// ExpiresAt is the time after which no certificate affected by this
+	// revocation can still be valid, at which point it is pruned.
+	ExpiresAt time.Time
+
+	RaftIndex
+}
+
+// Validate checks that exactly one of SerialNumber or SpiffeID is set.
+func (r *CARevocation) Validate() error {
+	switch {
+	case r.SerialNumber == "" && r.SpiffeID == "":
+		return fmt.Errorf("must provide either SerialNumber or SpiffeID")
+	case r.SerialNumber != "" && r.SpiffeID != "":
+		return fmt.Errorf("cannot provide both SerialNumber and SpiffeID")
+	case r.SerialNumber != "" && r.Datacenter == "":
+		return fmt.Errorf("must provide the Datacenter of the CA that issued SerialNumber")
+	}
+	return nil
+}
+
+// CARevocationList is a certificate revocation list signed by the CA that
+// issues leaf certificates in a datacenter.
+type CARevocationList struct {
+	// SigningKeyID is the connect.HexString encoded id of the key that signed
+	// the list, matching CARoot.SigningKeyID.
+	SigningKeyID string
+
+	// Datacenter is the datacenter whose CA signed the list.
+	Datacenter string
+
+	// PEM is the PEM-encoded X.509 CRL.
+	PEM string
+
+	// SerialNumbers are the serial numbers revoked by the list, encoded in
+	// standard hex separated by :.
+	SerialNumbers []string
+
+	// NextUpdate is the time at which the list expires and must be
+	// regenerated.
+	NextUpdate time.Time
+
+	RaftIndex
+}
+
+// IndexedCARevocations is the list of currently revoked certificates and the
+// revocation lists that enforce them.
+type IndexedCARevocations struct {
+	Revocations     []*CARevocation
+	RevocationLists []*CARevocationList
+
+	// QueryMeta contains the meta sent via a header. We ignore for JSON
+	// so this whole structure can be returned.
+	QueryMeta `json:"-"`
+}
+
+// CARevocationRequest is the request for adding or removing a revocation.
+type CARevocationRequest struct {
+	// Datacenter is the target for this request.
+	Datacenter string
+
+	// Revocation is the revocation to add. When removing, only the ID needs
+	// to be set.
+	Revocation CARevocation
+
+	// WriteRequest is a common struct containing ACL tokens and other
+	// write-related common elements for requests.
+	WriteRequest
+}
+
+// RequestDatacenter returns the datacenter for a given request.
+func (q *CARevocationRequest) RequestDatacenter() string {
+	return q.Datacenter
+}
+
+// CARevocationListRequest is the request used by secondary datacenters to
+// publish the revocation list signed by their CA to the primary datacenter.
+type CARevocationListRequest struct {
+	// Datacenter is the target for this request.
+	Datacenter string
+
+	// RevocationList is the list to publish.
+	RevocationList *CARevocationList
+
+	// WriteRequest is a common struct containing ACL tokens and other
+	// write-related common elements for requests.
+	WriteRequest
+}
+
+// RequestDatacenter returns the datacenter for a given request.
+func (q *CARevocationListRequest) RequestDatacenter() string {
+	return q.Datacenter
+}
+
 // CAOp is the operation for a request related to intentions.
 type CAOp string
 
@@ -245,6 +374,10 @@ const (
 	CAOpDeleteProviderState           CAOp = "delete-provider-state"
 	CAOpSetRootsAndConfig             CAOp = "set-roots-config"
 	CAOpIncrementProviderSerialNumber CAOp = "increment-provider-serial"
+	CAOpRevoke                        CAOp = "revoke"
+	CAOpDeleteRevocation              CAOp = "delete-revocation"
+	CAOpSetRevocations                CAOp = "set-revocations"
+	CAOpSetRevocationList             CAOp = "set-revocation-list"
 )
 
 // CARequest is used to modify connect CA data. This is used by the
@@ -270,6 +403,19 @@ type CARequest struct {
 	// ProviderState is the state for the builtin CA provider.
 	ProviderState *CAConsulProviderState
 
+	// Revocation is the revocation to add for CAOpRevoke or the one to delete
+	// for CAOpDeleteRevocation.
+	Revocation *CARevocation
+
+	// Revocations and RevocationLists replace all of the stored revocations
+	// and published revocation lists for CAOpSetRevocations. This is used by
+	// secondary datacenters to replicate them from the primary.
+	Revocations     []*CARevocation
+	RevocationLists []*CARevocationList
+
+	// RevocationList is the revocation list to store for CAOpSetRevocationList.
+	RevocationList *CARevocationList
+
 	// WriteRequest is a common struct containing ACL tokens and other
 	// write-related common elements for requests.
 	WriteRequest
@@ -284,6 +430,7 @@ const (
 	ConsulCAProvider = "consul"
 	VaultCAProvider  = "vault"
 	AWSCAProvider    = "aws-pca"
+	PKCS11CAProvider = "pkcs11"
 )
 
 // CAConfiguration is the configuration for the current CA plugin.
@@ -377,6 +524,14 @@ type CommonCAProviderConfig struct {
 	IntermediateCertTTL time.Duration
 	RootCertTTL         time.Duration
 
+	// RootRotationPeriod is how long a root certificate generated by the
+	// provider is used before the leader automatically rotates to a new root.
+	// The new root is cross-signed by the old one so that existing leaf
+	// certificates keep working during the rotation. Only providers that
+	// manage their own root key material support automatic rotation. Zero
+	// disables automatic rotation.
+	RootRotationPeriod time.Duration
+
 	SkipValidate bool
 
 	// CSRMaxPerSecond is a rate limit on processing Connect Certificate Signing
@@ -425,6 +580,10 @@ var MaxLeafCertTTL = 365 * 24 * time.Hour
 // of the intermediate cert is checked and renewed if necessary.
 var IntermediateCertRenewInterval = time.Hour
 
+// RootRotationCheckInterval is the interval at which the age of the active
+// root is checked against the RootRotationPeriod.
+var RootRotationCheckInterval = time.Hour
+
 func (c CommonCAProviderConfig) Validate() error {
 	if c.SkipValidate {
 		return nil
@@ -471,6 +630,23 @@ func (c CommonCAProviderConfig) Validate() error {
 		return fmt.Errorf("Intermediate Cert TTL must be greater or equal than 3 * LeafCertTTL (>=%s).", 3*c.LeafCertTTL)
 	}
 
+	if c.RootRotationPeriod < 0 {
+		return fmt.Errorf("root rotation period must not be negative")
+	}
+	if c.RootRotationPeriod > 0 {
+		// Old roots are pruned once they have been rotated out for twice the
+		// leaf cert TTL, so rotating more often than that would keep
+		// accumulating trusted roots.
+		if c.RootRotationPeriod < 2*c.LeafCertTTL {
+			return fmt.Errorf("root rotation period must be greater or equal than 2 * LeafCertTTL (>=%s)", 2*c.LeafCertTTL)
+		}
+		// Intermediates signed by the old root must be able to outlive the
+		// rotation without the old root expiring underneath them.
+		if c.RootRotationPeriod > c.RootCertTTL-c.IntermediateCertTTL {
+			return fmt.Errorf("root rotation period must be less or equal than RootCertTTL - IntermediateCertTTL (<=%s)", c.RootCertTTL-c.IntermediateCertTTL)
+		}
+	}
+
 	switch c.PrivateKeyType {
 	case "ec":
 		if c.PrivateKeyBits != 224 && c.PrivateKeyBits != 256 && c.PrivateKeyBits != 384 && c.PrivateKeyBits != 521 {
@@ -546,6 +722,23 @@ type AWSCAProviderConfig struct {
 	DeleteOnExit bool
 }
 
+type PKCS11CAProviderConfig struct {
+	CommonCAProviderConfig `mapstructure:",squash"`
+
+	// LibPath is the path to the PKCS#11 module (shared library) of the HSM.
+	LibPath string
+
+	// TokenLabel is the label of the token that holds the CA signing key.
+	TokenLabel string
+
+	// PIN is the user PIN used to log into the token.
+	PIN string
+
+	// KeyLabel is the label of the signing key and certificate objects on the
+	// token. Defaults to "consul-connect-ca-<datacenter>".
+	KeyLabel string
+}
+
 // CALeafOp is the operation for a request related to leaf certificates.
 type CALeafOp string
 
@@ -151,6 +151,44 @@ func TestCAProviderConfig_Validate(t *testing.T) {
 			wantErr: true,
 			wantMsg: "root cert TTL is set and is not greater than intermediate cert ttl. root cert ttl: 3h0m0s, intermediate cert ttl: 4h0m0s",
 		},
+		{
+			name: "good root rotation period",
+			cfg: &CommonCAProviderConfig{
+				LeafCertTTL:         1 * time.Hour,
+				IntermediateCertTTL: 4 * time.Hour,
+				RootCertTTL:         10 * time.Hour,
+				RootRotationPeriod:  6 * time.Hour,
+				PrivateKeyType:      "ec",
+				PrivateKeyBits:      256,
+			},
+			wantErr: false,
+		},
+		{
+			name: "root rotation period too short",
+			cfg: &CommonCAProviderConfig{
+				LeafCertTTL:         2 * time.Hour,
+				IntermediateCertTTL: 6 * time.Hour,
+				RootCertTTL:         10 * time.Hour,
+				RootRotationPeriod:  3 * time.Hour,
+				PrivateKeyType:      "ec",
+				PrivateKeyBits:      256,
+			},
+			wantErr: true,
+			wantMsg: "root rotation period must be greater or equal than 2 * LeafCertTTL (>=4h0m0s)",
+		},
+		{
+			name: "root rotation period too long",
+			cfg: &CommonCAProviderConfig{
+				LeafCertTTL:         1 * time.Hour,
+				IntermediateCertTTL: 4 * time.Hour,
+				RootCertTTL:         10 * time.Hour,
+				RootRotationPeriod:  7 * time.Hour,
+				PrivateKeyType:      "ec",
+				PrivateKeyBits:      256,
+			},
+			wantErr: true,
+			wantMsg: "root rotation period must be less or equal than RootCertTTL - IntermediateCertTTL (<=6h0m0s)",
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -126,6 +126,54 @@ func ValidateProxyMode(mode string) (ProxyMode, error) {
 	}
 }
 
+type MutualTLSMode string
+
+const (
+	// MutualTLSModeDefault represents no specific mode and should
+	// be used to indicate that a different layer of the configuration
+	// chain should take precedence
+	MutualTLSModeDefault MutualTLSMode = ""
+
+	// MutualTLSModeStrict represents that the proxy's public listener only
+	// accepts mTLS connections.
+	MutualTLSModeStrict MutualTLSMode = "strict"
+
+	// MutualTLSModePermissive represents that the proxy's public listener
+	// also accepts plaintext connections, from the permissive source CIDRs.
+	MutualTLSModePermissive MutualTLSMode = "permissive"
+)
+
+func ValidateMutualTLSMode(mode string) (MutualTLSMode, error) {
+	switch MutualTLSMode(mode) {
+	case MutualTLSModeDefault:
+		return MutualTLSModeDefault, nil
+	case MutualTLSModeStrict:
+		return MutualTLSModeStrict, nil
+	case MutualTLSModePermissive:
+		return MutualTLSModePermissive, nil
+	default:
+		return MutualTLSModeDefault, fmt.Errorf("Invalid Mutual TLS Mode: %q", mode)
+	}
+}
+
+// validateMutualTLS validates the mutual TLS mode of a proxy-defaults or
+// service-defaults config entry, and the CIDRs plaintext connections are
+// accepted from in permissive mode.
+func validateMutualTLS(mode MutualTLSMode, sourceCIDRs []string) error {
+	if _, err := ValidateMutualTLSMode(string(mode)); err != nil {
+		return err
+	}
+	if mode == MutualTLSModePermissive && len(sourceCIDRs) == 0 {
+		return fmt.Errorf("PermissiveSourceCIDRs must be set in permissive mutual TLS mode")
+	}
+	for _, cidr := range sourceCIDRs {
+		if _, _, err := net.ParseCIDR(cidr); err != nil {
+			return fmt.Errorf("invalid permissive source CIDR %q: %v", cidr, err)
+		}
+	}
+	return nil
+}
+
 type TransparentProxyConfig struct {
 	// The port of the listener where outbound application traffic is being redirected to.
 	OutboundListenerPort int `json:",omitempty" alias:"outbound_listener_port"`
@@ -340,6 +388,11 @@ type Upstream struct {
 	DestinationPartition string `json:",omitempty" alias:"destination_partition"`
 	DestinationName      string `alias:"destination_name"`
 
+	// DestinationPeer is the name of the cluster peer the destination service is
+	// imported from. Peered upstreams are always reached through the mesh
+	// gateways of the peer, and cannot be combined with Datacenter.
+	DestinationPeer string `json:",omitempty" alias:"destination_peer"`
+
 	// Datacenter that the service discovery request should be run against. Note
 	// for prepared queries, the actual results might be from a different
 	// datacenter.
@@ -385,6 +438,7 @@ func (t *Upstream) UnmarshalJSON(data []byte) (err error) {
 		DestinationPartitionSnake string `json:"destination_partition"`
 		DestinationNamespaceSnake string `json:"destination_namespace"`
 		DestinationNameSnake      string `json:"destination_name"`
+		DestinationPeerSnake      string `json:"destination_peer"`
 
 		LocalBindAddressSnake string `json:"local_bind_address"`
 		LocalBindPortSnake    int    `json:"local_bind_port"`
@@ -413,6 +467,9 @@ func (t *Upstream) UnmarshalJSON(data []byte) (err error) {
 	if t.DestinationName == "" {
 		t.DestinationName = aux.DestinationNameSnake
 	}
+	if t.DestinationPeer == "" {
+		t.DestinationPeer = aux.DestinationPeerSnake
+	}
 	if t.LocalBindAddress == "" {
 		t.LocalBindAddress = aux.LocalBindAddressSnake
 	}
@@ -448,6 +505,15 @@ func (u *Upstream) Validate() error {
 		return fmt.Errorf("upstream destination name cannot be a wildcard")
 	}
 
+	if u.DestinationPeer != "" {
+		if u.DestinationType == UpstreamDestTypePreparedQuery {
+			return fmt.Errorf("upstream destination peer cannot be set for prepared query upstreams")
+		}
+		if u.Datacenter != "" {
+			return fmt.Errorf("upstream destination peer and datacenter cannot both be set")
+		}
+	}
+
 	if u.LocalBindPort == 0 && u.LocalBindSocketPath == "" && !u.CentrallyConfigured {
 		return fmt.Errorf("upstream local bind port or local socket path must be defined and nonzero")
 	}
@@ -468,6 +534,7 @@ func (u *Upstream) ToAPI() api.Upstream {
 		DestinationNamespace: u.DestinationNamespace,
 		DestinationPartition: u.DestinationPartition,
 		DestinationName:      u.DestinationName,
+		DestinationPeer:      u.DestinationPeer,
 		Datacenter:           u.Datacenter,
 		LocalBindAddress:     u.LocalBindAddress,
 		LocalBindPort:        u.LocalBindPort,
@@ -490,6 +557,7 @@ func (u *Upstream) ToKey() UpstreamKey {
 		DestinationPartition: u.DestinationPartition,
 		DestinationNamespace: u.DestinationNamespace,
 		DestinationName:      u.DestinationName,
+		DestinationPeer:      u.DestinationPeer,
 		Datacenter:           u.Datacenter,
 	}
 }
@@ -528,16 +596,18 @@ type UpstreamKey struct {
 	DestinationName      string
 	DestinationPartition string
 	DestinationNamespace string
+	DestinationPeer      string
 	Datacenter           string
 }
 
 func (k UpstreamKey) String() string {
 	return fmt.Sprintf(
-		"[type=%q, name=%q, partition=%q, namespace=%q, datacenter=%q]",
+		"[type=%q, name=%q, partition=%q, namespace=%q, peer=%q, datacenter=%q]",
 		k.DestinationType,
 		k.DestinationName,
 		k.DestinationPartition,
 		k.DestinationNamespace,
+		k.DestinationPeer,
 		k.Datacenter,
 	)
 }
@@ -551,6 +621,9 @@ func (us *Upstream) String() string {
 	if us.Datacenter != "" {
 		name += "?dc=" + us.Datacenter
 	}
+	if us.DestinationPeer != "" {
+		name += "?peer=" + us.DestinationPeer
+	}
 
 	// Service is default type so never prefix it.
 	if typ == "" || typ == UpstreamDestTypeService {
@@ -566,6 +639,7 @@ func UpstreamFromAPI(u api.Upstream) Upstream {
 		DestinationPartition: u.DestinationPartition,
 		DestinationNamespace: u.DestinationNamespace,
 		DestinationName:      u.DestinationName,
+		DestinationPeer:      u.DestinationPeer,
 		Datacenter:           u.Datacenter,
 		LocalBindAddress:     u.LocalBindAddress,
 		LocalBindPort:        u.LocalBindPort,
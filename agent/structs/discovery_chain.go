@@ -129,6 +129,13 @@ type DiscoveryResolver struct {
 	ConnectTimeout time.Duration      `json:",omitempty"`
 	Target         string             `json:",omitempty"`
 	Failover       *DiscoveryFailover `json:",omitempty"`
+
+	// OutlierDetection is the ejection policy of the target service.
+	OutlierDetection *OutlierDetection `json:",omitempty"`
+
+	// PrioritizeByLocality determines whether the instances of the target
+	// service are prioritized by locality.
+	PrioritizeByLocality *ServiceResolverPrioritizeByLocality `json:",omitempty"`
 }
 
 func (r *DiscoveryResolver) MarshalJSON() ([]byte, error) {
@@ -171,6 +178,10 @@ func (r *DiscoveryResolver) UnmarshalJSON(data []byte) error {
 type DiscoveryRoute struct {
 	Definition *ServiceRoute `json:",omitempty"`
 	NextNode   string        `json:",omitempty"`
+
+	// MirrorNode is the resolver node that a copy of the requests matching
+	// this route are sent to, if the route has a Mirror configured.
+	MirrorNode string `json:",omitempty"`
 }
 
 // compiled form of ServiceSplit
@@ -0,0 +1,62 @@
+package structs
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/mitchellh/copystructure"
+
+	"github.com/hashicorp/consul/agent/envoyextensions"
+)
+
+// EnvoyExtension has the configuration of an extension patching the Envoy
+// resources Consul generates for a proxy. The available extensions and their
+// arguments are defined in the envoyextensions package.
+type EnvoyExtension struct {
+	Name      string
+	Arguments map[string]interface{} `bexpr:"-"`
+}
+
+// EnvoyExtensions is a list of extensions, applied in order.
+type EnvoyExtensions []EnvoyExtension
+
+// Clone returns a deep copy of the extensions, so the nested Arguments of the
+// copy can be modified without changing the original.
+func (e EnvoyExtensions) Clone() EnvoyExtensions {
+	if e == nil {
+		return nil
+	}
+	out := make(EnvoyExtensions, len(e))
+	for i, ext := range e {
+		out[i] = ext.Clone()
+	}
+	return out
+}
+
+func (e EnvoyExtension) Clone() EnvoyExtension {
+	if e.Arguments == nil {
+		return e
+	}
+	// Arguments only holds values decoded from JSON or HCL, which copystructure
+	// can always copy.
+	if args, err := copystructure.Copy(e.Arguments); err == nil {
+		e.Arguments = args.(map[string]interface{})
+	}
+	return e
+}
+
+// Validate returns the errors of every invalid extension, so they can all be
+// fixed at once.
+func (e EnvoyExtensions) Validate() error {
+	var result error
+	for i, ext := range e {
+		if ext.Name == "" {
+			result = multierror.Append(result, fmt.Errorf("EnvoyExtensions[%d]: Name is required", i))
+			continue
+		}
+		if err := envoyextensions.Validate(ext.Name, ext.Arguments); err != nil {
+			result = multierror.Append(result, fmt.Errorf("EnvoyExtensions[%d] (%s): %v", i, ext.Name, err))
+		}
+	}
+	return result
+}
@@ -0,0 +1,75 @@
+package structs
+
+const (
+	// MetaKeyLocalityRegion is the service or node meta key placing an
+	// instance in a region of its datacenter.
+	MetaKeyLocalityRegion = "locality-region"
+
+	// MetaKeyLocalityZone is the service or node meta key placing an instance
+	// in a zone of its region.
+	MetaKeyLocalityZone = "locality-zone"
+)
+
+// Locality identifies where an instance runs within its datacenter.
+type Locality struct {
+	Region string `json:",omitempty"`
+	Zone   string `json:",omitempty"`
+}
+
+// LocalityFromMeta returns the locality described by the given service and
+// node meta. Each key set in the service meta takes precedence over the node
+// meta. It returns nil if neither sets a region or a zone.
+func LocalityFromMeta(serviceMeta, nodeMeta map[string]string) *Locality {
+	lookup := func(key string) string {
+		if v := serviceMeta[key]; v != "" {
+			return v
+		}
+		return nodeMeta[key]
+	}
+
+	l := &Locality{
+		Region: lookup(MetaKeyLocalityRegion),
+		Zone:   lookup(MetaKeyLocalityZone),
+	}
+	if l.IsEmpty() {
+		return nil
+	}
+	return l
+}
+
+// IsEmpty returns true if the locality is nil or has neither a region nor a
+// zone.
+func (l *Locality) IsEmpty() bool {
+	return l == nil || (l.Region == "" && l.Zone == "")
+}
+
+// SameRegion returns true if both localities are in the same, non-empty,
+// region.
+func (l *Locality) SameRegion(other *Locality) bool {
+	if l.IsEmpty() || other.IsEmpty() {
+		return false
+	}
+	return l.Region != "" && l.Region == other.Region
+}
+
+// SameZone returns true if both localities are in the same, non-empty, zone
+// of the same region.
+func (l *Locality) SameZone(other *Locality) bool {
+	if l.IsEmpty() || other.IsEmpty() {
+		return false
+	}
+	return l.Region == other.Region && l.Zone != "" && l.Zone == other.Zone
+}
+
+// Locality returns the locality of the service instance, taken from its
+// service meta and falling back to its node meta.
+func (csn *CheckServiceNode) Locality() *Locality {
+	var serviceMeta, nodeMeta map[string]string
+	if csn.Service != nil {
+		serviceMeta = csn.Service.Meta
+	}
+	if csn.Node != nil {
+		nodeMeta = csn.Node.Meta
+	}
+	return LocalityFromMeta(serviceMeta, nodeMeta)
+}
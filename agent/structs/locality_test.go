@@ -0,0 +1,63 @@
+package structs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalityFromMeta(t *testing.T) {
+	cases := map[string]struct {
+		serviceMeta map[string]string
+		nodeMeta    map[string]string
+		expect      *Locality
+	}{
+		"none": {
+			serviceMeta: map[string]string{"foo": "bar"},
+			expect:      nil,
+		},
+		"node meta": {
+			nodeMeta: map[string]string{
+				MetaKeyLocalityRegion: "us-east-1",
+				MetaKeyLocalityZone:   "us-east-1a",
+			},
+			expect: &Locality{Region: "us-east-1", Zone: "us-east-1a"},
+		},
+		"service meta overrides node meta per key": {
+			serviceMeta: map[string]string{
+				MetaKeyLocalityZone: "us-east-1b",
+			},
+			nodeMeta: map[string]string{
+				MetaKeyLocalityRegion: "us-east-1",
+				MetaKeyLocalityZone:   "us-east-1a",
+			},
+			expect: &Locality{Region: "us-east-1", Zone: "us-east-1b"},
+		},
+	}
+
+	for name, tc := range cases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			require.Equal(t, tc.expect, LocalityFromMeta(tc.serviceMeta, tc.nodeMeta))
+		})
+	}
+}
+
+func TestLocality_SameRegionAndZone(t *testing.T) {
+	east1a := &Locality{Region: "us-east-1", Zone: "us-east-1a"}
+	east1b := &Locality{Region: "us-east-1", Zone: "us-east-1b"}
+	west2a := &Locality{Region: "us-west-2", Zone: "us-west-2a"}
+	zoneOnly := &Locality{Zone: "us-east-1a"}
+
+	require.True(t, east1a.SameZone(&Locality{Region: "us-east-1", Zone: "us-east-1a"}))
+	require.True(t, east1a.SameRegion(east1b))
+	require.False(t, east1a.SameZone(east1b))
+	require.False(t, east1a.SameRegion(west2a))
+	require.False(t, east1a.SameZone(zoneOnly))
+	require.True(t, zoneOnly.SameZone(&Locality{Zone: "us-east-1a"}))
+	require.False(t, zoneOnly.SameRegion(&Locality{Zone: "us-east-1a"}))
+
+	var empty *Locality
+	require.False(t, empty.SameZone(east1a))
+	require.False(t, east1a.SameRegion(empty))
+}
@@ -0,0 +1,424 @@
+package structs
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/mitchellh/hashstructure"
+
+	"github.com/hashicorp/consul/acl"
+	"github.com/hashicorp/consul/agent/cache"
+)
+
+// PeeringState is the state of a peering with another cluster.
+type PeeringState string
+
+const (
+	// PeeringStatePending is the state of a peering on the accepting side
+	// between the generation of its token and the first connection of the
+	// peer.
+	PeeringStatePending PeeringState = "PENDING"
+
+	// PeeringStateEstablishing is the state of a peering on the dialing side
+	// until the first connection to the peer succeeds.
+	PeeringStateEstablishing PeeringState = "ESTABLISHING"
+
+	// PeeringStateActive is the state of a peering once both sides have
+	// connected at least once.
+	PeeringStateActive PeeringState = "ACTIVE"
+
+	// PeeringStateFailing is reported for an active peering whose replication
+	// stream is currently disconnected with an error. It is never persisted.
+	PeeringStateFailing PeeringState = "FAILING"
+
+	// PeeringStateTerminated is the state of a peering the peer deleted on its
+	// side. Nothing is replicated anymore, and the peering can only be deleted.
+	PeeringStateTerminated PeeringState = "TERMINATED"
+)
+
+// Peering is a relationship between this cluster and another one, the peer,
+// through which each side replicates the services it exports to the other.
+//
+// One side of the peering generates a token the other side establishes the
+// peering with. The establishing side, which has PeerServerAddresses set,
+// dials the servers of the other side and keeps a replication stream open.
+type Peering struct {
+	// ID is the unique identifier of the peering in this cluster.
+	ID string
+
+	// Name is the local name of the peer. Services imported from the peer are
+	// addressed by it.
+	Name string
+
+	// State is the persisted state of the peering.
+	State PeeringState
+
+	// PeerID is the ID of the peering on the accepting side. It is only set
+	// on the dialing side, which presents it when opening the stream.
+	PeerID string `json:",omitempty"`
+
+	// StreamSecret authenticates the replication stream. The accepting side
+	// generates a new one along with each token, which rotates it, and only
+	// accepts streams presenting the latest one. The dialing side presents
+	// the one of the token it was established with. It is never returned by
+	// reads, see Redacted.
+	StreamSecret string `json:",omitempty"`
+
+	// PeerCAPems are the CA certificates used to verify the gRPC servers of
+	// the peer. When empty, the system roots are used.
+	PeerCAPems []string `json:",omitempty"`
+
+	// PeerServerName is the name the gRPC servers of the peer are verified
+	// against.
+	PeerServerName string `json:",omitempty"`
+
+	// PeerServerAddresses are the addresses of the gRPC servers of the peer.
+	// They are only set on the dialing side.
+	PeerServerAddresses []string `json:",omitempty"`
+
+	// PeerMesh is the mesh information last received from the peer.
+	PeerMesh *PeeringMeshInfo `json:",omitempty"`
+
+	// Meta is arbitrary metadata attached to the peering.
+	Meta map[string]string `json:",omitempty"`
+
+	// StreamStatus is the status of the replication stream as seen by the
+	// leader. It is filled in on reads and never persisted.
+	StreamStatus *PeeringStreamStatus `json:",omitempty"`
+
+	// RaftIndex is local raft data.
+	RaftIndex
+}
+
+// ShouldDial returns true if this side of the peering opens the replication
+// stream to the peer.
+func (p *Peering) ShouldDial() bool {
+	return len(p.PeerServerAddresses) > 0
+}
+
+// IsTerminated returns true when the peer deleted the peering on its side.
+func (p *Peering) IsTerminated() bool {
+	return p.State == PeeringStateTerminated
+}
+
+// Redacted returns a copy of the peering without its stream secret, to be
+// returned by reads.
+func (p *Peering) Redacted() *Peering {
+	cp := *p
+	cp.StreamSecret = ""
+	return &cp
+}
+
+// PeeringStreamStatus is the runtime status of the replication stream of a
+// peering.
+type PeeringStreamStatus struct {
+	// Connected is true while the stream is open.
+	Connected bool
+
+	// LastError is the error the stream last failed with, if any.
+	LastError string `json:",omitempty"`
+
+	// LastReceive is when a message was last received from the peer.
+	LastReceive time.Time
+}
+
+// PeeringMeshInfo is what a peer exposes so that proxies of this cluster can
+// reach the services it exports through its mesh gateways.
+type PeeringMeshInfo struct {
+	// TrustDomain is the trust domain of the certificates of the services of
+	// the peer.
+	TrustDomain string
+
+	// RootPEMs are the Connect CA roots of the peer.
+	RootPEMs []string
+
+	// Datacenter is the datacenter of the services of the peer.
+	Datacenter string
+
+	// GatewayAddresses are the host:port addresses of the mesh gateways of the
+	// peer.
+	GatewayAddresses []string
+}
+
+// Peerings is a list of peerings.
+type Peerings []*Peering
+
+// Sort sorts peerings by name.
+func (listings Peerings) Sort() {
+	sort.Slice(listings, func(i, j int) bool {
+		return listings[i].Name < listings[j].Name
+	})
+}
+
+// PeeringToken is handed by the accepting side of a peering to the
+// establishing side. It is encoded with Encode before being shown to users.
+type PeeringToken struct {
+	// CA are the CA certificates the gRPC servers of the accepting side are
+	// signed by.
+	CA []string `json:",omitempty"`
+
+	// ServerAddresses are the addresses of the gRPC servers of the accepting
+	// side.
+	ServerAddresses []string
+
+	// ServerName is the name the gRPC servers of the accepting side present.
+	ServerName string `json:",omitempty"`
+
+	// PeerID is the ID of the peering on the accepting side.
+	PeerID string
+
+	// StreamSecret is the secret the replication stream is authenticated
+	// with, see Peering.StreamSecret.
+	StreamSecret string
+}
+
+// Encode returns the opaque string form of the token.
+func (t *PeeringToken) Encode() (string, error) {
+	raw, err := json.Marshal(t)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(raw), nil
+}
+
+// DecodePeeringToken parses a token encoded with PeeringToken.Encode.
+func DecodePeeringToken(s string) (*PeeringToken, error) {
+	raw, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("peering token is not valid base64: %w", err)
+	}
+	var t PeeringToken
+	if err := json.Unmarshal(raw, &t); err != nil {
+		return nil, fmt.Errorf("peering token is malformed: %w", err)
+	}
+	if t.PeerID == "" {
+		return nil, fmt.Errorf("peering token is missing a peer ID")
+	}
+	if t.StreamSecret == "" {
+		return nil, fmt.Errorf("peering token is missing a stream secret")
+	}
+	if len(t.ServerAddresses) == 0 {
+		return nil, fmt.Errorf("peering token is missing server addresses")
+	}
+	return &t, nil
+}
+
+// PeeringOp is the operation for a request related to peerings.
+type PeeringOp string
+
+const (
+	PeeringUpsert PeeringOp = "upsert"
+	PeeringDelete PeeringOp = "delete"
+)
+
+// PeeringRequest is used to upsert and delete peerings.
+type PeeringRequest struct {
+	// Datacenter is the target for this request.
+	Datacenter string
+
+	// Op is the type of operation being requested.
+	Op PeeringOp
+
+	// Peering is the peering to upsert or in the case of a delete only the
+	// Peering.Name field should be set.
+	Peering *Peering
+
+	// WriteRequest is a common struct containing ACL tokens and other
+	// write-related common elements for requests.
+	WriteRequest
+}
+
+// RequestDatacenter returns the datacenter for a given request.
+func (r *PeeringRequest) RequestDatacenter() string {
+	return r.Datacenter
+}
+
+// PeeringImportOp is the operation for a request storing what was received
+// from a peer.
+type PeeringImportOp string
+
+const (
+	PeeringImportUpsertService PeeringImportOp = "upsert-service"
+	PeeringImportDeleteService PeeringImportOp = "delete-service"
+	PeeringImportSetMeshInfo   PeeringImportOp = "set-mesh-info"
+	PeeringImportActivate      PeeringImportOp = "activate"
+	PeeringImportTerminate     PeeringImportOp = "terminate"
+)
+
+// PeeringImportRequest is used by the leader to store the resources and state
+// changes received on the replication stream of a peering.
+type PeeringImportRequest struct {
+	// Op is the type of operation being requested.
+	Op PeeringImportOp
+
+	// PeerName is the name of the peering the resources were received on.
+	PeerName string
+
+	// ServiceName is the name of the imported service for service operations.
+	ServiceName string `json:",omitempty"`
+
+	// Nodes are the instances of the imported service for upserts.
+	Nodes CheckServiceNodes `json:",omitempty"`
+
+	// MeshInfo is the mesh information of the peer for set-mesh-info.
+	MeshInfo *PeeringMeshInfo `json:",omitempty"`
+}
+
+// PeeringImportedService holds the instances of a service imported from a
+// peer, as last received.
+type PeeringImportedService struct {
+	PeerName    string
+	ServiceName string
+	Nodes       CheckServiceNodes
+
+	RaftIndex
+}
+
+// PeeringGenerateTokenRequest is used to create a peering on the accepting
+// side and get the token to establish it with.
+type PeeringGenerateTokenRequest struct {
+	// Datacenter is the target for this request.
+	Datacenter string
+
+	// PeerName is the local name of the peer.
+	PeerName string
+
+	// Meta is arbitrary metadata attached to the peering.
+	Meta map[string]string `json:",omitempty"`
+
+	// ServerExternalAddresses overrides the addresses of the servers put in
+	// the token, for servers behind NAT or a load balancer.
+	ServerExternalAddresses []string `json:",omitempty"`
+
+	WriteRequest
+}
+
+// RequestDatacenter returns the datacenter for a given request.
+func (r *PeeringGenerateTokenRequest) RequestDatacenter() string {
+	return r.Datacenter
+}
+
+// PeeringGenerateTokenResponse holds the encoded token of a peering.
+type PeeringGenerateTokenResponse struct {
+	PeeringToken string
+}
+
+// PeeringEstablishRequest is used to create a peering on the dialing side
+// from a token generated by the peer.
+type PeeringEstablishRequest struct {
+	// Datacenter is the target for this request.
+	Datacenter string
+
+	// PeerName is the local name of the peer.
+	PeerName string
+
+	// PeeringToken is the token generated by the peer.
+	PeeringToken string
+
+	// Meta is arbitrary metadata attached to the peering.
+	Meta map[string]string `json:",omitempty"`
+
+	WriteRequest
+}
+
+// RequestDatacenter returns the datacenter for a given request.
+func (r *PeeringEstablishRequest) RequestDatacenter() string {
+	return r.Datacenter
+}
+
+// PeeringReadRequest is used to read or delete a single peering by name.
+type PeeringReadRequest struct {
+	// Datacenter is the target for this request.
+	Datacenter string
+
+	// PeerName is the name of the peering.
+	PeerName string
+
+	QueryOptions
+}
+
+// RequestDatacenter returns the datacenter for a given request.
+func (r *PeeringReadRequest) RequestDatacenter() string {
+	return r.Datacenter
+}
+
+// PeeringResponse is the response to a PeeringReadRequest.
+type PeeringResponse struct {
+	Peering *Peering
+	QueryMeta
+}
+
+// IndexedPeerings represents the list of all peerings.
+type IndexedPeerings struct {
+	Peerings Peerings
+	QueryMeta
+}
+
+// PeeringDeleteRequest is used to delete a peering by name.
+type PeeringDeleteRequest struct {
+	// Datacenter is the target for this request.
+	Datacenter string
+
+	// PeerName is the name of the peering.
+	PeerName string
+
+	WriteRequest
+}
+
+// RequestDatacenter returns the datacenter for a given request.
+func (r *PeeringDeleteRequest) RequestDatacenter() string {
+	return r.Datacenter
+}
+
+// PeeringMeshInfoRequest is used by proxies to learn about the mesh of their
+// peers. When PeerName is set the mesh information of that peer is returned,
+// otherwise that of all the peers ServiceName is exported to.
+type PeeringMeshInfoRequest struct {
+	Datacenter  string
+	PeerName    string
+	ServiceName string
+
+	acl.EnterpriseMeta `hcl:",squash" mapstructure:",squash"`
+	QueryOptions
+}
+
+// RequestDatacenter returns the datacenter for a given request.
+func (r *PeeringMeshInfoRequest) RequestDatacenter() string {
+	return r.Datacenter
+}
+
+// CacheInfo implements cache.Request.
+func (r *PeeringMeshInfoRequest) CacheInfo() cache.RequestInfo {
+	info := cache.RequestInfo{
+		Token:          r.Token,
+		Datacenter:     r.Datacenter,
+		MinIndex:       r.MinQueryIndex,
+		Timeout:        r.MaxQueryTime,
+		MaxAge:         r.MaxAge,
+		MustRevalidate: r.MustRevalidate,
+	}
+
+	v, err := hashstructure.Hash([]interface{}{
+		r.PeerName,
+		r.ServiceName,
+		r.EnterpriseMeta,
+	}, nil)
+	if err == nil {
+		// If there is an error, we don't set the key. A blank key forces
+		// no cache for this request so the request is forwarded directly
+		// to the server.
+		info.Key = strconv.FormatUint(v, 10)
+	}
+
+	return info
+}
+
+// IndexedPeeringMeshInfos maps peer names to their mesh information.
+type IndexedPeeringMeshInfos struct {
+	MeshInfos map[string]*PeeringMeshInfo
+	QueryMeta
+}
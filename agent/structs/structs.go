@@ -80,6 +80,11 @@ const (
 	ServiceVirtualIPRequestType                 = 32
 	FreeVirtualIPRequestType                    = 33
 	KindServiceNamesType                        = 34
+	ConnectCARevocationType                     = 35 // FSM snapshots only.
+	ConnectCARevocationListType                 = 36 // FSM snapshots only.
+	PeeringRequestType                          = 37
+	PeeringImportRequestType                    = 38
+	PeeringImportedServiceType                  = 39 // FSM snapshots only.
 )
 
 // if a new request type is added above it must be
@@ -123,6 +128,11 @@ var requestTypeStrings = map[MessageType]string{
 	ServiceVirtualIPRequestType:     "ServiceVirtualIP",
 	FreeVirtualIPRequestType:        "FreeVirtualIP",
 	KindServiceNamesType:            "KindServiceName",
+	ConnectCARevocationType:         "ConnectCARevocation",     // FSM snapshots only.
+	ConnectCARevocationListType:     "ConnectCARevocationList", // FSM snapshots only.
+	PeeringRequestType:              "Peering",
+	PeeringImportRequestType:        "PeeringImport",
+	PeeringImportedServiceType:      "PeeringImportedService", // FSM snapshots only.
 }
 
 const (
@@ -655,6 +665,10 @@ type ServiceSpecificRequest struct {
 	// Ingress if true will only search for Ingress gateways for the given service.
 	Ingress bool
 
+	// PeerName if set will search for the instances of the service imported
+	// from that cluster peer instead of the local ones.
+	PeerName string
+
 	acl.EnterpriseMeta `hcl:",squash" mapstructure:",squash"`
 	QueryOptions
 }
@@ -700,6 +714,7 @@ func (r *ServiceSpecificRequest) CacheInfo() cache.RequestInfo {
 		r.EnterpriseMeta,
 		r.Ingress,
 		r.ServiceKind,
+		r.PeerName,
 	}, nil)
 	if err == nil {
 		// If there is an error, we don't set the key. A blank key forces
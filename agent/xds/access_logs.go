@@ -0,0 +1,189 @@
+package xds
+
+import (
+	"encoding/json"
+	"fmt"
+
+	envoy_accesslog_v3 "github.com/envoyproxy/go-control-plane/envoy/config/accesslog/v3"
+	envoy_core_v3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	envoy_listener_v3 "github.com/envoyproxy/go-control-plane/envoy/config/listener/v3"
+	envoy_file_access_log_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/access_loggers/file/v3"
+	envoy_stream_access_log_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/access_loggers/stream/v3"
+	envoy_http_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/http_connection_manager/v3"
+	envoy_tcp_proxy_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/tcp_proxy/v3"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"github.com/hashicorp/consul/agent/proxycfg"
+	"github.com/hashicorp/consul/agent/structs"
+)
+
+const (
+	fileAccessLogName   = "envoy.access_loggers.file"
+	stdoutAccessLogName = "envoy.access_loggers.stdout"
+	stderrAccessLogName = "envoy.access_loggers.stderr"
+
+	tcpProxyFilterName = "envoy.filters.network.tcp_proxy"
+)
+
+// injectAccessLogs adds the access logs configured for the proxy to the
+// listeners, and to the HTTP connection manager and TCP proxy filters of
+// their filter chains. It is applied to every listener generated for any kind
+// of proxy, so that access logs don't need to be threaded through each of
+// them.
+func (s *ResourceGenerator) injectAccessLogs(cfgSnap *proxycfg.ConfigSnapshot, resources []proto.Message) error {
+	cfg, err := ParseAccessLogsConfig(cfgSnap.Proxy.Config)
+	if err != nil {
+		// Don't hard fail on a config typo, just warn. Access logs stay
+		// disabled if the config can't be parsed.
+		s.Logger.Warn("failed to parse access logs config", "error", err)
+		return nil
+	}
+	if !cfg.Enabled {
+		return nil
+	}
+
+	accessLog, err := makeAccessLog(cfg)
+	if err != nil {
+		return err
+	}
+
+	for _, res := range resources {
+		l, ok := res.(*envoy_listener_v3.Listener)
+		if !ok {
+			continue
+		}
+
+		// Listener logs record the connections rejected before any filter
+		// chain handles them, such as those matching no filter chain.
+		if !cfg.DisableListenerLogs {
+			l.AccessLog = append(l.AccessLog, accessLog)
+		}
+
+		for _, chain := range listenerFilterChains(l) {
+			for _, filter := range chain.Filters {
+				if err := injectAccessLogOnFilter(filter, accessLog); err != nil {
+					return fmt.Errorf("failed to add access logs to listener %q: %v", l.Name, err)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// listenerFilterChains returns all the filter chains of a listener, including
+// its default filter chain.
+func listenerFilterChains(l *envoy_listener_v3.Listener) []*envoy_listener_v3.FilterChain {
+	chains := make([]*envoy_listener_v3.FilterChain, 0, len(l.FilterChains)+1)
+	chains = append(chains, l.FilterChains...)
+	if l.DefaultFilterChain != nil {
+		chains = append(chains, l.DefaultFilterChain)
+	}
+	return chains
+}
+
+func injectAccessLogOnFilter(filter *envoy_listener_v3.Filter, accessLog *envoy_accesslog_v3.AccessLog) error {
+	var cfg proto.Message
+	switch filter.Name {
+	case httpConnectionManagerOldName, httpConnectionManagerNewName:
+		cfg = &envoy_http_v3.HttpConnectionManager{}
+	case tcpProxyFilterName:
+		cfg = &envoy_tcp_proxy_v3.TcpProxy{}
+	default:
+		return nil
+	}
+
+	tc, ok := filter.ConfigType.(*envoy_listener_v3.Filter_TypedConfig)
+	if !ok {
+		return fmt.Errorf("filter %q has an unsupported config type: %T", filter.Name, filter.ConfigType)
+	}
+	if err := ptypes.UnmarshalAny(tc.TypedConfig, cfg); err != nil {
+		return err
+	}
+
+	switch c := cfg.(type) {
+	case *envoy_http_v3.HttpConnectionManager:
+		c.AccessLog = append(c.AccessLog, accessLog)
+	case *envoy_tcp_proxy_v3.TcpProxy:
+		c.AccessLog = append(c.AccessLog, accessLog)
+	}
+
+	any, err := ptypes.MarshalAny(cfg)
+	if err != nil {
+		return err
+	}
+	tc.TypedConfig = any
+	return nil
+}
+
+func makeAccessLog(cfg structs.AccessLogsConfig) (*envoy_accesslog_v3.AccessLog, error) {
+	// Without a format Envoy uses its default text format.
+	var format *envoy_core_v3.SubstitutionFormatString
+	switch {
+	case cfg.JSONFormat != "":
+		var fields map[string]interface{}
+		if err := json.Unmarshal([]byte(cfg.JSONFormat), &fields); err != nil {
+			return nil, fmt.Errorf("access logs JSONFormat must be a JSON object: %v", err)
+		}
+		jsonFormat, err := structpb.NewStruct(fields)
+		if err != nil {
+			return nil, fmt.Errorf("invalid access logs JSONFormat: %v", err)
+		}
+		format = &envoy_core_v3.SubstitutionFormatString{
+			Format: &envoy_core_v3.SubstitutionFormatString_JsonFormat{
+				JsonFormat: jsonFormat,
+			},
+		}
+	case cfg.TextFormat != "":
+		format = &envoy_core_v3.SubstitutionFormatString{
+			Format: &envoy_core_v3.SubstitutionFormatString_TextFormatSource{
+				TextFormatSource: &envoy_core_v3.DataSource{
+					Specifier: &envoy_core_v3.DataSource_InlineString{
+						InlineString: cfg.TextFormat,
+					},
+				},
+			},
+		}
+	}
+
+	var (
+		name   string
+		logger proto.Message
+	)
+	switch cfg.Type {
+	case structs.DefaultLogSinkType, structs.StdOutLogSinkType:
+		name = stdoutAccessLogName
+		stdout := &envoy_stream_access_log_v3.StdoutAccessLog{}
+		if format != nil {
+			stdout.AccessLogFormat = &envoy_stream_access_log_v3.StdoutAccessLog_LogFormat{LogFormat: format}
+		}
+		logger = stdout
+	case structs.StdErrLogSinkType:
+		name = stderrAccessLogName
+		stderr := &envoy_stream_access_log_v3.StderrAccessLog{}
+		if format != nil {
+			stderr.AccessLogFormat = &envoy_stream_access_log_v3.StderrAccessLog_LogFormat{LogFormat: format}
+		}
+		logger = stderr
+	case structs.FileLogSinkType:
+		name = fileAccessLogName
+		file := &envoy_file_access_log_v3.FileAccessLog{Path: cfg.Path}
+		if format != nil {
+			file.AccessLogFormat = &envoy_file_access_log_v3.FileAccessLog_LogFormat{LogFormat: format}
+		}
+		logger = file
+	default:
+		return nil, fmt.Errorf("unsupported access logs type %q", cfg.Type)
+	}
+
+	any, err := ptypes.MarshalAny(logger)
+	if err != nil {
+		return nil, err
+	}
+	return &envoy_accesslog_v3.AccessLog{
+		Name:       name,
+		ConfigType: &envoy_accesslog_v3.AccessLog_TypedConfig{TypedConfig: any},
+	}, nil
+}
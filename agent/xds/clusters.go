@@ -3,7 +3,9 @@ package xds
 import (
 	"errors"
 	"fmt"
+	"net"
 	"sort"
+	"strconv"
 	"time"
 
 	envoy_cluster_v3 "github.com/envoyproxy/go-control-plane/envoy/config/cluster/v3"
@@ -19,9 +21,11 @@ import (
 	"github.com/golang/protobuf/ptypes"
 	"github.com/golang/protobuf/ptypes/any"
 	"github.com/golang/protobuf/ptypes/wrappers"
+	"google.golang.org/protobuf/encoding/protowire"
 	"google.golang.org/protobuf/types/known/anypb"
 
 	"github.com/hashicorp/consul/agent/connect"
+	"github.com/hashicorp/consul/agent/connect/ca"
 	"github.com/hashicorp/consul/agent/proxycfg"
 	"github.com/hashicorp/consul/agent/structs"
 )
@@ -40,7 +44,11 @@ func (s *ResourceGenerator) clustersFromSnapshot(cfgSnap *proxycfg.ConfigSnapsho
 		if err != nil {
 			return nil, err
 		}
-		return res, nil
+		destinations, err := s.makeDestinationClusters(cfgSnap)
+		if err != nil {
+			return nil, err
+		}
+		return append(res, destinations...), nil
 	case structs.ServiceKindMeshGateway:
 		res, err := s.clustersFromSnapshotMeshGateway(cfgSnap)
 		if err != nil {
@@ -116,6 +124,34 @@ func (s *ResourceGenerator) clustersFromSnapshotConnectProxy(cfgSnap *proxycfg.C
 		clusters = append(clusters, upstreamCluster)
 	}
 
+	// Upstreams imported from cluster peers do not have discovery chains either.
+	for uid, u := range cfgSnap.ConnectProxy.UpstreamConfig {
+		if u.DestinationPeer == "" {
+			continue
+		}
+		peerMesh, ok := cfgSnap.ConnectProxy.UpstreamPeerMeshInfos[uid]
+		if !ok {
+			// The peer has not sent its mesh information yet.
+			continue
+		}
+
+		upstreamCluster, err := s.makeUpstreamClusterForPeerService(uid, u, peerMesh, cfgSnap)
+		if err != nil {
+			return nil, err
+		}
+		clusters = append(clusters, upstreamCluster)
+	}
+
+	// External destinations are reached through the terminating gateways
+	// linked to them, with one cluster per destination address.
+	for _, dest := range destinationAddresses(cfgSnap) {
+		upstreamCluster, err := s.makeUpstreamClusterForDestination(dest, cfgSnap)
+		if err != nil {
+			return nil, err
+		}
+		clusters = append(clusters, upstreamCluster)
+	}
+
 	cfgSnap.Proxy.Expose.Finalize()
 	paths := cfgSnap.Proxy.Expose.Paths
 
@@ -508,6 +544,9 @@ func (s *ResourceGenerator) makeAppCluster(cfgSnap *proxycfg.ConfigSnapshot, nam
 			return c, err
 		}
 	}
+	if err := injectConnectionPoolToCluster(cfg.ConnectionPool, protocol, c); err != nil {
+		return c, err
+	}
 
 	return c, err
 }
@@ -554,13 +593,16 @@ func (s *ResourceGenerator) makeUpstreamClusterForPreparedQuery(upstream structs
 			CircuitBreakers: &envoy_cluster_v3.CircuitBreakers{
 				Thresholds: makeThresholdsIfNeeded(cfg.Limits),
 			},
-			OutlierDetection: ToOutlierDetection(cfg.PassiveHealthCheck),
+			OutlierDetection: ToOutlierDetection(nil, cfg.PassiveHealthCheck),
 		}
 		if cfg.Protocol == "http2" || cfg.Protocol == "grpc" {
 			if err := s.setHttp2ProtocolOptions(c); err != nil {
 				return c, err
 			}
 		}
+		if err := injectConnectionPoolToCluster(cfg.ConnectionPool, cfg.Protocol, c); err != nil {
+			return c, err
+		}
 	}
 
 	endpoints := cfgSnap.ConnectProxy.PreparedQueryEndpoints[uid]
@@ -613,6 +655,256 @@ func (s *ResourceGenerator) makeUpstreamClusterForPreparedQuery(upstream structs
 	return c, nil
 }
 
+// peerServiceSNI returns the SNI of an upstream imported from a cluster peer.
+// The service is addressed as it is in the peer, so that its mesh gateways
+// route the connection to its instances.
+func peerServiceSNI(u *structs.Upstream, peerMesh *structs.PeeringMeshInfo) string {
+	return connect.ServiceSNI(u.DestinationName, "", u.DestinationNamespace, u.DestinationPartition, peerMesh.Datacenter, peerMesh.TrustDomain)
+}
+
+func (s *ResourceGenerator) makeUpstreamClusterForPeerService(
+	uid proxycfg.UpstreamID,
+	upstream *structs.Upstream,
+	peerMesh *structs.PeeringMeshInfo,
+	cfgSnap *proxycfg.ConfigSnapshot,
+) (*envoy_cluster_v3.Cluster, error) {
+	var c *envoy_cluster_v3.Cluster
+	var err error
+
+	sni := peerServiceSNI(upstream, peerMesh)
+
+	cfg, err := structs.ParseUpstreamConfig(upstream.Config)
+	if err != nil {
+		// Don't hard fail on a config typo, just warn. The parse func returns
+		// default config if there is an error so it's safe to continue.
+		s.Logger.Warn("failed to parse", "upstream", uid, "error", err)
+	}
+	if cfg.EnvoyClusterJSON != "" {
+		c, err = makeClusterFromUserConfig(cfg.EnvoyClusterJSON)
+		if err != nil {
+			return c, err
+		}
+		// In the happy path don't return yet as we need to inject TLS config still.
+	}
+
+	if c == nil {
+		c = &envoy_cluster_v3.Cluster{
+			Name:                 sni,
+			ConnectTimeout:       ptypes.DurationProto(time.Duration(cfg.ConnectTimeoutMs) * time.Millisecond),
+			ClusterDiscoveryType: &envoy_cluster_v3.Cluster_Type{Type: envoy_cluster_v3.Cluster_EDS},
+			EdsClusterConfig: &envoy_cluster_v3.Cluster_EdsClusterConfig{
+				EdsConfig: &envoy_core_v3.ConfigSource{
+					ResourceApiVersion: envoy_core_v3.ApiVersion_V3,
+					ConfigSourceSpecifier: &envoy_core_v3.ConfigSource_Ads{
+						Ads: &envoy_core_v3.AggregatedConfigSource{},
+					},
+				},
+			},
+			CircuitBreakers: &envoy_cluster_v3.CircuitBreakers{
+				Thresholds: makeThresholdsIfNeeded(cfg.Limits),
+			},
+			OutlierDetection: ToOutlierDetection(nil, cfg.PassiveHealthCheck),
+		}
+		if cfg.Protocol == "http2" || cfg.Protocol == "grpc" {
+			if err := s.setHttp2ProtocolOptions(c); err != nil {
+				return c, err
+			}
+		}
+		if err := injectConnectionPoolToCluster(cfg.ConnectionPool, cfg.Protocol, c); err != nil {
+			return c, err
+		}
+	}
+
+	// The upstream presents a certificate signed by the roots of the peer, so
+	// those are the only ones trusted. The revocation lists of the local CA do
+	// not apply to them.
+	commonTLSContext := makeCommonTLSContextFromLeaf(
+		cfgSnap,
+		cfgSnap.Leaf(),
+		makeTLSParametersFromProxyTLSConfig(cfgSnap.MeshConfigTLSOutgoing()),
+	)
+	rootPEMs := ""
+	for _, root := range peerMesh.RootPEMs {
+		rootPEMs += ca.EnsureTrailingNewline(root)
+	}
+	commonTLSContext.ValidationContextType = &envoy_tls_v3.CommonTlsContext_ValidationContext{
+		ValidationContext: &envoy_tls_v3.CertificateValidationContext{
+			TrustedCa: &envoy_core_v3.DataSource{
+				Specifier: &envoy_core_v3.DataSource_InlineString{
+					InlineString: rootPEMs,
+				},
+			},
+		},
+	}
+	err = injectSANMatcher(commonTLSContext, connect.SpiffeIDService{
+		Host:       peerMesh.TrustDomain,
+		Namespace:  upstream.DestinationNamespace,
+		Partition:  upstream.DestinationPartition,
+		Datacenter: peerMesh.Datacenter,
+		Service:    upstream.DestinationName,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to inject SAN matcher rules for cluster %q: %v", sni, err)
+	}
+
+	tlsContext := &envoy_tls_v3.UpstreamTlsContext{
+		CommonTlsContext: commonTLSContext,
+		Sni:              sni,
+	}
+
+	transportSocket, err := makeUpstreamTLSTransportSocket(tlsContext)
+	if err != nil {
+		return nil, err
+	}
+	c.TransportSocket = transportSocket
+
+	return c, nil
+}
+
+// destinationAddress is one address of an external destination declared in
+// a service-defaults config entry.
+type destinationAddress struct {
+	uid     proxycfg.UpstreamID
+	service structs.ServiceName
+	address string
+	port    int
+
+	// sni is the name of the cluster sending traffic to the address, and the
+	// SNI it is routed by at the terminating gateway.
+	sni string
+}
+
+// destinationAddresses returns the addresses of the external destinations a
+// transparent proxy can reach, sorted by destination. An address and port
+// declared by more than one destination is only routed to the first one.
+func destinationAddresses(cfgSnap *proxycfg.ConfigSnapshot) []destinationAddress {
+	uids := make([]proxycfg.UpstreamID, 0, len(cfgSnap.ConnectProxy.DestinationsUpstream))
+	for uid := range cfgSnap.ConnectProxy.DestinationsUpstream {
+		uids = append(uids, uid)
+	}
+	sort.Slice(uids, func(i, j int) bool {
+		return uids[i].String() < uids[j].String()
+	})
+
+	var out []destinationAddress
+	seen := make(map[string]struct{})
+	for _, uid := range uids {
+		svcConf := cfgSnap.ConnectProxy.DestinationsUpstream[uid]
+		svc := structs.NewServiceName(svcConf.Name, &svcConf.EnterpriseMeta)
+		for _, addr := range svcConf.Destination.Addresses {
+			key := net.JoinHostPort(addr, strconv.Itoa(svcConf.Destination.Port))
+			if _, ok := seen[key]; ok {
+				continue
+			}
+			seen[key] = struct{}{}
+
+			out = append(out, destinationAddress{
+				uid:     uid,
+				service: svc,
+				address: addr,
+				port:    svcConf.Destination.Port,
+				sni: connect.DestinationSNI(addr, svc.Name, svc.NamespaceOrDefault(), svc.PartitionOrDefault(),
+					cfgSnap.Datacenter, cfgSnap.Roots.TrustDomain),
+			})
+		}
+	}
+	return out
+}
+
+func (s *ResourceGenerator) makeUpstreamClusterForDestination(
+	dest destinationAddress,
+	cfgSnap *proxycfg.ConfigSnapshot,
+) (*envoy_cluster_v3.Cluster, error) {
+	c := &envoy_cluster_v3.Cluster{
+		Name:                 dest.sni,
+		ConnectTimeout:       ptypes.DurationProto(5 * time.Second),
+		ClusterDiscoveryType: &envoy_cluster_v3.Cluster_Type{Type: envoy_cluster_v3.Cluster_EDS},
+		EdsClusterConfig: &envoy_cluster_v3.Cluster_EdsClusterConfig{
+			EdsConfig: &envoy_core_v3.ConfigSource{
+				ResourceApiVersion: envoy_core_v3.ApiVersion_V3,
+				ConfigSourceSpecifier: &envoy_core_v3.ConfigSource_Ads{
+					Ads: &envoy_core_v3.AggregatedConfigSource{},
+				},
+			},
+		},
+		CircuitBreakers: &envoy_cluster_v3.CircuitBreakers{
+			Thresholds: makeThresholdsIfNeeded(nil),
+		},
+		// Having an empty config enables outlier detection with default config.
+		OutlierDetection: &envoy_cluster_v3.OutlierDetection{},
+	}
+
+	// The terminating gateway presents the certificate of the destination
+	// service on its behalf.
+	commonTLSContext := makeCommonTLSContextFromLeaf(
+		cfgSnap,
+		cfgSnap.Leaf(),
+		makeTLSParametersFromProxyTLSConfig(cfgSnap.MeshConfigTLSOutgoing()),
+	)
+	err := injectSANMatcher(commonTLSContext, connect.SpiffeIDService{
+		Host:       cfgSnap.Roots.TrustDomain,
+		Namespace:  dest.service.NamespaceOrDefault(),
+		Partition:  dest.service.PartitionOrDefault(),
+		Datacenter: cfgSnap.Datacenter,
+		Service:    dest.service.Name,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to inject SAN matcher rules for cluster %q: %v", dest.sni, err)
+	}
+
+	tlsContext := &envoy_tls_v3.UpstreamTlsContext{
+		CommonTlsContext: commonTLSContext,
+		Sni:              dest.sni,
+	}
+	transportSocket, err := makeUpstreamTLSTransportSocket(tlsContext)
+	if err != nil {
+		return nil, err
+	}
+	c.TransportSocket = transportSocket
+
+	return c, nil
+}
+
+// makeDestinationClusters returns the clusters of a terminating gateway that
+// send traffic to the external destinations linked to it. Their addresses
+// are given inline, hostnames being resolved by Envoy.
+func (s *ResourceGenerator) makeDestinationClusters(cfgSnap *proxycfg.ConfigSnapshot) ([]proto.Message, error) {
+	var clusters []proto.Message
+	for _, svc := range cfgSnap.TerminatingGateway.ValidDestinations() {
+		dest := cfgSnap.TerminatingGateway.ServiceConfigs[svc].Destination
+
+		for _, addr := range dest.Addresses {
+			name := connect.DestinationSNI(addr, svc.Name, svc.NamespaceOrDefault(), svc.PartitionOrDefault(),
+				cfgSnap.Datacenter, cfgSnap.Roots.TrustDomain)
+
+			cluster := s.makeGatewayCluster(cfgSnap, gatewayClusterOpts{name: name})
+
+			if structs.IsDestinationHostname(addr) {
+				cluster.ClusterDiscoveryType = &envoy_cluster_v3.Cluster_Type{Type: envoy_cluster_v3.Cluster_LOGICAL_DNS}
+				cluster.DnsRefreshRate = ptypes.DurationProto(10 * time.Second)
+				cluster.DnsLookupFamily = envoy_cluster_v3.Cluster_V4_ONLY
+			} else {
+				cluster.ClusterDiscoveryType = &envoy_cluster_v3.Cluster_Type{Type: envoy_cluster_v3.Cluster_STATIC}
+			}
+			cluster.EdsClusterConfig = nil
+			cluster.LoadAssignment = &envoy_endpoint_v3.ClusterLoadAssignment{
+				ClusterName: name,
+				Endpoints: []*envoy_endpoint_v3.LocalityLbEndpoints{
+					{
+						LbEndpoints: []*envoy_endpoint_v3.LbEndpoint{makeEndpoint(addr, dest.Port)},
+					},
+				},
+			}
+
+			if err := s.injectGatewayServiceAddons(cfgSnap, cluster, svc, nil); err != nil {
+				return nil, err
+			}
+			clusters = append(clusters, cluster)
+		}
+	}
+	return clusters, nil
+}
+
 func (s *ResourceGenerator) makeUpstreamClustersForDiscoveryChain(
 	uid proxycfg.UpstreamID,
 	upstream *structs.Upstream,
@@ -747,7 +1039,7 @@ func (s *ResourceGenerator) makeUpstreamClustersForDiscoveryChain(
 			CircuitBreakers: &envoy_cluster_v3.CircuitBreakers{
 				Thresholds: makeThresholdsIfNeeded(cfg.Limits),
 			},
-			OutlierDetection: ToOutlierDetection(cfg.PassiveHealthCheck),
+			OutlierDetection: ToOutlierDetection(node.Resolver.OutlierDetection, cfg.PassiveHealthCheck),
 		}
 
 		var lb *structs.LoadBalancer
@@ -772,6 +1064,9 @@ func (s *ResourceGenerator) makeUpstreamClustersForDiscoveryChain(
 				return nil, err
 			}
 		}
+		if err := injectConnectionPoolToCluster(cfg.ConnectionPool, proto, c); err != nil {
+			return nil, err
+		}
 
 		commonTLSContext := makeCommonTLSContextFromLeaf(
 			cfgSnap,
@@ -1100,3 +1395,103 @@ func (s *ResourceGenerator) setHttp2ProtocolOptions(c *envoy_cluster_v3.Cluster)
 
 	return nil
 }
+
+// injectConnectionPoolToCluster applies the connection pool settings of an
+// upstream, or of the local app, to its cluster. The HTTP settings only
+// apply to HTTP protocols, and replace the protocol options set by
+// setHttp2ProtocolOptions.
+func injectConnectionPoolToCluster(pool *structs.ConnectionPoolConfig, protocol string, c *envoy_cluster_v3.Cluster) error {
+	if pool == nil {
+		return nil
+	}
+
+	if ka := pool.TCPKeepalive; ka != nil {
+		keepalive := &envoy_core_v3.TcpKeepalive{}
+		if ka.Probes != 0 {
+			keepalive.KeepaliveProbes = &wrappers.UInt32Value{Value: ka.Probes}
+		}
+		if ka.TimeSeconds != 0 {
+			keepalive.KeepaliveTime = &wrappers.UInt32Value{Value: ka.TimeSeconds}
+		}
+		if ka.IntervalSeconds != 0 {
+			keepalive.KeepaliveInterval = &wrappers.UInt32Value{Value: ka.IntervalSeconds}
+		}
+		c.UpstreamConnectionOptions = &envoy_cluster_v3.UpstreamConnectionOptions{
+			TcpKeepalive: keepalive,
+		}
+	}
+
+	var explicitConfig *envoy_upstreams_v3.HttpProtocolOptions_ExplicitHttpConfig
+	switch protocol {
+	case "http2", "grpc":
+		http2Opts := &envoy_core_v3.Http2ProtocolOptions{}
+		if pool.MaxConcurrentStreams != nil {
+			http2Opts.MaxConcurrentStreams = makeUint32Value(*pool.MaxConcurrentStreams)
+		}
+		explicitConfig = &envoy_upstreams_v3.HttpProtocolOptions_ExplicitHttpConfig{
+			ProtocolConfig: &envoy_upstreams_v3.HttpProtocolOptions_ExplicitHttpConfig_Http2ProtocolOptions{
+				Http2ProtocolOptions: http2Opts,
+			},
+		}
+	case "http":
+		explicitConfig = &envoy_upstreams_v3.HttpProtocolOptions_ExplicitHttpConfig{
+			ProtocolConfig: &envoy_upstreams_v3.HttpProtocolOptions_ExplicitHttpConfig_HttpProtocolOptions{
+				HttpProtocolOptions: &envoy_core_v3.Http1ProtocolOptions{},
+			},
+		}
+	default:
+		return nil
+	}
+
+	// Plain HTTP/1.1 clusters keep the Envoy defaults unless an option of the
+	// extension is set.
+	if protocol == "http" && pool.HTTPIdleTimeoutMs == nil && pool.MaxRequestsPerConnection == nil {
+		return nil
+	}
+
+	cfg := &envoy_upstreams_v3.HttpProtocolOptions{
+		UpstreamProtocolOptions: &envoy_upstreams_v3.HttpProtocolOptions_ExplicitHttpConfig_{
+			ExplicitHttpConfig: explicitConfig,
+		},
+	}
+	if pool.HTTPIdleTimeoutMs != nil || pool.MaxRequestsPerConnection != nil {
+		cfg.CommonHttpProtocolOptions = &envoy_core_v3.HttpProtocolOptions{}
+	}
+	if pool.HTTPIdleTimeoutMs != nil {
+		cfg.CommonHttpProtocolOptions.IdleTimeout = ptypes.DurationProto(time.Duration(*pool.HTTPIdleTimeoutMs) * time.Millisecond)
+	}
+	if pool.MaxRequestsPerConnection != nil {
+		if err := setMaxRequestsPerConnection(cfg.CommonHttpProtocolOptions, *pool.MaxRequestsPerConnection); err != nil {
+			return err
+		}
+	}
+	any, err := ptypes.MarshalAny(cfg)
+	if err != nil {
+		return err
+	}
+	c.TypedExtensionProtocolOptions = map[string]*anypb.Any{
+		"envoy.extensions.upstreams.http.v3.HttpProtocolOptions": any,
+	}
+
+	return nil
+}
+
+// maxRequestsPerConnectionField is the field number of max_requests_per_connection
+// in envoy.config.core.v3.HttpProtocolOptions.
+const maxRequestsPerConnectionField protowire.Number = 6
+
+// setMaxRequestsPerConnection sets max_requests_per_connection on the common
+// HTTP protocol options. The field was added in Envoy 1.20 and is missing from
+// the generated types of the go-control-plane version we use, so it is written
+// as a raw field. Older Envoy versions ignore it.
+func setMaxRequestsPerConnection(opts *envoy_core_v3.HttpProtocolOptions, max int) error {
+	value, err := proto.Marshal(makeUint32Value(max))
+	if err != nil {
+		return err
+	}
+	raw := opts.ProtoReflect().GetUnknown()
+	raw = protowire.AppendTag(raw, maxRequestsPerConnectionField, protowire.BytesType)
+	raw = protowire.AppendBytes(raw, value)
+	opts.ProtoReflect().SetUnknown(raw)
+	return nil
+}
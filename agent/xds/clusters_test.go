@@ -8,10 +8,14 @@ import (
 	"text/template"
 
 	envoy_cluster_v3 "github.com/envoyproxy/go-control-plane/envoy/config/cluster/v3"
+	envoy_upstreams_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/upstreams/http/v3"
 
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
 	"github.com/golang/protobuf/ptypes/wrappers"
 	testinf "github.com/mitchellh/go-testing-interface"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
 
 	"github.com/hashicorp/consul/agent/cache"
 	"github.com/hashicorp/consul/agent/proxycfg"
@@ -38,6 +42,12 @@ func TestClustersFromSnapshot(t *testing.T) {
 				return proxycfg.TestConfigSnapshot(t, nil, nil)
 			},
 		},
+		{
+			name: "connect-proxy-with-peered-upstreams",
+			create: func(t testinf.T) *proxycfg.ConfigSnapshot {
+				return proxycfg.TestConfigSnapshotPeering(t)
+			},
+		},
 		{
 			name: "connect-proxy-with-tls-outgoing-min-version-auto",
 			create: func(t testinf.T) *proxycfg.ConfigSnapshot {
@@ -128,6 +138,16 @@ func TestClustersFromSnapshot(t *testing.T) {
 				}, nil)
 			},
 		},
+		{
+			name: "connect-proxy-with-revocation-lists",
+			create: func(t testinf.T) *proxycfg.ConfigSnapshot {
+				snap := proxycfg.TestConfigSnapshot(t, nil, nil)
+				snap.Roots.RevocationLists = []string{
+					"-----BEGIN X509 CRL-----\nMIIBOTCB4AIBATAKBggqhkjOPQQDAjAWMRQwEgYDVQQDEwtDb25zdWwgQ0EgNxcN\n-----END X509 CRL-----\n",
+				}
+				return snap
+			},
+		},
 		{
 			name: "custom-upstream",
 			create: func(t testinf.T) *proxycfg.ConfigSnapshot {
@@ -229,6 +249,61 @@ func TestClustersFromSnapshot(t *testing.T) {
 				}, nil)
 			},
 		},
+		{
+			name: "custom-connection-pool",
+			create: func(t testinf.T) *proxycfg.ConfigSnapshot {
+				return proxycfg.TestConfigSnapshot(t, func(ns *structs.NodeService) {
+					ns.Proxy.Config["protocol"] = "http"
+					ns.Proxy.Config["connection_pool"] = map[string]interface{}{
+						"http_idle_timeout_ms":        30000,
+						"max_requests_per_connection": 100,
+					}
+					for i := range ns.Proxy.Upstreams {
+						if ns.Proxy.Upstreams[i].Config == nil {
+							ns.Proxy.Upstreams[i].Config = map[string]interface{}{}
+						}
+
+						ns.Proxy.Upstreams[i].Config["connection_pool"] = map[string]interface{}{
+							"tcp_idle_timeout_ms": 0,
+							"tcp_keepalive": map[string]interface{}{
+								"probes":           3,
+								"time_seconds":     60,
+								"interval_seconds": 10,
+							},
+						}
+					}
+				}, nil)
+			},
+		},
+		{
+			name: "custom-connection-pool-max-requests-per-connection",
+			create: func(t testinf.T) *proxycfg.ConfigSnapshot {
+				return proxycfg.TestConfigSnapshot(t, func(ns *structs.NodeService) {
+					ns.Proxy.Config["protocol"] = "http"
+					ns.Proxy.Config["connection_pool"] = map[string]interface{}{
+						"max_requests_per_connection": 1,
+					}
+				}, nil)
+			},
+		},
+		{
+			name: "custom-connection-pool-http2",
+			create: func(t testinf.T) *proxycfg.ConfigSnapshot {
+				return proxycfg.TestConfigSnapshot(t, func(ns *structs.NodeService) {
+					for i := range ns.Proxy.Upstreams {
+						if ns.Proxy.Upstreams[i].Config == nil {
+							ns.Proxy.Upstreams[i].Config = map[string]interface{}{}
+						}
+
+						ns.Proxy.Upstreams[i].Config["protocol"] = "http2"
+						ns.Proxy.Upstreams[i].Config["connection_pool"] = map[string]interface{}{
+							"http_idle_timeout_ms":   60000,
+							"max_concurrent_streams": 50,
+						}
+					}
+				}, nil)
+			},
+		},
 		{
 			name: "connect-proxy-with-chain",
 			create: func(t testinf.T) *proxycfg.ConfigSnapshot {
@@ -313,6 +388,18 @@ func TestClustersFromSnapshot(t *testing.T) {
 				return proxycfg.TestConfigSnapshotDiscoveryChain(t, "lb-resolver", nil, nil)
 			},
 		},
+		{
+			name: "connect-proxy-with-chain-and-outlier-detection",
+			create: func(t testinf.T) *proxycfg.ConfigSnapshot {
+				return proxycfg.TestConfigSnapshotDiscoveryChain(t, "outlier-detection-resolver", nil, nil)
+			},
+		},
+		{
+			name: "connect-proxy-with-fault-injection-and-mirror",
+			create: func(t testinf.T) *proxycfg.ConfigSnapshot {
+				return proxycfg.TestConfigSnapshotDiscoveryChain(t, "router-with-fault-injection-and-mirror", nil, nil)
+			},
+		},
 		{
 			name: "expose-paths-local-app-paths",
 			create: func(t testinf.T) *proxycfg.ConfigSnapshot {
@@ -585,6 +672,10 @@ func TestClustersFromSnapshot(t *testing.T) {
 			name:   "terminating-gateway-sni",
 			create: proxycfg.TestConfigSnapshotTerminatingGatewaySNI,
 		},
+		{
+			name:   "terminating-gateway-destinations",
+			create: proxycfg.TestConfigSnapshotTerminatingGatewayDestinations,
+		},
 		{
 			name:   "terminating-gateway-ignore-extra-resolvers",
 			create: proxycfg.TestConfigSnapshotTerminatingGatewayIgnoreExtraResolvers,
@@ -609,6 +700,10 @@ func TestClustersFromSnapshot(t *testing.T) {
 			name:   "transparent-proxy-dial-instances-directly",
 			create: proxycfg.TestConfigSnapshotTransparentProxyDialDirectly,
 		},
+		{
+			name:   "transparent-proxy-destination",
+			create: proxycfg.TestConfigSnapshotTransparentProxyDestination,
+		},
 	}
 
 	latestEnvoyVersion := proxysupport.EnvoyVersions[0]
@@ -804,6 +899,34 @@ func TestEnvoyLBConfig_InjectToCluster(t *testing.T) {
 	}
 }
 
+func TestInjectConnectionPoolToCluster_MaxRequestsPerConnection(t *testing.T) {
+	max := 10
+	c := &envoy_cluster_v3.Cluster{}
+	err := injectConnectionPoolToCluster(&structs.ConnectionPoolConfig{MaxRequestsPerConnection: &max}, "http", c)
+	require.NoError(t, err)
+
+	var opts envoy_upstreams_v3.HttpProtocolOptions
+	err = ptypes.UnmarshalAny(c.TypedExtensionProtocolOptions["envoy.extensions.upstreams.http.v3.HttpProtocolOptions"], &opts)
+	require.NoError(t, err)
+	require.NotNil(t, opts.CommonHttpProtocolOptions)
+
+	// The field is not part of the generated types, so it can only be read
+	// back from the raw message.
+	raw := opts.CommonHttpProtocolOptions.ProtoReflect().GetUnknown()
+	num, typ, n := protowire.ConsumeTag(raw)
+	require.Greater(t, n, 0)
+	require.Equal(t, maxRequestsPerConnectionField, num)
+	require.Equal(t, protowire.BytesType, typ)
+
+	value, m := protowire.ConsumeBytes(raw[n:])
+	require.Greater(t, m, 0)
+	require.Len(t, raw, n+m)
+
+	var got wrappers.UInt32Value
+	require.NoError(t, proto.Unmarshal(value, &got))
+	require.Equal(t, uint32(10), got.Value)
+}
+
 // UID is just a convenience function to aid in writing tests less verbosely.
 func UID(input string) proxycfg.UpstreamID {
 	return proxycfg.UpstreamIDFromString(input)
@@ -58,6 +58,29 @@ type ProxyConfig struct {
 	// enable proxies in network namespaces to bind to a different port
 	// than the host port being advertised.
 	BindPort int `mapstructure:"bind_port"`
+
+	// RateLimit configures local rate limiting of the traffic accepted by the
+	// public listener. It is usually set through the service-defaults config
+	// entry.
+	RateLimit *structs.RateLimitConfig `mapstructure:"rate_limit"`
+
+	// ExtAuthz configures an external authorization service consulted ahead of
+	// intentions for the traffic accepted by the public listener.
+	ExtAuthz *structs.ExtAuthzConfig `mapstructure:"ext_authz"`
+
+	// ConnectionPool configures the idle timeouts, keepalives and request
+	// limits of the public listener and of the connections to the local app.
+	ConnectionPool *structs.ConnectionPoolConfig `mapstructure:"connection_pool"`
+
+	// MutualTLSMode controls whether the public listener also accepts
+	// plaintext connections. It is usually set through the proxy-defaults or
+	// service-defaults config entries.
+	MutualTLSMode structs.MutualTLSMode `mapstructure:"mutual_tls_mode"`
+
+	// PermissiveSourceCIDRs are the sources plaintext connections are
+	// accepted from in permissive mode. No plaintext connection is accepted
+	// if empty.
+	PermissiveSourceCIDRs []string `mapstructure:"permissive_source_cidrs"`
 }
 
 // ParseProxyConfig returns the ProxyConfig parsed from the an opaque map. If an
@@ -149,20 +172,122 @@ func ParseGatewayConfig(m map[string]interface{}) (GatewayConfig, error) {
 	return cfg, err
 }
 
-// Return an envoy.OutlierDetection populated by the values from this struct.
-// If all values are zero a default empty OutlierDetection will be returned to
-// enable outlier detection with default values.
-func ToOutlierDetection(p *structs.PassiveHealthCheck) *envoy_cluster_v3.OutlierDetection {
-	od := &envoy_cluster_v3.OutlierDetection{}
-	if p == nil {
-		return od
+// ParseAccessLogsConfig returns the access logs configuration from the opaque
+// config map of any kind of proxy. It is usually set through the
+// proxy-defaults config entry.
+func ParseAccessLogsConfig(m map[string]interface{}) (structs.AccessLogsConfig, error) {
+	var cfg struct {
+		AccessLogs structs.AccessLogsConfig `mapstructure:"access_logs"`
+	}
+	d, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		DecodeHook: mapstructure.ComposeDecodeHookFunc(
+			decode.HookWeakDecodeFromSlice,
+			decode.HookTranslateKeys,
+		),
+		Result:           &cfg,
+		WeaklyTypedInput: true,
+	})
+	if err != nil {
+		return cfg.AccessLogs, err
+	}
+	err = d.Decode(m)
+	return cfg.AccessLogs, err
+}
+
+// ParseTracingConfig returns the tracing configuration from the opaque config
+// map of any kind of proxy, or nil if tracing is not configured. It is usually
+// set through the proxy-defaults config entry.
+func ParseTracingConfig(m map[string]interface{}) (*structs.TracingConfig, error) {
+	var cfg struct {
+		Tracing *structs.TracingConfig `mapstructure:"tracing"`
+	}
+	d, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		DecodeHook: mapstructure.ComposeDecodeHookFunc(
+			decode.HookWeakDecodeFromSlice,
+			decode.HookTranslateKeys,
+		),
+		Result:           &cfg,
+		WeaklyTypedInput: true,
+	})
+	if err != nil {
+		return nil, err
 	}
+	err = d.Decode(m)
+	return cfg.Tracing, err
+}
 
-	if p.Interval != 0 {
-		od.Interval = ptypes.DurationProto(p.Interval)
+// ParseEnvoyExtensions returns the Envoy extensions to apply from the opaque
+// config map of any kind of proxy. They are usually set through the
+// proxy-defaults and service-defaults config entries.
+func ParseEnvoyExtensions(m map[string]interface{}) (structs.EnvoyExtensions, error) {
+	var cfg struct {
+		EnvoyExtensions structs.EnvoyExtensions `mapstructure:"envoy_extensions"`
 	}
-	if p.MaxFailures != 0 {
-		od.Consecutive_5Xx = &wrappers.UInt32Value{Value: p.MaxFailures}
+	d, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		DecodeHook: mapstructure.ComposeDecodeHookFunc(
+			decode.HookWeakDecodeFromSlice,
+			decode.HookTranslateKeys,
+		),
+		Result:           &cfg,
+		WeaklyTypedInput: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = d.Decode(m)
+	return cfg.EnvoyExtensions, err
+}
+
+// Return an envoy.OutlierDetection populated by the ejection policy of the
+// target service, with the values set in the passive health check of the
+// upstream taking precedence. If all values are zero a default empty
+// OutlierDetection will be returned to enable outlier detection with default
+// values.
+func ToOutlierDetection(policy *structs.OutlierDetection, p *structs.PassiveHealthCheck) *envoy_cluster_v3.OutlierDetection {
+	od := &envoy_cluster_v3.OutlierDetection{}
+
+	if policy != nil {
+		if policy.Interval != 0 {
+			od.Interval = ptypes.DurationProto(policy.Interval)
+		}
+		if policy.Consecutive5xx != 0 {
+			od.Consecutive_5Xx = &wrappers.UInt32Value{Value: policy.Consecutive5xx}
+		}
+		if policy.ConsecutiveGatewayFailure != 0 {
+			od.ConsecutiveGatewayFailure = &wrappers.UInt32Value{Value: policy.ConsecutiveGatewayFailure}
+			// Envoy doesn't enforce gateway failure ejections by default.
+			od.EnforcingConsecutiveGatewayFailure = &wrappers.UInt32Value{Value: 100}
+		}
+		if sr := policy.SuccessRate; sr != nil {
+			if sr.MinimumHosts != 0 {
+				od.SuccessRateMinimumHosts = &wrappers.UInt32Value{Value: sr.MinimumHosts}
+			}
+			if sr.RequestVolume != 0 {
+				od.SuccessRateRequestVolume = &wrappers.UInt32Value{Value: sr.RequestVolume}
+			}
+			if sr.StdevFactor != 0 {
+				// Envoy expects the factor multiplied by 1000.
+				od.SuccessRateStdevFactor = &wrappers.UInt32Value{Value: uint32(sr.StdevFactor * 1000)}
+			}
+		} else {
+			// Envoy enforces success rate ejections by default.
+			od.EnforcingSuccessRate = &wrappers.UInt32Value{Value: 0}
+		}
+		if policy.BaseEjectionTime != 0 {
+			od.BaseEjectionTime = ptypes.DurationProto(policy.BaseEjectionTime)
+		}
+		if policy.MaxEjectionPercent != 0 {
+			od.MaxEjectionPercent = &wrappers.UInt32Value{Value: policy.MaxEjectionPercent}
+		}
+	}
+
+	if p != nil {
+		if p.Interval != 0 {
+			od.Interval = ptypes.DurationProto(p.Interval)
+		}
+		if p.MaxFailures != 0 {
+			od.Consecutive_5Xx = &wrappers.UInt32Value{Value: p.MaxFailures}
+		}
 	}
 	return od
 }
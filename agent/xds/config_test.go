@@ -157,6 +157,99 @@ func TestParseProxyConfig(t *testing.T) {
 				Protocol:              "tcp",
 			},
 		},
+		{
+			name: "rate limit from service-defaults",
+			input: map[string]interface{}{
+				"rate_limit": map[string]interface{}{
+					"RequestsPerSecond": int64(100),
+					"Burst":             int64(0),
+					"Routes": []interface{}{
+						map[string]interface{}{
+							"PathPrefix":        "/admin",
+							"RequestsPerSecond": int64(1),
+							"Burst":             int64(0),
+						},
+					},
+					"Sources": nil,
+				},
+			},
+			want: ProxyConfig{
+				LocalConnectTimeoutMs: 5000,
+				Protocol:              "tcp",
+				RateLimit: &structs.RateLimitConfig{
+					RequestsPerSecond: 100,
+					Routes: []structs.RateLimitRoute{
+						{PathPrefix: "/admin", RequestsPerSecond: 1},
+					},
+				},
+			},
+		},
+		{
+			name: "rate limit, snake case",
+			input: map[string]interface{}{
+				"rate_limit": map[string]interface{}{
+					"requests_per_second": 100,
+					"sources": []interface{}{
+						map[string]interface{}{
+							"name":                "web",
+							"requests_per_second": "10",
+						},
+					},
+				},
+			},
+			want: ProxyConfig{
+				LocalConnectTimeoutMs: 5000,
+				Protocol:              "tcp",
+				RateLimit: &structs.RateLimitConfig{
+					RequestsPerSecond: 100,
+					Sources: []structs.RateLimitSource{
+						{Name: "web", RequestsPerSecond: 10},
+					},
+				},
+			},
+		},
+		{
+			name: "ext authz from service-defaults",
+			input: map[string]interface{}{
+				"ext_authz": map[string]interface{}{
+					"Service":          "authz",
+					"Protocol":         "http",
+					"PathPrefix":       "/check",
+					"TimeoutMs":        uint64(500),
+					"FailureModeAllow": true,
+				},
+			},
+			want: ProxyConfig{
+				LocalConnectTimeoutMs: 5000,
+				Protocol:              "tcp",
+				ExtAuthz: &structs.ExtAuthzConfig{
+					Service:          "authz",
+					Protocol:         "http",
+					PathPrefix:       "/check",
+					TimeoutMs:        500,
+					FailureModeAllow: true,
+				},
+			},
+		},
+		{
+			name: "ext authz, snake case",
+			input: map[string]interface{}{
+				"ext_authz": map[string]interface{}{
+					"service":            "authz",
+					"timeout_ms":         "250",
+					"failure_mode_allow": "true",
+				},
+			},
+			want: ProxyConfig{
+				LocalConnectTimeoutMs: 5000,
+				Protocol:              "tcp",
+				ExtAuthz: &structs.ExtAuthzConfig{
+					Service:          "authz",
+					TimeoutMs:        250,
+					FailureModeAllow: true,
+				},
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -167,6 +260,128 @@ func TestParseProxyConfig(t *testing.T) {
 	}
 }
 
+func TestParseAccessLogsConfig(t *testing.T) {
+	tests := []struct {
+		name  string
+		input map[string]interface{}
+		want  structs.AccessLogsConfig
+	}{
+		{
+			name:  "defaults - nil",
+			input: nil,
+			want:  structs.AccessLogsConfig{},
+		},
+		{
+			name: "snake case",
+			input: map[string]interface{}{
+				"access_logs": map[string]interface{}{
+					"enabled":               true,
+					"disable_listener_logs": true,
+					"type":                  "file",
+					"path":                  "/var/log/envoy/access.log",
+					"text_format":           "%RESPONSE_CODE%",
+				},
+			},
+			want: structs.AccessLogsConfig{
+				Enabled:             true,
+				DisableListenerLogs: true,
+				Type:                structs.FileLogSinkType,
+				Path:                "/var/log/envoy/access.log",
+				TextFormat:          "%RESPONSE_CODE%",
+			},
+		},
+		{
+			name: "camel case from central config",
+			input: map[string]interface{}{
+				"access_logs": map[string]interface{}{
+					"Enabled":    true,
+					"Type":       "stderr",
+					"JSONFormat": `{"status": "%RESPONSE_CODE%"}`,
+				},
+			},
+			want: structs.AccessLogsConfig{
+				Enabled:    true,
+				Type:       structs.StdErrLogSinkType,
+				JSONFormat: `{"status": "%RESPONSE_CODE%"}`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseAccessLogsConfig(tt.input)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestParseTracingConfig(t *testing.T) {
+	tests := []struct {
+		name  string
+		input map[string]interface{}
+		want  *structs.TracingConfig
+	}{
+		{
+			name:  "defaults - nil",
+			input: nil,
+			want:  nil,
+		},
+		{
+			name: "snake case",
+			input: map[string]interface{}{
+				"tracing": map[string]interface{}{
+					"provider": "zipkin",
+					"collector": map[string]interface{}{
+						"address": "zipkin.service.consul",
+						"port":    "9411",
+					},
+					"collector_endpoint":  "/api/v1/spans",
+					"sampling_percentage": 5,
+					"custom_tags": map[string]interface{}{
+						"env": "prod",
+					},
+				},
+			},
+			want: &structs.TracingConfig{
+				Provider: structs.ZipkinTracingProvider,
+				Collector: structs.TracingCollector{
+					Address: "zipkin.service.consul",
+					Port:    9411,
+				},
+				CollectorEndpoint:  "/api/v1/spans",
+				SamplingPercentage: 5,
+				CustomTags:         map[string]string{"env": "prod"},
+			},
+		},
+		{
+			name: "camel case from central config",
+			input: map[string]interface{}{
+				"tracing": map[string]interface{}{
+					"Provider": "opentelemetry",
+					"Collector": map[string]interface{}{
+						"Service": "otel-collector",
+					},
+					"SamplingPercentage": 0.5,
+				},
+			},
+			want: &structs.TracingConfig{
+				Provider: structs.OpenTelemetryTracingProvider,
+				Collector: structs.TracingCollector{
+					Service: "otel-collector",
+				},
+				SamplingPercentage: 0.5,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseTracingConfig(tt.input)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
 func TestParseGatewayConfig(t *testing.T) {
 	tests := []struct {
 		name  string
@@ -89,6 +89,7 @@ func (s *Server) processDelta(stream ADSDeltaStream, reqCh <-chan *envoy_discove
 		proxyID     structs.ServiceID
 		nonce       uint64 // xDS requires a unique nonce to correlate response/request pairs
 		ready       bool   // set to true after the first snapshot arrives
+		streamStat  *streamStatus
 	)
 
 	var (
@@ -220,6 +221,8 @@ func (s *Server) processDelta(stream ADSDeltaStream, reqCh <-chan *envoy_discove
 				}
 			}
 
+			newResourceMap = applyEnvoyExtensions(generator.Logger, newResourceMap, cfgSnap)
+
 			if err := populateChildIndexMap(newResourceMap); err != nil {
 				return status.Errorf(codes.Unavailable, "failed to index xDS resource versions: %v", err)
 			}
@@ -232,6 +235,7 @@ func (s *Server) processDelta(stream ADSDeltaStream, reqCh <-chan *envoy_discove
 			resourceMap = newResourceMap
 			currentVersions = newVersions
 			ready = true
+			streamStat.recordSnapshot(cfgSnap)
 		}
 
 		// Trigger state machine
@@ -256,6 +260,13 @@ func (s *Server) processDelta(stream ADSDeltaStream, reqCh <-chan *envoy_discove
 
 			generator.Logger = generator.Logger.With("service_id", proxyID.String()) // enhance future logs
 
+			// Report what is sent on this stream through Server.ProxyStatus.
+			streamStat = newStreamStatus(proxyID, node)
+			for _, handler := range handlers {
+				handler.status = streamStat
+			}
+			defer s.streamStatuses.register(proxyID, streamStat)()
+
 			generator.Logger.Trace("watching proxy, pending initial proxycfg snapshot for xDS")
 
 			// Now wait for the config so we can check ACL
@@ -394,6 +405,10 @@ type xDSDeltaType struct {
 	// resources within the child to be re-configured.
 	childType *xDSDeltaType
 
+	// status records the responses sent for this type and their (N)ACKs. It
+	// is nil until the proxy is identified.
+	status *streamStatus
+
 	// registered indicates if this type has been requested at least once by
 	// the proxy
 	registered bool
@@ -503,7 +518,7 @@ func (t *xDSDeltaType) Recv(req *envoy_discovery_v3.DeltaDiscoveryRequest, sf su
 		} else {
 			logger.Error("got error response from envoy proxy", "nonce", req.ResponseNonce,
 				"error", status.ErrorProto(req.ErrorDetail))
-			t.nack(req.ResponseNonce)
+			t.nack(req.ResponseNonce, req.ErrorDetail.GetMessage())
 			return deltaRecvResponseNack
 		}
 	}
@@ -618,9 +633,13 @@ func (t *xDSDeltaType) ack(nonce string) {
 	}
 	t.sentToEnvoyOnce = true
 	delete(t.pendingUpdates, nonce)
+	t.status.recordACK(t.typeURL, nonce, t.resourceVersions)
 }
 
-func (t *xDSDeltaType) nack(nonce string) {
+func (t *xDSDeltaType) nack(nonce, errorDetail string) {
+	if _, ok := t.pendingUpdates[nonce]; ok {
+		t.status.recordNACK(t.typeURL, nonce, errorDetail)
+	}
 	delete(t.pendingUpdates, nonce)
 }
 
@@ -681,6 +700,7 @@ func (t *xDSDeltaType) SendIfNew(
 		}
 	}
 	t.pendingUpdates[resp.Nonce] = updates
+	t.status.recordSend(t.typeURL, resp.Nonce, updates)
 
 	return nil, true
 }
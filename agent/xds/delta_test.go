@@ -19,6 +19,7 @@ import (
 	"github.com/hashicorp/consul/acl"
 	"github.com/hashicorp/consul/agent/proxycfg"
 	"github.com/hashicorp/consul/agent/structs"
+	"github.com/hashicorp/consul/agent/xds/proxysupport"
 	"github.com/hashicorp/consul/agent/xds/xdscommon"
 )
 
@@ -325,12 +326,50 @@ func TestServer_DeltaAggregatedResources_v3_NackLoop(t *testing.T) {
 		assertDeltaChanBlocked(t, envoy.deltaStream.sendCh)
 
 		// NACKs the listener update due to the bad public listener
-		envoy.SendDeltaReqNACK(t, xdscommon.ListenerType, 3, &rpcstatus.Status{})
+		envoy.SendDeltaReqNACK(t, xdscommon.ListenerType, 3, &rpcstatus.Status{
+			Message: "cannot bind '127.0.0.1:1': Permission denied",
+		})
 
 		// Consul should not respond until a new snapshot is delivered
 		assertDeltaChanBlocked(t, envoy.deltaStream.sendCh)
 	})
 
+	runStep(t, "proxy status reports the nack", func(t *testing.T) {
+		ps, ok := scenario.server.ProxyStatus(sid)
+		require.True(t, ok)
+
+		require.Equal(t, "web-sidecar-proxy", ps.ProxyID)
+		require.Equal(t, proxysupport.EnvoyVersions[0], ps.Envoy.Version)
+		require.NotNil(t, ps.Snapshot)
+		require.Equal(t, structs.ServiceKindConnectProxy, ps.Snapshot.Kind)
+		require.Equal(t, []string{"db", "prepared_query:geo-cache", "upstream_socket"}, ps.Snapshot.Upstreams)
+
+		clusters := ps.Resources[xdscommon.ClusterType]
+		require.Equal(t, hexString(1), clusters.LastAckedNonce)
+		require.Len(t, clusters.AckedVersions, 3)
+		require.Equal(t, clusters.Versions, clusters.AckedVersions)
+
+		listeners := ps.Resources[xdscommon.ListenerType]
+		require.Equal(t, hexString(3), listeners.LastSentNonce)
+		require.Equal(t, hexString(3), listeners.LastNackedNonce)
+		require.Contains(t, listeners.LastNackError, "Permission denied")
+		require.Len(t, listeners.Versions, 3)
+		require.Empty(t, listeners.AckedVersions)
+
+		var events []string
+		for _, e := range ps.Events {
+			events = append(events, e.Type+":"+e.Nonce)
+		}
+		require.Equal(t, []string{
+			"send:" + hexString(1),
+			"ack:" + hexString(1),
+			"send:" + hexString(2),
+			"ack:" + hexString(2),
+			"send:" + hexString(3),
+			"nack:" + hexString(3),
+		}, events)
+	})
+
 	runStep(t, "simulate envoy NACKing a listener update", func(t *testing.T) {
 		// Correct the port and deliver a new snapshot
 		snap.Port = 9999
@@ -361,6 +400,10 @@ func TestServer_DeltaAggregatedResources_v3_NackLoop(t *testing.T) {
 	case <-time.After(50 * time.Millisecond):
 		t.Fatalf("timed out waiting for handler to finish")
 	}
+
+	// The status is only kept while the proxy is connected.
+	_, ok := scenario.server.ProxyStatus(sid)
+	require.False(t, ok)
 }
 
 func TestServer_DeltaAggregatedResources_v3_BasicProtocol_HTTP2(t *testing.T) {
@@ -3,6 +3,8 @@ package xds
 import (
 	"errors"
 	"fmt"
+	"net"
+	"strconv"
 
 	envoy_cluster_v3 "github.com/envoyproxy/go-control-plane/envoy/config/cluster/v3"
 	envoy_core_v3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
@@ -61,6 +63,7 @@ func (s *ResourceGenerator) endpointsFromSnapshotConnectProxy(cfgSnap *proxycfg.
 			uid,
 			chain,
 			cfgSnap.Locality,
+			cfgSnap.ServiceLocality,
 			upstreamCfg,
 			cfgSnap.ConnectProxy.WatchedUpstreamEndpoints[uid],
 			cfgSnap.ConnectProxy.WatchedGatewayEndpoints[uid],
@@ -94,6 +97,61 @@ func (s *ResourceGenerator) endpointsFromSnapshotConnectProxy(cfgSnap *proxycfg.
 		}
 	}
 
+	// Upstreams imported from cluster peers are reached through the mesh
+	// gateways of the peer.
+	for uid, u := range cfgSnap.ConnectProxy.UpstreamConfig {
+		if u.DestinationPeer == "" {
+			continue
+		}
+		peerMesh, ok := cfgSnap.ConnectProxy.UpstreamPeerMeshInfos[uid]
+		if !ok {
+			continue
+		}
+
+		la := &envoy_endpoint_v3.ClusterLoadAssignment{
+			ClusterName: peerServiceSNI(u, peerMesh),
+			Endpoints:   []*envoy_endpoint_v3.LocalityLbEndpoints{},
+		}
+
+		// The gateways are only used while the peer has a healthy instance.
+		healthy := cfgSnap.ConnectProxy.PeerUpstreamEndpoints[uid].Filter(false)
+		if len(healthy) > 0 {
+			lbEndpoints := make([]*envoy_endpoint_v3.LbEndpoint, 0, len(peerMesh.GatewayAddresses))
+			for _, addr := range peerMesh.GatewayAddresses {
+				host, portStr, err := net.SplitHostPort(addr)
+				if err != nil {
+					s.Logger.Warn("invalid mesh gateway address of peer", "upstream", uid, "address", addr, "error", err)
+					continue
+				}
+				port, err := strconv.Atoi(portStr)
+				if err != nil {
+					s.Logger.Warn("invalid mesh gateway address of peer", "upstream", uid, "address", addr, "error", err)
+					continue
+				}
+				lbEndpoints = append(lbEndpoints, makeEndpoint(host, port))
+			}
+			if len(lbEndpoints) > 0 {
+				la.Endpoints = append(la.Endpoints, &envoy_endpoint_v3.LocalityLbEndpoints{
+					LbEndpoints: lbEndpoints,
+				})
+			}
+		}
+		resources = append(resources, la)
+	}
+
+	// External destinations are reached through the terminating gateways
+	// linked to them.
+	for _, dest := range destinationAddresses(cfgSnap) {
+		la := makeLoadAssignment(
+			dest.sni,
+			[]loadAssignmentEndpointGroup{
+				{Endpoints: cfgSnap.ConnectProxy.DestinationGateways[dest.uid]},
+			},
+			cfgSnap.Locality,
+		)
+		resources = append(resources, la)
+	}
+
 	return resources, nil
 }
 
@@ -334,6 +392,7 @@ func (s *ResourceGenerator) endpointsFromSnapshotIngressGateway(cfgSnap *proxycf
 				uid,
 				cfgSnap.IngressGateway.DiscoveryChain[uid],
 				proxycfg.GatewayKey{Datacenter: cfgSnap.Datacenter, Partition: u.DestinationPartition},
+				cfgSnap.ServiceLocality,
 				&u,
 				cfgSnap.IngressGateway.WatchedUpstreamEndpoints[uid],
 				cfgSnap.IngressGateway.WatchedGatewayEndpoints[uid],
@@ -370,6 +429,7 @@ func (s *ResourceGenerator) endpointsFromDiscoveryChain(
 	uid proxycfg.UpstreamID,
 	chain *structs.CompiledDiscoveryChain,
 	gatewayKey proxycfg.GatewayKey,
+	locality *structs.Locality,
 	upstream *structs.Upstream,
 	upstreamEndpoints map[string]structs.CheckServiceNodes,
 	gatewayEndpoints map[string]structs.CheckServiceNodes,
@@ -454,7 +514,8 @@ func (s *ResourceGenerator) endpointsFromDiscoveryChain(
 
 		var endpointGroups []loadAssignmentEndpointGroup
 
-		if failover != nil && len(failover.Targets) > 0 {
+		hasFailover := failover != nil && len(failover.Targets) > 0
+		if hasFailover {
 			endpointGroups = make([]loadAssignmentEndpointGroup, 0, len(failover.Targets)+1)
 
 			endpointGroups = append(endpointGroups, primaryGroup)
@@ -476,11 +537,25 @@ func (s *ResourceGenerator) endpointsFromDiscoveryChain(
 			endpointGroups = append(endpointGroups, primaryGroup)
 		}
 
+		prioritize := node.Resolver.PrioritizeByLocality.IsEnabled() && !locality.IsEmpty()
+		if prioritize {
+			endpointGroups = prioritizeByLocality(locality, endpointGroups)
+		}
+
 		la := makeLoadAssignment(
 			clusterName,
 			endpointGroups,
 			gatewayKey,
 		)
+		if prioritize && !hasFailover {
+			// Use Envoy's default overprovisioning factor so that traffic
+			// starts spilling over to the next priority as soon as too few
+			// instances of the local zone are healthy, rather than when none
+			// are. The factor applies to every priority of the cluster, so
+			// with failover targets the large one is kept and each locality
+			// only spills over once none of its instances are healthy.
+			la.Policy = nil
+		}
 		resources = append(resources, la)
 	}
 
@@ -509,7 +584,12 @@ func makeLoadAssignment(clusterName string, endpointGroups []loadAssignmentEndpo
 
 	for priority, endpointGroup := range endpointGroups {
 		endpoints := endpointGroup.Endpoints
-		es := make([]*envoy_endpoint_v3.LbEndpoint, 0, len(endpoints))
+
+		// Instances are grouped by their Envoy locality, in the order their
+		// localities first appear. A group without any instance still yields
+		// one empty set of endpoints so that priorities stay contiguous.
+		var localities []*envoy_endpoint_v3.LocalityLbEndpoints
+		byLocality := make(map[structs.Locality]*envoy_endpoint_v3.LocalityLbEndpoints)
 
 		for _, ep := range endpoints {
 			// TODO (mesh-gateway) - should we respect the translate_wan_addrs configuration here or just always use the wan for cross-dc?
@@ -520,7 +600,22 @@ func makeLoadAssignment(clusterName string, endpointGroups []loadAssignmentEndpo
 				healthStatus = endpointGroup.OverrideHealth
 			}
 
-			es = append(es, &envoy_endpoint_v3.LbEndpoint{
+			var key structs.Locality
+			if l := ep.Locality(); l != nil {
+				key = *l
+			}
+			lle, ok := byLocality[key]
+			if !ok {
+				lle = &envoy_endpoint_v3.LocalityLbEndpoints{
+					Locality:    makeEnvoyLocality(key),
+					Priority:    uint32(priority),
+					LbEndpoints: make([]*envoy_endpoint_v3.LbEndpoint, 0, len(endpoints)),
+				}
+				byLocality[key] = lle
+				localities = append(localities, lle)
+			}
+
+			lle.LbEndpoints = append(lle.LbEndpoints, &envoy_endpoint_v3.LbEndpoint{
 				HostIdentifier: &envoy_endpoint_v3.LbEndpoint_Endpoint{
 					Endpoint: &envoy_endpoint_v3.Endpoint{
 						Address: makeAddress(addr, port),
@@ -531,15 +626,68 @@ func makeLoadAssignment(clusterName string, endpointGroups []loadAssignmentEndpo
 			})
 		}
 
-		cla.Endpoints = append(cla.Endpoints, &envoy_endpoint_v3.LocalityLbEndpoints{
-			Priority:    uint32(priority),
-			LbEndpoints: es,
-		})
+		if len(localities) == 0 {
+			localities = append(localities, &envoy_endpoint_v3.LocalityLbEndpoints{
+				Priority:    uint32(priority),
+				LbEndpoints: []*envoy_endpoint_v3.LbEndpoint{},
+			})
+		}
+		cla.Endpoints = append(cla.Endpoints, localities...)
 	}
 
 	return cla
 }
 
+func makeEnvoyLocality(l structs.Locality) *envoy_core_v3.Locality {
+	if l.IsEmpty() {
+		return nil
+	}
+	return &envoy_core_v3.Locality{
+		Region: l.Region,
+		Zone:   l.Zone,
+	}
+}
+
+// prioritizeByLocality splits each endpoint group into the instances in the
+// zone of the proxy, the instances in the rest of its region and every other
+// instance, in that order of priority. Groups pointing at mesh gateways are
+// left untouched since their instances are in another datacenter or partition.
+func prioritizeByLocality(local *structs.Locality, groups []loadAssignmentEndpointGroup) []loadAssignmentEndpointGroup {
+	result := make([]loadAssignmentEndpointGroup, 0, len(groups))
+
+	for _, group := range groups {
+		if group.OverrideHealth != envoy_core_v3.HealthStatus_UNKNOWN || len(group.Endpoints) == 0 {
+			result = append(result, group)
+			continue
+		}
+
+		var sameZone, sameRegion, others structs.CheckServiceNodes
+		for _, ep := range group.Endpoints {
+			l := ep.Locality()
+			switch {
+			case local.SameZone(l):
+				sameZone = append(sameZone, ep)
+			case local.SameRegion(l):
+				sameRegion = append(sameRegion, ep)
+			default:
+				others = append(others, ep)
+			}
+		}
+
+		for _, endpoints := range []structs.CheckServiceNodes{sameZone, sameRegion, others} {
+			if len(endpoints) == 0 {
+				continue
+			}
+			result = append(result, loadAssignmentEndpointGroup{
+				Endpoints:   endpoints,
+				OnlyPassing: group.OnlyPassing,
+			})
+		}
+	}
+
+	return result
+}
+
 func makeLoadAssignmentEndpointGroup(
 	targets map[string]*structs.DiscoveryTarget,
 	targetHealth map[string]structs.CheckServiceNodes,
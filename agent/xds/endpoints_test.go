@@ -94,6 +94,19 @@ func Test_makeLoadAssignment(t *testing.T) {
 	testWarningCheckServiceNodes[0].Checks[0].Status = "warning"
 	testWarningCheckServiceNodes[1].Checks[0].Status = "warning"
 
+	testLocalityCheckServiceNodesRaw, err := copystructure.Copy(testCheckServiceNodes)
+	require.NoError(t, err)
+	testLocalityCheckServiceNodes := testLocalityCheckServiceNodesRaw.(structs.CheckServiceNodes)
+
+	testLocalityCheckServiceNodes[0].Node.Meta = map[string]string{
+		structs.MetaKeyLocalityRegion: "us-east-1",
+		structs.MetaKeyLocalityZone:   "us-east-1a",
+	}
+	testLocalityCheckServiceNodes[1].Service.Meta = map[string]string{
+		structs.MetaKeyLocalityRegion: "us-east-1",
+		structs.MetaKeyLocalityZone:   "us-east-1b",
+	}
+
 	// TODO(rb): test onlypassing
 	tests := []struct {
 		name        string
@@ -204,6 +217,44 @@ func Test_makeLoadAssignment(t *testing.T) {
 				}},
 			},
 		},
+		{
+			name:        "instances, localities",
+			clusterName: "service:test",
+			endpoints: []loadAssignmentEndpointGroup{
+				{Endpoints: testLocalityCheckServiceNodes},
+			},
+			want: &envoy_endpoint_v3.ClusterLoadAssignment{
+				ClusterName: "service:test",
+				Endpoints: []*envoy_endpoint_v3.LocalityLbEndpoints{
+					{
+						Locality: &envoy_core_v3.Locality{Region: "us-east-1", Zone: "us-east-1a"},
+						LbEndpoints: []*envoy_endpoint_v3.LbEndpoint{
+							{
+								HostIdentifier: &envoy_endpoint_v3.LbEndpoint_Endpoint{
+									Endpoint: &envoy_endpoint_v3.Endpoint{
+										Address: makeAddress("10.10.10.10", 1234),
+									}},
+								HealthStatus:        envoy_core_v3.HealthStatus_HEALTHY,
+								LoadBalancingWeight: makeUint32Value(1),
+							},
+						},
+					},
+					{
+						Locality: &envoy_core_v3.Locality{Region: "us-east-1", Zone: "us-east-1b"},
+						LbEndpoints: []*envoy_endpoint_v3.LbEndpoint{
+							{
+								HostIdentifier: &envoy_endpoint_v3.LbEndpoint_Endpoint{
+									Endpoint: &envoy_endpoint_v3.Endpoint{
+										Address: makeAddress("10.10.10.20", 1234),
+									}},
+								HealthStatus:        envoy_core_v3.HealthStatus_HEALTHY,
+								LoadBalancingWeight: makeUint32Value(1),
+							},
+						},
+					},
+				},
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -233,6 +284,12 @@ func TestEndpointsFromSnapshot(t *testing.T) {
 				return proxycfg.TestConfigSnapshot(t, nil, nil)
 			},
 		},
+		{
+			name: "connect-proxy-with-peered-upstreams",
+			create: func(t testinf.T) *proxycfg.ConfigSnapshot {
+				return proxycfg.TestConfigSnapshotPeering(t)
+			},
+		},
 		{
 			name: "mesh-gateway",
 			create: func(t testinf.T) *proxycfg.ConfigSnapshot {
@@ -269,6 +326,28 @@ func TestEndpointsFromSnapshot(t *testing.T) {
 				return proxycfg.TestConfigSnapshotDiscoveryChain(t, "simple", nil, nil)
 			},
 		},
+		{
+			name: "connect-proxy-with-chain-and-locality-failover",
+			create: func(t testinf.T) *proxycfg.ConfigSnapshot {
+				return proxycfg.TestConfigSnapshotDiscoveryChain(t, "locality-failover", func(ns *structs.NodeService) {
+					ns.Meta = map[string]string{
+						structs.MetaKeyLocalityRegion: "us-east-1",
+						structs.MetaKeyLocalityZone:   "us-east-1a",
+					}
+				}, nil)
+			},
+		},
+		{
+			name: "connect-proxy-with-chain-and-locality-failover-with-failover",
+			create: func(t testinf.T) *proxycfg.ConfigSnapshot {
+				return proxycfg.TestConfigSnapshotDiscoveryChain(t, "locality-failover-with-failover", func(ns *structs.NodeService) {
+					ns.Meta = map[string]string{
+						structs.MetaKeyLocalityRegion: "us-east-1",
+						structs.MetaKeyLocalityZone:   "us-east-1a",
+					}
+				}, nil)
+			},
+		},
 		{
 			name: "connect-proxy-with-chain-external-sni",
 			create: func(t testinf.T) *proxycfg.ConfigSnapshot {
@@ -474,6 +553,10 @@ func TestEndpointsFromSnapshot(t *testing.T) {
 				return proxycfg.TestConfigSnapshotTerminatingGateway(t, false, nil, nil)
 			},
 		},
+		{
+			name:   "transparent-proxy-destination",
+			create: proxycfg.TestConfigSnapshotTransparentProxyDestination,
+		},
 		{
 			name:   "terminating-gateway-service-subsets",
 			create: proxycfg.TestConfigSnapshotTerminatingGatewayServiceSubsets,
@@ -0,0 +1,89 @@
+package xds
+
+import (
+	"fmt"
+	"strings"
+
+	envoy_core_v3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	envoy_listener_v3 "github.com/envoyproxy/go-control-plane/envoy/config/listener/v3"
+	"github.com/golang/protobuf/proto"
+	"github.com/hashicorp/go-hclog"
+
+	"github.com/hashicorp/consul/agent/envoyextensions"
+	"github.com/hashicorp/consul/agent/proxycfg"
+	"github.com/hashicorp/consul/agent/xds/xdscommon"
+	"github.com/hashicorp/consul/api"
+)
+
+// applyEnvoyExtensions patches the resources generated for a proxy with the
+// Envoy extensions configured for it, in order. Extensions are validated when
+// their config entry is written, so one failing here is logged and skipped
+// rather than keeping the proxy from getting any configuration.
+func applyEnvoyExtensions(logger hclog.Logger, resources *xdscommon.IndexedResources, cfgSnap *proxycfg.ConfigSnapshot) *xdscommon.IndexedResources {
+	extensions, err := ParseEnvoyExtensions(cfgSnap.Proxy.Config)
+	if err != nil {
+		logger.Warn("failed to parse Envoy extensions, none were applied", "error", err)
+		return resources
+	}
+
+	for _, ext := range extensions {
+		extension, err := envoyextensions.Construct(ext.Name, ext.Arguments)
+		if err != nil {
+			logger.Warn("invalid Envoy extension was skipped", "extension", ext.Name, "error", err)
+			continue
+		}
+		if !extension.CanApply(api.ServiceKind(cfgSnap.Kind)) {
+			continue
+		}
+
+		for name, msg := range resources.Index[xdscommon.ListenerType] {
+			listener, ok := msg.(*envoy_listener_v3.Listener)
+			if !ok {
+				continue
+			}
+			newListener, patched, err := patchListener(listener, extension)
+			if err != nil {
+				logger.Warn("failed to apply Envoy extension to listener",
+					"extension", ext.Name, "listener", name, "error", err)
+				continue
+			}
+			if patched {
+				resources.Index[xdscommon.ListenerType][name] = newListener
+			}
+		}
+	}
+
+	return resources
+}
+
+// patchListener applies an extension to the network filters of the public
+// listener or of an upstream listener. The listener is copied so a failed
+// patch leaves it untouched.
+func patchListener(l *envoy_listener_v3.Listener, extension envoyextensions.Extension) (*envoy_listener_v3.Listener, bool, error) {
+	var inbound bool
+	switch {
+	case strings.HasPrefix(l.Name, PublicListenerName+":"):
+		inbound = true
+	case l.TrafficDirection == envoy_core_v3.TrafficDirection_OUTBOUND:
+		inbound = false
+	default:
+		// Listeners for exposed paths and the like are left alone.
+		return l, false, nil
+	}
+
+	l = proto.Clone(l).(*envoy_listener_v3.Listener)
+	patched := false
+	for _, chain := range l.FilterChains {
+		for i, filter := range chain.Filters {
+			newFilter, ok, err := extension.PatchFilter(filter, inbound)
+			if err != nil {
+				return nil, false, fmt.Errorf("error patching filter %s: %w", filter.Name, err)
+			}
+			if ok {
+				chain.Filters[i] = newFilter
+				patched = true
+			}
+		}
+	}
+	return l, patched, nil
+}
@@ -0,0 +1,142 @@
+package xds
+
+import (
+	"path/filepath"
+	"sort"
+	"testing"
+
+	envoy_listener_v3 "github.com/envoyproxy/go-control-plane/envoy/config/listener/v3"
+	"github.com/golang/protobuf/proto"
+	testinf "github.com/mitchellh/go-testing-interface"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hashicorp/consul/agent/proxycfg"
+	"github.com/hashicorp/consul/agent/structs"
+	"github.com/hashicorp/consul/agent/xds/proxysupport"
+	"github.com/hashicorp/consul/agent/xds/xdscommon"
+	"github.com/hashicorp/consul/sdk/testutil"
+)
+
+func TestEnvoyExtensionsFromSnapshot(t *testing.T) {
+	const luaScript = `
+function envoy_on_request(request_handle)
+  request_handle:headers():add("x-consul-lua", "true")
+end`
+
+	tests := []struct {
+		name   string
+		create func(t testinf.T) *proxycfg.ConfigSnapshot
+	}{
+		{
+			name: "lua-inbound",
+			create: func(t testinf.T) *proxycfg.ConfigSnapshot {
+				return proxycfg.TestConfigSnapshot(t, func(ns *structs.NodeService) {
+					ns.Proxy.Config["protocol"] = "http"
+					ns.Proxy.Config["envoy_extensions"] = []interface{}{
+						map[string]interface{}{
+							"Name": "builtin/lua",
+							"Arguments": map[string]interface{}{
+								"Listener": "inbound",
+								"Script":   luaScript,
+							},
+						},
+					}
+				}, nil)
+			},
+		},
+		{
+			name: "lua-outbound",
+			create: func(t testinf.T) *proxycfg.ConfigSnapshot {
+				return proxycfg.TestConfigSnapshot(t, func(ns *structs.NodeService) {
+					ns.Proxy.Upstreams[0].Config["protocol"] = "http"
+					ns.Proxy.Config["envoy_extensions"] = []interface{}{
+						map[string]interface{}{
+							"Name": "builtin/lua",
+							"Arguments": map[string]interface{}{
+								"Listener": "outbound",
+								"Script":   luaScript,
+							},
+						},
+					}
+				}, nil)
+			},
+		},
+		{
+			name: "wasm-inbound",
+			create: func(t testinf.T) *proxycfg.ConfigSnapshot {
+				return proxycfg.TestConfigSnapshot(t, func(ns *structs.NodeService) {
+					ns.Proxy.Config["protocol"] = "http"
+					ns.Proxy.Config["envoy_extensions"] = []interface{}{
+						map[string]interface{}{
+							"Name": "builtin/wasm",
+							"Arguments": map[string]interface{}{
+								"Listener":      "inbound",
+								"Filename":      "/etc/envoy/filter.wasm",
+								"RootID":        "filter",
+								"Configuration": `{"header": "x-filtered"}`,
+								"FailOpen":      true,
+							},
+						},
+					}
+				}, nil)
+			},
+		},
+		{
+			// Invalid extensions are skipped rather than failing the proxy.
+			name: "invalid-extension-skipped",
+			create: func(t testinf.T) *proxycfg.ConfigSnapshot {
+				return proxycfg.TestConfigSnapshot(t, func(ns *structs.NodeService) {
+					ns.Proxy.Config["protocol"] = "http"
+					ns.Proxy.Config["envoy_extensions"] = []interface{}{
+						map[string]interface{}{
+							"Name": "builtin/lua",
+							"Arguments": map[string]interface{}{
+								"Listener": "inbound",
+							},
+						},
+					}
+				}, nil)
+			},
+		},
+	}
+
+	latestEnvoyVersion := proxysupport.EnvoyVersions[0]
+	for _, envoyVersion := range proxysupport.EnvoyVersions {
+		sf, err := determineSupportedProxyFeaturesFromString(envoyVersion)
+		require.NoError(t, err)
+		t.Run("envoy-"+envoyVersion, func(t *testing.T) {
+			for _, tt := range tests {
+				t.Run(tt.name, func(t *testing.T) {
+					snap := tt.create(t)
+
+					// We need to replace the TLS certs with deterministic ones to make golden
+					// files workable.
+					setupTLSRootsAndLeaf(t, snap)
+
+					g := newResourceGenerator(testutil.Logger(t), nil, nil, false)
+					g.ProxyFeatures = sf
+
+					res, err := g.allResourcesFromSnapshot(snap)
+					require.NoError(t, err)
+
+					indexedResources := applyEnvoyExtensions(g.Logger, indexResources(g.Logger, res), snap)
+
+					var msgs []proto.Message
+					for _, e := range indexedResources.Index[xdscommon.ListenerType] {
+						msgs = append(msgs, e)
+					}
+					sort.Slice(msgs, func(i, j int) bool {
+						return msgs[i].(*envoy_listener_v3.Listener).Name < msgs[j].(*envoy_listener_v3.Listener).Name
+					})
+					r, err := createResponse(xdscommon.ListenerType, "00000001", "00000001", msgs)
+					require.NoError(t, err)
+
+					gotJSON := protoToJSON(t, r)
+					require.JSONEq(t, goldenEnvoy(t,
+						filepath.Join("envoy_extensions", "listeners", tt.name),
+						envoyVersion, latestEnvoyVersion, gotJSON), gotJSON)
+				})
+			}
+		})
+	}
+}
@@ -0,0 +1,242 @@
+package xds
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	envoy_core_v3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	envoy_listener_v3 "github.com/envoyproxy/go-control-plane/envoy/config/listener/v3"
+	envoy_http_ext_authz_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/ext_authz/v3"
+	envoy_network_ext_authz_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/ext_authz/v3"
+	envoy_http_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/http_connection_manager/v3"
+
+	"github.com/golang/protobuf/ptypes"
+
+	"github.com/hashicorp/consul/agent/proxycfg"
+	"github.com/hashicorp/consul/agent/structs"
+)
+
+const (
+	extAuthzHTTPFilterName    = "envoy.filters.http.ext_authz"
+	extAuthzNetworkFilterName = "envoy.filters.network.ext_authz"
+
+	// extAuthzDefaultTimeout matches the default of Envoy for gRPC services,
+	// HTTP services require it to be set.
+	extAuthzDefaultTimeout = 200 * time.Millisecond
+)
+
+// makeExtAuthzHTTPFilter returns the HTTP filter calling the external
+// authorization service described by cfg, or nil if cfg is nil. If the
+// service cannot be called, for instance because it is not an upstream of the
+// proxy, a filter rejecting every request is returned instead so that the
+// rest of the listeners are still served. With FailureModeAllow set, no
+// filter is returned and every request is allowed, as Envoy would do when
+// the service fails.
+func (s *ResourceGenerator) makeExtAuthzHTTPFilter(cfgSnap *proxycfg.ConfigSnapshot, cfg *structs.ExtAuthzConfig) (*envoy_http_v3.HttpFilter, error) {
+	if cfg == nil {
+		return nil, nil
+	}
+
+	extAuthz, err := makeExtAuthzHTTPConfig(cfgSnap, cfg)
+	if err != nil && cfg.FailureModeAllow {
+		s.Logger.Warn("allowing all requests to the public listener, the ext_authz service cannot be called",
+			"proxy", cfgSnap.ProxyID,
+			"error", err,
+		)
+		return nil, nil
+	}
+	if err != nil {
+		s.Logger.Warn("rejecting all requests to the public listener, the ext_authz service cannot be called",
+			"proxy", cfgSnap.ProxyID,
+			"error", err,
+		)
+		return makeDenyAllRBACHTTPFilter()
+	}
+	return makeEnvoyHTTPFilter(extAuthzHTTPFilterName, extAuthz)
+}
+
+func makeExtAuthzHTTPConfig(cfgSnap *proxycfg.ConfigSnapshot, cfg *structs.ExtAuthzConfig) (*envoy_http_ext_authz_v3.ExtAuthz, error) {
+	protocol, err := extAuthzProtocol(cfg)
+	if err != nil {
+		return nil, err
+	}
+	clusterName, err := extAuthzClusterName(cfgSnap, cfg, protocol)
+	if err != nil {
+		return nil, err
+	}
+
+	extAuthz := &envoy_http_ext_authz_v3.ExtAuthz{
+		TransportApiVersion: envoy_core_v3.ApiVersion_V3,
+		FailureModeAllow:    cfg.FailureModeAllow,
+	}
+	switch protocol {
+	case structs.ExtAuthzProtocolGRPC:
+		extAuthz.Services = &envoy_http_ext_authz_v3.ExtAuthz_GrpcService{
+			GrpcService: makeExtAuthzGrpcService(cfg, clusterName),
+		}
+	case structs.ExtAuthzProtocolHTTP:
+		extAuthz.Services = &envoy_http_ext_authz_v3.ExtAuthz_HttpService{
+			HttpService: &envoy_http_ext_authz_v3.HttpService{
+				ServerUri: &envoy_core_v3.HttpUri{
+					// Only the cluster is used to route the call, the URI
+					// sets the host header.
+					Uri: "http://" + cfg.Service,
+					HttpUpstreamType: &envoy_core_v3.HttpUri_Cluster{
+						Cluster: clusterName,
+					},
+					Timeout: ptypes.DurationProto(extAuthzTimeout(cfg)),
+				},
+				PathPrefix: cfg.PathPrefix,
+			},
+		}
+	}
+	return extAuthz, nil
+}
+
+// makeExtAuthzNetworkFilter returns the network filter calling the external
+// authorization service described by cfg for each new connection, or nil if
+// cfg is nil. Envoy only supports gRPC authorization services for
+// connections. As with makeExtAuthzHTTPFilter, a filter rejecting every
+// connection is returned if the service cannot be called, unless
+// FailureModeAllow is set.
+func (s *ResourceGenerator) makeExtAuthzNetworkFilter(cfgSnap *proxycfg.ConfigSnapshot, cfg *structs.ExtAuthzConfig) (*envoy_listener_v3.Filter, error) {
+	if cfg == nil {
+		return nil, nil
+	}
+
+	extAuthz, err := makeExtAuthzNetworkConfig(cfgSnap, cfg)
+	if err != nil && cfg.FailureModeAllow {
+		s.Logger.Warn("allowing all connections to the public listener, the ext_authz service cannot be called",
+			"proxy", cfgSnap.ProxyID,
+			"error", err,
+		)
+		return nil, nil
+	}
+	if err != nil {
+		s.Logger.Warn("rejecting all connections to the public listener, the ext_authz service cannot be called",
+			"proxy", cfgSnap.ProxyID,
+			"error", err,
+		)
+		return makeDenyAllRBACNetworkFilter()
+	}
+	return makeFilter(extAuthzNetworkFilterName, extAuthz)
+}
+
+func makeExtAuthzNetworkConfig(cfgSnap *proxycfg.ConfigSnapshot, cfg *structs.ExtAuthzConfig) (*envoy_network_ext_authz_v3.ExtAuthz, error) {
+	protocol, err := extAuthzProtocol(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if protocol != structs.ExtAuthzProtocolGRPC {
+		return nil, fmt.Errorf("ext_authz protocol %q is only supported for HTTP-like services", protocol)
+	}
+	clusterName, err := extAuthzClusterName(cfgSnap, cfg, protocol)
+	if err != nil {
+		return nil, err
+	}
+
+	return &envoy_network_ext_authz_v3.ExtAuthz{
+		StatPrefix:          "connect_authz",
+		GrpcService:         makeExtAuthzGrpcService(cfg, clusterName),
+		FailureModeAllow:    cfg.FailureModeAllow,
+		TransportApiVersion: envoy_core_v3.ApiVersion_V3,
+	}, nil
+}
+
+// injectExtAuthzNetworkFilter inserts the network filter calling the external
+// authorization service as the first filter of each filter chain of a public
+// listener.
+func (s *ResourceGenerator) injectExtAuthzNetworkFilter(cfgSnap *proxycfg.ConfigSnapshot, listener *envoy_listener_v3.Listener, cfg *structs.ExtAuthzConfig) error {
+	extAuthzFilter, err := s.makeExtAuthzNetworkFilter(cfgSnap, cfg)
+	if err != nil || extAuthzFilter == nil {
+		return err
+	}
+
+	for idx := range listener.FilterChains {
+		listener.FilterChains[idx].Filters =
+			append([]*envoy_listener_v3.Filter{
+				extAuthzFilter,
+			}, listener.FilterChains[idx].Filters...)
+	}
+	return nil
+}
+
+func makeExtAuthzGrpcService(cfg *structs.ExtAuthzConfig, clusterName string) *envoy_core_v3.GrpcService {
+	return &envoy_core_v3.GrpcService{
+		TargetSpecifier: &envoy_core_v3.GrpcService_EnvoyGrpc_{
+			EnvoyGrpc: &envoy_core_v3.GrpcService_EnvoyGrpc{
+				ClusterName: clusterName,
+			},
+		},
+		Timeout: ptypes.DurationProto(extAuthzTimeout(cfg)),
+	}
+}
+
+func extAuthzProtocol(cfg *structs.ExtAuthzConfig) (string, error) {
+	// The config may come from the opaque proxy config, which is not
+	// validated.
+	switch protocol := strings.ToLower(cfg.Protocol); protocol {
+	case "":
+		return structs.ExtAuthzProtocolGRPC, nil
+	case structs.ExtAuthzProtocolGRPC, structs.ExtAuthzProtocolHTTP:
+		return protocol, nil
+	default:
+		return "", fmt.Errorf("unsupported ext_authz protocol %q", cfg.Protocol)
+	}
+}
+
+func extAuthzTimeout(cfg *structs.ExtAuthzConfig) time.Duration {
+	if cfg.TimeoutMs > 0 {
+		return time.Duration(cfg.TimeoutMs) * time.Millisecond
+	}
+	return extAuthzDefaultTimeout
+}
+
+// extAuthzClusterName returns the name of the cluster the proxy uses to reach
+// the authorization service. The service must be an upstream of the proxy in
+// the local datacenter whose discovery chain resolves to a single target,
+// since Envoy calls it through one cluster.
+func extAuthzClusterName(cfgSnap *proxycfg.ConfigSnapshot, cfg *structs.ExtAuthzConfig, protocol string) (string, error) {
+	if cfg.Service == "" {
+		return "", fmt.Errorf("ext_authz service is required")
+	}
+
+	// Upstream customizations change the name of the cluster, so pick the
+	// same upstream every time.
+	uids := make([]proxycfg.UpstreamID, 0, len(cfgSnap.ConnectProxy.DiscoveryChain))
+	for uid := range cfgSnap.ConnectProxy.DiscoveryChain {
+		uids = append(uids, uid)
+	}
+	sort.Slice(uids, func(i, j int) bool {
+		return uids[i].String() < uids[j].String()
+	})
+
+	for _, uid := range uids {
+		chain := cfgSnap.ConnectProxy.DiscoveryChain[uid]
+		if chain.ServiceName != cfg.Service ||
+			chain.Namespace != cfg.NamespaceOrDefault() ||
+			chain.Partition != cfg.PartitionOrDefault() ||
+			chain.Datacenter != cfgSnap.Datacenter {
+			continue
+		}
+
+		explicit := cfgSnap.ConnectProxy.UpstreamConfig[uid].HasLocalPortOrSocket()
+		if _, implicit := cfgSnap.ConnectProxy.IntentionUpstreams[uid]; !implicit && !explicit {
+			continue
+		}
+
+		if protocol == structs.ExtAuthzProtocolGRPC && chain.Protocol != "grpc" && chain.Protocol != "http2" {
+			return "", fmt.Errorf("ext_authz service %q must use the \"grpc\" protocol, not %q", cfg.Service, chain.Protocol)
+		}
+
+		target, err := simpleChainTarget(chain)
+		if err != nil {
+			return "", fmt.Errorf("ext_authz service %q: %v", cfg.Service, err)
+		}
+		return CustomizeClusterName(target.Name, chain), nil
+	}
+
+	return "", fmt.Errorf("ext_authz service %q is not an upstream of the proxy", cfg.Service)
+}
@@ -0,0 +1,283 @@
+package xds
+
+import (
+	"sort"
+
+	envoy_core_v3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	envoy_rbac_v3 "github.com/envoyproxy/go-control-plane/envoy/config/rbac/v3"
+	envoy_route_v3 "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
+	envoy_http_jwt_authn_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/jwt_authn/v3"
+	envoy_http_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/http_connection_manager/v3"
+	envoy_matcher_v3 "github.com/envoyproxy/go-control-plane/envoy/type/matcher/v3"
+
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/hashicorp/consul/agent/structs"
+)
+
+const jwtAuthnFilterName = "envoy.filters.http.jwt_authn"
+
+// makeJWTAuthnHTTPFilter returns the filter validating the tokens of the JWT
+// providers referenced by intentions. Requests without a token are let
+// through so that the RBAC filter can decide whether one was required, but
+// requests with an invalid token are rejected. It returns nil if no intention
+// requires a token.
+//
+// The payload of a valid token is stored in the dynamic metadata of the
+// request, where the RBAC permissions built by convertJWTRequirement match on
+// its claims.
+func makeJWTAuthnHTTPFilter(
+	providers map[string]*structs.JWTProviderConfigEntry,
+	intentions structs.Intentions,
+) (*envoy_http_v3.HttpFilter, error) {
+	cfg := &envoy_http_jwt_authn_v3.JwtAuthentication{
+		Providers: make(map[string]*envoy_http_jwt_authn_v3.JwtProvider),
+	}
+
+	var requirements []*envoy_http_jwt_authn_v3.JwtRequirement
+	for _, name := range referencedJWTProviders(intentions) {
+		// An unknown provider is left out, so permissions requiring one of
+		// its tokens never match.
+		p, ok := providers[name]
+		if !ok {
+			continue
+		}
+		cfg.Providers[name] = makeJWTProvider(p)
+		requirements = append(requirements, &envoy_http_jwt_authn_v3.JwtRequirement{
+			RequiresType: &envoy_http_jwt_authn_v3.JwtRequirement_ProviderName{
+				ProviderName: name,
+			},
+		})
+	}
+	if len(requirements) == 0 {
+		return nil, nil
+	}
+
+	requirements = append(requirements, &envoy_http_jwt_authn_v3.JwtRequirement{
+		RequiresType: &envoy_http_jwt_authn_v3.JwtRequirement_AllowMissing{
+			AllowMissing: &emptypb.Empty{},
+		},
+	})
+	cfg.Rules = []*envoy_http_jwt_authn_v3.RequirementRule{
+		{
+			Match: &envoy_route_v3.RouteMatch{
+				PathSpecifier: &envoy_route_v3.RouteMatch_Prefix{Prefix: "/"},
+			},
+			RequirementType: &envoy_http_jwt_authn_v3.RequirementRule_Requires{
+				Requires: &envoy_http_jwt_authn_v3.JwtRequirement{
+					RequiresType: &envoy_http_jwt_authn_v3.JwtRequirement_RequiresAny{
+						RequiresAny: &envoy_http_jwt_authn_v3.JwtRequirementOrList{
+							Requirements: requirements,
+						},
+					},
+				},
+			},
+		},
+	}
+	return makeEnvoyHTTPFilter(jwtAuthnFilterName, cfg)
+}
+
+// makeIngressJWTAuthnHTTPFilter returns the filter validating the tokens
+// required by the services of an ingress listener. Each virtual host selects
+// the requirement of its service by name, see
+// injectIngressJWTRequirementToVirtualHost. It returns nil if no service of
+// the listener requires a token from a known provider.
+func makeIngressJWTAuthnHTTPFilter(
+	providers map[string]*structs.JWTProviderConfigEntry,
+	listener structs.IngressListener,
+) (*envoy_http_v3.HttpFilter, error) {
+	cfg := &envoy_http_jwt_authn_v3.JwtAuthentication{
+		Providers:      make(map[string]*envoy_http_jwt_authn_v3.JwtProvider),
+		RequirementMap: make(map[string]*envoy_http_jwt_authn_v3.JwtRequirement),
+	}
+	for _, svc := range listener.Services {
+		req := makeIngressJWTRequirement(providers, svc.JWT)
+		if req == nil {
+			continue
+		}
+		cfg.RequirementMap[ingressServiceIdentifier(svc)] = req
+		for _, p := range svc.JWT.Providers {
+			if entry, ok := providers[p.Name]; ok {
+				cfg.Providers[p.Name] = makeJWTProvider(entry)
+			}
+		}
+	}
+	if len(cfg.RequirementMap) == 0 {
+		return nil, nil
+	}
+	return makeEnvoyHTTPFilter(jwtAuthnFilterName, cfg)
+}
+
+// makeIngressJWTRequirement returns a requirement accepting a valid token from
+// any of the known providers of req. It returns nil if req is nil or none of
+// its providers are known.
+func makeIngressJWTRequirement(
+	providers map[string]*structs.JWTProviderConfigEntry,
+	req *structs.IngressJWTRequirement,
+) *envoy_http_jwt_authn_v3.JwtRequirement {
+	if req == nil {
+		return nil
+	}
+
+	var requirements []*envoy_http_jwt_authn_v3.JwtRequirement
+	for _, p := range req.Providers {
+		if _, ok := providers[p.Name]; !ok {
+			continue
+		}
+		requirements = append(requirements, &envoy_http_jwt_authn_v3.JwtRequirement{
+			RequiresType: &envoy_http_jwt_authn_v3.JwtRequirement_ProviderName{
+				ProviderName: p.Name,
+			},
+		})
+	}
+
+	switch len(requirements) {
+	case 0:
+		return nil
+	case 1:
+		return requirements[0]
+	}
+	return &envoy_http_jwt_authn_v3.JwtRequirement{
+		RequiresType: &envoy_http_jwt_authn_v3.JwtRequirement_RequiresAny{
+			RequiresAny: &envoy_http_jwt_authn_v3.JwtRequirementOrList{
+				Requirements: requirements,
+			},
+		},
+	}
+}
+
+// injectIngressJWTRequirementToVirtualHost makes the JWT filter of the
+// listener enforce the requirement of the service on its virtual host.
+func injectIngressJWTRequirementToVirtualHost(
+	providers map[string]*structs.JWTProviderConfigEntry,
+	svc *structs.IngressService,
+	vh *envoy_route_v3.VirtualHost,
+) error {
+	if svc.JWT == nil {
+		return nil
+	}
+
+	if makeIngressJWTRequirement(providers, svc.JWT) == nil {
+		// None of the providers exist, so no token can be valid. Reject every
+		// request rather than letting them through unauthenticated.
+		vh.Routes = []*envoy_route_v3.Route{
+			{
+				Match: makeDefaultRouteMatch(),
+				Action: &envoy_route_v3.Route_DirectResponse{
+					DirectResponse: &envoy_route_v3.DirectResponseAction{
+						Status: 401,
+					},
+				},
+			},
+		}
+		return nil
+	}
+
+	return setVirtualHostPerFilterConfig(vh, jwtAuthnFilterName, &envoy_http_jwt_authn_v3.PerRouteConfig{
+		RequirementSpecifier: &envoy_http_jwt_authn_v3.PerRouteConfig_RequirementName{
+			RequirementName: ingressServiceIdentifier(*svc),
+		},
+	})
+}
+
+func makeJWTProvider(p *structs.JWTProviderConfigEntry) *envoy_http_jwt_authn_v3.JwtProvider {
+	jwks := &envoy_core_v3.DataSource{}
+	if local := p.JSONWebKeySet.Local; local.Filename != "" {
+		jwks.Specifier = &envoy_core_v3.DataSource_Filename{Filename: local.Filename}
+	} else {
+		jwks.Specifier = &envoy_core_v3.DataSource_InlineString{InlineString: local.JWKS}
+	}
+
+	return &envoy_http_jwt_authn_v3.JwtProvider{
+		Issuer:    p.Issuer,
+		Audiences: p.Audiences,
+		JwksSourceSpecifier: &envoy_http_jwt_authn_v3.JwtProvider_LocalJwks{
+			LocalJwks: jwks,
+		},
+		// Keep the token on the request so the service can read the claims
+		// of the end user.
+		Forward:           true,
+		PayloadInMetadata: jwtPayloadMetadataKey(p.Name),
+		ClockSkewSeconds:  uint32(p.ClockSkewSeconds),
+	}
+}
+
+// referencedJWTProviders returns the sorted names of the JWT providers used by
+// the permissions of intentions.
+func referencedJWTProviders(intentions structs.Intentions) []string {
+	seen := make(map[string]struct{})
+	for _, ixn := range intentions {
+		for _, perm := range ixn.Permissions {
+			if perm.JWT == nil {
+				continue
+			}
+			for _, p := range perm.JWT.Providers {
+				seen[p.Name] = struct{}{}
+			}
+		}
+	}
+
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// convertJWTRequirement returns a permission matching requests with a valid
+// token from any of the providers of req whose claims match.
+func convertJWTRequirement(req *structs.IntentionJWTRequirement) *envoy_rbac_v3.Permission {
+	perms := make([]*envoy_rbac_v3.Permission, 0, len(req.Providers))
+	for _, p := range req.Providers {
+		// Every validated token has an issuer, so its presence tells that
+		// the token was issued by this provider.
+		parts := []*envoy_rbac_v3.Permission{
+			jwtClaimPermission(p.Name, []string{"iss"}, &envoy_matcher_v3.ValueMatcher{
+				MatchPattern: &envoy_matcher_v3.ValueMatcher_PresentMatch{PresentMatch: true},
+			}),
+		}
+		for _, c := range p.VerifyClaims {
+			parts = append(parts, jwtClaimPermission(p.Name, c.Path, &envoy_matcher_v3.ValueMatcher{
+				MatchPattern: &envoy_matcher_v3.ValueMatcher_StringMatch{
+					StringMatch: &envoy_matcher_v3.StringMatcher{
+						MatchPattern: &envoy_matcher_v3.StringMatcher_Exact{Exact: c.Value},
+					},
+				},
+			}))
+		}
+		perms = append(perms, andPermissions(parts))
+	}
+
+	if len(perms) == 1 {
+		return perms[0]
+	}
+	return &envoy_rbac_v3.Permission{
+		Rule: &envoy_rbac_v3.Permission_OrRules{
+			OrRules: &envoy_rbac_v3.Permission_Set{Rules: perms},
+		},
+	}
+}
+
+func jwtClaimPermission(provider string, path []string, value *envoy_matcher_v3.ValueMatcher) *envoy_rbac_v3.Permission {
+	segments := make([]*envoy_matcher_v3.MetadataMatcher_PathSegment, 0, len(path)+1)
+	for _, key := range append([]string{jwtPayloadMetadataKey(provider)}, path...) {
+		segments = append(segments, &envoy_matcher_v3.MetadataMatcher_PathSegment{
+			Segment: &envoy_matcher_v3.MetadataMatcher_PathSegment_Key{Key: key},
+		})
+	}
+
+	return &envoy_rbac_v3.Permission{
+		Rule: &envoy_rbac_v3.Permission_Metadata{
+			Metadata: &envoy_matcher_v3.MetadataMatcher{
+				Filter: jwtAuthnFilterName,
+				Path:   segments,
+				Value:  value,
+			},
+		},
+	}
+}
+
+func jwtPayloadMetadataKey(provider string) string {
+	return "jwt_payload_" + provider
+}
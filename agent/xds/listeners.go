@@ -18,6 +18,7 @@ import (
 	envoy_core_v3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
 	envoy_listener_v3 "github.com/envoyproxy/go-control-plane/envoy/config/listener/v3"
 	envoy_route_v3 "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
+	envoy_http_fault_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/fault/v3"
 	envoy_grpc_http1_bridge_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/grpc_http1_bridge/v3"
 	envoy_grpc_stats_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/grpc_stats/v3"
 	envoy_http_router_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/router/v3"
@@ -49,18 +50,33 @@ func (s *ResourceGenerator) listenersFromSnapshot(cfgSnap *proxycfg.ConfigSnapsh
 		return nil, errors.New("nil config given")
 	}
 
+	var (
+		resources []proto.Message
+		err       error
+	)
 	switch cfgSnap.Kind {
 	case structs.ServiceKindConnectProxy:
-		return s.listenersFromSnapshotConnectProxy(cfgSnap)
+		resources, err = s.listenersFromSnapshotConnectProxy(cfgSnap)
 	case structs.ServiceKindTerminatingGateway:
-		return s.listenersFromSnapshotGateway(cfgSnap)
+		resources, err = s.listenersFromSnapshotGateway(cfgSnap)
 	case structs.ServiceKindMeshGateway:
-		return s.listenersFromSnapshotGateway(cfgSnap)
+		resources, err = s.listenersFromSnapshotGateway(cfgSnap)
 	case structs.ServiceKindIngressGateway:
-		return s.listenersFromSnapshotGateway(cfgSnap)
+		resources, err = s.listenersFromSnapshotGateway(cfgSnap)
 	default:
 		return nil, fmt.Errorf("Invalid service kind: %v", cfgSnap.Kind)
 	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.injectAccessLogs(cfgSnap, resources); err != nil {
+		return nil, err
+	}
+	if err := s.injectTracing(cfgSnap, resources); err != nil {
+		return nil, err
+	}
+	return resources, nil
 }
 
 // listenersFromSnapshotConnectProxy returns the "listeners" for a connect proxy service
@@ -146,6 +162,9 @@ func (s *ResourceGenerator) listenersFromSnapshotConnectProxy(cfgSnap *proxycfg.
 				filterName:  filterName,
 				protocol:    cfg.Protocol,
 				useRDS:      useRDS,
+
+				tcpIdleTimeoutMs: tcpIdleTimeoutMs(cfg.ConnectionPool),
+				faultInjection:   useRDS && chainUsesFaultInjection(chain),
 			})
 			if err != nil {
 				return nil, err
@@ -171,6 +190,9 @@ func (s *ResourceGenerator) listenersFromSnapshotConnectProxy(cfgSnap *proxycfg.
 			filterName:  filterName,
 			protocol:    cfg.Protocol,
 			useRDS:      useRDS,
+
+			tcpIdleTimeoutMs: tcpIdleTimeoutMs(cfg.ConnectionPool),
+			faultInjection:   useRDS && chainUsesFaultInjection(chain),
 		})
 		if err != nil {
 			return nil, err
@@ -257,6 +279,39 @@ func (s *ResourceGenerator) listenersFromSnapshotConnectProxy(cfgSnap *proxycfg.
 				outboundListener.FilterChains[j].FilterChainMatch.PrefixRanges[0].AddressPrefix
 		})
 
+		// Add a filter chain for every address and port of the external
+		// destinations the proxy can reach. IP addresses are matched by the
+		// original destination and hostnames by the SNI of the TLS handshake,
+		// which requires the TLS inspector.
+		var hasHostname bool
+		for _, dest := range destinationAddresses(cfgSnap) {
+			filterChain, err := s.makeUpstreamFilterChain(filterChainOpts{
+				clusterName: dest.sni,
+				filterName:  dest.sni,
+				protocol:    "tcp",
+			})
+			if err != nil {
+				return nil, err
+			}
+
+			if structs.IsDestinationHostname(dest.address) {
+				hasHostname = true
+				filterChain.FilterChainMatch = makeSNIFilterChainMatch(dest.address)
+			} else {
+				filterChain.FilterChainMatch = makeFilterChainMatchFromAddrs(map[string]struct{}{dest.address: {}})
+			}
+			// Destinations can share an address on different ports.
+			filterChain.FilterChainMatch.DestinationPort = &wrappers.UInt32Value{Value: uint32(dest.port)}
+			outboundListener.FilterChains = append(outboundListener.FilterChains, filterChain)
+		}
+		if hasHostname {
+			tlsInspector, err := makeTLSInspectorListenerFilter()
+			if err != nil {
+				return nil, err
+			}
+			outboundListener.ListenerFilters = append(outboundListener.ListenerFilters, tlsInspector)
+		}
+
 		// Add a catch-all filter chain that acts as a TCP proxy to destinations outside the mesh
 		if meshConf := cfgSnap.MeshConfig(); meshConf == nil ||
 			!meshConf.TransparentProxy.MeshDestinationsOnly {
@@ -278,12 +333,24 @@ func (s *ResourceGenerator) listenersFromSnapshotConnectProxy(cfgSnap *proxycfg.
 		}
 	}
 
-	// Looping over explicit upstreams is only needed for prepared queries because they do not have discovery chains
+	// Looping over explicit upstreams is only needed for prepared queries and
+	// upstreams imported from cluster peers because they do not have discovery chains
 	for uid, u := range cfgSnap.ConnectProxy.UpstreamConfig {
-		if u.DestinationType != structs.UpstreamDestTypePreparedQuery {
+		if u.DestinationType != structs.UpstreamDestTypePreparedQuery && u.DestinationPeer == "" {
 			continue
 		}
 
+		// TODO (SNI partition) add partition for upstream SNI
+		clusterName := connect.UpstreamSNI(u, "", cfgSnap.Datacenter, cfgSnap.Roots.TrustDomain)
+		if u.DestinationPeer != "" {
+			peerMesh, ok := cfgSnap.ConnectProxy.UpstreamPeerMeshInfos[uid]
+			if !ok {
+				// The peer has not sent its mesh information yet.
+				continue
+			}
+			clusterName = peerServiceSNI(u, peerMesh)
+		}
+
 		cfg, err := structs.ParseUpstreamConfig(u.Config)
 		if err != nil {
 			// Don't hard fail on a config typo, just warn. The parse func returns
@@ -307,11 +374,12 @@ func (s *ResourceGenerator) listenersFromSnapshotConnectProxy(cfgSnap *proxycfg.
 		upstreamListener := makeListener(uid.EnvoyID(), u, envoy_core_v3.TrafficDirection_OUTBOUND)
 
 		filterChain, err := s.makeUpstreamFilterChain(filterChainOpts{
-			// TODO (SNI partition) add partition for upstream SNI
-			clusterName: connect.UpstreamSNI(u, "", cfgSnap.Datacenter, cfgSnap.Roots.TrustDomain),
+			clusterName: clusterName,
 			filterName:  uid.EnvoyID(),
 			routeName:   uid.EnvoyID(),
 			protocol:    cfg.Protocol,
+
+			tcpIdleTimeoutMs: tcpIdleTimeoutMs(cfg.ConnectionPool),
 		})
 		if err != nil {
 			return nil, err
@@ -690,10 +758,46 @@ func extractRdsResourceNames(listener *envoy_listener_v3.Listener) ([]string, er
 	return found, nil
 }
 
+// makeInboundHTTPAuthzFilters returns the HTTP filters enforcing intentions on
+// the public listener of a connect proxy, in the order they must run: token
+// validation for intentions requiring a JWT, the external authorization
+// service if one is configured, then the RBAC filter. The RBAC filter is for
+// plaintext connections from plaintextSources if not nil, see
+// makePlaintextRBACHTTPFilter.
+func (s *ResourceGenerator) makeInboundHTTPAuthzFilters(
+	cfgSnap *proxycfg.ConfigSnapshot,
+	extAuthz *structs.ExtAuthzConfig,
+	plaintextSources []*envoy_core_v3.CidrRange,
+) ([]*envoy_http_v3.HttpFilter, error) {
+	var filters []*envoy_http_v3.HttpFilter
+
+	jwtFilter, err := makeJWTAuthnHTTPFilter(cfgSnap.ConnectProxy.JWTProviders, cfgSnap.ConnectProxy.Intentions)
+	if err != nil {
+		return nil, err
+	}
+	if jwtFilter != nil {
+		filters = append(filters, jwtFilter)
+	}
+
+	extAuthzFilter, err := s.makeExtAuthzHTTPFilter(cfgSnap, extAuthz)
+	if err != nil {
+		return nil, err
+	}
+	if extAuthzFilter != nil {
+		filters = append(filters, extAuthzFilter)
+	}
+
+	rbacFilter, err := makePlaintextRBACHTTPFilter(cfgSnap.ConnectProxy.Intentions, cfgSnap.IntentionDefaultAllow, plaintextSources)
+	if err != nil {
+		return nil, err
+	}
+	return append(filters, rbacFilter), nil
+}
+
 // Locate the existing http connect manager L4 filter and inject our RBAC filter at the top.
 func injectHTTPFilterOnFilterChains(
 	listener *envoy_listener_v3.Listener,
-	authzFilter *envoy_http_v3.HttpFilter,
+	authzFilters ...*envoy_http_v3.HttpFilter,
 ) error {
 	for chainIdx, chain := range listener.FilterChains {
 		var (
@@ -733,10 +837,8 @@ func injectHTTPFilterOnFilterChains(
 			return err
 		}
 
-		// Insert our authz filter before any others
-		hcm.HttpFilters = append([]*envoy_http_v3.HttpFilter{
-			authzFilter,
-		}, hcm.HttpFilters...)
+		// Insert our authz filters before any others
+		hcm.HttpFilters = append(append([]*envoy_http_v3.HttpFilter{}, authzFilters...), hcm.HttpFilters...)
 
 		// And persist the modified filter.
 		newFilter, err := makeFilter(hcmFilter.Name, &hcm)
@@ -764,6 +866,9 @@ func (s *ResourceGenerator) injectConnectTLSOnFilterChains(cfgSnap *proxycfg.Con
 			),
 			RequireClientCertificate: &wrappers.BoolValue{Value: true},
 		}
+		if cfgSnap.Kind == structs.ServiceKindConnectProxy {
+			appendPeerTrustedCAs(tlsContext.CommonTlsContext, cfgSnap.ConnectProxy.ExportedPeerMeshInfos)
+		}
 		transportSocket, err := makeDownstreamTLSTransportSocket(tlsContext)
 		if err != nil {
 			return err
@@ -796,16 +901,13 @@ func (s *ResourceGenerator) makeInboundListener(cfgSnap *proxycfg.ConfigSnapshot
 
 		// For HTTP-like services attach an RBAC http filter and do a best-effort insert
 		if useHTTPFilter {
-			httpAuthzFilter, err := makeRBACHTTPFilter(
-				cfgSnap.ConnectProxy.Intentions,
-				cfgSnap.IntentionDefaultAllow,
-			)
+			httpAuthzFilters, err := s.makeInboundHTTPAuthzFilters(cfgSnap, cfg.ExtAuthz, nil)
 			if err != nil {
 				return nil, err
 			}
 
 			// Try our best to inject the HTTP RBAC filter.
-			if err := injectHTTPFilterOnFilterChains(l, httpAuthzFilter); err != nil {
+			if err := injectHTTPFilterOnFilterChains(l, httpAuthzFilters...); err != nil {
 				s.Logger.Warn(
 					"could not inject the HTTP RBAC filter to enforce intentions on user-provided "+
 						"'envoy_public_listener_json' config; falling back on the RBAC network filter instead",
@@ -818,7 +920,7 @@ func (s *ResourceGenerator) makeInboundListener(cfgSnap *proxycfg.ConfigSnapshot
 			}
 		}
 
-		err := s.finalizePublicListenerFromConfig(l, cfgSnap, useHTTPFilter)
+		err := s.finalizePublicListenerFromConfig(l, cfgSnap, cfg.ExtAuthz, useHTTPFilter)
 		if err != nil {
 			return nil, fmt.Errorf("failed to attach Consul filters and TLS context to custom public listener: %v", err)
 		}
@@ -850,12 +952,11 @@ func (s *ResourceGenerator) makeInboundListener(cfgSnap *proxycfg.ConfigSnapshot
 		routeName:        name,
 		cluster:          LocalAppClusterName,
 		requestTimeoutMs: cfg.LocalRequestTimeoutMs,
+		tcpIdleTimeoutMs: tcpIdleTimeoutMs(cfg.ConnectionPool),
+		rateLimit:        cfg.RateLimit,
 	}
 	if useHTTPFilter {
-		filterOpts.httpAuthzFilter, err = makeRBACHTTPFilter(
-			cfgSnap.ConnectProxy.Intentions,
-			cfgSnap.IntentionDefaultAllow,
-		)
+		filterOpts.httpAuthzFilters, err = s.makeInboundHTTPAuthzFilters(cfgSnap, cfg.ExtAuthz, nil)
 		if err != nil {
 			return nil, err
 		}
@@ -864,30 +965,163 @@ func (s *ResourceGenerator) makeInboundListener(cfgSnap *proxycfg.ConfigSnapshot
 	if err != nil {
 		return nil, err
 	}
+	filters := []*envoy_listener_v3.Filter{
+		filter,
+	}
+	if !useHTTPFilter {
+		// HTTP rate limits are applied by the connection manager, here we limit
+		// new connections instead.
+		rateLimitFilter, err := makeRateLimitNetworkFilter(cfg.RateLimit)
+		if err != nil {
+			return nil, err
+		}
+		if rateLimitFilter != nil {
+			filters = append([]*envoy_listener_v3.Filter{rateLimitFilter}, filters...)
+		}
+	}
 	l.FilterChains = []*envoy_listener_v3.FilterChain{
 		{
-			Filters: []*envoy_listener_v3.Filter{
-				filter,
-			},
+			Filters: filters,
 		},
 	}
 
-	err = s.finalizePublicListenerFromConfig(l, cfgSnap, useHTTPFilter)
+	err = s.finalizePublicListenerFromConfig(l, cfgSnap, cfg.ExtAuthz, useHTTPFilter)
 	if err != nil {
 		return nil, fmt.Errorf("failed to attach Consul filters and TLS context to custom public listener: %v", err)
 	}
 
+	if cfg.MutualTLSMode == structs.MutualTLSModePermissive {
+		if len(cfg.PermissiveSourceCIDRs) == 0 {
+			s.Logger.Warn("permissive mutual TLS mode has no source CIDRs to accept plaintext connections from, only accepting mutual TLS",
+				"proxy", cfgSnap.ProxyID,
+			)
+		} else if err := s.addPermissiveFilterChain(l, cfgSnap, filterOpts, cfg.ExtAuthz, cfg.PermissiveSourceCIDRs); err != nil {
+			return nil, fmt.Errorf("failed to add permissive filter chain to public listener: %v", err)
+		}
+	}
+
 	return l, err
 }
 
+// addPermissiveFilterChain lets a public listener also accept plaintext
+// connections from the given source CIDRs. Connections are told apart by the
+// TLS inspector. Plaintext connections carry no client certificate, so
+// intentions are enforced on them by source address instead, see
+// makePlaintextRBACNetworkFilter. Their filters use a dedicated stat prefix so
+// that they can be counted while the callers of the service join the mesh.
+func (s *ResourceGenerator) addPermissiveFilterChain(
+	l *envoy_listener_v3.Listener,
+	cfgSnap *proxycfg.ConfigSnapshot,
+	opts listenerFilterOpts,
+	extAuthz *structs.ExtAuthzConfig,
+	sourceCIDRs []string,
+) error {
+	if len(sourceCIDRs) == 0 {
+		return fmt.Errorf("no permissive source CIDRs")
+	}
+
+	tlsInspector, err := makeTLSInspectorListenerFilter()
+	if err != nil {
+		return err
+	}
+	l.ListenerFilters = append(l.ListenerFilters, tlsInspector)
+
+	for _, chain := range l.FilterChains {
+		if chain.FilterChainMatch == nil {
+			chain.FilterChainMatch = &envoy_listener_v3.FilterChainMatch{}
+		}
+		chain.FilterChainMatch.TransportProtocol = "tls"
+	}
+
+	ranges := make([]*envoy_core_v3.CidrRange, 0, len(sourceCIDRs))
+	for _, cidr := range sourceCIDRs {
+		_, ipNet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return fmt.Errorf("invalid permissive source CIDR %q: %v", cidr, err)
+		}
+		prefixLen, _ := ipNet.Mask.Size()
+		ranges = append(ranges, &envoy_core_v3.CidrRange{
+			AddressPrefix: ipNet.IP.String(),
+			PrefixLen:     &wrappers.UInt32Value{Value: uint32(prefixLen)},
+		})
+	}
+
+	opts.filterName = opts.filterName + "_plaintext"
+	useHTTPFilter := structs.IsProtocolHTTPLike(opts.protocol)
+	if useHTTPFilter {
+		opts.httpAuthzFilters, err = s.makeInboundHTTPAuthzFilters(cfgSnap, extAuthz, ranges)
+		if err != nil {
+			return err
+		}
+	}
+	filter, err := makeListenerFilter(opts)
+	if err != nil {
+		return err
+	}
+	filters := []*envoy_listener_v3.Filter{filter}
+	if !useHTTPFilter {
+		rateLimitFilter, err := makeRateLimitNetworkFilter(opts.rateLimit)
+		if err != nil {
+			return err
+		}
+		if rateLimitFilter != nil {
+			filters = append([]*envoy_listener_v3.Filter{rateLimitFilter}, filters...)
+		}
+
+		authzFilter, err := makePlaintextRBACNetworkFilter(
+			cfgSnap.ConnectProxy.Intentions,
+			cfgSnap.IntentionDefaultAllow,
+			ranges,
+		)
+		if err != nil {
+			return err
+		}
+		filters = append([]*envoy_listener_v3.Filter{authzFilter}, filters...)
+
+		extAuthzFilter, err := s.makeExtAuthzNetworkFilter(cfgSnap, extAuthz)
+		if err != nil {
+			return err
+		}
+		if extAuthzFilter != nil {
+			filters = append([]*envoy_listener_v3.Filter{extAuthzFilter}, filters...)
+		}
+	}
+
+	l.FilterChains = append(l.FilterChains, &envoy_listener_v3.FilterChain{
+		FilterChainMatch: &envoy_listener_v3.FilterChainMatch{
+			TransportProtocol:  "raw_buffer",
+			SourcePrefixRanges: ranges,
+		},
+		Filters: filters,
+	})
+	return nil
+}
+
 // finalizePublicListenerFromConfig is used for best-effort injection of Consul filter-chains onto listeners.
 // This include L4 authorization filters and TLS context.
-func (s *ResourceGenerator) finalizePublicListenerFromConfig(l *envoy_listener_v3.Listener, cfgSnap *proxycfg.ConfigSnapshot, useHTTPFilter bool) error {
+func (s *ResourceGenerator) finalizePublicListenerFromConfig(
+	l *envoy_listener_v3.Listener,
+	cfgSnap *proxycfg.ConfigSnapshot,
+	extAuthz *structs.ExtAuthzConfig,
+	useHTTPFilter bool,
+) error {
 	if !useHTTPFilter {
 		// Best-effort injection of L4 intentions
 		if err := s.injectConnectFilters(cfgSnap, l); err != nil {
 			return nil
 		}
+
+		// The external authorization service is consulted ahead of
+		// intentions. Unlike them it is not skipped on error, as that would
+		// let through connections it was meant to reject.
+		if err := s.injectExtAuthzNetworkFilter(cfgSnap, l, extAuthz); err != nil {
+			return err
+		}
+	}
+
+	// Always reject revoked identities, ahead of any intentions.
+	if err := s.injectRevocationFilter(cfgSnap, l); err != nil {
+		return err
 	}
 
 	// Always apply TLS certificates
@@ -897,6 +1131,23 @@ func (s *ResourceGenerator) finalizePublicListenerFromConfig(l *envoy_listener_v
 	return nil
 }
 
+// injectRevocationFilter inserts a filter that rejects revoked identities as
+// the first filter of each filter chain of a public listener.
+func (s *ResourceGenerator) injectRevocationFilter(cfgSnap *proxycfg.ConfigSnapshot, listener *envoy_listener_v3.Listener) error {
+	revocationFilter, err := makeRevocationRBACNetworkFilter(cfgSnap.Roots)
+	if err != nil || revocationFilter == nil {
+		return err
+	}
+
+	for idx := range listener.FilterChains {
+		listener.FilterChains[idx].Filters =
+			append([]*envoy_listener_v3.Filter{
+				revocationFilter,
+			}, listener.FilterChains[idx].Filters...)
+	}
+	return nil
+}
+
 func (s *ResourceGenerator) makeExposedCheckListener(cfgSnap *proxycfg.ConfigSnapshot, cluster string, path structs.ExposePath) (proto.Message, error) {
 	cfg, err := ParseProxyConfig(cfgSnap.Proxy.Config)
 	if err != nil {
@@ -925,14 +1176,14 @@ func (s *ResourceGenerator) makeExposedCheckListener(cfgSnap *proxycfg.ConfigSna
 	filterName := fmt.Sprintf("exposed_path_filter_%s_%d", strippedPath, path.ListenerPort)
 
 	opts := listenerFilterOpts{
-		useRDS:          false,
-		protocol:        path.Protocol,
-		filterName:      filterName,
-		routeName:       filterName,
-		cluster:         cluster,
-		statPrefix:      "",
-		routePath:       path.Path,
-		httpAuthzFilter: nil,
+		useRDS:           false,
+		protocol:         path.Protocol,
+		filterName:       filterName,
+		routeName:        filterName,
+		cluster:          cluster,
+		statPrefix:       "",
+		routePath:        path.Path,
+		httpAuthzFilters: nil,
 	}
 	f, err := makeListenerFilter(opts)
 	if err != nil {
@@ -1048,6 +1299,30 @@ func (s *ResourceGenerator) makeTerminatingGatewayListener(
 		}
 	}
 
+	// Make a FilterChain for each address of the linked external destinations.
+	// Destinations are routed to by the SNI of their address, and only support
+	// TCP.
+	for _, svc := range cfgSnap.TerminatingGateway.ValidDestinations() {
+		intentions := cfgSnap.TerminatingGateway.Intentions[svc]
+		dest := cfgSnap.TerminatingGateway.ServiceConfigs[svc].Destination
+
+		for _, addr := range dest.Addresses {
+			clusterName := connect.DestinationSNI(addr, svc.Name, svc.NamespaceOrDefault(), svc.PartitionOrDefault(), cfgSnap.Datacenter, cfgSnap.Roots.TrustDomain)
+
+			clusterChain, err := s.makeFilterChainTerminatingGateway(
+				cfgSnap,
+				clusterName,
+				svc,
+				intentions,
+				"tcp",
+			)
+			if err != nil {
+				return nil, fmt.Errorf("failed to make filter chain for cluster %q: %v", clusterName, err)
+			}
+			l.FilterChains = append(l.FilterChains, clusterChain)
+		}
+	}
+
 	// Before we add the fallback, sort these chains by the matched name. All
 	// of these filter chains are independent, but envoy requires them to be in
 	// some order. If we put them in a random order then every xDS iteration
@@ -1107,6 +1382,15 @@ func (s *ResourceGenerator) makeFilterChainTerminatingGateway(
 		TransportSocket:  transportSocket,
 	}
 
+	// Revoked identities are rejected before any intentions are checked.
+	revocationFilter, err := makeRevocationRBACNetworkFilter(cfgSnap.Roots)
+	if err != nil {
+		return nil, err
+	}
+	if revocationFilter != nil {
+		filterChain.Filters = append(filterChain.Filters, revocationFilter)
+	}
+
 	// This controls if we do L4 or L7 intention checks.
 	useHTTPFilter := structs.IsProtocolHTTPLike(protocol)
 
@@ -1135,14 +1419,14 @@ func (s *ResourceGenerator) makeFilterChainTerminatingGateway(
 	}
 
 	if useHTTPFilter {
-		var err error
-		opts.httpAuthzFilter, err = makeRBACHTTPFilter(
+		httpAuthzFilter, err := makeRBACHTTPFilter(
 			intentions,
 			cfgSnap.IntentionDefaultAllow,
 		)
 		if err != nil {
 			return nil, err
 		}
+		opts.httpAuthzFilters = []*envoy_http_v3.HttpFilter{httpAuthzFilter}
 
 		opts.cluster = ""
 		opts.useRDS = true
@@ -1269,6 +1553,13 @@ type filterChainOpts struct {
 	protocol    string
 	useRDS      bool
 	tlsContext  *envoy_tls_v3.DownstreamTlsContext
+
+	// tcpIdleTimeoutMs overrides the idle timeout of the TCP proxy filter.
+	tcpIdleTimeoutMs *int
+
+	// faultInjection adds the fault filter to the HTTP filter chain for
+	// routes that inject faults.
+	faultInjection bool
 }
 
 func (s *ResourceGenerator) makeUpstreamFilterChain(opts filterChainOpts) (*envoy_listener_v3.FilterChain, error) {
@@ -1279,6 +1570,9 @@ func (s *ResourceGenerator) makeUpstreamFilterChain(opts filterChainOpts) (*envo
 		routeName:  opts.routeName,
 		cluster:    opts.clusterName,
 		statPrefix: "upstream.",
+
+		tcpIdleTimeoutMs: opts.tcpIdleTimeoutMs,
+		faultInjection:   opts.faultInjection,
 	})
 	if err != nil {
 		return nil, err
@@ -1374,8 +1668,15 @@ type listenerFilterOpts struct {
 	statPrefix       string
 	routePath        string
 	requestTimeoutMs *int
+	tcpIdleTimeoutMs *int
 	ingressGateway   bool
-	httpAuthzFilter  *envoy_http_v3.HttpFilter
+	httpAuthzFilters []*envoy_http_v3.HttpFilter
+	rateLimit        *structs.RateLimitConfig
+	faultInjection   bool
+
+	// ingressPolicyFilters enforce the CORS, request size and JWT policies of
+	// the services of an ingress listener.
+	ingressPolicyFilters []*envoy_http_v3.HttpFilter
 }
 
 func makeListenerFilter(opts listenerFilterOpts) (*envoy_listener_v3.Filter, error) {
@@ -1390,7 +1691,7 @@ func makeListenerFilter(opts listenerFilterOpts) (*envoy_listener_v3.Filter, err
 		} else if opts.cluster == "" {
 			return nil, fmt.Errorf("cluster name is required for a tcp proxy filter")
 		}
-		return makeTCPProxyFilter(opts.filterName, opts.cluster, opts.statPrefix)
+		return makeTCPProxyFilterWithIdleTimeout(opts.filterName, opts.cluster, opts.statPrefix, opts.tcpIdleTimeoutMs)
 	}
 }
 
@@ -1409,11 +1710,28 @@ func makeSNIClusterFilter() (*envoy_listener_v3.Filter, error) {
 }
 
 func makeTCPProxyFilter(filterName, cluster, statPrefix string) (*envoy_listener_v3.Filter, error) {
+	return makeTCPProxyFilterWithIdleTimeout(filterName, cluster, statPrefix, nil)
+}
+
+// makeTCPProxyFilterWithIdleTimeout makes a TCP proxy filter closing idle
+// connections after idleTimeoutMs, or after the Envoy default if nil.
+func makeTCPProxyFilterWithIdleTimeout(filterName, cluster, statPrefix string, idleTimeoutMs *int) (*envoy_listener_v3.Filter, error) {
 	cfg := &envoy_tcp_proxy_v3.TcpProxy{
 		StatPrefix:       makeStatPrefix(statPrefix, filterName),
 		ClusterSpecifier: &envoy_tcp_proxy_v3.TcpProxy_Cluster{Cluster: cluster},
 	}
-	return makeFilter("envoy.filters.network.tcp_proxy", cfg)
+	if idleTimeoutMs != nil {
+		cfg.IdleTimeout = ptypes.DurationProto(time.Duration(*idleTimeoutMs) * time.Millisecond)
+	}
+	return makeFilter(tcpProxyFilterName, cfg)
+}
+
+// tcpIdleTimeoutMs returns the TCP idle timeout of a connection pool, if any.
+func tcpIdleTimeoutMs(pool *structs.ConnectionPoolConfig) *int {
+	if pool == nil {
+		return nil
+	}
+	return pool.TCPIdleTimeoutMs
 }
 
 func makeStatPrefix(prefix, filterName string) string {
@@ -1443,6 +1761,10 @@ func makeHTTPFilter(opts listenerFilterOpts) (*envoy_listener_v3.Filter, error)
 		},
 	}
 
+	// rateLimitFilters are inserted after the authz filters below, so that only
+	// authorized requests count towards the limits.
+	var rateLimitFilters []*envoy_http_v3.HttpFilter
+
 	if opts.useRDS {
 		if opts.cluster != "" {
 			return nil, fmt.Errorf("cannot specify cluster name when using RDS")
@@ -1493,6 +1815,29 @@ func makeHTTPFilter(opts listenerFilterOpts) (*envoy_listener_v3.Filter, error)
 			route.Match.PathSpecifier = &envoy_route_v3.RouteMatch_Path{Path: opts.routePath}
 		}
 
+		routes := []*envoy_route_v3.Route{
+			route,
+		}
+		if isRateLimited(opts.rateLimit) {
+			routes, err = makeRateLimitRoutes(route, opts.rateLimit)
+			if err != nil {
+				return nil, err
+			}
+
+			sourceFilter, err := makeRateLimitSourceHTTPFilter(opts.rateLimit)
+			if err != nil {
+				return nil, err
+			}
+			if sourceFilter != nil {
+				rateLimitFilters = append(rateLimitFilters, sourceFilter)
+			}
+			rateLimitFilter, err := makeRateLimitHTTPFilter(opts.rateLimit)
+			if err != nil {
+				return nil, err
+			}
+			rateLimitFilters = append(rateLimitFilters, rateLimitFilter)
+		}
+
 		cfg.RouteSpecifier = &envoy_http_v3.HttpConnectionManager_RouteConfig{
 			RouteConfig: &envoy_route_v3.RouteConfiguration{
 				Name: opts.routeName,
@@ -1500,15 +1845,27 @@ func makeHTTPFilter(opts listenerFilterOpts) (*envoy_listener_v3.Filter, error)
 					{
 						Name:    opts.filterName,
 						Domains: []string{"*"},
-						Routes: []*envoy_route_v3.Route{
-							route,
-						},
+						Routes:  routes,
 					},
 				},
 			},
 		}
 	}
 
+	if opts.faultInjection {
+		// The faults themselves are configured per route, the filter only
+		// needs to be present.
+		faultFilter, err := makeEnvoyHTTPFilter(faultHTTPFilterName, &envoy_http_fault_v3.HTTPFault{})
+		if err != nil {
+			return nil, err
+		}
+		cfg.HttpFilters = append([]*envoy_http_v3.HttpFilter{faultFilter}, cfg.HttpFilters...)
+	}
+
+	if len(opts.ingressPolicyFilters) > 0 {
+		cfg.HttpFilters = append(append([]*envoy_http_v3.HttpFilter{}, opts.ingressPolicyFilters...), cfg.HttpFilters...)
+	}
+
 	if opts.protocol == "http2" || opts.protocol == "grpc" {
 		cfg.Http2ProtocolOptions = &envoy_core_v3.Http2ProtocolOptions{}
 	}
@@ -1517,8 +1874,11 @@ func makeHTTPFilter(opts listenerFilterOpts) (*envoy_listener_v3.Filter, error)
 	// (other than the "envoy.grpc_http1_bridge" filter) in the http filter
 	// chain of a public listener is the authz filter to prevent unauthorized
 	// access and that every filter chain uses our TLS certs.
-	if opts.httpAuthzFilter != nil {
-		cfg.HttpFilters = append([]*envoy_http_v3.HttpFilter{opts.httpAuthzFilter}, cfg.HttpFilters...)
+	if len(opts.httpAuthzFilters) > 0 || len(rateLimitFilters) > 0 {
+		filters := make([]*envoy_http_v3.HttpFilter, 0, len(opts.httpAuthzFilters)+len(rateLimitFilters)+len(cfg.HttpFilters))
+		filters = append(filters, opts.httpAuthzFilters...)
+		filters = append(filters, rateLimitFilters...)
+		cfg.HttpFilters = append(filters, cfg.HttpFilters...)
 	}
 
 	if opts.protocol == "grpc" {
@@ -1633,11 +1993,63 @@ func makeCommonTLSContextFromLeaf(
 						InlineString: rootPEMS,
 					},
 				},
+				Crl: makeRevocationListDataSource(cfgSnap.Roots),
 			},
 		},
 	}
 }
 
+// appendPeerTrustedCAs adds the roots of the cluster peers a service is
+// exported to, so that their proxies can connect to it. Peers do not publish
+// revocation lists, and Envoy rejects certificates whose CA has none once
+// lists are configured, so the lists of the local CA are dropped.
+func appendPeerTrustedCAs(tlsContext *envoy_tls_v3.CommonTlsContext, peerMeshes map[string]*structs.PeeringMeshInfo) {
+	if tlsContext == nil || len(peerMeshes) == 0 {
+		return
+	}
+	vc, ok := tlsContext.ValidationContextType.(*envoy_tls_v3.CommonTlsContext_ValidationContext)
+	if !ok || vc.ValidationContext.TrustedCa == nil {
+		return
+	}
+	src, ok := vc.ValidationContext.TrustedCa.Specifier.(*envoy_core_v3.DataSource_InlineString)
+	if !ok {
+		return
+	}
+
+	peers := make([]string, 0, len(peerMeshes))
+	for peer := range peerMeshes {
+		peers = append(peers, peer)
+	}
+	sort.Strings(peers)
+
+	for _, peer := range peers {
+		for _, root := range peerMeshes[peer].RootPEMs {
+			src.InlineString += ca.EnsureTrailingNewline(root)
+		}
+	}
+	vc.ValidationContext.Crl = nil
+}
+
+// makeRevocationListDataSource returns the concatenated revocation lists of
+// every CA in the federation, or nil if no certificate is revoked by serial
+// number. Envoy requires a list for each CA in the chain once one is set, so
+// the lists are only configured while they are needed.
+func makeRevocationListDataSource(roots *structs.IndexedCARoots) *envoy_core_v3.DataSource {
+	if len(roots.RevocationLists) == 0 {
+		return nil
+	}
+
+	crlPEMs := ""
+	for _, crl := range roots.RevocationLists {
+		crlPEMs += ca.EnsureTrailingNewline(crl)
+	}
+	return &envoy_core_v3.DataSource{
+		Specifier: &envoy_core_v3.DataSource_InlineString{
+			InlineString: crlPEMs,
+		},
+	}
+}
+
 func makeDownstreamTLSTransportSocket(tlsContext *envoy_tls_v3.DownstreamTlsContext) (*envoy_core_v3.TransportSocket, error) {
 	if tlsContext == nil {
 		return nil, nil
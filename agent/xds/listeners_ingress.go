@@ -5,6 +5,9 @@ import (
 
 	envoy_core_v3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
 	envoy_listener_v3 "github.com/envoyproxy/go-control-plane/envoy/config/listener/v3"
+	envoy_http_buffer_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/buffer/v3"
+	envoy_http_cors_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/cors/v3"
+	envoy_http_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/http_connection_manager/v3"
 	envoy_tls_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/transport_sockets/tls/v3"
 
 	"github.com/golang/protobuf/proto"
@@ -30,7 +33,16 @@ func (s *ResourceGenerator) makeIngressGatewayListeners(address string, cfgSnap
 			return nil, err
 		}
 
-		if listenerKey.Protocol == "tcp" {
+		if listenerKey.Protocol == "tcp" && ingressListenerRoutesBySNI(listenerCfg) {
+			listener, err := s.makeIngressSNIListener(address, cfgSnap, listenerCfg, upstreams, tlsContext)
+			if err != nil {
+				return nil, err
+			}
+			if listener != nil {
+				resources = append(resources, listener)
+			}
+
+		} else if listenerKey.Protocol == "tcp" {
 			// We rely on the invariant of upstreams slice always having at least 1
 			// member, because this key/value pair is created only when a
 			// GatewayService is returned in the RPC
@@ -71,6 +83,9 @@ func (s *ResourceGenerator) makeIngressGatewayListeners(address string, cfgSnap
 				filterName:  filterName,
 				protocol:    cfg.Protocol,
 				tlsContext:  tlsContext,
+
+				tcpIdleTimeoutMs: tcpIdleTimeoutMs(cfg.ConnectionPool),
+				faultInjection:   useRDS && chainUsesFaultInjection(chain),
 			})
 			if err != nil {
 				return nil, err
@@ -84,14 +99,29 @@ func (s *ResourceGenerator) makeIngressGatewayListeners(address string, cfgSnap
 			// If multiple upstreams share this port, make a special listener for the protocol.
 			listener := makePortListener(listenerKey.Protocol, address, listenerKey.Port, envoy_core_v3.TrafficDirection_OUTBOUND)
 			opts := listenerFilterOpts{
-				useRDS:          true,
-				protocol:        listenerKey.Protocol,
-				filterName:      listenerKey.RouteName(),
-				routeName:       listenerKey.RouteName(),
-				cluster:         "",
-				statPrefix:      "ingress_upstream_",
-				routePath:       "",
-				httpAuthzFilter: nil,
+				useRDS:           true,
+				protocol:         listenerKey.Protocol,
+				filterName:       listenerKey.RouteName(),
+				routeName:        listenerKey.RouteName(),
+				cluster:          "",
+				statPrefix:       "ingress_upstream_",
+				routePath:        "",
+				httpAuthzFilters: nil,
+			}
+
+			policyFilters, err := makeIngressPolicyHTTPFilters(cfgSnap.IngressGateway.JWTProviders, listenerCfg)
+			if err != nil {
+				return nil, err
+			}
+			opts.ingressPolicyFilters = policyFilters
+
+			// All services on the listener share its HTTP filters, so add the
+			// fault filter if any of their routes inject faults.
+			for _, u := range upstreams {
+				if chainUsesFaultInjection(cfgSnap.IngressGateway.DiscoveryChain[proxycfg.NewUpstreamID(&u)]) {
+					opts.faultInjection = true
+					break
+				}
 			}
 
 			// Generate any filter chains needed for services with custom TLS certs
@@ -140,6 +170,104 @@ func (s *ResourceGenerator) makeIngressGatewayListeners(address string, cfgSnap
 	return resources, nil
 }
 
+// ingressListenerRoutesBySNI returns true if connections to the tcp listener
+// are routed to its services by matching their SNI against the services'
+// hosts.
+func ingressListenerRoutesBySNI(listenerCfg structs.IngressListener) bool {
+	if listenerCfg.TLSPassthrough {
+		return true
+	}
+	for _, svc := range listenerCfg.Services {
+		if len(svc.Hosts) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// makeIngressSNIListener makes a tcp listener with a filter chain per service,
+// matching the SNI of connections against the hosts of the service. TLS is
+// either terminated, with the listener's certificate or the service's own SDS
+// certificate, or passed through to the service as is.
+func (s *ResourceGenerator) makeIngressSNIListener(
+	address string,
+	cfgSnap *proxycfg.ConfigSnapshot,
+	listenerCfg structs.IngressListener,
+	upstreams []structs.Upstream,
+	tlsContext *envoy_tls_v3.DownstreamTlsContext,
+) (*envoy_listener_v3.Listener, error) {
+	if listenerCfg.TLSPassthrough {
+		tlsContext = nil
+	}
+
+	serviceTLS := make(map[structs.ServiceName]*structs.GatewayServiceTLSConfig)
+	for _, svc := range listenerCfg.Services {
+		if ingressServiceHasSDSOverrides(svc) {
+			serviceTLS[svc.ToServiceName()] = svc.TLS
+		}
+	}
+
+	listener := makePortListener(listenerCfg.Protocol, address, listenerCfg.Port, envoy_core_v3.TrafficDirection_OUTBOUND)
+
+	for _, u := range upstreams {
+		if len(u.IngressHosts) == 0 {
+			// Shouldn't be possible with validation but be careful.
+			continue
+		}
+
+		uid := proxycfg.NewUpstreamID(&u)
+
+		chain := cfgSnap.IngressGateway.DiscoveryChain[uid]
+		if chain == nil {
+			// Wait until a chain is present in the snapshot.
+			continue
+		}
+
+		cfg := s.getAndModifyUpstreamConfigForListener(uid, &u, chain)
+
+		target, err := simpleChainTarget(chain)
+		if err != nil {
+			return nil, err
+		}
+
+		chainTLSContext := tlsContext
+		sn := structs.NewServiceName(u.DestinationName, u.GetEnterpriseMeta())
+		if svcTLS, ok := serviceTLS[sn]; ok && tlsContext != nil {
+			chainTLSContext = &envoy_tls_v3.DownstreamTlsContext{
+				CommonTlsContext:         makeCommonTLSContextFromGatewayServiceTLSConfig(*svcTLS),
+				RequireClientCertificate: &wrappers.BoolValue{Value: false},
+			}
+		}
+
+		filterChain, err := s.makeUpstreamFilterChain(filterChainOpts{
+			clusterName: CustomizeClusterName(target.Name, chain),
+			filterName:  fmt.Sprintf("%s.%s.%s.%s", chain.ServiceName, chain.Namespace, chain.Partition, chain.Datacenter),
+			protocol:    cfg.Protocol,
+			tlsContext:  chainTLSContext,
+
+			tcpIdleTimeoutMs: tcpIdleTimeoutMs(cfg.ConnectionPool),
+		})
+		if err != nil {
+			return nil, err
+		}
+		filterChain.FilterChainMatch = makeSNIFilterChainMatch(u.IngressHosts...)
+
+		listener.FilterChains = append(listener.FilterChains, filterChain)
+	}
+
+	if len(listener.FilterChains) == 0 {
+		return nil, nil
+	}
+
+	tlsInspector, err := makeTLSInspectorListenerFilter()
+	if err != nil {
+		return nil, err
+	}
+	listener.ListenerFilters = []*envoy_listener_v3.ListenerFilter{tlsInspector}
+
+	return listener, nil
+}
+
 func makeDownstreamTLSContextFromSnapshotListenerConfig(cfgSnap *proxycfg.ConfigSnapshot, listenerCfg structs.IngressListener) (*envoy_tls_v3.DownstreamTlsContext, error) {
 	var downstreamContext *envoy_tls_v3.DownstreamTlsContext
 
@@ -269,16 +397,21 @@ func routeNameForUpstream(l structs.IngressListener, s structs.IngressService) s
 
 	// Return a specific route for this service as it needs a custom FilterChain
 	// to serve its custom cert so we should attach its routes to a separate Route
-	// too. We need this to be consistent between OSS and Enterprise to avoid xDS
-	// config golden files in tests conflicting so we can't use ServiceID.String()
-	// which normalizes to included all identifiers in Enterprise.
+	// too.
+	return fmt.Sprintf("%s_%s", key.RouteName(), ingressServiceIdentifier(s))
+}
+
+// ingressServiceIdentifier names an ingress service in generated resources.
+// We need this to be consistent between OSS and Enterprise to avoid xDS config
+// golden files in tests conflicting so we can't use ServiceID.String() which
+// normalizes to included all identifiers in Enterprise.
+func ingressServiceIdentifier(s structs.IngressService) string {
 	sn := s.ToServiceName()
-	svcIdentifier := sn.Name
 	if !sn.InDefaultPartition() || !sn.InDefaultNamespace() {
 		// Non-default partition/namespace, use a full identifier
-		svcIdentifier = sn.String()
+		return sn.String()
 	}
-	return fmt.Sprintf("%s_%s", key.RouteName(), svcIdentifier)
+	return sn.Name
 }
 
 func ingressServiceHasSDSOverrides(s structs.IngressService) bool {
@@ -395,3 +528,60 @@ func makeTLSCertificateSdsSecretConfigsFromSDS(sdsCfg structs.GatewayTLSSDSConfi
 		},
 	}
 }
+
+// makeIngressPolicyHTTPFilters returns the HTTP filters enforcing the CORS
+// policies, request size limits and JWT requirements of the services of an
+// ingress listener. Each service configures them on its virtual host, see
+// injectIngressPoliciesToVirtualHost.
+func makeIngressPolicyHTTPFilters(
+	providers map[string]*structs.JWTProviderConfigEntry,
+	listener structs.IngressListener,
+) ([]*envoy_http_v3.HttpFilter, error) {
+	var filters []*envoy_http_v3.HttpFilter
+
+	// The CORS filter goes first so that preflight requests are answered
+	// without requiring a token.
+	for _, svc := range listener.Services {
+		if svc.CORS == nil {
+			continue
+		}
+		cors, err := makeEnvoyHTTPFilter(corsHTTPFilterName, &envoy_http_cors_v3.Cors{})
+		if err != nil {
+			return nil, err
+		}
+		filters = append(filters, cors)
+		break
+	}
+
+	jwtAuthn, err := makeIngressJWTAuthnHTTPFilter(providers, listener)
+	if err != nil {
+		return nil, err
+	}
+	if jwtAuthn != nil {
+		filters = append(filters, jwtAuthn)
+	}
+
+	if maxBytes := ingressListenerMaxRequestBytes(listener); maxBytes > 0 {
+		buffer, err := makeEnvoyHTTPFilter(bufferHTTPFilterName, &envoy_http_buffer_v3.Buffer{
+			MaxRequestBytes: &wrappers.UInt32Value{Value: maxBytes},
+		})
+		if err != nil {
+			return nil, err
+		}
+		filters = append(filters, buffer)
+	}
+
+	return filters, nil
+}
+
+// ingressListenerMaxRequestBytes returns the largest request size limit of the
+// services of the listener, or zero if none of them has one.
+func ingressListenerMaxRequestBytes(listener structs.IngressListener) uint32 {
+	var maxBytes uint32
+	for _, svc := range listener.Services {
+		if svc.MaxRequestBytes > maxBytes {
+			maxBytes = svc.MaxRequestBytes
+		}
+	}
+	return maxBytes
+}
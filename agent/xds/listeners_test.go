@@ -43,6 +43,12 @@ func TestListenersFromSnapshot(t *testing.T) {
 				return proxycfg.TestConfigSnapshot(t, nil, nil)
 			},
 		},
+		{
+			name: "connect-proxy-with-peered-upstreams",
+			create: func(t testinf.T) *proxycfg.ConfigSnapshot {
+				return proxycfg.TestConfigSnapshotPeering(t)
+			},
+		},
 		{
 			name: "connect-proxy-with-tls-outgoing-min-version-auto",
 			create: func(t testinf.T) *proxycfg.ConfigSnapshot {
@@ -184,6 +190,345 @@ func TestListenersFromSnapshot(t *testing.T) {
 				}, nil)
 			},
 		},
+		{
+			name: "tcp-public-listener-with-rate-limit",
+			create: func(t testinf.T) *proxycfg.ConfigSnapshot {
+				return proxycfg.TestConfigSnapshot(t, func(ns *structs.NodeService) {
+					ns.Proxy.Config["rate_limit"] = map[string]interface{}{
+						"requests_per_second": 100,
+					}
+				}, nil)
+			},
+		},
+		{
+			name: "http-public-listener-with-rate-limit",
+			create: func(t testinf.T) *proxycfg.ConfigSnapshot {
+				return proxycfg.TestConfigSnapshot(t, func(ns *structs.NodeService) {
+					ns.Proxy.Config["protocol"] = "http"
+					ns.Proxy.Config["local_request_timeout_ms"] = 2345
+					ns.Proxy.Config["rate_limit"] = map[string]interface{}{
+						"requests_per_second": 500,
+						"burst":               1000,
+						"routes": []interface{}{
+							map[string]interface{}{
+								"path_prefix":         "/admin",
+								"requests_per_second": 10,
+							},
+							map[string]interface{}{
+								"path_prefix":         "/admin/reports",
+								"requests_per_second": 1,
+							},
+						},
+						"sources": []interface{}{
+							map[string]interface{}{
+								"name":                "web",
+								"requests_per_second": 50,
+								"burst":               60,
+							},
+						},
+					}
+				}, nil)
+			},
+		},
+		{
+			name: "http-public-listener-with-ext-authz-and-rate-limit",
+			create: func(t testinf.T) *proxycfg.ConfigSnapshot {
+				// Only requests allowed by the authz filters are rate limited.
+				return proxycfg.TestConfigSnapshot(t, func(ns *structs.NodeService) {
+					ns.Proxy.Config["protocol"] = "http"
+					ns.Proxy.Config["ext_authz"] = map[string]interface{}{
+						"service":  "db",
+						"protocol": "http",
+					}
+					ns.Proxy.Config["rate_limit"] = map[string]interface{}{
+						"requests_per_second": 500,
+						"sources": []interface{}{
+							map[string]interface{}{
+								"name":                "web",
+								"requests_per_second": 50,
+							},
+						},
+					}
+				}, nil)
+			},
+		},
+		{
+			name: "http-public-listener-with-jwt-intentions",
+			create: func(t testinf.T) *proxycfg.ConfigSnapshot {
+				ixn := structs.TestIntention(t)
+				ixn.SourceName = "web"
+				ixn.DestinationName = "api"
+				ixn.Action = ""
+				ixn.Permissions = []*structs.IntentionPermission{
+					{
+						Action: structs.IntentionActionAllow,
+						JWT: &structs.IntentionJWTRequirement{
+							Providers: []*structs.IntentionJWTProvider{
+								{
+									Name: "okta",
+									VerifyClaims: []*structs.IntentionJWTClaimVerification{
+										{Path: []string{"role"}, Value: "admin"},
+									},
+								},
+								// Not defined, so left out of the jwt_authn filter.
+								{Name: "missing"},
+							},
+						},
+					},
+				}
+				//nolint:staticcheck
+				ixn.UpdatePrecedence()
+
+				return proxycfg.TestConfigSnapshot(t, func(ns *structs.NodeService) {
+					ns.Proxy.Config["protocol"] = "http"
+				}, []cache.UpdateEvent{
+					{
+						CorrelationID: "intentions",
+						Result: &structs.IndexedIntentionMatches{
+							Matches: []structs.Intentions{{ixn}},
+						},
+					},
+					{
+						CorrelationID: "jwt-providers",
+						Result: &structs.IndexedConfigEntries{
+							Kind: structs.JWTProvider,
+							Entries: []structs.ConfigEntry{
+								&structs.JWTProviderConfigEntry{
+									Name:      "okta",
+									Issuer:    "https://example.okta.com",
+									Audiences: []string{"api"},
+									JSONWebKeySet: &structs.JSONWebKeySet{
+										Local: &structs.LocalJWKS{
+											Filename: "/etc/consul/okta-jwks.json",
+										},
+									},
+									ClockSkewSeconds: 30,
+								},
+							},
+						},
+					},
+				})
+			},
+		},
+		{
+			name: "connection-pool-idle-timeouts",
+			create: func(t testinf.T) *proxycfg.ConfigSnapshot {
+				return proxycfg.TestConfigSnapshot(t, func(ns *structs.NodeService) {
+					ns.Proxy.Config["connection_pool"] = map[string]interface{}{
+						"tcp_idle_timeout_ms": 7200000,
+					}
+					for i := range ns.Proxy.Upstreams {
+						if ns.Proxy.Upstreams[i].Config == nil {
+							ns.Proxy.Upstreams[i].Config = map[string]interface{}{}
+						}
+						ns.Proxy.Upstreams[i].Config["connection_pool"] = map[string]interface{}{
+							"tcp_idle_timeout_ms": 0,
+						}
+					}
+				}, nil)
+			},
+		},
+		{
+			name: "tcp-public-listener-permissive-mtls",
+			create: func(t testinf.T) *proxycfg.ConfigSnapshot {
+				return proxycfg.TestConfigSnapshot(t, func(ns *structs.NodeService) {
+					ns.Proxy.Config["mutual_tls_mode"] = "permissive"
+					ns.Proxy.Config["permissive_source_cidrs"] = []string{"10.0.0.0/16", "2001:db8::/32"}
+				}, nil)
+			},
+		},
+		{
+			name: "http-public-listener-permissive-mtls",
+			create: func(t testinf.T) *proxycfg.ConfigSnapshot {
+				return proxycfg.TestConfigSnapshot(t, func(ns *structs.NodeService) {
+					ns.Proxy.Config["protocol"] = "http"
+					ns.Proxy.Config["mutual_tls_mode"] = "permissive"
+					ns.Proxy.Config["permissive_source_cidrs"] = []string{"10.0.0.0/16"}
+				}, nil)
+			},
+		},
+		{
+			name: "tcp-public-listener-permissive-mtls-with-intentions",
+			create: func(t testinf.T) *proxycfg.ConfigSnapshot {
+				// Plaintext connections are only matched by the wildcard
+				// intention, on their source address.
+				web := structs.TestIntention(t)
+				web.SourceName = "web"
+				web.DestinationName = "db"
+				web.Action = structs.IntentionActionDeny
+				//nolint:staticcheck
+				web.UpdatePrecedence()
+				wildcard := structs.TestIntention(t)
+				wildcard.SourceName = "*"
+				wildcard.DestinationName = "db"
+				wildcard.Action = structs.IntentionActionAllow
+				//nolint:staticcheck
+				wildcard.UpdatePrecedence()
+
+				return proxycfg.TestConfigSnapshot(t, func(ns *structs.NodeService) {
+					ns.Proxy.Config["mutual_tls_mode"] = "permissive"
+					ns.Proxy.Config["permissive_source_cidrs"] = []string{"10.0.0.0/16"}
+				}, []cache.UpdateEvent{
+					{
+						CorrelationID: "intentions",
+						Result: &structs.IndexedIntentionMatches{
+							Matches: []structs.Intentions{{web, wildcard}},
+						},
+					},
+				})
+			},
+		},
+		{
+			name: "tcp-public-listener-permissive-mtls-without-source-cidrs",
+			create: func(t testinf.T) *proxycfg.ConfigSnapshot {
+				return proxycfg.TestConfigSnapshot(t, func(ns *structs.NodeService) {
+					ns.Proxy.Config["mutual_tls_mode"] = "permissive"
+				}, nil)
+			},
+		},
+		{
+			name: "http-public-listener-with-ext-authz",
+			create: func(t testinf.T) *proxycfg.ConfigSnapshot {
+				return proxycfg.TestConfigSnapshot(t, func(ns *structs.NodeService) {
+					ns.Proxy.Config["protocol"] = "http"
+					ns.Proxy.Config["ext_authz"] = map[string]interface{}{
+						"service":            "db",
+						"protocol":           "http",
+						"path_prefix":        "/authz",
+						"timeout_ms":         500,
+						"failure_mode_allow": true,
+					}
+				}, nil)
+			},
+		},
+		{
+			name: "connect-proxy-with-tcp-ext-authz",
+			create: func(t testinf.T) *proxycfg.ConfigSnapshot {
+				return proxycfg.TestConfigSnapshot(t, func(ns *structs.NodeService) {
+					ns.Proxy.Config["ext_authz"] = map[string]interface{}{
+						"service": "db",
+					}
+				}, nil)
+			},
+			setup: func(snap *proxycfg.ConfigSnapshot) {
+				// gRPC authorization services need an HTTP/2 upstream.
+				for _, chain := range snap.ConnectProxy.DiscoveryChain {
+					if chain.ServiceName == "db" {
+						chain.Protocol = "grpc"
+					}
+				}
+			},
+		},
+		{
+			name: "http-public-listener-with-ext-authz-not-upstream",
+			create: func(t testinf.T) *proxycfg.ConfigSnapshot {
+				// Requests are rejected rather than the listeners left out.
+				return proxycfg.TestConfigSnapshot(t, func(ns *structs.NodeService) {
+					ns.Proxy.Config["protocol"] = "http"
+					ns.Proxy.Config["ext_authz"] = map[string]interface{}{
+						"service":  "authz",
+						"protocol": "http",
+					}
+				}, nil)
+			},
+			setup: func(snap *proxycfg.ConfigSnapshot) {
+				snap.IntentionDefaultAllow = true
+			},
+		},
+		{
+			name: "connect-proxy-with-tcp-ext-authz-not-upstream",
+			create: func(t testinf.T) *proxycfg.ConfigSnapshot {
+				// Connections are rejected rather than the listeners left out.
+				return proxycfg.TestConfigSnapshot(t, func(ns *structs.NodeService) {
+					ns.Proxy.Config["ext_authz"] = map[string]interface{}{
+						"service": "authz",
+					}
+				}, nil)
+			},
+			setup: func(snap *proxycfg.ConfigSnapshot) {
+				snap.IntentionDefaultAllow = true
+			},
+		},
+		{
+			name: "http-public-listener-with-ext-authz-not-upstream-failure-mode-allow",
+			create: func(t testinf.T) *proxycfg.ConfigSnapshot {
+				// The filter is left out rather than requests rejected.
+				return proxycfg.TestConfigSnapshot(t, func(ns *structs.NodeService) {
+					ns.Proxy.Config["protocol"] = "http"
+					ns.Proxy.Config["ext_authz"] = map[string]interface{}{
+						"service":            "authz",
+						"protocol":           "http",
+						"failure_mode_allow": true,
+					}
+				}, nil)
+			},
+			setup: func(snap *proxycfg.ConfigSnapshot) {
+				snap.IntentionDefaultAllow = true
+			},
+		},
+		{
+			name: "connect-proxy-with-tcp-ext-authz-not-upstream-failure-mode-allow",
+			create: func(t testinf.T) *proxycfg.ConfigSnapshot {
+				// The filter is left out rather than connections rejected.
+				return proxycfg.TestConfigSnapshot(t, func(ns *structs.NodeService) {
+					ns.Proxy.Config["ext_authz"] = map[string]interface{}{
+						"service":            "authz",
+						"failure_mode_allow": true,
+					}
+				}, nil)
+			},
+			setup: func(snap *proxycfg.ConfigSnapshot) {
+				snap.IntentionDefaultAllow = true
+			},
+		},
+		{
+			name: "connect-proxy-with-access-logs",
+			create: func(t testinf.T) *proxycfg.ConfigSnapshot {
+				return proxycfg.TestConfigSnapshot(t, func(ns *structs.NodeService) {
+					ns.Proxy.Config["protocol"] = "http"
+					ns.Proxy.Config["access_logs"] = map[string]interface{}{
+						"enabled":     true,
+						"type":        "file",
+						"path":        "/var/log/envoy/access.log",
+						"json_format": `{"method": "%REQ(:METHOD)%", "status": "%RESPONSE_CODE%"}`,
+					}
+				}, nil)
+			},
+		},
+		{
+			name: "connect-proxy-with-tracing",
+			create: func(t testinf.T) *proxycfg.ConfigSnapshot {
+				return proxycfg.TestConfigSnapshot(t, func(ns *structs.NodeService) {
+					ns.Proxy.Config["protocol"] = "http"
+					ns.Proxy.Config["tracing"] = map[string]interface{}{
+						"Provider": "zipkin",
+						"Collector": map[string]interface{}{
+							"Address": "zipkin.service.consul",
+							"Port":    9411,
+						},
+						"SamplingPercentage": 12.5,
+						"CustomTags": map[string]interface{}{
+							"env":    "prod",
+							"region": "us-east-1",
+						},
+					}
+				}, nil)
+			},
+		},
+		{
+			name: "connect-proxy-with-revocations",
+			create: func(t testinf.T) *proxycfg.ConfigSnapshot {
+				return proxycfg.TestConfigSnapshot(t, nil, nil)
+			},
+			setup: func(snap *proxycfg.ConfigSnapshot) {
+				snap.Roots.RevokedSpiffeIDs = []string{
+					"spiffe://11111111-2222-3333-4444-555555555555.consul/ns/default/dc/dc1/svc/web",
+				}
+				snap.Roots.RevocationLists = []string{
+					"-----BEGIN X509 CRL-----\nMIIBOTCB4AIBATAKBggqhkjOPQQDAjAWMRQwEgYDVQQDEwtDb25zdWwgQ0EgNxcN\n-----END X509 CRL-----\n",
+				}
+			},
+		},
 		{
 			name: "custom-public-listener",
 			create: func(t testinf.T) *proxycfg.ConfigSnapshot {
@@ -344,6 +689,12 @@ func TestListenersFromSnapshot(t *testing.T) {
 				)
 			},
 		},
+		{
+			name: "connect-proxy-with-fault-injection-and-mirror",
+			create: func(t testinf.T) *proxycfg.ConfigSnapshot {
+				return proxycfg.TestConfigSnapshotDiscoveryChain(t, "router-with-fault-injection-and-mirror", nil, nil)
+			},
+		},
 		{
 			name: "connect-proxy-with-chain-external-sni",
 			create: func(t testinf.T) *proxycfg.ConfigSnapshot {
@@ -444,6 +795,21 @@ func TestListenersFromSnapshot(t *testing.T) {
 				return proxycfg.TestConfigSnapshotMeshGateway(t, "default", nil, nil)
 			},
 		},
+		{
+			name: "mesh-gateway-with-access-logs",
+			create: func(t testinf.T) *proxycfg.ConfigSnapshot {
+				return proxycfg.TestConfigSnapshotMeshGateway(t, "default", func(ns *structs.NodeService) {
+					ns.Proxy.Config = map[string]interface{}{
+						"access_logs": map[string]interface{}{
+							"enabled":               true,
+							"disable_listener_logs": true,
+							"type":                  "stderr",
+							"text_format":           "[%START_TIME%] %UPSTREAM_HOST% %BYTES_SENT%\n",
+						},
+					}
+				}, nil)
+			},
+		},
 		{
 			name: "mesh-gateway-using-federation-states",
 			create: func(t testinf.T) *proxycfg.ConfigSnapshot {
@@ -496,6 +862,18 @@ func TestListenersFromSnapshot(t *testing.T) {
 				return proxycfg.TestConfigSnapshotIngressGateway(t, true, "tcp", "default", nil, nil, nil)
 			},
 		},
+		{
+			name: "ingress-gateway-with-access-logs",
+			create: func(t testinf.T) *proxycfg.ConfigSnapshot {
+				return proxycfg.TestConfigSnapshotIngressGateway(t, true, "tcp", "default", func(ns *structs.NodeService) {
+					ns.Proxy.Config = map[string]interface{}{
+						"access_logs": map[string]interface{}{
+							"enabled": true,
+						},
+					}
+				}, nil, nil)
+			},
+		},
 		{
 			name: "ingress-gateway-bind-addrs",
 			create: func(t testinf.T) *proxycfg.ConfigSnapshot {
@@ -556,6 +934,21 @@ func TestListenersFromSnapshot(t *testing.T) {
 				return proxycfg.TestConfigSnapshotTerminatingGateway(t, true, nil, nil)
 			},
 		},
+		{
+			name: "terminating-gateway-with-access-logs",
+			create: func(t testinf.T) *proxycfg.ConfigSnapshot {
+				return proxycfg.TestConfigSnapshotTerminatingGateway(t, true, func(ns *structs.NodeService) {
+					ns.Proxy.Config = map[string]interface{}{
+						"access_logs": map[string]interface{}{
+							"enabled":     true,
+							"type":        "file",
+							"path":        "/var/log/envoy/terminating-gateway.log",
+							"json_format": `{"upstream": "%UPSTREAM_HOST%", "sni": "%REQUESTED_SERVER_NAME%"}`,
+						},
+					}
+				}, nil)
+			},
+		},
 		{
 			name: "terminating-gateway-with-tls-incoming-min-version",
 			create: func(t testinf.T) *proxycfg.ConfigSnapshot {
@@ -622,6 +1015,10 @@ func TestListenersFromSnapshot(t *testing.T) {
 				return proxycfg.TestConfigSnapshotTerminatingGateway(t, false, nil, nil)
 			},
 		},
+		{
+			name:   "terminating-gateway-destinations",
+			create: proxycfg.TestConfigSnapshotTerminatingGatewayDestinations,
+		},
 		{
 			name: "terminating-gateway-custom-and-tagged-addresses",
 			create: func(t testinf.T) *proxycfg.ConfigSnapshot {
@@ -652,6 +1049,10 @@ func TestListenersFromSnapshot(t *testing.T) {
 			name:   "ingress-http-multiple-services",
 			create: proxycfg.TestConfigSnapshotIngress_HTTPMultipleServices,
 		},
+		{
+			name:   "ingress-http-with-cors-size-limit-and-jwt",
+			create: proxycfg.TestConfigSnapshotIngressGateway_HTTPPolicies,
+		},
 		{
 			name: "terminating-gateway-no-api-cert",
 			create: func(t testinf.T) *proxycfg.ConfigSnapshot {
@@ -722,6 +1123,30 @@ func TestListenersFromSnapshot(t *testing.T) {
 			name:   "ingress-with-tls-mixed-min-version-listeners",
 			create: proxycfg.TestConfigSnapshotIngressGateway_TLSMixedMinVersionListeners,
 		},
+		{
+			name:   "ingress-with-tcp-sni-listener",
+			create: proxycfg.TestConfigSnapshotIngressGateway_TCPSNI,
+		},
+		{
+			name:   "ingress-with-tcp-sni-listener-upstream-override",
+			create: proxycfg.TestConfigSnapshotIngressGateway_TCPSNI,
+			setup: func(snap *proxycfg.ConfigSnapshot) {
+				for _, upstreams := range snap.IngressGateway.Upstreams {
+					for i := range upstreams {
+						if upstreams[i].DestinationName != "s1" {
+							continue
+						}
+						upstreams[i].Config["connection_pool"] = map[string]interface{}{
+							"tcp_idle_timeout_ms": 5000,
+						}
+					}
+				}
+			},
+		},
+		{
+			name:   "ingress-with-tcp-sni-passthrough-listener",
+			create: proxycfg.TestConfigSnapshotIngressGateway_TCPSNIPassthrough,
+		},
 		{
 			name:   "ingress-with-sds-listener-gw-level",
 			create: proxycfg.TestConfigSnapshotIngressGatewaySDS_GatewayLevel,
@@ -770,6 +1195,14 @@ func TestListenersFromSnapshot(t *testing.T) {
 			name:   "transparent-proxy-terminating-gateway",
 			create: proxycfg.TestConfigSnapshotTransparentProxyTerminatingGatewayCatalogDestinationsOnly,
 		},
+		{
+			name:   "transparent-proxy-destination",
+			create: proxycfg.TestConfigSnapshotTransparentProxyDestination,
+		},
+		{
+			name:   "transparent-proxy-destination-shared-address",
+			create: proxycfg.TestConfigSnapshotTransparentProxyDestinationSharedAddress,
+		},
 	}
 
 	latestEnvoyVersion := proxysupport.EnvoyVersions[0]
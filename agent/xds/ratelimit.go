@@ -0,0 +1,257 @@
+package xds
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	envoy_core_v3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	envoy_listener_v3 "github.com/envoyproxy/go-control-plane/envoy/config/listener/v3"
+	envoy_rbac_v3 "github.com/envoyproxy/go-control-plane/envoy/config/rbac/v3"
+	envoy_route_v3 "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
+	envoy_ratelimit_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/common/ratelimit/v3"
+	envoy_http_local_ratelimit_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/local_ratelimit/v3"
+	envoy_http_rbac_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/rbac/v3"
+	envoy_http_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/http_connection_manager/v3"
+	envoy_network_local_ratelimit_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/local_ratelimit/v3"
+	envoy_metadata_v3 "github.com/envoyproxy/go-control-plane/envoy/type/metadata/v3"
+	envoy_type_v3 "github.com/envoyproxy/go-control-plane/envoy/type/v3"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/golang/protobuf/ptypes/any"
+
+	"github.com/hashicorp/consul/agent/structs"
+)
+
+const (
+	localRateLimitHTTPFilterName    = "envoy.filters.http.local_ratelimit"
+	localRateLimitNetworkFilterName = "envoy.filters.network.local_ratelimit"
+	localRateLimitStatPrefix        = "local_rate_limit"
+
+	// rateLimitSourceDescriptorKey is the key of the descriptors identifying
+	// the source service of a request.
+	rateLimitSourceDescriptorKey = "source"
+
+	// The RBAC HTTP filter exposes the name of the shadow policy matching a
+	// request as dynamic metadata under its own name.
+	rbacHTTPFilterName             = "envoy.filters.http.rbac"
+	rbacShadowEffectivePolicyIDKey = "shadow_effective_policy_id"
+)
+
+// makeRateLimitNetworkFilter returns a local rate limit network filter that
+// limits the rate of new connections accepted by a TCP public listener. It
+// returns nil if no default limit is set.
+func makeRateLimitNetworkFilter(rl *structs.RateLimitConfig) (*envoy_listener_v3.Filter, error) {
+	if rl == nil || rl.RequestsPerSecond <= 0 {
+		return nil, nil
+	}
+
+	cfg := &envoy_network_local_ratelimit_v3.LocalRateLimit{
+		StatPrefix:  localRateLimitStatPrefix,
+		TokenBucket: makeTokenBucket(rl.RequestsPerSecond, rl.Burst),
+	}
+	return makeFilter(localRateLimitNetworkFilterName, cfg)
+}
+
+// makeRateLimitHTTPFilter returns the local rate limit HTTP filter enforcing
+// the default limit of an HTTP public listener. Limits for path prefixes are
+// configured on the routes returned by makeRateLimitRoutes.
+func makeRateLimitHTTPFilter(rl *structs.RateLimitConfig) (*envoy_http_v3.HttpFilter, error) {
+	return makeEnvoyHTTPFilter(localRateLimitHTTPFilterName, makeLocalRateLimit(rl, rl.RequestsPerSecond, rl.Burst))
+}
+
+// makeRateLimitSourceHTTPFilter returns an RBAC filter with a shadow policy
+// per source service with its own limit, matching the client certificate of
+// requests. It never rejects a request, it only exposes the name of the
+// matching policy to the local rate limit filter, which must come after it.
+// It returns nil if no source service is limited.
+func makeRateLimitSourceHTTPFilter(rl *structs.RateLimitConfig) (*envoy_http_v3.HttpFilter, error) {
+	if !hasRateLimitSources(rl) {
+		return nil, nil
+	}
+
+	shadow := &envoy_rbac_v3.RBAC{
+		Action:   envoy_rbac_v3.RBAC_ALLOW,
+		Policies: make(map[string]*envoy_rbac_v3.Policy),
+	}
+	for _, src := range rateLimitSources(rl.Sources) {
+		shadow.Policies[rateLimitSourceDescriptorValue(src)] = &envoy_rbac_v3.Policy{
+			Principals:  []*envoy_rbac_v3.Principal{idPrincipal(structs.NewServiceName(src.Name, &src.EnterpriseMeta))},
+			Permissions: []*envoy_rbac_v3.Permission{anyPermission()},
+		}
+	}
+	return makeEnvoyHTTPFilter(rbacHTTPFilterName, &envoy_http_rbac_v3.RBAC{
+		ShadowRules:           shadow,
+		ShadowRulesStatPrefix: localRateLimitStatPrefix + "_",
+	})
+}
+
+// makeRateLimitRoutes returns a copy of route for each path prefix with its
+// own limit, ordered so that the longest prefix matches first, followed by
+// route itself. Every route carries the action identifying the source
+// service of a request when per-source limits are configured.
+func makeRateLimitRoutes(route *envoy_route_v3.Route, rl *structs.RateLimitConfig) ([]*envoy_route_v3.Route, error) {
+	actions := makeRateLimitSourceActions(rl)
+
+	prefixes := make([]structs.RateLimitRoute, 0, len(rl.Routes))
+	for _, r := range rl.Routes {
+		if r.RequestsPerSecond <= 0 || !strings.HasPrefix(r.PathPrefix, "/") {
+			continue
+		}
+		prefixes = append(prefixes, r)
+	}
+	sort.SliceStable(prefixes, func(i, j int) bool {
+		return len(prefixes[i].PathPrefix) > len(prefixes[j].PathPrefix)
+	})
+
+	routes := make([]*envoy_route_v3.Route, 0, len(prefixes)+1)
+	for _, r := range prefixes {
+		cfg, err := ptypes.MarshalAny(makeLocalRateLimit(rl, r.RequestsPerSecond, r.Burst))
+		if err != nil {
+			return nil, err
+		}
+
+		prefixRoute := proto.Clone(route).(*envoy_route_v3.Route)
+		prefixRoute.Match.PathSpecifier = &envoy_route_v3.RouteMatch_Prefix{
+			Prefix: r.PathPrefix,
+		}
+		prefixRoute.TypedPerFilterConfig = map[string]*any.Any{
+			localRateLimitHTTPFilterName: cfg,
+		}
+		routes = append(routes, prefixRoute)
+	}
+	routes = append(routes, route)
+
+	if len(actions) > 0 {
+		for _, r := range routes {
+			r.GetRoute().RateLimits = actions
+		}
+	}
+	return routes, nil
+}
+
+// makeLocalRateLimit returns the local rate limit config enforcing the given
+// default limit, along with the per-source limits of rl. The rate limiter is
+// left disabled if rps is not set.
+func makeLocalRateLimit(rl *structs.RateLimitConfig, rps, burst int) *envoy_http_local_ratelimit_v3.LocalRateLimit {
+	cfg := &envoy_http_local_ratelimit_v3.LocalRateLimit{
+		StatPrefix: localRateLimitStatPrefix,
+	}
+	if rps <= 0 {
+		return cfg
+	}
+
+	cfg.TokenBucket = makeTokenBucket(rps, burst)
+	cfg.FilterEnabled = makeFullRuntimeFraction("local_rate_limit_enabled")
+	cfg.FilterEnforced = makeFullRuntimeFraction("local_rate_limit_enforced")
+
+	for _, src := range rateLimitSources(rl.Sources) {
+		cfg.Descriptors = append(cfg.Descriptors, &envoy_ratelimit_v3.LocalRateLimitDescriptor{
+			Entries: []*envoy_ratelimit_v3.RateLimitDescriptor_Entry{
+				{
+					Key:   rateLimitSourceDescriptorKey,
+					Value: rateLimitSourceDescriptorValue(src),
+				},
+			},
+			TokenBucket: makeTokenBucket(src.RequestsPerSecond, src.Burst),
+		})
+	}
+	return cfg
+}
+
+// makeRateLimitSourceActions returns the rate limit producing a descriptor
+// for the source service of a request, as identified by the filter returned
+// by makeRateLimitSourceHTTPFilter. Requests from other services get no such
+// descriptor and are only subject to the limit of the route.
+func makeRateLimitSourceActions(rl *structs.RateLimitConfig) []*envoy_route_v3.RateLimit {
+	if !hasRateLimitSources(rl) {
+		return nil
+	}
+	return []*envoy_route_v3.RateLimit{
+		{
+			Actions: []*envoy_route_v3.RateLimit_Action{
+				{
+					ActionSpecifier: &envoy_route_v3.RateLimit_Action_Metadata{
+						Metadata: &envoy_route_v3.RateLimit_Action_MetaData{
+							DescriptorKey: rateLimitSourceDescriptorKey,
+							MetadataKey: &envoy_metadata_v3.MetadataKey{
+								Key: rbacHTTPFilterName,
+								Path: []*envoy_metadata_v3.MetadataKey_PathSegment{
+									{
+										Segment: &envoy_metadata_v3.MetadataKey_PathSegment_Key{
+											Key: rbacShadowEffectivePolicyIDKey,
+										},
+									},
+								},
+							},
+							Source: envoy_route_v3.RateLimit_Action_MetaData_DYNAMIC,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// rateLimitSources returns the valid sources sorted by name so that the
+// generated config is stable.
+func rateLimitSources(sources []structs.RateLimitSource) []structs.RateLimitSource {
+	out := make([]structs.RateLimitSource, 0, len(sources))
+	for _, src := range sources {
+		if src.Name == "" || src.Name == structs.WildcardSpecifier || src.RequestsPerSecond <= 0 {
+			continue
+		}
+		out = append(out, src)
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		return rateLimitSourceDescriptorValue(out[i]) < rateLimitSourceDescriptorValue(out[j])
+	})
+	return out
+}
+
+func rateLimitSourceDescriptorValue(src structs.RateLimitSource) string {
+	return structs.NewServiceName(src.Name, &src.EnterpriseMeta).String()
+}
+
+func makeTokenBucket(rps, burst int) *envoy_type_v3.TokenBucket {
+	if burst < rps {
+		burst = rps
+	}
+	return &envoy_type_v3.TokenBucket{
+		MaxTokens:     uint32(burst),
+		TokensPerFill: makeUint32Value(rps),
+		FillInterval:  ptypes.DurationProto(time.Second),
+	}
+}
+
+func makeFullRuntimeFraction(runtimeKey string) *envoy_core_v3.RuntimeFractionalPercent {
+	return &envoy_core_v3.RuntimeFractionalPercent{
+		DefaultValue: &envoy_type_v3.FractionalPercent{
+			Numerator:   100,
+			Denominator: envoy_type_v3.FractionalPercent_HUNDRED,
+		},
+		RuntimeKey: runtimeKey,
+	}
+}
+
+// hasRateLimitSources reports whether rl limits any source service.
+func hasRateLimitSources(rl *structs.RateLimitConfig) bool {
+	return rl != nil && len(rateLimitSources(rl.Sources)) > 0
+}
+
+// isRateLimited reports whether rl configures any limit.
+func isRateLimited(rl *structs.RateLimitConfig) bool {
+	if rl == nil {
+		return false
+	}
+	if rl.RequestsPerSecond > 0 {
+		return true
+	}
+	for _, r := range rl.Routes {
+		if r.RequestsPerSecond > 0 {
+			return true
+		}
+	}
+	return false
+}
@@ -5,6 +5,7 @@ import (
 	"sort"
 	"strings"
 
+	envoy_core_v3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
 	envoy_listener_v3 "github.com/envoyproxy/go-control-plane/envoy/config/listener/v3"
 	envoy_rbac_v3 "github.com/envoyproxy/go-control-plane/envoy/config/rbac/v3"
 	envoy_route_v3 "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
@@ -18,7 +19,15 @@ import (
 )
 
 func makeRBACNetworkFilter(intentions structs.Intentions, intentionDefaultAllow bool) (*envoy_listener_v3.Filter, error) {
-	rules, err := makeRBACRules(intentions, intentionDefaultAllow, false)
+	return makePlaintextRBACNetworkFilter(intentions, intentionDefaultAllow, nil)
+}
+
+// makePlaintextRBACNetworkFilter is makeRBACNetworkFilter for connections
+// accepted without mutual TLS from the given source ranges, see
+// rbacIntention.FlattenPlaintextPrincipal. A nil list of ranges is the same as
+// calling makeRBACNetworkFilter.
+func makePlaintextRBACNetworkFilter(intentions structs.Intentions, intentionDefaultAllow bool, plaintextSources []*envoy_core_v3.CidrRange) (*envoy_listener_v3.Filter, error) {
+	rules, err := makeRBACRules(intentions, intentionDefaultAllow, false, plaintextSources)
 	if err != nil {
 		return nil, err
 	}
@@ -31,7 +40,13 @@ func makeRBACNetworkFilter(intentions structs.Intentions, intentionDefaultAllow
 }
 
 func makeRBACHTTPFilter(intentions structs.Intentions, intentionDefaultAllow bool) (*envoy_http_v3.HttpFilter, error) {
-	rules, err := makeRBACRules(intentions, intentionDefaultAllow, true)
+	return makePlaintextRBACHTTPFilter(intentions, intentionDefaultAllow, nil)
+}
+
+// makePlaintextRBACHTTPFilter is the HTTP filter counterpart of
+// makePlaintextRBACNetworkFilter.
+func makePlaintextRBACHTTPFilter(intentions structs.Intentions, intentionDefaultAllow bool, plaintextSources []*envoy_core_v3.CidrRange) (*envoy_http_v3.HttpFilter, error) {
+	rules, err := makeRBACRules(intentions, intentionDefaultAllow, true, plaintextSources)
 	if err != nil {
 		return nil, err
 	}
@@ -42,6 +57,75 @@ func makeRBACHTTPFilter(intentions structs.Intentions, intentionDefaultAllow boo
 	return makeEnvoyHTTPFilter("envoy.filters.http.rbac", cfg)
 }
 
+// makeDenyAllRBACNetworkFilter returns an RBAC network filter rejecting every
+// connection, as intentions would if none allowed anything.
+func makeDenyAllRBACNetworkFilter() (*envoy_listener_v3.Filter, error) {
+	return makeRBACNetworkFilter(nil, false)
+}
+
+// makeDenyAllRBACHTTPFilter is the HTTP filter counterpart of
+// makeDenyAllRBACNetworkFilter.
+func makeDenyAllRBACHTTPFilter() (*envoy_http_v3.HttpFilter, error) {
+	return makeRBACHTTPFilter(nil, false)
+}
+
+// makeRevocationRBACNetworkFilter returns an RBAC network filter that rejects
+// connections authenticated with the certificate of a revoked SPIFFE ID. It
+// returns nil if no identity is revoked.
+func makeRevocationRBACNetworkFilter(roots *structs.IndexedCARoots) (*envoy_listener_v3.Filter, error) {
+	if roots == nil || len(roots.RevokedSpiffeIDs) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]string, len(roots.RevokedSpiffeIDs))
+	copy(ids, roots.RevokedSpiffeIDs)
+	sort.Strings(ids)
+
+	principals := make([]*envoy_rbac_v3.Principal, 0, len(ids))
+	for _, raw := range ids {
+		uri, err := connect.ParseCertURIFromString(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid revoked SPIFFE ID %q: %v", raw, err)
+		}
+		svc, ok := uri.(*connect.SpiffeIDService)
+		if !ok {
+			return nil, fmt.Errorf("revoked SPIFFE ID %q does not identify a service", raw)
+		}
+
+		// Unlike intentions, revocations apply to the exact datacenter but
+		// still ignore the trust domain so they survive a trust domain change.
+		id := *svc
+		id.Host = `[^/]+`
+		pattern := fmt.Sprintf(`^%s://%s%s$`, id.URI().Scheme, id.Host, id.URI().Path)
+
+		principals = append(principals, &envoy_rbac_v3.Principal{
+			Identifier: &envoy_rbac_v3.Principal_Authenticated_{
+				Authenticated: &envoy_rbac_v3.Principal_Authenticated{
+					PrincipalName: &envoy_matcher_v3.StringMatcher{
+						MatchPattern: &envoy_matcher_v3.StringMatcher_SafeRegex{
+							SafeRegex: makeEnvoyRegexMatch(pattern),
+						},
+					},
+				},
+			},
+		})
+	}
+
+	cfg := &envoy_network_rbac_v3.RBAC{
+		StatPrefix: "connect_revocation",
+		Rules: &envoy_rbac_v3.RBAC{
+			Action: envoy_rbac_v3.RBAC_DENY,
+			Policies: map[string]*envoy_rbac_v3.Policy{
+				"consul-revoked-identities": {
+					Principals:  principals,
+					Permissions: []*envoy_rbac_v3.Permission{anyPermission()},
+				},
+			},
+		},
+	}
+	return makeFilter("envoy.filters.network.rbac", cfg)
+}
+
 func intentionListToIntermediateRBACForm(intentions structs.Intentions, isHTTP bool) []*rbacIntention {
 	sort.Sort(structs.IntentionPrecedenceSorter(intentions))
 
@@ -269,6 +353,37 @@ func (r *rbacIntention) FlattenPrincipal() *envoy_rbac_v3.Principal {
 	return andPrincipals(andIDs)
 }
 
+// FlattenPlaintextPrincipal is FlattenPrincipal for connections accepted
+// without mutual TLS from the given source ranges. They carry no identity, so
+// only a source matching any service name applies to them, and it matches on
+// the source address of the connection instead.
+func (r *rbacIntention) FlattenPlaintextPrincipal(plaintextSources []*envoy_core_v3.CidrRange) *envoy_rbac_v3.Principal {
+	if r.Source.Name != structs.WildcardSpecifier {
+		return notPrincipal(anyPrincipal())
+	}
+	for _, src := range simplifyNotSourceSlice(r.NotSources) {
+		if src.Name == structs.WildcardSpecifier {
+			return notPrincipal(anyPrincipal())
+		}
+	}
+
+	ids := make([]*envoy_rbac_v3.Principal, 0, len(plaintextSources))
+	for _, cidr := range plaintextSources {
+		ids = append(ids, &envoy_rbac_v3.Principal{
+			Identifier: &envoy_rbac_v3.Principal_DirectRemoteIp{
+				DirectRemoteIp: cidr,
+			},
+		})
+	}
+	return &envoy_rbac_v3.Principal{
+		Identifier: &envoy_rbac_v3.Principal_OrIds{
+			OrIds: &envoy_rbac_v3.Principal_Set{
+				Ids: ids,
+			},
+		},
+	}
+}
+
 type rbacPermission struct {
 	Definition *structs.IntentionPermission
 
@@ -380,7 +495,11 @@ func simplifyNotSourceSlice(notSources []structs.ServiceName) []structs.ServiceN
 //     <default>    : DENY
 //
 // Which really is just an allow-list of [A, C AND NOT(B)]
-func makeRBACRules(intentions structs.Intentions, intentionDefaultAllow bool, isHTTP bool) (*envoy_rbac_v3.RBAC, error) {
+//
+// If plaintextSources is not nil the rules are for connections accepted
+// without mutual TLS from those source ranges, see
+// rbacIntention.FlattenPlaintextPrincipal.
+func makeRBACRules(intentions structs.Intentions, intentionDefaultAllow bool, isHTTP bool, plaintextSources []*envoy_core_v3.CidrRange) (*envoy_rbac_v3.RBAC, error) {
 	// Note that we DON'T explicitly validate the trust-domain matches ours.
 	//
 	// For now we don't validate the trust domain of the _destination_ at all.
@@ -393,8 +512,6 @@ func makeRBACRules(intentions structs.Intentions, intentionDefaultAllow bool, is
 	// they want including not authorizing traffic at all or routing it do a
 	// different service than they auth'd against.
 
-	// TODO(banks,rb): Implement revocation list checking?
-
 	// First build up just the basic principal matches.
 	rbacIxns := intentionListToIntermediateRBACForm(intentions, isHTTP)
 
@@ -424,6 +541,9 @@ func makeRBACRules(intentions structs.Intentions, intentionDefaultAllow bool, is
 
 	var principalsL4 []*envoy_rbac_v3.Principal
 	for i, rbacIxn := range rbacIxns {
+		if plaintextSources != nil {
+			rbacIxn.ComputedPrincipal = rbacIxn.FlattenPlaintextPrincipal(plaintextSources)
+		}
 		if rbacIxn.Action == intentionActionLayer7 {
 			if len(rbacIxn.Permissions) == 0 {
 				panic("invalid state: L7 intention has no permissions")
@@ -556,6 +676,12 @@ func andPrincipals(ids []*envoy_rbac_v3.Principal) *envoy_rbac_v3.Principal {
 	}
 }
 
+func anyPrincipal() *envoy_rbac_v3.Principal {
+	return &envoy_rbac_v3.Principal{
+		Identifier: &envoy_rbac_v3.Principal_Any{Any: true},
+	}
+}
+
 func notPrincipal(id *envoy_rbac_v3.Principal) *envoy_rbac_v3.Principal {
 	return &envoy_rbac_v3.Principal{
 		Identifier: &envoy_rbac_v3.Principal_NotId{
@@ -624,49 +750,63 @@ func anyPermission() *envoy_rbac_v3.Permission {
 
 func convertPermission(perm *structs.IntentionPermission) *envoy_rbac_v3.Permission {
 	// NOTE: this does not do anything with perm.Action
-	if perm.HTTP == nil {
+	if perm.HTTP == nil && perm.JWT == nil {
 		return anyPermission()
 	}
 
+	var parts []*envoy_rbac_v3.Permission
+	if perm.HTTP != nil {
+		parts = convertHTTPPermission(perm.HTTP)
+	}
+	if perm.JWT != nil {
+		parts = append(parts, convertJWTRequirement(perm.JWT))
+	}
+
+	// NOTE: if for some reason we errantly allow a permission to be defined
+	// with a body of "http{}" then we'll end up treating that like "ANY" here.
+	return andPermissions(parts)
+}
+
+func convertHTTPPermission(http *structs.IntentionHTTPPermission) []*envoy_rbac_v3.Permission {
 	var parts []*envoy_rbac_v3.Permission
 
 	switch {
-	case perm.HTTP.PathExact != "":
+	case http.PathExact != "":
 		parts = append(parts, &envoy_rbac_v3.Permission{
 			Rule: &envoy_rbac_v3.Permission_UrlPath{
 				UrlPath: &envoy_matcher_v3.PathMatcher{
 					Rule: &envoy_matcher_v3.PathMatcher_Path{
 						Path: &envoy_matcher_v3.StringMatcher{
 							MatchPattern: &envoy_matcher_v3.StringMatcher_Exact{
-								Exact: perm.HTTP.PathExact,
+								Exact: http.PathExact,
 							},
 						},
 					},
 				},
 			},
 		})
-	case perm.HTTP.PathPrefix != "":
+	case http.PathPrefix != "":
 		parts = append(parts, &envoy_rbac_v3.Permission{
 			Rule: &envoy_rbac_v3.Permission_UrlPath{
 				UrlPath: &envoy_matcher_v3.PathMatcher{
 					Rule: &envoy_matcher_v3.PathMatcher_Path{
 						Path: &envoy_matcher_v3.StringMatcher{
 							MatchPattern: &envoy_matcher_v3.StringMatcher_Prefix{
-								Prefix: perm.HTTP.PathPrefix,
+								Prefix: http.PathPrefix,
 							},
 						},
 					},
 				},
 			},
 		})
-	case perm.HTTP.PathRegex != "":
+	case http.PathRegex != "":
 		parts = append(parts, &envoy_rbac_v3.Permission{
 			Rule: &envoy_rbac_v3.Permission_UrlPath{
 				UrlPath: &envoy_matcher_v3.PathMatcher{
 					Rule: &envoy_matcher_v3.PathMatcher_Path{
 						Path: &envoy_matcher_v3.StringMatcher{
 							MatchPattern: &envoy_matcher_v3.StringMatcher_SafeRegex{
-								SafeRegex: makeEnvoyRegexMatch(perm.HTTP.PathRegex),
+								SafeRegex: makeEnvoyRegexMatch(http.PathRegex),
 							},
 						},
 					},
@@ -675,7 +815,7 @@ func convertPermission(perm *structs.IntentionPermission) *envoy_rbac_v3.Permiss
 		})
 	}
 
-	for _, hdr := range perm.HTTP.Header {
+	for _, hdr := range http.Header {
 		eh := &envoy_route_v3.HeaderMatcher{
 			Name: hdr.Name,
 		}
@@ -716,8 +856,8 @@ func convertPermission(perm *structs.IntentionPermission) *envoy_rbac_v3.Permiss
 		})
 	}
 
-	if len(perm.HTTP.Methods) > 0 {
-		methodHeaderRegex := strings.Join(perm.HTTP.Methods, "|")
+	if len(http.Methods) > 0 {
+		methodHeaderRegex := strings.Join(http.Methods, "|")
 
 		eh := &envoy_route_v3.HeaderMatcher{
 			Name: ":method",
@@ -733,9 +873,7 @@ func convertPermission(perm *structs.IntentionPermission) *envoy_rbac_v3.Permiss
 		})
 	}
 
-	// NOTE: if for some reason we errantly allow a permission to be defined
-	// with a body of "http{}" then we'll end up treating that like "ANY" here.
-	return andPermissions(parts)
+	return parts
 }
 
 func notPermission(perm *envoy_rbac_v3.Permission) *envoy_rbac_v3.Permission {
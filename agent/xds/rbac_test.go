@@ -704,6 +704,45 @@ func TestMakeRBACNetworkAndHTTPFilters(t *testing.T) {
 				),
 			),
 		},
+		"default-deny-jwt-allow": {
+			intentionDefaultAllow: false,
+			intentions: sorted(
+				testSourcePermIntention("web",
+					&structs.IntentionPermission{
+						Action: structs.IntentionActionAllow,
+						JWT: &structs.IntentionJWTRequirement{
+							Providers: []*structs.IntentionJWTProvider{
+								{Name: "okta"},
+							},
+						},
+					},
+				),
+			),
+		},
+		"default-deny-jwt-with-claims-and-path-allow": {
+			intentionDefaultAllow: false,
+			intentions: sorted(
+				testSourcePermIntention("web",
+					&structs.IntentionPermission{
+						Action: structs.IntentionActionAllow,
+						HTTP: &structs.IntentionHTTPPermission{
+							PathPrefix: "/v1",
+						},
+						JWT: &structs.IntentionJWTRequirement{
+							Providers: []*structs.IntentionJWTProvider{
+								{
+									Name: "okta",
+									VerifyClaims: []*structs.IntentionJWTClaimVerification{
+										{Path: []string{"perms", "role"}, Value: "admin"},
+									},
+								},
+								{Name: "auth0"},
+							},
+						},
+					},
+				),
+			),
+		},
 	}
 
 	for name, tt := range tests {
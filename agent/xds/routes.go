@@ -4,15 +4,22 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"strconv"
 	"strings"
 	"time"
 
 	envoy_core_v3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
 	envoy_route_v3 "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
+	envoy_fault_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/common/fault/v3"
+	envoy_http_buffer_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/buffer/v3"
+	envoy_http_fault_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/fault/v3"
 	envoy_matcher_v3 "github.com/envoyproxy/go-control-plane/envoy/type/matcher/v3"
+	envoy_type_v3 "github.com/envoyproxy/go-control-plane/envoy/type/v3"
 
 	"github.com/golang/protobuf/proto"
 	"github.com/golang/protobuf/ptypes"
+	"github.com/golang/protobuf/ptypes/any"
+	"github.com/golang/protobuf/ptypes/wrappers"
 
 	"github.com/hashicorp/consul/agent/connect"
 	"github.com/hashicorp/consul/agent/proxycfg"
@@ -214,6 +221,9 @@ func (s *ResourceGenerator) routesForIngressGateway(cfgSnap *proxycfg.ConfigSnap
 			if err := injectHeaderManipToVirtualHost(svc, virtualHost); err != nil {
 				return nil, err
 			}
+			if err := injectIngressPoliciesToVirtualHost(cfgSnap.IngressGateway.JWTProviders, lCfg, svc, virtualHost); err != nil {
+				return nil, err
+			}
 
 			// See if this upstream has its own route/filter chain
 			svcRouteName := routeNameForUpstream(lCfg, *svc)
@@ -408,6 +418,16 @@ func makeUpstreamRouteForDiscoveryChain(
 						}
 						retryPolicy.RetriableStatusCodes = destination.RetryOnStatusCodes
 					}
+					for _, code := range destination.RetryOnGRPCStatusCodes {
+						if retryPolicy.RetryOn != "" {
+							retryPolicy.RetryOn = retryPolicy.RetryOn + "," + structs.GRPCRetryOn(code)
+						} else {
+							retryPolicy.RetryOn = structs.GRPCRetryOn(code)
+						}
+					}
+					if destination.PerTryTimeout > 0 {
+						retryPolicy.PerTryTimeout = ptypes.DurationProto(destination.PerTryTimeout)
+					}
 
 					routeAction.Route.RetryPolicy = retryPolicy
 				}
@@ -415,6 +435,16 @@ func makeUpstreamRouteForDiscoveryChain(
 				if err := injectHeaderManipToRoute(destination, route); err != nil {
 					return nil, fmt.Errorf("failed to apply header manipulation configuration to route: %v", err)
 				}
+
+				if err := injectFaultInjectionToRoute(destination, route); err != nil {
+					return nil, fmt.Errorf("failed to apply fault injection configuration to route: %v", err)
+				}
+			}
+
+			if discoveryRoute.MirrorNode != "" {
+				if err := injectMirrorToRouteAction(discoveryRoute, chain, routeAction.Route); err != nil {
+					return nil, err
+				}
 			}
 
 			route.Match = routeMatch
@@ -481,6 +511,10 @@ func makeRouteMatchForDiscoveryRoute(discoveryRoute *structs.DiscoveryRoute) *en
 		return makeDefaultRouteMatch()
 	}
 
+	if match.GRPC != nil {
+		return makeRouteMatchForGRPC(match.GRPC)
+	}
+
 	em := &envoy_route_v3.RouteMatch{}
 
 	switch {
@@ -503,43 +537,7 @@ func makeRouteMatchForDiscoveryRoute(discoveryRoute *structs.DiscoveryRoute) *en
 	}
 
 	if len(match.HTTP.Header) > 0 {
-		em.Headers = make([]*envoy_route_v3.HeaderMatcher, 0, len(match.HTTP.Header))
-		for _, hdr := range match.HTTP.Header {
-			eh := &envoy_route_v3.HeaderMatcher{
-				Name: hdr.Name,
-			}
-
-			switch {
-			case hdr.Exact != "":
-				eh.HeaderMatchSpecifier = &envoy_route_v3.HeaderMatcher_ExactMatch{
-					ExactMatch: hdr.Exact,
-				}
-			case hdr.Regex != "":
-				eh.HeaderMatchSpecifier = &envoy_route_v3.HeaderMatcher_SafeRegexMatch{
-					SafeRegexMatch: makeEnvoyRegexMatch(hdr.Regex),
-				}
-			case hdr.Prefix != "":
-				eh.HeaderMatchSpecifier = &envoy_route_v3.HeaderMatcher_PrefixMatch{
-					PrefixMatch: hdr.Prefix,
-				}
-			case hdr.Suffix != "":
-				eh.HeaderMatchSpecifier = &envoy_route_v3.HeaderMatcher_SuffixMatch{
-					SuffixMatch: hdr.Suffix,
-				}
-			case hdr.Present:
-				eh.HeaderMatchSpecifier = &envoy_route_v3.HeaderMatcher_PresentMatch{
-					PresentMatch: true,
-				}
-			default:
-				continue // skip this impossible situation
-			}
-
-			if hdr.Invert {
-				eh.InvertMatch = true
-			}
-
-			em.Headers = append(em.Headers, eh)
-		}
+		em.Headers = makeHeaderMatchers(match.HTTP.Header)
 	}
 
 	if len(match.HTTP.Methods) > 0 {
@@ -594,6 +592,76 @@ func makeRouteMatchForDiscoveryRoute(discoveryRoute *structs.DiscoveryRoute) *en
 	return em
 }
 
+// makeRouteMatchForGRPC matches the requests to a gRPC service, or to one of
+// its methods, by the path gRPC requests are sent to.
+func makeRouteMatchForGRPC(match *structs.ServiceRouteGRPCMatch) *envoy_route_v3.RouteMatch {
+	em := &envoy_route_v3.RouteMatch{
+		Grpc: &envoy_route_v3.RouteMatch_GrpcRouteMatchOptions{},
+	}
+
+	switch {
+	case match.Method != "":
+		em.PathSpecifier = &envoy_route_v3.RouteMatch_Path{
+			Path: "/" + match.Service + "/" + match.Method,
+		}
+	case match.Service != "":
+		em.PathSpecifier = &envoy_route_v3.RouteMatch_Prefix{
+			Prefix: "/" + match.Service + "/",
+		}
+	default:
+		em.PathSpecifier = &envoy_route_v3.RouteMatch_Prefix{
+			Prefix: "/",
+		}
+	}
+
+	if len(match.Header) > 0 {
+		em.Headers = makeHeaderMatchers(match.Header)
+	}
+
+	return em
+}
+
+func makeHeaderMatchers(headers []structs.ServiceRouteHTTPMatchHeader) []*envoy_route_v3.HeaderMatcher {
+	out := make([]*envoy_route_v3.HeaderMatcher, 0, len(headers))
+	for _, hdr := range headers {
+		eh := &envoy_route_v3.HeaderMatcher{
+			Name: hdr.Name,
+		}
+
+		switch {
+		case hdr.Exact != "":
+			eh.HeaderMatchSpecifier = &envoy_route_v3.HeaderMatcher_ExactMatch{
+				ExactMatch: hdr.Exact,
+			}
+		case hdr.Regex != "":
+			eh.HeaderMatchSpecifier = &envoy_route_v3.HeaderMatcher_SafeRegexMatch{
+				SafeRegexMatch: makeEnvoyRegexMatch(hdr.Regex),
+			}
+		case hdr.Prefix != "":
+			eh.HeaderMatchSpecifier = &envoy_route_v3.HeaderMatcher_PrefixMatch{
+				PrefixMatch: hdr.Prefix,
+			}
+		case hdr.Suffix != "":
+			eh.HeaderMatchSpecifier = &envoy_route_v3.HeaderMatcher_SuffixMatch{
+				SuffixMatch: hdr.Suffix,
+			}
+		case hdr.Present:
+			eh.HeaderMatchSpecifier = &envoy_route_v3.HeaderMatcher_PresentMatch{
+				PresentMatch: true,
+			}
+		default:
+			continue // skip this impossible situation
+		}
+
+		if hdr.Invert {
+			eh.InvertMatch = true
+		}
+
+		out = append(out, eh)
+	}
+	return out
+}
+
 func makeDefaultRouteMatch() *envoy_route_v3.RouteMatch {
 	return &envoy_route_v3.RouteMatch{
 		PathSpecifier: &envoy_route_v3.RouteMatch_Prefix{
@@ -794,6 +862,84 @@ func injectHeaderManipToVirtualHost(dest *structs.IngressService, vh *envoy_rout
 	return nil
 }
 
+const (
+	corsHTTPFilterName   = "envoy.filters.http.cors"
+	bufferHTTPFilterName = "envoy.filters.http.buffer"
+)
+
+// injectIngressPoliciesToVirtualHost applies the CORS policy, request size
+// limit and JWT requirement of an ingress service to its virtual host. The
+// HTTP filters enforcing them are added to the listener by
+// makeIngressPolicyHTTPFilters.
+func injectIngressPoliciesToVirtualHost(
+	providers map[string]*structs.JWTProviderConfigEntry,
+	lCfg structs.IngressListener,
+	svc *structs.IngressService,
+	vh *envoy_route_v3.VirtualHost,
+) error {
+	if svc.CORS != nil {
+		vh.Cors = makeCorsPolicy(svc.CORS)
+	}
+
+	// The buffer filter of the listener is configured with the largest limit
+	// of its services, so each virtual host sets its own limit or opts out.
+	if ingressListenerMaxRequestBytes(lCfg) > 0 {
+		buffer := &envoy_http_buffer_v3.BufferPerRoute{
+			Override: &envoy_http_buffer_v3.BufferPerRoute_Disabled{Disabled: true},
+		}
+		if svc.MaxRequestBytes > 0 {
+			buffer.Override = &envoy_http_buffer_v3.BufferPerRoute_Buffer{
+				Buffer: &envoy_http_buffer_v3.Buffer{
+					MaxRequestBytes: &wrappers.UInt32Value{Value: svc.MaxRequestBytes},
+				},
+			}
+		}
+		if err := setVirtualHostPerFilterConfig(vh, bufferHTTPFilterName, buffer); err != nil {
+			return err
+		}
+	}
+
+	return injectIngressJWTRequirementToVirtualHost(providers, svc, vh)
+}
+
+func makeCorsPolicy(cfg *structs.IngressCORSConfig) *envoy_route_v3.CorsPolicy {
+	policy := &envoy_route_v3.CorsPolicy{
+		AllowMethods:  strings.Join(cfg.AllowMethods, ","),
+		AllowHeaders:  strings.Join(cfg.AllowHeaders, ","),
+		ExposeHeaders: strings.Join(cfg.ExposeHeaders, ","),
+	}
+	for _, origin := range cfg.AllowOrigins {
+		match := &envoy_matcher_v3.StringMatcher{
+			MatchPattern: &envoy_matcher_v3.StringMatcher_Exact{Exact: origin},
+		}
+		if origin == structs.WildcardSpecifier {
+			match.MatchPattern = &envoy_matcher_v3.StringMatcher_SafeRegex{
+				SafeRegex: makeEnvoyRegexMatch(".*"),
+			}
+		}
+		policy.AllowOriginStringMatch = append(policy.AllowOriginStringMatch, match)
+	}
+	if cfg.MaxAge > 0 {
+		policy.MaxAge = strconv.FormatInt(int64(cfg.MaxAge/time.Second), 10)
+	}
+	if cfg.AllowCredentials {
+		policy.AllowCredentials = makeBoolValue(true)
+	}
+	return policy
+}
+
+func setVirtualHostPerFilterConfig(vh *envoy_route_v3.VirtualHost, filterName string, cfg proto.Message) error {
+	typed, err := ptypes.MarshalAny(cfg)
+	if err != nil {
+		return err
+	}
+	if vh.TypedPerFilterConfig == nil {
+		vh.TypedPerFilterConfig = make(map[string]*any.Any)
+	}
+	vh.TypedPerFilterConfig[filterName] = typed
+	return nil
+}
+
 func injectHeaderManipToWeightedCluster(split *structs.ServiceSplit, c *envoy_route_v3.WeightedCluster_ClusterWeight) error {
 	if !split.RequestHeaders.IsZero() {
 		c.RequestHeadersToAdd = append(
@@ -825,3 +971,99 @@ func injectHeaderManipToWeightedCluster(split *structs.ServiceSplit, c *envoy_ro
 	}
 	return nil
 }
+
+const faultHTTPFilterName = "envoy.filters.http.fault"
+
+// injectFaultInjectionToRoute configures the fault filter of the upstream
+// listener to delay or abort requests matching the route. The filter itself is
+// only added to listeners whose chain uses fault injection, see
+// chainUsesFaultInjection.
+func injectFaultInjectionToRoute(dest *structs.ServiceRouteDestination, r *envoy_route_v3.Route) error {
+	if dest.FaultInjection == nil {
+		return nil
+	}
+
+	fault := &envoy_http_fault_v3.HTTPFault{}
+	if delay := dest.FaultInjection.Delay; delay != nil {
+		fault.Delay = &envoy_fault_v3.FaultDelay{
+			FaultDelaySecifier: &envoy_fault_v3.FaultDelay_FixedDelay{
+				FixedDelay: ptypes.DurationProto(delay.Duration),
+			},
+			Percentage: makeFractionalPercent(delay.Percentage),
+		}
+	}
+	if abort := dest.FaultInjection.Abort; abort != nil {
+		fault.Abort = &envoy_http_fault_v3.FaultAbort{
+			ErrorType: &envoy_http_fault_v3.FaultAbort_HttpStatus{
+				HttpStatus: abort.HTTPStatus,
+			},
+			Percentage: makeFractionalPercent(abort.Percentage),
+		}
+	}
+
+	cfg, err := ptypes.MarshalAny(fault)
+	if err != nil {
+		return err
+	}
+	if r.TypedPerFilterConfig == nil {
+		r.TypedPerFilterConfig = make(map[string]*any.Any)
+	}
+	r.TypedPerFilterConfig[faultHTTPFilterName] = cfg
+	return nil
+}
+
+// injectMirrorToRouteAction sends a copy of a percentage of the requests
+// matching the route to the cluster of its mirror node.
+func injectMirrorToRouteAction(
+	discoveryRoute *structs.DiscoveryRoute,
+	chain *structs.CompiledDiscoveryChain,
+	action *envoy_route_v3.RouteAction,
+) error {
+	mirrorNode := chain.Nodes[discoveryRoute.MirrorNode]
+	if mirrorNode == nil || mirrorNode.Type != structs.DiscoveryGraphNodeTypeResolver {
+		return fmt.Errorf("unexpected mirror node for route %q", discoveryRoute.MirrorNode)
+	}
+	target := chain.Targets[mirrorNode.Resolver.Target]
+
+	var percentage float32
+	if dest := discoveryRoute.Definition.Destination; dest != nil && dest.Mirror != nil {
+		percentage = dest.Mirror.Percentage
+	}
+
+	action.RequestMirrorPolicies = []*envoy_route_v3.RouteAction_RequestMirrorPolicy{
+		{
+			Cluster: CustomizeClusterName(target.Name, chain),
+			RuntimeFraction: &envoy_core_v3.RuntimeFractionalPercent{
+				DefaultValue: makeFractionalPercent(percentage),
+			},
+		},
+	}
+	return nil
+}
+
+// chainUsesFaultInjection reports whether any route of the chain injects
+// faults, in which case the fault filter must be added to the listener.
+func chainUsesFaultInjection(chain *structs.CompiledDiscoveryChain) bool {
+	if chain == nil {
+		return false
+	}
+	startNode := chain.Nodes[chain.StartNode]
+	if startNode == nil || startNode.Type != structs.DiscoveryGraphNodeTypeRouter {
+		return false
+	}
+	for _, route := range startNode.Routes {
+		if dest := route.Definition.Destination; dest != nil && dest.FaultInjection != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// makeFractionalPercent converts a percentage with a precision of 0.0001% to
+// an Envoy fractional percent.
+func makeFractionalPercent(percentage float32) *envoy_type_v3.FractionalPercent {
+	return &envoy_type_v3.FractionalPercent{
+		Numerator:   uint32(percentage*10000 + 0.5),
+		Denominator: envoy_type_v3.FractionalPercent_MILLION,
+	}
+}
@@ -68,6 +68,12 @@ func TestRoutesFromSnapshot(t *testing.T) {
 				return proxycfg.TestConfigSnapshotDiscoveryChain(t, "grpc-router", nil, nil)
 			},
 		},
+		{
+			name: "connect-proxy-with-grpc-router-with-grpc-match",
+			create: func(t testinf.T) *proxycfg.ConfigSnapshot {
+				return proxycfg.TestConfigSnapshotDiscoveryChain(t, "grpc-router-with-grpc-match", nil, nil)
+			},
+		},
 		{
 			name: "connect-proxy-with-chain-and-router",
 			create: func(t testinf.T) *proxycfg.ConfigSnapshot {
@@ -80,6 +86,12 @@ func TestRoutesFromSnapshot(t *testing.T) {
 				return proxycfg.TestConfigSnapshotDiscoveryChain(t, "lb-resolver", nil, nil)
 			},
 		},
+		{
+			name: "connect-proxy-with-fault-injection-and-mirror",
+			create: func(t testinf.T) *proxycfg.ConfigSnapshot {
+				return proxycfg.TestConfigSnapshotDiscoveryChain(t, "router-with-fault-injection-and-mirror", nil, nil)
+			},
+		},
 		// TODO(rb): test match stanza skipped for grpc
 		// Start ingress gateway test cases
 		{
@@ -142,6 +154,10 @@ func TestRoutesFromSnapshot(t *testing.T) {
 			name:   "ingress-http-multiple-services",
 			create: proxycfg.TestConfigSnapshotIngress_HTTPMultipleServices,
 		},
+		{
+			name:   "ingress-http-with-cors-size-limit-and-jwt",
+			create: proxycfg.TestConfigSnapshotIngressGateway_HTTPPolicies,
+		},
 		{
 			name: "ingress-with-chain-and-router-header-manip",
 			create: func(t testinf.T) *proxycfg.ConfigSnapshot {
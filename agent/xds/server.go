@@ -127,6 +127,7 @@ type Server struct {
 	ResourceMapMutateFn func(resourceMap *xdscommon.IndexedResources)
 
 	activeStreams           *activeStreamCounters
+	streamStatuses          *streamStatusRegistry
 	serverlessPluginEnabled bool
 }
 
@@ -176,6 +177,7 @@ func NewServer(
 		CfgFetcher:              cfgFetcher,
 		AuthCheckFrequency:      DefaultAuthCheckFrequency,
 		activeStreams:           &activeStreamCounters{},
+		streamStatuses:          newStreamStatusRegistry(),
 		serverlessPluginEnabled: serverlessPluginEnabled,
 	}
 }
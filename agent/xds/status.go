@@ -0,0 +1,330 @@
+package xds
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	envoy_core_v3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+
+	"github.com/hashicorp/consul/agent/proxycfg"
+	"github.com/hashicorp/consul/agent/structs"
+)
+
+// maxProxyStatusEvents is the number of xDS events kept in the history of a
+// proxy's status.
+const maxProxyStatusEvents = 32
+
+const (
+	ProxyStatusEventSend = "send"
+	ProxyStatusEventACK  = "ack"
+	ProxyStatusEventNACK = "nack"
+)
+
+// ProxyStatus reports what the xDS server has sent to a connected proxy and how
+// the proxy responded, to debug proxies without access to their admin API.
+type ProxyStatus struct {
+	ProxyID     string
+	ConnectedAt time.Time
+
+	// Envoy describes the proxy, as reported by its first request.
+	Envoy ProxyStatusEnvoy
+
+	// Snapshot summarizes the last config snapshot the resources were
+	// generated from. It is nil until the first snapshot arrives.
+	Snapshot *ProxyStatusSnapshot `json:",omitempty"`
+
+	// Resources are the resources of each type sent to the proxy, keyed by
+	// type URL.
+	Resources map[string]*ProxyStatusResources
+
+	// Events are the most recent responses sent to the proxy and their
+	// ACKs and NACKs, oldest first.
+	Events []ProxyStatusEvent
+}
+
+type ProxyStatusEnvoy struct {
+	// Version is empty if the proxy didn't report an Envoy build version.
+	Version string `json:",omitempty"`
+
+	// ClientFeatures are the xDS client features the proxy supports.
+	ClientFeatures []string `json:",omitempty"`
+}
+
+type ProxyStatusSnapshot struct {
+	ReceivedAt time.Time
+	Kind       structs.ServiceKind
+	Service    string
+	Datacenter string
+	Valid      bool
+
+	// Upstreams are the upstreams of a sidecar or ingress gateway, or the
+	// services of a terminating gateway.
+	Upstreams []string `json:",omitempty"`
+
+	LeafCertSerial string    `json:",omitempty"`
+	LeafCertExpiry time.Time `json:",omitempty"`
+}
+
+type ProxyStatusResources struct {
+	// Versions are the versions of the resources last sent, keyed by name.
+	// They are not necessarily accepted by the proxy yet.
+	Versions map[string]string
+
+	// AckedVersions are the versions of the resources the proxy accepted,
+	// keyed by name.
+	AckedVersions map[string]string
+
+	LastSentNonce   string `json:",omitempty"`
+	LastAckedNonce  string `json:",omitempty"`
+	LastNackedNonce string `json:",omitempty"`
+	LastNackError   string `json:",omitempty"`
+}
+
+type ProxyStatusEvent struct {
+	Time    time.Time
+	Type    string
+	TypeURL string
+	Nonce   string
+
+	// Upserted and Removed are the names of the resources sent.
+	Upserted []string `json:",omitempty"`
+	Removed  []string `json:",omitempty"`
+
+	// Error is the error detail of a NACK.
+	Error string `json:",omitempty"`
+}
+
+// ProxyStatus returns the status of the xDS stream of the given proxy, or
+// false if it isn't connected.
+func (s *Server) ProxyStatus(proxyID structs.ServiceID) (ProxyStatus, bool) {
+	return s.streamStatuses.get(proxyID)
+}
+
+// streamStatusRegistry indexes the status of the active xDS streams by proxy
+// ID.
+type streamStatusRegistry struct {
+	lock     sync.RWMutex
+	statuses map[structs.ServiceID]*streamStatus
+}
+
+func newStreamStatusRegistry() *streamStatusRegistry {
+	return &streamStatusRegistry{
+		statuses: make(map[structs.ServiceID]*streamStatus),
+	}
+}
+
+// register makes the status of a stream available under the ID of its proxy.
+// It returns a function removing it again. If the proxy reconnects before its
+// previous stream ended, the newest stream wins.
+func (r *streamStatusRegistry) register(proxyID structs.ServiceID, status *streamStatus) func() {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.statuses[proxyID] = status
+
+	return func() {
+		r.lock.Lock()
+		defer r.lock.Unlock()
+		if r.statuses[proxyID] == status {
+			delete(r.statuses, proxyID)
+		}
+	}
+}
+
+func (r *streamStatusRegistry) get(proxyID structs.ServiceID) (ProxyStatus, bool) {
+	r.lock.RLock()
+	status, ok := r.statuses[proxyID]
+	r.lock.RUnlock()
+	if !ok {
+		return ProxyStatus{}, false
+	}
+	return status.get(), true
+}
+
+// streamStatus records the status of a single xDS stream. It is written by the
+// goroutine handling the stream and read by the HTTP API, so all its methods
+// lock. A nil streamStatus records nothing.
+type streamStatus struct {
+	lock   sync.Mutex
+	status ProxyStatus
+}
+
+func newStreamStatus(proxyID structs.ServiceID, node *envoy_core_v3.Node) *streamStatus {
+	status := ProxyStatus{
+		ProxyID:     proxyID.String(),
+		ConnectedAt: time.Now().UTC(),
+		Resources:   make(map[string]*ProxyStatusResources),
+	}
+	if v := determineEnvoyVersionFromNode(node); v != nil {
+		status.Envoy.Version = v.String()
+	}
+	status.Envoy.ClientFeatures = append([]string(nil), node.GetClientFeatures()...)
+
+	return &streamStatus{status: status}
+}
+
+func (s *streamStatus) get() ProxyStatus {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	// Copy everything a later update could modify.
+	out := s.status
+	if out.Snapshot != nil {
+		snap := *out.Snapshot
+		out.Snapshot = &snap
+	}
+	out.Resources = make(map[string]*ProxyStatusResources, len(s.status.Resources))
+	for typeURL, res := range s.status.Resources {
+		cp := *res
+		cp.Versions = copyVersions(res.Versions)
+		cp.AckedVersions = copyVersions(res.AckedVersions)
+		out.Resources[typeURL] = &cp
+	}
+	out.Events = append([]ProxyStatusEvent(nil), s.status.Events...)
+	return out
+}
+
+func (s *streamStatus) recordSnapshot(cfgSnap *proxycfg.ConfigSnapshot) {
+	if s == nil || cfgSnap == nil {
+		return
+	}
+
+	summary := &ProxyStatusSnapshot{
+		ReceivedAt: time.Now().UTC(),
+		Kind:       cfgSnap.Kind,
+		Service:    cfgSnap.Service,
+		Datacenter: cfgSnap.Datacenter,
+		Valid:      cfgSnap.Valid(),
+	}
+	switch cfgSnap.Kind {
+	case structs.ServiceKindConnectProxy:
+		// Prepared query upstreams have no discovery chain, and upstreams
+		// discovered in transparent proxy mode may have no explicit config.
+		uids := make(map[proxycfg.UpstreamID]struct{})
+		for uid := range cfgSnap.ConnectProxy.DiscoveryChain {
+			uids[uid] = struct{}{}
+		}
+		for uid := range cfgSnap.ConnectProxy.UpstreamConfig {
+			uids[uid] = struct{}{}
+		}
+		for uid := range uids {
+			summary.Upstreams = append(summary.Upstreams, uid.String())
+		}
+	case structs.ServiceKindIngressGateway:
+		for uid := range cfgSnap.IngressGateway.DiscoveryChain {
+			summary.Upstreams = append(summary.Upstreams, uid.String())
+		}
+	case structs.ServiceKindTerminatingGateway:
+		for _, svc := range cfgSnap.TerminatingGateway.ValidServices() {
+			summary.Upstreams = append(summary.Upstreams, svc.String())
+		}
+	}
+	sort.Strings(summary.Upstreams)
+	if leaf := cfgSnap.Leaf(); leaf != nil {
+		summary.LeafCertSerial = leaf.SerialNumber
+		summary.LeafCertExpiry = leaf.ValidBefore
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.status.Snapshot = summary
+}
+
+func (s *streamStatus) recordSend(typeURL, nonce string, updates map[string]PendingUpdate) {
+	if s == nil {
+		return
+	}
+
+	event := ProxyStatusEvent{
+		Type:    ProxyStatusEventSend,
+		TypeURL: typeURL,
+		Nonce:   nonce,
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	res := s.resources(typeURL)
+	res.LastSentNonce = nonce
+	for name, update := range updates {
+		if update.Remove {
+			delete(res.Versions, name)
+			event.Removed = append(event.Removed, name)
+		} else {
+			res.Versions[name] = update.Version
+			event.Upserted = append(event.Upserted, name)
+		}
+	}
+	sort.Strings(event.Upserted)
+	sort.Strings(event.Removed)
+	s.addEvent(event)
+}
+
+// recordACK records the ACK of a response, given the versions of the
+// resources the proxy now has.
+func (s *streamStatus) recordACK(typeURL, nonce string, ackedVersions map[string]string) {
+	if s == nil {
+		return
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	res := s.resources(typeURL)
+	res.LastAckedNonce = nonce
+	res.AckedVersions = copyVersions(ackedVersions)
+	s.addEvent(ProxyStatusEvent{
+		Type:    ProxyStatusEventACK,
+		TypeURL: typeURL,
+		Nonce:   nonce,
+	})
+}
+
+func (s *streamStatus) recordNACK(typeURL, nonce, errorDetail string) {
+	if s == nil {
+		return
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	res := s.resources(typeURL)
+	res.LastNackedNonce = nonce
+	res.LastNackError = errorDetail
+	s.addEvent(ProxyStatusEvent{
+		Type:    ProxyStatusEventNACK,
+		TypeURL: typeURL,
+		Nonce:   nonce,
+		Error:   errorDetail,
+	})
+}
+
+// resources must be called with the lock held.
+func (s *streamStatus) resources(typeURL string) *ProxyStatusResources {
+	res, ok := s.status.Resources[typeURL]
+	if !ok {
+		res = &ProxyStatusResources{
+			Versions:      make(map[string]string),
+			AckedVersions: make(map[string]string),
+		}
+		s.status.Resources[typeURL] = res
+	}
+	return res
+}
+
+// addEvent must be called with the lock held.
+func (s *streamStatus) addEvent(event ProxyStatusEvent) {
+	event.Time = time.Now().UTC()
+	s.status.Events = append(s.status.Events, event)
+	if n := len(s.status.Events); n > maxProxyStatusEvents {
+		s.status.Events = append([]ProxyStatusEvent(nil), s.status.Events[n-maxProxyStatusEvents:]...)
+	}
+}
+
+func copyVersions(versions map[string]string) map[string]string {
+	out := make(map[string]string, len(versions))
+	for name, version := range versions {
+		out[name] = version
+	}
+	return out
+}
@@ -0,0 +1,95 @@
+package xds
+
+import (
+	"fmt"
+	"sort"
+
+	envoy_listener_v3 "github.com/envoyproxy/go-control-plane/envoy/config/listener/v3"
+	envoy_http_v3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/http_connection_manager/v3"
+	envoy_tracing_v3 "github.com/envoyproxy/go-control-plane/envoy/type/tracing/v3"
+	envoy_type_v3 "github.com/envoyproxy/go-control-plane/envoy/type/v3"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+
+	"github.com/hashicorp/consul/agent/proxycfg"
+	"github.com/hashicorp/consul/agent/structs"
+)
+
+// injectTracing applies the sampling rate and custom tags of the tracing
+// configured for the proxy to the HTTP connection managers of every listener.
+//
+// The tracer itself and its collector cluster are part of the bootstrap config
+// of the proxy, since Envoy requires the collector to be a static cluster.
+func (s *ResourceGenerator) injectTracing(cfgSnap *proxycfg.ConfigSnapshot, resources []proto.Message) error {
+	cfg, err := ParseTracingConfig(cfgSnap.Proxy.Config)
+	if err != nil {
+		// Don't hard fail on a config typo, just warn. Requests are only traced
+		// when the client asks for it if the config can't be parsed.
+		s.Logger.Warn("failed to parse tracing config", "error", err)
+		return nil
+	}
+	if cfg == nil {
+		return nil
+	}
+
+	tracing := makeHTTPConnectionManagerTracing(cfg)
+	for _, res := range resources {
+		l, ok := res.(*envoy_listener_v3.Listener)
+		if !ok {
+			continue
+		}
+		for _, chain := range listenerFilterChains(l) {
+			for _, filter := range chain.Filters {
+				if err := injectTracingOnFilter(filter, tracing); err != nil {
+					return fmt.Errorf("failed to add tracing to listener %q: %v", l.Name, err)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func injectTracingOnFilter(filter *envoy_listener_v3.Filter, tracing *envoy_http_v3.HttpConnectionManager_Tracing) error {
+	if filter.Name != httpConnectionManagerOldName && filter.Name != httpConnectionManagerNewName {
+		return nil
+	}
+
+	tc, ok := filter.ConfigType.(*envoy_listener_v3.Filter_TypedConfig)
+	if !ok {
+		return fmt.Errorf("filter %q has an unsupported config type: %T", filter.Name, filter.ConfigType)
+	}
+	var hcm envoy_http_v3.HttpConnectionManager
+	if err := ptypes.UnmarshalAny(tc.TypedConfig, &hcm); err != nil {
+		return err
+	}
+
+	hcm.Tracing = tracing
+	any, err := ptypes.MarshalAny(&hcm)
+	if err != nil {
+		return err
+	}
+	tc.TypedConfig = any
+	return nil
+}
+
+func makeHTTPConnectionManagerTracing(cfg *structs.TracingConfig) *envoy_http_v3.HttpConnectionManager_Tracing {
+	tracing := &envoy_http_v3.HttpConnectionManager_Tracing{
+		RandomSampling: &envoy_type_v3.Percent{Value: cfg.SamplingPercentage},
+	}
+
+	tags := make([]string, 0, len(cfg.CustomTags))
+	for tag := range cfg.CustomTags {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	for _, tag := range tags {
+		tracing.CustomTags = append(tracing.CustomTags, &envoy_tracing_v3.CustomTag{
+			Tag: tag,
+			Type: &envoy_tracing_v3.CustomTag_Literal_{
+				Literal: &envoy_tracing_v3.CustomTag_Literal{Value: cfg.CustomTags[tag]},
+			},
+		})
+	}
+	return tracing
+}
@@ -40,6 +40,7 @@ type ACLToken struct {
 	NodeIdentities    []*ACLNodeIdentity    `json:",omitempty"`
 	Local             bool
 	AuthMethod        string        `json:",omitempty"`
+	ParentAccessorID  string        `json:",omitempty"`
 	ExpirationTTL     time.Duration `json:",omitempty"`
 	ExpirationTime    *time.Time    `json:",omitempty"`
 	CreateTime        time.Time     `json:",omitempty"`
@@ -88,6 +89,7 @@ type ACLTokenListEntry struct {
 	NodeIdentities    []*ACLNodeIdentity    `json:",omitempty"`
 	Local             bool
 	AuthMethod        string     `json:",omitempty"`
+	ParentAccessorID  string     `json:",omitempty"`
 	ExpirationTime    *time.Time `json:",omitempty"`
 	CreateTime        time.Time
 	Hash              []byte
@@ -166,6 +168,43 @@ type ACLPolicy struct {
 	Partition string `json:",omitempty"`
 }
 
+// ACLPolicyLintFinding describes a single problem found while linting the
+// rules of a policy.
+type ACLPolicyLintFinding struct {
+	// Severity is either "warning" or "info".
+	Severity string
+
+	// Kind identifies the check that produced the finding, for example
+	// "shadowed", "duplicate", "unreachable", "broad-write" or "deprecated".
+	Kind string
+
+	// Rule is the rule the finding is about, e.g. `service_prefix "web"`.
+	Rule string
+
+	Message string
+}
+
+// ACLPolicyLintResult holds the lint findings for a single policy. PolicyID
+// and PolicyName are empty when linting rules that were passed directly.
+type ACLPolicyLintResult struct {
+	PolicyID   string
+	PolicyName string
+	Findings   []ACLPolicyLintFinding
+}
+
+// ACLPolicyGrant identifies a policy granting access to a resource along with
+// the rule in that policy which grants it.
+type ACLPolicyGrant struct {
+	PolicyID   string
+	PolicyName string
+	Rule       string
+}
+
+type ACLPolicyLintResponse struct {
+	Results []ACLPolicyLintResult
+	Grants  []ACLPolicyGrant
+}
+
 type ACLPolicyListEntry struct {
 	ID          string
 	Name        string
@@ -759,6 +798,32 @@ func (a *ACL) TokenClone(tokenID string, description string, q *WriteOptions) (*
 	return &out, wm, nil
 }
 
+// TokenDerive creates a new short-lived token from the token used to make the
+// request. Only the Description, Policies, ServiceIdentities, NodeIdentities
+// and ExpirationTTL fields of the passed token are used. The requested policies
+// and identities must be a subset of those granted to the requesting token and
+// the derived token is revoked when the requesting token is deleted.
+func (a *ACL) TokenDerive(token *ACLToken, q *WriteOptions) (*ACLToken, *WriteMeta, error) {
+	r := a.c.newRequest("PUT", "/v1/acl/token/derive")
+	r.setWriteOptions(q)
+	r.obj = token
+	rtt, resp, err := a.c.doRequest(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer closeResponseBody(resp)
+	if err := requireOK(resp); err != nil {
+		return nil, nil, err
+	}
+	wm := &WriteMeta{RequestTime: rtt}
+	var out ACLToken
+	if err := decodeBody(resp, &out); err != nil {
+		return nil, nil, err
+	}
+
+	return &out, wm, nil
+}
+
 // TokenDelete removes a single ACL token. The tokenID parameter must be a valid
 // Accessor ID of an existing token.
 func (a *ACL) TokenDelete(tokenID string, q *WriteOptions) (*WriteMeta, error) {
@@ -1026,6 +1091,68 @@ func (a *ACL) PolicyList(q *QueryOptions) ([]*ACLPolicyListEntry, *QueryMeta, er
 	return entries, qm, nil
 }
 
+// PolicyLint checks policy rules for rules that are shadowed, unreachable or
+// overly broad. When rules is empty all of the stored policies are linted,
+// otherwise only the given rules are.
+func (a *ACL) PolicyLint(rules string, q *QueryOptions) ([]ACLPolicyLintResult, *QueryMeta, error) {
+	method := "GET"
+	if rules != "" {
+		method = "PUT"
+	}
+	r := a.c.newRequest(method, "/v1/acl/policy/lint")
+	if rules != "" {
+		r.obj = map[string]string{"Rules": rules}
+	}
+	r.setQueryOptions(q)
+	rtt, resp, err := a.c.doRequest(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer closeResponseBody(resp)
+	if err := requireOK(resp); err != nil {
+		return nil, nil, err
+	}
+	qm := &QueryMeta{}
+	parseQueryMeta(resp, qm)
+	qm.RequestTime = rtt
+
+	var out ACLPolicyLintResponse
+	if err := decodeBody(resp, &out); err != nil {
+		return nil, nil, err
+	}
+	return out.Results, qm, nil
+}
+
+// PolicyGrants lists the policies which grant the given access level to a
+// resource, e.g. "write" access to the "service" named "web". An empty access
+// level defaults to "read".
+func (a *ACL) PolicyGrants(resource, segment, access string, q *QueryOptions) ([]ACLPolicyGrant, *QueryMeta, error) {
+	r := a.c.newRequest("GET", "/v1/acl/policy/lint")
+	r.setQueryOptions(q)
+	r.params.Set("resource", resource)
+	r.params.Set("segment", segment)
+	if access != "" {
+		r.params.Set("access", access)
+	}
+	rtt, resp, err := a.c.doRequest(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer closeResponseBody(resp)
+	if err := requireOK(resp); err != nil {
+		return nil, nil, err
+	}
+	qm := &QueryMeta{}
+	parseQueryMeta(resp, qm)
+	qm.RequestTime = rtt
+
+	var out ACLPolicyLintResponse
+	if err := decodeBody(resp, &out); err != nil {
+		return nil, nil, err
+	}
+	return out.Grants, qm, nil
+}
+
 // RulesTranslate translates the legacy rule syntax into the current syntax.
 //
 // Deprecated: Support for the legacy syntax translation will be removed
@@ -146,6 +146,49 @@ func TestAPI_ACLPolicy_CreateUpdate(t *testing.T) {
 	require.Equal(t, updated, updated_read)
 }
 
+func TestAPI_ACLPolicy_Lint(t *testing.T) {
+	t.Parallel()
+	c, s := makeACLClient(t)
+	defer s.Stop()
+
+	acl := c.ACL()
+
+	created, _, err := acl.PolicyCreate(&ACLPolicy{
+		Name:  "shadowed",
+		Rules: `node_prefix "" { policy = "read" } node "foo" { policy = "read" }`,
+	}, nil)
+	require.NoError(t, err)
+
+	results, _, err := acl.PolicyLint(`key_prefix "" { policy = "write" }`, nil)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	require.Equal(t, []ACLPolicyLintFinding{{
+		Severity: "warning",
+		Kind:     "broad-write",
+		Rule:     `key_prefix ""`,
+		Message:  "grants write access to every key; consider scoping the rule to the names that need it",
+	}}, results[0].Findings)
+
+	results, _, err = acl.PolicyLint("", nil)
+	require.NoError(t, err)
+	var found bool
+	for _, result := range results {
+		if result.PolicyID == created.ID {
+			found = true
+			require.Len(t, result.Findings, 1)
+			require.Equal(t, "shadowed", result.Findings[0].Kind)
+		}
+	}
+	require.True(t, found)
+
+	grants, _, err := acl.PolicyGrants("node", "foo", "read", nil)
+	require.NoError(t, err)
+	require.ElementsMatch(t, []ACLPolicyGrant{
+		{PolicyID: "00000000-0000-0000-0000-000000000001", PolicyName: "global-management", Rule: `node_prefix ""`},
+		{PolicyID: created.ID, PolicyName: "shadowed", Rule: `node "foo"`},
+	}, grants)
+}
+
 func TestAPI_ACLPolicy_List(t *testing.T) {
 	t.Parallel()
 	c, s := makeACLClient(t)
@@ -538,6 +581,36 @@ func TestAPI_ACLToken_Clone(t *testing.T) {
 	require.Equal(t, cloned, read)
 }
 
+func TestAPI_ACLToken_Derive(t *testing.T) {
+	t.Parallel()
+	c, s := makeACLClient(t)
+	defer s.Stop()
+
+	acl := c.ACL()
+
+	initialManagement, _, err := acl.TokenReadSelf(nil)
+	require.NoError(t, err)
+	require.NotNil(t, initialManagement)
+
+	derived, _, err := acl.TokenDerive(&ACLToken{
+		Description:   "derived",
+		Policies:      initialManagement.Policies,
+		ExpirationTTL: time.Hour,
+	}, nil)
+	require.NoError(t, err)
+	require.NotNil(t, derived)
+	require.NotEqual(t, initialManagement.AccessorID, derived.AccessorID)
+	require.Equal(t, initialManagement.AccessorID, derived.ParentAccessorID)
+	require.Equal(t, "derived", derived.Description)
+	require.NotNil(t, derived.ExpirationTime)
+	require.ElementsMatch(t, initialManagement.Policies, derived.Policies)
+
+	read, _, err := acl.TokenRead(derived.AccessorID, nil)
+	require.NoError(t, err)
+	require.NotNil(t, read)
+	require.Equal(t, derived, read)
+}
+
 //
 func TestAPI_AuthMethod_List(t *testing.T) {
 	t.Parallel()
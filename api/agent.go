@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"time"
 )
 
 // ServiceKind is the kind of service being registered.
@@ -402,6 +403,19 @@ type AgentAuthorizeParams struct {
 	Target           string
 	ClientCertURI    string
 	ClientCertSerial string
+
+	// HTTP has the attributes of the request being authorized. When it is set
+	// the permissions of L7 intentions are evaluated against the request,
+	// otherwise any L7 intention denies it.
+	HTTP *AgentAuthorizeHTTPRequest `json:",omitempty"`
+}
+
+// AgentAuthorizeHTTPRequest has the attributes of an HTTP request that the
+// permissions of L7 intentions match against.
+type AgentAuthorizeHTTPRequest struct {
+	Method string
+	Path   string
+	Header map[string][]string `json:",omitempty"`
 }
 
 // AgentAuthorize is the response structure for Connect authorization.
@@ -427,6 +441,7 @@ type Upstream struct {
 	DestinationPartition string           `json:",omitempty"`
 	DestinationNamespace string           `json:",omitempty"`
 	DestinationName      string
+	DestinationPeer      string                 `json:",omitempty"`
 	Datacenter           string                 `json:",omitempty"`
 	LocalBindAddress     string                 `json:",omitempty"`
 	LocalBindPort        int                    `json:",omitempty"`
@@ -437,6 +452,57 @@ type Upstream struct {
 	CentrallyConfigured  bool                   `json:",omitempty" bexpr:"-"`
 }
 
+// AgentXDSStatus is the response structure for the xDS status of a proxy
+// connected to the agent.
+type AgentXDSStatus struct {
+	ProxyID     string
+	ConnectedAt time.Time
+	Envoy       AgentXDSStatusEnvoy
+	Snapshot    *AgentXDSStatusSnapshot `json:",omitempty"`
+	Resources   map[string]*AgentXDSStatusResources
+	Events      []AgentXDSStatusEvent
+}
+
+// AgentXDSStatusEnvoy describes the Envoy proxy connected to the agent.
+type AgentXDSStatusEnvoy struct {
+	Version        string   `json:",omitempty"`
+	ClientFeatures []string `json:",omitempty"`
+}
+
+// AgentXDSStatusSnapshot summarizes the config snapshot the resources sent to
+// a proxy were generated from.
+type AgentXDSStatusSnapshot struct {
+	ReceivedAt     time.Time
+	Kind           ServiceKind
+	Service        string
+	Datacenter     string
+	Valid          bool
+	Upstreams      []string  `json:",omitempty"`
+	LeafCertSerial string    `json:",omitempty"`
+	LeafCertExpiry time.Time `json:",omitempty"`
+}
+
+// AgentXDSStatusResources are the resources of a single type sent to a proxy.
+type AgentXDSStatusResources struct {
+	Versions        map[string]string
+	AckedVersions   map[string]string
+	LastSentNonce   string `json:",omitempty"`
+	LastAckedNonce  string `json:",omitempty"`
+	LastNackedNonce string `json:",omitempty"`
+	LastNackError   string `json:",omitempty"`
+}
+
+// AgentXDSStatusEvent is a response sent to a proxy, or its ACK or NACK.
+type AgentXDSStatusEvent struct {
+	Time     time.Time
+	Type     string
+	TypeURL  string
+	Nonce    string
+	Upserted []string `json:",omitempty"`
+	Removed  []string `json:",omitempty"`
+	Error    string   `json:",omitempty"`
+}
+
 // Agent can be used to query the Agent endpoints
 type Agent struct {
 	c *Client
@@ -1128,6 +1194,31 @@ func (a *Agent) ConnectCALeaf(serviceID string, q *QueryOptions) (*LeafCert, *Qu
 	return &out, qm, nil
 }
 
+// XDSStatus returns what the agent sent over xDS to the proxy with the given
+// service ID, and how the proxy responded. The proxy must be connected to the
+// agent.
+func (a *Agent) XDSStatus(proxyID string, q *QueryOptions) (*AgentXDSStatus, *QueryMeta, error) {
+	r := a.c.newRequest("GET", "/v1/agent/xds/"+proxyID)
+	r.setQueryOptions(q)
+	rtt, resp, err := a.c.doRequest(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer closeResponseBody(resp)
+	if err := requireOK(resp); err != nil {
+		return nil, nil, err
+	}
+	qm := &QueryMeta{}
+	parseQueryMeta(resp, qm)
+	qm.RequestTime = rtt
+
+	var out AgentXDSStatus
+	if err := decodeBody(resp, &out); err != nil {
+		return nil, nil, err
+	}
+	return &out, qm, nil
+}
+
 // EnableServiceMaintenance toggles service maintenance mode on
 // for the given service ID.
 func (a *Agent) EnableServiceMaintenance(serviceID, reason string) error {
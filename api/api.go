@@ -107,6 +107,10 @@ type QueryOptions struct {
 	// Note: Partitions are available only in Consul Enterprise
 	Partition string
 
+	// Peer is the name of the cluster peer to query services imported from.
+	// It is only supported by the health service endpoints.
+	Peer string
+
 	// Providing a datacenter overwrites the DC provided
 	// by the Config
 	Datacenter string
@@ -786,6 +790,9 @@ func (r *request) setQueryOptions(q *QueryOptions) {
 	if q.Partition != "" {
 		r.params.Set("partition", q.Partition)
 	}
+	if q.Peer != "" {
+		r.params.Set("peer", q.Peer)
+	}
 	if q.Datacenter != "" {
 		r.params.Set("dc", q.Datacenter)
 	}
@@ -23,6 +23,7 @@ const (
 	ServiceIntentions  string = "service-intentions"
 	MeshConfig         string = "mesh"
 	ExportedServices   string = "exported-services"
+	JWTProvider        string = "jwt-provider"
 
 	ProxyConfigGlobal string = "global"
 	MeshConfigMesh    string = "mesh"
@@ -83,6 +84,23 @@ const (
 	ProxyModeDirect ProxyMode = "direct"
 )
 
+type MutualTLSMode string
+
+const (
+	// MutualTLSModeDefault represents no specific mode and should
+	// be used to indicate that a different layer of the configuration
+	// chain should take precedence
+	MutualTLSModeDefault MutualTLSMode = ""
+
+	// MutualTLSModeStrict represents that the proxy's public listener only
+	// accepts mTLS connections.
+	MutualTLSModeStrict MutualTLSMode = "strict"
+
+	// MutualTLSModePermissive represents that the proxy's public listener
+	// also accepts plaintext connections, from the permissive source CIDRs.
+	MutualTLSModePermissive MutualTLSMode = "permissive"
+)
+
 type TransparentProxyConfig struct {
 	// The port of the listener where outbound application traffic is being redirected to.
 	OutboundListenerPort int `json:",omitempty" alias:"outbound_listener_port"`
@@ -175,6 +193,10 @@ type UpstreamConfig struct {
 	// be monitored for removal from the load balancing pool.
 	PassiveHealthCheck *PassiveHealthCheck `json:",omitempty" alias:"passive_health_check"`
 
+	// ConnectionPool configures the idle timeouts, keepalives and request
+	// limits of the connections the local proxy makes to the upstream.
+	ConnectionPool *ConnectionPoolConfig `json:",omitempty" alias:"connection_pool"`
+
 	// MeshGatewayConfig controls how Mesh Gateways are configured and used
 	MeshGateway MeshGatewayConfig `json:",omitempty" alias:"mesh_gateway" `
 }
@@ -208,6 +230,119 @@ type UpstreamLimits struct {
 	MaxConcurrentRequests *int `alias:"max_concurrent_requests"`
 }
 
+// ConnectionPoolConfig describes how the local proxy manages the connections
+// to an upstream, or to the local service instance for the public listener.
+// Unset values keep the Envoy defaults.
+type ConnectionPoolConfig struct {
+	// TCPIdleTimeoutMs is the number of milliseconds after which a TCP
+	// connection without any traffic is closed. Envoy defaults to 1 hour, and 0
+	// disables the timeout. It only applies to the tcp protocol.
+	TCPIdleTimeoutMs *int `json:",omitempty" alias:"tcp_idle_timeout_ms"`
+
+	// HTTPIdleTimeoutMs is the number of milliseconds after which an HTTP
+	// connection without active requests is closed. Envoy defaults to 1 hour,
+	// and 0 disables the timeout.
+	HTTPIdleTimeoutMs *int `json:",omitempty" alias:"http_idle_timeout_ms"`
+
+	// MaxRequestsPerConnection is the maximum number of HTTP requests sent over
+	// a connection before it is closed. Unlimited if not set.
+	MaxRequestsPerConnection *int `json:",omitempty" alias:"max_requests_per_connection"`
+
+	// MaxConcurrentStreams is the maximum number of concurrent streams of an
+	// HTTP/2 connection. It only applies to the http2 and grpc protocols.
+	MaxConcurrentStreams *int `json:",omitempty" alias:"max_concurrent_streams"`
+
+	// TCPKeepalive enables TCP keepalives on the connections when set.
+	TCPKeepalive *TCPKeepaliveConfig `json:",omitempty" alias:"tcp_keepalive"`
+}
+
+// TCPKeepaliveConfig configures the TCP keepalives of a connection. Zero
+// values keep the defaults of the operating system.
+type TCPKeepaliveConfig struct {
+	Probes          uint32 `json:",omitempty"`
+	TimeSeconds     uint32 `json:",omitempty" alias:"time_seconds"`
+	IntervalSeconds uint32 `json:",omitempty" alias:"interval_seconds"`
+}
+
+// RateLimitConfig configures the rate at which the sidecar proxies of a
+// service accept inbound traffic. Limits are enforced by each proxy instance
+// independently.
+type RateLimitConfig struct {
+	// RequestsPerSecond is the default number of requests per second each
+	// proxy instance accepts. For services using the "tcp" protocol it limits
+	// new connections per second instead.
+	RequestsPerSecond int `json:",omitempty" alias:"requests_per_second"`
+
+	// Burst is the number of requests that can be accepted at once before the
+	// limit applies. Defaults to RequestsPerSecond.
+	Burst int `json:",omitempty"`
+
+	// Routes overrides the default limit for requests matching a path prefix.
+	Routes []RateLimitRoute `json:",omitempty"`
+
+	// Sources overrides the limit for requests from specific source services.
+	Sources []RateLimitSource `json:",omitempty"`
+}
+
+// RateLimitRoute is the rate limit applied to requests matching a path
+// prefix.
+type RateLimitRoute struct {
+	PathPrefix        string `json:",omitempty" alias:"path_prefix"`
+	RequestsPerSecond int    `json:",omitempty" alias:"requests_per_second"`
+	Burst             int    `json:",omitempty"`
+}
+
+// RateLimitSource is the rate limit applied to requests from one source
+// service.
+type RateLimitSource struct {
+	Name              string `json:",omitempty"`
+	Partition         string `json:",omitempty"`
+	Namespace         string `json:",omitempty"`
+	RequestsPerSecond int    `json:",omitempty" alias:"requests_per_second"`
+	Burst             int    `json:",omitempty"`
+}
+
+// ExtAuthzConfig configures an external authorization service that the
+// sidecar proxies of a service consult for every inbound request, or every new
+// connection for services using the "tcp" protocol. The service must be an
+// upstream of the proxies.
+type ExtAuthzConfig struct {
+	Service   string `json:",omitempty"`
+	Partition string `json:",omitempty"`
+	Namespace string `json:",omitempty"`
+
+	// Protocol is either "grpc" or "http". Defaults to "grpc".
+	Protocol string `json:",omitempty"`
+
+	// PathPrefix is prepended to the path of the request sent to an "http"
+	// authorization service.
+	PathPrefix string `json:",omitempty" alias:"path_prefix"`
+
+	// TimeoutMs defaults to 200ms.
+	TimeoutMs int `json:",omitempty" alias:"timeout_ms"`
+
+	// FailureModeAllow lets requests through when the authorization service
+	// cannot be reached or fails to answer in time.
+	FailureModeAllow bool `json:",omitempty" alias:"failure_mode_allow"`
+}
+
+// DestinationConfig declares a service as an external destination, reached
+// by transparent proxies through the terminating gateways the service is
+// linked to. Addresses are IP addresses or hostnames, and clients must use TLS
+// to reach a hostname.
+type DestinationConfig struct {
+	Addresses []string `json:",omitempty"`
+	Port      int      `json:",omitempty"`
+}
+
+// EnvoyExtension configures an extension patching the Envoy resources Consul
+// generates for a proxy, such as "builtin/lua" or "builtin/wasm". The
+// arguments depend on the extension.
+type EnvoyExtension struct {
+	Name      string
+	Arguments map[string]interface{} `bexpr:"-"`
+}
+
 type ServiceConfigEntry struct {
 	Kind             string
 	Name             string
@@ -220,6 +355,14 @@ type ServiceConfigEntry struct {
 	Expose           ExposeConfig            `json:",omitempty"`
 	ExternalSNI      string                  `json:",omitempty" alias:"external_sni"`
 	UpstreamConfig   *UpstreamConfiguration  `json:",omitempty" alias:"upstream_config"`
+	RateLimit        *RateLimitConfig        `json:",omitempty" alias:"rate_limit"`
+	ExtAuthz         *ExtAuthzConfig         `json:",omitempty" alias:"ext_authz"`
+	EnvoyExtensions  []EnvoyExtension        `json:",omitempty" alias:"envoy_extensions"`
+	Destination      *DestinationConfig      `json:",omitempty"`
+	ConnectionPool   *ConnectionPoolConfig   `json:",omitempty" alias:"connection_pool"`
+
+	MutualTLSMode         MutualTLSMode `json:",omitempty" alias:"mutual_tls_mode"`
+	PermissiveSourceCIDRs []string      `json:",omitempty" alias:"permissive_source_cidrs"`
 
 	Meta        map[string]string `json:",omitempty"`
 	CreateIndex uint64
@@ -244,12 +387,89 @@ type ProxyConfigEntry struct {
 	Config           map[string]interface{}  `json:",omitempty"`
 	MeshGateway      MeshGatewayConfig       `json:",omitempty" alias:"mesh_gateway"`
 	Expose           ExposeConfig            `json:",omitempty"`
+	AccessLogs       *AccessLogsConfig       `json:",omitempty" alias:"access_logs"`
+	Tracing          *TracingConfig          `json:",omitempty"`
+	EnvoyExtensions  []EnvoyExtension        `json:",omitempty" alias:"envoy_extensions"`
+
+	MutualTLSMode         MutualTLSMode `json:",omitempty" alias:"mutual_tls_mode"`
+	PermissiveSourceCIDRs []string      `json:",omitempty" alias:"permissive_source_cidrs"`
 
 	Meta        map[string]string `json:",omitempty"`
 	CreateIndex uint64
 	ModifyIndex uint64
 }
 
+type LogSinkType string
+
+const (
+	DefaultLogSinkType LogSinkType = ""
+	FileLogSinkType    LogSinkType = "file"
+	StdErrLogSinkType  LogSinkType = "stderr"
+	StdOutLogSinkType  LogSinkType = "stdout"
+)
+
+// AccessLogsConfig configures the access logs written by every Envoy proxy
+// of the mesh.
+type AccessLogsConfig struct {
+	// Enabled turns access logging on.
+	Enabled bool `json:",omitempty"`
+
+	// DisableListenerLogs turns off the logging of connections rejected by a
+	// listener before reaching any filter chain.
+	DisableListenerLogs bool `json:",omitempty" alias:"disable_listener_logs"`
+
+	// Type is where the logs are written. Defaults to "stdout".
+	Type LogSinkType `json:",omitempty"`
+
+	// Path is the file the logs are written to when Type is "file".
+	Path string `json:",omitempty"`
+
+	// JSONFormat is a JSON object whose values are Envoy format strings.
+	JSONFormat string `json:",omitempty" alias:"json_format"`
+
+	// TextFormat is an Envoy format string.
+	TextFormat string `json:",omitempty" alias:"text_format"`
+}
+
+type TracingProvider string
+
+const (
+	ZipkinTracingProvider        TracingProvider = "zipkin"
+	OpenTelemetryTracingProvider TracingProvider = "opentelemetry"
+)
+
+// TracingConfig configures the distributed tracing of the HTTP requests
+// handled by every Envoy proxy of the mesh.
+type TracingConfig struct {
+	// Provider is the tracer the spans are reported with.
+	Provider TracingProvider `json:",omitempty"`
+
+	// Collector is where the spans are reported.
+	Collector TracingCollector `json:",omitempty"`
+
+	// CollectorEndpoint is the HTTP path the spans are posted to with the
+	// "zipkin" provider. Defaults to "/api/v2/spans".
+	CollectorEndpoint string `json:",omitempty" alias:"collector_endpoint"`
+
+	// SamplingPercentage is the percentage of the requests not already
+	// sampled by the client that are traced.
+	SamplingPercentage float64 `json:",omitempty" alias:"sampling_percentage"`
+
+	// CustomTags are added to every span.
+	CustomTags map[string]string `json:",omitempty" alias:"custom_tags"`
+}
+
+// TracingCollector is either a service of the mesh, which must be an upstream
+// of the proxies, or a static address reachable by the proxies.
+type TracingCollector struct {
+	Service   string `json:",omitempty"`
+	Partition string `json:",omitempty"`
+	Namespace string `json:",omitempty"`
+
+	Address string `json:",omitempty"`
+	Port    int    `json:",omitempty"`
+}
+
 func (p *ProxyConfigEntry) GetKind() string            { return p.Kind }
 func (p *ProxyConfigEntry) GetName() string            { return p.Name }
 func (p *ProxyConfigEntry) GetPartition() string       { return p.Partition }
@@ -280,6 +500,8 @@ func makeConfigEntry(kind, name string) (ConfigEntry, error) {
 		return &MeshConfigEntry{}, nil
 	case ExportedServices:
 		return &ExportedServicesConfigEntry{Name: name}, nil
+	case JWTProvider:
+		return &JWTProviderConfigEntry{Name: name}, nil
 	default:
 		return nil, fmt.Errorf("invalid config entry kind: %s", kind)
 	}
@@ -33,6 +33,7 @@ type ServiceRoute struct {
 
 type ServiceRouteMatch struct {
 	HTTP *ServiceRouteHTTPMatch `json:",omitempty"`
+	GRPC *ServiceRouteGRPCMatch `json:",omitempty"`
 }
 
 type ServiceRouteHTTPMatch struct {
@@ -45,6 +46,12 @@ type ServiceRouteHTTPMatch struct {
 	Methods    []string                          `json:",omitempty"`
 }
 
+type ServiceRouteGRPCMatch struct {
+	Service string                        `json:",omitempty"`
+	Method  string                        `json:",omitempty"`
+	Header  []ServiceRouteHTTPMatchHeader `json:",omitempty"`
+}
+
 type ServiceRouteHTTPMatchHeader struct {
 	Name    string
 	Present bool   `json:",omitempty"`
@@ -74,20 +81,90 @@ type ServiceRouteDestination struct {
 	RetryOnStatusCodes    []uint32             `json:",omitempty" alias:"retry_on_status_codes"`
 	RequestHeaders        *HTTPHeaderModifiers `json:",omitempty" alias:"request_headers"`
 	ResponseHeaders       *HTTPHeaderModifiers `json:",omitempty" alias:"response_headers"`
+
+	RetryOnGRPCStatusCodes []string      `json:",omitempty" alias:"retry_on_grpc_status_codes"`
+	PerTryTimeout          time.Duration `json:",omitempty" alias:"per_try_timeout"`
+
+	FaultInjection *ServiceRouteFaultInjection `json:",omitempty" alias:"fault_injection"`
+	Mirror         *ServiceRouteMirror         `json:",omitempty"`
+}
+
+type ServiceRouteFaultInjection struct {
+	Delay *ServiceRouteFaultDelay `json:",omitempty"`
+	Abort *ServiceRouteFaultAbort `json:",omitempty"`
+}
+
+type ServiceRouteFaultDelay struct {
+	Duration   time.Duration `json:",omitempty"`
+	Percentage float32       `json:",omitempty"`
+}
+
+func (d *ServiceRouteFaultDelay) MarshalJSON() ([]byte, error) {
+	type Alias ServiceRouteFaultDelay
+	exported := &struct {
+		Duration string `json:",omitempty"`
+		*Alias
+	}{
+		Duration: d.Duration.String(),
+		Alias:    (*Alias)(d),
+	}
+	if d.Duration == 0 {
+		exported.Duration = ""
+	}
+
+	return json.Marshal(exported)
+}
+
+func (d *ServiceRouteFaultDelay) UnmarshalJSON(data []byte) error {
+	type Alias ServiceRouteFaultDelay
+	aux := &struct {
+		Duration string
+		*Alias
+	}{
+		Alias: (*Alias)(d),
+	}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	var err error
+	if aux.Duration != "" {
+		if d.Duration, err = time.ParseDuration(aux.Duration); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type ServiceRouteFaultAbort struct {
+	HTTPStatus uint32  `json:",omitempty" alias:"http_status"`
+	Percentage float32 `json:",omitempty"`
+}
+
+type ServiceRouteMirror struct {
+	Service       string  `json:",omitempty"`
+	ServiceSubset string  `json:",omitempty" alias:"service_subset"`
+	Namespace     string  `json:",omitempty"`
+	Partition     string  `json:",omitempty"`
+	Percentage    float32 `json:",omitempty"`
 }
 
 func (e *ServiceRouteDestination) MarshalJSON() ([]byte, error) {
 	type Alias ServiceRouteDestination
 	exported := &struct {
 		RequestTimeout string `json:",omitempty"`
+		PerTryTimeout  string `json:",omitempty"`
 		*Alias
 	}{
 		RequestTimeout: e.RequestTimeout.String(),
+		PerTryTimeout:  e.PerTryTimeout.String(),
 		Alias:          (*Alias)(e),
 	}
 	if e.RequestTimeout == 0 {
 		exported.RequestTimeout = ""
 	}
+	if e.PerTryTimeout == 0 {
+		exported.PerTryTimeout = ""
+	}
 
 	return json.Marshal(exported)
 }
@@ -96,6 +173,7 @@ func (e *ServiceRouteDestination) UnmarshalJSON(data []byte) error {
 	type Alias ServiceRouteDestination
 	aux := &struct {
 		RequestTimeout string
+		PerTryTimeout  string
 		*Alias
 	}{
 		Alias: (*Alias)(e),
@@ -109,6 +187,11 @@ func (e *ServiceRouteDestination) UnmarshalJSON(data []byte) error {
 			return err
 		}
 	}
+	if aux.PerTryTimeout != "" {
+		if e.PerTryTimeout, err = time.ParseDuration(aux.PerTryTimeout); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -159,6 +242,15 @@ type ServiceResolverConfigEntry struct {
 	// issuing requests to this upstream service.
 	LoadBalancer *LoadBalancer `json:",omitempty" alias:"load_balancer"`
 
+	// OutlierDetection determines when instances of this service are ejected
+	// from the load balancing pool of its downstreams.
+	OutlierDetection *OutlierDetection `json:",omitempty" alias:"outlier_detection"`
+
+	// PrioritizeByLocality determines whether the proxies of downstream
+	// services prefer the instances of this service running in their own
+	// zone and region.
+	PrioritizeByLocality *ServiceResolverPrioritizeByLocality `json:",omitempty" alias:"prioritize_by_locality"`
+
 	Meta        map[string]string `json:",omitempty"`
 	CreateIndex uint64
 	ModifyIndex uint64
@@ -229,6 +321,58 @@ type ServiceResolverFailover struct {
 	Datacenters []string `json:",omitempty"`
 }
 
+// ServiceResolverPrioritizeByLocality configures locality aware load balancing
+// using the locality-region and locality-zone service or node meta of the
+// instances and of the downstream proxy.
+type ServiceResolverPrioritizeByLocality struct {
+	// Mode is either "none", the default, or "failover".
+	Mode string `json:",omitempty"`
+}
+
+// OutlierDetection determines when instances of a service are ejected from the
+// load balancing pool of its downstreams.
+type OutlierDetection struct {
+	// Interval between ejection analysis sweeps. Defaults to 10s.
+	Interval time.Duration `json:",omitempty"`
+
+	// Consecutive5xx is the number of consecutive 5xx responses, or connection
+	// failures for TCP services, after which an instance is ejected. Defaults
+	// to 5.
+	Consecutive5xx uint32 `json:",omitempty" alias:"consecutive_5xx"`
+
+	// ConsecutiveGatewayFailure is the number of consecutive 502, 503 or 504
+	// responses, or connection failures, after which an instance is ejected.
+	// Disabled if zero.
+	ConsecutiveGatewayFailure uint32 `json:",omitempty" alias:"consecutive_gateway_failure"`
+
+	// SuccessRate ejects the instances whose success rate is too low compared
+	// to the other instances. Disabled if nil.
+	SuccessRate *OutlierDetectionSuccessRate `json:",omitempty" alias:"success_rate"`
+
+	// BaseEjectionTime is how long an instance is ejected for, multiplied by
+	// the number of times it was ejected. Defaults to 30s.
+	BaseEjectionTime time.Duration `json:",omitempty" alias:"base_ejection_time"`
+
+	// MaxEjectionPercent is the maximum percentage of the instances that can
+	// be ejected at once. Defaults to 10.
+	MaxEjectionPercent uint32 `json:",omitempty" alias:"max_ejection_percent"`
+}
+
+// OutlierDetectionSuccessRate configures success rate based ejection.
+type OutlierDetectionSuccessRate struct {
+	// MinimumHosts is the number of instances that must have enough requests
+	// for success rates to be computed. Defaults to 5.
+	MinimumHosts uint32 `json:",omitempty" alias:"minimum_hosts"`
+
+	// RequestVolume is the number of requests an instance must receive during
+	// an interval to be included. Defaults to 100.
+	RequestVolume uint32 `json:",omitempty" alias:"request_volume"`
+
+	// StdevFactor ejects the instances whose success rate is below the mean
+	// minus StdevFactor times the standard deviation. Defaults to 1.9.
+	StdevFactor float64 `json:",omitempty" alias:"stdev_factor"`
+}
+
 // LoadBalancer determines the load balancing policy and configuration for services
 // issuing requests to this upstream service.
 type LoadBalancer struct {
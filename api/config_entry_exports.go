@@ -44,9 +44,13 @@ type ExportedService struct {
 }
 
 // ServiceConsumer represents a downstream consumer of the service to be exported.
+// Exactly one of Partition or PeerName must be set.
 type ServiceConsumer struct {
 	// Partition is the admin partition to export the service to.
 	Partition string
+
+	// PeerName is the name of the cluster peer to export the service to.
+	PeerName string `json:",omitempty" alias:"peer_name"`
 }
 
 func (e *ExportedServicesConfigEntry) GetKind() string            { return ExportedServices }
@@ -1,5 +1,7 @@
 package api
 
+import "time"
+
 // IngressGatewayConfigEntry manages the configuration for an ingress service
 // with the given name.
 type IngressGatewayConfigEntry struct {
@@ -76,12 +78,19 @@ type IngressListener struct {
 	// Services declares the set of services to which the listener forwards
 	// traffic.
 	//
-	// For "tcp" protocol listeners, only a single service is allowed.
+	// For "tcp" protocol listeners, only a single service is allowed unless
+	// the listener terminates TLS or uses TLSPassthrough, in which case
+	// connections are routed to a service by SNI.
 	// For "http" listeners, multiple services can be declared.
 	Services []IngressService
 
 	// TLS allows specifying some TLS configuration per listener.
 	TLS *GatewayTLSConfig `json:",omitempty"`
+
+	// TLSPassthrough makes a "tcp" listener forward TLS connections to its
+	// services without terminating them. Connections are routed to a service
+	// when their SNI matches one of its Hosts.
+	TLSPassthrough bool `json:",omitempty" alias:"tls_passthrough"`
 }
 
 // IngressService manages configuration for services that are exposed to
@@ -98,16 +107,17 @@ type IngressService struct {
 	Name string
 
 	// Hosts is a list of hostnames which should be associated to this service on
-	// the defined listener. Only allowed on layer 7 protocols, this will be used
-	// to route traffic to the service by matching the Host header of the HTTP
-	// request.
+	// the defined listener. On layer 7 protocols, this will be used to route
+	// traffic to the service by matching the Host header of the HTTP request.
+	// On "tcp" listeners that terminate TLS or use TLSPassthrough, it is
+	// matched against the SNI of the connection instead.
 	//
 	// If a host is provided for a service that also has a wildcard specifier
 	// defined, the host will override the wildcard-specifier-provided
 	// "<service-name>.*" domain for that listener.
 	//
 	// This cannot be specified when using the wildcard specifier, "*", or when
-	// using a "tcp" listener.
+	// using a "tcp" listener that doesn't see the SNI of connections.
 	Hosts []string
 
 	// Namespace is the namespace where the service is located.
@@ -124,6 +134,55 @@ type IngressService struct {
 	// Allow HTTP header manipulation to be configured.
 	RequestHeaders  *HTTPHeaderModifiers `json:",omitempty" alias:"request_headers"`
 	ResponseHeaders *HTTPHeaderModifiers `json:",omitempty" alias:"response_headers"`
+
+	// CORS is the cross-origin resource sharing policy applied to requests
+	// for this service. Only supported on layer 7 listeners.
+	CORS *IngressCORSConfig `json:",omitempty"`
+
+	// MaxRequestBytes limits the size of request bodies sent to this service.
+	// Larger requests are rejected with a 413 status. Zero means no limit. Only
+	// supported on layer 7 listeners.
+	MaxRequestBytes uint32 `json:",omitempty" alias:"max_request_bytes"`
+
+	// JWT requires requests for this service to carry a valid JSON Web Token
+	// issued by one of the given providers. Only supported on layer 7
+	// listeners.
+	JWT *IngressJWTRequirement `json:",omitempty"`
+}
+
+// IngressCORSConfig is the cross-origin resource sharing policy of an ingress
+// service.
+type IngressCORSConfig struct {
+	// AllowOrigins are the origins allowed to make cross-origin requests, such
+	// as "https://example.com". The special value "*" allows any origin.
+	AllowOrigins []string `json:",omitempty" alias:"allow_origins"`
+
+	// AllowMethods, AllowHeaders and ExposeHeaders are returned in the
+	// matching Access-Control-* response headers.
+	AllowMethods  []string `json:",omitempty" alias:"allow_methods"`
+	AllowHeaders  []string `json:",omitempty" alias:"allow_headers"`
+	ExposeHeaders []string `json:",omitempty" alias:"expose_headers"`
+
+	// MaxAge is how long browsers may cache the result of a preflight
+	// request.
+	MaxAge time.Duration `json:",omitempty" alias:"max_age"`
+
+	// AllowCredentials allows cross-origin requests to include credentials
+	// such as cookies.
+	AllowCredentials bool `json:",omitempty" alias:"allow_credentials"`
+}
+
+// IngressJWTRequirement lists the JWT providers trusted to issue tokens for an
+// ingress service. A request is accepted if its token is valid for any of
+// them.
+type IngressJWTRequirement struct {
+	Providers []*IngressJWTProvider `json:",omitempty"`
+}
+
+// IngressJWTProvider references a jwt-provider config entry.
+type IngressJWTProvider struct {
+	// Name of the jwt-provider config entry.
+	Name string
 }
 
 func (i *IngressGatewayConfigEntry) GetKind() string            { return i.Kind }
@@ -43,6 +43,7 @@ func (e *ServiceIntentionsConfigEntry) GetModifyIndex() uint64     { return e.Mo
 type IntentionPermission struct {
 	Action IntentionAction
 	HTTP   *IntentionHTTPPermission `json:",omitempty"`
+	JWT    *IntentionJWTRequirement `json:",omitempty"`
 }
 
 type IntentionHTTPPermission struct {
@@ -64,3 +65,23 @@ type IntentionHTTPHeaderPermission struct {
 	Regex   string `json:",omitempty"`
 	Invert  bool   `json:",omitempty"`
 }
+
+// IntentionJWTRequirement requires a request to carry a valid token from at
+// least one of the listed JWT providers.
+type IntentionJWTRequirement struct {
+	Providers []*IntentionJWTProvider `json:",omitempty"`
+}
+
+// IntentionJWTProvider refers to a jwt-provider config entry and the claims a
+// token it issued must contain.
+type IntentionJWTProvider struct {
+	Name         string
+	VerifyClaims []*IntentionJWTClaimVerification `json:",omitempty" alias:"verify_claims"`
+}
+
+// IntentionJWTClaimVerification requires the claim found at Path in the token
+// payload to be equal to Value.
+type IntentionJWTClaimVerification struct {
+	Path  []string `json:",omitempty"`
+	Value string   `json:",omitempty"`
+}
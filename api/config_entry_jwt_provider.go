@@ -0,0 +1,82 @@
+package api
+
+import "encoding/json"
+
+// JWTProviderConfigEntry describes an issuer of end-user JSON Web Tokens that
+// sidecar proxies can validate. Intentions refer to providers by name.
+type JWTProviderConfigEntry struct {
+	// Name is the name the intentions use to refer to this provider.
+	Name string `json:",omitempty"`
+
+	// Issuer is the value the "iss" claim of a token must match.
+	Issuer string `json:",omitempty"`
+
+	// Audiences restricts the tokens accepted to those whose "aud" claim
+	// contains at least one of these values.
+	Audiences []string `json:",omitempty"`
+
+	// JSONWebKeySet holds the public keys used to verify the signature of a
+	// token.
+	JSONWebKeySet *JSONWebKeySet `json:",omitempty" alias:"json_web_key_set"`
+
+	// ClockSkewSeconds is the amount of clock skew tolerated when checking the
+	// "exp" and "nbf" claims.
+	ClockSkewSeconds int `json:",omitempty" alias:"clock_skew_seconds"`
+
+	Meta map[string]string `json:",omitempty"`
+
+	// Partition is the partition the JWTProviderConfigEntry applies to.
+	// Partitioning is a Consul Enterprise feature.
+	Partition string `json:",omitempty"`
+
+	// Namespace is the namespace the JWTProviderConfigEntry applies to.
+	// Namespacing is a Consul Enterprise feature.
+	Namespace string `json:",omitempty"`
+
+	// CreateIndex is the Raft index this entry was created at. This is a
+	// read-only field.
+	CreateIndex uint64
+
+	// ModifyIndex is used for the Check-And-Set operations and can also be fed
+	// back into the WaitIndex of the QueryOptions in order to perform blocking
+	// queries.
+	ModifyIndex uint64
+}
+
+// JSONWebKeySet describes where the key set of a JWT provider comes from.
+type JSONWebKeySet struct {
+	Local *LocalJWKS `json:",omitempty"`
+}
+
+// LocalJWKS is a key set made available to the proxy without fetching it over
+// the network. Exactly one of JWKS or Filename must be set.
+type LocalJWKS struct {
+	// JWKS is the key set inline, as a JSON document.
+	JWKS string `json:",omitempty"`
+
+	// Filename is the path to a file containing the key set on the host of
+	// each proxy.
+	Filename string `json:",omitempty"`
+}
+
+func (e *JWTProviderConfigEntry) GetKind() string            { return JWTProvider }
+func (e *JWTProviderConfigEntry) GetName() string            { return e.Name }
+func (e *JWTProviderConfigEntry) GetPartition() string       { return e.Partition }
+func (e *JWTProviderConfigEntry) GetNamespace() string       { return e.Namespace }
+func (e *JWTProviderConfigEntry) GetMeta() map[string]string { return e.Meta }
+func (e *JWTProviderConfigEntry) GetCreateIndex() uint64     { return e.CreateIndex }
+func (e *JWTProviderConfigEntry) GetModifyIndex() uint64     { return e.ModifyIndex }
+
+// MarshalJSON adds the Kind field so that the JSON can be decoded back into the
+// correct type.
+func (e *JWTProviderConfigEntry) MarshalJSON() ([]byte, error) {
+	type Alias JWTProviderConfigEntry
+	source := &struct {
+		Kind string
+		*Alias
+	}{
+		Kind:  JWTProvider,
+		Alias: (*Alias)(e),
+	}
+	return json.Marshal(source)
+}
@@ -391,7 +391,34 @@ func TestDecodeConfigEntry(t *testing.T) {
 				"TransparentProxy": {
 					"OutboundListenerPort": 808,
 					"DialedDirectly": true
-				}
+				},
+				"AccessLogs": {
+					"Enabled": true,
+					"DisableListenerLogs": true,
+					"Type": "file",
+					"Path": "/var/log/envoy/access.log",
+					"TextFormat": "%RESPONSE_CODE%"
+				},
+				"Tracing": {
+					"Provider": "zipkin",
+					"Collector": {
+						"Address": "zipkin.service.consul",
+						"Port": 9411
+					},
+					"SamplingPercentage": 2.5,
+					"CustomTags": {
+						"env": "prod"
+					}
+				},
+				"EnvoyExtensions": [
+					{
+						"Name": "builtin/lua",
+						"Arguments": {
+							"Listener": "inbound",
+							"Script": "function envoy_on_request(h) end"
+						}
+					}
+				]
 			}
 			`,
 			expect: &ProxyConfigEntry{
@@ -416,6 +443,33 @@ func TestDecodeConfigEntry(t *testing.T) {
 					OutboundListenerPort: 808,
 					DialedDirectly:       true,
 				},
+				AccessLogs: &AccessLogsConfig{
+					Enabled:             true,
+					DisableListenerLogs: true,
+					Type:                FileLogSinkType,
+					Path:                "/var/log/envoy/access.log",
+					TextFormat:          "%RESPONSE_CODE%",
+				},
+				Tracing: &TracingConfig{
+					Provider: ZipkinTracingProvider,
+					Collector: TracingCollector{
+						Address: "zipkin.service.consul",
+						Port:    9411,
+					},
+					SamplingPercentage: 2.5,
+					CustomTags: map[string]string{
+						"env": "prod",
+					},
+				},
+				EnvoyExtensions: []EnvoyExtension{
+					{
+						Name: "builtin/lua",
+						Arguments: map[string]interface{}{
+							"Listener": "inbound",
+							"Script":   "function envoy_on_request(h) end",
+						},
+					},
+				},
 			},
 		},
 		{
@@ -692,6 +746,104 @@ func TestDecodeConfigEntry(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "service-router: fault injection and mirror",
+			body: `
+			{
+				"Kind": "service-router",
+				"Name": "main",
+				"Routes": [
+					{
+						"Destination": {
+							"FaultInjection": {
+								"Delay": {
+									"Duration": "500ms",
+									"Percentage": 10
+								},
+								"Abort": {
+									"HTTPStatus": 503,
+									"Percentage": 0.5
+								}
+							},
+							"Mirror": {
+								"Service": "shadow",
+								"ServiceSubset": "v2",
+								"Percentage": 25
+							}
+						}
+					}
+				]
+			}
+			`,
+			expect: &ServiceRouterConfigEntry{
+				Kind: "service-router",
+				Name: "main",
+				Routes: []ServiceRoute{
+					{
+						Destination: &ServiceRouteDestination{
+							FaultInjection: &ServiceRouteFaultInjection{
+								Delay: &ServiceRouteFaultDelay{
+									Duration:   500 * time.Millisecond,
+									Percentage: 10,
+								},
+								Abort: &ServiceRouteFaultAbort{
+									HTTPStatus: 503,
+									Percentage: 0.5,
+								},
+							},
+							Mirror: &ServiceRouteMirror{
+								Service:       "shadow",
+								ServiceSubset: "v2",
+								Percentage:    25,
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "service-router: grpc match and retries",
+			body: `
+			{
+				"Kind": "service-router",
+				"Name": "main",
+				"Routes": [
+					{
+						"Match": {
+							"GRPC": {
+								"Service": "helloworld.Greeter",
+								"Method": "SayHello"
+							}
+						},
+						"Destination": {
+							"NumRetries": 3,
+							"RetryOnGRPCStatusCodes": ["UNAVAILABLE", "RESOURCE_EXHAUSTED"],
+							"PerTryTimeout": "2s"
+						}
+					}
+				]
+			}
+			`,
+			expect: &ServiceRouterConfigEntry{
+				Kind: "service-router",
+				Name: "main",
+				Routes: []ServiceRoute{
+					{
+						Match: &ServiceRouteMatch{
+							GRPC: &ServiceRouteGRPCMatch{
+								Service: "helloworld.Greeter",
+								Method:  "SayHello",
+							},
+						},
+						Destination: &ServiceRouteDestination{
+							NumRetries:             3,
+							RetryOnGRPCStatusCodes: []string{"UNAVAILABLE", "RESOURCE_EXHAUSTED"},
+							PerTryTimeout:          2 * time.Second,
+						},
+					},
+				},
+			},
+		},
 		{
 			name: "service-splitter: kitchen sink",
 			body: `
@@ -943,6 +1095,62 @@ func TestDecodeConfigEntry(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "service-resolver: outlier detection",
+			body: `
+			{
+				"Kind": "service-resolver",
+				"Name": "main",
+				"OutlierDetection": {
+					"Interval": "5s",
+					"Consecutive5xx": 3,
+					"ConsecutiveGatewayFailure": 2,
+					"SuccessRate": {
+						"MinimumHosts": 4,
+						"RequestVolume": 50,
+						"StdevFactor": 1.5
+					},
+					"BaseEjectionTime": "45s",
+					"MaxEjectionPercent": 50
+				}
+			}
+			`,
+			expect: &ServiceResolverConfigEntry{
+				Kind: "service-resolver",
+				Name: "main",
+				OutlierDetection: &OutlierDetection{
+					Interval:                  5 * time.Second,
+					Consecutive5xx:            3,
+					ConsecutiveGatewayFailure: 2,
+					SuccessRate: &OutlierDetectionSuccessRate{
+						MinimumHosts:  4,
+						RequestVolume: 50,
+						StdevFactor:   1.5,
+					},
+					BaseEjectionTime:   45 * time.Second,
+					MaxEjectionPercent: 50,
+				},
+			},
+		},
+		{
+			name: "service-resolver: prioritize by locality",
+			body: `
+			{
+				"Kind": "service-resolver",
+				"Name": "main",
+				"PrioritizeByLocality": {
+					"Mode": "failover"
+				}
+			}
+			`,
+			expect: &ServiceResolverConfigEntry{
+				Kind: "service-resolver",
+				Name: "main",
+				PrioritizeByLocality: &ServiceResolverPrioritizeByLocality{
+					Mode: "failover",
+				},
+			},
+		},
 		{
 			// TODO(rb): test SDS stuff here in both places (global/service)
 			name: "ingress-gateway",
@@ -974,7 +1182,21 @@ func TestDecodeConfigEntry(t *testing.T) {
 								"Partition": "bar"
 							},
 							{
-								"Name": "db"
+								"Name": "db",
+								"CORS": {
+									"AllowOrigins": ["https://example.com"],
+									"AllowMethods": ["GET"],
+									"MaxAge": "1h",
+									"AllowCredentials": true
+								},
+								"MaxRequestBytes": 1048576,
+								"JWT": {
+									"Providers": [
+										{
+											"Name": "okta"
+										}
+									]
+								}
 							}
 						]
 					},
@@ -986,6 +1208,21 @@ func TestDecodeConfigEntry(t *testing.T) {
 								"Name": "mysql"
 							}
 						]
+					},
+					{
+						"Port": 9443,
+						"Protocol": "tcp",
+						"TLSPassthrough": true,
+						"Services": [
+							{
+								"Name": "postgres",
+								"Hosts": ["postgres.example.com"]
+							},
+							{
+								"Name": "redis",
+								"Hosts": ["redis.example.com"]
+							}
+						]
 					}
 				]
 			}
@@ -1018,6 +1255,16 @@ func TestDecodeConfigEntry(t *testing.T) {
 							},
 							{
 								Name: "db",
+								CORS: &IngressCORSConfig{
+									AllowOrigins:     []string{"https://example.com"},
+									AllowMethods:     []string{"GET"},
+									MaxAge:           time.Hour,
+									AllowCredentials: true,
+								},
+								MaxRequestBytes: 1048576,
+								JWT: &IngressJWTRequirement{
+									Providers: []*IngressJWTProvider{{Name: "okta"}},
+								},
 							},
 						},
 					},
@@ -1030,6 +1277,21 @@ func TestDecodeConfigEntry(t *testing.T) {
 							},
 						},
 					},
+					{
+						Port:           9443,
+						Protocol:       "tcp",
+						TLSPassthrough: true,
+						Services: []IngressService{
+							{
+								Name:  "postgres",
+								Hosts: []string{"postgres.example.com"},
+							},
+							{
+								Name:  "redis",
+								Hosts: []string{"redis.example.com"},
+							},
+						},
+					},
 				},
 			},
 		},
@@ -1309,6 +1571,42 @@ func TestDecodeConfigEntry(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "jwt-provider",
+			body: `
+			{
+				"Kind": "jwt-provider",
+				"Name": "okta",
+				"Meta" : {
+					"foo": "bar",
+					"gir": "zim"
+				},
+				"Issuer": "https://example.okta.com",
+				"Audiences": ["api", "web"],
+				"JSONWebKeySet": {
+					"Local": {
+						"Filename": "/etc/consul/okta-jwks.json"
+					}
+				},
+				"ClockSkewSeconds": 30
+			}
+			`,
+			expect: &JWTProviderConfigEntry{
+				Name: "okta",
+				Meta: map[string]string{
+					"foo": "bar",
+					"gir": "zim",
+				},
+				Issuer:    "https://example.okta.com",
+				Audiences: []string{"api", "web"},
+				JSONWebKeySet: &JSONWebKeySet{
+					Local: &LocalJWKS{
+						Filename: "/etc/consul/okta-jwks.json",
+					},
+				},
+				ClockSkewSeconds: 30,
+			},
+		},
 	} {
 		tc := tc
 
@@ -37,11 +37,12 @@ type CAConfig struct {
 
 // CommonCAProviderConfig is the common options available to all CA providers.
 type CommonCAProviderConfig struct {
-	LeafCertTTL      time.Duration
-	RootCertTTL      time.Duration
-	SkipValidate     bool
-	CSRMaxPerSecond  float32
-	CSRMaxConcurrent int
+	LeafCertTTL        time.Duration
+	RootCertTTL        time.Duration
+	RootRotationPeriod time.Duration
+	SkipValidate       bool
+	CSRMaxPerSecond    float32
+	CSRMaxConcurrent   int
 }
 
 // ConsulCAProviderConfig is the config for the built-in Consul CA provider.
@@ -196,3 +197,116 @@ func (h *Connect) CASetConfig(conf *CAConfig, q *WriteOptions) (*WriteMeta, erro
 	wm.RequestTime = rtt
 	return wm, nil
 }
+
+// CARevocation revokes either a single leaf certificate identified by its
+// serial number, or every certificate issued for a SPIFFE ID.
+type CARevocation struct {
+	ID string
+
+	// SerialNumber is the serial number of the revoked certificate, encoded
+	// in hex optionally separated by :. Datacenter is the datacenter whose
+	// CA issued the certificate and defaults to the datacenter of the request.
+	SerialNumber string `json:",omitempty"`
+	Datacenter   string `json:",omitempty"`
+
+	// SpiffeID is the SPIFFE ID of the revoked service identity.
+	SpiffeID string `json:",omitempty"`
+
+	// Reason is a human-friendly explanation of the revocation.
+	Reason string `json:",omitempty"`
+
+	// RevokedAt is the time the revocation was made and ExpiresAt is the time
+	// after which it no longer affects any valid certificate.
+	RevokedAt time.Time
+	ExpiresAt time.Time
+
+	CreateIndex uint64
+	ModifyIndex uint64
+}
+
+// CARevocationList is a certificate revocation list signed by the CA of a
+// datacenter.
+type CARevocationList struct {
+	SigningKeyID  string
+	Datacenter    string
+	PEM           string
+	SerialNumbers []string
+	NextUpdate    time.Time
+
+	CreateIndex uint64
+	ModifyIndex uint64
+}
+
+// CARevocations is the structure for the results of listing revocations.
+type CARevocations struct {
+	Revocations     []*CARevocation
+	RevocationLists []*CARevocationList
+}
+
+// CARevocations queries the list of revoked certificates and the revocation
+// lists that enforce them.
+func (h *Connect) CARevocations(q *QueryOptions) (*CARevocations, *QueryMeta, error) {
+	r := h.c.newRequest("GET", "/v1/connect/ca/crl")
+	r.setQueryOptions(q)
+	rtt, resp, err := h.c.doRequest(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer closeResponseBody(resp)
+	if err := requireOK(resp); err != nil {
+		return nil, nil, err
+	}
+
+	qm := &QueryMeta{}
+	parseQueryMeta(resp, qm)
+	qm.RequestTime = rtt
+
+	var out CARevocations
+	if err := decodeBody(resp, &out); err != nil {
+		return nil, nil, err
+	}
+	return &out, qm, nil
+}
+
+// CARevoke revokes a leaf certificate by serial number, or every certificate
+// for a SPIFFE ID, and returns the ID of the revocation.
+func (h *Connect) CARevoke(revocation *CARevocation, q *WriteOptions) (string, *WriteMeta, error) {
+	r := h.c.newRequest("PUT", "/v1/connect/ca/crl")
+	r.setWriteOptions(q)
+	r.obj = revocation
+	rtt, resp, err := h.c.doRequest(r)
+	if err != nil {
+		return "", nil, err
+	}
+	defer closeResponseBody(resp)
+	if err := requireOK(resp); err != nil {
+		return "", nil, err
+	}
+
+	wm := &WriteMeta{}
+	wm.RequestTime = rtt
+
+	var out struct{ ID string }
+	if err := decodeBody(resp, &out); err != nil {
+		return "", nil, err
+	}
+	return out.ID, wm, nil
+}
+
+// CARevocationDelete removes the revocation with the given ID.
+func (h *Connect) CARevocationDelete(id string, q *WriteOptions) (*WriteMeta, error) {
+	r := h.c.newRequest("DELETE", "/v1/connect/ca/crl/"+id)
+	r.setWriteOptions(q)
+	rtt, resp, err := h.c.doRequest(r)
+	if err != nil {
+		return nil, err
+	}
+	defer closeResponseBody(resp)
+	if err := requireOK(resp); err != nil {
+		return nil, err
+	}
+
+	wm := &WriteMeta{}
+	wm.RequestTime = rtt
+	return wm, nil
+}
@@ -103,3 +103,35 @@ func TestAPI_ConnectCAConfig_get_set(t *testing.T) {
 		require.Equal(r, "bar", updated.State["foo"])
 	})
 }
+
+func TestAPI_ConnectCARevocations(t *testing.T) {
+	t.Parallel()
+
+	c, s := makeClient(t)
+	defer s.Stop()
+
+	s.WaitForActiveCARoot(t)
+
+	connect := c.Connect()
+	id, _, err := connect.CARevoke(&CARevocation{
+		SpiffeID: "spiffe://11111111-2222-3333-4444-555555555555.consul/ns/default/dc/dc1/svc/web",
+		Reason:   "decommissioned",
+	}, nil)
+	require.NoError(t, err)
+	require.NotEmpty(t, id)
+
+	list, meta, err := connect.CARevocations(nil)
+	require.NoError(t, err)
+	require.NotZero(t, meta.LastIndex)
+	require.Len(t, list.Revocations, 1)
+	require.Equal(t, id, list.Revocations[0].ID)
+	require.Equal(t, "decommissioned", list.Revocations[0].Reason)
+	require.True(t, list.Revocations[0].ExpiresAt.After(list.Revocations[0].RevokedAt))
+
+	_, err = connect.CARevocationDelete(id, nil)
+	require.NoError(t, err)
+
+	list, _, err = connect.CARevocations(nil)
+	require.NoError(t, err)
+	require.Empty(t, list.Revocations)
+}
@@ -60,6 +60,54 @@ func (d *DiscoveryChain) Get(name string, opts *DiscoveryChainOptions, q *QueryO
 	return &out, qm, nil
 }
 
+// Simulate compiles the discovery chain for the named service as if the given
+// config entries had been written, without writing them. The response
+// contains both the simulated and the current chain along with a human
+// readable explanation of the simulated chain and a diff from the current one.
+func (d *DiscoveryChain) Simulate(name string, entries []ConfigEntry, opts *DiscoveryChainOptions, q *QueryOptions) (*DiscoveryChainSimulateResponse, *QueryMeta, error) {
+	if name == "" {
+		return nil, nil, fmt.Errorf("Name parameter must not be empty")
+	}
+
+	r := d.c.newRequest("POST", fmt.Sprintf("/v1/discovery-chain/%s", name))
+	r.setQueryOptions(q)
+	r.params.Set("simulate", "")
+
+	body := struct {
+		DiscoveryChainOptions
+		Entries []ConfigEntry
+	}{
+		Entries: entries,
+	}
+	if opts != nil {
+		if opts.EvaluateInDatacenter != "" {
+			r.params.Set("compile-dc", opts.EvaluateInDatacenter)
+		}
+		body.DiscoveryChainOptions = *opts
+	}
+	r.obj = body
+
+	rtt, resp, err := d.c.doRequest(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer closeResponseBody(resp)
+	if err := requireOK(resp); err != nil {
+		return nil, nil, err
+	}
+
+	qm := &QueryMeta{}
+	parseQueryMeta(resp, qm)
+	qm.RequestTime = rtt
+
+	var out DiscoveryChainSimulateResponse
+	if err := decodeBody(resp, &out); err != nil {
+		return nil, nil, err
+	}
+
+	return &out, qm, nil
+}
+
 type DiscoveryChainOptions struct {
 	EvaluateInDatacenter string `json:"-"`
 
@@ -95,6 +143,24 @@ type DiscoveryChainResponse struct {
 	Chain *CompiledDiscoveryChain
 }
 
+type DiscoveryChainSimulateResponse struct {
+	// Chain is the discovery chain compiled with the simulated config entries.
+	Chain *CompiledDiscoveryChain
+
+	// CurrentChain is the discovery chain compiled with only the config
+	// entries currently stored.
+	CurrentChain *CompiledDiscoveryChain
+
+	// Explain is a human readable tree of the routes, splits, resolvers and
+	// failover targets of Chain.
+	Explain string
+
+	// Diff is a line based diff from the explanation of CurrentChain to
+	// Explain. Added lines are prefixed with "+ " and removed lines with "- ".
+	// It is empty if simulating the config entries does not change the chain.
+	Diff string
+}
+
 type CompiledDiscoveryChain struct {
 	ServiceName string
 	Namespace   string
@@ -176,10 +242,11 @@ type DiscoverySplit struct {
 
 // compiled form of ServiceResolverConfigEntry
 type DiscoveryResolver struct {
-	Default        bool
-	ConnectTimeout time.Duration
-	Target         string
-	Failover       *DiscoveryFailover
+	Default          bool
+	ConnectTimeout   time.Duration
+	Target           string
+	Failover         *DiscoveryFailover
+	OutlierDetection *OutlierDetection `json:",omitempty"`
 }
 
 func (r *DiscoveryResolver) MarshalJSON() ([]byte, error) {
@@ -232,6 +299,7 @@ type DiscoveryTarget struct {
 	Service       string
 	ServiceSubset string
 	Namespace     string
+	Partition     string
 	Datacenter    string
 
 	MeshGateway    MeshGatewayConfig
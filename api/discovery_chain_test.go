@@ -50,6 +50,7 @@ func TestAPI_DiscoveryChain_Get(t *testing.T) {
 						ID:             "web.default.default.dc1",
 						Service:        "web",
 						Namespace:      "default",
+						Partition:      "default",
 						Datacenter:     "dc1",
 						ConnectTimeout: 5 * time.Second,
 						SNI:            "web.default.dc1.internal." + testClusterID + ".consul",
@@ -92,6 +93,7 @@ func TestAPI_DiscoveryChain_Get(t *testing.T) {
 						ID:             "web.default.default.dc2",
 						Service:        "web",
 						Namespace:      "default",
+						Partition:      "default",
 						Datacenter:     "dc2",
 						ConnectTimeout: 5 * time.Second,
 						SNI:            "web.default.dc2.internal." + testClusterID + ".consul",
@@ -139,6 +141,7 @@ func TestAPI_DiscoveryChain_Get(t *testing.T) {
 						ID:             "web.default.default.dc1",
 						Service:        "web",
 						Namespace:      "default",
+						Partition:      "default",
 						Datacenter:     "dc1",
 						ConnectTimeout: 33 * time.Second,
 						SNI:            "web.default.dc1.internal." + testClusterID + ".consul",
@@ -185,6 +188,7 @@ func TestAPI_DiscoveryChain_Get(t *testing.T) {
 						ID:         "web.default.default.dc2",
 						Service:    "web",
 						Namespace:  "default",
+						Partition:  "default",
 						Datacenter: "dc2",
 						MeshGateway: MeshGatewayConfig{
 							Mode: MeshGatewayModeLocal,
@@ -199,3 +203,56 @@ func TestAPI_DiscoveryChain_Get(t *testing.T) {
 		require.Equal(t, expect, resp)
 	}))
 }
+
+func TestAPI_DiscoveryChain_Simulate(t *testing.T) {
+	t.Parallel()
+	c, s := makeClient(t)
+	defer s.Stop()
+
+	discoverychain := c.DiscoveryChain()
+
+	s.WaitForActiveCARoot(t)
+
+	_, _, err := discoverychain.Simulate("", nil, nil, nil)
+	require.Error(t, err)
+
+	resolver := &ServiceResolverConfigEntry{
+		Kind:           ServiceResolver,
+		Name:           "web",
+		ConnectTimeout: 10 * time.Second,
+		Failover: map[string]ServiceResolverFailover{
+			"*": {Datacenters: []string{"dc2"}},
+		},
+	}
+
+	resp, _, err := discoverychain.Simulate("web", []ConfigEntry{resolver}, nil, nil)
+	require.NoError(t, err)
+
+	require.True(t, resp.CurrentChain.Default)
+	require.False(t, resp.Chain.Default)
+
+	node := resp.Chain.Nodes["resolver:web.default.default.dc1"]
+	require.NotNil(t, node)
+	require.Equal(t, 10*time.Second, node.Resolver.ConnectTimeout)
+	require.Equal(t, []string{"web.default.default.dc2"}, node.Resolver.Failover.Targets)
+
+	require.Contains(t, resp.Explain, "resolver: web.default.default.dc1\n")
+	require.Contains(t, resp.Diff, "- resolver: web.default.default.dc1 (default)\n")
+	require.Contains(t, resp.Diff, "+   failover 1: web.default.default.dc2\n")
+
+	// Simulating must not write the config entry.
+	_, _, err = c.ConfigEntries().Get(ServiceResolver, "web", nil)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "404")
+
+	t.Run("with overrides", func(t *testing.T) {
+		opts := &DiscoveryChainOptions{
+			EvaluateInDatacenter:   "dc2",
+			OverrideConnectTimeout: 22 * time.Second,
+		}
+		resp, _, err := discoverychain.Simulate("web", []ConfigEntry{resolver}, opts, nil)
+		require.NoError(t, err)
+		require.Equal(t, "dc2", resp.Chain.Datacenter)
+		require.Equal(t, 22*time.Second, resp.Chain.Nodes["resolver:web.default.default.dc2"].Resolver.ConnectTimeout)
+	})
+}
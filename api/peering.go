@@ -0,0 +1,238 @@
+package api
+
+import (
+	"fmt"
+	"time"
+)
+
+// PeeringState is the state of a peering with another cluster.
+type PeeringState string
+
+const (
+	PeeringStatePending      PeeringState = "PENDING"
+	PeeringStateEstablishing PeeringState = "ESTABLISHING"
+	PeeringStateActive       PeeringState = "ACTIVE"
+	PeeringStateFailing      PeeringState = "FAILING"
+	PeeringStateTerminated   PeeringState = "TERMINATED"
+)
+
+// Peering is a relationship between this cluster and another one, the peer,
+// through which each side replicates the services it exports to the other.
+type Peering struct {
+	// ID is the unique identifier of the peering in this cluster.
+	ID string
+
+	// Name is the local name of the peer.
+	Name string
+
+	// State is the state of the peering.
+	State PeeringState
+
+	// PeerID is the ID of the peering on the side that generated the token.
+	PeerID string `json:",omitempty"`
+
+	// PeerServerName is the name the gRPC servers of the peer are verified
+	// against.
+	PeerServerName string `json:",omitempty"`
+
+	// PeerServerAddresses are the addresses of the gRPC servers of the peer.
+	// They are only set on the side that established the peering.
+	PeerServerAddresses []string `json:",omitempty"`
+
+	// PeerMesh is the mesh information last received from the peer.
+	PeerMesh *PeeringMeshInfo `json:",omitempty"`
+
+	// Meta is arbitrary metadata attached to the peering.
+	Meta map[string]string `json:",omitempty"`
+
+	// StreamStatus is the status of the replication stream with the peer, as
+	// seen by the leader.
+	StreamStatus *PeeringStreamStatus `json:",omitempty"`
+
+	CreateIndex uint64
+	ModifyIndex uint64
+}
+
+// PeeringStreamStatus is the status of the replication stream of a peering.
+type PeeringStreamStatus struct {
+	Connected   bool
+	LastError   string `json:",omitempty"`
+	LastReceive time.Time
+}
+
+// PeeringMeshInfo is what a peer exposes so that proxies can reach the
+// services it exports through its mesh gateways.
+type PeeringMeshInfo struct {
+	TrustDomain      string
+	RootPEMs         []string
+	Datacenter       string
+	GatewayAddresses []string
+}
+
+// PeeringGenerateTokenRequest is used to create a peering and get the token
+// the peer establishes it with.
+type PeeringGenerateTokenRequest struct {
+	// PeerName is the local name of the peer.
+	PeerName string
+
+	// Meta is arbitrary metadata attached to the peering.
+	Meta map[string]string `json:",omitempty"`
+
+	// ServerExternalAddresses overrides the addresses of the servers put in
+	// the token, for servers behind NAT or a load balancer.
+	ServerExternalAddresses []string `json:",omitempty"`
+}
+
+// PeeringGenerateTokenResponse holds the token of a peering.
+type PeeringGenerateTokenResponse struct {
+	PeeringToken string
+}
+
+// PeeringEstablishRequest is used to establish a peering from a token
+// generated by the peer.
+type PeeringEstablishRequest struct {
+	// PeerName is the local name of the peer.
+	PeerName string
+
+	// PeeringToken is the token generated by the peer.
+	PeeringToken string
+
+	// Meta is arbitrary metadata attached to the peering.
+	Meta map[string]string `json:",omitempty"`
+}
+
+// Peerings can be used to manage the peerings of the cluster.
+type Peerings struct {
+	c *Client
+}
+
+// Peerings returns a handle to the peering endpoints.
+func (c *Client) Peerings() *Peerings {
+	return &Peerings{c}
+}
+
+// GenerateToken creates a peering, if it does not exist yet, and returns the
+// token the peer can establish it with.
+func (p *Peerings) GenerateToken(g PeeringGenerateTokenRequest, wq *WriteOptions) (*PeeringGenerateTokenResponse, *WriteMeta, error) {
+	if g.PeerName == "" {
+		return nil, nil, fmt.Errorf("peer name cannot be empty")
+	}
+
+	r := p.c.newRequest("POST", "/v1/peering/token")
+	r.setWriteOptions(wq)
+	r.obj = g
+	rtt, resp, err := p.c.doRequest(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer closeResponseBody(resp)
+	if err := requireOK(resp); err != nil {
+		return nil, nil, err
+	}
+
+	wm := &WriteMeta{RequestTime: rtt}
+
+	var out PeeringGenerateTokenResponse
+	if err := decodeBody(resp, &out); err != nil {
+		return nil, nil, err
+	}
+	return &out, wm, nil
+}
+
+// Establish establishes a peering from a token generated by the peer.
+func (p *Peerings) Establish(e PeeringEstablishRequest, wq *WriteOptions) (*WriteMeta, error) {
+	if e.PeerName == "" {
+		return nil, fmt.Errorf("peer name cannot be empty")
+	}
+
+	r := p.c.newRequest("POST", "/v1/peering/establish")
+	r.setWriteOptions(wq)
+	r.obj = e
+	rtt, resp, err := p.c.doRequest(r)
+	if err != nil {
+		return nil, err
+	}
+	defer closeResponseBody(resp)
+	if err := requireOK(resp); err != nil {
+		return nil, err
+	}
+
+	return &WriteMeta{RequestTime: rtt}, nil
+}
+
+// Read returns a single peering by name, or nil if it does not exist.
+func (p *Peerings) Read(name string, q *QueryOptions) (*Peering, *QueryMeta, error) {
+	if name == "" {
+		return nil, nil, fmt.Errorf("peer name cannot be empty")
+	}
+
+	r := p.c.newRequest("GET", "/v1/peering/"+name)
+	r.setQueryOptions(q)
+	rtt, resp, err := p.c.doRequest(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer closeResponseBody(resp)
+	found, resp, err := requireNotFoundOrOK(resp)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	qm := &QueryMeta{}
+	parseQueryMeta(resp, qm)
+	qm.RequestTime = rtt
+
+	if !found {
+		return nil, qm, nil
+	}
+
+	var out Peering
+	if err := decodeBody(resp, &out); err != nil {
+		return nil, nil, err
+	}
+	return &out, qm, nil
+}
+
+// List returns all the peerings of the cluster.
+func (p *Peerings) List(q *QueryOptions) ([]*Peering, *QueryMeta, error) {
+	r := p.c.newRequest("GET", "/v1/peerings")
+	r.setQueryOptions(q)
+	rtt, resp, err := p.c.doRequest(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer closeResponseBody(resp)
+	if err := requireOK(resp); err != nil {
+		return nil, nil, err
+	}
+
+	qm := &QueryMeta{}
+	parseQueryMeta(resp, qm)
+	qm.RequestTime = rtt
+
+	var out []*Peering
+	if err := decodeBody(resp, &out); err != nil {
+		return nil, nil, err
+	}
+	return out, qm, nil
+}
+
+// Delete deletes a peering along with the services imported from the peer.
+func (p *Peerings) Delete(name string, wq *WriteOptions) (*WriteMeta, error) {
+	if name == "" {
+		return nil, fmt.Errorf("peer name cannot be empty")
+	}
+
+	r := p.c.newRequest("DELETE", "/v1/peering/"+name)
+	r.setWriteOptions(wq)
+	rtt, resp, err := p.c.doRequest(r)
+	if err != nil {
+		return nil, err
+	}
+	defer closeResponseBody(resp)
+	if err := requireOK(resp); err != nil {
+		return nil, err
+	}
+
+	return &WriteMeta{RequestTime: rtt}, nil
+}
@@ -0,0 +1,62 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hashicorp/consul/sdk/testutil"
+)
+
+func TestAPI_Peerings(t *testing.T) {
+	t.Parallel()
+	// Peering tokens are only generated by servers serving gRPC over TLS.
+	c, s := makeClientWithConfig(t, nil, func(conf *testutil.TestServerConfig) {
+		conf.CAFile = "../test/hostname/CertAuth.crt"
+		conf.CertFile = "../test/hostname/Alice.crt"
+		conf.KeyFile = "../test/hostname/Alice.key"
+	})
+	defer s.Stop()
+
+	s.WaitForSerfCheck(t)
+
+	peerings := c.Peerings()
+
+	_, _, err := peerings.GenerateToken(PeeringGenerateTokenRequest{}, nil)
+	require.Error(t, err)
+
+	resp, _, err := peerings.GenerateToken(PeeringGenerateTokenRequest{
+		PeerName:                "east",
+		Meta:                    map[string]string{"env": "production"},
+		ServerExternalAddresses: []string{"198.18.0.1:8502"},
+	}, nil)
+	require.NoError(t, err)
+	require.NotEmpty(t, resp.PeeringToken)
+
+	peering, qm, err := peerings.Read("east", nil)
+	require.NoError(t, err)
+	require.NotZero(t, qm.LastIndex)
+	require.NotNil(t, peering)
+	require.Equal(t, "east", peering.Name)
+	require.Equal(t, PeeringStatePending, peering.State)
+	require.Equal(t, map[string]string{"env": "production"}, peering.Meta)
+
+	list, _, err := peerings.List(nil)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	require.Equal(t, peering.ID, list[0].ID)
+
+	// A token cannot establish a peering on the side that generated it.
+	_, err = peerings.Establish(PeeringEstablishRequest{
+		PeerName:     "east",
+		PeeringToken: resp.PeeringToken,
+	}, nil)
+	require.Error(t, err)
+
+	_, err = peerings.Delete("east", nil)
+	require.NoError(t, err)
+
+	peering, _, err = peerings.Read("east", nil)
+	require.NoError(t, err)
+	require.Nil(t, peering)
+}
@@ -18,6 +18,8 @@ const (
 type Formatter interface {
 	FormatPolicy(policy *api.ACLPolicy) (string, error)
 	FormatPolicyList(policies []*api.ACLPolicyListEntry) (string, error)
+	FormatLintResults(results []api.ACLPolicyLintResult) (string, error)
+	FormatPolicyGrants(grants []api.ACLPolicyGrant) (string, error)
 }
 
 // GetSupportedFormats returns supported formats
@@ -103,6 +105,41 @@ func (f *prettyFormatter) formatPolicyListEntry(policy *api.ACLPolicyListEntry)
 	return buffer.String()
 }
 
+func (f *prettyFormatter) FormatLintResults(results []api.ACLPolicyLintResult) (string, error) {
+	var buffer bytes.Buffer
+
+	for _, result := range results {
+		if len(result.Findings) == 0 {
+			continue
+		}
+		indent := ""
+		if result.PolicyID != "" {
+			buffer.WriteString(fmt.Sprintf("%s (%s):\n", result.PolicyName, result.PolicyID))
+			indent = "   "
+		}
+		for _, finding := range result.Findings {
+			buffer.WriteString(fmt.Sprintf("%s[%s] %s: %s %s\n", indent, finding.Severity, finding.Kind, finding.Rule, finding.Message))
+		}
+	}
+
+	if buffer.Len() == 0 {
+		return "No problems found", nil
+	}
+	return buffer.String(), nil
+}
+
+func (f *prettyFormatter) FormatPolicyGrants(grants []api.ACLPolicyGrant) (string, error) {
+	var buffer bytes.Buffer
+
+	for _, grant := range grants {
+		buffer.WriteString(fmt.Sprintf("%s:\n", grant.PolicyName))
+		buffer.WriteString(fmt.Sprintf("   ID:    %s\n", grant.PolicyID))
+		buffer.WriteString(fmt.Sprintf("   Rule:  %s\n", grant.Rule))
+	}
+
+	return buffer.String(), nil
+}
+
 func newJSONFormatter(showMeta bool) Formatter {
 	return &jsonFormatter{showMeta}
 }
@@ -126,3 +163,19 @@ func (f *jsonFormatter) FormatPolicyList(policies []*api.ACLPolicyListEntry) (st
 	}
 	return string(b), nil
 }
+
+func (f *jsonFormatter) FormatLintResults(results []api.ACLPolicyLintResult) (string, error) {
+	b, err := json.MarshalIndent(results, "", "    ")
+	if err != nil {
+		return "", fmt.Errorf("Failed to marshal lint results: %v", err)
+	}
+	return string(b), nil
+}
+
+func (f *jsonFormatter) FormatPolicyGrants(grants []api.ACLPolicyGrant) (string, error) {
+	b, err := json.MarshalIndent(grants, "", "    ")
+	if err != nil {
+		return "", fmt.Errorf("Failed to marshal policy grants: %v", err)
+	}
+	return string(b), nil
+}
@@ -0,0 +1,211 @@
+package policylint
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/mitchellh/cli"
+
+	"github.com/hashicorp/consul/acl"
+	"github.com/hashicorp/consul/api"
+	"github.com/hashicorp/consul/command/acl/policy"
+	"github.com/hashicorp/consul/command/flags"
+	"github.com/hashicorp/consul/command/helpers"
+)
+
+func New(ui cli.Ui) *cmd {
+	c := &cmd{UI: ui}
+	c.init()
+	return c
+}
+
+type cmd struct {
+	UI    cli.Ui
+	flags *flag.FlagSet
+	http  *flags.HTTPFlags
+	help  string
+
+	rules    string
+	offline  bool
+	resource string
+	segment  string
+	access   string
+	format   string
+
+	testStdin io.Reader
+}
+
+func (c *cmd) init() {
+	c.flags = flag.NewFlagSet("", flag.ContinueOnError)
+	c.flags.StringVar(&c.rules, "rules", "", "The policy rules to lint. May be prefixed with '@' "+
+		"to indicate that the value is a file path to load the rules from. '-' may also be "+
+		"given to indicate that the rules are available on stdin. When not set all of the "+
+		"policies stored in Consul are linted")
+	c.flags.BoolVar(&c.offline, "offline", false, "Lint the rules given with -rules locally "+
+		"without contacting a Consul agent")
+	c.flags.StringVar(&c.resource, "resource", "", "List the policies which grant access to "+
+		"this kind of resource instead of linting, e.g. \"service\" or \"key\"")
+	c.flags.StringVar(&c.segment, "segment", "", "The name of the resource given with -resource")
+	c.flags.StringVar(&c.access, "access", "read", "The access level to the resource given with "+
+		"-resource to list policies for")
+	c.flags.StringVar(
+		&c.format,
+		"format",
+		policy.PrettyFormat,
+		fmt.Sprintf("Output format {%s}", strings.Join(policy.GetSupportedFormats(), "|")),
+	)
+
+	c.http = &flags.HTTPFlags{}
+	flags.Merge(c.flags, c.http.ClientFlags())
+	flags.Merge(c.flags, c.http.ServerFlags())
+	flags.Merge(c.flags, c.http.MultiTenancyFlags())
+	c.help = flags.Usage(help, c.flags)
+}
+
+func (c *cmd) Run(args []string) int {
+	if err := c.flags.Parse(args); err != nil {
+		return 1
+	}
+
+	if c.resource != "" && c.rules != "" {
+		c.UI.Error("Cannot specify both -rules and -resource")
+		return 1
+	}
+	if c.offline && c.rules == "" {
+		c.UI.Error("The -offline flag requires -rules")
+		return 1
+	}
+
+	formatter, err := policy.NewFormatter(c.format, false)
+	if err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	var rules string
+	if c.rules != "" {
+		rules, err = helpers.LoadDataSource(c.rules, c.testStdin)
+		if err != nil {
+			c.UI.Error(fmt.Sprintf("Error loading rules: %v", err))
+			return 1
+		}
+	}
+
+	if c.offline {
+		results, err := lintOffline(rules)
+		if err != nil {
+			c.UI.Error(fmt.Sprintf("Failed to parse rules: %v", err))
+			return 1
+		}
+		return c.outputResults(formatter, results)
+	}
+
+	client, err := c.http.APIClient()
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error connecting to Consul agent: %s", err))
+		return 1
+	}
+
+	if c.resource != "" {
+		grants, _, err := client.ACL().PolicyGrants(c.resource, c.segment, c.access, nil)
+		if err != nil {
+			c.UI.Error(fmt.Sprintf("Failed to retrieve policy grants: %v", err))
+			return 1
+		}
+
+		if len(grants) == 0 && c.format == policy.PrettyFormat {
+			c.UI.Info(fmt.Sprintf("No policies grant %s access to %s %q", c.access, c.resource, c.segment))
+			return 0
+		}
+
+		out, err := formatter.FormatPolicyGrants(grants)
+		if err != nil {
+			c.UI.Error(err.Error())
+			return 1
+		}
+		c.UI.Info(out)
+		return 0
+	}
+
+	results, _, err := client.ACL().PolicyLint(rules, nil)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Failed to lint policies: %v", err))
+		return 1
+	}
+	return c.outputResults(formatter, results)
+}
+
+// outputResults prints the lint results and returns exit code 2 when any of
+// them contain warnings so that the command can be used in scripts.
+func (c *cmd) outputResults(formatter policy.Formatter, results []api.ACLPolicyLintResult) int {
+	out, err := formatter.FormatLintResults(results)
+	if err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+	c.UI.Info(out)
+
+	for _, result := range results {
+		for _, finding := range result.Findings {
+			if finding.Severity == acl.PolicyLintWarning {
+				return 2
+			}
+		}
+	}
+	return 0
+}
+
+func lintOffline(rules string) ([]api.ACLPolicyLintResult, error) {
+	parsed, err := acl.NewPolicyFromSource(rules, acl.SyntaxCurrent, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var result api.ACLPolicyLintResult
+	for _, finding := range acl.LintPolicy(parsed) {
+		result.Findings = append(result.Findings, api.ACLPolicyLintFinding{
+			Severity: finding.Severity,
+			Kind:     finding.Kind,
+			Rule:     finding.Rule,
+			Message:  finding.Message,
+		})
+	}
+	return []api.ACLPolicyLintResult{result}, nil
+}
+
+func (c *cmd) Synopsis() string {
+	return synopsis
+}
+
+func (c *cmd) Help() string {
+	return flags.Usage(c.help, nil)
+}
+
+const (
+	synopsis = "Lint ACL policies"
+	help     = `
+Usage: consul acl policy lint [options]
+
+    Checks ACL policy rules for rules that are shadowed by other rules, rules
+    that can never match and rules that grant write access to everything. The
+    command exits with code 2 when any warnings are found.
+
+    Lint all of the policies stored in Consul:
+
+        $ consul acl policy lint
+
+    Lint rules from a file before creating a policy:
+
+        $ consul acl policy lint -rules @rules.hcl
+
+    Lint rules without contacting a Consul agent:
+
+        $ consul acl policy lint -offline -rules @rules.hcl
+
+    List the policies which grant write access to the "web" service:
+
+        $ consul acl policy lint -resource service -segment web -access write
+`
+)
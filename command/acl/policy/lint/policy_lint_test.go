@@ -0,0 +1,168 @@
+package policylint
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/mitchellh/cli"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hashicorp/consul/agent"
+	"github.com/hashicorp/consul/api"
+	"github.com/hashicorp/consul/sdk/testutil"
+	"github.com/hashicorp/consul/testrpc"
+)
+
+func TestPolicyLintCommand_noTabs(t *testing.T) {
+	t.Parallel()
+
+	if strings.ContainsRune(New(cli.NewMockUi()).Help(), '\t') {
+		t.Fatal("help has tabs")
+	}
+}
+
+func TestPolicyLintCommand_Offline(t *testing.T) {
+	t.Parallel()
+
+	t.Run("warnings", func(t *testing.T) {
+		ui := cli.NewMockUi()
+		cmd := New(ui)
+		cmd.testStdin = strings.NewReader(`key_prefix "" { policy = "write" }`)
+
+		code := cmd.Run([]string{"-offline", "-rules=-"})
+		require.Equal(t, 2, code)
+		require.Empty(t, ui.ErrorWriter.String())
+		require.Contains(t, ui.OutputWriter.String(), `[warning] broad-write: key_prefix ""`)
+	})
+
+	t.Run("clean", func(t *testing.T) {
+		ui := cli.NewMockUi()
+		cmd := New(ui)
+		cmd.testStdin = strings.NewReader(`key_prefix "app/" { policy = "write" }`)
+
+		code := cmd.Run([]string{"-offline", "-rules=-"})
+		require.Equal(t, 0, code)
+		require.Contains(t, ui.OutputWriter.String(), "No problems found")
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		ui := cli.NewMockUi()
+		cmd := New(ui)
+		cmd.testStdin = strings.NewReader(`key_prefix "app/" { policy = "sudo" }`)
+
+		code := cmd.Run([]string{"-offline", "-rules=-"})
+		require.Equal(t, 1, code)
+		require.Contains(t, ui.ErrorWriter.String(), "Failed to parse rules")
+	})
+
+	t.Run("requires rules", func(t *testing.T) {
+		ui := cli.NewMockUi()
+		cmd := New(ui)
+
+		code := cmd.Run([]string{"-offline"})
+		require.Equal(t, 1, code)
+	})
+}
+
+func TestPolicyLintCommand(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+
+	testDir := testutil.TempDir(t, "acl")
+
+	a := agent.NewTestAgent(t, `
+	primary_datacenter = "dc1"
+	acl {
+		enabled = true
+		tokens {
+			initial_management = "root"
+		}
+	}`)
+
+	defer a.Shutdown()
+	testrpc.WaitForTestAgent(t, a.RPC, "dc1", testrpc.WithToken("root"))
+
+	client := a.Client()
+
+	shadowed, _, err := client.ACL().PolicyCreate(
+		&api.ACLPolicy{
+			Name:  "shadowed",
+			Rules: `service_prefix "" { policy = "read" } service "web" { policy = "read" }`,
+		},
+		&api.WriteOptions{Token: "root"},
+	)
+	require.NoError(t, err)
+
+	t.Run("stored policies", func(t *testing.T) {
+		ui := cli.NewMockUi()
+		cmd := New(ui)
+
+		code := cmd.Run([]string{
+			"-http-addr=" + a.HTTPAddr(),
+			"-token=root",
+		})
+		require.Equal(t, 2, code)
+		require.Empty(t, ui.ErrorWriter.String())
+
+		output := ui.OutputWriter.String()
+		require.Contains(t, output, "shadowed ("+shadowed.ID+"):")
+		require.Contains(t, output, `[warning] shadowed: service "web" has no effect`)
+	})
+
+	t.Run("rules file", func(t *testing.T) {
+		rulesFile := testDir + "/rules.hcl"
+		require.NoError(t, ioutil.WriteFile(rulesFile, []byte(`node "" { policy = "read" }`), 0600))
+
+		ui := cli.NewMockUi()
+		cmd := New(ui)
+
+		code := cmd.Run([]string{
+			"-http-addr=" + a.HTTPAddr(),
+			"-token=root",
+			"-rules=@" + rulesFile,
+		})
+		require.Equal(t, 2, code)
+		require.Contains(t, ui.OutputWriter.String(), `[warning] unreachable: node ""`)
+	})
+
+	t.Run("grants", func(t *testing.T) {
+		ui := cli.NewMockUi()
+		cmd := New(ui)
+
+		code := cmd.Run([]string{
+			"-http-addr=" + a.HTTPAddr(),
+			"-token=root",
+			"-resource=service",
+			"-segment=web",
+			"-format=json",
+		})
+		require.Equal(t, 0, code)
+		require.Empty(t, ui.ErrorWriter.String())
+
+		var grants []api.ACLPolicyGrant
+		require.NoError(t, json.Unmarshal(ui.OutputWriter.Bytes(), &grants))
+		require.ElementsMatch(t, []api.ACLPolicyGrant{
+			{PolicyID: "00000000-0000-0000-0000-000000000001", PolicyName: "global-management", Rule: `service_prefix ""`},
+			{PolicyID: shadowed.ID, PolicyName: "shadowed", Rule: `service "web"`},
+		}, grants)
+	})
+
+	t.Run("top level grants", func(t *testing.T) {
+		ui := cli.NewMockUi()
+		cmd := New(ui)
+
+		code := cmd.Run([]string{
+			"-http-addr=" + a.HTTPAddr(),
+			"-token=root",
+			"-resource=operator",
+			"-access=write",
+		})
+		require.Equal(t, 0, code)
+		require.Contains(t, ui.OutputWriter.String(), "global-management")
+	})
+}
@@ -43,6 +43,10 @@ Usage: consul config <subcommand> [options] [args]
 
     $ consul config list -kind service-defaults
 
+  Simulate the effect of a config on a discovery chain:
+
+    $ consul config simulate -f web.resolver.hcl
+
   Delete a config:
 
     $ consul config delete -kind service-defaults -name web
@@ -0,0 +1,190 @@
+package simulate
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/mitchellh/cli"
+
+	"github.com/hashicorp/consul/api"
+	"github.com/hashicorp/consul/command/config/write"
+	"github.com/hashicorp/consul/command/flags"
+	"github.com/hashicorp/consul/command/helpers"
+)
+
+const (
+	PrettyFormat string = "pretty"
+	JSONFormat   string = "json"
+)
+
+func New(ui cli.Ui) *cmd {
+	c := &cmd{UI: ui}
+	c.init()
+	return c
+}
+
+type cmd struct {
+	UI    cli.Ui
+	flags *flag.FlagSet
+	http  *flags.HTTPFlags
+	help  string
+
+	files     []string
+	service   string
+	compileDC string
+	format    string
+
+	testStdin io.Reader
+}
+
+func (c *cmd) init() {
+	c.flags = flag.NewFlagSet("", flag.ContinueOnError)
+	c.flags.Var((*flags.AppendSliceValue)(&c.files), "f",
+		"Path to a config entry to simulate, in HCL or JSON form. '-' may be "+
+			"given to read the config entry from stdin. This flag may be "+
+			"specified multiple times to simulate several config entries at once.")
+	c.flags.StringVar(&c.service, "service", "",
+		"The name of the service whose discovery chain is simulated. Defaults "+
+			"to the name of the given config entries when they are all for the "+
+			"same service.")
+	c.flags.StringVar(&c.compileDC, "compile-dc", "",
+		"The datacenter to compile the discovery chain for. Defaults to the "+
+			"datacenter of the agent.")
+	c.flags.StringVar(&c.format, "format", PrettyFormat,
+		fmt.Sprintf("Output format {%s|%s}", PrettyFormat, JSONFormat))
+
+	c.http = &flags.HTTPFlags{}
+	flags.Merge(c.flags, c.http.ClientFlags())
+	flags.Merge(c.flags, c.http.ServerFlags())
+	flags.Merge(c.flags, c.http.MultiTenancyFlags())
+	c.help = flags.Usage(help, c.flags)
+}
+
+func (c *cmd) Run(args []string) int {
+	if err := c.flags.Parse(args); err != nil {
+		return 1
+	}
+
+	if len(c.files) == 0 {
+		c.UI.Error("Must provide at least one config entry to simulate with -f")
+		return 1
+	}
+	if c.format != PrettyFormat && c.format != JSONFormat {
+		c.UI.Error(fmt.Sprintf("Invalid format %q, must be one of %s or %s", c.format, PrettyFormat, JSONFormat))
+		return 1
+	}
+
+	var entries []api.ConfigEntry
+	for _, file := range c.files {
+		data, err := helpers.LoadDataSourceNoRaw(file, c.testStdin)
+		if err != nil {
+			c.UI.Error(fmt.Sprintf("Failed to load data: %v", err))
+			return 1
+		}
+
+		entry, err := write.ParseConfigEntry(data)
+		if err != nil {
+			c.UI.Error(fmt.Sprintf("Failed to decode config entry input: %v", err))
+			return 1
+		}
+		entries = append(entries, entry)
+	}
+
+	service := c.service
+	if service == "" {
+		var err error
+		service, err = chainName(entries)
+		if err != nil {
+			c.UI.Error(err.Error())
+			return 1
+		}
+	}
+
+	client, err := c.http.APIClient()
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error connecting to Consul agent: %s", err))
+		return 1
+	}
+
+	opts := &api.DiscoveryChainOptions{EvaluateInDatacenter: c.compileDC}
+	resp, _, err := client.DiscoveryChain().Simulate(service, entries, opts, nil)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error simulating the discovery chain for %q: %v", service, err))
+		return 1
+	}
+
+	if c.format == JSONFormat {
+		b, err := json.MarshalIndent(resp, "", "    ")
+		if err != nil {
+			c.UI.Error(fmt.Sprintf("Failed to encode output data: %v", err))
+			return 1
+		}
+		c.UI.Output(string(b))
+		return 0
+	}
+
+	c.UI.Output(fmt.Sprintf("Simulated discovery chain for %q:\n", service))
+	c.UI.Output(strings.TrimSuffix(resp.Explain, "\n"))
+	if resp.Diff == "" {
+		c.UI.Output("\nThe config entries do not change the current discovery chain.")
+		return 0
+	}
+	c.UI.Output("\nChanges from the current discovery chain:\n")
+	c.UI.Output(strings.TrimSuffix(resp.Diff, "\n"))
+	return 0
+}
+
+// chainName returns the name of the service the config entries are for.
+// proxy-defaults entries apply to every service so they are not considered.
+func chainName(entries []api.ConfigEntry) (string, error) {
+	var name string
+	for _, entry := range entries {
+		if entry.GetKind() == api.ProxyDefaults {
+			continue
+		}
+		if name != "" && entry.GetName() != name {
+			return "", fmt.Errorf("The config entries are for more than one service, use -service to choose the discovery chain to simulate")
+		}
+		name = entry.GetName()
+	}
+	if name == "" {
+		return "", fmt.Errorf("Must provide -service when only proxy-defaults config entries are given")
+	}
+	return name, nil
+}
+
+func (c *cmd) Synopsis() string {
+	return synopsis
+}
+
+func (c *cmd) Help() string {
+	return flags.Usage(c.help, nil)
+}
+
+const (
+	synopsis = "Simulate the effect of config entries on a discovery chain"
+	help     = `
+Usage: consul config simulate [options] -f <configuration>
+
+  Compiles the discovery chain of a service as if the given config entries
+  had been written, without writing them. The resulting routes, splits,
+  resolvers and failover targets are printed as a tree, followed by the
+  changes from the discovery chain compiled with the config entries that are
+  currently stored.
+
+  Simulate a new service-resolver:
+
+    $ consul config simulate -f web-resolver.hcl
+
+  Simulate a service-router and service-splitter together:
+
+    $ consul config simulate -f web-router.hcl -f web-splitter.hcl
+
+  Simulate a proxy-defaults change for a given service:
+
+    $ consul config simulate -service web -f proxy-defaults.hcl
+`
+)
@@ -0,0 +1,156 @@
+package simulate
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/mitchellh/cli"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hashicorp/consul/agent"
+	"github.com/hashicorp/consul/api"
+	"github.com/hashicorp/consul/sdk/testutil"
+	"github.com/hashicorp/consul/testrpc"
+)
+
+func TestConfigSimulate_noTabs(t *testing.T) {
+	t.Parallel()
+
+	require.NotContains(t, New(cli.NewMockUi()).Help(), "\t")
+}
+
+func TestConfigSimulate(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+
+	a := agent.NewTestAgent(t, ``)
+	defer a.Shutdown()
+	testrpc.WaitForTestAgent(t, a.RPC, "dc1")
+
+	resolver := testutil.TempFile(t, "config-simulate-web-resolver.hcl")
+	_, err := resolver.WriteString(`
+      Kind = "service-resolver"
+      Name = "web"
+      Failover = {
+        "*" = {
+          Datacenters = ["dc2"]
+        }
+      }
+      `)
+	require.NoError(t, err)
+
+	t.Run("pretty", func(t *testing.T) {
+		ui := cli.NewMockUi()
+		c := New(ui)
+
+		code := c.Run([]string{
+			"-http-addr=" + a.HTTPAddr(),
+			"-f", resolver.Name(),
+		})
+		require.Empty(t, ui.ErrorWriter.String())
+		require.Equal(t, 0, code)
+
+		output := ui.OutputWriter.String()
+		require.Contains(t, output, `Simulated discovery chain for "web":`)
+		require.Contains(t, output, "Changes from the current discovery chain:")
+		require.Contains(t, output, "- resolver: web.default.default.dc1 (default)\n")
+		require.Contains(t, output, "+   failover 1: web.default.default.dc2\n")
+
+		// The resolver must not have been written.
+		_, _, err := a.Client().ConfigEntries().Get(api.ServiceResolver, "web", nil)
+		require.Error(t, err)
+	})
+
+	t.Run("json", func(t *testing.T) {
+		ui := cli.NewMockUi()
+		c := New(ui)
+
+		code := c.Run([]string{
+			"-http-addr=" + a.HTTPAddr(),
+			"-format=json",
+			"-f", resolver.Name(),
+		})
+		require.Empty(t, ui.ErrorWriter.String())
+		require.Equal(t, 0, code)
+
+		var resp api.DiscoveryChainSimulateResponse
+		require.NoError(t, json.Unmarshal(ui.OutputWriter.Bytes(), &resp))
+		require.Equal(t, "web", resp.Chain.ServiceName)
+		require.NotEmpty(t, resp.Diff)
+	})
+
+	t.Run("no changes", func(t *testing.T) {
+		ui := cli.NewMockUi()
+		c := New(ui)
+		c.testStdin = strings.NewReader(`{"Kind": "service-defaults", "Name": "web", "Protocol": "tcp"}`)
+
+		code := c.Run([]string{
+			"-http-addr=" + a.HTTPAddr(),
+			"-f", "-",
+		})
+		require.Empty(t, ui.ErrorWriter.String())
+		require.Equal(t, 0, code)
+		require.Contains(t, ui.OutputWriter.String(), "The config entries do not change the current discovery chain.")
+	})
+}
+
+func TestConfigSimulate_Errors(t *testing.T) {
+	t.Parallel()
+
+	webResolver := testutil.TempFile(t, "config-simulate-web.hcl")
+	_, err := webResolver.WriteString(`
+      Kind = "service-resolver"
+      Name = "web"
+      `)
+	require.NoError(t, err)
+
+	apiResolver := testutil.TempFile(t, "config-simulate-api.hcl")
+	_, err = apiResolver.WriteString(`
+      Kind = "service-resolver"
+      Name = "api"
+      `)
+	require.NoError(t, err)
+
+	proxyDefaults := testutil.TempFile(t, "config-simulate-proxy-defaults.hcl")
+	_, err = proxyDefaults.WriteString(`
+      Kind = "proxy-defaults"
+      Name = "global"
+      `)
+	require.NoError(t, err)
+
+	cases := map[string]struct {
+		args   []string
+		expect string
+	}{
+		"no files": {
+			expect: "Must provide at least one config entry to simulate with -f",
+		},
+		"invalid format": {
+			args:   []string{"-format=yaml", "-f", webResolver.Name()},
+			expect: `Invalid format "yaml"`,
+		},
+		"several services": {
+			args:   []string{"-f", webResolver.Name(), "-f", apiResolver.Name()},
+			expect: "The config entries are for more than one service",
+		},
+		"only proxy-defaults": {
+			args:   []string{"-f", proxyDefaults.Name()},
+			expect: "Must provide -service when only proxy-defaults config entries are given",
+		},
+	}
+
+	for name, tc := range cases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			ui := cli.NewMockUi()
+			c := New(ui)
+
+			require.Equal(t, 1, c.Run(tc.args))
+			require.Contains(t, ui.ErrorWriter.String(), tc.expect)
+		})
+	}
+}
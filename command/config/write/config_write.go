@@ -66,7 +66,7 @@ func (c *cmd) Run(args []string) int {
 		return 1
 	}
 
-	entry, err := parseConfigEntry(data)
+	entry, err := ParseConfigEntry(data)
 	if err != nil {
 		c.UI.Error(fmt.Sprintf("Failed to decode config entry input: %v", err))
 		return 1
@@ -100,7 +100,8 @@ func (c *cmd) Run(args []string) int {
 	return 0
 }
 
-func parseConfigEntry(data string) (api.ConfigEntry, error) {
+// ParseConfigEntry decodes a config entry given in either HCL or JSON form.
+func ParseConfigEntry(data string) (api.ConfigEntry, error) {
 	// parse the data
 	var raw map[string]interface{}
 	if err := hclDecode(&raw, data); err != nil {
@@ -10,11 +10,16 @@ import (
 	"strings"
 	"text/template"
 
+	"github.com/hashicorp/consul/agent/structs"
 	"github.com/hashicorp/consul/api"
 )
 
 const (
 	selfAdminName = "self_admin"
+
+	// tracingCollectorClusterName is the name of the static cluster the tracer
+	// configured by the Tracing field reports spans to.
+	tracingCollectorClusterName = "consul_tracing_collector"
 )
 
 // BootstrapConfig is the set of keys we care about in a Connect.Proxy.Config
@@ -149,6 +154,13 @@ type BootstrapConfig struct {
 	// the bootstrap config. It's format may vary based on Envoy version used.
 	// See https://www.envoyproxy.io/docs/envoy/v1.9.0/api-v2/config/trace/v2/trace.proto.
 	TracingConfigJSON string `mapstructure:"envoy_tracing_json"`
+
+	// Tracing is the typed tracing configuration of the proxy, usually set
+	// through the proxy-defaults config entry. It is rendered as the tracer and
+	// the static cluster of its collector, unless TracingConfigJSON is set.
+	// Its collector must be resolved to an address first, see
+	// resolveTracingCollector.
+	Tracing *structs.TracingConfig `mapstructure:"-"`
 }
 
 // Template returns the bootstrap template to use as a base.
@@ -211,6 +223,13 @@ func (c *BootstrapConfig) ConfigureArgs(args *BootstrapTplArgs, omitDeprecatedTa
 	if c.StaticListenersJSON != "" {
 		args.StaticListenersJSON = c.StaticListenersJSON
 	}
+	// Setup the tracer if needed. This MUST happen after the Static*JSON is set
+	// above.
+	if c.Tracing != nil && c.TracingConfigJSON == "" {
+		if err := c.generateTracingConfig(args); err != nil {
+			return err
+		}
+	}
 	// Setup prometheus if needed. This MUST happen after the Static*JSON is set above
 	if c.PrometheusBindAddr != "" {
 		if err := c.generateListenerConfig(args, c.PrometheusBindAddr, "envoy_prometheus_metrics", "path", args.PrometheusScrapePath, "/stats/prometheus", args.PrometheusBackendPort); err != nil {
@@ -719,6 +738,135 @@ func (c *BootstrapConfig) generateListenerConfig(args *BootstrapTplArgs, bindAdd
 	return nil
 }
 
+// generateTracingConfig renders the tracer described by the Tracing field and
+// adds the static cluster of its collector. Envoy requires the collector of
+// the tracers to be a static cluster.
+func (c *BootstrapConfig) generateTracingConfig(args *BootstrapTplArgs) error {
+	collector := c.Tracing.Collector
+	if collector.Address == "" || collector.Port == 0 {
+		return fmt.Errorf("tracing collector has no address")
+	}
+
+	clusterType := "STRICT_DNS"
+	if net.ParseIP(collector.Address) != nil {
+		clusterType = "STATIC"
+	}
+	cluster := map[string]interface{}{
+		"name":            tracingCollectorClusterName,
+		"connect_timeout": "5s",
+		"type":            clusterType,
+		"load_assignment": map[string]interface{}{
+			"cluster_name": tracingCollectorClusterName,
+			"endpoints": []interface{}{
+				map[string]interface{}{
+					"lb_endpoints": []interface{}{
+						map[string]interface{}{
+							"endpoint": map[string]interface{}{
+								"address": map[string]interface{}{
+									"socket_address": map[string]interface{}{
+										"address":    collector.Address,
+										"port_value": collector.Port,
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	var tracer map[string]interface{}
+	switch c.Tracing.Provider {
+	case structs.ZipkinTracingProvider:
+		endpoint := c.Tracing.CollectorEndpoint
+		if endpoint == "" {
+			endpoint = "/api/v2/spans"
+		}
+		tracer = map[string]interface{}{
+			"name": "envoy.tracers.zipkin",
+			"typedConfig": map[string]interface{}{
+				"@type":                      "type.googleapis.com/envoy.config.trace.v3.ZipkinConfig",
+				"collector_cluster":          tracingCollectorClusterName,
+				"collector_endpoint":         endpoint,
+				"collector_endpoint_version": "HTTP_JSON",
+			},
+		}
+	case structs.OpenTelemetryTracingProvider:
+		// The supported Envoy versions have no OpenTelemetry tracer, so spans
+		// are exported with the OpenCensus protocol, which the OpenTelemetry
+		// collector receives, and propagated with W3C trace context headers.
+		tracer = map[string]interface{}{
+			"name": "envoy.tracers.opencensus",
+			"typedConfig": map[string]interface{}{
+				"@type":                    "type.googleapis.com/envoy.config.trace.v3.OpenCensusConfig",
+				"ocagent_exporter_enabled": true,
+				"ocagent_grpc_service": map[string]interface{}{
+					"envoy_grpc": map[string]interface{}{
+						"cluster_name": tracingCollectorClusterName,
+					},
+				},
+				"incoming_trace_context": []string{"TRACE_CONTEXT", "B3"},
+				"outgoing_trace_context": []string{"TRACE_CONTEXT"},
+			},
+		}
+		cluster["http2_protocol_options"] = map[string]interface{}{}
+	default:
+		return fmt.Errorf("unsupported tracing provider %q", c.Tracing.Provider)
+	}
+
+	tracingJSON, err := json.Marshal(map[string]interface{}{"http": tracer})
+	if err != nil {
+		return err
+	}
+	clusterJSON, err := json.Marshal(cluster)
+	if err != nil {
+		return err
+	}
+
+	args.TracingConfigJSON = string(tracingJSON)
+	if args.StaticClustersJSON != "" {
+		args.StaticClustersJSON += ",\n"
+	}
+	args.StaticClustersJSON += string(clusterJSON)
+	return nil
+}
+
+// resolveTracingCollector sets the address of a tracing collector that is a
+// service of the mesh to the local address of the upstream of the proxy
+// targeting it, so the tracer reaches it through the proxy itself. It returns
+// false if the proxy has no such upstream.
+func resolveTracingCollector(cfg *structs.TracingConfig, svc *api.AgentService) bool {
+	if cfg.Collector.Service == "" {
+		return true
+	}
+
+	orDefault := func(s, def string) string {
+		if s == "" {
+			return def
+		}
+		return s
+	}
+	namespace := orDefault(svc.Namespace, "default")
+	partition := orDefault(svc.Partition, "default")
+
+	for _, u := range svc.Proxy.Upstreams {
+		if u.DestinationType == api.UpstreamDestTypePreparedQuery ||
+			u.DestinationName != cfg.Collector.Service ||
+			orDefault(u.DestinationNamespace, namespace) != cfg.Collector.NamespaceOrDefault() ||
+			orDefault(u.DestinationPartition, partition) != cfg.Collector.PartitionOrDefault() ||
+			(u.Datacenter != "" && u.Datacenter != svc.Datacenter) ||
+			u.LocalBindPort == 0 {
+			continue
+		}
+
+		cfg.Collector.Address = orDefault(u.LocalBindAddress, "127.0.0.1")
+		cfg.Collector.Port = u.LocalBindPort
+		return true
+	}
+	return false
+}
+
 func containsSelfAdminCluster(clustersJSON string) (bool, error) {
 	clusterNames := []struct {
 		Name string
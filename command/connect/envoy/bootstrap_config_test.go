@@ -9,6 +9,9 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/hashicorp/consul/agent/structs"
+	"github.com/hashicorp/consul/api"
 )
 
 const (
@@ -824,6 +827,143 @@ func TestBootstrapConfig_ConfigureArgs(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "tracing-zipkin",
+			input: BootstrapConfig{
+				Tracing: &structs.TracingConfig{
+					Provider: structs.ZipkinTracingProvider,
+					Collector: structs.TracingCollector{
+						Address: "zipkin.service.consul",
+						Port:    9411,
+					},
+				},
+			},
+			wantArgs: BootstrapTplArgs{
+				StatsConfigJSON: defaultStatsConfigJSON,
+				StaticClustersJSON: `{
+					"name": "consul_tracing_collector",
+					"connect_timeout": "5s",
+					"type": "STRICT_DNS",
+					"load_assignment": {
+						"cluster_name": "consul_tracing_collector",
+						"endpoints": [
+							{
+								"lb_endpoints": [
+									{
+										"endpoint": {
+											"address": {
+												"socket_address": {
+													"address": "zipkin.service.consul",
+													"port_value": 9411
+												}
+											}
+										}
+									}
+								]
+							}
+						]
+					}
+				}`,
+				TracingConfigJSON: `{
+					"http": {
+						"name": "envoy.tracers.zipkin",
+						"typedConfig": {
+							"@type": "type.googleapis.com/envoy.config.trace.v3.ZipkinConfig",
+							"collector_cluster": "consul_tracing_collector",
+							"collector_endpoint": "/api/v2/spans",
+							"collector_endpoint_version": "HTTP_JSON"
+						}
+					}
+				}`,
+			},
+		},
+		{
+			name: "tracing-opentelemetry-with-static-clusters",
+			input: BootstrapConfig{
+				StaticClustersJSON: `{"name": "fake_cluster"}`,
+				Tracing: &structs.TracingConfig{
+					Provider: structs.OpenTelemetryTracingProvider,
+					Collector: structs.TracingCollector{
+						Address: "127.0.0.1",
+						Port:    55678,
+					},
+				},
+			},
+			wantArgs: BootstrapTplArgs{
+				StatsConfigJSON: defaultStatsConfigJSON,
+				StaticClustersJSON: `{"name": "fake_cluster"},
+				{
+					"name": "consul_tracing_collector",
+					"connect_timeout": "5s",
+					"type": "STATIC",
+					"http2_protocol_options": {},
+					"load_assignment": {
+						"cluster_name": "consul_tracing_collector",
+						"endpoints": [
+							{
+								"lb_endpoints": [
+									{
+										"endpoint": {
+											"address": {
+												"socket_address": {
+													"address": "127.0.0.1",
+													"port_value": 55678
+												}
+											}
+										}
+									}
+								]
+							}
+						]
+					}
+				}`,
+				TracingConfigJSON: `{
+					"http": {
+						"name": "envoy.tracers.opencensus",
+						"typedConfig": {
+							"@type": "type.googleapis.com/envoy.config.trace.v3.OpenCensusConfig",
+							"ocagent_exporter_enabled": true,
+							"ocagent_grpc_service": {
+								"envoy_grpc": {
+									"cluster_name": "consul_tracing_collector"
+								}
+							},
+							"incoming_trace_context": ["TRACE_CONTEXT", "B3"],
+							"outgoing_trace_context": ["TRACE_CONTEXT"]
+						}
+					}
+				}`,
+			},
+		},
+		{
+			name: "tracing-json-overrides-tracing",
+			input: BootstrapConfig{
+				TracingConfigJSON: `{"foo": "bar"}`,
+				Tracing: &structs.TracingConfig{
+					Provider: structs.ZipkinTracingProvider,
+					Collector: structs.TracingCollector{
+						Address: "zipkin.service.consul",
+						Port:    9411,
+					},
+				},
+			},
+			wantArgs: BootstrapTplArgs{
+				StatsConfigJSON:   defaultStatsConfigJSON,
+				TracingConfigJSON: `{"foo": "bar"}`,
+			},
+		},
+		{
+			name: "err-tracing-unresolved-collector",
+			input: BootstrapConfig{
+				Tracing: &structs.TracingConfig{
+					Provider: structs.ZipkinTracingProvider,
+					Collector: structs.TracingCollector{
+						Service: "zipkin",
+					},
+				},
+			},
+			wantErr: true,
+		},
 		{
 			name: "err-bad-prometheus-addr",
 			input: BootstrapConfig{
@@ -985,6 +1125,81 @@ func TestBootstrapConfig_ConfigureArgs(t *testing.T) {
 	}
 }
 
+func TestResolveTracingCollector(t *testing.T) {
+	svc := &api.AgentService{
+		Datacenter: "dc1",
+		Proxy: &api.AgentServiceConnectProxyConfig{
+			Upstreams: []api.Upstream{
+				{
+					DestinationName: "zipkin",
+					Datacenter:      "dc2",
+					LocalBindPort:   9000,
+				},
+				{
+					DestinationType: api.UpstreamDestTypePreparedQuery,
+					DestinationName: "zipkin",
+					LocalBindPort:   9001,
+				},
+				{
+					DestinationName: "zipkin",
+					LocalBindPort:   9002,
+				},
+				{
+					DestinationName:  "otel",
+					LocalBindAddress: "127.0.0.2",
+					LocalBindPort:    9003,
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name        string
+		collector   structs.TracingCollector
+		wantAddress string
+		wantPort    int
+		wantOK      bool
+	}{
+		{
+			name:        "static address",
+			collector:   structs.TracingCollector{Address: "10.0.0.1", Port: 9411},
+			wantAddress: "10.0.0.1",
+			wantPort:    9411,
+			wantOK:      true,
+		},
+		{
+			name:        "local upstream",
+			collector:   structs.TracingCollector{Service: "zipkin"},
+			wantAddress: "127.0.0.1",
+			wantPort:    9002,
+			wantOK:      true,
+		},
+		{
+			name:        "local upstream with bind address",
+			collector:   structs.TracingCollector{Service: "otel"},
+			wantAddress: "127.0.0.2",
+			wantPort:    9003,
+			wantOK:      true,
+		},
+		{
+			name:      "not an upstream",
+			collector: structs.TracingCollector{Service: "jaeger"},
+			wantOK:    false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &structs.TracingConfig{Collector: tt.collector}
+			require.Equal(t, tt.wantOK, resolveTracingCollector(cfg, svc))
+			if tt.wantOK {
+				require.Equal(t, tt.wantAddress, cfg.Collector.Address)
+				require.Equal(t, tt.wantPort, cfg.Collector.Port)
+			}
+		})
+	}
+}
+
 func TestConsulTagSpecifiers(t *testing.T) {
 	// Conveniently both envoy and Go use the re2 dialect of regular
 	// expressions, so we can actually test the stats tag extraction regular
@@ -554,6 +554,17 @@ func (c *cmd) generateConfig() ([]byte, error) {
 		if err := mapstructure.WeakDecode(svc.Proxy.Config, &bsCfg); err != nil {
 			return nil, fmt.Errorf("failed parsing Proxy.Config: %s", err)
 		}
+
+		tracing, err := xds.ParseTracingConfig(svc.Proxy.Config)
+		if err != nil {
+			return nil, fmt.Errorf("failed parsing tracing config: %s", err)
+		}
+		if tracing != nil && !resolveTracingCollector(tracing, svc) {
+			c.UI.Warn(fmt.Sprintf("Tracing disabled: collector service %q is not an upstream of the proxy with a local bind port",
+				tracing.Collector.Service))
+			tracing = nil
+		}
+		bsCfg.Tracing = tracing
 	}
 
 	return bsCfg.GenerateJSON(args, c.omitDeprecatedTags)
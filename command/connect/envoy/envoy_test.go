@@ -721,6 +721,37 @@ func TestGenerateConfig(t *testing.T) {
 				PrometheusScrapePath:  "/metrics",
 			},
 		},
+		{
+			Name:  "typed-tracing-config",
+			Flags: []string{"-proxy-id", "test-proxy"},
+			ProxyConfig: map[string]interface{}{
+				// Set by the proxy-defaults config entry.
+				"tracing": map[string]interface{}{
+					"Provider": "zipkin",
+					"Collector": map[string]interface{}{
+						"Address": "zipkin.service.consul",
+						"Port":    9411,
+					},
+					"SamplingPercentage": 10,
+				},
+			},
+			WantArgs: BootstrapTplArgs{
+				ProxyCluster: "test-proxy",
+				ProxyID:      "test-proxy",
+				// We don't know this til after the lookup so it will be empty in the
+				// initial args call we are testing here.
+				ProxySourceService: "",
+				GRPC: GRPC{
+					AgentAddress: "127.0.0.1",
+					AgentPort:    "8502",
+				},
+				AdminAccessLogPath:    "/dev/null",
+				AdminBindAddress:      "127.0.0.1",
+				AdminBindPort:         "19000",
+				LocalAgentClusterName: xds.LocalAgentClusterName,
+				PrometheusScrapePath:  "/metrics",
+			},
+		},
 		{
 			Name:  "CONSUL_HTTP_ADDR-with-https-scheme-enables-tls",
 			Flags: []string{"-proxy-id", "test-proxy"},
@@ -0,0 +1,248 @@
+package proxystatus
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/consul/api"
+	"github.com/hashicorp/consul/command/flags"
+	"github.com/mitchellh/cli"
+	"github.com/ryanuber/columnize"
+)
+
+const (
+	PrettyFormat string = "pretty"
+	JSONFormat   string = "json"
+)
+
+func New(ui cli.Ui) *cmd {
+	c := &cmd{UI: ui}
+	c.init()
+	return c
+}
+
+type cmd struct {
+	UI    cli.Ui
+	flags *flag.FlagSet
+	http  *flags.HTTPFlags
+	help  string
+
+	// flags
+	proxyID string
+	format  string
+}
+
+func (c *cmd) init() {
+	c.flags = flag.NewFlagSet("", flag.ContinueOnError)
+	c.flags.StringVar(&c.proxyID, "proxy-id", "",
+		"The service ID of the proxy or gateway to report on. It must be "+
+			"registered with, and connected to, the agent being queried.")
+	c.flags.StringVar(&c.format, "format", PrettyFormat,
+		fmt.Sprintf("Output format {%s}", strings.Join([]string{PrettyFormat, JSONFormat}, "|")))
+	c.http = &flags.HTTPFlags{}
+	flags.Merge(c.flags, c.http.ClientFlags())
+	flags.Merge(c.flags, c.http.MultiTenancyFlags())
+	c.help = flags.Usage(help, c.flags)
+}
+
+func (c *cmd) Run(args []string) int {
+	if err := c.flags.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+		c.UI.Error(fmt.Sprintf("Failed to parse args: %v", err))
+		return 1
+	}
+
+	if c.proxyID == "" {
+		c.UI.Error("-proxy-id is required")
+		return 1
+	}
+	if c.format != PrettyFormat && c.format != JSONFormat {
+		c.UI.Error(fmt.Sprintf("Unknown format: %s", c.format))
+		return 1
+	}
+
+	client, err := c.http.APIClient()
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error connecting to Consul agent: %s", err))
+		return 1
+	}
+
+	status, _, err := client.Agent().XDSStatus(c.proxyID, nil)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error querying xDS status of proxy %q: %s", c.proxyID, err))
+		return 1
+	}
+
+	if c.format == JSONFormat {
+		out, err := json.MarshalIndent(status, "", "    ")
+		if err != nil {
+			c.UI.Error(fmt.Sprintf("Error formatting xDS status: %s", err))
+			return 1
+		}
+		c.UI.Output(string(out))
+		return 0
+	}
+
+	c.UI.Output(formatStatus(status))
+	return 0
+}
+
+// formatStatus renders the status of a proxy for humans.
+func formatStatus(status *api.AgentXDSStatus) string {
+	var b strings.Builder
+
+	envoyVersion := status.Envoy.Version
+	if envoyVersion == "" {
+		envoyVersion = "unknown"
+	}
+	b.WriteString(columns("", []string{
+		fmt.Sprintf("Proxy ID:\x1f%s", status.ProxyID),
+		fmt.Sprintf("Connected At:\x1f%s", formatTime(status.ConnectedAt)),
+		fmt.Sprintf("Envoy Version:\x1f%s", envoyVersion),
+	}))
+	b.WriteString("\n")
+
+	if snap := status.Snapshot; snap != nil {
+		b.WriteString("\nSnapshot:\n")
+		lines := []string{
+			fmt.Sprintf("Kind:\x1f%s", formatKind(snap.Kind)),
+			fmt.Sprintf("Service:\x1f%s", snap.Service),
+			fmt.Sprintf("Datacenter:\x1f%s", snap.Datacenter),
+			fmt.Sprintf("Valid:\x1f%t", snap.Valid),
+			fmt.Sprintf("Received At:\x1f%s", formatTime(snap.ReceivedAt)),
+		}
+		if snap.LeafCertSerial != "" {
+			lines = append(lines,
+				fmt.Sprintf("Leaf Cert Serial:\x1f%s", snap.LeafCertSerial),
+				fmt.Sprintf("Leaf Cert Expiry:\x1f%s", formatTime(snap.LeafCertExpiry)),
+			)
+		}
+		b.WriteString(columns("   ", lines))
+		b.WriteString("\n")
+		if len(snap.Upstreams) > 0 {
+			b.WriteString("   Upstreams:\n")
+			for _, upstream := range snap.Upstreams {
+				b.WriteString(fmt.Sprintf("      %s\n", upstream))
+			}
+		}
+	}
+
+	typeURLs := make([]string, 0, len(status.Resources))
+	for typeURL := range status.Resources {
+		typeURLs = append(typeURLs, typeURL)
+	}
+	sort.Strings(typeURLs)
+	for _, typeURL := range typeURLs {
+		res := status.Resources[typeURL]
+
+		b.WriteString(fmt.Sprintf("\n%s:\n", typeURL))
+		b.WriteString(fmt.Sprintf("   Last Sent Nonce:   %s\n", orNone(res.LastSentNonce)))
+		b.WriteString(fmt.Sprintf("   Last Acked Nonce:  %s\n", orNone(res.LastAckedNonce)))
+		if res.LastNackedNonce != "" {
+			b.WriteString(fmt.Sprintf("   Last Nacked Nonce: %s\n", res.LastNackedNonce))
+			b.WriteString(fmt.Sprintf("   Last NACK Error:   %s\n", res.LastNackError))
+		}
+
+		names := make([]string, 0, len(res.Versions))
+		for name := range res.Versions {
+			names = append(names, name)
+		}
+		for name := range res.AckedVersions {
+			if _, ok := res.Versions[name]; !ok {
+				names = append(names, name)
+			}
+		}
+		if len(names) == 0 {
+			continue
+		}
+		sort.Strings(names)
+
+		lines := []string{"Name\x1fSent Version\x1fAcked Version"}
+		for _, name := range names {
+			lines = append(lines, fmt.Sprintf("%s\x1f%s\x1f%s",
+				name, orNone(res.Versions[name]), orNone(res.AckedVersions[name])))
+		}
+		b.WriteString(columns("   ", lines))
+		b.WriteString("\n")
+	}
+
+	if len(status.Events) > 0 {
+		b.WriteString("\nEvents:\n")
+		lines := []string{"Time\x1fType\x1fResource Type\x1fNonce\x1fDetail"}
+		for _, event := range status.Events {
+			detail := event.Error
+			if event.Type == "send" {
+				detail = fmt.Sprintf("%d upserted, %d removed", len(event.Upserted), len(event.Removed))
+			}
+			lines = append(lines, fmt.Sprintf("%s\x1f%s\x1f%s\x1f%s\x1f%s",
+				formatTime(event.Time), strings.ToUpper(event.Type), event.TypeURL, event.Nonce, detail))
+		}
+		b.WriteString(columns("   ", lines))
+		b.WriteString("\n")
+	}
+
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// columns aligns the columns of the given lines, which are delimited by the
+// unit separator so values may contain any printable character.
+func columns(prefix string, lines []string) string {
+	return columnize.Format(lines, &columnize.Config{
+		Delim:  string([]byte{0x1f}),
+		Prefix: prefix,
+	})
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return "<none>"
+	}
+	return t.Local().Format(time.RFC3339)
+}
+
+func formatKind(kind api.ServiceKind) string {
+	if kind == api.ServiceKindTypical {
+		return "typical"
+	}
+	return string(kind)
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "<none>"
+	}
+	return s
+}
+
+func (c *cmd) Synopsis() string {
+	return synopsis
+}
+
+func (c *cmd) Help() string {
+	return c.help
+}
+
+const synopsis = "Display the xDS status of a proxy connected to the agent"
+const help = `
+Usage: consul connect proxy-status -proxy-id <proxy-id> [options]
+
+  Displays what the local agent sent over xDS to a connected Envoy proxy or
+  gateway, and how the proxy responded: a summary of the configuration
+  snapshot the resources were generated from, the versions of the resources
+  sent and acknowledged, and the latest ACKs and NACKs including their error
+  details.
+
+  The proxy must be registered with, and connected to, the agent being queried.
+
+      $ consul connect proxy-status -proxy-id web-sidecar-proxy
+
+  To output the raw status as JSON:
+
+      $ consul connect proxy-status -proxy-id web-sidecar-proxy -format json
+`
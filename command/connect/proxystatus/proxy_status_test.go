@@ -0,0 +1,143 @@
+package proxystatus
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mitchellh/cli"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hashicorp/consul/agent"
+	"github.com/hashicorp/consul/api"
+	"github.com/hashicorp/consul/testrpc"
+)
+
+func TestConnectProxyStatusCommand_noTabs(t *testing.T) {
+	t.Parallel()
+	if strings.ContainsRune(New(cli.NewMockUi()).Help(), '\t') {
+		t.Fatal("help has tabs")
+	}
+}
+
+func TestConnectProxyStatusCommand_FlagValidation(t *testing.T) {
+	cases := []struct {
+		name     string
+		args     []string
+		expError string
+	}{
+		{
+			"-proxy-id is missing",
+			nil,
+			"-proxy-id is required",
+		},
+		{
+			"unknown format",
+			[]string{"-proxy-id=web-sidecar-proxy", "-format=yaml"},
+			"Unknown format: yaml",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ui := cli.NewMockUi()
+			cmd := New(ui)
+
+			code := cmd.Run(c.args)
+			require.Equal(t, 1, code)
+			require.Contains(t, ui.ErrorWriter.String(), c.expError)
+		})
+	}
+}
+
+func TestConnectProxyStatusCommand_notConnected(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+	a := agent.NewTestAgent(t, ``)
+	defer a.Shutdown()
+	testrpc.WaitForTestAgent(t, a.RPC, "dc1")
+
+	err := a.Client().Agent().ServiceRegister(&api.AgentServiceRegistration{
+		Kind: api.ServiceKindConnectProxy,
+		Name: "web-sidecar-proxy",
+		Port: 21000,
+		Proxy: &api.AgentServiceConnectProxyConfig{
+			DestinationServiceName: "web",
+			LocalServicePort:       8080,
+		},
+	})
+	require.NoError(t, err)
+
+	ui := cli.NewMockUi()
+	cmd := New(ui)
+	code := cmd.Run([]string{
+		"-http-addr=" + a.HTTPAddr(),
+		"-proxy-id=web-sidecar-proxy",
+	})
+	require.Equal(t, 1, code)
+	require.Contains(t, ui.ErrorWriter.String(), "is not connected to this agent")
+}
+
+func TestFormatStatus(t *testing.T) {
+	now := time.Date(2022, 5, 1, 12, 0, 0, 0, time.Local)
+	const (
+		clusterType  = "type.googleapis.com/envoy.config.cluster.v3.Cluster"
+		listenerType = "type.googleapis.com/envoy.config.listener.v3.Listener"
+	)
+
+	out := formatStatus(&api.AgentXDSStatus{
+		ProxyID:     "web-sidecar-proxy",
+		ConnectedAt: now,
+		Envoy:       api.AgentXDSStatusEnvoy{Version: "1.22.0"},
+		Snapshot: &api.AgentXDSStatusSnapshot{
+			ReceivedAt:     now,
+			Kind:           api.ServiceKindConnectProxy,
+			Service:        "web-sidecar-proxy",
+			Datacenter:     "dc1",
+			Valid:          true,
+			Upstreams:      []string{"db"},
+			LeafCertSerial: "01",
+			LeafCertExpiry: now.Add(72 * time.Hour),
+		},
+		Resources: map[string]*api.AgentXDSStatusResources{
+			clusterType: {
+				Versions:       map[string]string{"db.default.dc1.internal.11111111-2222-3333-4444-555555555555.consul": "a1"},
+				AckedVersions:  map[string]string{"db.default.dc1.internal.11111111-2222-3333-4444-555555555555.consul": "a1"},
+				LastSentNonce:  "00000001",
+				LastAckedNonce: "00000001",
+			},
+			listenerType: {
+				Versions:        map[string]string{"db:127.0.0.1:9191": "b2"},
+				AckedVersions:   map[string]string{},
+				LastSentNonce:   "00000002",
+				LastNackedNonce: "00000002",
+				LastNackError:   "cannot bind '127.0.0.1:9191': Address already in use",
+			},
+		},
+		Events: []api.AgentXDSStatusEvent{
+			{Time: now, Type: "send", TypeURL: clusterType, Nonce: "00000001", Upserted: []string{"db"}},
+			{Time: now, Type: "ack", TypeURL: clusterType, Nonce: "00000001"},
+			{Time: now, Type: "send", TypeURL: listenerType, Nonce: "00000002", Upserted: []string{"db:127.0.0.1:9191"}},
+			{Time: now, Type: "nack", TypeURL: listenerType, Nonce: "00000002", Error: "cannot bind '127.0.0.1:9191': Address already in use"},
+		},
+	})
+
+	stamp := now.Format(time.RFC3339)
+	for _, expect := range []string{
+		"Proxy ID:       web-sidecar-proxy",
+		"Envoy Version:  1.22.0",
+		"   Kind:              connect-proxy",
+		"   Leaf Cert Expiry:  " + now.Add(72*time.Hour).Format(time.RFC3339),
+		"   Upstreams:\n      db\n",
+		"   db.default.dc1.internal.11111111-2222-3333-4444-555555555555.consul  a1            a1",
+		"   db:127.0.0.1:9191  b2            <none>",
+		"   Last NACK Error:   cannot bind '127.0.0.1:9191': Address already in use",
+		"   " + stamp + "  SEND  " + clusterType + "    00000001  1 upserted, 0 removed",
+		"   " + stamp + "  NACK  " + listenerType + "  00000002  cannot bind '127.0.0.1:9191': Address already in use",
+	} {
+		require.Contains(t, out, expect)
+	}
+}
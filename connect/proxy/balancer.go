@@ -0,0 +1,592 @@
+package proxy
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"net"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+
+	"github.com/hashicorp/consul/acl"
+	agConnect "github.com/hashicorp/consul/agent/connect"
+	"github.com/hashicorp/consul/agent/structs"
+	"github.com/hashicorp/consul/api"
+	"github.com/hashicorp/consul/connect"
+	"github.com/hashicorp/consul/ipaddr"
+)
+
+const (
+	// The defaults match the ones of the outlier detection in Envoy.
+	defaultMaxFailures        = 5
+	defaultInterval           = 10 * time.Second
+	defaultBaseEjectionTime   = 30 * time.Second
+	defaultMaxEjectionTime    = 300 * time.Second
+	defaultMaxEjectionPercent = 10
+)
+
+// upstreamBalancer picks the instance each connection to an upstream service
+// is made to. Instances are discovered through the compiled discovery chain
+// of the upstream, so that the redirects, subsets, failover and splits
+// configured with config entries apply as they do with Envoy. Upstream
+// listeners proxy TCP, so routers only ever follow their default route and
+// splits are made per connection.
+//
+// Instances failing to accept connections too many times in a row are
+// ejected for a while, like Envoy's outlier detection does, and targets whose
+// instances keep failing to accept connections are failed over.
+type upstreamBalancer struct {
+	logger hclog.Logger
+
+	// fetchChain and fetchTarget query the discovery chain of the upstream and
+	// the instances of one of its targets. They are swapped out in tests.
+	fetchChain  func(ctx context.Context) (*api.CompiledDiscoveryChain, error)
+	fetchTarget func(ctx context.Context, target *api.DiscoveryTarget) ([]*api.ServiceEntry, error)
+
+	// passiveHealthCheck of the upstream takes precedence over the outlier
+	// detection of the resolvers of the chain.
+	passiveHealthCheck *structs.PassiveHealthCheck
+
+	lock sync.Mutex
+	// hosts tracks the instances with active connections, recent failures or
+	// ejections, keyed by address.
+	hosts map[string]*hostState
+	// targets tracks the consecutive failures to connect to the instances of
+	// each target, keyed by target ID.
+	targets map[string]*targetState
+	// next is the round robin position in each target, keyed by target ID.
+	next map[string]int
+}
+
+type hostState struct {
+	targetID string
+	active   int
+	failures uint32
+
+	// ejections is the number of times in a row the instance was ejected. It
+	// is decremented for every interval the instance stays in the pool.
+	ejections    uint32
+	ejectedUntil time.Time
+	decayedAt    time.Time
+	interval     time.Duration
+}
+
+type targetState struct {
+	// hosts is the number of instances of the target when last picked.
+	hosts        int
+	failures     uint32
+	failingUntil time.Time
+}
+
+// upstreamHost is an instance that may be dialed.
+type upstreamHost struct {
+	addr     string
+	certURI  agConnect.CertURI
+	targetID string
+	outlier  outlierPolicy
+}
+
+// outlierPolicy is the outlier detection applying to the instances of a
+// target, with the same meaning as for Envoy. Connection failures count
+// towards both the consecutive 5xx and the consecutive gateway failures.
+// Success rates can't be computed from TCP connections so they are ignored.
+type outlierPolicy struct {
+	maxFailures        uint32
+	interval           time.Duration
+	baseEjectionTime   time.Duration
+	maxEjectionPercent uint32
+}
+
+// makeOutlierPolicy merges the outlier detection of a resolver and the passive
+// health check of the upstream like xds.ToOutlierDetection does for Envoy.
+func makeOutlierPolicy(od *api.OutlierDetection, chk *structs.PassiveHealthCheck) outlierPolicy {
+	p := outlierPolicy{
+		maxFailures:        defaultMaxFailures,
+		interval:           defaultInterval,
+		baseEjectionTime:   defaultBaseEjectionTime,
+		maxEjectionPercent: defaultMaxEjectionPercent,
+	}
+	if od != nil {
+		if od.Interval > 0 {
+			p.interval = od.Interval
+		}
+		if od.Consecutive5xx > 0 {
+			p.maxFailures = od.Consecutive5xx
+		}
+		if od.ConsecutiveGatewayFailure > 0 && od.ConsecutiveGatewayFailure < p.maxFailures {
+			p.maxFailures = od.ConsecutiveGatewayFailure
+		}
+		if od.BaseEjectionTime > 0 {
+			p.baseEjectionTime = od.BaseEjectionTime
+		}
+		if od.MaxEjectionPercent > 0 {
+			p.maxEjectionPercent = od.MaxEjectionPercent
+		}
+	}
+	if chk != nil {
+		if chk.Interval > 0 {
+			p.interval = chk.Interval
+		}
+		if chk.MaxFailures > 0 {
+			p.maxFailures = chk.MaxFailures
+		}
+	}
+	return p
+}
+
+// ejectionTime returns how long an instance ejected for the given number of
+// times in a row stays out of the pool. Like with Envoy, it only returns at
+// the sweep following the multiplied base ejection time.
+func (p outlierPolicy) ejectionTime(ejections uint32) time.Duration {
+	d := p.baseEjectionTime * time.Duration(ejections)
+	max := defaultMaxEjectionTime
+	if p.baseEjectionTime > max {
+		max = p.baseEjectionTime
+	}
+	if d > max {
+		d = max
+	}
+	if rem := d % p.interval; rem != 0 {
+		d += p.interval - rem
+	}
+	return d
+}
+
+// decay decrements the ejections of the instance for every interval it stayed
+// in the pool since it was last ejected or decayed.
+func (s *hostState) decay(now time.Time) {
+	if s.ejections == 0 || s.interval <= 0 || now.Before(s.decayedAt) {
+		return
+	}
+	n := uint32(now.Sub(s.decayedAt) / s.interval)
+	if n > s.ejections {
+		n = s.ejections
+	}
+	s.ejections -= n
+	s.decayedAt = s.decayedAt.Add(time.Duration(n) * s.interval)
+}
+
+func newUpstreamBalancer(client *api.Client, cfg UpstreamConfig, logger hclog.Logger) *upstreamBalancer {
+	b := &upstreamBalancer{
+		logger:  logger,
+		hosts:   make(map[string]*hostState),
+		targets: make(map[string]*targetState),
+		next:    make(map[string]int),
+	}
+
+	// The passive health checks are configured like for Envoy.
+	upstreamCfg, err := structs.ParseUpstreamConfig(cfg.Config)
+	if err != nil {
+		logger.Warn("failed to parse upstream config, using defaults", "upstream", cfg.String(), "error", err)
+	}
+	b.passiveHealthCheck = upstreamCfg.PassiveHealthCheck
+
+	b.fetchChain = func(ctx context.Context) (*api.CompiledDiscoveryChain, error) {
+		entMeta := acl.NewEnterpriseMetaWithPartition(cfg.DestinationPartition, cfg.DestinationNamespace)
+		q := &api.QueryOptions{
+			UseCache:  true,
+			Namespace: entMeta.NamespaceOrEmpty(),
+			Partition: entMeta.PartitionOrEmpty(),
+		}
+		resp, _, err := client.DiscoveryChain().Get(cfg.DestinationName,
+			&api.DiscoveryChainOptions{EvaluateInDatacenter: cfg.Datacenter}, q.WithContext(ctx))
+		if err != nil {
+			return nil, err
+		}
+		return resp.Chain, nil
+	}
+	b.fetchTarget = func(ctx context.Context, target *api.DiscoveryTarget) ([]*api.ServiceEntry, error) {
+		entMeta := acl.NewEnterpriseMetaWithPartition(target.Partition, target.Namespace)
+		q := &api.QueryOptions{
+			AllowStale: true,
+			UseCache:   true,
+			Datacenter: target.Datacenter,
+			Namespace:  entMeta.NamespaceOrEmpty(),
+			Partition:  entMeta.PartitionOrEmpty(),
+			Peer:       cfg.DestinationPeer,
+			Filter:     target.Subset.Filter,
+		}
+		entries, _, err := client.Health().Connect(target.Service, "", target.Subset.OnlyPassing, q.WithContext(ctx))
+		return entries, err
+	}
+
+	// Like with Envoy, the discovery chain doesn't apply to upstreams
+	// imported from a cluster peer, their instances are queried directly.
+	if cfg.DestinationPeer != "" {
+		chain := peerUpstreamChain(cfg)
+		b.fetchChain = func(context.Context) (*api.CompiledDiscoveryChain, error) {
+			return chain, nil
+		}
+	}
+	return b
+}
+
+// peerUpstreamChain returns a chain resolving to all the instances of an
+// upstream imported from a cluster peer.
+func peerUpstreamChain(cfg UpstreamConfig) *api.CompiledDiscoveryChain {
+	id := fmt.Sprintf("%s.%s.%s.external.%s", cfg.DestinationName, cfg.DestinationNamespace, cfg.DestinationPartition, cfg.DestinationPeer)
+	return &api.CompiledDiscoveryChain{
+		ServiceName: cfg.DestinationName,
+		Namespace:   cfg.DestinationNamespace,
+		StartNode:   "resolver:" + id,
+		Nodes: map[string]*api.DiscoveryGraphNode{
+			"resolver:" + id: {
+				Type:     api.DiscoveryGraphNodeTypeResolver,
+				Name:     id,
+				Resolver: &api.DiscoveryResolver{Default: true, Target: id},
+			},
+		},
+		Targets: map[string]*api.DiscoveryTarget{
+			id: {
+				ID:        id,
+				Service:   cfg.DestinationName,
+				Namespace: cfg.DestinationNamespace,
+				Partition: cfg.DestinationPartition,
+			},
+		},
+	}
+}
+
+// Dial connects to an instance of the upstream, keeping track of the
+// connection until it is closed.
+func (b *upstreamBalancer) Dial(ctx context.Context, svc *connect.Service) (net.Conn, error) {
+	host, err := b.pick(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := svc.Dial(ctx, &connect.StaticResolver{Addr: host.addr, CertURI: host.certURI})
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	state, ok := b.hosts[host.addr]
+	if !ok {
+		state = &hostState{}
+		b.hosts[host.addr] = state
+	}
+	state.targetID = host.targetID
+	target, ok := b.targets[host.targetID]
+	if !ok {
+		target = &targetState{}
+		b.targets[host.targetID] = target
+	}
+
+	if err != nil {
+		now := time.Now()
+		state.failures++
+		if state.failures >= host.outlier.maxFailures {
+			b.eject(host, state, now)
+		}
+
+		// Instances may not be ejected because of the maximum ejection
+		// percentage, so the target is failed over once its instances
+		// failed that many times in a row.
+		target.failures++
+		if target.failures >= host.outlier.maxFailures {
+			b.logger.Warn("failing over upstream target after consecutive failures",
+				"target", host.targetID, "failures", target.failures, "duration", host.outlier.baseEjectionTime)
+			target.failingUntil = now.Add(host.outlier.baseEjectionTime)
+			target.failures = 0
+		}
+		return nil, err
+	}
+	state.failures = 0
+	state.active++
+	target.failures = 0
+
+	return &balancedConn{Conn: conn, release: func() { b.release(host.addr) }}, nil
+}
+
+// eject removes an instance from the pool, unless too many of the instances
+// of its target already are. At least one instance can always be ejected.
+// The lock must be held.
+func (b *upstreamBalancer) eject(host *upstreamHost, state *hostState, now time.Time) {
+	ejected := 0
+	for _, s := range b.hosts {
+		if s != state && s.targetID == host.targetID && now.Before(s.ejectedUntil) {
+			ejected++
+		}
+	}
+	if total := b.targets[host.targetID].hosts; ejected > 0 && uint32((ejected+1)*100) > host.outlier.maxEjectionPercent*uint32(total) {
+		return
+	}
+
+	state.decay(now)
+	state.ejections++
+	duration := host.outlier.ejectionTime(state.ejections)
+	b.logger.Warn("ejecting upstream instance after consecutive failures",
+		"addr", host.addr, "failures", state.failures, "duration", duration)
+	state.ejectedUntil = now.Add(duration)
+	state.decayedAt = state.ejectedUntil
+	state.interval = host.outlier.interval
+	state.failures = 0
+}
+
+func (b *upstreamBalancer) release(addr string) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	state, ok := b.hosts[addr]
+	if !ok {
+		return
+	}
+	state.active--
+	now := time.Now()
+	state.decay(now)
+	if state.active <= 0 && state.failures == 0 && state.ejections == 0 && now.After(state.ejectedUntil) {
+		delete(b.hosts, addr)
+	}
+}
+
+// pick walks the discovery chain of the upstream to the instance the next
+// connection should be made to.
+func (b *upstreamBalancer) pick(ctx context.Context) (*upstreamHost, error) {
+	chain, err := b.fetchChain(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	nodeName := chain.StartNode
+	// Compiled chains are acyclic, the limit only guards against bugs.
+	for i := 0; i < len(chain.Nodes); i++ {
+		node, ok := chain.Nodes[nodeName]
+		if !ok {
+			return nil, fmt.Errorf("discovery chain for %q has no node %q", chain.ServiceName, nodeName)
+		}
+
+		switch node.Type {
+		case api.DiscoveryGraphNodeTypeRouter:
+			// The last route is the default one, matching every request.
+			if len(node.Routes) == 0 {
+				return nil, fmt.Errorf("router %q has no routes", node.Name)
+			}
+			nodeName = node.Routes[len(node.Routes)-1].NextNode
+		case api.DiscoveryGraphNodeTypeSplitter:
+			nodeName = pickSplit(node.Splits)
+		case api.DiscoveryGraphNodeTypeResolver:
+			return b.pickFromResolver(ctx, chain, node)
+		default:
+			return nil, fmt.Errorf("unknown discovery chain node type %q", node.Type)
+		}
+	}
+	return nil, fmt.Errorf("discovery chain for %q has a cycle", chain.ServiceName)
+}
+
+// pickFromResolver picks an instance of the resolver's target, or of its
+// failover targets in order when the target has no healthy instances or its
+// instances keep failing to accept connections.
+func (b *upstreamBalancer) pickFromResolver(ctx context.Context, chain *api.CompiledDiscoveryChain, node *api.DiscoveryGraphNode) (*upstreamHost, error) {
+	targetIDs := []string{node.Resolver.Target}
+	if node.Resolver.Failover != nil {
+		targetIDs = append(targetIDs, node.Resolver.Failover.Targets...)
+	}
+	outlier := makeOutlierPolicy(node.Resolver.OutlierDetection, b.passiveHealthCheck)
+
+	policy := ""
+	if node.LoadBalancer != nil {
+		policy = node.LoadBalancer.Policy
+	}
+
+	var (
+		lastErr error
+		// fallback is the first target with instances, should all of them be
+		// ejected.
+		fallbackID    string
+		fallbackHosts []*upstreamHost
+	)
+	for _, id := range targetIDs {
+		target, ok := chain.Targets[id]
+		if !ok {
+			lastErr = fmt.Errorf("discovery chain for %q has no target %q", chain.ServiceName, id)
+			continue
+		}
+		entries, err := b.fetchTarget(ctx, target)
+		if err != nil {
+			// The target may be in an unreachable datacenter, which is what
+			// failover is for.
+			lastErr = err
+			continue
+		}
+
+		hosts := targetHosts(target, entries, outlier)
+		if len(hosts) > 0 && fallbackHosts == nil {
+			fallbackID, fallbackHosts = id, hosts
+		}
+		if !b.targetAvailable(id, len(hosts)) {
+			continue
+		}
+		if host := b.choose(id, policy, b.withoutEjected(hosts)); host != nil {
+			return host, nil
+		}
+	}
+
+	// Like Envoy, rather fail over to ejected instances than have none at all.
+	if host := b.choose(fallbackID, policy, fallbackHosts); host != nil {
+		return host, nil
+	}
+	if lastErr != nil {
+		return nil, lastErr
+	}
+	return nil, fmt.Errorf("no healthy instances found")
+}
+
+// targetAvailable records the number of instances of a target and returns
+// whether it is not being failed over.
+func (b *upstreamBalancer) targetAvailable(id string, hosts int) bool {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	state, ok := b.targets[id]
+	if !ok {
+		state = &targetState{}
+		b.targets[id] = state
+	}
+	state.hosts = hosts
+	return !time.Now().Before(state.failingUntil)
+}
+
+func (b *upstreamBalancer) withoutEjected(hosts []*upstreamHost) []*upstreamHost {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	now := time.Now()
+	var out []*upstreamHost
+	for _, h := range hosts {
+		if state, ok := b.hosts[h.addr]; ok && now.Before(state.ejectedUntil) {
+			continue
+		}
+		out = append(out, h)
+	}
+	return out
+}
+
+// choose picks one of the hosts of a target according to the load balancing
+// policy of its resolver. Envoy's hash based policies have nothing to hash
+// on for TCP connections, so like Envoy those pick at random.
+func (b *upstreamBalancer) choose(targetID, policy string, hosts []*upstreamHost) *upstreamHost {
+	if len(hosts) == 0 {
+		return nil
+	}
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	switch policy {
+	case "", structs.LBPolicyRoundRobin:
+		idx := b.next[targetID] % len(hosts)
+		b.next[targetID] = idx + 1
+		return hosts[idx]
+
+	case structs.LBPolicyLeastRequest:
+		// Ties are broken at random so that new instances don't get all the
+		// connections.
+		var (
+			best  *upstreamHost
+			least = -1
+			ties  = 0
+		)
+		for _, h := range hosts {
+			active := 0
+			if state, ok := b.hosts[h.addr]; ok {
+				active = state.active
+			}
+			switch {
+			case least < 0 || active < least:
+				best, least, ties = h, active, 1
+			case active == least:
+				ties++
+				if rand.Intn(ties) == 0 {
+					best = h
+				}
+			}
+		}
+		return best
+
+	default:
+		return hosts[rand.Intn(len(hosts))]
+	}
+}
+
+// pickSplit picks the next node of one of the splits according to their
+// weights, which are percentages.
+func pickSplit(splits []*api.DiscoverySplit) string {
+	if len(splits) == 0 {
+		return ""
+	}
+	n := rand.Float32() * 100
+	for _, split := range splits {
+		if n < split.Weight {
+			return split.NextNode
+		}
+		n -= split.Weight
+	}
+	// Rounding may leave some of the 100% unaccounted for.
+	return splits[len(splits)-1].NextNode
+}
+
+// targetHosts returns the healthy instances of a target in a stable order.
+// Warning instances are healthy unless the target only allows passing ones,
+// which were already filtered by the query.
+func targetHosts(target *api.DiscoveryTarget, entries []*api.ServiceEntry, outlier outlierPolicy) []*upstreamHost {
+	var hosts []*upstreamHost
+	for _, entry := range entries {
+		if status := entry.Checks.AggregatedStatus(); status != api.HealthPassing && status != api.HealthWarning {
+			continue
+		}
+
+		var service string
+		switch {
+		case entry.Service.Connect != nil && entry.Service.Connect.Native:
+			service = entry.Service.Service
+		case entry.Service.Proxy != nil:
+			service = entry.Service.Proxy.DestinationServiceName
+		}
+		if service == "" {
+			continue
+		}
+
+		addr := entry.Service.Address
+		if addr == "" {
+			addr = entry.Node.Address
+		}
+
+		namespace := target.Namespace
+		if namespace == "" {
+			namespace = "default"
+		}
+		hosts = append(hosts, &upstreamHost{
+			addr: ipaddr.FormatAddressPort(addr, entry.Service.Port),
+			certURI: &agConnect.SpiffeIDService{
+				// No host since we don't validate trust domain here (we rely on
+				// x509 to prove trust).
+				Partition:  target.Partition,
+				Namespace:  namespace,
+				Datacenter: entry.Node.Datacenter,
+				Service:    service,
+			},
+			targetID: target.ID,
+			outlier:  outlier,
+		})
+	}
+
+	sort.Slice(hosts, func(i, j int) bool {
+		return hosts[i].addr < hosts[j].addr
+	})
+	return hosts
+}
+
+// balancedConn releases its instance in the balancer once closed.
+type balancedConn struct {
+	net.Conn
+	once    sync.Once
+	release func()
+}
+
+func (c *balancedConn) Close() error {
+	c.once.Do(c.release)
+	return c.Conn.Close()
+}
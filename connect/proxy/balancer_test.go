@@ -0,0 +1,421 @@
+package proxy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hashicorp/consul/agent"
+	agConnect "github.com/hashicorp/consul/agent/connect"
+	"github.com/hashicorp/consul/agent/structs"
+	"github.com/hashicorp/consul/api"
+	"github.com/hashicorp/consul/connect"
+	"github.com/hashicorp/consul/sdk/freeport"
+	"github.com/hashicorp/consul/sdk/testutil"
+	"github.com/hashicorp/consul/sdk/testutil/retry"
+	"github.com/hashicorp/consul/testrpc"
+)
+
+// testBalancer returns a balancer for the given chain, whose targets have the
+// instances listening on the given ports, keyed by target ID.
+func testBalancer(t *testing.T, chain *api.CompiledDiscoveryChain, instances map[string][]int) *upstreamBalancer {
+	b := newUpstreamBalancer(nil, UpstreamConfig{DestinationName: chain.ServiceName}, testutil.Logger(t))
+	b.fetchChain = func(context.Context) (*api.CompiledDiscoveryChain, error) {
+		return chain, nil
+	}
+	b.fetchTarget = func(_ context.Context, target *api.DiscoveryTarget) ([]*api.ServiceEntry, error) {
+		ports, ok := instances[target.ID]
+		if !ok {
+			return nil, errors.New("unreachable")
+		}
+		var entries []*api.ServiceEntry
+		for _, port := range ports {
+			entries = append(entries, &api.ServiceEntry{
+				Node: &api.Node{Address: "127.0.0.1", Datacenter: target.Datacenter},
+				Service: &api.AgentService{
+					Service: target.Service,
+					Port:    port,
+					Connect: &api.AgentServiceConnect{Native: true},
+				},
+			})
+		}
+		return entries, nil
+	}
+	return b
+}
+
+func testResolverChain(policy string, failover ...string) *api.CompiledDiscoveryChain {
+	chain := &api.CompiledDiscoveryChain{
+		ServiceName: "db",
+		StartNode:   "resolver:db.default.dc1",
+		Nodes: map[string]*api.DiscoveryGraphNode{
+			"resolver:db.default.dc1": {
+				Type:     api.DiscoveryGraphNodeTypeResolver,
+				Name:     "db.default.dc1",
+				Resolver: &api.DiscoveryResolver{Target: "db.default.dc1"},
+			},
+		},
+		Targets: map[string]*api.DiscoveryTarget{
+			"db.default.dc1": {ID: "db.default.dc1", Service: "db", Datacenter: "dc1"},
+			"db.default.dc2": {ID: "db.default.dc2", Service: "db", Datacenter: "dc2"},
+		},
+	}
+	node := chain.Nodes[chain.StartNode]
+	if policy != "" {
+		node.LoadBalancer = &api.LoadBalancer{Policy: policy}
+	}
+	if len(failover) > 0 {
+		node.Resolver.Failover = &api.DiscoveryFailover{Targets: failover}
+	}
+	return chain
+}
+
+func testPickAddrs(t *testing.T, b *upstreamBalancer, n int) []string {
+	t.Helper()
+	var addrs []string
+	for i := 0; i < n; i++ {
+		host, err := b.pick(context.Background())
+		require.NoError(t, err)
+		addrs = append(addrs, host.addr)
+	}
+	return addrs
+}
+
+func TestUpstreamBalancer_RoundRobin(t *testing.T) {
+	b := testBalancer(t, testResolverChain(""), map[string][]int{
+		"db.default.dc1": {8082, 8080, 8081},
+	})
+
+	require.Equal(t, []string{
+		"127.0.0.1:8080", "127.0.0.1:8081", "127.0.0.1:8082", "127.0.0.1:8080",
+	}, testPickAddrs(t, b, 4))
+}
+
+func TestUpstreamBalancer_LeastRequest(t *testing.T) {
+	b := testBalancer(t, testResolverChain("least_request"), map[string][]int{
+		"db.default.dc1": {8080, 8081},
+	})
+	b.hosts["127.0.0.1:8080"] = &hostState{active: 3}
+	b.hosts["127.0.0.1:8081"] = &hostState{active: 1}
+
+	require.Equal(t, []string{"127.0.0.1:8081", "127.0.0.1:8081"}, testPickAddrs(t, b, 2))
+}
+
+func TestUpstreamBalancer_Failover(t *testing.T) {
+	b := testBalancer(t, testResolverChain("", "db.default.dc2"), map[string][]int{
+		"db.default.dc1": nil,
+		"db.default.dc2": {9090},
+	})
+
+	host, err := b.pick(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "127.0.0.1:9090", host.addr)
+	require.Equal(t, "spiffe:///ns/default/dc/dc2/svc/db", host.certURI.URI().String())
+
+	// Unreachable datacenters are failed over too.
+	b = testBalancer(t, testResolverChain("", "db.default.dc2"), map[string][]int{
+		"db.default.dc2": {9090},
+	})
+	host, err = b.pick(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "127.0.0.1:9090", host.addr)
+
+	b = testBalancer(t, testResolverChain(""), map[string][]int{
+		"db.default.dc1": nil,
+	})
+	_, err = b.pick(context.Background())
+	require.EqualError(t, err, "no healthy instances found")
+}
+
+func TestUpstreamBalancer_Ejection(t *testing.T) {
+	b := testBalancer(t, testResolverChain("", "db.default.dc2"), map[string][]int{
+		"db.default.dc1": {8080, 8081},
+		"db.default.dc2": {9090},
+	})
+	b.hosts["127.0.0.1:8080"] = &hostState{ejectedUntil: time.Now().Add(time.Hour)}
+
+	require.Equal(t, []string{"127.0.0.1:8081", "127.0.0.1:8081"}, testPickAddrs(t, b, 2))
+
+	// Once all instances of the target are ejected its failover is used.
+	b.hosts["127.0.0.1:8081"] = &hostState{ejectedUntil: time.Now().Add(time.Hour)}
+	require.Equal(t, []string{"127.0.0.1:9090"}, testPickAddrs(t, b, 1))
+
+	// And when all of them are ejected too the target is used anyway.
+	b.hosts["127.0.0.1:9090"] = &hostState{ejectedUntil: time.Now().Add(time.Hour)}
+	require.Contains(t, []string{"127.0.0.1:8080", "127.0.0.1:8081"}, testPickAddrs(t, b, 1)[0])
+}
+
+func TestUpstreamBalancer_Dial(t *testing.T) {
+	ca := agConnect.TestCA(t, nil)
+	svc := connect.TestService(t, "web", ca)
+
+	testSvr := connect.NewTestServer(t, "db", ca)
+	go func() {
+		err := testSvr.Serve()
+		require.NoError(t, err)
+	}()
+	defer testSvr.Close()
+	<-testSvr.Listening
+	_, portStr, err := net.SplitHostPort(testSvr.Addr)
+	require.NoError(t, err)
+	port, err := strconv.Atoi(portStr)
+	require.NoError(t, err)
+
+	b := testBalancer(t, testResolverChain(""), map[string][]int{
+		"db.default.dc1": {port},
+	})
+	conn, err := b.Dial(context.Background(), svc)
+	require.NoError(t, err)
+	require.Equal(t, 1, b.hosts[testSvr.Addr].active)
+
+	// Closed connections are no longer tracked.
+	require.NoError(t, conn.Close())
+	require.NotContains(t, b.hosts, testSvr.Addr)
+
+	// Nothing listens on this one, it is ejected after enough failures.
+	closedPort := freeport.GetOne(t)
+	closedAddr := fmt.Sprintf("127.0.0.1:%d", closedPort)
+	b = testBalancer(t, testResolverChain(""), map[string][]int{
+		"db.default.dc1": {closedPort},
+	})
+	b.passiveHealthCheck = &structs.PassiveHealthCheck{MaxFailures: 2}
+
+	_, err = b.Dial(context.Background(), svc)
+	require.Error(t, err)
+	require.Equal(t, uint32(1), b.hosts[closedAddr].failures)
+	require.True(t, b.hosts[closedAddr].ejectedUntil.IsZero())
+
+	_, err = b.Dial(context.Background(), svc)
+	require.Error(t, err)
+	require.True(t, b.hosts[closedAddr].ejectedUntil.After(time.Now()))
+	require.Equal(t, uint32(1), b.hosts[closedAddr].ejections)
+}
+
+func TestUpstreamBalancer_FailoverOnDialErrors(t *testing.T) {
+	ca := agConnect.TestCA(t, nil)
+	svc := connect.TestService(t, "web", ca)
+
+	// Nothing listens on these. With the default maximum ejection percentage
+	// only one of them can be ejected at once.
+	ports := freeport.GetN(t, 3)
+	b := testBalancer(t, testResolverChain("", "db.default.dc2"), map[string][]int{
+		"db.default.dc1": ports,
+		"db.default.dc2": {9090},
+	})
+	b.passiveHealthCheck = &structs.PassiveHealthCheck{MaxFailures: 3}
+
+	for i := 0; i < 3; i++ {
+		host, err := b.pick(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, "db.default.dc1", host.targetID)
+
+		_, err = b.Dial(context.Background(), svc)
+		require.Error(t, err)
+	}
+	for _, port := range ports {
+		require.True(t, b.hosts[fmt.Sprintf("127.0.0.1:%d", port)].ejectedUntil.IsZero())
+	}
+
+	// The target failed too many times in a row, so it is failed over.
+	require.Equal(t, []string{"127.0.0.1:9090", "127.0.0.1:9090"}, testPickAddrs(t, b, 2))
+}
+
+func TestUpstreamBalancer_MaxEjectionPercent(t *testing.T) {
+	b := testBalancer(t, testResolverChain(""), map[string][]int{
+		"db.default.dc1": {8080, 8081, 8082},
+	})
+	hosts := testPickAddrs(t, b, 3)
+	require.Equal(t, 3, b.targets["db.default.dc1"].hosts)
+
+	outlier := makeOutlierPolicy(&api.OutlierDetection{MaxEjectionPercent: 50}, nil)
+	eject := func(addr string) *hostState {
+		state := &hostState{targetID: "db.default.dc1"}
+		b.hosts[addr] = state
+		b.eject(&upstreamHost{addr: addr, targetID: "db.default.dc1", outlier: outlier}, state, time.Now())
+		return state
+	}
+
+	// At least one instance can always be ejected, but no more than half of
+	// them.
+	require.False(t, eject(hosts[0]).ejectedUntil.IsZero())
+	require.True(t, eject(hosts[1]).ejectedUntil.IsZero())
+}
+
+func TestUpstreamBalancer_OutlierPolicy(t *testing.T) {
+	require.Equal(t, outlierPolicy{
+		maxFailures:        defaultMaxFailures,
+		interval:           defaultInterval,
+		baseEjectionTime:   defaultBaseEjectionTime,
+		maxEjectionPercent: defaultMaxEjectionPercent,
+	}, makeOutlierPolicy(nil, nil))
+
+	od := &api.OutlierDetection{
+		Interval:                  5 * time.Second,
+		Consecutive5xx:            4,
+		ConsecutiveGatewayFailure: 2,
+		BaseEjectionTime:          20 * time.Second,
+		MaxEjectionPercent:        50,
+	}
+	require.Equal(t, outlierPolicy{
+		maxFailures:        2,
+		interval:           5 * time.Second,
+		baseEjectionTime:   20 * time.Second,
+		maxEjectionPercent: 50,
+	}, makeOutlierPolicy(od, nil))
+
+	// The passive health check of the upstream takes precedence.
+	p := makeOutlierPolicy(od, &structs.PassiveHealthCheck{Interval: 7 * time.Second, MaxFailures: 3})
+	require.Equal(t, outlierPolicy{
+		maxFailures:        3,
+		interval:           7 * time.Second,
+		baseEjectionTime:   20 * time.Second,
+		maxEjectionPercent: 50,
+	}, p)
+
+	// Instances are ejected for the base ejection time multiplied by the
+	// number of ejections, until the next sweep.
+	require.Equal(t, 21*time.Second, p.ejectionTime(1))
+	require.Equal(t, 42*time.Second, p.ejectionTime(2))
+	require.Equal(t, 301*time.Second, p.ejectionTime(100))
+
+	// Ejections decay for every interval an instance stays in the pool.
+	now := time.Now()
+	state := &hostState{ejections: 3, decayedAt: now, interval: 10 * time.Second}
+	state.decay(now.Add(25 * time.Second))
+	require.Equal(t, uint32(1), state.ejections)
+	require.Equal(t, now.Add(20*time.Second), state.decayedAt)
+	state.decay(now.Add(time.Hour))
+	require.Equal(t, uint32(0), state.ejections)
+}
+
+func TestUpstreamBalancer_OutlierDetectionFromResolver(t *testing.T) {
+	chain := testResolverChain("")
+	chain.Nodes[chain.StartNode].Resolver.OutlierDetection = &api.OutlierDetection{
+		Consecutive5xx:   2,
+		BaseEjectionTime: time.Minute,
+	}
+	b := testBalancer(t, chain, map[string][]int{
+		"db.default.dc1": {8080},
+	})
+
+	host, err := b.pick(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, uint32(2), host.outlier.maxFailures)
+	require.Equal(t, time.Minute, host.outlier.baseEjectionTime)
+}
+
+func TestUpstreamBalancer_Peer(t *testing.T) {
+	var query url.Values
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/v1/health/connect/db", r.URL.Path)
+		query = r.URL.Query()
+		w.Write([]byte(`[{
+			"Node": {"Address": "10.0.0.1", "Datacenter": "dc2"},
+			"Service": {"Service": "db", "Port": 8080, "Connect": {"Native": true}},
+			"Checks": []
+		}]`))
+	}))
+	defer srv.Close()
+
+	client, err := api.NewClient(&api.Config{Address: srv.Listener.Addr().String()})
+	require.NoError(t, err)
+
+	cfg := UpstreamConfig{DestinationName: "db", DestinationPeer: "cluster-01"}
+	cfg.applyDefaults()
+	b := newUpstreamBalancer(client, cfg, testutil.Logger(t))
+
+	// The instances imported from the peer are queried without a discovery
+	// chain.
+	host, err := b.pick(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "10.0.0.1:8080", host.addr)
+	require.Equal(t, "cluster-01", query.Get("peer"))
+}
+
+func TestUpstreamBalancer_Splitter(t *testing.T) {
+	chain := testResolverChain("")
+	chain.StartNode = "splitter:db"
+	chain.Nodes["splitter:db"] = &api.DiscoveryGraphNode{
+		Type: api.DiscoveryGraphNodeTypeSplitter,
+		Name: "db",
+		Splits: []*api.DiscoverySplit{
+			{Weight: 0, NextNode: "resolver:db.default.dc1"},
+			{Weight: 100, NextNode: "resolver:db.default.dc2"},
+		},
+	}
+	chain.Nodes["resolver:db.default.dc2"] = &api.DiscoveryGraphNode{
+		Type:     api.DiscoveryGraphNodeTypeResolver,
+		Name:     "db.default.dc2",
+		Resolver: &api.DiscoveryResolver{Target: "db.default.dc2"},
+	}
+
+	b := testBalancer(t, chain, map[string][]int{
+		"db.default.dc1": {8080},
+		"db.default.dc2": {9090},
+	})
+	require.Equal(t, []string{"127.0.0.1:9090", "127.0.0.1:9090", "127.0.0.1:9090"}, testPickAddrs(t, b, 3))
+
+	// Even splits eventually reach both sides.
+	chain.Nodes["splitter:db"].Splits[0].Weight = 50
+	chain.Nodes["splitter:db"].Splits[1].Weight = 50
+	require.Subset(t, testPickAddrs(t, b, 100), []string{"127.0.0.1:8080", "127.0.0.1:9090"})
+}
+
+func TestUpstreamBalancer_DiscoveryChain(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	a := agent.NewTestAgent(t, "")
+	defer a.Shutdown()
+	testrpc.WaitForTestAgent(t, a.RPC, "dc1")
+	client := a.Client()
+
+	for name, port := range map[string]int{"db": 8080, "db-v2": 8081} {
+		_, err := client.Catalog().Register(&api.CatalogRegistration{
+			Datacenter: "dc1",
+			Node:       "local",
+			Address:    "127.0.0.1",
+			Service: &api.AgentService{
+				Service: name,
+				Port:    port,
+				Connect: &api.AgentServiceConnect{Native: true},
+			},
+		}, nil)
+		require.NoError(t, err)
+	}
+
+	b := newUpstreamBalancer(client, UpstreamConfig{
+		DestinationName:      "db",
+		DestinationNamespace: "default",
+		DestinationPartition: "default",
+	}, testutil.Logger(t))
+
+	host, err := b.pick(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "127.0.0.1:8080", host.addr)
+
+	// Redirecting the upstream changes the instances it resolves to.
+	_, _, err = client.ConfigEntries().Set(&api.ServiceResolverConfigEntry{
+		Kind:     api.ServiceResolver,
+		Name:     "db",
+		Redirect: &api.ServiceResolverRedirect{Service: "db-v2"},
+	}, nil)
+	require.NoError(t, err)
+
+	retry.Run(t, func(r *retry.R) {
+		host, err := b.pick(context.Background())
+		require.NoError(r, err)
+		require.Equal(r, "127.0.0.1:8081", host.addr)
+		require.Equal(r, "spiffe:///ns/default/dc/dc1/svc/db-v2", host.certURI.URI().String())
+	})
+}
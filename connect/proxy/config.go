@@ -70,6 +70,24 @@ type PublicListenerConfig struct {
 	// handshake. Setting this low avoids DOS by malicious clients holding
 	// resources open. Defaults to 10000 (10s).
 	HandshakeTimeoutMs int `json:"handshake_timeout_ms" hcl:"handshake_timeout_ms" mapstructure:"handshake_timeout_ms"`
+
+	// Protocol is the protocol spoken by the local application, usually set
+	// for the service with a proxy-defaults or service-defaults config entry.
+	// With "http", "http2" or "grpc" the listener proxies HTTP requests and
+	// authorizes each of them, so L7 intentions are enforced. Anything else is
+	// proxied as TCP.
+	Protocol string `json:"protocol" hcl:"protocol" mapstructure:"protocol"`
+}
+
+// isHTTP returns whether the public listener proxies HTTP requests rather
+// than TCP connections.
+func (plc *PublicListenerConfig) isHTTP() bool {
+	switch plc.Protocol {
+	case "http", "http2", "grpc":
+		return true
+	default:
+		return false
+	}
 }
 
 // applyDefaults sets zero-valued params to a reasonable default.
@@ -102,6 +102,7 @@ func TestAgentConfigWatcherSidecarProxy(t *testing.T) {
 				Proxy: &api.AgentServiceConnectProxyConfig{
 					Config: map[string]interface{}{
 						"handshake_timeout_ms": 999,
+						"protocol":             "http",
 					},
 					Upstreams: []api.Upstream{
 						{
@@ -131,6 +132,7 @@ func TestAgentConfigWatcherSidecarProxy(t *testing.T) {
 			LocalServiceAddress:   "127.0.0.1:8080",
 			HandshakeTimeoutMs:    999,
 			LocalConnectTimeoutMs: 1000, // from applyDefaults
+			Protocol:              "http",
 		},
 		Upstreams: []UpstreamConfig{
 			{
@@ -0,0 +1,206 @@
+package proxy
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	metrics "github.com/armon/go-metrics"
+	"github.com/hashicorp/go-hclog"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+
+	agConnect "github.com/hashicorp/consul/agent/connect"
+)
+
+// connContextKey is the context key of the connection an HTTP request was
+// received on.
+type connContextKey struct{}
+
+// newLocalHTTPProxy returns a reverse proxy sending requests to the local
+// application. HTTP/2 and gRPC applications are spoken to over h2c.
+func newLocalHTTPProxy(cfg PublicListenerConfig, logger hclog.Logger) *httputil.ReverseProxy {
+	dialer := &net.Dialer{
+		Timeout: time.Duration(cfg.LocalConnectTimeoutMs) * time.Millisecond,
+	}
+
+	var (
+		transport     http.RoundTripper
+		flushInterval time.Duration
+	)
+	if cfg.Protocol == "http" {
+		transport = &http.Transport{
+			DialContext:     dialer.DialContext,
+			IdleConnTimeout: 90 * time.Second,
+		}
+	} else {
+		transport = &http2.Transport{
+			AllowHTTP: true,
+			DialTLS: func(network, addr string, _ *tls.Config) (net.Conn, error) {
+				return dialer.Dial(network, addr)
+			},
+		}
+		// Streams, gRPC ones in particular, must not wait on a buffer to fill.
+		flushInterval = -1
+	}
+
+	return &httputil.ReverseProxy{
+		Director: func(r *http.Request) {
+			r.URL.Scheme = "http"
+			r.URL.Host = cfg.LocalServiceAddress
+		},
+		Transport:     transport,
+		FlushInterval: flushInterval,
+		ErrorLog:      logger.StandardLogger(&hclog.StandardLoggerOptions{InferLevels: true}),
+	}
+}
+
+// serveHTTP serves the connections accepted by the listener with an HTTP
+// server, accepting both HTTP/1.1 and h2c.
+func (l *Listener) serveHTTP(listener net.Listener) error {
+	srv := &http.Server{
+		Handler: h2c.NewHandler(http.HandlerFunc(l.handleHTTP), &http2.Server{}),
+		ConnContext: func(ctx context.Context, c net.Conn) context.Context {
+			return context.WithValue(ctx, connContextKey{}, c)
+		},
+		ErrorLog: l.logger.StandardLogger(&hclog.StandardLoggerOptions{InferLevels: true}),
+	}
+
+	err := srv.Serve(&trackedListener{Listener: listener, l: l})
+	if atomic.LoadInt32(&l.stopFlag) == 1 {
+		return nil
+	}
+	return err
+}
+
+// handleHTTP authorizes a request against the intentions of the service and
+// proxies it to the local application if it is allowed.
+func (l *Listener) handleHTTP(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+
+	var state tls.ConnectionState
+	if c, ok := r.Context().Value(connContextKey{}).(*trackedConn); ok {
+		if tlsConn, ok := c.Conn.(*tls.Conn); ok {
+			state = tlsConn.ConnectionState()
+		}
+	}
+
+	rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+	resp, err := l.Service.AuthorizeHTTP(state, r)
+	switch {
+	case err != nil:
+		l.logger.Error("authz call failed", "error", err)
+		http.Error(rec, "connect: authz call failed", http.StatusInternalServerError)
+	case !resp.Authorized:
+		l.logger.Debug("request denied", "method", r.Method, "path", r.URL.Path, "reason", resp.Reason)
+		http.Error(rec, "connect: authz denied", http.StatusForbidden)
+	default:
+		l.httpProxy.ServeHTTP(rec, r)
+	}
+
+	labels := append([]metrics.Label{}, l.metricLabels...)
+	labels = append(labels, metrics.Label{Name: "src", Value: sourceService(state)})
+	metrics.MeasureSinceWithLabels([]string{l.metricPrefix, "http_request_time"}, start, labels)
+	labels = append(labels, metrics.Label{Name: "code", Value: strconv.Itoa(rec.status)})
+	metrics.IncrCounterWithLabels([]string{l.metricPrefix, "http_requests"}, 1, labels)
+}
+
+// sourceService returns the name of the service identified by the client
+// certificate of the connection, or "unknown".
+func sourceService(state tls.ConnectionState) string {
+	if len(state.PeerCertificates) < 1 || len(state.PeerCertificates[0].URIs) < 1 {
+		return "unknown"
+	}
+	certURI, err := agConnect.ParseCertURI(state.PeerCertificates[0].URIs[0])
+	if err != nil {
+		return "unknown"
+	}
+	if svc, ok := certURI.(*agConnect.SpiffeIDService); ok {
+		return svc.Service
+	}
+	return "unknown"
+}
+
+// statusRecorder records the status code of a response for metrics.
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (r *statusRecorder) WriteHeader(code int) {
+	if !r.wroteHeader {
+		r.status = code
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(code)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	r.wroteHeader = true
+	return r.ResponseWriter.Write(b)
+}
+
+// Flush lets the reverse proxy stream responses through the recorder.
+func (r *statusRecorder) Flush() {
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// trackedListener counts the connections it accepts as active connections of
+// the Listener, and closes them when the Listener is closed. The HTTP server
+// can't do the latter itself for h2c connections, which it hands off.
+type trackedListener struct {
+	net.Listener
+	l *Listener
+}
+
+func (t *trackedListener) Accept() (net.Conn, error) {
+	conn, err := t.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+
+	// Make sure Listener.Close waits for this conn to be cleaned up.
+	t.l.connWG.Add(1)
+	c := &trackedConn{
+		Conn:    conn,
+		closeCh: make(chan struct{}),
+		untrack: t.l.trackConn(),
+		done:    t.l.connWG.Done,
+	}
+	go func() {
+		select {
+		case <-t.l.stopChan:
+			c.Close()
+		case <-c.closeCh:
+		}
+	}()
+	return c, nil
+}
+
+type trackedConn struct {
+	net.Conn
+
+	closeOnce sync.Once
+	closeCh   chan struct{}
+	untrack   func()
+	done      func()
+}
+
+func (c *trackedConn) Close() error {
+	err := c.Conn.Close()
+	c.closeOnce.Do(func() {
+		close(c.closeCh)
+		c.untrack()
+		c.done()
+	})
+	return err
+}
@@ -5,6 +5,7 @@ import (
 	"crypto/tls"
 	"errors"
 	"net"
+	"net/http/httputil"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -34,6 +35,10 @@ type Listener struct {
 	dialFunc   func() (net.Conn, error)
 	bindAddr   string
 
+	// httpProxy is set for public listeners proxying HTTP, which serve each
+	// connection with an HTTP server instead of copying its bytes.
+	httpProxy *httputil.ReverseProxy
+
 	stopFlag int32
 	stopChan chan struct{}
 
@@ -61,10 +66,12 @@ type Listener struct {
 
 // NewPublicListener returns a Listener setup to listen for public mTLS
 // connections and proxy them to the configured local application over TCP.
+// When the application speaks HTTP the requests are proxied instead, and each
+// of them is authorized so that L7 intentions are enforced.
 func NewPublicListener(svc *connect.Service, cfg PublicListenerConfig,
 	logger hclog.Logger) *Listener {
 	bindAddr := ipaddr.FormatAddressPort(cfg.BindAddress, cfg.BindPort)
-	return &Listener{
+	l := &Listener{
 		Service: svc,
 		listenFunc: func() (net.Listener, error) {
 			return tls.Listen("tcp", bindAddr, svc.ServerTLSConfig())
@@ -88,19 +95,49 @@ func NewPublicListener(svc *connect.Service, cfg PublicListenerConfig,
 		// seems for the extra complication of tracking many gauges here.
 		metricLabels: []metrics.Label{{Name: "dst", Value: svc.Name()}},
 	}
+
+	if cfg.isHTTP() {
+		// Connections are only verified during the handshake, intentions are
+		// checked against each request.
+		l.listenFunc = func() (net.Listener, error) {
+			return tls.Listen("tcp", bindAddr, svc.ServerTLSConfigWithoutAuthz())
+		}
+		l.httpProxy = newLocalHTTPProxy(cfg, l.logger)
+	}
+	return l
 }
 
 // NewUpstreamListener returns a Listener setup to listen locally for TCP
 // connections that are proxied to a discovered Connect service instance.
+// Service instances are discovered through the upstream's discovery chain and
+// load balanced, prepared query results are picked from at random.
 func NewUpstreamListener(svc *connect.Service, client *api.Client,
 	cfg UpstreamConfig, logger hclog.Logger) *Listener {
-	return newUpstreamListenerWithResolver(svc, cfg,
-		UpstreamResolverFuncFromClient(client), logger)
+	if cfg.DestinationType == "prepared_query" {
+		return newUpstreamListenerWithResolver(svc, cfg,
+			UpstreamResolverFuncFromClient(client), logger)
+	}
+
+	balancer := newUpstreamBalancer(client, cfg, logger.Named(upstreamListenerPrefix))
+	return newUpstreamListener(svc, cfg, func(ctx context.Context) (net.Conn, error) {
+		return balancer.Dial(ctx, svc)
+	}, logger)
 }
 
 func newUpstreamListenerWithResolver(svc *connect.Service, cfg UpstreamConfig,
 	resolverFunc func(UpstreamConfig) (connect.Resolver, error),
 	logger hclog.Logger) *Listener {
+	return newUpstreamListener(svc, cfg, func(ctx context.Context) (net.Conn, error) {
+		rf, err := resolverFunc(cfg)
+		if err != nil {
+			return nil, err
+		}
+		return svc.Dial(ctx, rf)
+	}, logger)
+}
+
+func newUpstreamListener(svc *connect.Service, cfg UpstreamConfig,
+	dial func(ctx context.Context) (net.Conn, error), logger hclog.Logger) *Listener {
 	bindAddr := ipaddr.FormatAddressPort(cfg.LocalBindAddress, cfg.LocalBindPort)
 	return &Listener{
 		Service: svc,
@@ -108,14 +145,10 @@ func newUpstreamListenerWithResolver(svc *connect.Service, cfg UpstreamConfig,
 			return net.Listen("tcp", bindAddr)
 		},
 		dialFunc: func() (net.Conn, error) {
-			rf, err := resolverFunc(cfg)
-			if err != nil {
-				return nil, err
-			}
 			ctx, cancel := context.WithTimeout(context.Background(),
 				cfg.ConnectTimeout())
 			defer cancel()
-			return svc.Dial(ctx, rf)
+			return dial(ctx)
 		},
 		bindAddr:      bindAddr,
 		stopChan:      make(chan struct{}),
@@ -150,6 +183,10 @@ func (l *Listener) Serve() error {
 
 	close(l.listeningChan)
 
+	if l.httpProxy != nil {
+		return l.serveHTTP(listener)
+	}
+
 	for {
 		conn, err := listener.Accept()
 		if err != nil {
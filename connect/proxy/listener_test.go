@@ -3,8 +3,12 @@ package proxy
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
 	"fmt"
+	"io/ioutil"
 	"net"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
@@ -13,6 +17,8 @@ import (
 	metrics "github.com/armon/go-metrics"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
 
 	agConnect "github.com/hashicorp/consul/agent/connect"
 	"github.com/hashicorp/consul/ipaddr"
@@ -160,6 +166,98 @@ func TestPublicListener(t *testing.T) {
 	assertAllTimeCounterValue(t, sink, "consul.proxy.test.inbound.rx_bytes;dst=db", 11)
 }
 
+func TestPublicListener_HTTP(t *testing.T) {
+	// Can't enable t.Parallel since we rely on the global metrics instance.
+
+	ca := agConnect.TestCA(t, nil)
+
+	cases := []struct {
+		protocol  string
+		h2        bool
+		wantProto string
+	}{
+		{protocol: "http", wantProto: "HTTP/1.1"},
+		{protocol: "http", h2: true, wantProto: "HTTP/1.1"},
+		{protocol: "http2", h2: true, wantProto: "HTTP/2.0"},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+		name := tc.protocol
+		if tc.h2 {
+			name += "-h2c"
+		}
+		t.Run(name, func(t *testing.T) {
+			// The local application reports the protocol it was spoken to with.
+			testApp := httptest.NewUnstartedServer(h2c.NewHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("X-Proto", r.Proto)
+				fmt.Fprintf(w, "%s %s", r.Method, r.URL.Path)
+			}), &http2.Server{}))
+			testApp.Start()
+			defer testApp.Close()
+
+			port := freeport.GetOne(t)
+			cfg := PublicListenerConfig{
+				BindAddress:           "127.0.0.1",
+				BindPort:              port,
+				LocalServiceAddress:   testApp.Listener.Addr().String(),
+				HandshakeTimeoutMs:    100,
+				LocalConnectTimeoutMs: 100,
+				Protocol:              tc.protocol,
+			}
+
+			sink := testSetupMetrics(t)
+
+			svc := connect.TestService(t, "db", ca)
+			l := NewPublicListener(svc, cfg, testutil.Logger(t))
+
+			go func() {
+				if err := l.Serve(); err != nil {
+					t.Errorf("failed to listen: %v", err.Error())
+				}
+			}()
+			defer l.Close()
+			l.Wait()
+
+			dialTLS := func(network, addr string) (net.Conn, error) {
+				return svc.Dial(context.Background(), &connect.StaticResolver{
+					Addr:    TestLocalAddr(port),
+					CertURI: agConnect.TestSpiffeIDService(t, "db"),
+				})
+			}
+			client := &http.Client{Transport: &http.Transport{DialTLS: dialTLS}}
+			url := "https://db/hello"
+			if tc.h2 {
+				// Envoy speaks HTTP/2 over mTLS without negotiating it.
+				client.Transport = &http2.Transport{
+					AllowHTTP: true,
+					DialTLS: func(network, addr string, _ *tls.Config) (net.Conn, error) {
+						return dialTLS(network, addr)
+					},
+				}
+				url = "http://db/hello"
+			}
+
+			resp, err := client.Get(url)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			body, err := ioutil.ReadAll(resp.Body)
+			require.NoError(t, err)
+
+			require.Equal(t, http.StatusOK, resp.StatusCode)
+			require.Equal(t, "GET /hello", string(body))
+			require.Equal(t, tc.wantProto, resp.Header.Get("X-Proto"))
+
+			// Check the active conn is tracked, and is closed with the listener.
+			assertCurrentGaugeValue(t, sink, "consul.proxy.test.inbound.conns;dst=db", 1)
+			l.Close()
+			assertCurrentGaugeValue(t, sink, "consul.proxy.test.inbound.conns;dst=db", 0)
+
+			assertAllTimeCounterValue(t, sink, "consul.proxy.test.inbound.http_requests;dst=db;src=db;code=200", 1)
+		})
+	}
+}
+
 func TestUpstreamListener(t *testing.T) {
 	// Can't enable t.Parallel since we rely on the global metrics instance.
 
@@ -3,7 +3,11 @@ package proxy
 import (
 	"context"
 	"crypto/tls"
+	"fmt"
+	"io/ioutil"
 	"net"
+	"net/http"
+	"net/http/httptest"
 	"path/filepath"
 	"runtime"
 	"strconv"
@@ -126,3 +130,112 @@ func TestProxy_public(t *testing.T) {
 		require.NoFileExists(t, unixSocket)
 	})
 }
+
+func TestProxy_publicHTTP(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	port := freeport.GetOne(t)
+
+	a := agent.NewTestAgent(t, "")
+	defer a.Shutdown()
+	testrpc.WaitForTestAgent(t, a.RPC, "dc1")
+	client := a.Client()
+
+	// Register the service so we can get a leaf cert
+	_, err := client.Catalog().Register(&api.CatalogRegistration{
+		Datacenter: "dc1",
+		Node:       "local",
+		Address:    "127.0.0.1",
+		Service: &api.AgentService{
+			Service: "echo",
+		},
+	}, nil)
+	require.NoError(t, err)
+
+	// Deny requests to the admin endpoints, anything else is allowed by
+	// default since ACLs are disabled.
+	_, _, err = client.ConfigEntries().Set(&api.ServiceConfigEntry{
+		Kind:     api.ServiceDefaults,
+		Name:     "echo",
+		Protocol: "http",
+	}, nil)
+	require.NoError(t, err)
+	_, _, err = client.ConfigEntries().Set(&api.ServiceIntentionsConfigEntry{
+		Kind: api.ServiceIntentions,
+		Name: "echo",
+		Sources: []*api.SourceIntention{
+			{
+				Name: "echo",
+				Permissions: []*api.IntentionPermission{
+					{
+						Action: api.IntentionActionDeny,
+						HTTP:   &api.IntentionHTTPPermission{PathPrefix: "/admin"},
+					},
+				},
+			},
+		},
+	}, nil)
+	require.NoError(t, err)
+
+	// Start the backend service that is being proxied
+	testApp := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, r.URL.Path)
+	}))
+	defer testApp.Close()
+
+	// Start the proxy
+	p, err := New(client, NewStaticConfigWatcher(&Config{
+		ProxiedServiceName: "echo",
+		PublicListener: PublicListenerConfig{
+			BindAddress:         "127.0.0.1",
+			BindPort:            port,
+			LocalServiceAddress: testApp.Listener.Addr().String(),
+			Protocol:            "http",
+		},
+	}), testutil.Logger(t))
+	require.NoError(t, err)
+	defer p.Close()
+	go p.Serve()
+
+	svc, err := connect.NewServiceWithConfig("echo", connect.Config{Client: client})
+	require.NoError(t, err)
+	defer svc.Close()
+
+	httpClient := &http.Client{Transport: &http.Transport{
+		DialTLS: func(network, addr string) (net.Conn, error) {
+			return svc.Dial(context.Background(), &connect.StaticResolver{
+				Addr:    TestLocalAddr(port),
+				CertURI: agConnect.TestSpiffeIDService(t, "echo"),
+			})
+		},
+	}}
+
+	get := func(path string) (int, string, error) {
+		resp, err := httpClient.Get("https://echo" + path)
+		if err != nil {
+			return 0, "", err
+		}
+		defer resp.Body.Close()
+		body, err := ioutil.ReadAll(resp.Body)
+		return resp.StatusCode, string(body), err
+	}
+
+	// We retry here a few times since this is dependent on the agent actually
+	// starting up and setting up the CA.
+	retry.Run(t, func(r *retry.R) {
+		code, body, err := get("/hello")
+		if err != nil {
+			r.Fatalf("err: %s", err)
+		}
+		if code != http.StatusOK {
+			r.Fatalf("got status %d", code)
+		}
+		require.Equal(r, "/hello", body)
+	})
+
+	code, _, err := get("/admin/users")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusForbidden, code)
+}
@@ -9,6 +9,7 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/hashicorp/consul/agent/connect"
 	"github.com/hashicorp/consul/api"
 	"github.com/hashicorp/consul/api/watch"
 	"github.com/hashicorp/consul/logging"
@@ -179,6 +180,50 @@ func (s *Service) ServerTLSConfig() *tls.Config {
 	return s.tlsCfg.Get(newServerSideVerifier(s.logger, s.client, s.service))
 }
 
+// ServerTLSConfigWithoutAuthz returns a *tls.Config like ServerTLSConfig,
+// except that it only verifies the certificates of clients and leaves it to
+// the server to authorize each request, for example with AuthorizeHTTP.
+func (s *Service) ServerTLSConfigWithoutAuthz() *tls.Config {
+	return s.tlsCfg.Get(newServerSideChainVerifier(s.logger))
+}
+
+// AuthorizeHTTP authorizes an HTTP request made over a connection accepted
+// with the config from ServerTLSConfigWithoutAuthz. Unlike the authorization
+// of whole connections, the permissions of L7 intentions are evaluated
+// against the method, path and headers of the request. As for connections,
+// every request is authorized if the Service has no client.
+func (s *Service) AuthorizeHTTP(state tls.ConnectionState, r *http.Request) (*api.AgentAuthorize, error) {
+	if len(state.PeerCertificates) < 1 || len(state.PeerCertificates[0].URIs) < 1 {
+		return nil, errors.New("connect: no client certificate")
+	}
+	leaf := state.PeerCertificates[0]
+	certURI, err := connect.ParseCertURI(leaf.URIs[0])
+	if err != nil {
+		return nil, errors.New("connect: invalid leaf certificate URI")
+	}
+
+	if s.client == nil {
+		return &api.AgentAuthorize{Authorized: true, Reason: "No client to authorize with"}, nil
+	}
+
+	// The Host header is moved out of the headers by net/http but intentions
+	// may still match on it.
+	header := r.Header.Clone()
+	if r.Host != "" {
+		header.Set("Host", r.Host)
+	}
+	return s.client.Agent().ConnectAuthorize(&api.AgentAuthorizeParams{
+		Target:           s.service,
+		ClientCertURI:    certURI.URI().String(),
+		ClientCertSerial: connect.EncodeSerialNumber(leaf.SerialNumber),
+		HTTP: &api.AgentAuthorizeHTTPRequest{
+			Method: r.Method,
+			Path:   r.URL.Path,
+			Header: header,
+		},
+	})
+}
+
 // Dial connects to a remote Connect-enabled server. The passed Resolver is used
 // to discover a single candidate instance which will be dialed and have it's
 // TLS certificate verified against the expected identity. Failures are returned
@@ -53,6 +53,18 @@ func TestMapWalk(t *testing.T) {
 				"bar": "baz",
 			},
 		},
+		"nested map with nil value": {
+			input: map[string]interface{}{
+				"foo": map[interface{}]interface{}{
+					"bar": nil,
+				},
+			},
+			expected: map[string]interface{}{
+				"foo": map[string]interface{}{
+					"bar": nil,
+				},
+			},
+		},
 		"map with slice": {
 			input: map[string]interface{}{
 				"foo": []uint8("bar"),
@@ -38,6 +38,7 @@ const (
 	Namespace          string = "namespace"
 	NetworkAreas       string = "network_areas"
 	Operator           string = "operator"
+	Peering            string = "peering"
 	PreparedQuery      string = "prepared_query"
 	Proxy              string = "proxy"
 	ProxyConfig        string = "proxycfg"
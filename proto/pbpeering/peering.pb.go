@@ -0,0 +1,826 @@
+// Package peering provides the service used by the servers of two peered
+// clusters to replicate the resources they export to each other.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.25.0
+// 	protoc        v3.15.8
+// source: proto/pbpeering/peering.proto
+
+package pbpeering
+
+import (
+	context "context"
+	proto "github.com/golang/protobuf/proto"
+	any1 "github.com/golang/protobuf/ptypes/any"
+	pbservice "github.com/hashicorp/consul/proto/pbservice"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// This is a compile-time assertion that a sufficiently up-to-date version
+// of the legacy proto package is being used.
+const _ = proto.ProtoPackageIsVersion4
+
+type ReplicationMessage_Response_Operation int32
+
+const (
+	ReplicationMessage_Response_Unknown ReplicationMessage_Response_Operation = 0
+	ReplicationMessage_Response_UPSERT  ReplicationMessage_Response_Operation = 1
+	ReplicationMessage_Response_DELETE  ReplicationMessage_Response_Operation = 2
+)
+
+// Enum value maps for ReplicationMessage_Response_Operation.
+var (
+	ReplicationMessage_Response_Operation_name = map[int32]string{
+		0: "Unknown",
+		1: "UPSERT",
+		2: "DELETE",
+	}
+	ReplicationMessage_Response_Operation_value = map[string]int32{
+		"Unknown": 0,
+		"UPSERT":  1,
+		"DELETE":  2,
+	}
+)
+
+func (x ReplicationMessage_Response_Operation) Enum() *ReplicationMessage_Response_Operation {
+	p := new(ReplicationMessage_Response_Operation)
+	*p = x
+	return p
+}
+
+func (x ReplicationMessage_Response_Operation) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ReplicationMessage_Response_Operation) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_pbpeering_peering_proto_enumTypes[0].Descriptor()
+}
+
+func (ReplicationMessage_Response_Operation) Type() protoreflect.EnumType {
+	return &file_proto_pbpeering_peering_proto_enumTypes[0]
+}
+
+func (x ReplicationMessage_Response_Operation) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ReplicationMessage_Response_Operation.Descriptor instead.
+func (ReplicationMessage_Response_Operation) EnumDescriptor() ([]byte, []int) {
+	return file_proto_pbpeering_peering_proto_rawDescGZIP(), []int{0, 1, 0}
+}
+
+type ReplicationMessage struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Types that are assignable to Payload:
+	//	*ReplicationMessage_Request_
+	//	*ReplicationMessage_Response_
+	//	*ReplicationMessage_Terminated_
+	Payload isReplicationMessage_Payload `protobuf_oneof:"Payload"`
+}
+
+func (x *ReplicationMessage) Reset() {
+	*x = ReplicationMessage{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_pbpeering_peering_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ReplicationMessage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReplicationMessage) ProtoMessage() {}
+
+func (x *ReplicationMessage) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_pbpeering_peering_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReplicationMessage.ProtoReflect.Descriptor instead.
+func (*ReplicationMessage) Descriptor() ([]byte, []int) {
+	return file_proto_pbpeering_peering_proto_rawDescGZIP(), []int{0}
+}
+
+func (m *ReplicationMessage) GetPayload() isReplicationMessage_Payload {
+	if m != nil {
+		return m.Payload
+	}
+	return nil
+}
+
+func (x *ReplicationMessage) GetRequest() *ReplicationMessage_Request {
+	if x, ok := x.GetPayload().(*ReplicationMessage_Request_); ok {
+		return x.Request
+	}
+	return nil
+}
+
+func (x *ReplicationMessage) GetResponse() *ReplicationMessage_Response {
+	if x, ok := x.GetPayload().(*ReplicationMessage_Response_); ok {
+		return x.Response
+	}
+	return nil
+}
+
+func (x *ReplicationMessage) GetTerminated() *ReplicationMessage_Terminated {
+	if x, ok := x.GetPayload().(*ReplicationMessage_Terminated_); ok {
+		return x.Terminated
+	}
+	return nil
+}
+
+type isReplicationMessage_Payload interface {
+	isReplicationMessage_Payload()
+}
+
+type ReplicationMessage_Request_ struct {
+	Request *ReplicationMessage_Request `protobuf:"bytes,1,opt,name=request,proto3,oneof"`
+}
+
+type ReplicationMessage_Response_ struct {
+	Response *ReplicationMessage_Response `protobuf:"bytes,2,opt,name=response,proto3,oneof"`
+}
+
+type ReplicationMessage_Terminated_ struct {
+	Terminated *ReplicationMessage_Terminated `protobuf:"bytes,3,opt,name=terminated,proto3,oneof"`
+}
+
+func (*ReplicationMessage_Request_) isReplicationMessage_Payload() {}
+
+func (*ReplicationMessage_Response_) isReplicationMessage_Payload() {}
+
+func (*ReplicationMessage_Terminated_) isReplicationMessage_Payload() {}
+
+// ExportedService holds the instances of a service exported to a peer.
+type ExportedService struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Nodes []*pbservice.CheckServiceNode `protobuf:"bytes,1,rep,name=Nodes,proto3" json:"Nodes,omitempty"`
+}
+
+func (x *ExportedService) Reset() {
+	*x = ExportedService{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_pbpeering_peering_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ExportedService) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExportedService) ProtoMessage() {}
+
+func (x *ExportedService) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_pbpeering_peering_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExportedService.ProtoReflect.Descriptor instead.
+func (*ExportedService) Descriptor() ([]byte, []int) {
+	return file_proto_pbpeering_peering_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ExportedService) GetNodes() []*pbservice.CheckServiceNode {
+	if x != nil {
+		return x.Nodes
+	}
+	return nil
+}
+
+// MeshInfo is what a peer needs to send mesh traffic to the services exported
+// to it: the roots their certificates are signed by and the mesh gateways the
+// traffic enters through.
+type MeshInfo struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// TrustDomain is the trust domain of the certificates of the exported
+	// services.
+	TrustDomain string `protobuf:"bytes,1,opt,name=TrustDomain,proto3" json:"TrustDomain,omitempty"`
+	// RootPEMs are the active Connect CA roots of the exporting cluster.
+	RootPEMs []string `protobuf:"bytes,2,rep,name=RootPEMs,proto3" json:"RootPEMs,omitempty"`
+	// Datacenter is the datacenter of the exported services.
+	Datacenter string `protobuf:"bytes,3,opt,name=Datacenter,proto3" json:"Datacenter,omitempty"`
+	// GatewayAddresses are the addresses, host and port, of the mesh gateways
+	// of the exporting datacenter.
+	GatewayAddresses []string `protobuf:"bytes,4,rep,name=GatewayAddresses,proto3" json:"GatewayAddresses,omitempty"`
+}
+
+func (x *MeshInfo) Reset() {
+	*x = MeshInfo{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_pbpeering_peering_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *MeshInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MeshInfo) ProtoMessage() {}
+
+func (x *MeshInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_pbpeering_peering_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MeshInfo.ProtoReflect.Descriptor instead.
+func (*MeshInfo) Descriptor() ([]byte, []int) {
+	return file_proto_pbpeering_peering_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *MeshInfo) GetTrustDomain() string {
+	if x != nil {
+		return x.TrustDomain
+	}
+	return ""
+}
+
+func (x *MeshInfo) GetRootPEMs() []string {
+	if x != nil {
+		return x.RootPEMs
+	}
+	return nil
+}
+
+func (x *MeshInfo) GetDatacenter() string {
+	if x != nil {
+		return x.Datacenter
+	}
+	return ""
+}
+
+func (x *MeshInfo) GetGatewayAddresses() []string {
+	if x != nil {
+		return x.GatewayAddresses
+	}
+	return nil
+}
+
+// A Request subscribes to the resources of a type exported by the peer.
+type ReplicationMessage_Request struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// PeerID is the ID of the peering on the accepting side. It is only set
+	// by the first message sent by the dialing side.
+	PeerID string `protobuf:"bytes,1,opt,name=PeerID,proto3" json:"PeerID,omitempty"`
+	// ResourceURL is the type URL of the resources subscribed to.
+	ResourceURL string `protobuf:"bytes,2,opt,name=ResourceURL,proto3" json:"ResourceURL,omitempty"`
+}
+
+func (x *ReplicationMessage_Request) Reset() {
+	*x = ReplicationMessage_Request{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_pbpeering_peering_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ReplicationMessage_Request) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReplicationMessage_Request) ProtoMessage() {}
+
+func (x *ReplicationMessage_Request) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_pbpeering_peering_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReplicationMessage_Request.ProtoReflect.Descriptor instead.
+func (*ReplicationMessage_Request) Descriptor() ([]byte, []int) {
+	return file_proto_pbpeering_peering_proto_rawDescGZIP(), []int{0, 0}
+}
+
+func (x *ReplicationMessage_Request) GetPeerID() string {
+	if x != nil {
+		return x.PeerID
+	}
+	return ""
+}
+
+func (x *ReplicationMessage_Request) GetResourceURL() string {
+	if x != nil {
+		return x.ResourceURL
+	}
+	return ""
+}
+
+// A Response carries a resource of a type the peer subscribed to.
+type ReplicationMessage_Response struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// ResourceURL is the type URL of the resource.
+	ResourceURL string `protobuf:"bytes,1,opt,name=ResourceURL,proto3" json:"ResourceURL,omitempty"`
+	// ResourceID identifies the resource within its type, for instance the
+	// name of an exported service.
+	ResourceID string `protobuf:"bytes,2,opt,name=ResourceID,proto3" json:"ResourceID,omitempty"`
+	// Resource is the resource itself. It is not set for deletions.
+	Resource *any1.Any `protobuf:"bytes,3,opt,name=Resource,proto3" json:"Resource,omitempty"`
+	// Operation is what should be done with the resource on the receiving
+	// side.
+	Operation ReplicationMessage_Response_Operation `protobuf:"varint,4,opt,name=operation,proto3,enum=peering.ReplicationMessage_Response_Operation" json:"operation,omitempty"`
+}
+
+func (x *ReplicationMessage_Response) Reset() {
+	*x = ReplicationMessage_Response{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_pbpeering_peering_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ReplicationMessage_Response) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReplicationMessage_Response) ProtoMessage() {}
+
+func (x *ReplicationMessage_Response) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_pbpeering_peering_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReplicationMessage_Response.ProtoReflect.Descriptor instead.
+func (*ReplicationMessage_Response) Descriptor() ([]byte, []int) {
+	return file_proto_pbpeering_peering_proto_rawDescGZIP(), []int{0, 1}
+}
+
+func (x *ReplicationMessage_Response) GetResourceURL() string {
+	if x != nil {
+		return x.ResourceURL
+	}
+	return ""
+}
+
+func (x *ReplicationMessage_Response) GetResourceID() string {
+	if x != nil {
+		return x.ResourceID
+	}
+	return ""
+}
+
+func (x *ReplicationMessage_Response) GetResource() *any1.Any {
+	if x != nil {
+		return x.Resource
+	}
+	return nil
+}
+
+func (x *ReplicationMessage_Response) GetOperation() ReplicationMessage_Response_Operation {
+	if x != nil {
+		return x.Operation
+	}
+	return ReplicationMessage_Response_Unknown
+}
+
+// Terminated is sent when the peering is deleted on the sending side. The
+// stream is closed right after it.
+type ReplicationMessage_Terminated struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *ReplicationMessage_Terminated) Reset() {
+	*x = ReplicationMessage_Terminated{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_pbpeering_peering_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ReplicationMessage_Terminated) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReplicationMessage_Terminated) ProtoMessage() {}
+
+func (x *ReplicationMessage_Terminated) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_pbpeering_peering_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReplicationMessage_Terminated.ProtoReflect.Descriptor instead.
+func (*ReplicationMessage_Terminated) Descriptor() ([]byte, []int) {
+	return file_proto_pbpeering_peering_proto_rawDescGZIP(), []int{0, 2}
+}
+
+var File_proto_pbpeering_peering_proto protoreflect.FileDescriptor
+
+var file_proto_pbpeering_peering_proto_rawDesc = []byte{
+	0x0a, 0x1d, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x70, 0x62, 0x70, 0x65, 0x65, 0x72, 0x69, 0x6e,
+	0x67, 0x2f, 0x70, 0x65, 0x65, 0x72, 0x69, 0x6e, 0x67, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12,
+	0x07, 0x70, 0x65, 0x65, 0x72, 0x69, 0x6e, 0x67, 0x1a, 0x19, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65,
+	0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x61, 0x6e, 0x79, 0x2e, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x1a, 0x1a, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x70, 0x62, 0x73, 0x65, 0x72,
+	0x76, 0x69, 0x63, 0x65, 0x2f, 0x6e, 0x6f, 0x64, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22,
+	0xc2, 0x04, 0x0a, 0x12, 0x52, 0x65, 0x70, 0x6c, 0x69, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x4d,
+	0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x3f, 0x0a, 0x07, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x23, 0x2e, 0x70, 0x65, 0x65, 0x72, 0x69, 0x6e,
+	0x67, 0x2e, 0x52, 0x65, 0x70, 0x6c, 0x69, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x4d, 0x65, 0x73,
+	0x73, 0x61, 0x67, 0x65, 0x2e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x48, 0x00, 0x52, 0x07,
+	0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x42, 0x0a, 0x08, 0x72, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x24, 0x2e, 0x70, 0x65, 0x65, 0x72,
+	0x69, 0x6e, 0x67, 0x2e, 0x52, 0x65, 0x70, 0x6c, 0x69, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x4d,
+	0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x2e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x48,
+	0x00, 0x52, 0x08, 0x72, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x48, 0x0a, 0x0a, 0x74,
+	0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61, 0x74, 0x65, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x26, 0x2e, 0x70, 0x65, 0x65, 0x72, 0x69, 0x6e, 0x67, 0x2e, 0x52, 0x65, 0x70, 0x6c, 0x69, 0x63,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x2e, 0x54, 0x65, 0x72,
+	0x6d, 0x69, 0x6e, 0x61, 0x74, 0x65, 0x64, 0x48, 0x00, 0x52, 0x0a, 0x74, 0x65, 0x72, 0x6d, 0x69,
+	0x6e, 0x61, 0x74, 0x65, 0x64, 0x1a, 0x43, 0x0a, 0x07, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x12, 0x16, 0x0a, 0x06, 0x50, 0x65, 0x65, 0x72, 0x49, 0x44, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x06, 0x50, 0x65, 0x65, 0x72, 0x49, 0x44, 0x12, 0x20, 0x0a, 0x0b, 0x52, 0x65, 0x73, 0x6f,
+	0x75, 0x72, 0x63, 0x65, 0x55, 0x52, 0x4c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x52,
+	0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x55, 0x52, 0x4c, 0x1a, 0xfe, 0x01, 0x0a, 0x08, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x20, 0x0a, 0x0b, 0x52, 0x65, 0x73, 0x6f, 0x75,
+	0x72, 0x63, 0x65, 0x55, 0x52, 0x4c, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x52, 0x65,
+	0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x55, 0x52, 0x4c, 0x12, 0x1e, 0x0a, 0x0a, 0x52, 0x65, 0x73,
+	0x6f, 0x75, 0x72, 0x63, 0x65, 0x49, 0x44, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x52,
+	0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x49, 0x44, 0x12, 0x30, 0x0a, 0x08, 0x52, 0x65, 0x73,
+	0x6f, 0x75, 0x72, 0x63, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x67, 0x6f,
+	0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x41, 0x6e,
+	0x79, 0x52, 0x08, 0x52, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x12, 0x4c, 0x0a, 0x09, 0x6f,
+	0x70, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x2e,
+	0x2e, 0x70, 0x65, 0x65, 0x72, 0x69, 0x6e, 0x67, 0x2e, 0x52, 0x65, 0x70, 0x6c, 0x69, 0x63, 0x61,
+	0x74, 0x69, 0x6f, 0x6e, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x2e, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x2e, 0x4f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x09,
+	0x6f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x22, 0x30, 0x0a, 0x09, 0x4f, 0x70, 0x65,
+	0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x0b, 0x0a, 0x07, 0x55, 0x6e, 0x6b, 0x6e, 0x6f, 0x77,
+	0x6e, 0x10, 0x00, 0x12, 0x0a, 0x0a, 0x06, 0x55, 0x50, 0x53, 0x45, 0x52, 0x54, 0x10, 0x01, 0x12,
+	0x0a, 0x0a, 0x06, 0x44, 0x45, 0x4c, 0x45, 0x54, 0x45, 0x10, 0x02, 0x1a, 0x0c, 0x0a, 0x0a, 0x54,
+	0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61, 0x74, 0x65, 0x64, 0x42, 0x09, 0x0a, 0x07, 0x50, 0x61, 0x79,
+	0x6c, 0x6f, 0x61, 0x64, 0x22, 0x44, 0x0a, 0x0f, 0x45, 0x78, 0x70, 0x6f, 0x72, 0x74, 0x65, 0x64,
+	0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x31, 0x0a, 0x05, 0x4e, 0x6f, 0x64, 0x65, 0x73,
+	0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x70, 0x62, 0x73, 0x65, 0x72, 0x76, 0x69,
+	0x63, 0x65, 0x2e, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x4e,
+	0x6f, 0x64, 0x65, 0x52, 0x05, 0x4e, 0x6f, 0x64, 0x65, 0x73, 0x22, 0x94, 0x01, 0x0a, 0x08, 0x4d,
+	0x65, 0x73, 0x68, 0x49, 0x6e, 0x66, 0x6f, 0x12, 0x20, 0x0a, 0x0b, 0x54, 0x72, 0x75, 0x73, 0x74,
+	0x44, 0x6f, 0x6d, 0x61, 0x69, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x54, 0x72,
+	0x75, 0x73, 0x74, 0x44, 0x6f, 0x6d, 0x61, 0x69, 0x6e, 0x12, 0x1a, 0x0a, 0x08, 0x52, 0x6f, 0x6f,
+	0x74, 0x50, 0x45, 0x4d, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x08, 0x52, 0x6f, 0x6f,
+	0x74, 0x50, 0x45, 0x4d, 0x73, 0x12, 0x1e, 0x0a, 0x0a, 0x44, 0x61, 0x74, 0x61, 0x63, 0x65, 0x6e,
+	0x74, 0x65, 0x72, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x44, 0x61, 0x74, 0x61, 0x63,
+	0x65, 0x6e, 0x74, 0x65, 0x72, 0x12, 0x2a, 0x0a, 0x10, 0x47, 0x61, 0x74, 0x65, 0x77, 0x61, 0x79,
+	0x41, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x65, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x09, 0x52,
+	0x10, 0x47, 0x61, 0x74, 0x65, 0x77, 0x61, 0x79, 0x41, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x65,
+	0x73, 0x32, 0x63, 0x0a, 0x0e, 0x50, 0x65, 0x65, 0x72, 0x69, 0x6e, 0x67, 0x53, 0x65, 0x72, 0x76,
+	0x69, 0x63, 0x65, 0x12, 0x51, 0x0a, 0x0f, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x52, 0x65, 0x73,
+	0x6f, 0x75, 0x72, 0x63, 0x65, 0x73, 0x12, 0x1b, 0x2e, 0x70, 0x65, 0x65, 0x72, 0x69, 0x6e, 0x67,
+	0x2e, 0x52, 0x65, 0x70, 0x6c, 0x69, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x4d, 0x65, 0x73, 0x73,
+	0x61, 0x67, 0x65, 0x1a, 0x1b, 0x2e, 0x70, 0x65, 0x65, 0x72, 0x69, 0x6e, 0x67, 0x2e, 0x52, 0x65,
+	0x70, 0x6c, 0x69, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65,
+	0x22, 0x00, 0x28, 0x01, 0x30, 0x01, 0x42, 0x2d, 0x5a, 0x2b, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62,
+	0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x68, 0x61, 0x73, 0x68, 0x69, 0x63, 0x6f, 0x72, 0x70, 0x2f, 0x63,
+	0x6f, 0x6e, 0x73, 0x75, 0x6c, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x70, 0x62, 0x70, 0x65,
+	0x65, 0x72, 0x69, 0x6e, 0x67, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_proto_pbpeering_peering_proto_rawDescOnce sync.Once
+	file_proto_pbpeering_peering_proto_rawDescData = file_proto_pbpeering_peering_proto_rawDesc
+)
+
+func file_proto_pbpeering_peering_proto_rawDescGZIP() []byte {
+	file_proto_pbpeering_peering_proto_rawDescOnce.Do(func() {
+		file_proto_pbpeering_peering_proto_rawDescData = protoimpl.X.CompressGZIP(file_proto_pbpeering_peering_proto_rawDescData)
+	})
+	return file_proto_pbpeering_peering_proto_rawDescData
+}
+
+var file_proto_pbpeering_peering_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_proto_pbpeering_peering_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
+var file_proto_pbpeering_peering_proto_goTypes = []interface{}{
+	(ReplicationMessage_Response_Operation)(0), // 0: peering.ReplicationMessage.Response.Operation
+	(*ReplicationMessage)(nil),                 // 1: peering.ReplicationMessage
+	(*ExportedService)(nil),                    // 2: peering.ExportedService
+	(*MeshInfo)(nil),                           // 3: peering.MeshInfo
+	(*ReplicationMessage_Request)(nil),         // 4: peering.ReplicationMessage.Request
+	(*ReplicationMessage_Response)(nil),        // 5: peering.ReplicationMessage.Response
+	(*ReplicationMessage_Terminated)(nil),      // 6: peering.ReplicationMessage.Terminated
+	(*pbservice.CheckServiceNode)(nil),         // 7: pbservice.CheckServiceNode
+	(*any1.Any)(nil),                           // 8: google.protobuf.Any
+}
+var file_proto_pbpeering_peering_proto_depIdxs = []int32{
+	4, // 0: peering.ReplicationMessage.request:type_name -> peering.ReplicationMessage.Request
+	5, // 1: peering.ReplicationMessage.response:type_name -> peering.ReplicationMessage.Response
+	6, // 2: peering.ReplicationMessage.terminated:type_name -> peering.ReplicationMessage.Terminated
+	7, // 3: peering.ExportedService.Nodes:type_name -> pbservice.CheckServiceNode
+	8, // 4: peering.ReplicationMessage.Response.Resource:type_name -> google.protobuf.Any
+	0, // 5: peering.ReplicationMessage.Response.operation:type_name -> peering.ReplicationMessage.Response.Operation
+	1, // 6: peering.PeeringService.StreamResources:input_type -> peering.ReplicationMessage
+	1, // 7: peering.PeeringService.StreamResources:output_type -> peering.ReplicationMessage
+	7, // [7:8] is the sub-list for method output_type
+	6, // [6:7] is the sub-list for method input_type
+	6, // [6:6] is the sub-list for extension type_name
+	6, // [6:6] is the sub-list for extension extendee
+	0, // [0:6] is the sub-list for field type_name
+}
+
+func init() { file_proto_pbpeering_peering_proto_init() }
+func file_proto_pbpeering_peering_proto_init() {
+	if File_proto_pbpeering_peering_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_proto_pbpeering_peering_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ReplicationMessage); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_pbpeering_peering_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ExportedService); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_pbpeering_peering_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*MeshInfo); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_pbpeering_peering_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ReplicationMessage_Request); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_pbpeering_peering_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ReplicationMessage_Response); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_pbpeering_peering_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ReplicationMessage_Terminated); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	file_proto_pbpeering_peering_proto_msgTypes[0].OneofWrappers = []interface{}{
+		(*ReplicationMessage_Request_)(nil),
+		(*ReplicationMessage_Response_)(nil),
+		(*ReplicationMessage_Terminated_)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_proto_pbpeering_peering_proto_rawDesc,
+			NumEnums:      1,
+			NumMessages:   6,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_proto_pbpeering_peering_proto_goTypes,
+		DependencyIndexes: file_proto_pbpeering_peering_proto_depIdxs,
+		EnumInfos:         file_proto_pbpeering_peering_proto_enumTypes,
+		MessageInfos:      file_proto_pbpeering_peering_proto_msgTypes,
+	}.Build()
+	File_proto_pbpeering_peering_proto = out.File
+	file_proto_pbpeering_peering_proto_rawDesc = nil
+	file_proto_pbpeering_peering_proto_goTypes = nil
+	file_proto_pbpeering_peering_proto_depIdxs = nil
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConnInterface
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion6
+
+// PeeringServiceClient is the client API for PeeringService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type PeeringServiceClient interface {
+	// StreamResources opens an event stream for the resources exported between
+	// two peers. The first message sent by the dialing side must be a Request
+	// carrying the ID of the peering on the accepting side, which the token
+	// the peering was established with holds. The stream must carry the stream
+	// secret of the peering, which the token holds as well, in the
+	// x-consul-peering-stream-secret metadata. Resources are sent in responses
+	// as they change, for as long as the stream stays open.
+	StreamResources(ctx context.Context, opts ...grpc.CallOption) (PeeringService_StreamResourcesClient, error)
+}
+
+type peeringServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewPeeringServiceClient(cc grpc.ClientConnInterface) PeeringServiceClient {
+	return &peeringServiceClient{cc}
+}
+
+func (c *peeringServiceClient) StreamResources(ctx context.Context, opts ...grpc.CallOption) (PeeringService_StreamResourcesClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_PeeringService_serviceDesc.Streams[0], "/peering.PeeringService/StreamResources", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &peeringServiceStreamResourcesClient{stream}
+	return x, nil
+}
+
+type PeeringService_StreamResourcesClient interface {
+	Send(*ReplicationMessage) error
+	Recv() (*ReplicationMessage, error)
+	grpc.ClientStream
+}
+
+type peeringServiceStreamResourcesClient struct {
+	grpc.ClientStream
+}
+
+func (x *peeringServiceStreamResourcesClient) Send(m *ReplicationMessage) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *peeringServiceStreamResourcesClient) Recv() (*ReplicationMessage, error) {
+	m := new(ReplicationMessage)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// PeeringServiceServer is the server API for PeeringService service.
+type PeeringServiceServer interface {
+	// StreamResources opens an event stream for the resources exported between
+	// two peers. The first message sent by the dialing side must be a Request
+	// carrying the ID of the peering on the accepting side, which the token
+	// the peering was established with holds. The stream must carry the stream
+	// secret of the peering, which the token holds as well, in the
+	// x-consul-peering-stream-secret metadata. Resources are sent in responses
+	// as they change, for as long as the stream stays open.
+	StreamResources(PeeringService_StreamResourcesServer) error
+}
+
+// UnimplementedPeeringServiceServer can be embedded to have forward compatible implementations.
+type UnimplementedPeeringServiceServer struct {
+}
+
+func (*UnimplementedPeeringServiceServer) StreamResources(PeeringService_StreamResourcesServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamResources not implemented")
+}
+
+func RegisterPeeringServiceServer(s *grpc.Server, srv PeeringServiceServer) {
+	s.RegisterService(&_PeeringService_serviceDesc, srv)
+}
+
+func _PeeringService_StreamResources_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(PeeringServiceServer).StreamResources(&peeringServiceStreamResourcesServer{stream})
+}
+
+type PeeringService_StreamResourcesServer interface {
+	Send(*ReplicationMessage) error
+	Recv() (*ReplicationMessage, error)
+	grpc.ServerStream
+}
+
+type peeringServiceStreamResourcesServer struct {
+	grpc.ServerStream
+}
+
+func (x *peeringServiceStreamResourcesServer) Send(m *ReplicationMessage) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *peeringServiceStreamResourcesServer) Recv() (*ReplicationMessage, error) {
+	m := new(ReplicationMessage)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+var _PeeringService_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "peering.PeeringService",
+	HandlerType: (*PeeringServiceServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamResources",
+			Handler:       _PeeringService_StreamResources_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "proto/pbpeering/peering.proto",
+}
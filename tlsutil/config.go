@@ -233,6 +233,14 @@ func (c *Configurator) ManualCAPems() []string {
 	return c.internalRPC.manualCAPEMs
 }
 
+// GRPCManualCAPems returns the currently loaded CAs for the external gRPC
+// protocol in PEM format. It is used to generate peering tokens.
+func (c *Configurator) GRPCManualCAPems() []string {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return c.grpc.manualCAPEMs
+}
+
 // Update updates the internal configuration which is used to generate
 // *tls.Config.
 // This function acquires a write lock because it writes the new config.